@@ -0,0 +1,161 @@
+// file: cmd/ecsm-cli/cmd/apply.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+)
+
+// newApplyCmd 创建 "apply" 命令，它把清单中的 ECSMService 写入 operator 的 Registry
+func newApplyCmd() *cobra.Command {
+	var (
+		filename  string
+		namespace string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply -f FILENAME",
+		Short: "Create or update ECSMServices from manifests",
+		Long: `Creates or updates the ECSMServices in YAML or JSON manifests. FILENAME may be
+a file, a directory whose .yaml, .yml and .json files are applied in name order,
+or "-" to read from standard input. Manifests of older ecsm.sh versions are
+converted to v1.
+
+Services that do not exist are created. For existing services the spec is
+replaced and the labels and annotations of the manifest are added, labels and
+annotations missing from the manifest are kept. If the manifest sets
+metadata.resourceVersion, the update only succeeds if the service has not been
+modified since that version.
+
+The services are written through the operator's API server given with --server.
+While the operator is stopped they can instead be written to its registry
+database given with --registry-db.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return fmt.Errorf("-f must be specified")
+			}
+			manifests, err := util.ReadManifests(filename)
+			if err != nil {
+				return err
+			}
+			// 先解码所有清单，避免只应用了一部分之后才发现后面的清单有误
+			var services []*ecsmv1.ECSMService
+			for i := range manifests {
+				service, err := manifests[i].DecodeService()
+				if err != nil {
+					return err
+				}
+				if service.Namespace == "" {
+					service.Namespace = namespace
+				}
+				services = append(services, service)
+			}
+
+			reg, closeFn, err := util.NewWritableRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			// 一个对象失败时继续应用其余的对象，最后返回所有错误
+			var errs []error
+			for _, service := range services {
+				result, err := applyService(context.Background(), reg, service)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to apply ecsmservice %s/%s: %w", service.Namespace, service.Name, err))
+					continue
+				}
+				fmt.Fprintf(os.Stdout, "ecsmservice/%s %s\n", service.Name, result)
+			}
+			return errors.Join(errs...)
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "The file, directory or \"-\" for standard input that contains the manifests to apply")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of services whose manifest does not set one")
+	return cmd
+}
+
+// applyService 创建或更新一个 ECSMService，返回 "created"、"configured" 或 "unchanged"。
+func applyService(ctx context.Context, reg registry.Interface, service *ecsmv1.ECSMService) (string, error) {
+	precondition := service.ResourceVersion
+	// Registry 在创建时填充默认值，更新时不会，这里先填充以便和已有的 spec 比较
+	defaults.SetServiceDefaults(service)
+
+	result := "unchanged"
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := reg.GetService(ctx, service.Namespace, service.Name)
+		if apierrors.IsNotFound(err) {
+			if precondition != "" {
+				return err
+			}
+			created := service.DeepCopy()
+			created.ResourceVersion = ""
+			created.Status = ecsmv1.ECSMServiceStatus{}
+			if _, err := reg.CreateService(ctx, created); err != nil {
+				return err
+			}
+			result = "created"
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		updated := mergeAppliedService(current, service)
+		if equality.Semantic.DeepEqual(current, updated) {
+			result = "unchanged"
+			return nil
+		}
+		if precondition != "" {
+			updated.ResourceVersion = precondition
+		}
+		if _, err := reg.UpdateService(ctx, updated); err != nil {
+			if apierrors.IsConflict(err) && precondition != "" {
+				// 指定了 resourceVersion 时冲突不能重试，这里不包装原错误，以免被 RetryOnConflict 当作冲突
+				return fmt.Errorf("the service has been modified since resource version %s", precondition)
+			}
+			return err
+		}
+		result = "configured"
+		return nil
+	})
+	return result, err
+}
+
+// mergeAppliedService 返回把清单中的 service 应用到已有的 current 之后的对象：spec 被替换，标签和注解被合并。
+func mergeAppliedService(current, service *ecsmv1.ECSMService) *ecsmv1.ECSMService {
+	updated := current.DeepCopy()
+	updated.Spec = service.Spec
+	updated.Labels = mergeStrings(updated.Labels, service.Labels)
+	updated.Annotations = mergeStrings(updated.Annotations, service.Annotations)
+	return updated
+}
+
+// mergeStrings 把 overrides 中的键值写入 base 的副本。
+func mergeStrings(base, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
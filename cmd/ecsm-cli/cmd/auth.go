@@ -0,0 +1,213 @@
+// file: cmd/ecsm-cli/cmd/auth.go
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// newAuthCmd 创建 auth 命令，它管理保存在上下文中的 ECSM API Server 凭据
+func newAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Log in to and out of the ECSM API server",
+		Long: `Manages the credentials used to authenticate to the ECSM API server.
+
+"auth login" logs in with a username and password and saves the resulting
+token in the current context of the config file, which is written with
+permissions 0600. All later commands using the context send the token and
+refresh it shortly before it expires. The global --token flag (or the
+ECSMCLI_TOKEN environment variable) overrides the saved token.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newAuthLoginCmd())
+	cmd.AddCommand(newAuthLogoutCmd())
+	cmd.AddCommand(newAuthStatusCmd())
+	return cmd
+}
+
+// loadCurrentContext 读取配置文件和正在使用的上下文，没有使用上下文时返回错误。
+func loadCurrentContext() (path string, config *util.CLIConfig, name string, err error) {
+	path, err = configFilePath()
+	if err != nil {
+		return "", nil, "", err
+	}
+	config, err = util.LoadConfig(path)
+	if err != nil {
+		return "", nil, "", err
+	}
+	name, err = util.CurrentContextName(config)
+	if err != nil {
+		return "", nil, "", err
+	}
+	if name == "" {
+		return "", nil, "", fmt.Errorf("no context is selected, credentials are saved per context; create one with \"ecsm-cli config set-context NAME\" and select it with \"ecsm-cli config use-context NAME\"")
+	}
+	return path, config, name, nil
+}
+
+// newAuthLoginCmd 创建 "auth login" 子命令
+func newAuthLoginCmd() *cobra.Command {
+	var username string
+
+	cmd := &cobra.Command{
+		Use:   "login [--username USERNAME]",
+		Short: "Log in to the ECSM API server and save the token in the current context",
+		Long: `Logs in to the ECSM API server of the current context and saves the token in
+the context. The username defaults to the one of the context and is asked for
+if the context has none. The password of the context is used if it has one,
+otherwise it is read from the terminal without echo, or from the first line of
+standard input when it is not a terminal.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, config, name, err := loadCurrentContext()
+			if err != nil {
+				return err
+			}
+			ctx := config.Contexts[name]
+
+			if username == "" {
+				username = ctx.Username
+			}
+			if username == "" {
+				if username, err = readUsername(); err != nil {
+					return err
+				}
+			}
+			password := ctx.Password
+			if password == "" {
+				if password, err = util.ReadPassword(fmt.Sprintf("Password for %s: ", username)); err != nil {
+					return err
+				}
+			}
+
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			token, err := cs.Auth().Login(context.Background(), &clientset.LoginRequest{Username: username, Password: password})
+			if err != nil {
+				return fmt.Errorf("failed to log in as %s: %w", username, err)
+			}
+
+			// 记住用户名，下次登录时不需要再输入
+			ctx.Username = username
+			ctx.SetToken(token)
+			if err := util.SaveConfig(path, config); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Logged in as %s, the token is saved in context %q.\n", username, name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&username, "username", "u", "", "The username to log in with (default is the username of the context)")
+	return cmd
+}
+
+// readUsername 从终端读取用户名，标准输入不是终端时要求使用 --username，因为标准输入留给密码。
+func readUsername() (string, error) {
+	if !util.IsTerminal(os.Stdin) {
+		return "", fmt.Errorf("--username must be specified when standard input is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, "Username: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read username: %w", err)
+	}
+	username := strings.TrimSpace(line)
+	if username == "" {
+		return "", fmt.Errorf("the username must not be empty")
+	}
+	return username, nil
+}
+
+// newAuthLogoutCmd 创建 "auth logout" 子命令
+func newAuthLogoutCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Revoke and remove the token saved in the current context",
+		Long: `Asks the ECSM API server to revoke the token saved in the current context and
+removes it from the context. The token is removed even if the server cannot be
+reached.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, config, name, err := loadCurrentContext()
+			if err != nil {
+				return err
+			}
+			ctx := config.Contexts[name]
+			token := ctx.SavedToken()
+			if token == nil {
+				fmt.Fprintf(os.Stdout, "Context %q is not logged in.\n", name)
+				return nil
+			}
+
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			if err := cs.Auth().Logout(context.Background(), token.Token); err != nil {
+				klog.Warningf("Could not revoke the token on the server: %v", err)
+			}
+
+			ctx.SetToken(nil)
+			if err := util.SaveConfig(path, config); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Logged out of context %q.\n", name)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newAuthStatusCmd 创建 "auth status" 子命令
+func newAuthStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the current context is logged in",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, config, name, err := loadCurrentContext()
+			if err != nil {
+				return err
+			}
+			ctx := config.Contexts[name]
+			token := ctx.SavedToken()
+
+			fmt.Fprintf(os.Stdout, "Context:   %s\n", name)
+			fmt.Fprintf(os.Stdout, "Server:    %s\n", contextServer(ctx))
+			if ctx.Username != "" {
+				fmt.Fprintf(os.Stdout, "Username:  %s\n", ctx.Username)
+			}
+			switch {
+			case token == nil:
+				fmt.Fprintf(os.Stdout, "Status:    not logged in\n")
+			case token.ExpiresAt == 0:
+				fmt.Fprintf(os.Stdout, "Status:    logged in, the token does not expire\n")
+			case time.Now().Before(token.Expiry()):
+				fmt.Fprintf(os.Stdout, "Status:    logged in, the token expires at %s\n", token.Expiry().Local().Format(time.RFC3339))
+			case token.RefreshToken != "":
+				fmt.Fprintf(os.Stdout, "Status:    logged in, the token expired at %s and is refreshed on the next request\n", token.Expiry().Local().Format(time.RFC3339))
+			default:
+				fmt.Fprintf(os.Stdout, "Status:    the token expired at %s, log in again\n", token.Expiry().Local().Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
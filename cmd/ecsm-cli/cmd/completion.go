@@ -0,0 +1,215 @@
+// file: cmd/ecsm-cli/cmd/completion.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout 限制补全时查询 ECSM 平台或 Registry 的时间，避免按下 Tab 后 shell 长时间无响应
+const completionTimeout = 5 * time.Second
+
+// newCompletionCmd 创建 "completion" 命令
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Generate the shell completion script",
+		Long: `Prints the completion script for bash, zsh or fish. Besides commands and flags
+the script completes the names of nodes, services, containers and images by
+querying the ECSM platform, and the names of ECSMServices by reading the
+registry database, using the connection settings of the current context.
+
+To load completions in the current bash session:
+
+  source <(ecsm-cli completion bash)
+
+To load them for every zsh session:
+
+  ecsm-cli completion zsh > "${fpath[1]}/_ecsm-cli"
+
+To load them for every fish session:
+
+  ecsm-cli completion fish > ~/.config/fish/completions/ecsm-cli.fish`,
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return rootCmd.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return rootCmd.GenZshCompletion(os.Stdout)
+			case "fish":
+				return rootCmd.GenFishCompletion(os.Stdout, true)
+			}
+			return fmt.Errorf("unsupported shell %q", args[0])
+		},
+	}
+}
+
+// completeNames 返回一个补全第一个位置参数的函数，候选项由 list 查询得到。
+// 补全失败时不返回任何候选项，错误只在设置了 BASH_COMP_DEBUG_FILE 时记录。
+func completeNames(list func(ctx context.Context, cmd *cobra.Command) ([]string, error)) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterNames(cmd, toComplete, list), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFlagValues 返回一个补全标志值的函数，候选项由 list 查询得到。
+func completeFlagValues(list func(ctx context.Context, cmd *cobra.Command) ([]string, error)) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return filterNames(cmd, toComplete, list), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeServiceArgs 补全 "service SERVICE_NAME" 形式的参数，第二个参数是 Registry 中的 ECSMService 名称。
+func completeServiceArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return []cobra.Completion{"service"}, cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return filterNames(cmd, toComplete, listECSMServiceNames), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// filterNames 调用 list 并返回以 toComplete 开头的名称。
+func filterNames(cmd *cobra.Command, toComplete string, list func(ctx context.Context, cmd *cobra.Command) ([]string, error)) []cobra.Completion {
+	// 补全请求在解析 --config 和 --context 之前就初始化了配置，这里按照解析后的标志重新初始化
+	initConfig()
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	names, err := list(ctx, cmd)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil
+	}
+
+	var completions []cobra.Completion
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name)
+		}
+	}
+	return completions
+}
+
+// listNodeNames 返回 ECSM 平台上所有节点的名称。
+func listNodeNames(ctx context.Context, cmd *cobra.Command) ([]string, error) {
+	cs, err := util.NewClientsetFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := cs.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names, nil
+}
+
+// listServiceNames 返回 ECSM 平台上所有服务的名称。
+func listServiceNames(ctx context.Context, cmd *cobra.Command) ([]string, error) {
+	cs, err := util.NewClientsetFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	services, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(services))
+	for _, service := range services {
+		names = append(names, service.Name)
+	}
+	return names, nil
+}
+
+// listContainerNames 返回 ECSM 平台上所有容器的名称。
+func listContainerNames(ctx context.Context, cmd *cobra.Command) ([]string, error) {
+	cs, err := util.NewClientsetFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := listContainers(ctx, cs, "", "")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(containers))
+	for _, container := range containers {
+		names = append(names, container.Name)
+	}
+	return names, nil
+}
+
+// listImageRefs 以 NAME@TAG#OS 的形式返回 --registry-id 指定的仓库中的镜像。
+func listImageRefs(ctx context.Context, cmd *cobra.Command) ([]string, error) {
+	registryID, err := cmd.Flags().GetString("registry-id")
+	if err != nil {
+		return nil, err
+	}
+	cs, err := util.NewClientsetFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	images, err := cs.Images().ListAll(ctx, clientset.ImageListOptions{RegistryID: registryID})
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]string, 0, len(images))
+	for _, image := range images {
+		refs = append(refs, fmt.Sprintf("%s@%s#%s", image.Name, image.Tag, image.OS))
+	}
+	return refs, nil
+}
+
+// listECSMServiceNames 返回 Registry 中 --namespace 指定的命名空间下的 ECSMService 名称。
+func listECSMServiceNames(ctx context.Context, cmd *cobra.Command) ([]string, error) {
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return nil, err
+	}
+	reg, closeFn, err := util.NewRegistryFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	defer closeFn()
+
+	list, _, err := reg.ListAllServices(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, service := range list.Items {
+		names = append(names, service.Name)
+	}
+	return names, nil
+}
+
+// listContextNames 返回配置文件中的上下文名称。
+func listContextNames(ctx context.Context, cmd *cobra.Command) ([]string, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+	config, err := util.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return config.ContextNames(), nil
+}
@@ -0,0 +1,244 @@
+// file: cmd/ecsm-cli/cmd/config.go
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"github.com/spf13/cobra"
+)
+
+// newConfigCmd 创建 "config" 命令
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config SUBCOMMAND",
+		Short: "Manage the contexts in the ecsm-cli config file",
+		Long: `Manages the named contexts in the ecsm-cli config file. A context holds the
+connection settings of one ECSM cluster: host, port, protocol, credentials and
+the registry database of its operator.
+
+The current context is used unless another one is selected with --context.
+Command line flags and ECSMCLI_* environment variables override the settings
+of the context, which in turn override the top-level settings of the config
+file.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newConfigGetContextsCmd())
+	cmd.AddCommand(newConfigUseContextCmd())
+	cmd.AddCommand(newConfigSetContextCmd())
+	return cmd
+}
+
+// newConfigGetContextsCmd 创建 "config get-contexts" 子命令
+func newConfigGetContextsCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List the contexts in the config file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && output != util.OutputName {
+				return fmt.Errorf("unsupported output format %q, must be %s", output, util.OutputName)
+			}
+			path, err := configFilePath()
+			if err != nil {
+				return err
+			}
+			config, err := util.LoadConfig(path)
+			if err != nil {
+				return err
+			}
+
+			names := config.ContextNames()
+			if output == util.OutputName {
+				for _, name := range names {
+					fmt.Fprintln(os.Stdout, name)
+				}
+				return nil
+			}
+			if len(names) == 0 {
+				fmt.Fprintln(os.Stdout, "No contexts found.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			defer w.Flush()
+			fmt.Fprintln(w, "CURRENT\tNAME\tSERVER\tUSERNAME\tREGISTRY-DB")
+			for _, name := range names {
+				ctx := config.Contexts[name]
+				current := ""
+				if name == config.CurrentContext {
+					current = "*"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", current, name, contextServer(ctx), ctx.Username, ctx.RegistryDB)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. Only 'name' is supported")
+	return cmd
+}
+
+// contextServer 返回上下文的服务器地址，没有设置的部分显示为标志的默认值
+func contextServer(ctx *util.Context) string {
+	protocol, host, port := ctx.Protocol, ctx.Host, ctx.Port.String()
+	if protocol == "" {
+		protocol = rootCmd.PersistentFlags().Lookup("protocol").DefValue
+	}
+	if host == "" {
+		host = rootCmd.PersistentFlags().Lookup("host").DefValue
+	}
+	if port == "" {
+		port = rootCmd.PersistentFlags().Lookup("port").DefValue
+	}
+	return fmt.Sprintf("%s://%s:%s", protocol, host, port)
+}
+
+// newConfigUseContextCmd 创建 "config use-context" 子命令
+func newConfigUseContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "use-context CONTEXT_NAME",
+		Short:             "Set the current context in the config file",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listContextNames),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			path, err := configFilePath()
+			if err != nil {
+				return err
+			}
+			config, err := util.LoadConfig(path)
+			if err != nil {
+				return err
+			}
+			if _, ok := config.Contexts[name]; !ok {
+				return fmt.Errorf("context %q not found in config file %s", name, path)
+			}
+
+			config.CurrentContext = name
+			if err := util.SaveConfig(path, config); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Switched to context %q.\n", name)
+			return nil
+		},
+	}
+}
+
+// newConfigSetContextCmd 创建 "config set-context" 子命令
+func newConfigSetContextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-context CONTEXT_NAME [--host=HOST] [--port=PORT] [--protocol=PROTOCOL] [--username=USERNAME] [--password=PASSWORD] [--auth-mode=MODE] [--server=URL] [--registry-db=PATH] [--encryption-key-file=PATH] [--certificate-authority=PATH] [--client-certificate=PATH] [--client-key=PATH] [--tls-server-name=NAME] [--insecure-skip-tls-verify] [--proxy-url=URL] [--api-version=VERSION]",
+		Short: "Create or modify a context in the config file",
+		Long: `Creates a context in the config file, or modifies an existing one. Only the
+settings given on the command line are changed; pass an empty value, for
+example --registry-db="", to remove a setting from the context.
+
+The global --host, --port, --protocol, --server, --registry-db, --encryption-key-file,
+--proxy-url, --api-version and TLS flags are saved into the context instead
+of being used for this command. Certificate paths are saved as absolute paths so that the context
+works from any directory. The
+config file is written with permissions 0600 because it may contain
+credentials.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listContextNames),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			path, err := configFilePath()
+			if err != nil {
+				return err
+			}
+			config, err := util.LoadConfig(path)
+			if err != nil {
+				return err
+			}
+
+			ctx, exists := config.Contexts[name]
+			if !exists {
+				ctx = &util.Context{}
+			}
+			flags := cmd.Flags()
+			fields := map[string]*string{
+				"host":                  &ctx.Host,
+				"protocol":              &ctx.Protocol,
+				"username":              &ctx.Username,
+				"password":              &ctx.Password,
+				"auth-mode":             &ctx.AuthMode,
+				"server":                &ctx.Server,
+				"registry-db":           &ctx.RegistryDB,
+				"encryption-key-file":   &ctx.EncryptionKeyFile,
+				"certificate-authority": &ctx.CertificateAuthority,
+				"client-certificate":    &ctx.ClientCertificate,
+				"client-key":            &ctx.ClientKey,
+				"tls-server-name":       &ctx.TLSServerName,
+				"proxy-url":             &ctx.ProxyURL,
+				"api-version":           &ctx.APIVersion,
+			}
+			for key, field := range fields {
+				if flags.Changed(key) {
+					*field, _ = flags.GetString(key)
+				}
+			}
+			if flags.Changed("port") {
+				port, _ := flags.GetString("port")
+				if _, err := strconv.ParseUint(port, 10, 16); port != "" && err != nil {
+					return fmt.Errorf("invalid port %q", port)
+				}
+				ctx.Port = json.Number(port)
+			}
+			for _, field := range []*string{&ctx.CertificateAuthority, &ctx.ClientCertificate, &ctx.ClientKey} {
+				if *field == "" {
+					continue
+				}
+				if *field, err = filepath.Abs(*field); err != nil {
+					return err
+				}
+			}
+			if flags.Changed("insecure-skip-tls-verify") {
+				ctx.InsecureSkipTLSVerify, _ = flags.GetBool("insecure-skip-tls-verify")
+			}
+			if ctx.Protocol != "" && ctx.Protocol != "http" && ctx.Protocol != "https" {
+				return fmt.Errorf("unsupported protocol %q, must be http or https", ctx.Protocol)
+			}
+			if _, err := rest.ProxyURL(ctx.ProxyURL); err != nil {
+				return err
+			}
+			if ctx.AuthMode != "" && !slices.Contains(util.AuthModes, ctx.AuthMode) {
+				return fmt.Errorf("unsupported auth mode %q, must be one of %v", ctx.AuthMode, util.AuthModes)
+			}
+
+			if config.Contexts == nil {
+				config.Contexts = map[string]*util.Context{}
+			}
+			config.Contexts[name] = ctx
+			if err := util.SaveConfig(path, config); err != nil {
+				return err
+			}
+			if exists {
+				fmt.Fprintf(os.Stdout, "Context %q modified.\n", name)
+			} else {
+				fmt.Fprintf(os.Stdout, "Context %q created.\n", name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("username", "", "The username used to authenticate to the ECSM API server")
+	cmd.Flags().String("password", "", "The password used to authenticate to the ECSM API server")
+	cmd.Flags().String("auth-mode", "", "How requests are authenticated with the username and password when not logged in: session (log in and send the session token, the default) or basic (send them with every request)")
+	cmd.RegisterFlagCompletionFunc("auth-mode", cobra.FixedCompletions(util.AuthModes, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,75 @@
+// file: cmd/ecsm-cli/cmd/configs.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// newGetConfigsCmd 创建 "get configs" 子命令，它从 operator 的 Registry 中读取 ECSMConfig
+func newGetConfigsCmd() *cobra.Command {
+	var (
+		namespace     string
+		allNamespaces bool
+		output        *string
+		sortBy        *string
+		tableFlags    *util.TableFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "configs",
+		Short: "Display a list of ECSMConfigs",
+		Long: `Lists the ECSMConfigs that container templates can reference through envFrom,
+env valueFrom and volume mount hostPathFrom. Configs are read from the operator's
+API server given with --server, or from its registry database given with
+--registry-db while the operator is stopped.`,
+		Aliases: []string{"config", "cfg"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "ecsmconfig")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			if allNamespaces {
+				namespace = ""
+			}
+
+			reg, closeFn, err := util.NewRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			list, _, err := reg.ListConfigs(context.Background(), namespace)
+			if err != nil {
+				return fmt.Errorf("failed to list configs: %w", err)
+			}
+
+			if len(list.Items) == 0 && util.IsTableOutput(*output) {
+				fmt.Fprintln(os.Stdout, "No configs found.")
+				return nil
+			}
+			if err := sorter.Sort(list.Items); err != nil {
+				return err
+			}
+			return printer.PrintObj(list.Items, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the configs to list")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List configs across all namespaces")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	return cmd
+}
@@ -0,0 +1,121 @@
+// file: cmd/ecsm-cli/cmd/container.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+)
+
+// controlOptions 是控制容器的命令共用的标志
+type controlOptions struct {
+	noWait  bool
+	timeout time.Duration
+}
+
+// addFlags 把控制容器的标志添加到 cmd
+func (o *controlOptions) addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&o.noWait, "no-wait", false, "Return after the action has been submitted instead of waiting for its transaction")
+	cmd.PersistentFlags().DurationVar(&o.timeout, "timeout", 2*time.Minute, "How long to wait for the transaction of the action")
+}
+
+// containerActions 是可以对容器执行的动作及其完成后的描述
+var containerActions = []struct {
+	action clientset.ContainerAction
+	done   string
+	short  string
+}{
+	{clientset.ActionStart, "started", "Start containers"},
+	{clientset.ActionStop, "stopped", "Stop containers"},
+	{clientset.ActionRestart, "restarted", "Restart containers"},
+	{clientset.ActionPause, "paused", "Pause containers"},
+	{clientset.ActionUnpause, "unpaused", "Unpause paused containers"},
+}
+
+// newContainerCmd 创建 "container" 命令，它启动、停止、重启和暂停 ECSM 平台上的容器
+func newContainerCmd() *cobra.Command {
+	var opts controlOptions
+
+	cmd := &cobra.Command{
+		Use:     "container",
+		Short:   "Start, stop, restart and pause containers",
+		Aliases: []string{"co"},
+		Long: `Controls containers of the ECSM platform by name. Every action runs as a
+transaction on the platform; the command waits until the transaction has
+finished, showing a spinner on standard error if it is a terminal, and fails
+if the transaction fails. With --no-wait it returns right after submitting
+the action.`,
+	}
+	opts.addFlags(cmd)
+
+	for _, a := range containerActions {
+		action, done := a.action, a.done
+		cmd.AddCommand(&cobra.Command{
+			Use:   string(action) + " <CONTAINER_NAME>...",
+			Short: a.short,
+			Args:  cobra.MinimumNArgs(1),
+			ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+				return filterNames(cmd, toComplete, listContainerNames), cobra.ShellCompDirectiveNoFileComp
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cs, err := util.NewClientsetFromFlags()
+				if err != nil {
+					return err
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+
+				var errs []error
+				for _, name := range args {
+					err := runControlAction(ctx, cs, "container/"+name, action, done, opts, func() (*clientset.Transaction, error) {
+						return cs.Containers().SubmitControlActionByName(ctx, name, action)
+					})
+					if err != nil {
+						errs = append(errs, err)
+					}
+				}
+				if ctx.Err() != nil {
+					// 用户中断了等待，已经提交的动作仍会在平台上执行
+					return nil
+				}
+				return errors.Join(errs...)
+			},
+		})
+	}
+	return cmd
+}
+
+// runControlAction 提交一个控制动作并等待它的事务结束。ref 是输出中的对象，例如 "container/NAME"。
+func runControlAction(ctx context.Context, cs clientset.Interface, ref string, action clientset.ContainerAction, done string,
+	opts controlOptions, submit func() (*clientset.Transaction, error)) error {
+	tx, err := submit()
+	if err != nil {
+		return fmt.Errorf("failed to %s %s: %w", action, ref, err)
+	}
+	if opts.noWait {
+		fmt.Fprintf(os.Stdout, "%s %s requested (transaction %s)\n", ref, action, tx.ID)
+		return nil
+	}
+
+	var spinnerOut io.Writer
+	if util.IsTerminal(os.Stderr) {
+		spinnerOut = os.Stderr
+	}
+	spinner := util.StartSpinner(fmt.Sprintf("Waiting for %s to be %s (transaction %s)", ref, done, tx.ID), spinnerOut)
+	err = util.WaitForTransaction(ctx, cs, tx.ID, opts.timeout)
+	spinner.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to %s %s: %w", action, ref, err)
+	}
+	fmt.Fprintf(os.Stdout, "%s %s\n", ref, done)
+	return nil
+}
@@ -0,0 +1,201 @@
+// file: cmd/ecsm-cli/cmd/convert.go
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/convert"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// newConvertCmd 创建 "convert" 命令，它把 Kubernetes Deployment 清单和旧版本的 ecsm.sh 清单转换为指定版本的清单
+func newConvertCmd() *cobra.Command {
+	var (
+		filename      string
+		output        string
+		outputVersion string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "convert -f FILENAME [--output-version VERSION]",
+		Short: "Convert manifests between API versions or from Kubernetes Deployments",
+		Long: `Converts the manifests in a YAML or JSON file, or in all .yaml, .yml and .json
+files of a directory, to the API version given by --output-version (ecsm.sh/v1 by
+default) and prints them. Use "-f -" to read from standard input.
+
+Manifests of any served ecsm.sh version, such as ecsm.sh/v1alpha1, are converted
+with the same conversions the API server uses, so manifests can be migrated when
+the API evolves. Unknown fields are dropped with a warning on standard error.
+
+Kubernetes apps/v1 Deployments are converted into ECSMServices, easing the
+migration of existing workloads to ECSM edge clusters. Replicas, the image,
+command, environment, memory and ephemeral-storage limits, hostPath volumes,
+tcpSocket and exec probes, node selectors and tolerations are converted.
+ConfigMap and Secret references become references to the ECSMConfig and
+ECSMSecret with the same name. Fields that ECSM cannot express are dropped with a
+warning on standard error.
+
+Nothing is written to the registry.`,
+		Example: `  # Rewrite v1alpha1 manifests to v1
+  ecsm-cli convert -f service-v1alpha1.yaml > service.yaml
+
+  # Convert a Kubernetes Deployment into an ECSMService
+  kubectl get deployment web -o yaml | ecsm-cli convert -f -`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return fmt.Errorf("-f must be specified")
+			}
+			if output != "yaml" && output != "json" {
+				return fmt.Errorf("unsupported output format %q, must be yaml or json", output)
+			}
+			gv, err := parseOutputVersion(outputVersion)
+			if err != nil {
+				return err
+			}
+
+			manifests, err := util.ReadManifests(filename)
+			if err != nil {
+				return err
+			}
+			for i := range manifests {
+				obj, err := convertManifest(&manifests[i], gv)
+				if err != nil {
+					return err
+				}
+				if err := printConverted(obj, output, i > 0); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "The file or directory that contains the manifests to convert")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format, one of yaml or json")
+	cmd.Flags().StringVar(&outputVersion, "output-version", ecsmv1.SchemeGroupVersion.String(), "The ecsm.sh API version to convert the manifests to")
+	cmd.RegisterFlagCompletionFunc("output-version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var versions []string
+		for _, gv := range util.ManifestVersions() {
+			versions = append(versions, gv.String())
+		}
+		return versions, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+// parseOutputVersion 解析 --output-version，只接受 ecsm.sh 组的版本，可以省略组名。
+func parseOutputVersion(version string) (schema.GroupVersion, error) {
+	if !strings.Contains(version, "/") {
+		version = ecsmv1.GroupName + "/" + version
+	}
+	gv, err := schema.ParseGroupVersion(version)
+	if err != nil {
+		return schema.GroupVersion{}, fmt.Errorf("invalid --output-version %q: %w", version, err)
+	}
+	versions := util.ManifestVersions()
+	if !slices.Contains(versions, gv) {
+		return schema.GroupVersion{}, fmt.Errorf("unsupported --output-version %q, must be one of %v", version, versions)
+	}
+	return gv, nil
+}
+
+// convertManifest 把一个清单转换为 gv 版本的对象，Deployment 先被转换为 ECSMService。
+func convertManifest(m *util.Manifest, gv schema.GroupVersion) (runtime.Object, error) {
+	var obj runtime.Object
+	if m.GroupVersionKind() == appsv1.SchemeGroupVersion.WithKind("Deployment") {
+		service, err := convertDeployment(m.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Source, err)
+		}
+		obj = service
+	} else {
+		if !strings.HasPrefix(m.APIVersion, ecsmv1.GroupName+"/") {
+			return nil, fmt.Errorf("%s: unsupported object %s %s, only ecsm.sh objects and apps/v1 Deployments can be converted", m.Source, m.APIVersion, m.Kind)
+		}
+		decoded, strictErrs, err := m.DecodeObject()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range strictErrs {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s %s: %v\n", m.Source, m.Kind, manifestName(m), e)
+		}
+		obj = decoded
+	}
+
+	out, err := util.ConvertToVersion(obj, gv)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.Source, err)
+	}
+	return out, nil
+}
+
+// manifestName 返回清单中的 metadata.name，用于警告信息。
+func manifestName(m *util.Manifest) string {
+	var obj struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}
+	json.Unmarshal(m.Raw, &obj)
+	return obj.Metadata.Name
+}
+
+// convertDeployment 把一个 Deployment 文档转换为 ECSMService，无法转换的字段以警告输出。
+func convertDeployment(raw []byte) (*ecsmv1.ECSMService, error) {
+	deployment := &appsv1.Deployment{}
+	if err := json.Unmarshal(raw, deployment); err != nil {
+		return nil, fmt.Errorf("invalid Deployment: %w", err)
+	}
+
+	service, warnings, err := convert.Deployment(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Deployment %s: %w", deployment.Name, err)
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: Deployment %s: %s\n", deployment.Name, w)
+	}
+	return service, nil
+}
+
+// printConverted 打印转换的结果，separate 为 true 时在 YAML 文档之前输出分隔符。
+func printConverted(obj runtime.Object, output string, separate bool) error {
+	// 清单中不应该有 status 和 creationTimestamp
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return err
+	}
+	delete(manifest, "status")
+	if metadata, ok := manifest["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+
+	if output == "json" {
+		data, err = json.MarshalIndent(manifest, "", "    ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(manifest)
+		if separate {
+			data = append([]byte("---\n"), data...)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
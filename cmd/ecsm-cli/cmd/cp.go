@@ -0,0 +1,241 @@
+// file: cmd/ecsm-cli/cmd/cp.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ftp"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// cpOptions 是 cp 命令的标志
+type cpOptions struct {
+	address  string
+	port     int
+	user     string
+	password string
+	timeout  time.Duration
+	quiet    bool
+}
+
+// newCpCmd 创建 "cp" 命令，它通过容器的 FTPD 服务器在本地和容器之间复制文件
+func newCpCmd() *cobra.Command {
+	var opts cpOptions
+
+	cmd := &cobra.Command{
+		Use:   "cp <SRC> <DEST>",
+		Short: "Copy files to and from SylixOS containers",
+		Long: `Copies a file between the local machine and a SylixOS container, using the
+FTPD server of the container. One of SRC and DEST is a local file, "-" for
+standard input or output, and the other one is CONTAINER_NAME:PATH. When PATH
+ends with "/" or the local DEST is a directory, the file keeps its name.
+
+The FTPD server must be enabled in the SylixOS network config of the service
+(spec.template.sylixos.network.ftpd of an ECSMService). The command connects
+to the address of the node running the container, or to --address, and logs in
+as --ftp-user. The password is read from the terminal, or from the first line
+of standard input when it is not a terminal, unless --ftp-password is given.
+While uploading, the progress is shown on standard error if it is a terminal.`,
+		Example: `  # Upload a file into the /apps directory of container web-0
+  ecsm-cli cp app.bin web-0:/apps/
+
+  # Download a file from container web-0
+  ecsm-cli cp web-0:/etc/app.conf ./app.conf`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			// 包含 ":" 时已经在输入容器中的路径，否则同时可能是本地文件
+			if len(args) >= 2 || strings.Contains(toComplete, ":") {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			var completions []cobra.Completion
+			for _, name := range filterNames(cmd, toComplete, listContainerNames) {
+				completions = append(completions, name+":")
+			}
+			return completions, cobra.ShellCompDirectiveNoSpace
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srcContainer, srcPath, srcRemote := parseCopySpec(args[0])
+			destContainer, destPath, destRemote := parseCopySpec(args[1])
+			switch {
+			case srcRemote == destRemote:
+				return fmt.Errorf("exactly one of SRC and DEST must be CONTAINER_NAME:PATH")
+			case srcRemote && srcPath == "", destRemote && destPath == "":
+				return fmt.Errorf("the path in the container must not be empty")
+			}
+
+			if !cmd.Flags().Changed("ftp-password") {
+				// 标准输入是要上传的文件时不能从中读取密码
+				if !srcRemote && srcPath == "-" {
+					return fmt.Errorf("--ftp-password must be specified when uploading from standard input")
+				}
+				password, err := util.ReadPassword(fmt.Sprintf("FTP password for %s: ", opts.user))
+				if err != nil {
+					return err
+				}
+				opts.password = password
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+			if srcRemote {
+				return downloadFile(ctx, srcContainer, srcPath, destPath, opts)
+			}
+			return uploadFile(ctx, srcPath, destContainer, destPath, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.address, "address", "", "The address of the FTPD server (default is the address of the node running the container)")
+	cmd.Flags().IntVar(&opts.port, "ftp-port", 21, "The port of the FTPD server")
+	cmd.Flags().StringVar(&opts.user, "ftp-user", "root", "The user to log in to the FTPD server as")
+	cmd.Flags().StringVar(&opts.password, "ftp-password", "", "The password of --ftp-user (read from the terminal if not set)")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 30*time.Second, "How long to wait for connections to the FTPD server")
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Do not show the upload progress")
+	return cmd
+}
+
+// parseCopySpec 解析 cp 的参数，CONTAINER_NAME:PATH 形式的参数返回 remote 为 true。
+// 冒号之前有 "/" 或 "\" 时是本地路径，例如 "./a:b" 和 "C:\a"。
+func parseCopySpec(arg string) (container, path string, remote bool) {
+	name, p, ok := strings.Cut(arg, ":")
+	if !ok || name == "" || strings.ContainsAny(name, `/\`) || (len(name) == 1 && strings.HasPrefix(p, `\`)) {
+		return "", arg, false
+	}
+	return name, p, true
+}
+
+// uploadFile 把本地文件 src 上传到容器中的 dest。
+func uploadFile(ctx context.Context, src, container, dest string, opts cpOptions) error {
+	var in io.Reader = os.Stdin
+	var size int64
+	if src != "-" {
+		f, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory, only files can be copied", src)
+		}
+		in, size = f, info.Size()
+		if strings.HasSuffix(dest, "/") {
+			dest += filepath.Base(src)
+		}
+	} else if strings.HasSuffix(dest, "/") {
+		return fmt.Errorf("a file name is needed in the container when copying from standard input")
+	}
+
+	conn, err := dialContainerFTP(ctx, container, opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// 进度在同一行上刷新，只在终端上显示
+	var progressOut io.Writer
+	if !opts.quiet && src != "-" && util.IsTerminal(os.Stderr) {
+		progressOut = os.Stderr
+	}
+	progress := util.NewProgressReader(in, size, "Copying "+filepath.Base(src)+" to "+container, progressOut)
+	err = conn.Stor(dest, progress)
+	progress.Done()
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s:%s: %w", src, container, dest, err)
+	}
+	return conn.Quit()
+}
+
+// downloadFile 把容器中的 src 下载到本地文件 dest。下载失败时删除不完整的文件。
+func downloadFile(ctx context.Context, container, src, dest string, opts cpOptions) error {
+	if strings.HasSuffix(src, "/") {
+		return fmt.Errorf("%s:%s is a directory, only files can be copied", container, src)
+	}
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		dest = filepath.Join(dest, path.Base(src))
+	}
+
+	conn, err := dialContainerFTP(ctx, container, opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var out io.Writer = os.Stdout
+	if dest != "-" {
+		f, err := os.Create(dest)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := conn.Retr(src, out); err != nil {
+		if dest != "-" {
+			os.Remove(dest)
+		}
+		return fmt.Errorf("failed to copy %s:%s to %s: %w", container, src, dest, err)
+	}
+	return conn.Quit()
+}
+
+// dialContainerFTP 连接到容器的 FTPD 服务器并登录。
+func dialContainerFTP(ctx context.Context, name string, opts cpOptions) (*ftp.Conn, error) {
+	address := opts.address
+	if address == "" {
+		cs, err := util.NewClientsetFromFlags()
+		if err != nil {
+			return nil, err
+		}
+		container, err := cs.Containers().GetByName(ctx, cs.Services(), name)
+		if err != nil {
+			return nil, err
+		}
+		if !serviceFTPDEnabled(ctx, cs, container.ServiceID) {
+			return nil, fmt.Errorf("the FTPD server of container %s is not enabled, enable it in the SylixOS network config of service %s", name, container.ServiceName)
+		}
+		address = container.Address
+	}
+
+	addr := net.JoinHostPort(address, strconv.Itoa(opts.port))
+	klog.V(4).Infof("Connecting to the FTPD server of container %s at %s", name, addr)
+	conn, err := ftp.Dial(ctx, addr, opts.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the FTPD server of container %s at %s: %w", name, addr, err)
+	}
+	if err := conn.Login(opts.user, opts.password); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to log in to the FTPD server of container %s as %s: %w", name, opts.user, err)
+	}
+	return conn, nil
+}
+
+// serviceFTPDEnabled 返回服务的 SylixOS 网络配置是否启动了 FTPD 服务器，无法确定时返回 true。
+func serviceFTPDEnabled(ctx context.Context, cs clientset.Interface, serviceID string) bool {
+	service, err := cs.Services().Get(ctx, serviceID)
+	if err != nil {
+		klog.V(2).Infof("Could not get service %s to check its FTPD config: %v", serviceID, err)
+		return true
+	}
+	if service.Image == nil || service.Image.Config == nil || service.Image.Config.SylixOS == nil {
+		return true
+	}
+	network := service.Image.Config.SylixOS.Network
+	return network != nil && network.FtpdEnable
+}
@@ -0,0 +1,468 @@
+// file: cmd/ecsm-cli/cmd/dash.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+)
+
+// dashLogTail 是面板中查看日志时显示的最近日志条数
+const dashLogTail = 500
+
+// newDashCmd 创建 "dash" 命令，它在终端中显示一个实时刷新的面板
+func newDashCmd() *cobra.Command {
+	var (
+		interval time.Duration
+		timeout  time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:     "dash",
+		Short:   "Show an interactive dashboard of services, containers and nodes",
+		Aliases: []string{"dashboard"},
+		Long: `Shows a full screen terminal dashboard with the services, containers and
+nodes of the ECSM platform. The visible list is refreshed every --interval.
+
+Keys:
+  1, 2, 3, Tab   switch between services, containers and nodes
+  Up, Down, j, k select a row
+  Enter, d       describe the selected object
+  l              show the latest logs of the selected container
+  r              restart the selected service or container
+  Esc            close the description or the logs
+  q, Ctrl+C      quit`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			if !util.IsTerminal(os.Stdout) {
+				return fmt.Errorf("dash requires a terminal")
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+			return newDashboard(cs, interval, timeout).run(ctx)
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "How often the visible list is refreshed")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "How long to wait for the transaction of a restart")
+	return cmd
+}
+
+// dashRow 是面板表格中的一行
+type dashRow struct {
+	// name 是对象的名称，id 是控制和查询它时使用的 ID (服务和节点的 ID，容器的 Task ID)
+	name  string
+	id    string
+	cells []string
+}
+
+// dashView 是面板中的一个列表
+type dashView struct {
+	title  string
+	kind   string
+	header []string
+	table  *tview.Table
+	fetch  func(ctx context.Context) ([]dashRow, error)
+	rows   []dashRow
+}
+
+// dashboard 是 "dash" 命令的终端界面
+type dashboard struct {
+	cs       clientset.Interface
+	interval time.Duration
+	timeout  time.Duration
+
+	app    *tview.Application
+	pages  *tview.Pages
+	header *tview.TextView
+	footer *tview.TextView
+	views  []*dashView
+
+	// mu 保护 current，它在刷新的 goroutine 中也会被读取
+	mu      sync.Mutex
+	current int
+	// refresh 通知刷新的 goroutine 立即刷新当前列表
+	refresh chan struct{}
+}
+
+func newDashboard(cs clientset.Interface, interval, timeout time.Duration) *dashboard {
+	d := &dashboard{
+		cs:       cs,
+		interval: interval,
+		timeout:  timeout,
+		app:      tview.NewApplication(),
+		pages:    tview.NewPages(),
+		header:   tview.NewTextView().SetDynamicColors(true),
+		footer:   tview.NewTextView().SetDynamicColors(true),
+		refresh:  make(chan struct{}, 1),
+	}
+	d.views = []*dashView{
+		{title: "Services", kind: "service", header: []string{"NAME", "STATUS", "POLICY", "ONLINE", "DESIRED", "IMAGE"}, fetch: d.fetchServices},
+		{title: "Containers", kind: "container", header: []string{"NAME", "STATUS", "RESTARTS", "SERVICE", "NODE", "CPU(%)", "MEMORY(MiB)"}, fetch: d.fetchContainers},
+		{title: "Nodes", kind: "node", header: []string{"NAME", "STATUS", "ADDRESS", "CPU(%)", "MEMORY(%)", "DISK(%)", "CONTAINERS"}, fetch: d.fetchNodes},
+	}
+	for _, v := range d.views {
+		v.table = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+		v.table.SetBorder(true).SetTitle(" " + v.title + " ")
+		d.fillTable(v, nil)
+		d.pages.AddPage(v.title, v.table, true, false)
+	}
+	return d
+}
+
+// run 显示面板直到用户退出或 ctx 被取消。
+func (d *dashboard) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.header, 1, 0, false).
+		AddItem(d.pages, 0, 1, true).
+		AddItem(d.footer, 1, 0, false)
+	d.app.SetRoot(layout, true).SetInputCapture(d.handleKey)
+	d.switchTo(0)
+
+	go func() {
+		<-ctx.Done()
+		d.app.Stop()
+	}()
+	go d.refreshLoop(ctx)
+	return d.app.Run()
+}
+
+// refreshLoop 每隔 interval 或在收到 refresh 通知时刷新当前显示的列表。
+func (d *dashboard) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.mu.Lock()
+		v := d.views[d.current]
+		d.mu.Unlock()
+
+		rows, err := v.fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		d.app.QueueUpdateDraw(func() {
+			if err != nil {
+				d.setStatus(fmt.Sprintf("[red]Failed to list %ss: %s", v.kind, tview.Escape(err.Error())))
+				return
+			}
+			d.fillTable(v, rows)
+			d.setStatus(fmt.Sprintf("Updated %s", time.Now().Format("15:04:05")))
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.refresh:
+		}
+	}
+}
+
+// fillTable 用 rows 重新填充 v 的表格，保持之前选中的对象不变。
+func (d *dashboard) fillTable(v *dashView, rows []dashRow) {
+	selected := ""
+	if row, ok := v.selected(); ok {
+		selected = row.name
+	}
+
+	v.rows = rows
+	v.table.Clear()
+	for col, title := range v.header {
+		v.table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false).SetExpansion(1))
+	}
+	selectRow := 1
+	for i, row := range rows {
+		for col, text := range row.cells {
+			v.table.SetCell(i+1, col, tview.NewTableCell(tview.Escape(text)).SetExpansion(1))
+		}
+		if row.name == selected {
+			selectRow = i + 1
+		}
+	}
+	if len(rows) > 0 {
+		v.table.Select(selectRow, 0)
+	}
+}
+
+// selected 返回表格中选中的行。
+func (v *dashView) selected() (dashRow, bool) {
+	row, _ := v.table.GetSelection()
+	if row < 1 || row > len(v.rows) {
+		return dashRow{}, false
+	}
+	return v.rows[row-1], true
+}
+
+// handleKey 处理主界面的按键，查看详情和日志时的按键由 showText 处理。
+func (d *dashboard) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if front, _ := d.pages.GetFrontPage(); front != d.views[d.currentView()].title {
+		return event
+	}
+	switch event.Key() {
+	case tcell.KeyTab:
+		d.switchTo((d.currentView() + 1) % len(d.views))
+		return nil
+	case tcell.KeyEnter:
+		d.describeSelected()
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch event.Rune() {
+	case '1', '2', '3':
+		d.switchTo(int(event.Rune() - '1'))
+	case 'd':
+		d.describeSelected()
+	case 'l':
+		d.showLogsOfSelected()
+	case 'r':
+		d.confirmRestart()
+	case 'q':
+		d.app.Stop()
+	default:
+		return event
+	}
+	return nil
+}
+
+func (d *dashboard) currentView() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.current
+}
+
+// switchTo 显示第 i 个列表并立即刷新它。
+func (d *dashboard) switchTo(i int) {
+	d.mu.Lock()
+	d.current = i
+	d.mu.Unlock()
+
+	d.pages.SwitchToPage(d.views[i].title)
+	tabs := make([]string, len(d.views))
+	for j, v := range d.views {
+		if j == i {
+			tabs[j] = fmt.Sprintf("[black:aqua] %d %s [-:-]", j+1, v.title)
+		} else {
+			tabs[j] = fmt.Sprintf(" %d %s ", j+1, v.title)
+		}
+	}
+	d.header.SetText(" [::b]ECSM[::-] " + strings.Join(tabs, " "))
+	select {
+	case d.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// setStatus 在底部显示 status 和按键提示，必须在界面的 goroutine 中调用。
+func (d *dashboard) setStatus(status string) {
+	d.footer.SetText(" " + status + "[-]  [gray]Enter:describe l:logs r:restart Tab:switch q:quit")
+}
+
+// describeSelected 在一个可以滚动的页面中显示选中对象的 describe 输出。
+func (d *dashboard) describeSelected() {
+	v := d.views[d.currentView()]
+	row, ok := v.selected()
+	if !ok {
+		return
+	}
+	d.showText(fmt.Sprintf("%s/%s", v.kind, row.name), func(ctx context.Context) (string, error) {
+		var description interface{}
+		var err error
+		switch v.kind {
+		case "service":
+			description, err = fetchServiceDescription(ctx, d.cs, row.id)
+		case "container":
+			description, err = fetchContainerDescription(ctx, d.cs, row.name)
+		case "node":
+			description, err = fetchNodeDescription(ctx, d.cs, row.id)
+		}
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		err = (&util.TablePrinter{}).PrintObj(description, &buf)
+		return buf.String(), err
+	})
+}
+
+// showLogsOfSelected 显示选中容器最近的日志。
+func (d *dashboard) showLogsOfSelected() {
+	v := d.views[d.currentView()]
+	row, ok := v.selected()
+	if !ok || v.kind != "container" {
+		d.setStatus("[yellow]Logs are only available for containers")
+		return
+	}
+	d.showText(fmt.Sprintf("logs of container/%s", row.name), func(ctx context.Context) (string, error) {
+		logs, err := d.cs.Containers().Logs(ctx, clientset.ContainerLogOptions{TaskID: row.id, Tail: dashLogTail})
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		for _, entry := range logs.Items {
+			printLogEntry(&buf, entry, true)
+		}
+		if buf.Len() == 0 {
+			return "No logs found.", nil
+		}
+		return buf.String(), nil
+	})
+}
+
+// showText 打开一个页面，在后台执行 load 并显示它返回的文本，按 Esc 或 q 关闭页面。
+func (d *dashboard) showText(title string, load func(ctx context.Context) (string, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	text := tview.NewTextView().SetDynamicColors(false).SetScrollable(true).SetText("Loading...")
+	text.SetBorder(true).SetTitle(" " + title + " (Esc to close) ")
+	text.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' {
+			cancel()
+			d.pages.RemovePage("text")
+			d.app.SetFocus(d.views[d.currentView()].table)
+			return nil
+		}
+		return event
+	})
+	d.pages.AddPage("text", text, true, true)
+	d.app.SetFocus(text)
+
+	go func() {
+		content, err := load(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			content = "Error: " + err.Error()
+		}
+		d.app.QueueUpdateDraw(func() {
+			// 日志停留在末尾，describe 的输出从头开始显示
+			text.SetText(content)
+			if strings.HasPrefix(title, "logs") {
+				text.ScrollToEnd()
+			} else {
+				text.ScrollToBeginning()
+			}
+		})
+	}()
+}
+
+// confirmRestart 确认后重启选中的服务或容器，在后台等待事务结束并在底部报告结果。
+func (d *dashboard) confirmRestart() {
+	v := d.views[d.currentView()]
+	row, ok := v.selected()
+	if !ok || v.kind == "node" {
+		d.setStatus("[yellow]Only services and containers can be restarted")
+		return
+	}
+	ref := fmt.Sprintf("%s/%s", v.kind, row.name)
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Restart %s?", ref)).
+		AddButtons([]string{"Restart", "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			d.pages.RemovePage("confirm")
+			d.app.SetFocus(v.table)
+			if label != "Restart" {
+				return
+			}
+			d.setStatus(fmt.Sprintf("Restarting %s...", tview.Escape(ref)))
+			go func() {
+				ctx := context.Background()
+				var tx *clientset.Transaction
+				var err error
+				if v.kind == "service" {
+					tx, err = d.cs.Containers().SubmitControlActionByService(ctx, row.id, clientset.ActionRestart)
+				} else {
+					tx, err = d.cs.Containers().SubmitControlActionByName(ctx, row.name, clientset.ActionRestart)
+				}
+				if err == nil {
+					err = util.WaitForTransaction(ctx, d.cs, tx.ID, d.timeout)
+				}
+				d.app.QueueUpdateDraw(func() {
+					if err != nil {
+						d.setStatus(fmt.Sprintf("[red]Failed to restart %s: %s", tview.Escape(ref), tview.Escape(err.Error())))
+						return
+					}
+					d.setStatus(fmt.Sprintf("[green]%s restarted", tview.Escape(ref)))
+				})
+			}()
+		})
+	d.pages.AddPage("confirm", modal, false, true)
+	d.app.SetFocus(modal)
+}
+
+func (d *dashboard) fetchServices(ctx context.Context) ([]dashRow, error) {
+	services, err := d.cs.Services().ListAll(ctx, clientset.ListServicesOptions{})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]dashRow, 0, len(services))
+	for _, svc := range services {
+		imageName := "N/A"
+		if len(svc.ImageList) > 0 {
+			imageName = fmt.Sprintf("%s:%s", svc.ImageList[0].Name, svc.ImageList[0].Tag)
+		}
+		rows = append(rows, dashRow{name: svc.Name, id: svc.ID, cells: []string{
+			svc.Name, svc.Status, svc.Policy, fmt.Sprint(svc.InstanceOnline), fmt.Sprint(svc.Factor), imageName,
+		}})
+	}
+	return rows, nil
+}
+
+func (d *dashboard) fetchContainers(ctx context.Context) ([]dashRow, error) {
+	containers, err := listContainers(ctx, d.cs, "", "")
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]dashRow, 0, len(containers))
+	for _, c := range containers {
+		rows = append(rows, dashRow{name: c.Name, id: c.TaskID, cells: []string{
+			c.Name, c.Status, fmt.Sprint(c.RestartCount), c.ServiceName, c.NodeName,
+			fmt.Sprintf("%.2f", c.CPUUsage.Total), fmt.Sprintf("%.2f", float64(c.MemoryUsage)/1024/1024),
+		}})
+	}
+	return rows, nil
+}
+
+func (d *dashboard) fetchNodes(ctx context.Context) ([]dashRow, error) {
+	nodes, err := d.cs.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]dashRow, 0, len(nodes))
+	for _, node := range nodes {
+		cpu, mem, disk := "<unknown>", "<unknown>", "<unknown>"
+		// 离线节点没有指标，仍然列出它
+		if metrics, err := d.cs.Nodes().GetNodeMetrics(ctx, clientset.NodeMetricsOptions{NodeID: node.ID, Instant: true}); err == nil && len(metrics) > 0 {
+			cpu, mem, disk = metrics[0].CPU.Percent, metrics[0].RAM.Percent, metrics[0].ROM.Percent
+		}
+		rows = append(rows, dashRow{name: node.Name, id: node.ID, cells: []string{
+			node.Name, node.Status, node.Address, cpu, mem, disk,
+			fmt.Sprintf("%d/%d", node.ContainerEcsmRunning, node.ContainerEcsmTotal),
+		}})
+	}
+	return rows, nil
+}
@@ -0,0 +1,210 @@
+// file: cmd/ecsm-cli/cmd/delete.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+// deleteOptions 是 delete 命令及其子命令共用的标志
+type deleteOptions struct {
+	wait    bool
+	timeout time.Duration
+}
+
+// newDeleteCmd 创建 "delete" 命令，它删除 ECSM 平台上的服务和节点，或者删除清单中的 ECSMService
+func newDeleteCmd() *cobra.Command {
+	var (
+		opts      deleteOptions
+		filename  string
+		namespace string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "delete ([resource] NAME_OR_ID | -f FILENAME)",
+		Short: "Delete resources by name, ID or manifest",
+		Long: `Deletes a service or node of the ECSM platform by name or ID, or the
+ECSMServices in a manifest file, directory or standard input ("-f -").
+
+ECSMServices are deleted through the operator's API server given with --server,
+and the operator then deletes their platform services. While the operator is
+stopped they can instead be deleted from its registry database given with
+--registry-db.
+
+With --wait the command blocks until the ECSM platform confirms the deletion:
+until the deletion transaction of a service has finished, until a node is no
+longer listed, or until the platform service recorded in the status of an
+ECSMService is gone.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return cmd.Help()
+			}
+			return deleteFromManifests(context.Background(), filename, namespace, opts)
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&opts.wait, "wait", false, "Wait until the ECSM platform confirms the deletion")
+	cmd.PersistentFlags().DurationVar(&opts.timeout, "timeout", 5*time.Minute, "How long to wait with --wait")
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "The file, directory or \"-\" for standard input that contains the ECSMServices to delete")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of services whose manifest does not set one")
+
+	cmd.AddCommand(newDeleteServiceCmd(&opts))
+	cmd.AddCommand(newDeleteNodeCmd(&opts))
+	return cmd
+}
+
+// newDeleteServiceCmd 创建 "delete service" 子命令
+func newDeleteServiceCmd(opts *deleteOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:               "service <SERVICE_NAME_OR_ID>",
+		Short:             "Delete a service of the ECSM platform",
+		Aliases:           []string{"svc"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listServiceNames),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			serviceID, err := util.ResolveServiceID(ctx, cs, args[0])
+			if err != nil {
+				return err
+			}
+			resp, err := cs.Services().Delete(ctx, serviceID)
+			if err != nil {
+				return fmt.Errorf("failed to delete service %s: %w", args[0], err)
+			}
+
+			if !opts.wait {
+				fmt.Fprintf(os.Stdout, "service/%s deletion requested (transaction %s)\n", args[0], resp.ID)
+				return nil
+			}
+			if err := util.WaitForTransaction(ctx, cs, resp.ID, opts.timeout); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "service/%s deleted\n", args[0])
+			return nil
+		},
+	}
+}
+
+// newDeleteNodeCmd 创建 "delete node" 子命令
+func newDeleteNodeCmd(opts *deleteOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:               "node <NODE_NAME_OR_ID>",
+		Short:             "Delete a node of the ECSM platform",
+		Long:              `Deletes a node of the ECSM platform. Nodes that still run services cannot be deleted.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listNodeNames),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			nodeID, err := util.ResolveNodeID(ctx, cs, args[0])
+			if err != nil {
+				return err
+			}
+			conflicts, err := cs.Nodes().Delete(ctx, []string{nodeID})
+			if err != nil {
+				return fmt.Errorf("failed to delete node %s: %w", args[0], err)
+			}
+			// 平台拒绝删除仍在运行服务的节点，列出这些服务
+			if err := nodeConflictsError(conflicts); err != nil {
+				return err
+			}
+
+			if opts.wait {
+				if err := util.WaitForNodeGone(ctx, cs, nodeID, opts.timeout); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(os.Stdout, "node/%s deleted\n", args[0])
+			return nil
+		},
+	}
+}
+
+// deleteFromManifests 从 Registry 中删除清单中的 ECSMService。
+func deleteFromManifests(ctx context.Context, filename, namespace string, opts deleteOptions) error {
+	manifests, err := util.ReadManifests(filename)
+	if err != nil {
+		return err
+	}
+	var services []*ecsmv1.ECSMService
+	for i := range manifests {
+		service, err := manifests[i].DecodeService()
+		if err != nil {
+			return err
+		}
+		if service.Namespace == "" {
+			service.Namespace = namespace
+		}
+		services = append(services, service)
+	}
+
+	var platformIDs []string
+	var errs []error
+	err = func() error {
+		reg, closeFn, err := util.NewWritableRegistryFromFlags()
+		if err != nil {
+			return err
+		}
+		defer closeFn()
+		platformIDs, errs = deleteECSMServices(ctx, reg, services)
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	// 释放 Registry 之后再等待，直接打开数据库时 operator 需要它才能删除平台服务
+	if opts.wait && len(platformIDs) > 0 {
+		cs, err := util.NewClientsetFromFlags()
+		if err != nil {
+			return err
+		}
+		if err := util.WaitForServicesGone(ctx, cs, platformIDs, opts.timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deleteECSMServices 从 reg 中删除 services，返回被删除的服务记录的平台服务 ID 和删除失败的错误。
+// 一个服务失败时继续删除其余的服务。
+func deleteECSMServices(ctx context.Context, reg registry.Interface, services []*ecsmv1.ECSMService) ([]string, []error) {
+	// 平台服务的 ID 只记录在 Registry 中，必须在删除之前读取
+	var platformIDs []string
+	var errs []error
+	for _, service := range services {
+		current, err := reg.GetService(ctx, service.Namespace, service.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete ecsmservice %s/%s: %w", service.Namespace, service.Name, err))
+			continue
+		}
+		if err := reg.DeleteService(ctx, service.Namespace, service.Name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete ecsmservice %s/%s: %w", service.Namespace, service.Name, err))
+			continue
+		}
+		if current.Status.UnderlyingServiceID != "" {
+			platformIDs = append(platformIDs, current.Status.UnderlyingServiceID)
+		}
+		fmt.Fprintf(os.Stdout, "ecsmservice/%s deleted\n", service.Name)
+	}
+	return platformIDs, errs
+}
@@ -0,0 +1,41 @@
+// file: cmd/ecsm-cli/cmd/delete_test.go
+
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// TestDeleteECSMServices 测试 delete -f 通过 API Server 删除 ECSMService，并返回它们的平台服务 ID。
+func TestDeleteECSMServices(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	service, err := reg.CreateService(ctx, newTestECSMService("web"))
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	service.Status.UnderlyingServiceID = "svc-1"
+	if _, err := reg.UpdateServiceStatus(ctx, service); err != nil {
+		t.Fatalf("UpdateServiceStatus failed: %v", err)
+	}
+
+	platformIDs, errs := deleteECSMServices(ctx, c, []*ecsmv1.ECSMService{
+		newTestECSMService("web"),
+		newTestECSMService("missing"),
+	})
+	if !slices.Equal(platformIDs, []string{"svc-1"}) {
+		t.Errorf("platform IDs = %v, want [svc-1]", platformIDs)
+	}
+	if len(errs) != 1 || !errors.IsNotFound(errs[0]) {
+		t.Errorf("errors = %v, want one NotFound error for the missing service", errs)
+	}
+	if _, err := reg.GetService(ctx, "default", "web"); !errors.IsNotFound(err) {
+		t.Errorf("GetService after delete error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,46 @@
+// file: cmd/ecsm-cli/cmd/describe_test.go
+
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset/fake"
+)
+
+// TestFetchServiceDescription_Redaction 测试 describe service 不输出 VSOA 的密码，
+// 而环境变量 (包括来自 ECSMSecret 的值) 按平台保存的原样输出。
+func TestFetchServiceDescription_Redaction(t *testing.T) {
+	cs := fake.NewClientset()
+	cs.Tracker().AddNode(&clientset.NodeInfo{Name: "node-1"})
+	ctx := context.Background()
+	env := []string{"MODE=prod", "DB_PASSWORD=s3cret"}
+	if _, err := cs.Services().Create(ctx, &clientset.CreateServiceRequest{
+		Name: "web",
+		Image: clientset.ImageSpec{
+			Ref:    "web@1.0",
+			Config: &clientset.EcsImageConfig{Process: &clientset.Process{Env: env}},
+			VSOA:   &clientset.ImageVSOA{Password: "vsoa-pass"},
+		},
+	}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	description, err := fetchServiceDescription(ctx, cs, "web")
+	if err != nil {
+		t.Fatalf("fetchServiceDescription failed: %v", err)
+	}
+	image := description.Service.Image
+	if image.VSOA.Password != "" {
+		t.Errorf("Expected the VSOA password to be cleared, got %q", image.VSOA.Password)
+	}
+	if !slices.Equal(image.Config.Process.Env, env) {
+		t.Errorf("Expected the environment to be printed as stored, got %v", image.Config.Process.Env)
+	}
+	if len(description.Containers) != 1 {
+		t.Errorf("Expected 1 container, got %d", len(description.Containers))
+	}
+}
@@ -0,0 +1,260 @@
+// file: cmd/ecsm-cli/cmd/diff.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/controller"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// newDiffCmd 创建 "diff" 命令，它预览 apply 会对 ECSMService 做出的修改
+func newDiffCmd() *cobra.Command {
+	var (
+		filename  string
+		namespace string
+		direct    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff -f FILENAME",
+		Short: "Show what apply would change",
+		Long: `Prints a unified diff between the ECSMServices in manifests and the live
+objects, without changing anything. FILENAME is read like in apply.
+
+By default the manifests are compared with the services read through the
+operator's API server given with --server, or from its registry database given
+with --registry-db while the operator is stopped: the diff shows the object
+apply would write, with the spec replaced and the labels and annotations
+merged. Services that do not exist yet are shown as added.
+
+With --direct the manifests are compared with the platform services on the
+ECSM platform instead: the diff shows how the controller would create the
+current revision of the service compared with the platform service it runs
+now. References to ECSMConfigs and ECSMSecrets are only resolved when --server
+or --registry-db is given. The API server never returns the values of
+ECSMSecrets, so with --server values taken from ECSMSecrets are shown as empty
+and always differ from the platform service.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return fmt.Errorf("-f must be specified")
+			}
+			manifests, err := util.ReadManifests(filename)
+			if err != nil {
+				return err
+			}
+			var services []*ecsmv1.ECSMService
+			for i := range manifests {
+				service, err := manifests[i].DecodeService()
+				if err != nil {
+					return err
+				}
+				if service.Namespace == "" {
+					service.Namespace = namespace
+				}
+				// 与 apply 相同，先填充默认值再比较
+				defaults.SetServiceDefaults(service)
+				services = append(services, service)
+			}
+
+			ctx := context.Background()
+			// diff 只读取 Registry；--direct 时 Registry 是可选的，只用于展开配置引用
+			var reg registry.Interface
+			if !direct || util.HasRegistryFlags() {
+				r, closeFn, err := util.NewRegistryFromFlags()
+				if err != nil {
+					return err
+				}
+				defer closeFn()
+				reg = r
+			}
+			var cs clientset.Interface
+			if direct {
+				c, err := util.NewClientsetFromFlags()
+				if err != nil {
+					return err
+				}
+				cs = c
+			}
+
+			for _, service := range services {
+				var diff string
+				if direct {
+					diff, err = diffPlatformService(ctx, cs, reg, service)
+				} else {
+					diff, err = diffRegistryService(ctx, reg, service)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to diff ecsmservice %s/%s: %w", service.Namespace, service.Name, err)
+				}
+				fmt.Fprint(os.Stdout, diff)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "The file, directory or \"-\" for standard input that contains the manifests to diff")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of services whose manifest does not set one")
+	cmd.Flags().BoolVar(&direct, "direct", false, "Compare with the platform services on the ECSM platform instead of the registry")
+	return cmd
+}
+
+// diffRegistryService 比较 Registry 中的 ECSMService 和 apply 之后的对象。
+func diffRegistryService(ctx context.Context, reg registry.Interface, service *ecsmv1.ECSMService) (string, error) {
+	var live, merged *ecsmv1.ECSMService
+	current, err := reg.GetService(ctx, service.Namespace, service.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		merged = service.DeepCopy()
+		merged.ResourceVersion = ""
+		merged.Status = ecsmv1.ECSMServiceStatus{}
+	case err != nil:
+		return "", err
+	default:
+		live = current
+		merged = mergeAppliedService(current, service)
+	}
+
+	path := fmt.Sprintf("ecsmservice/%s/%s", service.Namespace, service.Name)
+	return unifiedDiff("live/"+path, "merged/"+path, typedService(live), typedService(merged))
+}
+
+// typedService 返回带有 apiVersion 和 kind 的副本，Registry 返回的对象没有设置它们。
+func typedService(service *ecsmv1.ECSMService) *ecsmv1.ECSMService {
+	if service == nil {
+		return nil
+	}
+	service = service.DeepCopy()
+	service.APIVersion, service.Kind = ecsmv1.SchemeGroupVersion.String(), "ECSMService"
+	return service
+}
+
+// diffPlatformService 比较平台上正在运行的平台服务和控制器为清单中的模板创建的平台服务。
+func diffPlatformService(ctx context.Context, cs clientset.Interface, reg registry.Interface, service *ecsmv1.ECSMService) (string, error) {
+	desired, err := controller.DesiredPlatformService(ctx, reg, service)
+	if err != nil {
+		return "", err
+	}
+	rows, err := controller.ListPlatformServicesByOwner(ctx, cs, service.Namespace, service.Name)
+	if err != nil {
+		return "", err
+	}
+
+	var live *clientset.CreateServiceRequest
+	if row := currentPlatformService(rows, desired.Name); row != nil {
+		// 属主标签中的 UID 只有 Registry 知道，清单中没有，这里沿用平台服务上的 UID，避免它出现在每个 diff 中
+		service = service.DeepCopy()
+		service.UID = types.UID(util.ServiceLabels(row)[controller.LabelOwnerUID])
+		if desired, err = controller.DesiredPlatformService(ctx, reg, service); err != nil {
+			return "", err
+		}
+
+		details, err := cs.Services().Get(ctx, row.ID)
+		if err != nil {
+			return "", err
+		}
+		live = platformServiceRequest(details)
+	}
+
+	path := "service/" + desired.Name
+	livePath := path
+	if live != nil {
+		livePath = "service/" + live.Name
+	}
+	return unifiedDiff("live/"+livePath, "desired/"+path, live, desired)
+}
+
+// currentPlatformService 选出与清单比较的平台服务：优先选择名称 (即模板哈希) 与期望相同的修订版本，
+// 否则选择最近更新的修订版本。没有平台服务时返回 nil。
+func currentPlatformService(rows []clientset.ProvisionListRow, desiredName string) *clientset.ProvisionListRow {
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		if rows[i].Name == desiredName {
+			return &rows[i]
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].UpdatedTime > rows[j].UpdatedTime })
+	return &rows[0]
+}
+
+// platformServiceRequest 把平台服务的详情转换成创建请求的形式，以便和控制器生成的请求比较。
+func platformServiceRequest(svc *clientset.ServiceGet) *clientset.CreateServiceRequest {
+	req := &clientset.CreateServiceRequest{
+		Name:   svc.Name,
+		Policy: svc.Policy,
+		Labels: append([]string(nil), svc.Labels...),
+	}
+	sort.Strings(req.Labels)
+	if svc.Image != nil {
+		req.Image = *svc.Image
+	}
+	if svc.Node != nil {
+		req.Node = *svc.Node
+	}
+	if svc.Factor > 0 {
+		factor := svc.Factor
+		req.Factor = &factor
+	}
+	return req
+}
+
+// unifiedDiff 以 YAML 形式渲染 from 和 to 并返回它们的 unified diff，两者相同时返回空字符串。
+// from 或 to 为 nil 表示对象不存在。
+func unifiedDiff(fromName, toName string, from, to interface{}) (string, error) {
+	render := func(obj interface{}) (string, error) {
+		if obj == nil || isNilPointer(obj) {
+			return "", nil
+		}
+		data, err := yaml.Marshal(obj)
+		return string(data), err
+	}
+	// 不存在的对象没有任何行，而不是一个空行
+	lines := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return difflib.SplitLines(s)
+	}
+	a, err := render(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := render(to)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines(a),
+		B:        lines(b),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
+
+// isNilPointer 判断 obj 是否是 unifiedDiff 的调用方传入的空指针
+func isNilPointer(obj interface{}) bool {
+	switch v := obj.(type) {
+	case *ecsmv1.ECSMService:
+		return v == nil
+	case *clientset.CreateServiceRequest:
+		return v == nil
+	}
+	return false
+}
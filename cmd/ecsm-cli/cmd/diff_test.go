@@ -0,0 +1,49 @@
+// file: cmd/ecsm-cli/cmd/diff_test.go
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+)
+
+// TestDiffRegistryService 测试 diff 通过 API Server 读取当前的服务并与清单比较。
+func TestDiffRegistryService(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	if _, err := reg.CreateService(ctx, newTestECSMService("web")); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	// 与 diff 命令相同，先填充默认值再比较
+	manifest := newTestECSMService("web")
+	defaults.SetServiceDefaults(manifest)
+	diff, err := diffRegistryService(ctx, c, manifest)
+	if err != nil {
+		t.Fatalf("diffRegistryService() error = %v", err)
+	}
+	if diff != "" {
+		t.Errorf("diff of an unchanged manifest = %q, want empty", diff)
+	}
+
+	manifest.Spec.Template.Image = "web@2.0"
+	diff, err = diffRegistryService(ctx, c, manifest)
+	if err != nil {
+		t.Fatalf("diffRegistryService() error = %v", err)
+	}
+	if !strings.Contains(diff, "-    image: web@1.0") || !strings.Contains(diff, "+    image: web@2.0") {
+		t.Errorf("diff = %q, want the image change", diff)
+	}
+
+	diff, err = diffRegistryService(ctx, c, newTestECSMService("new"))
+	if err != nil {
+		t.Fatalf("diffRegistryService() error = %v", err)
+	}
+	if !strings.Contains(diff, "+++ merged/ecsmservice/default/new") || strings.Contains(diff, "\n-") {
+		t.Errorf("diff of a new service = %q, want only added lines", diff)
+	}
+}
@@ -0,0 +1,190 @@
+// file: cmd/ecsm-cli/cmd/edit.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/validation"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
+)
+
+// editHeader 是编辑器中对象之前的说明，以 # 开头的行在保存后被忽略
+const editHeader = `# Please edit the object below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving this file
+# will be reopened with the relevant failures.
+#
+`
+
+// newEditCmd 创建 "edit" 命令
+func newEditCmd() *cobra.Command {
+	var namespace string
+
+	cmd := &cobra.Command{
+		Use:   "edit service SERVICE_NAME",
+		Short: "Edit an ECSMService in your editor",
+		Long: `Opens an ECSMService as YAML in the editor given by the ECSM_EDITOR or EDITOR
+environment variable, falling back to vi. After the editor exits the service
+is validated and updated. If the edited object is invalid the editor is
+reopened with the errors at the top of the file.
+
+Changes to the spec, labels and annotations are saved, the status cannot be
+edited. If the service is modified by someone else while it is being edited,
+the edit is retried on top of the latest version as long as the other change
+did not touch the spec, labels or annotations.
+
+The service is read and updated through the operator's API server given with
+--server. While the operator is stopped it can instead be edited in its
+registry database given with --registry-db.`,
+		Args:              serviceArgs,
+		ValidArgsFunction: completeServiceArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reg, closeFn, err := util.NewWritableRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+			return editService(context.Background(), reg, namespace, args[1])
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the service")
+	return cmd
+}
+
+// editService 在编辑器中编辑 reg 中的一个 ECSMService 并保存修改。
+func editService(ctx context.Context, reg registry.Interface, namespace, name string) error {
+	original, err := reg.GetService(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	shown := original.DeepCopy()
+	shown.APIVersion, shown.Kind = ecsmv1.SchemeGroupVersion.String(), "ECSMService"
+	body, err := yaml.Marshal(shown)
+	if err != nil {
+		return err
+	}
+
+	// 编辑的结果无效时，把错误放在文件开头并重新打开编辑器，保留用户已经做的修改
+	var edited *ecsmv1.ECSMService
+	var editErr error
+	for {
+		contents := []byte(editHeader + errorComment(editErr) + string(body))
+		result, err := util.EditBuffer(contents, ".yaml")
+		if err != nil {
+			return err
+		}
+		body = stripComments(result)
+		if len(bytes.TrimSpace(body)) == 0 {
+			fmt.Fprintln(os.Stdout, "Edit cancelled, saved file was empty.")
+			return nil
+		}
+		if bytes.Equal(result, contents) {
+			if editErr != nil {
+				// 用户没有修正错误就退出了编辑器
+				return editErr
+			}
+			fmt.Fprintln(os.Stdout, "Edit cancelled, no changes made.")
+			return nil
+		}
+
+		edited, editErr = decodeEditedService(body, original)
+		if editErr == nil {
+			break
+		}
+	}
+
+	if equality.Semantic.DeepEqual(edited.Spec, original.Spec) &&
+		equality.Semantic.DeepEqual(edited.Labels, original.Labels) &&
+		equality.Semantic.DeepEqual(edited.Annotations, original.Annotations) {
+		fmt.Fprintln(os.Stdout, "Edit cancelled, no changes made.")
+		return nil
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := reg.GetService(ctx, namespace, name)
+		if err != nil {
+			return err
+		}
+		// 其他人只修改了 status 等字段时，可以安全地把这次编辑应用到最新的版本上
+		if !equality.Semantic.DeepEqual(latest.Spec, original.Spec) ||
+			!equality.Semantic.DeepEqual(latest.Labels, original.Labels) ||
+			!equality.Semantic.DeepEqual(latest.Annotations, original.Annotations) {
+			return fmt.Errorf("ecsmservice/%s was modified while it was being edited, please edit it again", name)
+		}
+		updated := latest.DeepCopy()
+		updated.Spec = edited.Spec
+		updated.Labels = edited.Labels
+		updated.Annotations = edited.Annotations
+		_, err = reg.UpdateService(ctx, updated)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update ecsmservice/%s: %w", name, err)
+	}
+	fmt.Fprintf(os.Stdout, "ecsmservice/%s edited\n", name)
+	return nil
+}
+
+// decodeEditedService 解码并校验编辑后的对象，名称和命名空间不能被修改。
+func decodeEditedService(body []byte, original *ecsmv1.ECSMService) (*ecsmv1.ECSMService, error) {
+	manifests, err := util.ParseManifests(body, "edited object")
+	if err != nil {
+		return nil, err
+	}
+	if len(manifests) != 1 {
+		return nil, fmt.Errorf("expected exactly one object, got %d", len(manifests))
+	}
+	service, err := manifests[0].DecodeService()
+	if err != nil {
+		return nil, err
+	}
+	if service.Name != original.Name || service.Namespace != original.Namespace {
+		return nil, fmt.Errorf("the name and namespace of ecsmservice/%s cannot be changed", original.Name)
+	}
+
+	// 与 apply 相同，Registry 在更新时不会填充默认值
+	defaults.SetServiceDefaults(service)
+	if errs := validation.ValidateService(service); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return service, nil
+}
+
+// errorComment 把错误格式化为放在文件开头的注释。
+func errorComment(err error) string {
+	if err == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("# ecsmservice could not be saved:\n")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		b.WriteString("# " + line + "\n")
+	}
+	b.WriteString("#\n")
+	return b.String()
+}
+
+// stripComments 去掉文件开头以 # 开头的行，即说明和上一次的错误。
+func stripComments(data []byte) []byte {
+	for len(data) > 0 && data[0] == '#' {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return nil
+		}
+		data = data[i+1:]
+	}
+	return data
+}
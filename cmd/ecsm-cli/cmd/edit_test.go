@@ -0,0 +1,34 @@
+// file: cmd/ecsm-cli/cmd/edit_test.go
+
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+// TestEditService 测试 edit 通过 API Server 读取并更新服务，编辑器由 ECSM_EDITOR 中的 sed 命令代替。
+func TestEditService(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	if _, err := reg.CreateService(ctx, newTestECSMService("web")); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	t.Setenv("ECSM_EDITOR", "sed -i s/web@1.0/web@2.0/")
+
+	if err := editService(ctx, c, "default", "web"); err != nil {
+		t.Fatalf("editService() error = %v", err)
+	}
+	got, err := reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if got.Spec.Template.Image != "web@2.0" {
+		t.Errorf("image = %q, want web@2.0", got.Spec.Template.Image)
+	}
+
+	if err := editService(ctx, c, "default", "missing"); err == nil {
+		t.Error("editService() of a missing service succeeded, want an error")
+	}
+}
@@ -0,0 +1,163 @@
+// file: cmd/ecsm-cli/cmd/events.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/openapi"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+// newEventsCmd 创建 events 命令，它从 operator 的 Registry 中读取控制器记录的事件。
+// 同一个命令也以 "get events" 的形式注册。
+func newEventsCmd() *cobra.Command {
+	var (
+		namespace     string
+		allNamespaces bool
+		forObject     string
+		eventType     string
+		output        *string
+		sortBy        *string
+		tableFlags    *util.TableFlags
+		watchOpts     watchOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "List events recorded by the ecsm-operator",
+		Long: `Lists the events recorded by the ecsm-operator controllers, such as scaling,
+rolling updates and failures, oldest first. Events are read through the
+operator's API server given with --server. While the operator is stopped they
+can instead be read from its registry database given with --registry-db.
+
+--for selects the events about one object, given as KIND/NAME such as
+service/my-app or ecsmjob/backup, or as a bare NAME matching objects of any
+kind. With --watch the registry is polled and new and updated events are
+printed as they are recorded.`,
+		Aliases: []string{"event", "ev"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "event")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			forKind, forName, err := parseEventsFor(forObject)
+			if err != nil {
+				return err
+			}
+			if allNamespaces {
+				namespace = ""
+			}
+
+			// --watch 时反复读取同一个 Registry，通过 API Server 访问时不会占用 operator 的数据库
+			reg, closeFn, err := util.NewRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			list := func(ctx context.Context) ([]ecsmv1.Event, error) {
+				events, err := listEvents(ctx, reg, namespace)
+				if err != nil {
+					return nil, err
+				}
+				var filtered []ecsmv1.Event
+				for _, e := range events {
+					if eventType != "" && !strings.EqualFold(e.Type, eventType) {
+						continue
+					}
+					if forName != "" && e.InvolvedObject.Name != forName {
+						continue
+					}
+					if forKind != "" && e.InvolvedObject.Kind != forKind {
+						continue
+					}
+					filtered = append(filtered, e)
+				}
+				return sortedList(sorter, filtered)
+			}
+
+			if watchOpts.watch {
+				return runWatch(watchOpts, printer, &util.ListWatcher[ecsmv1.Event]{
+					List: list,
+					Key:  func(e *ecsmv1.Event) string { return e.Namespace + "/" + e.Name },
+				})
+			}
+
+			events, err := list(context.Background())
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 && util.IsTableOutput(*output) {
+				fmt.Fprintln(os.Stdout, "No events found.")
+				return nil
+			}
+			return printer.PrintObj(events, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the events to list")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List events across all namespaces")
+	cmd.Flags().StringVar(&forObject, "for", "", "Only show events about this object, given as KIND/NAME (e.g. service/my-app) or NAME")
+	cmd.Flags().StringVar(&eventType, "type", "", "Only show events of this type (Normal or Warning)")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	watchOpts.addFlags(cmd)
+	return cmd
+}
+
+// parseEventsFor 解析 --for 的值，返回对象的 Kind 和名称。只给出名称时 Kind 为空，表示任意 Kind。
+func parseEventsFor(forObject string) (kind, name string, err error) {
+	resource, name, found := strings.Cut(forObject, "/")
+	if !found {
+		return "", forObject, nil
+	}
+	if resource == "" || name == "" {
+		return "", "", fmt.Errorf("invalid --for %q, must be KIND/NAME or NAME", forObject)
+	}
+	kind, ok := openapi.NewDocument().KindFor(resource)
+	if !ok {
+		return "", "", fmt.Errorf("invalid --for %q: unknown kind %q", forObject, resource)
+	}
+	return kind, name, nil
+}
+
+// listEvents 从 reg 中读取事件，并按最近发生时间排序
+func listEvents(ctx context.Context, reg registry.Interface, namespace string) ([]ecsmv1.Event, error) {
+	list, _, err := reg.ListEvents(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return list.Items[i].LastTimestamp.Before(&list.Items[j].LastTimestamp)
+	})
+	return list.Items, nil
+}
+
+// eventsForPlatformService 返回与一个平台服务相关的事件。
+// 平台服务的名称以 "<ECSMService 名称>-" 开头，因此它所属的 ECSMService 上的事件也会被包含。
+func eventsForPlatformService(events []ecsmv1.Event, serviceName string) []ecsmv1.Event {
+	var result []ecsmv1.Event
+	for _, e := range events {
+		name := e.InvolvedObject.Name
+		if name == serviceName || strings.HasPrefix(serviceName, name+"-") {
+			result = append(result, e)
+		}
+	}
+	return result
+}
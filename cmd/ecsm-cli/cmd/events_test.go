@@ -0,0 +1,63 @@
+// file: cmd/ecsm-cli/cmd/events_test.go
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestListEvents 测试 events 通过 API Server 读取事件，并按最近发生时间排序。
+func TestListEvents(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, e := range []struct {
+		name      string
+		namespace string
+		last      time.Time
+	}{
+		{"web.2", "default", now},
+		{"web.1", "default", now.Add(-time.Minute)},
+		{"db.1", "other", now.Add(-2 * time.Minute)},
+	} {
+		if _, err := reg.CreateEvent(ctx, &ecsmv1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: e.name, Namespace: e.namespace},
+			InvolvedObject: ecsmv1.ObjectReference{Kind: "ECSMService", Name: "web"},
+			Type:           "Normal",
+			Reason:         "Scaled",
+			LastTimestamp:  metav1.NewTime(e.last),
+		}); err != nil {
+			t.Fatalf("CreateEvent failed: %v", err)
+		}
+	}
+
+	events, err := listEvents(ctx, c, "default")
+	if err != nil {
+		t.Fatalf("listEvents() error = %v", err)
+	}
+	if len(events) != 2 || events[0].Name != "web.1" || events[1].Name != "web.2" {
+		t.Errorf("events = %v, want web.1 and web.2 oldest first", eventNames(events))
+	}
+
+	events, err = listEvents(ctx, c, "")
+	if err != nil {
+		t.Fatalf("listEvents() error = %v", err)
+	}
+	if len(events) != 3 || events[0].Name != "db.1" {
+		t.Errorf("events of all namespaces = %v, want db.1 first", eventNames(events))
+	}
+}
+
+func eventNames(events []ecsmv1.Event) []string {
+	var names []string
+	for _, e := range events {
+		names = append(names, e.Namespace+"/"+e.Name)
+	}
+	return names
+}
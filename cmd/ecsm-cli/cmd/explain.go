@@ -0,0 +1,81 @@
+// file: cmd/ecsm-cli/cmd/explain.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// newExplainCmd 创建 "explain" 命令，它打印 ecsm.sh/v1 API 对象的字段说明
+func newExplainCmd() *cobra.Command {
+	var recursive bool
+
+	cmd := &cobra.Command{
+		Use:   "explain RESOURCE[.FIELD...]",
+		Short: "Describe the fields of the ecsm.sh/v1 API objects",
+		Long: `Prints the documentation of an ecsm.sh/v1 object or one of its fields: the
+type, the description and the fields it contains. Fields are given as a dot
+separated path, for example:
+
+  ecsm-cli explain ecsmservice.spec.template
+  ecsm-cli explain service.spec.template.env --recursive
+
+The resource is the kind of the object in any case, optionally in plural and
+without the ECSM prefix. Fields of list and map elements are addressed
+directly, e.g. spec.template.env.name. The documentation is built into
+ecsm-cli, no server is contacted.`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return explainableKinds(openapi.NewDocument()), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc := openapi.NewDocument()
+			resource, fieldPath, _ := strings.Cut(args[0], ".")
+			kind, ok := doc.KindFor(resource)
+			if !ok {
+				return fmt.Errorf("unknown resource %q, must be one of: %s", resource, strings.Join(explainableKinds(doc), ", "))
+			}
+			schema, _ := doc.SchemaForKind(kind)
+
+			var path []string
+			if fieldPath != "" {
+				path = strings.Split(fieldPath, ".")
+			}
+			field, err := doc.Field(schema, path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", kind, err)
+			}
+			util.PrintExplanation(os.Stdout, doc, field, util.ExplainOptions{
+				Kind:       kind,
+				APIVersion: ecsmv1.SchemeGroupVersion.String(),
+				Path:       path,
+				Recursive:  recursive,
+			})
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&recursive, "recursive", false, "Print the names and types of all nested fields instead of the descriptions")
+	return cmd
+}
+
+// explainableKinds 返回可以解释的 Kind 的小写形式，列表类型除外。
+func explainableKinds(doc *openapi.Document) []string {
+	var kinds []string
+	for _, kind := range doc.Kinds() {
+		if !strings.HasSuffix(kind, "List") {
+			kinds = append(kinds, strings.ToLower(kind))
+		}
+	}
+	return kinds
+}
@@ -4,8 +4,12 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"sync"
+	"time"
 
 	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
 	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
@@ -25,25 +29,67 @@ func newGetCmd() *cobra.Command {
 	}
 
 	// 添加 get 的子命令
+	cmd.AddCommand(newGetAllCmd())
 	cmd.AddCommand(newGetNodesCmd())
 	cmd.AddCommand(newGetImagesCmd())
 	cmd.AddCommand(newGetServicesCmd())
 	cmd.AddCommand(newGetContainersCmd())
+	cmd.AddCommand(newGetTransactionsCmd())
+	cmd.AddCommand(newEventsCmd())
+	cmd.AddCommand(newGetJobsCmd())
+	cmd.AddCommand(newGetCronJobsCmd())
+	cmd.AddCommand(newGetNodeSetsCmd())
+	cmd.AddCommand(newGetConfigsCmd())
+	cmd.AddCommand(newGetSecretsCmd())
 
 	return cmd
 }
 
+// watchOptions 是支持 --watch 的 get 子命令共用的标志
+type watchOptions struct {
+	watch    bool
+	interval time.Duration
+}
+
+func (o *watchOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&o.watch, "watch", "w", false, "After listing the resources, poll the ECSM platform and print the resources that were added, modified or deleted")
+	cmd.Flags().DurationVar(&o.interval, "watch-interval", 2*time.Second, "How often to poll the ECSM platform with --watch")
+}
+
+// runWatch 运行 lw 直到用户按下 Ctrl+C。
+func runWatch[T any](opts watchOptions, printer util.ResourcePrinter, lw *util.ListWatcher[T]) error {
+	if opts.interval <= 0 {
+		return fmt.Errorf("--watch-interval must be positive")
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	lw.Interval = opts.interval
+	return lw.Watch(ctx, os.Stdout, printer)
+}
+
 // newGetNodesCmd 创建 "get nodes" 子命令
 func newGetNodesCmd() *cobra.Command {
 	var pageSize int
 	var pageNum int
 	var nameFilter string
 	var basicInfo bool
+	var output, sortBy *string
+	var tableFlags *util.TableFlags
+	var watchOpts watchOptions
 	cmd := &cobra.Command{
 		Use:     "nodes",
 		Short:   "Display a list of nodes",
 		Aliases: []string{"node", "no"},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "node")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
 			cs, err := util.NewClientsetFromFlags()
 			if err != nil {
 				return err
@@ -55,34 +101,56 @@ func newGetNodesCmd() *cobra.Command {
 				BasicInfo: basicInfo,
 			}
 
-			var nodesToPrint []clientset.NodeInfo
+			list := func(ctx context.Context) ([]clientset.NodeInfo, error) {
+				var nodes []clientset.NodeInfo
 
-			// --- 核心修复 ---
-			// 通过检查用户是否在命令行中明确设置了 "page" 标志，
-			// 来决定是分页还是获取全部。
-			if cmd.Flags().Changed("page") {
-				// 用户明确指定了页码，执行分页 List
-				opts.PageNum = pageNum
-				nodeList, err := cs.Nodes().List(context.Background(), opts)
-				if err != nil {
-					return err
+				// --- 核心修复 ---
+				// 通过检查用户是否在命令行中明确设置了 "page" 标志，
+				// 来决定是分页还是获取全部。
+				if cmd.Flags().Changed("page") {
+					// 用户明确指定了页码，执行分页 List
+					opts.PageNum = pageNum
+					nodeList, err := cs.Nodes().List(ctx, opts)
+					if err != nil {
+						return nil, err
+					}
+					nodes = nodeList.Items
+				} else {
+					// 默认行为：获取所有节点
+					allNodes, err := cs.Nodes().ListAll(ctx, opts)
+					if err != nil {
+						return nil, err
+					}
+					nodes = allNodes
 				}
-				nodesToPrint = nodeList.Items
-			} else {
-				// 默认行为：获取所有节点
-				allNodes, err := cs.Nodes().ListAll(context.Background(), opts)
-				if err != nil {
-					return err
+
+				// 平台在节点列表中返回了节点的密码，不应该输出它
+				for i := range nodes {
+					nodes[i].Password = ""
 				}
-				nodesToPrint = allNodes
+				return sortedList(sorter, nodes)
 			}
 
-			if len(nodesToPrint) > 0 {
-				util.PrintNodesTable(os.Stdout, nodesToPrint)
-			} else {
+			if watchOpts.watch {
+				return runWatch(watchOpts, printer, &util.ListWatcher[clientset.NodeInfo]{
+					List: list,
+					Key:  func(node *clientset.NodeInfo) string { return node.ID },
+					Normalize: func(node clientset.NodeInfo) clientset.NodeInfo {
+						node.UpTime = 0
+						return node
+					},
+				})
+			}
+
+			nodesToPrint, err := list(context.Background())
+			if err != nil {
+				return err
+			}
+			if len(nodesToPrint) == 0 && util.IsTableOutput(*output) {
 				fmt.Println("No nodes found.")
+				return nil
 			}
-			return nil
+			return printer.PrintObj(nodesToPrint, os.Stdout)
 		},
 	}
 
@@ -91,6 +159,10 @@ func newGetNodesCmd() *cobra.Command {
 	cmd.Flags().IntVarP(&pageSize, "page-size", "s", 100, "Number of items per page (used for both single and all-page listing)")
 	cmd.Flags().StringVarP(&nameFilter, "name", "n", "", "Filter nodes by name (fuzzy match)")
 	cmd.Flags().BoolVar(&basicInfo, "basic", false, "Display basic information only")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	watchOpts.addFlags(cmd)
 
 	return cmd
 }
@@ -101,6 +173,8 @@ func newGetImagesCmd() *cobra.Command {
 	var registryID, nameFilter, osFilter, authorFilter string
 	var pageNum, pageSize int
 	var listAll bool
+	var output, sortBy *string
+	var tableFlags *util.TableFlags
 
 	cmd := &cobra.Command{
 		Use:     "images",
@@ -109,6 +183,16 @@ func newGetImagesCmd() *cobra.Command {
 		// 我们不希望 get images 后面跟任何参数
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "image")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+
 			// 1. 创建客户端
 			cs, err := util.NewClientsetFromFlags()
 			if err != nil {
@@ -143,13 +227,14 @@ func newGetImagesCmd() *cobra.Command {
 			}
 
 			// 4. 使用 printer 打印结果
-			if len(imagesToPrint) > 0 {
-				util.PrintImagesTable(os.Stdout, imagesToPrint)
-			} else {
+			if len(imagesToPrint) == 0 && util.IsTableOutput(*output) {
 				fmt.Println("No images found.")
+				return nil
 			}
-
-			return nil
+			if err := sorter.Sort(imagesToPrint); err != nil {
+				return err
+			}
+			return printer.PrintObj(imagesToPrint, os.Stdout)
 		},
 	}
 
@@ -162,6 +247,9 @@ func newGetImagesCmd() *cobra.Command {
 	cmd.Flags().BoolVarP(&listAll, "all", "A", true, "List all pages of images (default behavior)")
 	cmd.Flags().IntVar(&pageNum, "page", 1, "Page number to retrieve (if --all=false)")
 	cmd.Flags().IntVar(&pageSize, "page-size", 100, "Number of items per page")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
 
 	return cmd
 }
@@ -170,8 +258,11 @@ func newGetImagesCmd() *cobra.Command {
 func newGetServicesCmd() *cobra.Command {
 	// 定义 get services 命令的本地标志
 	var pageNum, pageSize int
-	var nameFilter, imageID, nodeID, labelFilter string
+	var nameFilter, imageID, nodeID, labelFilter, selectorFilter string
 	var listAll bool
+	var output, sortBy *string
+	var tableFlags *util.TableFlags
+	var watchOpts watchOptions
 
 	cmd := &cobra.Command{
 		Use:     "services",
@@ -179,6 +270,19 @@ func newGetServicesCmd() *cobra.Command {
 		Aliases: []string{"service", "svc"},
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "service")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			selector, err := util.ParseSelector(selectorFilter)
+			if err != nil {
+				return err
+			}
 			cs, err := util.NewClientsetFromFlags()
 			if err != nil {
 				return err
@@ -191,31 +295,43 @@ func newGetServicesCmd() *cobra.Command {
 				NodeID:   nodeID,
 				Label:    labelFilter,
 			}
+			// 选择器要求路径标签时，先由服务端按路径标签过滤
+			if opts.Label == "" {
+				opts.Label = selector.PathLabel()
+			}
 
-			var servicesToPrint []clientset.ProvisionListRow
-
-			if listAll {
-				allServices, err := cs.Services().ListAll(context.Background(), opts)
-				if err != nil {
-					return err
+			list := func(ctx context.Context) ([]clientset.ProvisionListRow, error) {
+				if listAll {
+					services, err := cs.Services().ListAll(ctx, opts)
+					if err != nil {
+						return nil, err
+					}
+					return sortedList(sorter, util.FilterServices(services, selector))
 				}
-				servicesToPrint = allServices
-			} else {
 				opts.PageNum = pageNum
-				serviceList, err := cs.Services().List(context.Background(), opts)
+				serviceList, err := cs.Services().List(ctx, opts)
 				if err != nil {
-					return err
+					return nil, err
 				}
-				servicesToPrint = serviceList.Items
+				return sortedList(sorter, util.FilterServices(serviceList.Items, selector))
 			}
 
-			if len(servicesToPrint) > 0 {
-				util.PrintServicesTable(os.Stdout, servicesToPrint)
-			} else {
-				fmt.Println("No services found.")
+			if watchOpts.watch {
+				return runWatch(watchOpts, printer, &util.ListWatcher[clientset.ProvisionListRow]{
+					List: list,
+					Key:  func(svc *clientset.ProvisionListRow) string { return svc.ID },
+				})
 			}
 
-			return nil
+			servicesToPrint, err := list(context.Background())
+			if err != nil {
+				return err
+			}
+			if len(servicesToPrint) == 0 && util.IsTableOutput(*output) {
+				fmt.Println("No services found.")
+				return nil
+			}
+			return printer.PrintObj(servicesToPrint, os.Stdout)
 		},
 	}
 
@@ -223,11 +339,16 @@ func newGetServicesCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&nameFilter, "name", "n", "", "Filter services by name (fuzzy match)")
 	cmd.Flags().StringVar(&imageID, "image-id", "", "Filter services by image ID")
 	cmd.Flags().StringVar(&nodeID, "node-id", "", "Filter services by node ID")
-	cmd.Flags().StringVarP(&labelFilter, "label", "l", "", "Filter services by path label (fuzzy match)")
+	cmd.Flags().StringVar(&labelFilter, "label", "", "Filter services by path label (fuzzy match)")
+	cmd.Flags().StringVarP(&selectorFilter, "selector", "l", "", "Label selector to filter on, supports 'key=value', 'key!=value', 'key' and '!key' separated by commas. The path label is matched as key "+util.PathLabelKey)
 
 	cmd.Flags().BoolVarP(&listAll, "all", "A", true, "List all pages of services (default behavior)")
 	cmd.Flags().IntVar(&pageNum, "page", 1, "Page number to retrieve (if --all=false)")
 	cmd.Flags().IntVar(&pageSize, "page-size", 100, "Number of items per page")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, true)
+	watchOpts.addFlags(cmd)
 
 	return cmd
 }
@@ -237,7 +358,11 @@ func newGetContainersCmd() *cobra.Command {
 	// 定义 get containers 命令的本地标志
 	var serviceFilter string
 	var nodeFilter string
+	var selectorFilter string
 	var listAll bool
+	var output, sortBy *string
+	var tableFlags *util.TableFlags
+	var watchOpts watchOptions
 
 	cmd := &cobra.Command{
 		Use:     "containers",
@@ -245,105 +370,338 @@ func newGetContainersCmd() *cobra.Command {
 		Aliases: []string{"container", "co"},
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "container")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			selector, err := util.ParseSelector(selectorFilter)
+			if err != nil {
+				return err
+			}
 			cs, err := util.NewClientsetFromFlags()
 			if err != nil {
 				return err
 			}
-			ctx := context.Background()
-
-			var containersToPrint []clientset.ContainerInfo
-
-			// --- 核心逻辑：根据标志决定如何获取容器 ---
-			if serviceFilter != "" {
-				// 按服务过滤
-				// 1. 智能查找 Service ID
-				serviceOpts := clientset.ListServicesOptions{Name: serviceFilter}
-				allServices, err := cs.Services().ListAll(ctx, serviceOpts)
+			list := func(ctx context.Context) ([]clientset.ContainerInfo, error) {
+				containers, err := listContainers(ctx, cs, serviceFilter, nodeFilter)
+				if err == nil && !selector.Empty() {
+					containers, err = filterContainersBySelector(ctx, cs, containers, selector)
+				}
 				if err != nil {
-					return fmt.Errorf("failed to list services to find service '%s': %w", serviceFilter, err)
+					return nil, err
 				}
+				return sortedList(sorter, containers)
+			}
 
-				if len(allServices) == 0 {
-					return fmt.Errorf("service '%s' not found", serviceFilter)
-				}
+			if watchOpts.watch {
+				return runWatch(watchOpts, printer, &util.ListWatcher[clientset.ContainerInfo]{
+					List: list,
+					Key:  func(c *clientset.ContainerInfo) string { return c.ID },
+					Normalize: func(c clientset.ContainerInfo) clientset.ContainerInfo {
+						// 运行时间和资源使用每次查询都不同
+						c.Uptime = 0
+						c.CPUUsage = clientset.CPUUsage{}
+						c.MemoryUsage, c.MemoryMaxUsage, c.SizeUsage = 0, 0, 0
+						return c
+					},
+				})
+			}
 
-				var targetServiceIDs []string
-				// List API 的 name 可能是模糊匹配，所以我们需要收集所有匹配项
-				for _, svc := range allServices {
-					targetServiceIDs = append(targetServiceIDs, svc.ID)
-				}
+			containersToPrint, err := list(context.Background())
+			if err != nil {
+				return err
+			}
 
-				// 2. 使用找到的 ID 列表来获取容器
-				containerOpts := clientset.ListContainersByServiceOptions{ServiceIDs: targetServiceIDs}
-				containersToPrint, err = cs.Containers().ListAllByService(ctx, containerOpts)
-				if err != nil {
-					return fmt.Errorf("failed to list containers for service(s) '%s': %w", serviceFilter, err)
-				}
+			// 打印结果
+			if len(containersToPrint) == 0 && util.IsTableOutput(*output) {
+				fmt.Println("No containers found.")
+				return nil
+			}
+			return printer.PrintObj(containersToPrint, os.Stdout)
+		},
+	}
 
-			} else if nodeFilter != "" {
-				// --- 按节点过滤 (已实现) ---
+	// 绑定本地标志
+	cmd.Flags().StringVarP(&serviceFilter, "service", "s", "", "Filter containers by service name or ID")
+	cmd.Flags().StringVarP(&nodeFilter, "node", "n", "", "Filter containers by node name or ID")
+	cmd.Flags().StringVarP(&selectorFilter, "selector", "l", "", "Label selector on the labels of the containers' services, supports 'key=value', 'key!=value', 'key' and '!key' separated by commas")
+	cmd.RegisterFlagCompletionFunc("service", completeFlagValues(listServiceNames))
+	cmd.RegisterFlagCompletionFunc("node", completeFlagValues(listNodeNames))
 
-				// 1. 智能查找 Node ID
-				nodeOpts := clientset.NodeListOptions{Name: nodeFilter}
-				allNodes, err := cs.Nodes().ListAll(ctx, nodeOpts)
-				if err != nil {
-					return fmt.Errorf("failed to list nodes to find node '%s': %w", nodeFilter, err)
-				}
+	cmd.Flags().BoolVarP(&listAll, "all", "A", true, "List all pages of containers (default behavior)")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	watchOpts.addFlags(cmd)
 
-				if len(allNodes) == 0 {
-					return fmt.Errorf("node '%s' not found", nodeFilter)
-				}
+	return cmd
+}
 
-				var targetNodeIDs []string
-				for _, node := range allNodes {
-					targetNodeIDs = append(targetNodeIDs, node.ID)
-				}
+// listContainers 列出 serviceFilter 或 nodeFilter 指定的服务或节点上的容器，两者都为空时列出所有服务的容器。
+func listContainers(ctx context.Context, cs clientset.Interface, serviceFilter, nodeFilter string) ([]clientset.ContainerInfo, error) {
+	var containers []clientset.ContainerInfo
+
+	// --- 核心逻辑：根据标志决定如何获取容器 ---
+	if serviceFilter != "" {
+		// 按服务过滤
+		// 1. 智能查找 Service ID
+		serviceOpts := clientset.ListServicesOptions{Name: serviceFilter}
+		allServices, err := cs.Services().ListAll(ctx, serviceOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services to find service '%s': %w", serviceFilter, err)
+		}
+
+		if len(allServices) == 0 {
+			return nil, fmt.Errorf("service '%s' not found", serviceFilter)
+		}
+
+		var targetServiceIDs []string
+		// List API 的 name 可能是模糊匹配，所以我们需要收集所有匹配项
+		for _, svc := range allServices {
+			targetServiceIDs = append(targetServiceIDs, svc.ID)
+		}
+
+		// 2. 使用找到的 ID 列表来获取容器
+		containerOpts := clientset.ListContainersByServiceOptions{ServiceIDs: targetServiceIDs}
+		containers, err = cs.Containers().ListAllByService(ctx, containerOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers for service(s) '%s': %w", serviceFilter, err)
+		}
+
+	} else if nodeFilter != "" {
+		// --- 按节点过滤 (已实现) ---
+
+		// 1. 智能查找 Node ID
+		nodeOpts := clientset.NodeListOptions{Name: nodeFilter}
+		allNodes, err := cs.Nodes().ListAll(ctx, nodeOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes to find node '%s': %w", nodeFilter, err)
+		}
+
+		if len(allNodes) == 0 {
+			return nil, fmt.Errorf("node '%s' not found", nodeFilter)
+		}
+
+		var targetNodeIDs []string
+		for _, node := range allNodes {
+			targetNodeIDs = append(targetNodeIDs, node.ID)
+		}
+
+		// 2. 使用找到的 ID 列表来获取容器
+		// (我们需要一个新的 ListAllContainersByNode 辅助函数)
+		containerOpts := clientset.ListContainersByNodeOptions{NodeIDs: targetNodeIDs}
+		containers, err = cs.Containers().ListAllByNode(ctx, containerOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers for node(s) '%s': %w", nodeFilter, err)
+		}
+
+	} else {
+		// 获取所有容器：遍历所有服务
+		allServices, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+
+		var allServiceIDs []string
+		for _, svc := range allServices {
+			allServiceIDs = append(allServiceIDs, svc.ID)
+		}
+
+		if len(allServiceIDs) > 0 {
+			opts := clientset.ListContainersByServiceOptions{ServiceIDs: allServiceIDs}
+			containers, err = cs.Containers().ListAllByService(ctx, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list containers: %w", err)
+			}
+		}
+	}
+	return containers, nil
+}
+
+// filterContainersBySelector 返回所属服务满足选择器的容器，容器本身没有标签。
+func filterContainersBySelector(ctx context.Context, cs clientset.Interface, containers []clientset.ContainerInfo, selector util.Selector) ([]clientset.ContainerInfo, error) {
+	services, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{Label: selector.PathLabel()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services to match selector: %w", err)
+	}
+	matched := make(map[string]bool)
+	for _, svc := range util.FilterServices(services, selector) {
+		matched[svc.ID] = true
+	}
+
+	var result []clientset.ContainerInfo
+	for _, c := range containers {
+		if matched[c.ServiceID] {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// allResources 是 "get all" 以 json、yaml 等格式输出的对象
+type allResources struct {
+	Nodes      []clientset.NodeInfo         `json:"nodes"`
+	Services   []clientset.ProvisionListRow `json:"services"`
+	Containers []clientset.ContainerInfo    `json:"containers"`
+}
+
+// newGetAllCmd 创建 "get all" 子命令
+func newGetAllCmd() *cobra.Command {
+	var output, sortBy *string
+	var tableFlags *util.TableFlags
+
+	cmd := &cobra.Command{
+		Use:   "all",
+		Short: "Display the nodes, services and containers of the ECSM platform",
+		Long: `Prints the nodes, services and containers of the ECSM platform as separate
+tables, which gives an overview of the platform in one command. The three
+lists are fetched concurrently; if one of them cannot be fetched the others
+are still printed and the command fails afterwards.
+
+With -o json or yaml a single object with the fields nodes, services and
+containers is printed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := util.NewPrinter(*output, "node"); err != nil {
+				return err
+			}
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
 
-				// 2. 使用找到的 ID 列表来获取容器
-				// (我们需要一个新的 ListAllContainersByNode 辅助函数)
-				containerOpts := clientset.ListContainersByNodeOptions{NodeIDs: targetNodeIDs}
-				containersToPrint, err = cs.Containers().ListAllByNode(ctx, containerOpts)
+			all := allResources{
+				Nodes:      []clientset.NodeInfo{},
+				Services:   []clientset.ProvisionListRow{},
+				Containers: []clientset.ContainerInfo{},
+			}
+			var nodesErr, servicesErr, containersErr error
+			var wg sync.WaitGroup
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				nodes, err := cs.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+				for i := range nodes {
+					// 平台在节点列表中返回了节点的密码，不应该输出它
+					nodes[i].Password = ""
+				}
 				if err != nil {
-					return fmt.Errorf("failed to list containers for node(s) '%s': %w", nodeFilter, err)
+					nodesErr = fmt.Errorf("failed to list nodes: %w", err)
+				} else if nodes != nil {
+					all.Nodes = nodes
 				}
-
-			} else {
-				// 获取所有容器：遍历所有服务
-				allServices, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{})
+			}()
+			go func() {
+				defer wg.Done()
+				services, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{})
 				if err != nil {
-					return fmt.Errorf("failed to list services: %w", err)
+					servicesErr = fmt.Errorf("failed to list services: %w", err)
+				} else if services != nil {
+					all.Services = services
 				}
-
-				var allServiceIDs []string
-				for _, svc := range allServices {
-					allServiceIDs = append(allServiceIDs, svc.ID)
+			}()
+			go func() {
+				defer wg.Done()
+				containers, err := listContainers(ctx, cs, "", "")
+				if err != nil {
+					containersErr = err
+				} else if containers != nil {
+					all.Containers = containers
 				}
-
-				if len(allServiceIDs) > 0 {
-					opts := clientset.ListContainersByServiceOptions{ServiceIDs: allServiceIDs}
-					containersToPrint, err = cs.Containers().ListAllByService(ctx, opts)
-					if err != nil {
-						return fmt.Errorf("failed to list containers: %w", err)
-					}
+			}()
+			wg.Wait()
+			fetchErr := errors.Join(nodesErr, servicesErr, containersErr)
+			for _, list := range []interface{}{all.Nodes, all.Services, all.Containers} {
+				if err := sorter.Sort(list); err != nil {
+					return err
 				}
 			}
 
-			// 打印结果
-			if len(containersToPrint) > 0 {
-				util.PrintContainersTable(os.Stdout, containersToPrint)
-			} else {
-				fmt.Println("No containers found.")
+			if !util.IsTableOutput(*output) {
+				if err := printAllResources(all, *output); err != nil {
+					return err
+				}
+				return fetchErr
 			}
 
-			return nil
+			sections := []struct {
+				title string
+				kind  string
+				items interface{}
+				count int
+				err   error
+			}{
+				{"NODES", "node", all.Nodes, len(all.Nodes), nodesErr},
+				{"SERVICES", "service", all.Services, len(all.Services), servicesErr},
+				{"CONTAINERS", "container", all.Containers, len(all.Containers), containersErr},
+			}
+			first := true
+			for _, s := range sections {
+				// 获取失败的部分只在最后的错误中报告
+				if s.err != nil || s.count == 0 {
+					continue
+				}
+				if !first {
+					fmt.Println()
+				}
+				first = false
+				if !tableFlags.NoHeaders {
+					fmt.Println(s.title)
+				}
+				printer, _ := util.NewPrinter(*output, s.kind)
+				tableFlags.Apply(printer)
+				if err := printer.PrintObj(s.items, os.Stdout); err != nil {
+					return err
+				}
+			}
+			if first && fetchErr == nil {
+				fmt.Println("No resources found.")
+			}
+			return fetchErr
 		},
 	}
 
-	// 绑定本地标志
-	cmd.Flags().StringVarP(&serviceFilter, "service", "s", "", "Filter containers by service name or ID")
-	cmd.Flags().StringVarP(&nodeFilter, "node", "n", "", "Filter containers by node name or ID")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	return cmd
+}
 
-	cmd.Flags().BoolVarP(&listAll, "all", "A", true, "List all pages of containers (default behavior)")
+// printAllResources 以非表格的格式打印 all。name 格式分别打印三种资源的名称，其他格式打印整个对象。
+func printAllResources(all allResources, output string) error {
+	if output != util.OutputName {
+		printer, err := util.NewPrinter(output, "")
+		if err != nil {
+			return err
+		}
+		return printer.PrintObj(all, os.Stdout)
+	}
+	for _, r := range []struct {
+		kind  string
+		items interface{}
+	}{{"node", all.Nodes}, {"service", all.Services}, {"container", all.Containers}} {
+		printer, _ := util.NewPrinter(output, r.kind)
+		if err := printer.PrintObj(r.items, os.Stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	return cmd
+// sortedList 对 list 排序后返回它，用于列出资源的函数的返回语句。
+func sortedList[T any](sorter *util.Sorter, list []T) ([]T, error) {
+	if err := sorter.Sort(list); err != nil {
+		return nil, err
+	}
+	return list, nil
 }
@@ -0,0 +1,386 @@
+// file: cmd/ecsm-cli/cmd/image.go
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+// newImageCmd 创建 "image" 命令，它管理 ECSM 平台镜像仓库中的镜像
+func newImageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "image",
+		Short:   "Manage the images in the registries of the ECSM platform",
+		Aliases: []string{"img"},
+		Long: `Uploads, pulls and removes images. Images are referenced as NAME@TAG[#OS],
+like in "get images" and "describe image".`,
+	}
+
+	cmd.AddCommand(newImagePushCmd())
+	cmd.AddCommand(newImagePullCmd())
+	cmd.AddCommand(newImageRmCmd())
+	cmd.AddCommand(newImagePruneCmd())
+	return cmd
+}
+
+// newImagePushCmd 创建 "image push" 子命令
+func newImagePushCmd() *cobra.Command {
+	var (
+		registryID string
+		quiet      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "push <TARBALL>",
+		Short:   "Upload an OCI image bundle to a registry",
+		Aliases: []string{"import"},
+		Long: `Uploads an OCI image bundle (a tar file) to a registry of the ECSM platform,
+the local registry by default. While uploading, the progress is shown on
+standard error if it is a terminal.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			// 进度在同一行上刷新，只在终端上显示
+			var progressOut io.Writer
+			if !quiet && util.IsTerminal(os.Stderr) {
+				progressOut = os.Stderr
+			}
+			progress := util.NewProgressReader(f, info.Size(), "Uploading "+filepath.Base(args[0]), progressOut)
+			resp, err := cs.Images().Push(context.Background(), progress, clientset.ImagePushOptions{
+				RegistryID: registryID,
+				FileName:   filepath.Base(args[0]),
+			})
+			progress.Done()
+			if err != nil {
+				return fmt.Errorf("failed to push image %s: %w", args[0], err)
+			}
+
+			name := resp.Ref
+			if name == "" {
+				name = resp.ID
+			}
+			fmt.Fprintf(os.Stdout, "image/%s pushed\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&registryID, "registry-id", clientset.LocalRegistryID, "The ID of the registry to upload to")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Do not show the upload progress")
+	return cmd
+}
+
+// newImagePullCmd 创建 "image pull" 子命令
+func newImagePullCmd() *cobra.Command {
+	var (
+		registryID string
+		wait       bool
+		timeout    time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pull <NAME@TAG[#OS]> --registry-id REGISTRY_ID",
+		Short: "Pull an image from a remote registry into the local registry",
+		Long: `Pulls an image from a remote registry of the ECSM platform into the local
+registry. The pull runs asynchronously on the platform; with --wait the
+command blocks until it has finished.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listImageRefs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if registryID == "" || registryID == clientset.LocalRegistryID {
+				return fmt.Errorf("--registry-id must be the ID of a remote registry")
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			resp, err := cs.Images().Pull(ctx, clientset.ImagePullOptions{RegistryID: registryID, Ref: args[0]})
+			if err != nil {
+				return fmt.Errorf("failed to pull image %s: %w", args[0], err)
+			}
+			if !wait {
+				fmt.Fprintf(os.Stdout, "image/%s pull requested (transaction %s)\n", args[0], resp.ID)
+				return nil
+			}
+			if err := util.WaitForTransaction(ctx, cs, resp.ID, timeout); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "image/%s pulled\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&registryID, "registry-id", "", "The ID of the remote registry to pull from")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait until the pull has finished")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "How long to wait with --wait")
+	return cmd
+}
+
+// newImageRmCmd 创建 "image rm" 子命令
+func newImageRmCmd() *cobra.Command {
+	var registryID string
+
+	cmd := &cobra.Command{
+		Use:     "rm <NAME@TAG[#OS]>...",
+		Short:   "Remove images from a registry",
+		Aliases: []string{"delete"},
+		Args:    cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			return filterNames(cmd, toComplete, listImageRefs), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			var errs []error
+			for _, ref := range args {
+				details, err := cs.Images().GetDetailsByRef(ctx, registryID, ref)
+				if err == nil {
+					err = cs.Images().Delete(ctx, registryID, details.ID)
+				}
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to remove image %s: %w", ref, err))
+					continue
+				}
+				fmt.Fprintf(os.Stdout, "image/%s removed\n", ref)
+			}
+			return errors.Join(errs...)
+		},
+	}
+
+	cmd.Flags().StringVar(&registryID, "registry-id", clientset.LocalRegistryID, "The ID of the registry to remove the images from")
+	return cmd
+}
+
+// newImagePruneCmd 创建 "image prune" 子命令
+func newImagePruneCmd() *cobra.Command {
+	var (
+		registryID     string
+		dryRun         bool
+		force          bool
+		ignoreRegistry bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune [--force]",
+		Short: "Remove the images that no service uses",
+		Long: `Lists the images of a registry, the local registry by default, that are not
+used by any service on the ECSM platform, and removes them after confirmation.
+
+The images referenced by the ECSMServices, ECSMJobs, ECSMCronJobs and
+ECSMNodeSets of the operator are kept as well, even if the operator has not
+deployed them yet. They are read through the operator's API server given with
+--server, or from its registry database given with --registry-db while the
+operator is stopped. Such references without an OS ("name@tag") keep the image
+for every OS. Without either flag the command refuses to run, because it would
+remove the images of objects that are not deployed yet; pass --ignore-registry
+to prune against the services on the ECSM platform only.
+
+The images are removed after answering the confirmation prompt, or without
+asking with --force. When standard input is not a terminal and --force is not
+given, the images are only listed. With --dry-run the images are only listed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !util.HasRegistryFlags() && !ignoreRegistry {
+				return fmt.Errorf("--server or --registry-db is required to keep the images of objects the operator has not deployed yet, use --ignore-registry to prune without them")
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			var reg registry.Interface
+			if util.HasRegistryFlags() {
+				r, closeFn, openErr := util.NewRegistryFromFlags()
+				if openErr != nil {
+					return openErr
+				}
+				defer closeFn()
+				reg = r
+			}
+
+			images, err := unusedImages(ctx, cs, reg, registryID)
+			if err != nil {
+				return err
+			}
+			if len(images) == 0 {
+				fmt.Fprintln(os.Stdout, "No unused images found.")
+				return nil
+			}
+
+			var reclaimable float64
+			for _, image := range images {
+				reclaimable += image.Size
+				fmt.Fprintf(os.Stdout, "image/%s\t%.2f MB\n", image.Ref(), image.Size)
+			}
+			fmt.Fprintf(os.Stdout, "Total reclaimable space: %.2f MB\n", reclaimable)
+			if dryRun {
+				return nil
+			}
+			if !force {
+				if !util.IsTerminal(os.Stdin) {
+					fmt.Fprintln(os.Stderr, "Run with --force to remove them.")
+					return nil
+				}
+				ok, err := confirm(os.Stdin, os.Stderr, fmt.Sprintf("Remove %d images? [y/N]: ", len(images)))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Fprintln(os.Stderr, "Aborted, no images were removed.")
+					return nil
+				}
+			}
+
+			var reclaimed float64
+			var errs []error
+			for _, image := range images {
+				ref := image.Ref()
+				if err := cs.Images().Delete(ctx, registryID, image.ID); err != nil {
+					errs = append(errs, fmt.Errorf("failed to remove image %s: %w", ref, err))
+					continue
+				}
+				reclaimed += image.Size
+				fmt.Fprintf(os.Stdout, "image/%s removed\n", ref)
+			}
+			fmt.Fprintf(os.Stdout, "Total reclaimed space: %.2f MB\n", reclaimed)
+			return errors.Join(errs...)
+		},
+	}
+
+	cmd.Flags().StringVar(&registryID, "registry-id", clientset.LocalRegistryID, "The ID of the registry to prune")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only list the images that would be removed, without asking")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Remove the images without asking for confirmation")
+	cmd.Flags().BoolVar(&ignoreRegistry, "ignore-registry", false, "Prune without --server or --registry-db, removing the images of objects the operator has not deployed yet")
+	return cmd
+}
+
+// confirm 在 out 上显示 prompt 并从 in 读取一行，回答 y 或 yes (不区分大小写) 时返回 true。
+func confirm(in io.Reader, out io.Writer, prompt string) (bool, error) {
+	fmt.Fprint(out, prompt)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read the answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// imagePruneClient 是 image prune 使用的 ECSM 客户端，clientset.Interface 不包括镜像客户端。
+type imagePruneClient interface {
+	clientset.ServiceGetter
+	clientset.ImageGetter
+}
+
+// unusedImages 返回仓库 registryID 中没有被平台上的服务引用的镜像。
+// reg 不为 nil 时，Registry 中的对象引用的镜像也被保留。
+func unusedImages(ctx context.Context, cs imagePruneClient, reg registry.Interface, registryID string) ([]clientset.ImageListItem, error) {
+	used, err := usedImageRefs(ctx, cs, reg)
+	if err != nil {
+		return nil, err
+	}
+	images, err := cs.Images().ListAll(ctx, clientset.ImageListOptions{RegistryID: registryID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	var unused []clientset.ImageListItem
+	for _, image := range images {
+		if used[image.Name+"@"+image.Tag] || used[image.Name+"@"+image.Tag+"#"+image.OS] {
+			continue
+		}
+		unused = append(unused, image)
+	}
+	return unused, nil
+}
+
+// usedImageRefs 返回被平台上的服务引用的镜像，形式为 NAME@TAG#OS。
+// reg 不为 nil 时还包括 Registry 中的对象引用的镜像，它们的形式为 NAME@TAG 或 NAME@TAG#OS。
+func usedImageRefs(ctx context.Context, cs clientset.ServiceGetter, reg registry.Interface) (map[string]bool, error) {
+	used := map[string]bool{}
+	services, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, service := range services {
+		for _, image := range service.ImageList {
+			used[image.Name+"@"+image.Tag+"#"+image.OS] = true
+		}
+	}
+
+	if reg == nil {
+		return used, nil
+	}
+
+	var templates []ecsmv1.ContainerTemplateSpec
+	serviceList, _, err := reg.ListAllServices(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ECSMServices: %w", err)
+	}
+	for _, service := range serviceList.Items {
+		templates = append(templates, service.Spec.Template)
+	}
+	jobList, _, err := reg.ListJobs(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ECSMJobs: %w", err)
+	}
+	for _, job := range jobList.Items {
+		templates = append(templates, job.Spec.Template)
+	}
+	cronJobList, _, err := reg.ListCronJobs(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ECSMCronJobs: %w", err)
+	}
+	for _, cronJob := range cronJobList.Items {
+		templates = append(templates, cronJob.Spec.JobTemplate.Spec.Template)
+	}
+	nodeSetList, _, err := reg.ListNodeSets(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ECSMNodeSets: %w", err)
+	}
+	for _, nodeSet := range nodeSetList.Items {
+		templates = append(templates, nodeSet.Spec.Template)
+	}
+
+	for _, template := range templates {
+		if ref := strings.TrimSpace(template.Image); ref != "" {
+			used[ref] = true
+		}
+	}
+	return used, nil
+}
@@ -0,0 +1,77 @@
+// file: cmd/ecsm-cli/cmd/image_test.go
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset/fake"
+)
+
+// TestUnusedImages 测试 image prune 保留平台上的服务和 Registry 中尚未部署的 ECSMService 引用的镜像。
+func TestUnusedImages(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	cs := fake.NewClientset()
+	cs.Tracker().AddNode(&clientset.NodeInfo{Name: "node-1"})
+	for _, name := range []string{"deployed", "pending", "old"} {
+		cs.Tracker().AddImage(clientset.LocalRegistryID, &clientset.ImageDetails{Name: name, Tag: "1.0", OS: "sylixos"})
+	}
+	if _, err := cs.Services().Create(ctx, &clientset.CreateServiceRequest{
+		Name:  "deployed",
+		Image: clientset.ImageSpec{Ref: "deployed@1.0#sylixos"},
+	}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// pending 只被还没有部署的 ECSMService 引用
+	if _, err := reg.CreateService(ctx, newTestECSMService("pending")); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	refs := func(images []clientset.ImageListItem) []string {
+		var refs []string
+		for _, image := range images {
+			refs = append(refs, image.Ref())
+		}
+		slices.Sort(refs)
+		return refs
+	}
+
+	images, err := unusedImages(ctx, cs, c, clientset.LocalRegistryID)
+	if err != nil {
+		t.Fatalf("unusedImages failed: %v", err)
+	}
+	if got, want := refs(images), []string{"old@1.0#sylixos"}; !slices.Equal(got, want) {
+		t.Errorf("Expected unused images %v, got %v", want, got)
+	}
+
+	// 没有 Registry 时只参考平台上的服务
+	images, err = unusedImages(ctx, cs, nil, clientset.LocalRegistryID)
+	if err != nil {
+		t.Fatalf("unusedImages failed: %v", err)
+	}
+	if got, want := refs(images), []string{"old@1.0#sylixos", "pending@1.0#sylixos"}; !slices.Equal(got, want) {
+		t.Errorf("Expected unused images %v without the registry, got %v", want, got)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	for answer, want := range map[string]bool{
+		"y\n":   true,
+		"YES\n": true,
+		"n\n":   false,
+		"\n":    false,
+		"":      false,
+	} {
+		got, err := confirm(strings.NewReader(answer), io.Discard, "Remove? [y/N]: ")
+		if err != nil || got != want {
+			t.Errorf("confirm(%q) = %v, %v, want %v", answer, got, err, want)
+		}
+	}
+}
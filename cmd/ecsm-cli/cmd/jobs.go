@@ -0,0 +1,136 @@
+// file: cmd/ecsm-cli/cmd/jobs.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// newGetJobsCmd 创建 "get jobs" 子命令，它从 operator 的 Registry 中读取 ECSMJob
+func newGetJobsCmd() *cobra.Command {
+	var (
+		namespace     string
+		allNamespaces bool
+		output        *string
+		sortBy        *string
+		tableFlags    *util.TableFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Display a list of ECSMJobs",
+		Long: `Lists the ECSMJobs managed by the ecsm-operator and their progress. Jobs are
+read from the operator's API server given with --server, or from its registry
+database given with --registry-db while the operator is stopped.`,
+		Aliases: []string{"job"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "ecsmjob")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			if allNamespaces {
+				namespace = ""
+			}
+
+			reg, closeFn, err := util.NewRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			list, _, err := reg.ListJobs(context.Background(), namespace)
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+
+			if len(list.Items) == 0 && util.IsTableOutput(*output) {
+				fmt.Fprintln(os.Stdout, "No jobs found.")
+				return nil
+			}
+			if err := sorter.Sort(list.Items); err != nil {
+				return err
+			}
+			return printer.PrintObj(list.Items, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the jobs to list")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List jobs across all namespaces")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	return cmd
+}
+
+// newGetCronJobsCmd 创建 "get cronjobs" 子命令，它从 operator 的 Registry 中读取 ECSMCronJob
+func newGetCronJobsCmd() *cobra.Command {
+	var (
+		namespace     string
+		allNamespaces bool
+		output        *string
+		sortBy        *string
+		tableFlags    *util.TableFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cronjobs",
+		Short: "Display a list of ECSMCronJobs",
+		Long: `Lists the ECSMCronJobs managed by the ecsm-operator and when they last ran.
+CronJobs are read from the operator's API server given with --server, or from its
+registry database given with --registry-db while the operator is stopped.`,
+		Aliases: []string{"cronjob", "cj"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "ecsmcronjob")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			if allNamespaces {
+				namespace = ""
+			}
+
+			reg, closeFn, err := util.NewRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			list, _, err := reg.ListCronJobs(context.Background(), namespace)
+			if err != nil {
+				return fmt.Errorf("failed to list cronjobs: %w", err)
+			}
+
+			if len(list.Items) == 0 && util.IsTableOutput(*output) {
+				fmt.Fprintln(os.Stdout, "No cronjobs found.")
+				return nil
+			}
+			if err := sorter.Sort(list.Items); err != nil {
+				return err
+			}
+			return printer.PrintObj(list.Items, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the cronjobs to list")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List cronjobs across all namespaces")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	return cmd
+}
@@ -0,0 +1,151 @@
+// file: cmd/ecsm-cli/cmd/logs.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// logPollInterval 是 --follow 查询新日志的间隔，ECSM 平台不支持流式返回日志
+const logPollInterval = time.Second
+
+// newLogsCmd 创建 "logs" 命令
+func newLogsCmd() *cobra.Command {
+	var (
+		tail       int
+		since      time.Duration
+		follow     bool
+		timestamps bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs <CONTAINER_NAME>",
+		Short: "Print the logs of a container",
+		Long: `Prints the output of a container of the ECSM platform.
+
+With --follow the command keeps polling the platform for new output until it
+is interrupted.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listContainerNames),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since < 0 {
+				return fmt.Errorf("--since must not be negative")
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			container, err := cs.Containers().GetByName(ctx, cs.Services(), args[0])
+			if err != nil {
+				return err
+			}
+
+			opts := clientset.ContainerLogOptions{TaskID: container.TaskID}
+			if tail > 0 {
+				opts.Tail = tail
+			}
+			if since > 0 {
+				opts.Since = time.Now().Add(-since).UnixMilli()
+			}
+
+			cursor := &logCursor{last: opts.Since}
+			printLogs := func(entries []clientset.ContainerLogEntry) {
+				for _, entry := range cursor.next(entries) {
+					printLogEntry(os.Stdout, entry, timestamps)
+				}
+			}
+
+			if tail != 0 {
+				logs, err := cs.Containers().Logs(ctx, opts)
+				if err != nil {
+					return fmt.Errorf("failed to get logs of container %s: %w", args[0], err)
+				}
+				printLogs(logs.Items)
+			} else {
+				// --tail=0 不打印已有的日志，--follow 时只打印之后的日志
+				cursor.last = time.Now().UnixMilli()
+			}
+			if !follow {
+				return nil
+			}
+
+			err = wait.PollUntilContextCancel(ctx, logPollInterval, false, func(ctx context.Context) (bool, error) {
+				logs, err := cs.Containers().Logs(ctx, clientset.ContainerLogOptions{TaskID: container.TaskID, Since: cursor.last})
+				if err != nil {
+					return false, fmt.Errorf("failed to get logs of container %s: %w", args[0], err)
+				}
+				printLogs(logs.Items)
+				return false, nil
+			})
+			if ctx.Err() != nil {
+				// 用户中断了 --follow，不是错误
+				return nil
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().IntVar(&tail, "tail", -1, "Number of most recent log lines to print, -1 prints all lines")
+	cmd.Flags().DurationVar(&since, "since", 0, "Only print logs newer than a relative duration like 5s, 2m or 3h")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new logs until interrupted")
+	cmd.Flags().BoolVar(&timestamps, "timestamps", false, "Prefix each log line with its timestamp")
+	return cmd
+}
+
+// logCursor 记录已经打印到的位置。平台按毫秒时间戳过滤日志，同一毫秒可能有多条日志，
+// 因此还要记录最后一个时间戳已经打印的条数，避免重复打印。
+type logCursor struct {
+	last          int64
+	printedAtLast int
+}
+
+// next 返回 entries 中还没有打印的日志，并把它们记为已打印。entries 必须按时间排序。
+func (c *logCursor) next(entries []clientset.ContainerLogEntry) []clientset.ContainerLogEntry {
+	var result []clientset.ContainerLogEntry
+	seenAtLast := 0
+	for _, entry := range entries {
+		if entry.Timestamp < c.last {
+			continue
+		}
+		if entry.Timestamp == c.last {
+			seenAtLast++
+			if seenAtLast <= c.printedAtLast {
+				continue
+			}
+		}
+		result = append(result, entry)
+	}
+
+	for _, entry := range result {
+		if entry.Timestamp > c.last {
+			c.last, c.printedAtLast = entry.Timestamp, 0
+		}
+		c.printedAtLast++
+	}
+	return result
+}
+
+// printLogEntry 打印一条日志，日志本身没有换行符时补上换行符。
+func printLogEntry(out io.Writer, entry clientset.ContainerLogEntry, timestamps bool) {
+	if timestamps {
+		fmt.Fprintf(out, "%s ", time.UnixMilli(entry.Timestamp).UTC().Format(time.RFC3339Nano))
+	}
+	fmt.Fprint(out, entry.Message)
+	if !strings.HasSuffix(entry.Message, "\n") {
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,254 @@
+// file: cmd/ecsm-cli/cmd/node.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+)
+
+// newNodeCmd 创建 "node" 命令，它注册、修改和移除 ECSM 平台上的节点
+func newNodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "node",
+		Short:   "Register, update and remove the nodes of the ECSM platform",
+		Aliases: []string{"no"},
+		Long: `Manages the nodes of the ECSM platform. Node passwords are never given as
+flags: they are prompted for without echo when standard input is a terminal,
+and read from the first line of standard input otherwise, for example:
+
+  cat password.txt | ecsm-cli node register --name edge-1 --address 10.0.0.5`,
+	}
+
+	cmd.AddCommand(newNodeRegisterCmd())
+	cmd.AddCommand(newNodeUpdateCmd())
+	cmd.AddCommand(newNodeRmCmd())
+	return cmd
+}
+
+// newNodeRegisterCmd 创建 "node register" 子命令
+func newNodeRegisterCmd() *cobra.Command {
+	var (
+		name    string
+		address string
+		tls     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "register --name NAME --address ADDRESS",
+		Short: "Register a new node",
+		Long: `Registers a new node with the ECSM platform. The name and the address are
+checked to be unused before the node is registered.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name == "" || address == "" {
+				return fmt.Errorf("--name and --address must be specified")
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			if err := validateNode(ctx, cs, name, address, tls, ""); err != nil {
+				return err
+			}
+			password, err := util.ReadPassword(fmt.Sprintf("Password for node %s: ", name))
+			if err != nil {
+				return err
+			}
+			if password == "" {
+				return fmt.Errorf("the node password must not be empty")
+			}
+
+			err = cs.Nodes().Register(ctx, &clientset.NodeRegisterRequest{
+				Address:  address,
+				Name:     name,
+				Password: password,
+				TLS:      &tls,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to register node %s: %w", name, err)
+			}
+			fmt.Fprintf(os.Stdout, "node/%s registered\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "The name of the node")
+	cmd.Flags().StringVar(&address, "address", "", "The address of the node")
+	cmd.Flags().BoolVar(&tls, "tls", false, "Connect to the node over TLS")
+	return cmd
+}
+
+// newNodeUpdateCmd 创建 "node update" 子命令
+func newNodeUpdateCmd() *cobra.Command {
+	var (
+		name           string
+		address        string
+		tls            bool
+		changePassword bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update <NODE_NAME_OR_ID>",
+		Short: "Update the name, address or password of a node",
+		Long: `Updates a registered node. Only the given settings are changed, the others
+keep their current values. With --change-password the new password is read
+like in "node register".`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listNodeNames),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			if !flags.Changed("name") && !flags.Changed("address") && !flags.Changed("tls") && !changePassword {
+				return fmt.Errorf("nothing to update, specify --name, --address, --tls or --change-password")
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			nodeID, err := util.ResolveNodeID(ctx, cs, args[0])
+			if err != nil {
+				return err
+			}
+			current, err := cs.Nodes().GetByID(ctx, nodeID)
+			if err != nil {
+				return fmt.Errorf("failed to get node %s: %w", args[0], err)
+			}
+
+			// 平台的修改接口要求提供完整的节点信息，没有指定的设置沿用当前值
+			req := &clientset.NodeUpdateRequest{
+				ID:       nodeID,
+				Address:  current.Address,
+				Name:     current.Name,
+				Password: current.Password,
+				TLS:      current.TLS,
+			}
+			if flags.Changed("name") {
+				req.Name = name
+			}
+			if flags.Changed("address") {
+				req.Address = address
+			}
+			if flags.Changed("tls") {
+				req.TLS = tls
+			}
+			if err := validateNode(ctx, cs, req.Name, req.Address, req.TLS, nodeID); err != nil {
+				return err
+			}
+			if changePassword {
+				if req.Password, err = util.ReadPassword(fmt.Sprintf("New password for node %s: ", req.Name)); err != nil {
+					return err
+				}
+				if req.Password == "" {
+					return fmt.Errorf("the node password must not be empty")
+				}
+			}
+
+			if err := cs.Nodes().Update(ctx, nodeID, req); err != nil {
+				return fmt.Errorf("failed to update node %s: %w", args[0], err)
+			}
+			fmt.Fprintf(os.Stdout, "node/%s updated\n", req.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "The new name of the node")
+	cmd.Flags().StringVar(&address, "address", "", "The new address of the node")
+	cmd.Flags().BoolVar(&tls, "tls", false, "Connect to the node over TLS")
+	cmd.Flags().BoolVar(&changePassword, "change-password", false, "Prompt for a new password of the node")
+	return cmd
+}
+
+// newNodeRmCmd 创建 "node rm" 子命令
+func newNodeRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm <NODE_NAME_OR_ID>...",
+		Short:   "Remove nodes",
+		Aliases: []string{"delete"},
+		Long: `Removes nodes from the ECSM platform. Nodes that still run services cannot be
+removed; they are listed together with their services, and the other nodes
+are removed.`,
+		Args: cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			return filterNames(cmd, toComplete, listNodeNames), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			ids := make([]string, 0, len(args))
+			names := make(map[string]string, len(args))
+			for _, arg := range args {
+				nodeID, err := util.ResolveNodeID(ctx, cs, arg)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, nodeID)
+				names[nodeID] = arg
+			}
+
+			conflicts, err := cs.Nodes().Delete(ctx, ids)
+			if err != nil {
+				return fmt.Errorf("failed to remove nodes: %w", err)
+			}
+			inUse := make(map[string]bool, len(conflicts))
+			for _, c := range conflicts {
+				inUse[c.ID] = true
+			}
+			for _, id := range ids {
+				if !inUse[id] {
+					fmt.Fprintf(os.Stdout, "node/%s removed\n", names[id])
+				}
+			}
+			return nodeConflictsError(conflicts)
+		},
+	}
+}
+
+// validateNode 检查节点的名称和地址没有被其他节点使用，excludeID 是正在修改的节点，注册时为空。
+func validateNode(ctx context.Context, cs clientset.Interface, name, address string, tls bool, excludeID string) error {
+	result, err := cs.Nodes().ValidateName(ctx, clientset.NodeValidateNameOptions{Name: name, ExcludeID: excludeID})
+	if err != nil {
+		return fmt.Errorf("failed to validate node name %s: %w", name, err)
+	}
+	if !result.IsValid {
+		return errors.New(result.Message)
+	}
+	result, err = cs.Nodes().ValidateAddress(ctx, clientset.NodeValidateAddressOptions{Address: address, ExcludeID: excludeID, TLS: &tls})
+	if err != nil {
+		return fmt.Errorf("failed to validate node address %s: %w", address, err)
+	}
+	if !result.IsValid {
+		return errors.New(result.Message)
+	}
+	return nil
+}
+
+// nodeConflictsError 把平台拒绝删除的节点及占用它们的服务转换成一个错误，没有冲突时返回 nil。
+func nodeConflictsError(conflicts []clientset.NodeDeleteConflict) error {
+	if len(conflicts) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		services := make([]string, 0, len(c.Serves))
+		for _, svc := range c.Serves {
+			services = append(services, svc.Name)
+		}
+		lines = append(lines, fmt.Sprintf("node %s is still used by services: %s", c.Name, strings.Join(services, ", ")))
+	}
+	return errors.New(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,42 @@
+// file: cmd/ecsm-cli/cmd/registry_test.go
+
+package cmd
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/apiserver"
+	"github.com/fx147/ecsm-operator/pkg/apiserver/client"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	bolt "go.etcd.io/bbolt"
+)
+
+// newTestRemoteRegistry 启动一个使用临时 Registry 的 operator API Server，
+// 返回命令通过 --server 访问它时使用的 Client，以及用来准备数据和检查结果的底层 Registry。
+func newTestRemoteRegistry(t *testing.T) (*client.Client, *registry.Registry) {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	api, err := apiserver.New(reg, nil)
+	if err != nil {
+		t.Fatalf("Failed to create API server: %v", err)
+	}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	t.Cleanup(api.Close)
+
+	c, err := client.New(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return c, reg
+}
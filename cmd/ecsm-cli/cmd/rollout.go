@@ -0,0 +1,258 @@
+// file: cmd/ecsm-cli/cmd/rollout.go
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// rolloutPollInterval 是 rollout status 重新读取服务状态的间隔
+const rolloutPollInterval = 2 * time.Second
+
+// newRolloutCmd 创建 "rollout" 命令
+func newRolloutCmd() *cobra.Command {
+	var namespace string
+
+	cmd := &cobra.Command{
+		Use:   "rollout SUBCOMMAND",
+		Short: "Manage the rollout of an ECSMService",
+		Long: `Manages the rollout of an ECSMService: waits for a rollout to finish, restarts
+the containers of a service, or reverts a service to its previous template.
+
+The services are read and updated through the operator's API server given with
+--server. While the operator is stopped they can instead be read from its
+registry database given with --registry-db.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the service")
+
+	cmd.AddCommand(newRolloutStatusCmd(&namespace))
+	cmd.AddCommand(newRolloutRestartCmd(&namespace))
+	cmd.AddCommand(newRolloutUndoCmd(&namespace))
+	return cmd
+}
+
+// newRolloutStatusCmd 创建 "rollout status" 子命令
+func newRolloutStatusCmd(namespace *string) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "status service SERVICE_NAME",
+		Short: "Wait for the rollout of an ECSMService to finish",
+		Long: `Watches the status of an ECSMService until the controller has rolled out its
+current spec: all replicas run the current template, no old replicas are left
+and all replicas are ready. Fails if the service becomes Degraded or the
+rollout does not finish within --timeout.`,
+		Args:              serviceArgs,
+		ValidArgsFunction: completeServiceArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[1]
+			// 整个等待期间使用同一个 Registry，通过 API Server 访问时不会占用 operator 的数据库
+			reg, closeFn, err := util.NewRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			lastMessage := ""
+			err = wait.PollUntilContextTimeout(context.Background(), rolloutPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+				message, done, err := getRolloutStatus(ctx, reg, *namespace, name)
+				if err != nil {
+					return false, err
+				}
+				// 只在进度变化时打印，避免刷屏
+				if message != lastMessage {
+					fmt.Fprintln(os.Stdout, message)
+					lastMessage = message
+				}
+				return done, nil
+			})
+			if wait.Interrupted(err) {
+				return fmt.Errorf("timed out waiting for the rollout of ecsmservice/%s to finish", name)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "How long to wait for the rollout to finish")
+	return cmd
+}
+
+// getRolloutStatus 从 reg 中读取一次服务的状态，返回描述发布进度的消息和发布是否已经完成。
+func getRolloutStatus(ctx context.Context, reg registry.Interface, namespace, name string) (string, bool, error) {
+	service, err := reg.GetService(ctx, namespace, name)
+	if err != nil {
+		return "", false, err
+	}
+	scale, err := reg.GetServiceScale(ctx, namespace, name)
+	if err != nil {
+		return "", false, err
+	}
+	desired := scale.Spec.Replicas
+	status := service.Status
+
+	if cond := apimeta.FindStatusCondition(status.Conditions, ecsmv1.ServiceDegraded); cond != nil && cond.Status == metav1.ConditionTrue {
+		return "", false, fmt.Errorf("ecsmservice/%s is degraded: %s", name, cond.Message)
+	}
+	switch {
+	case status.ObservedGeneration < service.Generation:
+		return "Waiting for the controller to observe the latest spec...", false, nil
+	case status.UpdatedReplicas < desired:
+		return fmt.Sprintf("Waiting for rollout to finish: %d out of %d new replicas have been updated...", status.UpdatedReplicas, desired), false, nil
+	case status.Replicas > status.UpdatedReplicas:
+		return fmt.Sprintf("Waiting for rollout to finish: %d old replicas are pending termination...", status.Replicas-status.UpdatedReplicas), false, nil
+	case status.ReadyReplicas < desired:
+		return fmt.Sprintf("Waiting for rollout to finish: %d of %d updated replicas are ready...", status.ReadyReplicas, desired), false, nil
+	case status.Rollout != nil:
+		// Canary 和蓝绿发布在副本全部就绪之后还可能处于暂停或验证阶段
+		return fmt.Sprintf("Waiting for rollout to finish: revision %s is at step %d...", status.Rollout.Revision, status.Rollout.Step), false, nil
+	}
+	return fmt.Sprintf("ecsmservice/%s successfully rolled out", name), true, nil
+}
+
+// newRolloutRestartCmd 创建 "rollout restart" 子命令
+func newRolloutRestartCmd(namespace *string) *cobra.Command {
+	var (
+		waitForRedeploy bool
+		timeout         time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restart service SERVICE_NAME",
+		Short: "Redeploy the containers of an ECSMService",
+		Long: `Asks the ECSM platform to redeploy the platform service of an ECSMService,
+which recreates all of its containers with the current configuration.`,
+		Args:              serviceArgs,
+		ValidArgsFunction: completeServiceArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[1]
+			ctx := context.Background()
+
+			serviceID, err := func() (string, error) {
+				reg, closeFn, err := util.NewRegistryFromFlags()
+				if err != nil {
+					return "", err
+				}
+				defer closeFn()
+				service, err := reg.GetService(ctx, *namespace, name)
+				if err != nil {
+					return "", err
+				}
+				return service.Status.UnderlyingServiceID, nil
+			}()
+			if err != nil {
+				return err
+			}
+			if serviceID == "" {
+				return fmt.Errorf("ecsmservice/%s has not been deployed to the ECSM platform yet", name)
+			}
+
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+			resp, err := cs.Services().Redeploy(ctx, serviceID)
+			if err != nil {
+				return fmt.Errorf("failed to redeploy ecsmservice/%s: %w", name, err)
+			}
+			if !waitForRedeploy {
+				fmt.Fprintf(os.Stdout, "ecsmservice/%s restart requested (transaction %s)\n", name, resp.ID)
+				return nil
+			}
+			if err := util.WaitForTransaction(ctx, cs, resp.ID, timeout); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "ecsmservice/%s restarted\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&waitForRedeploy, "wait", false, "Wait until the ECSM platform has finished the redeployment")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "How long to wait with --wait")
+	return cmd
+}
+
+// newRolloutUndoCmd 创建 "rollout undo" 子命令
+func newRolloutUndoCmd(namespace *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "undo service SERVICE_NAME",
+		Short: "Revert an ECSMService to its previous template",
+		Long: `Replaces the template of an ECSMService with the template it had before its
+last template change. The controller then rolls out the previous revision
+using the service's upgrade strategy. Undoing twice returns to the current
+template.`,
+		Args:              serviceArgs,
+		ValidArgsFunction: completeServiceArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[1]
+			ctx := context.Background()
+
+			reg, closeFn, err := util.NewWritableRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			if err := undoRollout(ctx, reg, *namespace, name); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "ecsmservice/%s rolled back\n", name)
+			return nil
+		},
+	}
+}
+
+// undoRollout 把服务的模板替换为注解中记录的上一个模板，发生冲突时重新读取服务后重试。
+func undoRollout(ctx context.Context, reg registry.Interface, namespace, name string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		service, err := reg.GetService(ctx, namespace, name)
+		if err != nil {
+			return err
+		}
+		data, ok := service.Annotations[ecsmv1.PreviousTemplateAnnotation]
+		if !ok {
+			return fmt.Errorf("ecsmservice/%s has no previous template to roll back to", name)
+		}
+		var previous ecsmv1.ContainerTemplateSpec
+		if err := json.Unmarshal([]byte(data), &previous); err != nil {
+			return fmt.Errorf("failed to decode the previous template of ecsmservice/%s: %w", name, err)
+		}
+		if equality.Semantic.DeepEqual(service.Spec.Template, previous) {
+			return nil
+		}
+		// Registry 会把当前的模板记录为新的上一个模板
+		service.Spec.Template = previous
+		_, err = reg.UpdateService(ctx, service)
+		return err
+	})
+}
+
+// serviceArgs 检查 "service SERVICE_NAME" 形式的参数，rollout 和 edit 目前只支持 ECSMService。
+func serviceArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected \"service SERVICE_NAME\", got %d argument(s)", len(args))
+	}
+	switch args[0] {
+	case "service", "services", "svc", "ecsmservice":
+		return nil
+	default:
+		return fmt.Errorf("%s is not supported for resource type %q, only services are supported", cmd.CommandPath(), args[0])
+	}
+}
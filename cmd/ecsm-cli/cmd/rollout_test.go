@@ -0,0 +1,89 @@
+// file: cmd/ecsm-cli/cmd/rollout_test.go
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestECSMService(name string) *ecsmv1.ECSMService {
+	replicas := int32(2)
+	return &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeDynamic, Replicas: &replicas},
+			Template:           ecsmv1.ContainerTemplateSpec{Image: name + "@1.0"},
+		},
+	}
+}
+
+// TestGetRolloutStatus 测试 rollout status 通过 API Server 读取发布进度。
+func TestGetRolloutStatus(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	service, err := reg.CreateService(ctx, newTestECSMService("web"))
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	message, done, err := getRolloutStatus(ctx, c, "default", "web")
+	if err != nil || done || !strings.Contains(message, "observe the latest spec") {
+		t.Errorf("getRolloutStatus() = %q, %v, %v, want waiting for the controller", message, done, err)
+	}
+
+	service.Status = ecsmv1.ECSMServiceStatus{
+		ObservedGeneration: service.Generation,
+		Replicas:           2,
+		UpdatedReplicas:    2,
+		ReadyReplicas:      2,
+	}
+	if _, err := reg.UpdateServiceStatus(ctx, service); err != nil {
+		t.Fatalf("UpdateServiceStatus failed: %v", err)
+	}
+	message, done, err = getRolloutStatus(ctx, c, "default", "web")
+	if err != nil || !done {
+		t.Errorf("getRolloutStatus() = %q, %v, %v, want done", message, done, err)
+	}
+
+	if _, _, err := getRolloutStatus(ctx, c, "default", "missing"); err == nil {
+		t.Error("getRolloutStatus() of a missing service succeeded, want an error")
+	}
+}
+
+// TestUndoRollout 测试 rollout undo 通过 API Server 恢复上一个模板。
+func TestUndoRollout(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	if _, err := reg.CreateService(ctx, newTestECSMService("web")); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	if err := undoRollout(ctx, c, "default", "web"); err == nil {
+		t.Error("undoRollout() without a previous template succeeded, want an error")
+	}
+
+	service, err := reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	service.Spec.Template.Image = "web@2.0"
+	if _, err := reg.UpdateService(ctx, service); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+
+	if err := undoRollout(ctx, c, "default", "web"); err != nil {
+		t.Fatalf("undoRollout() error = %v", err)
+	}
+	got, err := reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if got.Spec.Template.Image != "web@1.0" {
+		t.Errorf("image after undo = %q, want web@1.0", got.Spec.Template.Image)
+	}
+}
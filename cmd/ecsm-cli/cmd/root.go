@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
@@ -16,6 +18,9 @@ var (
 	// cfgFile 用于存储配置文件的路径
 	cfgFile string
 
+	// contextName 是 --context 标志指定的上下文名称
+	contextName string
+
 	// rootCmd 代表没有调用子命令时的基础命令
 	rootCmd = &cobra.Command{
 		Use:   "ecsm-cli",
@@ -50,22 +55,77 @@ func init() {
 
 	// --config 标志
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ecsm-cli.yaml)")
+	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "The name of the context in the config file to use (default is the current context)")
 
 	// ECSM Server 连接相关的标志
 	rootCmd.PersistentFlags().String("host", "localhost", "The host of the ECSM API server")
 	rootCmd.PersistentFlags().String("port", "3001", "The port of the ECSM API server")
 	rootCmd.PersistentFlags().String("protocol", "http", "The protocol to use (http or https)")
+	rootCmd.PersistentFlags().String("token", "", "The bearer token used to authenticate to the ECSM API server, overrides the token saved by \"auth login\"")
+	rootCmd.PersistentFlags().String("certificate-authority", "", "Path to a PEM file of the CA certificates used to verify the ECSM API server (default is the system roots)")
+	rootCmd.PersistentFlags().String("client-certificate", "", "Path to a PEM client certificate presented to the ECSM API server")
+	rootCmd.PersistentFlags().String("client-key", "", "Path to the PEM private key of --client-certificate")
+	rootCmd.PersistentFlags().String("tls-server-name", "", "The server name used to verify the certificate of the ECSM API server (default is --host)")
+	rootCmd.PersistentFlags().String("proxy-url", "", "URL of the http, https or socks5 proxy used to reach the ECSM API server (default is the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables)")
+	rootCmd.PersistentFlags().String("api-version", "", "The version of the ECSM API to use, for example v2 after a server upgrade, or \"auto\" to use the newest version supported by both the server and this client (default is v1)")
+	rootCmd.PersistentFlags().Bool("insecure-skip-tls-verify", false, "Do not verify the certificate of the ECSM API server. This makes the connection insecure and should only be used for testing")
+	rootCmd.PersistentFlags().Bool("debug-http", false, "Print every request to and response from the ECSM API server, including headers, bodies and timing, to standard error. Same as -v=9 without the other debug logs")
+	rootCmd.PersistentFlags().Duration("cache-ttl", 500*time.Millisecond, "How long responses to repeated reads, such as the lists used to look up resources by name, are reused without asking the ECSM API server again. Older responses are revalidated with conditional requests. 0 disables the cache")
+
+	// ecsm-operator Registry 相关的标志
+	rootCmd.PersistentFlags().String("server", "", "URL of the ecsm-operator API server, for example http://127.0.0.1:8090. Commands that read or write ECSM resources such as ECSMServices and events go through it")
+	rootCmd.PersistentFlags().String("registry-db", "", "Path to the ecsm-operator registry database, opened directly when --server is not given. Only works while the operator is stopped, because a running operator locks the file")
+	rootCmd.PersistentFlags().String("encryption-key-file", "", "Path to the encryption key file of the ecsm-operator, needed to list ECSMSecrets")
 
 	// --- 将标志与 Viper 绑定 ---
 	// 这使得我们可以通过配置文件或环境变量来设置这些值
 	viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("host"))
 	viper.BindPFlag("port", rootCmd.PersistentFlags().Lookup("port"))
 	viper.BindPFlag("protocol", rootCmd.PersistentFlags().Lookup("protocol"))
+	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
+	viper.BindPFlag("certificate-authority", rootCmd.PersistentFlags().Lookup("certificate-authority"))
+	viper.BindPFlag("client-certificate", rootCmd.PersistentFlags().Lookup("client-certificate"))
+	viper.BindPFlag("client-key", rootCmd.PersistentFlags().Lookup("client-key"))
+	viper.BindPFlag("tls-server-name", rootCmd.PersistentFlags().Lookup("tls-server-name"))
+	viper.BindPFlag("insecure-skip-tls-verify", rootCmd.PersistentFlags().Lookup("insecure-skip-tls-verify"))
+	viper.BindPFlag("proxy-url", rootCmd.PersistentFlags().Lookup("proxy-url"))
+	viper.BindPFlag("api-version", rootCmd.PersistentFlags().Lookup("api-version"))
+	viper.BindPFlag("debug-http", rootCmd.PersistentFlags().Lookup("debug-http"))
+	viper.BindPFlag("cache-ttl", rootCmd.PersistentFlags().Lookup("cache-ttl"))
+	viper.BindPFlag("server", rootCmd.PersistentFlags().Lookup("server"))
+	viper.BindPFlag("registry-db", rootCmd.PersistentFlags().Lookup("registry-db"))
+	viper.BindPFlag("encryption-key-file", rootCmd.PersistentFlags().Lookup("encryption-key-file"))
+	viper.BindPFlag("context", rootCmd.PersistentFlags().Lookup("context"))
+	rootCmd.RegisterFlagCompletionFunc("context", completeFlagValues(listContextNames))
 
 	// --- 添加子命令 ---
 	// 我们将在这里添加 get, describe 等命令
 	rootCmd.AddCommand(newGetCmd())
 	rootCmd.AddCommand(newDescribeCmd())
+	rootCmd.AddCommand(newLogsCmd())
+	rootCmd.AddCommand(newCpCmd())
+	rootCmd.AddCommand(newTopCmd())
+	rootCmd.AddCommand(newDashCmd())
+	rootCmd.AddCommand(newEventsCmd())
+	rootCmd.AddCommand(newScaleCmd())
+	rootCmd.AddCommand(newRolloutCmd())
+	rootCmd.AddCommand(newWaitCmd())
+	rootCmd.AddCommand(newApplyCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newValidateCmd())
+	rootCmd.AddCommand(newImageCmd())
+	rootCmd.AddCommand(newNodeCmd())
+	rootCmd.AddCommand(newContainerCmd())
+	rootCmd.AddCommand(newServiceCmd())
+	rootCmd.AddCommand(newEditCmd())
+	rootCmd.AddCommand(newDeleteCmd())
+	rootCmd.AddCommand(newConvertCmd())
+	rootCmd.AddCommand(newExplainCmd())
+	rootCmd.AddCommand(newSnapshotCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newAuthCmd())
+	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newCompletionCmd())
 }
 
 // initConfig 读取配置文件和环境变量（如果设置了的话）。
@@ -97,6 +157,62 @@ func initConfig() {
 			klog.Warningf("Error reading config file: %v", err)
 		}
 	}
+
+	cobra.CheckErr(applyContext())
+}
+
+// applyContext 把选中的上下文中的连接设置应用到 viper。
+// 上下文的优先级高于配置文件顶层的设置，但低于命令行标志和环境变量。
+func applyContext() error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil
+	}
+	config, err := util.LoadConfig(path)
+	if err != nil {
+		klog.Warningf("Error reading contexts from config file: %v", err)
+		return nil
+	}
+
+	name := viper.GetString("context")
+	explicit := name != ""
+	if !explicit {
+		name = config.CurrentContext
+	}
+	if name == "" {
+		return nil
+	}
+	ctx, ok := config.Contexts[name]
+	if !ok {
+		if explicit {
+			return fmt.Errorf("context %q not found in config file %s", name, path)
+		}
+		// 当前上下文失效时仍然允许运行命令，例如用 use-context 修复它
+		klog.Warningf("Current context %q not found in config file %s", name, path)
+		return nil
+	}
+
+	for key, value := range ctx.Settings() {
+		if flag := rootCmd.PersistentFlags().Lookup(key); flag != nil && flag.Changed {
+			continue
+		}
+		if _, ok := os.LookupEnv("ECSMCLI_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))); ok {
+			continue
+		}
+		viper.Set(key, value)
+	}
+	return nil
+}
+
+// configFilePath 返回 config 子命令读写的配置文件：--config 指定的文件、已经读取的配置文件或家目录下的默认文件。
+func configFilePath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	return util.DefaultConfigPath()
 }
 
 // GetRootCmd 导出 rootCmd 以便 main.go 可以添加 klog 标志
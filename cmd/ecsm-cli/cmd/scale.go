@@ -0,0 +1,76 @@
+// file: cmd/ecsm-cli/cmd/scale.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newScaleCmd 创建 "scale" 命令，它通过 scale 子资源修改 ECSMService 的副本数
+func newScaleCmd() *cobra.Command {
+	var (
+		namespace       string
+		replicas        int32
+		resourceVersion string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "scale SERVICE_NAME --replicas=COUNT",
+		Short: "Set a new size for an ECSMService",
+		Long: `Sets the number of replicas of an ECSMService through its scale subresource.
+Only the replicas are changed, the rest of the service spec is left untouched.
+Services with the Static deployment strategy cannot be scaled, their replicas
+are determined by their nodes.
+
+The scale subresource is updated through the operator's API server given with
+--server. While the operator is stopped the service can instead be updated in its
+registry database given with --registry-db. With --resource-version the update
+only succeeds if the service has not been modified since that version.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listECSMServiceNames),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("replicas") {
+				return fmt.Errorf("--replicas must be specified")
+			}
+
+			reg, closeFn, err := util.NewWritableRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			updated, err := scaleService(context.Background(), reg, namespace, args[0], replicas, resourceVersion)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "ecsmservice/%s scaled to %d replicas\n", updated.Name, updated.Spec.Replicas)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the service")
+	cmd.Flags().Int32Var(&replicas, "replicas", 0, "The new number of replicas")
+	cmd.Flags().StringVar(&resourceVersion, "resource-version", "", "Precondition for the resource version of the service")
+	return cmd
+}
+
+// scaleService 通过 scale 子资源把服务的副本数设置为 replicas，resourceVersion 不为空时作为更新的前提条件。
+func scaleService(ctx context.Context, reg registry.Interface, namespace, name string, replicas int32, resourceVersion string) (*ecsmv1.ECSMServiceScale, error) {
+	scale := &ecsmv1.ECSMServiceScale{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: resourceVersion},
+		Spec:       ecsmv1.ScaleSpec{Replicas: replicas},
+	}
+	updated, err := reg.UpdateServiceScale(ctx, scale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scale service %s/%s: %w", namespace, name, err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,47 @@
+// file: cmd/ecsm-cli/cmd/scale_test.go
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// TestScaleService 测试 scale 通过 API Server 的 scale 子资源修改副本数。
+func TestScaleService(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	created, err := reg.CreateService(ctx, newTestECSMService("web"))
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	updated, err := scaleService(ctx, c, "default", "web", 5, "")
+	if err != nil {
+		t.Fatalf("scaleService() error = %v", err)
+	}
+	if updated.Spec.Replicas != 5 {
+		t.Errorf("scale replicas = %d, want 5", updated.Spec.Replicas)
+	}
+	got, err := reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if *got.Spec.DeploymentStrategy.Replicas != 5 {
+		t.Errorf("service replicas = %d, want 5", *got.Spec.DeploymentStrategy.Replicas)
+	}
+	if got.Spec.Template.Image != created.Spec.Template.Image {
+		t.Errorf("image = %q, want the template to be left untouched", got.Spec.Template.Image)
+	}
+
+	// 服务在 created 之后被修改过，以它的版本为前提条件的更新会失败
+	if _, err := scaleService(ctx, c, "default", "web", 3, created.ResourceVersion); !errors.IsConflict(err) {
+		t.Errorf("scaleService() with a stale resource version error = %v, want Conflict", err)
+	}
+	if _, err := scaleService(ctx, c, "default", "missing", 1, ""); !errors.IsNotFound(err) {
+		t.Errorf("scaleService() of a missing service error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,87 @@
+// file: cmd/ecsm-cli/cmd/secrets.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+// newGetSecretsCmd 创建 "get secrets" 子命令，它从 operator 的 Registry 中读取 ECSMSecret，只显示键而不显示值
+func newGetSecretsCmd() *cobra.Command {
+	var (
+		namespace     string
+		allNamespaces bool
+		output        *string
+		sortBy        *string
+		tableFlags    *util.TableFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Display a list of ECSMSecrets",
+		Long: `Lists the ECSMSecrets that container templates can reference through envFrom,
+env valueFrom and vsoa passwordFrom. Only the keys are shown, never the values.
+Secrets are read from the operator's API server given with --server. While the
+operator is stopped they can instead be read from its registry database given
+with --registry-db, which also needs the operator's --encryption-key-file
+because secrets are stored encrypted.`,
+		Aliases: []string{"secret"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "ecsmsecret")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			if allNamespaces {
+				namespace = ""
+			}
+
+			reg, closeFn, err := util.NewRegistryFromFlags()
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			list, _, err := reg.ListSecrets(context.Background(), namespace)
+			if err != nil {
+				if registry.IsMissingKey(err) {
+					return fmt.Errorf("failed to list secrets: %w, use --encryption-key-file", err)
+				}
+				return fmt.Errorf("failed to list secrets: %w", err)
+			}
+
+			// 与表格一样，其他格式也只输出键的名称，不输出值
+			for i := range list.Items {
+				for k := range list.Items[i].Data {
+					list.Items[i].Data[k] = ""
+				}
+			}
+			if len(list.Items) == 0 && util.IsTableOutput(*output) {
+				fmt.Fprintln(os.Stdout, "No secrets found.")
+				return nil
+			}
+			if err := sorter.Sort(list.Items); err != nil {
+				return err
+			}
+			return printer.PrintObj(list.Items, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the secrets to list")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List secrets across all namespaces")
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	return cmd
+}
@@ -0,0 +1,127 @@
+// file: cmd/ecsm-cli/cmd/service.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+)
+
+// newServiceCmd 创建 "service" 命令，它一次控制一个或多个服务的所有容器
+func newServiceCmd() *cobra.Command {
+	var opts controlOptions
+
+	cmd := &cobra.Command{
+		Use:     "service",
+		Short:   "Start, stop and restart all containers of services",
+		Aliases: []string{"svc"},
+		Long: `Controls all containers of services of the ECSM platform at once. The
+services are given by name or ID, or selected with --selector like in
+"get services".
+
+A selector that only requires the path label (` + util.PathLabelKey + `=VALUE) is
+handled by the platform in a single transaction. Other selectors are matched
+against the services first, and each matching service gets its own
+transaction. Like "container", the command waits until the transactions have
+finished unless --no-wait is given.`,
+	}
+	opts.addFlags(cmd)
+
+	for _, a := range containerActions {
+		action, done := a.action, a.done
+		var selectorFilter string
+		sub := &cobra.Command{
+			Use:   string(action) + " (SERVICE_NAME_OR_ID... | -l SELECTOR)",
+			Short: a.short + " of services",
+			ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+				return filterNames(cmd, toComplete, listServiceNames), cobra.ShellCompDirectiveNoFileComp
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if (len(args) == 0) == (selectorFilter == "") {
+					return fmt.Errorf("either service names or --selector must be specified")
+				}
+				selector, err := util.ParseSelector(selectorFilter)
+				if err != nil {
+					return err
+				}
+				cs, err := util.NewClientsetFromFlags()
+				if err != nil {
+					return err
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+
+				if len(args) > 0 {
+					err = controlServicesByName(ctx, cs, args, action, done, opts)
+				} else {
+					err = controlServicesBySelector(ctx, cs, selector, action, done, opts)
+				}
+				if ctx.Err() != nil {
+					// 用户中断了等待，已经提交的动作仍会在平台上执行
+					return nil
+				}
+				return err
+			},
+		}
+		sub.Flags().StringVarP(&selectorFilter, "selector", "l", "", "Label selector of the services, supports 'key=value', 'key!=value', 'key' and '!key' separated by commas. The path label is matched as key "+util.PathLabelKey)
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
+
+// controlServicesByName 对按名称或 ID 指定的每个服务的容器执行动作。
+func controlServicesByName(ctx context.Context, cs clientset.Interface, names []string, action clientset.ContainerAction, done string, opts controlOptions) error {
+	var errs []error
+	for _, name := range names {
+		serviceID, err := util.ResolveServiceID(ctx, cs, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		err = runControlAction(ctx, cs, "service/"+name, action, done, opts, func() (*clientset.Transaction, error) {
+			return cs.Containers().SubmitControlActionByService(ctx, serviceID, action)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// controlServicesBySelector 对满足选择器的所有服务的容器执行动作。
+func controlServicesBySelector(ctx context.Context, cs clientset.Interface, selector util.Selector, action clientset.ContainerAction, done string, opts controlOptions) error {
+	// 只按路径标签选择时由平台在一个事务中完成
+	if label := selector.PathLabel(); len(selector) == 1 && label != "" {
+		return runControlAction(ctx, cs, "services with path label "+label, action, done, opts, func() (*clientset.Transaction, error) {
+			return cs.Containers().SubmitControlActionByLabel(ctx, label, action)
+		})
+	}
+
+	services, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{Label: selector.PathLabel()})
+	if err != nil {
+		return fmt.Errorf("failed to list services to match selector: %w", err)
+	}
+	services = util.FilterServices(services, selector)
+	if len(services) == 0 {
+		return fmt.Errorf("no services match the selector")
+	}
+
+	var errs []error
+	for _, svc := range services {
+		serviceID := svc.ID
+		err := runControlAction(ctx, cs, "service/"+svc.Name, action, done, opts, func() (*clientset.Transaction, error) {
+			return cs.Containers().SubmitControlActionByService(ctx, serviceID, action)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,123 @@
+// file: cmd/ecsm-cli/cmd/snapshot.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/snapshot"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newSnapshotCmd 创建 "snapshot" 命令，它管理 ecsm-operator 写入的 Registry 快照
+func newSnapshotCmd() *cobra.Command {
+	var target string
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Manage registry snapshots written by the ecsm-operator",
+		Long: `Lists and restores the registry snapshots that the ecsm-operator writes
+periodically when started with --snapshot-target. --target must be the same
+directory or http(s) URL.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().StringVar(&target, "target", "", "Directory or http(s) URL holding the snapshots")
+
+	cmd.AddCommand(newSnapshotListCmd(&target))
+	cmd.AddCommand(newSnapshotRestoreCmd(&target))
+	return cmd
+}
+
+// newSnapshotListCmd 创建 "snapshot list" 子命令
+func newSnapshotListCmd(target *string) *cobra.Command {
+	var output, sortBy *string
+	var tableFlags *util.TableFlags
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List registry snapshots, newest first",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "snapshot")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			t, err := snapshot.NewTarget(*target)
+			if err != nil {
+				return err
+			}
+			snapshots, err := snapshot.List(context.Background(), t)
+			if err != nil {
+				return err
+			}
+
+			if len(snapshots) == 0 && util.IsTableOutput(*output) {
+				fmt.Fprintln(os.Stdout, "No snapshots found.")
+				return nil
+			}
+			if err := sorter.Sort(snapshots); err != nil {
+				return err
+			}
+			return printer.PrintObj(snapshots, os.Stdout)
+		},
+	}
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	return cmd
+}
+
+// newSnapshotRestoreCmd 创建 "snapshot restore" 子命令
+func newSnapshotRestoreCmd(target *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore SNAPSHOT_NAME",
+		Short: "Restore the registry database from a snapshot",
+		Long: `Restores the registry database given with --registry-db from a snapshot.
+The checksum and the structure of the snapshot are verified before the database
+is replaced, and the current database is kept as <registry-db>.bak.
+
+The ecsm-operator must be stopped while the database is restored. Use "latest"
+to restore the newest snapshot.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbPath := viper.GetString("registry-db")
+			if dbPath == "" {
+				return fmt.Errorf("registry-db must be specified")
+			}
+			t, err := snapshot.NewTarget(*target)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			name := args[0]
+			if name == "latest" {
+				snapshots, err := snapshot.List(ctx, t)
+				if err != nil {
+					return err
+				}
+				if len(snapshots) == 0 {
+					return fmt.Errorf("no snapshots found in %s", t)
+				}
+				name = snapshots[0].Name
+			}
+
+			if err := snapshot.Restore(ctx, t, name, dbPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Registry %s restored from snapshot %s\n", dbPath, name)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,171 @@
+// file: cmd/ecsm-cli/cmd/top.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// clearScreen 把光标移到左上角并清屏，用于 --refresh 时重绘表格
+const clearScreen = "\033[H\033[2J"
+
+// topOptions 是 top 子命令共用的标志
+type topOptions struct {
+	sortBy  string
+	refresh time.Duration
+}
+
+// newTopCmd 创建 "top" 命令
+func newTopCmd() *cobra.Command {
+	var opts topOptions
+
+	cmd := &cobra.Command{
+		Use:   "top [resource]",
+		Short: "Display resource usage of nodes or containers",
+		Long: `Displays the CPU and memory usage of nodes or containers.
+
+With --refresh the table is redrawn periodically until the command is
+interrupted, like "docker stats".`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&opts.sortBy, "sort-by", "", "Sort by cpu or memory usage, highest first")
+	cmd.RegisterFlagCompletionFunc("sort-by", cobra.FixedCompletions([]cobra.Completion{util.SortByCPU, util.SortByMemory}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.PersistentFlags().DurationVar(&opts.refresh, "refresh", 0, "Redraw the table at this interval until interrupted, 0 prints it once")
+
+	cmd.AddCommand(newTopNodesCmd(&opts))
+	cmd.AddCommand(newTopContainersCmd(&opts))
+	return cmd
+}
+
+// newTopNodesCmd 创建 "top nodes" 子命令
+func newTopNodesCmd(opts *topOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "nodes",
+		Short:   "Display resource usage of nodes",
+		Aliases: []string{"node", "no"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := util.ValidateSortBy(opts.sortBy); err != nil {
+				return err
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+
+			return runTop(opts, func(ctx context.Context, buf *bytes.Buffer) error {
+				nodes, err := cs.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+				if err != nil {
+					return err
+				}
+				if len(nodes) == 0 {
+					fmt.Fprintln(buf, "No nodes found.")
+					return nil
+				}
+
+				usages := make([]util.NodeUsage, len(nodes))
+				for i, node := range nodes {
+					usages[i].Node = node
+					metrics, err := cs.Nodes().GetNodeMetrics(ctx, clientset.NodeMetricsOptions{NodeID: node.ID, Instant: true})
+					if err != nil || len(metrics) == 0 {
+						// 离线节点没有指标，仍然列出它
+						klog.V(2).Infof("Could not retrieve metrics for node %s: %v", node.Name, err)
+						continue
+					}
+					usages[i].Metrics = &metrics[0]
+				}
+				util.SortNodeUsage(usages, opts.sortBy)
+				util.PrintNodeUsageTable(buf, usages)
+				return nil
+			})
+		},
+	}
+}
+
+// newTopContainersCmd 创建 "top containers" 子命令
+func newTopContainersCmd(opts *topOptions) *cobra.Command {
+	var serviceFilter, nodeFilter string
+
+	cmd := &cobra.Command{
+		Use:     "containers",
+		Short:   "Display resource usage of containers",
+		Aliases: []string{"container", "co"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := util.ValidateSortBy(opts.sortBy); err != nil {
+				return err
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+
+			return runTop(opts, func(ctx context.Context, buf *bytes.Buffer) error {
+				containers, err := listContainers(ctx, cs, serviceFilter, nodeFilter)
+				if err != nil {
+					return err
+				}
+				if len(containers) == 0 {
+					fmt.Fprintln(buf, "No containers found.")
+					return nil
+				}
+				util.SortContainerUsage(containers, opts.sortBy)
+				util.PrintContainerUsageTable(buf, containers)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&serviceFilter, "service", "s", "", "Only show containers of services matching this name")
+	cmd.Flags().StringVarP(&nodeFilter, "node", "n", "", "Only show containers on nodes matching this name")
+	cmd.RegisterFlagCompletionFunc("service", completeFlagValues(listServiceNames))
+	cmd.RegisterFlagCompletionFunc("node", completeFlagValues(listNodeNames))
+	return cmd
+}
+
+// runTop 把 render 生成的表格打印一次，或者在设置了 --refresh 时周期性地清屏重绘，直到用户按下 Ctrl+C。
+// 表格先写入缓冲区，避免查询较慢时屏幕长时间处于清空状态。
+func runTop(opts *topOptions, render func(ctx context.Context, buf *bytes.Buffer) error) error {
+	if opts.refresh < 0 {
+		return fmt.Errorf("--refresh must not be negative")
+	}
+	if opts.refresh == 0 {
+		var buf bytes.Buffer
+		if err := render(context.Background(), &buf); err != nil {
+			return err
+		}
+		_, err := buf.WriteTo(os.Stdout)
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	err := wait.PollUntilContextCancel(ctx, opts.refresh, true, func(ctx context.Context) (bool, error) {
+		var buf bytes.Buffer
+		if err := render(ctx, &buf); err != nil {
+			return false, err
+		}
+		fmt.Fprint(os.Stdout, clearScreen)
+		_, err := buf.WriteTo(os.Stdout)
+		return false, err
+	})
+	if ctx.Err() != nil {
+		// 用户中断了刷新，不是错误
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+// file: cmd/ecsm-cli/cmd/transactions.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+)
+
+// transactionStatuses 是 --status 可以使用的事务状态
+var transactionStatuses = []string{
+	clientset.TransactionStatusRunning,
+	clientset.TransactionStatusFailure,
+	clientset.TransactionStatusSuccess,
+}
+
+// newGetTransactionsCmd 创建 "get transactions" 子命令，它列出 ECSM 平台上的异步事务
+func newGetTransactionsCmd() *cobra.Command {
+	var pageSize int
+	var pageNum int
+	var status string
+	var txType string
+	var output, sortBy *string
+	var tableFlags *util.TableFlags
+	var watchOpts watchOptions
+
+	cmd := &cobra.Command{
+		Use:   "transactions",
+		Short: "Display a list of asynchronous transactions",
+		Long: `Lists the transactions of asynchronous operations on the ECSM platform, such as
+creating or deleting services and controlling containers, newest first.
+Use "ecsm-cli describe transaction ID" to show the data of a transaction.`,
+		Aliases: []string{"transaction", "tx"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if status != "" && !slices.Contains(transactionStatuses, status) {
+				return fmt.Errorf("invalid --status %q, must be one of %v", status, transactionStatuses)
+			}
+			printer, err := util.NewPrinter(*output, "transaction")
+			if err != nil {
+				return err
+			}
+			tableFlags.Apply(printer)
+			sorter, err := util.NewSorter(*sortBy)
+			if err != nil {
+				return err
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+
+			opts := clientset.TransactionListOptions{
+				PageSize: pageSize,
+				Status:   status,
+				Type:     txType,
+			}
+
+			list := func(ctx context.Context) ([]clientset.Transaction, error) {
+				var transactions []clientset.Transaction
+				if cmd.Flags().Changed("page") {
+					opts.PageNum = pageNum
+					txList, err := cs.Transactions().List(ctx, opts)
+					if err != nil {
+						return nil, err
+					}
+					transactions = txList.Items
+				} else {
+					all, err := cs.Transactions().ListAll(ctx, opts)
+					if err != nil {
+						return nil, err
+					}
+					transactions = all
+				}
+
+				// 没有指定 --sort-by 时最近提交的事务在前
+				sort.SliceStable(transactions, func(i, j int) bool {
+					return transactions[i].Timestamp > transactions[j].Timestamp
+				})
+				return sortedList(sorter, transactions)
+			}
+
+			if watchOpts.watch {
+				return runWatch(watchOpts, printer, &util.ListWatcher[clientset.Transaction]{
+					List: list,
+					Key:  func(tx *clientset.Transaction) string { return tx.ID },
+				})
+			}
+
+			transactions, err := list(context.Background())
+			if err != nil {
+				return err
+			}
+			if len(transactions) == 0 && util.IsTableOutput(*output) {
+				fmt.Println("No transactions found.")
+				return nil
+			}
+			return printer.PrintObj(transactions, os.Stdout)
+		},
+	}
+
+	cmd.Flags().IntVarP(&pageNum, "page", "p", 1, "Page number to retrieve (disables listing all pages)")
+	cmd.Flags().IntVarP(&pageSize, "page-size", "s", 100, "Number of items per page (used for both single and all-page listing)")
+	cmd.Flags().StringVar(&status, "status", "", "Only list transactions with this status, one of running, failure or success")
+	cmd.Flags().StringVar(&txType, "type", "", "Only list transactions of this operation type, e.g. service.create")
+	cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(transactionStatuses, cobra.ShellCompDirectiveNoFileComp))
+	output = util.AddOutputFlag(cmd)
+	sortBy = util.AddSortByFlag(cmd)
+	tableFlags = util.AddTableFlags(cmd, false)
+	watchOpts.addFlags(cmd)
+
+	return cmd
+}
+
+// newDescribeTransactionCmd 创建 "describe transaction" 子命令
+func newDescribeTransactionCmd() *cobra.Command {
+	var output *string
+	cmd := &cobra.Command{
+		Use:               "transaction <TRANSACTION_ID>",
+		Short:             "Show the status and data of an asynchronous transaction",
+		Aliases:           []string{"transactions", "tx"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(listTransactionIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := util.NewPrinter(*output, "transaction")
+			if err != nil {
+				return err
+			}
+			cs, err := util.NewClientsetFromFlags()
+			if err != nil {
+				return err
+			}
+
+			tx, err := cs.Transactions().Get(context.Background(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get transaction %q: %w", args[0], err)
+			}
+			return printer.PrintObj(tx, os.Stdout)
+		},
+	}
+	output = util.AddOutputFlag(cmd)
+	return cmd
+}
+
+// listTransactionIDs 返回 ECSM 平台上所有事务的 ID，最近提交的在前。
+func listTransactionIDs(ctx context.Context, cmd *cobra.Command) ([]string, error) {
+	cs, err := util.NewClientsetFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := cs.Transactions().ListAll(ctx, clientset.TransactionListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Timestamp > transactions[j].Timestamp
+	})
+	ids := make([]string, 0, len(transactions))
+	for _, tx := range transactions {
+		ids = append(ids, tx.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,132 @@
+// file: cmd/ecsm-cli/cmd/validate.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/validation"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// newValidateCmd 创建 "validate" 命令，它在本地校验清单而不连接任何服务器
+func newValidateCmd() *cobra.Command {
+	var (
+		filename  string
+		namespace string
+		strict    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "validate -f FILENAME",
+		Short: "Validate manifests without contacting any server",
+		Long: `Validates the ecsm.sh objects in YAML or JSON manifests locally. FILENAME may be
+a file, a directory whose .yaml, .yml and .json files are read in name order,
+or "-" to read from standard input. Manifests of older ecsm.sh versions are
+converted to v1 first.
+
+ECSMServices are defaulted and validated exactly like the operator's registry
+does when they are applied, ECSMNotifications are validated as well; other
+objects are only checked to decode. Unknown and duplicate fields are errors
+unless --strict=false is given, in which case they are printed as warnings.
+
+All errors of all objects are reported. The command fails if any object is
+invalid, which makes it suitable for CI pipelines.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return fmt.Errorf("-f must be specified")
+			}
+			manifests, err := util.ReadManifests(filename)
+			if err != nil {
+				return err
+			}
+
+			invalid := 0
+			for i := range manifests {
+				m := &manifests[i]
+				ref, errs, warnings := validateManifest(m, namespace, strict)
+				for _, w := range warnings {
+					fmt.Fprintf(os.Stderr, "Warning: %s: %s: %s\n", m.Source, ref, w)
+				}
+				if len(errs) == 0 {
+					fmt.Fprintf(os.Stdout, "%s valid\n", ref)
+					continue
+				}
+				invalid++
+				fmt.Fprintf(os.Stdout, "%s: %s is invalid:\n", m.Source, ref)
+				for _, e := range errs {
+					fmt.Fprintf(os.Stdout, "  * %s\n", e)
+				}
+			}
+			if invalid > 0 {
+				return fmt.Errorf("%d of %d objects are invalid", invalid, len(manifests))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "The file, directory or \"-\" for standard input that contains the manifests to validate")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of services whose manifest does not set one, as in apply")
+	cmd.Flags().BoolVar(&strict, "strict", true, "Treat unknown and duplicate fields as errors instead of warnings")
+	return cmd
+}
+
+// validateManifest 解码并校验一个清单，返回对象的引用 (例如 "ecsmservice/web")、错误和警告。
+func validateManifest(m *util.Manifest, namespace string, strict bool) (string, []string, []string) {
+	ref := strings.ToLower(m.Kind)
+	if ref == "" {
+		ref = "<unknown>"
+	}
+	obj, strictErrs, err := m.DecodeObject()
+	if err != nil {
+		// 输出中已经有清单的文件名
+		return ref, []string{strings.TrimPrefix(err.Error(), m.Source+": ")}, nil
+	}
+	if accessor, err := meta.Accessor(obj); err == nil && accessor.GetName() != "" {
+		ref += "/" + accessor.GetName()
+	}
+
+	var errs, warnings []string
+	for _, e := range strictErrs {
+		if strict {
+			errs = append(errs, e.Error())
+		} else {
+			warnings = append(warnings, e.Error())
+		}
+	}
+	for _, e := range validateObject(obj, namespace) {
+		errs = append(errs, e.Error())
+	}
+	return ref, errs, warnings
+}
+
+// validateObject 使用 Registry 写入对象之前的默认值和校验函数校验 obj。
+func validateObject(obj runtime.Object, namespace string) field.ErrorList {
+	switch obj := obj.(type) {
+	case *ecsmv1.ECSMService:
+		if obj.Namespace == "" {
+			obj.Namespace = namespace
+		}
+		defaults.SetServiceDefaults(obj)
+		return validation.ValidateService(obj)
+	case *ecsmv1.ECSMNotification:
+		return validation.ValidateNotification(obj)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil
+	}
+	if accessor.GetName() == "" {
+		return field.ErrorList{field.Required(field.NewPath("metadata", "name"), "")}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// file: cmd/ecsm-cli/cmd/version.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/cobra"
+)
+
+// version 和 gitCommit 在构建时通过 -ldflags 设置，例如
+// -X github.com/fx147/ecsm-operator/cmd/ecsm-cli/cmd.version=v0.3.0
+var (
+	version   string
+	gitCommit string
+)
+
+// clientInfo 是 ecsm-cli 自身的版本信息
+type clientInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"gitCommit,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+// versionInfo 是 "version" 命令以 json 和 yaml 格式打印的对象
+type versionInfo struct {
+	Client clientInfo            `json:"client"`
+	Server *clientset.ServerInfo `json:"server,omitempty"`
+}
+
+// newVersionCmd 创建 "version" 命令，它打印客户端和 ECSM 服务器的版本
+func newVersionCmd() *cobra.Command {
+	var clientOnly bool
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the client and server version information",
+		Long: `Prints the version of ecsm-cli and the version, API versions and capabilities
+of the ECSM server. A warning is printed on standard error if the server
+version is not supported by this client (it requires a ` + clientset.MinServerVersion + ` server
+or a newer one of the same major version). Servers without version
+information are reported as unknown.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && output != util.OutputJSON && output != util.OutputYAML {
+				return fmt.Errorf("unsupported output format %q, must be json or yaml", output)
+			}
+			info := versionInfo{Client: currentClientInfo()}
+
+			var serverErr error
+			if !clientOnly {
+				cs, err := util.NewClientsetFromFlags()
+				if err != nil {
+					return err
+				}
+				info.Server, serverErr = cs.Discovery().ServerVersion(context.Background())
+				if errors.Is(serverErr, clientset.ErrVersionUnavailable) {
+					fmt.Fprintf(os.Stderr, "Warning: %v, it may be older than %s\n", serverErr, clientset.MinServerVersion)
+					serverErr = nil
+				} else if serverErr == nil {
+					if err := info.Server.CheckCompatibility(); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					}
+				}
+			}
+
+			if output != "" {
+				printer, err := util.NewPrinter(output, "")
+				if err != nil {
+					return err
+				}
+				if err := printer.PrintObj(info, os.Stdout); err != nil {
+					return err
+				}
+				return serverErr
+			}
+
+			c := info.Client
+			if c.GitCommit != "" {
+				fmt.Fprintf(os.Stdout, "Client Version: %s (commit %s, %s, %s)\n", c.Version, c.GitCommit, c.GoVersion, c.Platform)
+			} else {
+				fmt.Fprintf(os.Stdout, "Client Version: %s (%s, %s)\n", c.Version, c.GoVersion, c.Platform)
+			}
+			switch {
+			case clientOnly || serverErr != nil:
+			case info.Server == nil:
+				fmt.Fprintln(os.Stdout, "Server Version: unknown")
+			default:
+				util.PrintServerInfo(os.Stdout, info.Server)
+			}
+			if serverErr != nil {
+				return fmt.Errorf("failed to get the server version: %w", serverErr)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&clientOnly, "client", false, "Print the client version only, without contacting the server")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format, one of json or yaml")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]cobra.Completion{util.OutputJSON, util.OutputYAML}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// currentClientInfo 返回 ecsm-cli 的版本。没有通过 -ldflags 设置版本时，使用 go 记录的模块版本和 VCS 信息。
+func currentClientInfo() clientInfo {
+	info := clientInfo{
+		Version:   version,
+		GitCommit: gitCommit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" {
+			info.Version = build.Main.Version
+		}
+		for _, s := range build.Settings {
+			if s.Key == "vcs.revision" && info.GitCommit == "" {
+				info.GitCommit = s.Value
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+	if len(info.GitCommit) > 12 {
+		info.GitCommit = info.GitCommit[:12]
+	}
+	return info
+}
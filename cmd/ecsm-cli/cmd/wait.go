@@ -0,0 +1,231 @@
+// file: cmd/ecsm-cli/cmd/wait.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/internal/ecsm-cli/util"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// waitPollInterval 是 wait 重新读取对象或事务状态的间隔
+const waitPollInterval = 2 * time.Second
+
+// waitForDone 是 "wait transaction" 中表示事务结束 (无论成功还是失败) 的 --for 值
+const waitForDone = "done"
+
+// newWaitCmd 创建 "wait" 命令
+func newWaitCmd() *cobra.Command {
+	var (
+		namespace string
+		forFlag   string
+		timeout   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "wait (TYPE/NAME | transaction TRANSACTION_ID) --for=CONDITION",
+		Short: "Wait for a condition on an object or an ECSM transaction",
+		Long: `Waits until an object managed by the ecsm-operator meets a condition,
+or until an asynchronous transaction of the ECSM platform finishes.
+
+For services (service/NAME), jobs (job/NAME) and nodes (node/NAME) --for is
+either "condition=TYPE[=STATUS]", which waits for a condition in the object's
+status to have the given status ("True" by default), or "delete", which waits
+for the object to be removed. The objects are polled through the operator's API
+server given with --server, or read from its registry database given with
+--registry-db while the operator is stopped.
+
+For "transaction TRANSACTION_ID" --for is "success", "failure" or "done",
+which waits for the transaction to finish with either result.
+
+Examples:
+
+  ecsm-cli wait service/my-app --for=condition=Available --timeout=120s
+  ecsm-cli wait job/migrate --for=condition=Complete
+  ecsm-cli wait transaction 42 --for=success`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if timeout <= 0 {
+				return fmt.Errorf("--timeout must be positive")
+			}
+			if forFlag == "" {
+				return fmt.Errorf("--for must be specified")
+			}
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			var err error
+			if args[0] == "transaction" || args[0] == "tx" {
+				if len(args) != 2 {
+					return fmt.Errorf("expected \"transaction TRANSACTION_ID\"")
+				}
+				err = waitForTransaction(ctx, args[1], forFlag, timeout)
+			} else {
+				if len(args) != 1 {
+					return fmt.Errorf("expected TYPE/NAME, got %d arguments", len(args))
+				}
+				// 整个等待期间使用同一个 Registry，通过 API Server 访问时不会占用 operator 的数据库
+				reg, closeFn, openErr := util.NewRegistryFromFlags()
+				if openErr != nil {
+					return openErr
+				}
+				defer closeFn()
+				err = waitForObject(ctx, reg, namespace, args[0], forFlag, timeout)
+			}
+			if ctx.Err() != nil {
+				// 用户中断了等待，不是错误
+				return nil
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the object")
+	cmd.Flags().StringVar(&forFlag, "for", "", "The condition to wait for: condition=TYPE[=STATUS] or delete for objects, success, failure or done for transactions")
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "How long to wait before giving up")
+	return cmd
+}
+
+// waitForTransaction 等待 ECSM 平台的事务进入 forFlag 指定的状态。
+func waitForTransaction(ctx context.Context, id, forFlag string, timeout time.Duration) error {
+	switch forFlag {
+	case clientset.TransactionStatusSuccess, clientset.TransactionStatusFailure, waitForDone:
+	default:
+		return fmt.Errorf("unsupported --for %q for transactions, must be %s, %s or %s",
+			forFlag, clientset.TransactionStatusSuccess, clientset.TransactionStatusFailure, waitForDone)
+	}
+	cs, err := util.NewClientsetFromFlags()
+	if err != nil {
+		return err
+	}
+
+	var tx *clientset.Transaction
+	err = wait.PollUntilContextTimeout(ctx, waitPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		tx, err = cs.Transactions().Get(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		return tx.Status != clientset.TransactionStatusRunning, nil
+	})
+	if wait.Interrupted(err) && ctx.Err() == nil {
+		return fmt.Errorf("timed out waiting for transaction %s", id)
+	}
+	if err != nil {
+		return err
+	}
+	if forFlag != waitForDone && tx.Status != forFlag {
+		// 事务已经结束，不会再变成期望的状态
+		return fmt.Errorf("transaction %s finished with status %s: %v", id, tx.Status, tx.Data)
+	}
+	fmt.Fprintf(os.Stdout, "transaction/%s %s\n", id, tx.Status)
+	return nil
+}
+
+// waitForObject 等待 Registry 中的对象满足 forFlag 指定的条件。
+func waitForObject(ctx context.Context, reg registry.Interface, namespace, ref, forFlag string, timeout time.Duration) error {
+	kind, name, ok := strings.Cut(ref, "/")
+	if !ok || name == "" {
+		return fmt.Errorf("expected TYPE/NAME, got %q", ref)
+	}
+	getConditions, err := conditionsGetter(reg, kind, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	deletion := forFlag == "delete"
+	var condType string
+	condStatus := metav1.ConditionTrue
+	if !deletion {
+		spec, ok := strings.CutPrefix(forFlag, "condition=")
+		if !ok || spec == "" {
+			return fmt.Errorf("unsupported --for %q, must be condition=TYPE[=STATUS] or delete", forFlag)
+		}
+		typ, status, hasStatus := strings.Cut(spec, "=")
+		condType = typ
+		if hasStatus {
+			condStatus = metav1.ConditionStatus(status)
+		}
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, waitPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		conditions, err := getConditions(ctx)
+		if errors.IsNotFound(err) {
+			if deletion {
+				return true, nil
+			}
+			// 对象可能还没有被创建
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if deletion {
+			return false, nil
+		}
+		// 状况的类型和状态不区分大小写，与 kubectl wait 一致
+		for _, cond := range conditions {
+			if strings.EqualFold(cond.Type, condType) {
+				return strings.EqualFold(string(cond.Status), string(condStatus)), nil
+			}
+		}
+		return false, nil
+	})
+	if wait.Interrupted(err) && ctx.Err() == nil {
+		return fmt.Errorf("timed out waiting for the condition on %s", ref)
+	}
+	if err != nil {
+		return err
+	}
+	if deletion {
+		fmt.Fprintf(os.Stdout, "%s deleted\n", ref)
+	} else {
+		fmt.Fprintf(os.Stdout, "%s condition met\n", ref)
+	}
+	return nil
+}
+
+// conditionsGetter 返回从 reg 中读取一个对象的 status.conditions 的函数。
+func conditionsGetter(reg registry.Interface, kind, namespace, name string) (func(ctx context.Context) ([]metav1.Condition, error), error) {
+	var get func(ctx context.Context) ([]metav1.Condition, error)
+	switch strings.ToLower(kind) {
+	case "service", "services", "svc", "ecsmservice":
+		get = func(ctx context.Context) ([]metav1.Condition, error) {
+			service, err := reg.GetService(ctx, namespace, name)
+			if err != nil {
+				return nil, err
+			}
+			return service.Status.Conditions, nil
+		}
+	case "job", "jobs", "ecsmjob":
+		get = func(ctx context.Context) ([]metav1.Condition, error) {
+			job, err := reg.GetJob(ctx, namespace, name)
+			if err != nil {
+				return nil, err
+			}
+			return job.Status.Conditions, nil
+		}
+	case "node", "nodes", "no", "ecsmnode":
+		get = func(ctx context.Context) ([]metav1.Condition, error) {
+			node, err := reg.GetNode(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			return node.Status.Conditions, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported resource type %q, must be service, job or node", kind)
+	}
+
+	return get, nil
+}
@@ -0,0 +1,51 @@
+// file: cmd/ecsm-cli/cmd/wait_test.go
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestWaitForObject 测试 wait 通过 API Server 读取对象的状况。
+func TestWaitForObject(t *testing.T) {
+	c, reg := newTestRemoteRegistry(t)
+	ctx := context.Background()
+
+	replicas := int32(1)
+	service, err := reg.CreateService(ctx, &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeDynamic, Replicas: &replicas},
+			Template:           ecsmv1.ContainerTemplateSpec{Image: "web@1.0"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	service.Status.Conditions = []metav1.Condition{{
+		Type: "Available", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now(),
+	}}
+	if _, err := reg.UpdateServiceStatus(ctx, service); err != nil {
+		t.Fatalf("UpdateServiceStatus failed: %v", err)
+	}
+
+	if err := waitForObject(ctx, c, "default", "service/web", "condition=available", time.Second); err != nil {
+		t.Errorf("wait for condition=available: %v", err)
+	}
+	if err := waitForObject(ctx, c, "default", "service/missing", "delete", time.Second); err != nil {
+		t.Errorf("wait for deletion of a missing service: %v", err)
+	}
+	err = waitForObject(ctx, c, "default", "service/web", "condition=Available=False", 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("wait for condition=Available=False error = %v, want a timeout", err)
+	}
+	if err := waitForObject(ctx, c, "default", "pod/web", "delete", time.Second); err == nil {
+		t.Error("wait for an unsupported type succeeded, want an error")
+	}
+}
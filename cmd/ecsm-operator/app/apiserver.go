@@ -0,0 +1,66 @@
+// file: cmd/ecsm-operator/app/apiserver.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/apiserver"
+	"github.com/fx147/ecsm-operator/pkg/apiserver/admission"
+	"github.com/fx147/ecsm-operator/pkg/openapi"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/klog/v2"
+)
+
+// newAdmissionChain 根据准入配置文件创建准入插件链，没有配置文件时返回 nil。
+func newAdmissionChain(configFile string) (*admission.Chain, error) {
+	if configFile == "" {
+		return nil, nil
+	}
+	config, err := admission.LoadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	chain := admission.NewChain()
+	if err := chain.RegisterWebhooks(config.Webhooks); err != nil {
+		return nil, fmt.Errorf("invalid admission config %s: %w", configFile, err)
+	}
+	klog.Infof("Registered admission webhooks %v", chain.Names())
+	return chain, nil
+}
+
+// serveAPI 在 addr 上启动暴露 Registry 的 API Server，返回的函数用于关闭它。
+// 只有 leader 持有 Registry，因此 API Server 在获得写锁之后才启动。
+func serveAPI(addr string, reg registry.Interface, chain *admission.Chain) (func(), error) {
+	api, err := apiserver.New(reg, chain)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(apiserver.APIPrefix, api)
+	mux.Handle(apiserver.APIPrefix+"/", api)
+	mux.Handle(openapi.Path, api)
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		klog.Infof("Serving the registry API on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Errorf("API server failed: %v", err)
+		}
+	}()
+
+	return func() {
+		// WATCH 请求是长连接，先结束它们，Shutdown 超时后直接关闭
+		api.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			klog.Warningf("Failed to shut down API server gracefully: %v", err)
+			server.Close()
+		}
+	}, nil
+}
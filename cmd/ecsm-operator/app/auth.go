@@ -0,0 +1,126 @@
+// file: cmd/ecsm-operator/app/auth.go
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+)
+
+// operator 认证 ECSM API Server 的方式，参见 --auth-mode
+const (
+	authModeNone    = "none"
+	authModeToken   = "token"
+	authModeBasic   = "basic"
+	authModeSession = "session"
+)
+
+// authModes 是 --auth-mode 可以使用的值
+var authModes = []string{authModeNone, authModeToken, authModeBasic, authModeSession}
+
+// effectiveAuthMode 返回实际使用的认证方式：没有指定 --auth-mode 时，
+// 指定了 --token-file 使用 token，指定了 --username 使用 session，否则不认证。
+func (o *Options) effectiveAuthMode() string {
+	switch {
+	case o.AuthMode != "":
+		return o.AuthMode
+	case o.TokenFile != "":
+		return authModeToken
+	case o.Username != "":
+		return authModeSession
+	}
+	return authModeNone
+}
+
+// validateAuth 检查认证相关的选项是否合法。
+func (o *Options) validateAuth() error {
+	switch mode := o.effectiveAuthMode(); mode {
+	case authModeNone:
+	case authModeToken:
+		if o.TokenFile == "" {
+			return fmt.Errorf("auth-mode %s requires token-file", mode)
+		}
+	case authModeBasic, authModeSession:
+		if o.Username == "" || o.PasswordFile == "" {
+			return fmt.Errorf("auth-mode %s requires username and password-file", mode)
+		}
+	default:
+		return fmt.Errorf("unsupported auth-mode %q, must be one of %v", mode, authModes)
+	}
+	return nil
+}
+
+// configureAuth 按照认证相关的选项为 client 设置 AuthProvider，每个集群的客户端各自登录。
+func (o *Options) configureAuth(client *clientset.Clientset) error {
+	switch o.effectiveAuthMode() {
+	case authModeToken:
+		client.SetTokenSource(newFileTokenSource(o.TokenFile))
+	case authModeBasic, authModeSession:
+		password, err := readSecretFile(o.PasswordFile)
+		if err != nil {
+			return err
+		}
+		if o.effectiveAuthMode() == authModeBasic {
+			client.SetAuthProvider(rest.NewBasicAuthProvider(o.Username, password))
+		} else {
+			client.SetAuthProvider(clientset.NewLoginAuthProvider(client.Auth(), o.Username, password))
+		}
+	}
+	return nil
+}
+
+// readSecretFile 读取只包含一个凭据的文件，去掉首尾的空白。
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read credentials: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("credentials file %s is empty", path)
+	}
+	return secret, nil
+}
+
+// fileTokenSource 提供文件中的 Token，文件被修改后重新读取，使轮换 Token 不需要重启 operator。
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+func newFileTokenSource(path string) *fileTokenSource {
+	return &fileTokenSource{path: path}
+}
+
+func (s *fileTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if s.token != "" {
+			// 文件在轮换时可能短暂地不存在，继续使用已经读取的 Token
+			return s.token, nil
+		}
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	if s.token != "" && info.ModTime().Equal(s.modTime) {
+		return s.token, nil
+	}
+	token, err := readSecretFile(s.path)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.modTime = token, info.ModTime()
+	return s.token, nil
+}
@@ -0,0 +1,128 @@
+// file: cmd/ecsm-operator/app/health.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/healthz"
+	"github.com/fx147/ecsm-operator/pkg/informer"
+	"github.com/fx147/ecsm-operator/pkg/metrics"
+	bolt "go.etcd.io/bbolt"
+	"k8s.io/klog/v2"
+)
+
+// ecsmAPICheckTimeout 是探测 ECSM API Server 可达性的超时时间
+const ecsmAPICheckTimeout = 3 * time.Second
+
+// healthState 保存了健康检查需要观察的运行时状态。
+// 健康检查服务在获得 Registry 的写锁 (成为 leader) 之前就已经启动，
+// 所以这些字段在进程运行期间会被逐步填充。
+type healthState struct {
+	db         atomic.Pointer[bolt.DB]
+	ecsmClient atomic.Pointer[clientset.Clientset]
+	factory    atomic.Value // informer.SharedInformerFactory
+}
+
+// leaderCheck 在进程获得 Registry 的写锁之前失败。
+// bbolt 的文件锁保证同一时刻只有一个 operator 进程在运行控制器，持有锁的进程就是 leader。
+func (s *healthState) leaderCheck(_ *http.Request) error {
+	if s.db.Load() == nil {
+		return errors.New("not the leader, waiting for the registry lock")
+	}
+	return nil
+}
+
+// registryCheck 确认 Registry 可以被读取。
+func (s *healthState) registryCheck(_ *http.Request) error {
+	db := s.db.Load()
+	if db == nil {
+		return errors.New("registry is not open")
+	}
+	return db.View(func(tx *bolt.Tx) error { return nil })
+}
+
+// registryLivenessCheck 与 registryCheck 相同，但在成为 leader 之前总是成功，
+// 以免进程监管程序不断重启等待锁的备用进程。
+func (s *healthState) registryLivenessCheck(r *http.Request) error {
+	if s.db.Load() == nil {
+		return nil
+	}
+	return s.registryCheck(r)
+}
+
+// ecsmAPICheck 通过列出一个节点来确认 ECSM API Server 可达。
+func (s *healthState) ecsmAPICheck(r *http.Request) error {
+	client := s.ecsmClient.Load()
+	if client == nil {
+		return errors.New("ECSM client is not initialized")
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), ecsmAPICheckTimeout)
+	defer cancel()
+	if _, err := client.Nodes().List(ctx, clientset.NodeListOptions{PageNum: 1, PageSize: 1, BasicInfo: true}); err != nil {
+		return fmt.Errorf("ECSM API server is unreachable: %w", err)
+	}
+	return nil
+}
+
+// informerSyncCheck 在所有 Informer 完成第一次同步之前失败。
+func (s *healthState) informerSyncCheck(_ *http.Request) error {
+	factory, _ := s.factory.Load().(informer.SharedInformerFactory)
+	if factory == nil {
+		return errors.New("informers are not started")
+	}
+	if !factory.HasSynced() {
+		return errors.New("informers have not synced yet")
+	}
+	return nil
+}
+
+// installHealthChecks 注册 /livez、/readyz 和 /healthz。
+//   - /livez 只在进程本身卡死时失败，进程监管程序可以据此重启 operator；
+//   - /readyz 还要求进程是 leader、能访问 ECSM API 且 Informer 已同步；
+//   - /healthz 保留为 /readyz 的别名，兼容只支持单个探测地址的监控系统。
+func (s *healthState) installHealthChecks(mux *http.ServeMux) {
+	ping := healthz.PingHealthz
+	registry := healthz.NamedCheck("registry", s.registryCheck)
+	readyChecks := []healthz.HealthChecker{
+		ping,
+		healthz.NamedCheck("leader", s.leaderCheck),
+		registry,
+		healthz.NamedCheck("ecsm-api", s.ecsmAPICheck),
+		healthz.NamedCheck("informer-sync", s.informerSyncCheck),
+	}
+
+	healthz.InstallPathHandler(mux, "/livez", ping, healthz.NamedCheck("registry", s.registryLivenessCheck))
+	healthz.InstallPathHandler(mux, "/readyz", readyChecks...)
+	healthz.InstallHandler(mux, readyChecks...)
+}
+
+// serveHealth 启动健康检查 HTTP 服务，返回的函数用于关闭它。
+// 同一个服务也在 /metrics 上暴露 Prometheus 指标。
+func (s *healthState) serveHealth(addr string) func() {
+	mux := http.NewServeMux()
+	s.installHealthChecks(mux)
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		klog.Infof("Serving health checks on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Errorf("Health check server failed: %v", err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			klog.Warningf("Failed to shut down health check server: %v", err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// file: cmd/ecsm-operator/app/health_test.go
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"github.com/fx147/ecsm-operator/pkg/informer"
+	bolt "go.etcd.io/bbolt"
+)
+
+// fakeInformerFactory 只实现健康检查用到的 HasSynced。
+type fakeInformerFactory struct {
+	informer.SharedInformerFactory
+	synced atomic.Bool
+}
+
+func (f *fakeInformerFactory) HasSynced() bool { return f.synced.Load() }
+
+// newTestHealthClient 返回一个 ECSM 客户端，ecsmUp 为 false 时列出节点以 503 失败。
+func newTestHealthClient(ecsmUp *atomic.Bool) *clientset.Clientset {
+	fake := rest.NewFake()
+	fake.RESTClient.SetRetryPolicy(rest.RetryPolicy{})
+	fake.AddReactor("GET", "node", func(action rest.Action) (bool, *http.Response, error) {
+		if !ecsmUp.Load() {
+			return rest.RespondWithError(http.StatusServiceUnavailable, "unavailable")(action)
+		}
+		return rest.RespondWith(&clientset.NodeList{})(action)
+	})
+	return clientset.NewForRESTClient(fake.RESTClient)
+}
+
+// probe 请求 path 并返回状态码和响应体。
+func probe(t *testing.T, mux *http.ServeMux, path string) (int, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code, w.Body.String()
+}
+
+// expectProbe 检查 path 的状态码，期望失败时还检查失败的检查项出现在输出中。
+func expectProbe(t *testing.T, mux *http.ServeMux, path string, want int, failed ...string) {
+	t.Helper()
+	code, body := probe(t, mux, path)
+	if code != want {
+		t.Errorf("%s: expected status %d, got %d: %s", path, want, code, body)
+	}
+	for _, name := range failed {
+		if !strings.Contains(body, "[-]"+name+" failed") {
+			t.Errorf("%s: expected check %q to fail, got: %s", path, name, body)
+		}
+	}
+}
+
+// TestHealthChecks 测试进程从等待 Registry 锁、成为 leader、连上 ECSM API、Informer 同步完成
+// 直到 Registry 不可读的过程中，/livez、/readyz 和 /healthz 的结果。
+func TestHealthChecks(t *testing.T) {
+	s := &healthState{}
+	mux := http.NewServeMux()
+	s.installHealthChecks(mux)
+
+	// 等待锁的备用进程是存活的，但还没有就绪
+	expectProbe(t, mux, "/livez", http.StatusOK)
+	expectProbe(t, mux, "/readyz", http.StatusInternalServerError, "leader", "registry", "ecsm-api", "informer-sync")
+	expectProbe(t, mux, "/healthz", http.StatusInternalServerError, "leader")
+
+	// 获得了锁，但 ECSM API 不可达
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	s.db.Store(db)
+	var ecsmUp atomic.Bool
+	s.ecsmClient.Store(newTestHealthClient(&ecsmUp))
+	factory := &fakeInformerFactory{}
+	s.factory.Store(informer.SharedInformerFactory(factory))
+
+	expectProbe(t, mux, "/readyz/leader", http.StatusOK)
+	expectProbe(t, mux, "/readyz/registry", http.StatusOK)
+	expectProbe(t, mux, "/readyz/ecsm-api", http.StatusInternalServerError)
+	expectProbe(t, mux, "/readyz", http.StatusInternalServerError, "ecsm-api", "informer-sync")
+
+	// ECSM API 恢复，Informer 仍在同步
+	ecsmUp.Store(true)
+	expectProbe(t, mux, "/readyz/ecsm-api", http.StatusOK)
+	expectProbe(t, mux, "/readyz", http.StatusInternalServerError, "informer-sync")
+
+	// 全部就绪
+	factory.synced.Store(true)
+	expectProbe(t, mux, "/readyz", http.StatusOK)
+	expectProbe(t, mux, "/healthz", http.StatusOK)
+	if code, body := probe(t, mux, "/readyz?verbose"); code != http.StatusOK || !strings.Contains(body, "[+]informer-sync ok") {
+		t.Errorf("Expected a verbose report of passed checks, got %d: %s", code, body)
+	}
+
+	// ECSM API 再次不可达只影响就绪，不影响存活
+	ecsmUp.Store(false)
+	expectProbe(t, mux, "/readyz", http.StatusInternalServerError, "ecsm-api")
+	expectProbe(t, mux, "/livez", http.StatusOK)
+	ecsmUp.Store(true)
+
+	// 成为 leader 之后 Registry 不可读，进程需要被重启
+	db.Close()
+	expectProbe(t, mux, "/livez", http.StatusInternalServerError, "registry")
+	expectProbe(t, mux, "/readyz", http.StatusInternalServerError, "registry")
+}
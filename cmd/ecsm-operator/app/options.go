@@ -0,0 +1,344 @@
+// file: cmd/ecsm-operator/app/options.go
+
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/controller"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"github.com/spf13/pflag"
+)
+
+// Options 包含了运行 ecsm-operator 所需的全部配置。
+type Options struct {
+	// ECSM API Server 的连接参数
+	Protocol string
+	Host     string
+	Port     string
+	// ClusterName 是由上面的连接参数指定的默认 ECSM 集群的名称，
+	// 没有指定 spec.cluster 的 ECSMService 由它负责
+	ClusterName string
+	// Clusters 是额外的具名 ECSM 集群，值为 "protocol://host:port" 形式的地址
+	Clusters map[string]string
+	// TLS 是连接使用 https 的 ECSM API Server 的设置，所有 https 集群使用同样的设置
+	TLS rest.TLSConfig
+
+	// AuthMode 是认证 ECSM API Server 的方式：none、token、basic 或 session，为空时根据下面的选项推断。
+	// 所有集群使用同样的凭据
+	AuthMode string
+	// TokenFile 是包含 Bearer Token 的文件的路径，文件被修改后重新读取
+	TokenFile string
+	// Username 是 basic 和 session 方式使用的用户名
+	Username string
+	// PasswordFile 是包含 Username 的密码的文件的路径，密码不通过命令行传入，避免出现在进程列表中
+	PasswordFile string
+
+	// ProxyURL 是访问所有 ECSM API Server 使用的代理，为空时按照 HTTP_PROXY、HTTPS_PROXY 和 NO_PROXY 环境变量选择代理
+	ProxyURL string
+	// APIVersion 是所有 ECSM API Server 使用的 API 版本，为 "auto" 时启动时与每个服务器协商
+	APIVersion string
+
+	// RegistryDB 是 bbolt 数据库文件的路径
+	RegistryDB string
+	// EncryptionKeyFile 是 base64 编码的 AES 密钥文件的路径，用于加密存储 ECSMSecret，
+	// 为空时不能创建和读取 ECSMSecret
+	EncryptionKeyFile string
+
+	// MaxInflightRequests 是同一时刻发往 ECSM API Server 的最大请求数，0 表示不限制
+	MaxInflightRequests int
+	// RequestQPS 和 RequestBurst 限制每个集群的客户端每秒发往 ECSM API Server 的请求数，RequestQPS 为 0 表示不限制
+	RequestQPS   float32
+	RequestBurst int
+	// RequestRetry 是发往 ECSM API Server 的请求遇到连接失败、超时和 5xx 时的重试策略，
+	// 避免短暂的网络故障导致 reconcile 失败
+	RequestRetry rest.RetryPolicy
+
+	// ServiceWorkers 是 ECSMService 控制器并发处理的 worker 数量
+	ServiceWorkers int
+	// ServiceController 是 ECSMService 控制器的可调参数
+	ServiceController controller.ServiceControllerOptions
+	// ResyncPeriod 是 Informer 全量同步 Registry 和轮询 ECSM 平台的周期
+	ResyncPeriod time.Duration
+	// NodeSyncPeriod 是节点控制器同步平台节点的周期
+	NodeSyncPeriod time.Duration
+	// AutoscalerSyncPeriod 是自动扩缩容控制器重新计算期望副本数的周期
+	AutoscalerSyncPeriod time.Duration
+	// JobSyncPeriod 是作业控制器同步 ECSMJob 的周期
+	JobSyncPeriod time.Duration
+	// CronJobSyncPeriod 是定时作业控制器检查计划时间的周期
+	CronJobSyncPeriod time.Duration
+	// NodeSetSyncPeriod 是节点集控制器根据节点变化重新选择节点的周期
+	NodeSetSyncPeriod time.Duration
+	// Remediation 是容器健康补救控制器的可调参数
+	Remediation controller.RemediationControllerOptions
+	// GarbageCollector 是无主平台服务垃圾回收器的可调参数
+	GarbageCollector controller.GarbageCollectorOptions
+
+	// LeaderElect 为 true 时，如果 Registry 已被另一个 operator 进程锁定，
+	// 当前进程会作为备用进程等待，而不是直接退出
+	LeaderElect bool
+	// LeaderRetryPeriod 是备用进程尝试获取 Registry 锁的间隔
+	LeaderRetryPeriod time.Duration
+
+	// HealthzBindAddress 是健康检查 HTTP 服务的监听地址，为空时不启动
+	HealthzBindAddress string
+	// PlatformMetricsPeriod 是抓取 ECSM 平台节点、容器和服务指标的周期，为 0 时不抓取。
+	// 抓取到的指标和 operator 自身的指标一起暴露在健康检查服务的 /metrics 上
+	PlatformMetricsPeriod time.Duration
+	// APIBindAddress 是暴露 Registry 的 API Server 的监听地址，为空时不启动。
+	// API Server 不做认证，只应该监听在可信的网络上
+	APIBindAddress string
+	// AdmissionConfigFile 是准入 webhook 配置文件的路径，API Server 的写请求需要经过其中的 webhook
+	AdmissionConfigFile string
+
+	// SnapshotTarget 是 Registry 快照的存放位置，可以是本地目录或 http(s) 地址，为空时不写入快照
+	SnapshotTarget string
+	// SnapshotPeriod 是两次 Registry 快照的间隔
+	SnapshotPeriod time.Duration
+	// SnapshotRetention 是最多保留的快照数量，0 表示不按数量清理
+	SnapshotRetention int
+	// SnapshotMaxAge 是快照的最长保留时间，0 表示不按时间清理
+	SnapshotMaxAge time.Duration
+
+	// ShutdownGracePeriod 是收到退出信号后，等待进行中的调谐完成的最长时间
+	ShutdownGracePeriod time.Duration
+}
+
+// NewOptions 返回带有默认值的 Options。
+func NewOptions() *Options {
+	return &Options{
+		Protocol:             "http",
+		Host:                 "localhost",
+		Port:                 "3001",
+		ClusterName:          controller.DefaultClusterName,
+		APIVersion:           clientset.AutoAPIVersion,
+		RegistryDB:           "ecsm-operator.db",
+		MaxInflightRequests:  10,
+		RequestQPS:           20,
+		RequestBurst:         40,
+		RequestRetry:         rest.DefaultRetryPolicy,
+		ServiceWorkers:       2,
+		ServiceController:    controller.DefaultServiceControllerOptions(),
+		ResyncPeriod:         30 * time.Second,
+		NodeSyncPeriod:       controller.DefaultNodeSyncPeriod,
+		AutoscalerSyncPeriod: controller.DefaultAutoscalerSyncPeriod,
+		JobSyncPeriod:        controller.DefaultJobSyncPeriod,
+		CronJobSyncPeriod:    controller.DefaultCronJobSyncPeriod,
+		NodeSetSyncPeriod:    controller.DefaultNodeSetSyncPeriod,
+		Remediation:          controller.DefaultRemediationControllerOptions(),
+		GarbageCollector:     controller.DefaultGarbageCollectorOptions(),
+		LeaderRetryPeriod:    2 * time.Second,
+		HealthzBindAddress:   ":8081",
+		SnapshotPeriod:       time.Hour,
+		SnapshotRetention:    24,
+		ShutdownGracePeriod:  30 * time.Second,
+	}
+}
+
+// AddFlags 将所有选项注册为命令行标志。
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Protocol, "protocol", o.Protocol, "The protocol to use (http or https)")
+	fs.StringVar(&o.Host, "host", o.Host, "The host of the ECSM API server")
+	fs.StringVar(&o.Port, "port", o.Port, "The port of the ECSM API server")
+	fs.StringVar(&o.ClusterName, "cluster-name", o.ClusterName, "Name of the ECSM cluster given by --protocol, --host and --port, used by ECSMServices without spec.cluster")
+	fs.StringToStringVar(&o.Clusters, "cluster", o.Clusters, "Additional named ECSM API servers as name=protocol://host:port, selected by spec.cluster of ECSMServices (can be repeated)")
+	fs.StringVar(&o.TLS.CAFile, "certificate-authority", o.TLS.CAFile, "Path to a PEM file of the CA certificates used to verify https ECSM API servers (default is the system roots)")
+	fs.StringVar(&o.TLS.CertFile, "client-certificate", o.TLS.CertFile, "Path to a PEM client certificate presented to https ECSM API servers")
+	fs.StringVar(&o.TLS.KeyFile, "client-key", o.TLS.KeyFile, "Path to the PEM private key of --client-certificate")
+	fs.StringVar(&o.TLS.ServerName, "tls-server-name", o.TLS.ServerName, "The server name used to verify the certificates of https ECSM API servers (default is their host)")
+	fs.StringVar(&o.ProxyURL, "proxy-url", o.ProxyURL, "URL of the http, https or socks5 proxy used to reach the ECSM API servers, for example through a jump host (default is the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables)")
+	fs.StringVar(&o.APIVersion, "api-version", o.APIVersion, "The version of the ECSM API to use, for example v1, or \"auto\" to use the newest version supported by both each server and the operator, probed at startup")
+	fs.BoolVar(&o.TLS.Insecure, "insecure-skip-tls-verify", o.TLS.Insecure, "Do not verify the certificates of https ECSM API servers. This makes the connections insecure and should only be used for testing")
+	fs.StringVar(&o.AuthMode, "auth-mode", o.AuthMode, "How to authenticate to the ECSM API servers: none, token (send the bearer token in --token-file), basic (send --username and the password in --password-file with every request) or session (log in with them and log in again when the session expires). Inferred from the other flags by default")
+	fs.StringVar(&o.TokenFile, "token-file", o.TokenFile, "Path to a file holding the bearer token used to authenticate to the ECSM API servers, re-read when it changes")
+	fs.StringVar(&o.Username, "username", o.Username, "The username used to authenticate to the ECSM API servers")
+	fs.StringVar(&o.PasswordFile, "password-file", o.PasswordFile, "Path to a file holding the password of --username")
+	fs.StringVar(&o.RegistryDB, "registry-db", o.RegistryDB, "Path to the registry database file")
+	fs.StringVar(&o.EncryptionKeyFile, "encryption-key-file", o.EncryptionKeyFile, "Path to a file holding a base64 encoded 16, 24 or 32 byte AES key used to encrypt ECSMSecrets in the registry")
+	fs.IntVar(&o.MaxInflightRequests, "max-inflight-requests", o.MaxInflightRequests, "Maximum number of concurrent requests sent to the ECSM API server, 0 for no limit")
+	fs.Float32Var(&o.RequestQPS, "request-qps", o.RequestQPS, "Maximum average number of requests per second sent to each ECSM API server, 0 for no limit")
+	fs.IntVar(&o.RequestBurst, "request-burst", o.RequestBurst, "Maximum burst of requests sent to each ECSM API server above --request-qps")
+	fs.IntVar(&o.RequestRetry.MaxAttempts, "request-retry-attempts", o.RequestRetry.MaxAttempts, "Maximum number of attempts of a request to the ECSM API server failing with a connection error, timeout or 5xx, 1 to disable retries. Creating requests carry an Idempotency-Key header and are retried like idempotent ones, other non-idempotent requests are only retried when the server did not receive them")
+	fs.BoolVar(&o.RequestRetry.NoIdempotencyKeys, "request-retry-no-idempotency-keys", o.RequestRetry.NoIdempotencyKeys, "Do not send Idempotency-Key headers with creating requests, and do not retry them after timeouts and 5xx. For ECSM API servers that would process a retried request twice")
+	fs.DurationVar(&o.RequestRetry.BaseDelay, "request-retry-base-delay", o.RequestRetry.BaseDelay, "Delay before the first retry of a failed request to the ECSM API server, doubled on every retry")
+	fs.DurationVar(&o.RequestRetry.MaxDelay, "request-retry-max-delay", o.RequestRetry.MaxDelay, "Maximum delay between retries of a failed request to the ECSM API server")
+	fs.IntVar(&o.ServiceWorkers, "service-workers", o.ServiceWorkers, "Number of ECSMService objects that are allowed to sync concurrently")
+	fs.BoolVar(&o.ServiceController.DryRun, "dry-run", o.ServiceController.DryRun, "Only record the actions the controllers would take on the ECSM platform as events and status, without executing them")
+	fs.DurationVar(&o.ServiceController.ReconcileTimeout, "service-reconcile-timeout", o.ServiceController.ReconcileTimeout, "Maximum duration of a single ECSMService sync before its ECSM API calls are canceled")
+	fs.DurationVar(&o.ServiceController.StatusFlushInterval, "status-flush-interval", o.ServiceController.StatusFlushInterval, "How often coalesced ECSMService status updates are written to the registry, 0 to write every update immediately")
+	fs.DurationVar(&o.ServiceController.RequeueAfter, "service-requeue-after", o.ServiceController.RequeueAfter, "How often a converged ECSMService is re-verified against the ECSM platform without any event, 0 to disable")
+	fs.DurationVar(&o.ServiceController.RateLimiter.BaseDelay, "service-retry-base-delay", o.ServiceController.RateLimiter.BaseDelay, "Initial delay before retrying a failed ECSMService sync, doubled on every failure")
+	fs.DurationVar(&o.ServiceController.RateLimiter.MaxDelay, "service-retry-max-delay", o.ServiceController.RateLimiter.MaxDelay, "Maximum delay before retrying a failed ECSMService sync")
+	fs.Float64Var(&o.ServiceController.RateLimiter.QPS, "service-retry-qps", o.ServiceController.RateLimiter.QPS, "Overall number of ECSMService sync retries allowed per second")
+	fs.IntVar(&o.ServiceController.RateLimiter.Burst, "service-retry-burst", o.ServiceController.RateLimiter.Burst, "Overall burst of ECSMService sync retries")
+	fs.DurationVar(&o.ResyncPeriod, "resync-period", o.ResyncPeriod, "How often informers relist the registry and poll the ECSM platform")
+	fs.DurationVar(&o.NodeSyncPeriod, "node-sync-period", o.NodeSyncPeriod, "How often platform nodes are synced into ECSMNode objects")
+	fs.DurationVar(&o.AutoscalerSyncPeriod, "autoscaler-sync-period", o.AutoscalerSyncPeriod, "How often ECSMServiceAutoscalers recompute the replica count of their target service")
+	fs.DurationVar(&o.JobSyncPeriod, "job-sync-period", o.JobSyncPeriod, "How often ECSMJobs are synced with the containers that run them")
+	fs.DurationVar(&o.CronJobSyncPeriod, "cronjob-sync-period", o.CronJobSyncPeriod, "How often ECSMCronJobs are checked for due schedules")
+	fs.DurationVar(&o.NodeSetSyncPeriod, "nodeset-sync-period", o.NodeSetSyncPeriod, "How often ECSMNodeSets reselect their nodes as nodes join and leave")
+	fs.DurationVar(&o.Remediation.Period, "remediation-period", o.Remediation.Period, "How often the containers of services with a remediation policy are checked")
+	fs.DurationVar(&o.GarbageCollector.Period, "gc-period", o.GarbageCollector.Period, "How often platform services whose owner no longer exists are collected")
+	fs.DurationVar(&o.GarbageCollector.GracePeriod, "gc-grace-period", o.GarbageCollector.GracePeriod, "How long a platform service must stay ownerless before it is deleted")
+	fs.BoolVar(&o.LeaderElect, "leader-elect", o.LeaderElect, "Wait as a standby when another operator holds the registry lock, instead of exiting")
+	fs.DurationVar(&o.LeaderRetryPeriod, "leader-retry-period", o.LeaderRetryPeriod, "How often a standby operator tries to acquire the registry lock")
+	fs.StringVar(&o.HealthzBindAddress, "healthz-bind-address", o.HealthzBindAddress, "The address the health check and metrics server listens on, empty to disable")
+	fs.DurationVar(&o.PlatformMetricsPeriod, "platform-metrics-period", o.PlatformMetricsPeriod, "How often node, container and service metrics are scraped from the ECSM platform and exported on /metrics, 0 to disable")
+	fs.StringVar(&o.APIBindAddress, "api-bind-address", o.APIBindAddress, "The address the registry API server listens on, empty to disable. The API is served without authentication, so bind it to a trusted network only")
+	fs.StringVar(&o.AdmissionConfigFile, "admission-config-file", o.AdmissionConfigFile, "Path to a YAML file listing the admission webhooks called for writes through the registry API server")
+	fs.StringVar(&o.SnapshotTarget, "snapshot-target", o.SnapshotTarget, "Directory or http(s) URL that periodic registry snapshots are written to, empty to disable. HTTP targets must accept PUT, GET and DELETE")
+	fs.DurationVar(&o.SnapshotPeriod, "snapshot-period", o.SnapshotPeriod, "How often a registry snapshot is written to --snapshot-target")
+	fs.IntVar(&o.SnapshotRetention, "snapshot-retention", o.SnapshotRetention, "Number of registry snapshots to keep, 0 to keep any number")
+	fs.DurationVar(&o.SnapshotMaxAge, "snapshot-max-age", o.SnapshotMaxAge, "How long registry snapshots are kept, 0 to keep them regardless of age. The newest snapshot is always kept")
+	fs.DurationVar(&o.ShutdownGracePeriod, "shutdown-grace-period", o.ShutdownGracePeriod, "How long to wait for in-flight reconciles to finish on shutdown")
+}
+
+// Validate 检查选项是否合法。
+func (o *Options) Validate() error {
+	if o.Host == "" || o.Port == "" || o.Protocol == "" {
+		return fmt.Errorf("host, port, and protocol must be specified")
+	}
+	if o.ClusterName == "" {
+		return fmt.Errorf("cluster-name must be specified")
+	}
+	for name, addr := range o.Clusters {
+		if name == "" || name == o.ClusterName {
+			return fmt.Errorf("cluster %q: name must be non-empty and differ from cluster-name", name)
+		}
+		if _, _, _, err := parseClusterAddress(addr); err != nil {
+			return fmt.Errorf("cluster %q: %w", name, err)
+		}
+	}
+	if err := o.validateTLS(); err != nil {
+		return err
+	}
+	if _, err := rest.ProxyURL(o.ProxyURL); err != nil {
+		return err
+	}
+	if o.APIVersion == "" || strings.Contains(o.APIVersion, "/") {
+		return fmt.Errorf("invalid api-version %q, must be a version such as v1 or %q", o.APIVersion, clientset.AutoAPIVersion)
+	}
+	if err := o.validateAuth(); err != nil {
+		return err
+	}
+	if o.RegistryDB == "" {
+		return fmt.Errorf("registry-db must be specified")
+	}
+	if o.ServiceWorkers < 1 {
+		return fmt.Errorf("service-workers must be at least 1, got %d", o.ServiceWorkers)
+	}
+	if o.MaxInflightRequests < 0 {
+		return fmt.Errorf("max-inflight-requests must not be negative, got %d", o.MaxInflightRequests)
+	}
+	if o.RequestQPS < 0 {
+		return fmt.Errorf("request-qps must not be negative, got %v", o.RequestQPS)
+	}
+	if o.RequestQPS > 0 && o.RequestBurst < 1 {
+		return fmt.Errorf("request-burst must be at least 1, got %d", o.RequestBurst)
+	}
+	if o.RequestRetry.MaxAttempts < 1 {
+		return fmt.Errorf("request-retry-attempts must be at least 1, got %d", o.RequestRetry.MaxAttempts)
+	}
+	if o.RequestRetry.BaseDelay <= 0 || o.RequestRetry.MaxDelay < o.RequestRetry.BaseDelay {
+		return fmt.Errorf("request-retry-base-delay must be positive and not exceed request-retry-max-delay")
+	}
+	if o.ServiceController.ReconcileTimeout <= 0 {
+		return fmt.Errorf("service-reconcile-timeout must be positive")
+	}
+	if o.ServiceController.RequeueAfter < 0 {
+		return fmt.Errorf("service-requeue-after must not be negative")
+	}
+	if o.ServiceController.StatusFlushInterval < 0 {
+		return fmt.Errorf("status-flush-interval must not be negative")
+	}
+	rl := o.ServiceController.RateLimiter
+	if rl.BaseDelay <= 0 || rl.MaxDelay < rl.BaseDelay {
+		return fmt.Errorf("service-retry-base-delay must be positive and not greater than service-retry-max-delay")
+	}
+	if rl.QPS <= 0 || rl.Burst < 1 {
+		return fmt.Errorf("service-retry-qps must be positive and service-retry-burst must be at least 1")
+	}
+	if o.ResyncPeriod <= 0 || o.NodeSyncPeriod <= 0 || o.AutoscalerSyncPeriod <= 0 || o.JobSyncPeriod <= 0 || o.CronJobSyncPeriod <= 0 || o.NodeSetSyncPeriod <= 0 {
+		return fmt.Errorf("resync-period, node-sync-period, autoscaler-sync-period, job-sync-period, cronjob-sync-period and nodeset-sync-period must be positive")
+	}
+	if o.Remediation.Period <= 0 {
+		return fmt.Errorf("remediation-period must be positive")
+	}
+	if o.GarbageCollector.Period <= 0 || o.GarbageCollector.GracePeriod < 0 {
+		return fmt.Errorf("gc-period must be positive and gc-grace-period must not be negative")
+	}
+	if o.LeaderRetryPeriod <= 0 {
+		return fmt.Errorf("leader-retry-period must be positive")
+	}
+	if o.PlatformMetricsPeriod < 0 {
+		return fmt.Errorf("platform-metrics-period must not be negative")
+	}
+	if o.PlatformMetricsPeriod > 0 && o.HealthzBindAddress == "" {
+		return fmt.Errorf("platform-metrics-period requires healthz-bind-address, which serves /metrics")
+	}
+	if o.AdmissionConfigFile != "" && o.APIBindAddress == "" {
+		return fmt.Errorf("admission-config-file requires api-bind-address")
+	}
+	if o.SnapshotTarget != "" && o.SnapshotPeriod <= 0 {
+		return fmt.Errorf("snapshot-period must be positive")
+	}
+	if o.SnapshotRetention < 0 || o.SnapshotMaxAge < 0 {
+		return fmt.Errorf("snapshot-retention and snapshot-max-age must not be negative")
+	}
+	if o.ShutdownGracePeriod < 0 {
+		return fmt.Errorf("shutdown-grace-period must not be negative")
+	}
+	return nil
+}
+
+// parseClusterAddress 把 "protocol://host:port" 形式的集群地址拆分为 ECSM 客户端的连接参数。
+func parseClusterAddress(addr string) (protocol, host, port string, err error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", "", fmt.Errorf("invalid address %q: protocol must be http or https", addr)
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return "", "", "", fmt.Errorf("invalid address %q: host and port must be specified", addr)
+	}
+	return u.Scheme, u.Hostname(), u.Port(), nil
+}
+
+// validateTLS 检查 TLS 选项是否有 https 集群使用，并提前读取证书，使错误的证书在启动时报告。
+func (o *Options) validateTLS() error {
+	if o.TLS.IsZero() {
+		return nil
+	}
+	https := o.Protocol == "https"
+	for _, addr := range o.Clusters {
+		if protocol, _, _, _ := parseClusterAddress(addr); protocol == "https" {
+			https = true
+		}
+	}
+	if !https {
+		return fmt.Errorf("TLS options require an ECSM API server using protocol https")
+	}
+	_, err := o.TLS.ClientConfig()
+	return err
+}
+
+// restConfig 返回连接到一个 ECSM API Server 的 rest.Config，TLS 选项只用于 https 的服务器。
+func (o *Options) restConfig(protocol, host, port string) *rest.Config {
+	config := &rest.Config{Protocol: protocol, Host: host, Port: port, QPS: o.RequestQPS, Burst: o.RequestBurst}
+	// ProxyURL 已经在 Validate 中检查过
+	config.Proxy, _ = rest.ProxyURL(o.ProxyURL)
+	if o.APIVersion != clientset.AutoAPIVersion {
+		config.APIVersion = o.APIVersion
+	}
+	if protocol == "https" {
+		config.TLS = o.TLS
+	}
+	return config
+}
@@ -0,0 +1,356 @@
+// file: cmd/ecsm-operator/app/server.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/install"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/controller"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/exporter"
+	"github.com/fx147/ecsm-operator/pkg/informer"
+	"github.com/fx147/ecsm-operator/pkg/metrics"
+	"github.com/fx147/ecsm-operator/pkg/notifier"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/fx147/ecsm-operator/pkg/snapshot"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// NewOperatorCommand 创建 ecsm-operator 的根命令。
+func NewOperatorCommand() *cobra.Command {
+	opts := NewOptions()
+
+	cmd := &cobra.Command{
+		Use:   "ecsm-operator",
+		Short: "Run the ECSM controllers",
+		Long: `ecsm-operator is the controller manager of the ECSM declarative layer.
+
+It stores declared objects in an embedded registry and runs the control
+loops that drive the ECSM platform towards them.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			return Run(setupSignalContext(), opts)
+		},
+	}
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Run 启动所有控制器，并阻塞到 ctx 被取消。
+// ctx 取消后，它会停止 Informer 和控制器，并在 ShutdownGracePeriod 内等待进行中的调谐完成。
+func Run(ctx context.Context, opts *Options) error {
+	health := &healthState{}
+	if opts.HealthzBindAddress != "" {
+		stopHealth := health.serveHealth(opts.HealthzBindAddress)
+		defer stopHealth()
+	}
+
+	// --- 1. 期望世界: Registry ---
+	db, err := openRegistryDB(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if db == nil {
+		// 在成为 leader 之前就收到了退出信号
+		return nil
+	}
+	defer db.Close()
+	health.db.Store(db)
+
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		return fmt.Errorf("failed to create registry: %w", err)
+	}
+	if opts.EncryptionKeyFile != "" {
+		key, err := registry.LoadEncryptionKey(opts.EncryptionKeyFile)
+		if err != nil {
+			return err
+		}
+		transformer, err := registry.NewAESGCMTransformer(key)
+		if err != nil {
+			return err
+		}
+		reg.SetEncryption(transformer)
+	} else {
+		klog.Info("No encryption key file given, ECSMSecrets cannot be stored or referenced")
+	}
+	if opts.APIBindAddress != "" {
+		chain, err := newAdmissionChain(opts.AdmissionConfigFile)
+		if err != nil {
+			return err
+		}
+		stopAPI, err := serveAPI(opts.APIBindAddress, reg, chain)
+		if err != nil {
+			return fmt.Errorf("failed to start API server: %w", err)
+		}
+		defer stopAPI()
+	}
+
+	// --- 2. 现实世界: ECSM 客户端 ---
+	ecsmClient, err := clientset.NewClientsetForConfig(opts.restConfig(opts.Protocol, opts.Host, opts.Port))
+	if err != nil {
+		return fmt.Errorf("failed to create ECSM client: %w", err)
+	}
+	ecsmClient.SetMaxInflightRequests(opts.MaxInflightRequests)
+	ecsmClient.SetRetryPolicy(opts.RequestRetry)
+	if err := opts.negotiateAPIVersion(ctx, opts.ClusterName, ecsmClient); err != nil {
+		return err
+	}
+	if err := opts.configureAuth(ecsmClient); err != nil {
+		return err
+	}
+	health.ecsmClient.Store(ecsmClient)
+
+	// 其他具名集群各自使用独立的客户端，并发限制对每个集群分别生效
+	clusters := controller.NewClusterClients(opts.ClusterName, ecsmClient)
+	for name, addr := range opts.Clusters {
+		protocol, host, port, err := parseClusterAddress(addr)
+		if err != nil {
+			return fmt.Errorf("cluster %q: %w", name, err)
+		}
+		client, err := clientset.NewClientsetForConfig(opts.restConfig(protocol, host, port))
+		if err != nil {
+			return fmt.Errorf("failed to create ECSM client for cluster %q: %w", name, err)
+		}
+		client.SetMaxInflightRequests(opts.MaxInflightRequests)
+		client.SetRetryPolicy(opts.RequestRetry)
+		if err := opts.negotiateAPIVersion(ctx, name, client); err != nil {
+			return err
+		}
+		if err := opts.configureAuth(client); err != nil {
+			return err
+		}
+		clusters.Add(name, client)
+	}
+	klog.Infof("Managing ECSM clusters %v, default cluster is %q", clusters.Names(), opts.ClusterName)
+
+	scheme := runtime.NewScheme()
+	install.Install(scheme)
+
+	// --- 3. Informer 和控制器 ---
+	factory := informer.NewSharedInformerFactory(reg, ecsmClient, opts.ResyncPeriod)
+
+	// 就绪探针由 RemediationController 执行，结果由 ECSMServiceController 用于计算就绪副本数
+	readiness := controller.NewProbeResults()
+	serviceOpts := opts.ServiceController
+	serviceOpts.Readiness = readiness
+	serviceController := controller.NewECSMServiceController(
+		clusters,
+		reg,
+		factory.Services(),
+		factory.PlatformServices(),
+		record.NewRecorder(reg, scheme, ecsmv1.EventSource{Component: "ecsmservice-controller"}),
+		serviceOpts,
+	)
+	nodeController := controller.NewNodeController(
+		ecsmClient,
+		reg,
+		record.NewRecorder(reg, scheme, ecsmv1.EventSource{Component: "node-controller"}),
+		opts.NodeSyncPeriod,
+	)
+	autoscalerController := controller.NewAutoscalerController(
+		clusters,
+		reg,
+		record.NewRecorder(reg, scheme, ecsmv1.EventSource{Component: "autoscaler-controller"}),
+		opts.AutoscalerSyncPeriod,
+	)
+	jobController := controller.NewJobController(
+		clusters,
+		reg,
+		record.NewRecorder(reg, scheme, ecsmv1.EventSource{Component: "job-controller"}),
+		opts.JobSyncPeriod,
+	)
+	cronJobController := controller.NewCronJobController(
+		reg,
+		record.NewRecorder(reg, scheme, ecsmv1.EventSource{Component: "cronjob-controller"}),
+		opts.CronJobSyncPeriod,
+	)
+	nodeSetController := controller.NewNodeSetController(
+		reg,
+		record.NewRecorder(reg, scheme, ecsmv1.EventSource{Component: "nodeset-controller"}),
+		opts.NodeSetSyncPeriod,
+	)
+	remediationOpts := opts.Remediation
+	remediationOpts.DryRun = opts.ServiceController.DryRun
+	remediationOpts.Readiness = readiness
+	remediationController := controller.NewRemediationController(
+		clusters,
+		reg,
+		record.NewRecorder(reg, scheme, ecsmv1.EventSource{Component: "remediation-controller"}),
+		remediationOpts,
+	)
+	// 把控制器记录的事件发送到 ECSMNotification 中配置的 webhook
+	eventNotifier := notifier.New(reg)
+	gcOpts := opts.GarbageCollector
+	gcOpts.DryRun = opts.ServiceController.DryRun
+	// 每个集群都可能留下无主的平台服务，分别为它们运行一个垃圾回收器
+	var garbageCollectors []*controller.GarbageCollector
+	for _, name := range clusters.Names() {
+		client, _ := clusters.Get(name)
+		garbageCollectors = append(garbageCollectors, controller.NewGarbageCollector(client, reg, gcOpts))
+	}
+
+	// 可能失败的初始化都必须在启动任何 goroutine 之前完成，
+	// 否则提前返回时 defer 会在控制器仍在运行时关闭数据库
+	var platformExporter *exporter.Exporter
+	if opts.PlatformMetricsPeriod > 0 {
+		clients := make(map[string]clientset.Interface)
+		for _, name := range clusters.Names() {
+			clients[name], _ = clusters.Get(name)
+		}
+		platformExporter = exporter.New(clients, opts.PlatformMetricsPeriod)
+		if err := metrics.Registry.Register(platformExporter); err != nil {
+			return fmt.Errorf("failed to register platform metrics: %w", err)
+		}
+		defer metrics.Registry.Unregister(platformExporter)
+	}
+	var snapshotter *snapshot.Snapshotter
+	if opts.SnapshotTarget != "" {
+		target, err := snapshot.NewTarget(opts.SnapshotTarget)
+		if err != nil {
+			return err
+		}
+		snapshotter = snapshot.New(db, target, snapshot.Options{
+			Period:    opts.SnapshotPeriod,
+			Retention: opts.SnapshotRetention,
+			MaxAge:    opts.SnapshotMaxAge,
+		})
+	}
+
+	// --- 4. 启动 ---
+	stopCh := ctx.Done()
+	factory.Start(stopCh)
+	health.factory.Store(factory)
+
+	var wg sync.WaitGroup
+	wg.Add(8)
+	go func() {
+		defer wg.Done()
+		serviceController.Run(opts.ServiceWorkers, stopCh)
+	}()
+	go func() {
+		defer wg.Done()
+		nodeController.Run(stopCh)
+	}()
+	go func() {
+		defer wg.Done()
+		autoscalerController.Run(stopCh)
+	}()
+	go func() {
+		defer wg.Done()
+		jobController.Run(stopCh)
+	}()
+	go func() {
+		defer wg.Done()
+		cronJobController.Run(stopCh)
+	}()
+	go func() {
+		defer wg.Done()
+		nodeSetController.Run(stopCh)
+	}()
+	go func() {
+		defer wg.Done()
+		remediationController.Run(stopCh)
+	}()
+	go func() {
+		defer wg.Done()
+		eventNotifier.Run(stopCh)
+	}()
+	for _, gc := range garbageCollectors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gc.Run(stopCh)
+		}()
+	}
+	if platformExporter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			platformExporter.Run(stopCh)
+		}()
+	}
+	if snapshotter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			snapshotter.Run(stopCh)
+		}()
+	}
+
+	<-stopCh
+
+	// --- 5. 优雅退出 ---
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		factory.Shutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		klog.Info("All controllers stopped")
+		return nil
+	case <-time.After(opts.ShutdownGracePeriod):
+		// 直接返回会关闭数据库，bbolt 会等待进行中的事务结束，不会损坏数据
+		return fmt.Errorf("timed out after %v waiting for controllers to stop", opts.ShutdownGracePeriod)
+	}
+}
+
+// negotiateAPIVersion 在 --api-version=auto 时为集群 cluster 的 client 选择 API 版本。
+// 服务器暂时无法访问时使用默认版本，不阻止 operator 启动；服务器不支持 operator 的任何版本时返回错误。
+func (o *Options) negotiateAPIVersion(ctx context.Context, cluster string, client *clientset.Clientset) error {
+	if o.APIVersion != clientset.AutoAPIVersion {
+		return nil
+	}
+	v, err := client.NegotiateAPIVersion(ctx)
+	if errors.Is(err, clientset.ErrNoCommonAPIVersion) {
+		return fmt.Errorf("cluster %q: %w", cluster, err)
+	}
+	if err != nil {
+		klog.Warningf("Failed to negotiate the API version of cluster %q, using the default version: %v", cluster, err)
+		return nil
+	}
+	klog.Infof("Using API version %s for cluster %q", v, cluster)
+	return nil
+}
+
+// openRegistryDB 打开 Registry 的数据库文件并获得它的写锁。
+// 启用 leader 选举时，它会一直等待到其他 operator 进程释放锁 (备用模式)，
+// 如果在此期间 ctx 被取消，则返回 (nil, nil)。
+func openRegistryDB(ctx context.Context, opts *Options) (*bolt.DB, error) {
+	for {
+		// 设置打开超时，避免无限期阻塞在文件锁上
+		db, err := bolt.Open(opts.RegistryDB, 0600, &bolt.Options{Timeout: time.Second})
+		if err == nil {
+			klog.Infof("Acquired the registry lock on %s", opts.RegistryDB)
+			return db, nil
+		}
+		if !opts.LeaderElect || !errors.Is(err, berrors.ErrTimeout) {
+			return nil, fmt.Errorf("failed to open registry database %s: %w", opts.RegistryDB, err)
+		}
+
+		klog.V(2).Infof("Registry %s is locked by another operator, waiting to become the leader", opts.RegistryDB)
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(opts.LeaderRetryPeriod):
+		}
+	}
+}
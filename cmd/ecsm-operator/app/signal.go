@@ -0,0 +1,32 @@
+// file: cmd/ecsm-operator/app/signal.go
+
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"k8s.io/klog/v2"
+)
+
+// setupSignalContext 返回一个在收到 SIGINT 或 SIGTERM 时被取消的 context。
+// 第一次收到信号时开始优雅退出；再次收到信号时立即退出进程。
+func setupSignalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-c
+		klog.Infof("Received signal %s, shutting down gracefully", sig)
+		cancel()
+		sig = <-c
+		klog.Warningf("Received second signal %s, exiting immediately", sig)
+		klog.Flush()
+		os.Exit(1)
+	}()
+
+	return ctx
+}
@@ -0,0 +1,28 @@
+// file: cmd/ecsm-operator/main.go
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fx147/ecsm-operator/cmd/ecsm-operator/app"
+	"k8s.io/klog/v2"
+)
+
+func main() {
+	// 将 klog 的标志 (-v, --logtostderr 等) 添加到命令上
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+
+	command := app.NewOperatorCommand()
+	command.PersistentFlags().AddGoFlagSet(fs)
+
+	if err := command.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		klog.Flush()
+		os.Exit(1)
+	}
+	klog.Flush()
+}
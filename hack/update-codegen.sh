@@ -10,4 +10,18 @@ BOILERPLATE_PATH="${SCRIPT_ROOT}/hack/boilerplate.go.txt"
 
 DEEPCOPY_GEN="$(go env GOPATH)/bin/deepcopy-gen"
 
-"${DEEPCOPY_GEN}" -i ${SCRIPT_ROOT}/pkg/apis/ecsm/v1 -O zz_generated.deepcopy --output-file-base zz_generated.deepcopy --output-package ./pkg/apis/ecsm/v1 --go-header-file hack/boilerplate.go.txt
\ No newline at end of file
+# 为 pkg/apis 下每个带有 +k8s:deepcopy-gen=package 标记的 API 版本生成 zz_generated.deepcopy.go
+cd "${SCRIPT_ROOT}"
+for doc in $(grep -rl --include=doc.go '+k8s:deepcopy-gen=package' pkg/apis); do
+  pkg=$(dirname "${doc}")
+  echo "Generating deepcopy functions for ${pkg}"
+  "${DEEPCOPY_GEN}" --output-file zz_generated.deepcopy.go --go-header-file hack/boilerplate.go.txt "./${pkg}"
+done
+
+# 为 pkg/apis/ecsm/v1 的 API 类型生成 SwaggerDoc() 方法，pkg/openapi 用它们填写字段说明
+V1_DIR=pkg/apis/ecsm/v1
+echo "Generating swagger docs for ${V1_DIR}"
+go run ./hack/genswaggertypedocs \
+  --output "${V1_DIR}/types_swagger_doc_generated.go" \
+  --boilerplate "${BOILERPLATE_PATH}" \
+  $(ls "${V1_DIR}"/types.go "${V1_DIR}"/*_types.go)
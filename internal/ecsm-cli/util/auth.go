@@ -0,0 +1,136 @@
+// file: internal/ecsm-cli/util/auth.go
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+// tokenRefreshMargin 是 Token 过期前多久刷新它，避免请求在发送途中因为 Token 过期而失败
+const tokenRefreshMargin = time.Minute
+
+// CurrentContextName 返回 config 中正在使用的上下文的名称：--context 指定的上下文或当前上下文。
+// 上下文不存在时返回错误，没有使用任何上下文时返回空字符串。
+func CurrentContextName(config *CLIConfig) (string, error) {
+	name := viper.GetString("context")
+	if name == "" {
+		name = config.CurrentContext
+	}
+	if name == "" {
+		return "", nil
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		return "", fmt.Errorf("context %q not found in the config file", name)
+	}
+	return name, nil
+}
+
+// StoreToken 在配置文件 path 的上下文 name 中保存 token，token 为 nil 时删除保存的凭据。
+// 配置文件被重新读取，不会覆盖其它命令同时做出的修改。
+func StoreToken(path, name string, token *clientset.Token) error {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	ctx, ok := config.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q not found in config file %s", name, path)
+	}
+	ctx.SetToken(token)
+	return SaveConfig(path, config)
+}
+
+// contextAuthProvider 发送上下文中保存的 Token，在它过期前或被服务器拒绝后用刷新令牌换取新的 Token 并保存到配置文件。
+type contextAuthProvider struct {
+	path string
+	name string
+	auth clientset.AuthInterface
+
+	mu    sync.Mutex
+	token clientset.Token
+}
+
+// newContextAuthProvider 返回使用配置文件中正在使用的上下文的凭据的 AuthProvider，
+// 没有配置文件、没有使用上下文或者上下文中没有凭据时返回 nil。
+func newContextAuthProvider(auth clientset.AuthInterface) *contextAuthProvider {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil
+	}
+	// 读取配置文件的错误已经在初始化配置时报告过
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil
+	}
+	name, err := CurrentContextName(config)
+	if err != nil || name == "" {
+		return nil
+	}
+	token := config.Contexts[name].SavedToken()
+	if token == nil {
+		return nil
+	}
+	return &contextAuthProvider{path: path, name: name, auth: auth, token: *token}
+}
+
+func (p *contextAuthProvider) Authorize(ctx context.Context, req *http.Request) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expiry := p.token.Expiry()
+	if !expiry.IsZero() && time.Until(expiry) <= tokenRefreshMargin {
+		if err := p.refresh(ctx); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token.Token)
+	return nil
+}
+
+// Reauthorize 在服务器拒绝了当前的 Token 时刷新它，例如 Token 在服务器上提前失效。
+func (p *contextAuthProvider) Reauthorize(ctx context.Context, req *http.Request) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 并发的请求可能已经刷新过了
+	if req.Header.Get("Authorization") != "Bearer "+p.token.Token {
+		return true, nil
+	}
+	if p.token.RefreshToken == "" {
+		return false, nil
+	}
+	if err := p.refresh(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// refresh 用刷新令牌换取新的 Token 并保存到配置文件，调用方必须持有 p.mu。
+func (p *contextAuthProvider) refresh(ctx context.Context) error {
+	if p.token.RefreshToken == "" {
+		return fmt.Errorf("the token of context %q expired at %s, run \"ecsm-cli auth login\" to log in again",
+			p.name, p.token.Expiry().Local().Format(time.RFC3339))
+	}
+
+	token, err := p.auth.Refresh(ctx, p.token.RefreshToken)
+	if err != nil {
+		return fmt.Errorf("failed to refresh the token of context %q, run \"ecsm-cli auth login\" to log in again: %w", p.name, err)
+	}
+	// 服务器没有轮换刷新令牌时继续使用原来的刷新令牌
+	if token.RefreshToken == "" {
+		token.RefreshToken = p.token.RefreshToken
+	}
+	p.token = *token
+	if err := StoreToken(p.path, p.name, token); err != nil {
+		klog.Warningf("Could not save the refreshed token of context %q: %v", p.name, err)
+	}
+	return nil
+}
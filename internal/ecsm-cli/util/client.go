@@ -3,9 +3,12 @@
 package util
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
 	"github.com/spf13/viper"
 )
 
@@ -19,5 +22,63 @@ func NewClientsetFromFlags() (*clientset.Clientset, error) {
 		return nil, fmt.Errorf("host, port, and protocol must be specified")
 	}
 
-	return clientset.NewClientset(protocol, host, port) // http.Client 先用 nil
+	proxy, err := rest.ProxyURL(viper.GetString("proxy-url"))
+	if err != nil {
+		return nil, err
+	}
+	// --api-version=auto 在设置认证之前与服务器协商，使登录等认证请求也使用协商的版本
+	apiVersion := viper.GetString("api-version")
+	negotiate := apiVersion == clientset.AutoAPIVersion
+	if negotiate {
+		apiVersion = ""
+	}
+	cs, err := clientset.NewClientsetForConfig(&rest.Config{
+		Protocol:   protocol,
+		Host:       host,
+		Port:       port,
+		APIVersion: apiVersion,
+		TLS: rest.TLSConfig{
+			CAFile:     viper.GetString("certificate-authority"),
+			CertFile:   viper.GetString("client-certificate"),
+			KeyFile:    viper.GetString("client-key"),
+			ServerName: viper.GetString("tls-server-name"),
+			Insecure:   viper.GetBool("insecure-skip-tls-verify"),
+		},
+		Proxy: proxy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// --debug-http 把所有请求和响应完整地打印到标准错误，不需要调整 klog 的日志级别
+	if viper.GetBool("debug-http") {
+		cs.SetDebugLevel(rest.DebugFullBodies, os.Stderr)
+	}
+
+	if negotiate {
+		if _, err := cs.NegotiateAPIVersion(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	// 同一次命令中反复列出同一类资源 (例如按名称查找) 时复用最近的响应
+	if ttl := viper.GetDuration("cache-ttl"); ttl > 0 {
+		cs.Use(rest.CacheResponses(ttl))
+	}
+
+	// --token 优先于 "auth login" 保存在上下文中的凭据，它们都优先于上下文中的用户名和密码
+	if token := viper.GetString("token"); token != "" {
+		cs.SetTokenSource(rest.StaticTokenSource(token))
+	} else if p := newContextAuthProvider(cs.Auth()); p != nil {
+		cs.SetAuthProvider(p)
+	} else if username, password := viper.GetString("username"), viper.GetString("password"); username != "" && password != "" {
+		switch mode := viper.GetString("auth-mode"); mode {
+		case "", AuthModeSession:
+			cs.SetAuthProvider(clientset.NewLoginAuthProvider(cs.Auth(), username, password))
+		case AuthModeBasic:
+			cs.SetAuthProvider(rest.NewBasicAuthProvider(username, password))
+		default:
+			return nil, fmt.Errorf("unsupported auth-mode %q, must be one of %v", mode, AuthModes)
+		}
+	}
+	return cs, nil
 }
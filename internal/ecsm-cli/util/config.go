@@ -0,0 +1,179 @@
+// file: internal/ecsm-cli/util/config.go
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultConfigFileName 是家目录下默认配置文件的名称
+const DefaultConfigFileName = ".ecsm-cli.yaml"
+
+// CLIConfig 是 ecsm-cli 配置文件的内容。
+// 顶层的连接设置是早期版本的格式，没有选中上下文时仍然生效。
+type CLIConfig struct {
+	Host              string      `json:"host,omitempty"`
+	Port              json.Number `json:"port,omitempty"`
+	Protocol          string      `json:"protocol,omitempty"`
+	RegistryDB        string      `json:"registry-db,omitempty"`
+	EncryptionKeyFile string      `json:"encryption-key-file,omitempty"`
+
+	// CurrentContext 是没有指定 --context 时使用的上下文
+	CurrentContext string `json:"current-context,omitempty"`
+	// Contexts 以名称为键保存每个 ECSM 集群的连接设置
+	Contexts map[string]*Context `json:"contexts,omitempty"`
+}
+
+const (
+	// AuthModeSession 用上下文中的用户名和密码登录，请求携带登录得到的 Token 和会话 Cookie，这是默认的方式
+	AuthModeSession = "session"
+	// AuthModeBasic 在每个请求中以 HTTP Basic 认证发送上下文中的用户名和密码
+	AuthModeBasic = "basic"
+)
+
+// AuthModes 是上下文的 auth-mode 可以使用的值
+var AuthModes = []string{AuthModeSession, AuthModeBasic}
+
+// Context 是一个 ECSM 集群的连接设置，空字段表示使用标志的默认值。
+type Context struct {
+	Host              string      `json:"host,omitempty"`
+	Port              json.Number `json:"port,omitempty"`
+	Protocol          string      `json:"protocol,omitempty"`
+	Username          string      `json:"username,omitempty"`
+	Password          string      `json:"password,omitempty"`
+	AuthMode          string      `json:"auth-mode,omitempty"`
+	Server            string      `json:"server,omitempty"`
+	RegistryDB        string      `json:"registry-db,omitempty"`
+	EncryptionKeyFile string      `json:"encryption-key-file,omitempty"`
+
+	// https 连接的设置，与同名的全局标志对应
+	CertificateAuthority  string `json:"certificate-authority,omitempty"`
+	ClientCertificate     string `json:"client-certificate,omitempty"`
+	ClientKey             string `json:"client-key,omitempty"`
+	TLSServerName         string `json:"tls-server-name,omitempty"`
+	InsecureSkipTLSVerify bool   `json:"insecure-skip-tls-verify,omitempty"`
+
+	// ProxyURL 是访问 ECSM API Server 使用的代理，为空时按照环境变量选择代理
+	ProxyURL string `json:"proxy-url,omitempty"`
+	// APIVersion 是使用的 ECSM API 版本，为 "auto" 时与服务器协商，为空时使用 v1
+	APIVersion string `json:"api-version,omitempty"`
+
+	// Token、RefreshToken 和 TokenExpiry 是 "auth login" 保存的凭据，它们不是标志，不在 Settings 中
+	Token        string     `json:"token,omitempty"`
+	RefreshToken string     `json:"refresh-token,omitempty"`
+	TokenExpiry  *time.Time `json:"token-expiry,omitempty"`
+}
+
+// SavedToken 返回上下文中保存的凭据，没有时返回 nil。
+func (c *Context) SavedToken() *clientset.Token {
+	if c.Token == "" {
+		return nil
+	}
+	token := &clientset.Token{Token: c.Token, RefreshToken: c.RefreshToken}
+	if c.TokenExpiry != nil {
+		token.ExpiresAt = c.TokenExpiry.UnixMilli()
+	}
+	return token
+}
+
+// SetToken 在上下文中保存 token，token 为 nil 时删除保存的凭据。
+func (c *Context) SetToken(token *clientset.Token) {
+	c.Token, c.RefreshToken, c.TokenExpiry = "", "", nil
+	if token == nil {
+		return
+	}
+	c.Token, c.RefreshToken = token.Token, token.RefreshToken
+	if expiry := token.Expiry(); !expiry.IsZero() {
+		expiry = expiry.UTC()
+		c.TokenExpiry = &expiry
+	}
+}
+
+// Settings 以全局标志的名称为键返回上下文中设置了的值。
+func (c *Context) Settings() map[string]string {
+	settings := map[string]string{}
+	for key, value := range map[string]string{
+		"host":                  c.Host,
+		"port":                  c.Port.String(),
+		"protocol":              c.Protocol,
+		"username":              c.Username,
+		"password":              c.Password,
+		"auth-mode":             c.AuthMode,
+		"server":                c.Server,
+		"registry-db":           c.RegistryDB,
+		"encryption-key-file":   c.EncryptionKeyFile,
+		"certificate-authority": c.CertificateAuthority,
+		"client-certificate":    c.ClientCertificate,
+		"client-key":            c.ClientKey,
+		"tls-server-name":       c.TLSServerName,
+		"proxy-url":             c.ProxyURL,
+		"api-version":           c.APIVersion,
+	} {
+		if value != "" {
+			settings[key] = value
+		}
+	}
+	if c.InsecureSkipTLSVerify {
+		settings["insecure-skip-tls-verify"] = "true"
+	}
+	return settings
+}
+
+// ContextNames 返回按名称排序的上下文名称。
+func (c *CLIConfig) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultConfigPath 返回家目录下的默认配置文件路径。
+func DefaultConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, DefaultConfigFileName), nil
+}
+
+// LoadConfig 读取配置文件，文件不存在时返回空的配置。
+// 上下文名称区分大小写，因此不能通过 viper 读取。
+func LoadConfig(path string) (*CLIConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &CLIConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config := &CLIConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// SaveConfig 把配置写入 path。配置中可能有凭据，因此文件只对当前用户可读写。
+func SaveConfig(path string, config *CLIConfig) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile 不会修改已有文件的权限
+	return os.Chmod(path, 0600)
+}
@@ -0,0 +1,46 @@
+// file: internal/ecsm-cli/util/editor.go
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// defaultEditor 是没有设置 ECSM_EDITOR 和 EDITOR 环境变量时使用的编辑器
+const defaultEditor = "vi"
+
+// EditBuffer 把 contents 写入一个扩展名为 ext 的临时文件，在用户的编辑器中打开它，
+// 编辑器退出后返回文件的新内容。编辑器依次取自 ECSM_EDITOR、EDITOR 环境变量，可以带有参数，例如 "code --wait"。
+func EditBuffer(contents []byte, ext string) ([]byte, error) {
+	f, err := os.CreateTemp("", "ecsm-edit-*"+ext)
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	if _, err := f.Write(contents); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := os.Getenv("ECSM_EDITOR")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+	// 通过 shell 运行编辑器以支持带参数的编辑器命令，文件路径作为位置参数传入，不需要转义
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,122 @@
+// file: internal/ecsm-cli/util/explain.go
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/pkg/openapi"
+)
+
+// ExplainOptions 控制 PrintExplanation 的输出
+type ExplainOptions struct {
+	// Kind 和 APIVersion 是被解释的对象的类型
+	Kind       string
+	APIVersion string
+	// Path 是字段的路径，为空时解释对象本身
+	Path []string
+	// Recursive 为 true 时打印所有层级的字段名和类型，不打印字段的说明
+	Recursive bool
+}
+
+// PrintExplanation 打印 field (对象或它的一个字段) 的类型、说明和子字段。
+func PrintExplanation(out io.Writer, doc *openapi.Document, field *openapi.Schema, opts ExplainOptions) {
+	fmt.Fprintf(out, "KIND:     %s\n", opts.Kind)
+	fmt.Fprintf(out, "VERSION:  %s\n\n", opts.APIVersion)
+
+	if len(opts.Path) > 0 {
+		fmt.Fprintf(out, "FIELD:    %s <%s>\n\n", opts.Path[len(opts.Path)-1], schemaTypeName(doc, field))
+	}
+
+	// 字段自己的说明优先，没有时使用类型的说明
+	description := field.Description
+	if description == "" {
+		description = doc.Resolve(field).Description
+	}
+	fmt.Fprintln(out, "DESCRIPTION:")
+	printIndented(out, orNone(description), "    ")
+
+	elem := doc.Elem(field)
+	if elem == nil || len(elem.Properties) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "\nFIELDS:")
+	if opts.Recursive {
+		printFieldTree(out, doc, elem, "   ", map[*openapi.Schema]bool{elem: true})
+		return
+	}
+	for i, name := range sortedFieldNames(elem) {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		prop := elem.Properties[name]
+		fmt.Fprintf(out, "   %s\t<%s>\n", name, schemaTypeName(doc, prop))
+		desc := prop.Description
+		if resolved := doc.Resolve(prop); desc == "" && resolved != nil {
+			desc = resolved.Description
+		}
+		if desc != "" {
+			printIndented(out, desc, "     ")
+		}
+	}
+}
+
+// printFieldTree 递归地打印 s 的字段名和类型，visiting 是正在展开的类型，用来避免递归类型无限展开。
+func printFieldTree(out io.Writer, doc *openapi.Document, s *openapi.Schema, indent string, visiting map[*openapi.Schema]bool) {
+	for _, name := range sortedFieldNames(s) {
+		prop := s.Properties[name]
+		fmt.Fprintf(out, "%s%s\t<%s>\n", indent, name, schemaTypeName(doc, prop))
+		elem := doc.Elem(prop)
+		if elem == nil || len(elem.Properties) == 0 || visiting[elem] {
+			continue
+		}
+		visiting[elem] = true
+		printFieldTree(out, doc, elem, indent+"   ", visiting)
+		delete(visiting, elem)
+	}
+}
+
+// schemaTypeName 返回给人阅读的类型名，例如 string、[]EnvVar 和 map[string]string。
+func schemaTypeName(doc *openapi.Document, s *openapi.Schema) string {
+	if s.Ref != "" {
+		name := s.Ref[strings.LastIndex(s.Ref, ".")+1:]
+		if resolved := doc.Resolve(s); resolved != nil && len(resolved.Properties) == 0 && resolved.Type != "object" {
+			// 引用的不是结构体，例如自定义格式的类型
+			return schemaTypeName(doc, resolved)
+		}
+		return name
+	}
+	switch {
+	case s.IntOrString:
+		return "IntOrString"
+	case s.PreserveUnknownFields:
+		return "Object"
+	case s.Items != nil:
+		return "[]" + schemaTypeName(doc, s.Items)
+	case s.AdditionalProperties != nil:
+		return "map[string]" + schemaTypeName(doc, s.AdditionalProperties)
+	case s.Type == "":
+		return "Object"
+	}
+	return s.Type
+}
+
+// sortedFieldNames 返回 s 的字段名，按字母排序。
+func sortedFieldNames(s *openapi.Schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printIndented 在 text 的每一行前加上 indent 后打印。
+func printIndented(out io.Writer, text, indent string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(out, "%s%s\n", indent, strings.TrimSpace(line))
+	}
+}
@@ -0,0 +1,161 @@
+// file: internal/ecsm-cli/util/jsonpath_printer.go
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// --output 标志中带参数的格式，例如 "jsonpath={.items[*].name}"
+const (
+	OutputJSONPath      = "jsonpath"
+	OutputCustomColumns = "custom-columns"
+)
+
+// JSONPathPrinter 按 JSONPath 模板打印对象。对象列表被包装为 {"items": [...]}，
+// 因此 "{.items[*].name}" 打印所有对象的名称。
+type JSONPathPrinter struct {
+	jp *jsonpath.JSONPath
+}
+
+// NewJSONPathPrinter 解析 template 并返回 JSONPathPrinter。
+func NewJSONPathPrinter(template string) (*JSONPathPrinter, error) {
+	jp := jsonpath.New("output")
+	if err := jp.Parse(relaxedJSONPath(template)); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath template %q: %w", template, err)
+	}
+	return &JSONPathPrinter{jp: jp}, nil
+}
+
+func (p *JSONPathPrinter) PrintObj(obj interface{}, out io.Writer) error {
+	data, err := toJSONData(obj)
+	if err != nil {
+		return err
+	}
+	if reflect.ValueOf(obj).Kind() == reflect.Slice {
+		data = map[string]interface{}{"items": data}
+	}
+	if err := p.jp.Execute(out, data); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out)
+	return err
+}
+
+// column 是 custom-columns 格式中的一列
+type column struct {
+	header string
+	jp     *jsonpath.JSONPath
+}
+
+// CustomColumnsPrinter 为每个对象打印一行，每一列的值由该列的 JSONPath 表达式从对象中取出。
+type CustomColumnsPrinter struct {
+	columns []column
+	// NoHeaders 为 true 时不打印表头
+	NoHeaders bool
+}
+
+// NewCustomColumnsPrinter 解析 "NAME:.name,STATUS:.status" 形式的列定义。
+func NewCustomColumnsPrinter(spec string) (*CustomColumnsPrinter, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("custom-columns format requires at least one column, e.g. custom-columns=NAME:.name")
+	}
+	var columns []column
+	for _, part := range strings.Split(spec, ",") {
+		header, expr, ok := strings.Cut(part, ":")
+		if !ok || header == "" || expr == "" {
+			return nil, fmt.Errorf("invalid custom column %q, expected HEADER:JSONPATH", part)
+		}
+		jp := jsonpath.New(header).AllowMissingKeys(true)
+		if err := jp.Parse(relaxedJSONPath(expr)); err != nil {
+			return nil, fmt.Errorf("invalid jsonpath %q in custom column %s: %w", expr, header, err)
+		}
+		columns = append(columns, column{header: header, jp: jp})
+	}
+	return &CustomColumnsPrinter{columns: columns}, nil
+}
+
+func (p *CustomColumnsPrinter) PrintObj(obj interface{}, out io.Writer) error {
+	data, err := toJSONData(obj)
+	if err != nil {
+		return err
+	}
+	rows := []interface{}{data}
+	if items, ok := data.([]interface{}); ok {
+		rows = items
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if !p.NoHeaders {
+		headers := make([]string, len(p.columns))
+		for i, c := range p.columns {
+			headers[i] = c.header
+		}
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+	}
+
+	for _, row := range rows {
+		values := make([]string, len(p.columns))
+		for i, c := range p.columns {
+			results, err := c.jp.FindResults(row)
+			if err != nil {
+				return err
+			}
+			values[i] = formatResults(results)
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	return w.Flush()
+}
+
+// formatResults 用逗号连接 JSONPath 匹配到的值，没有匹配时返回 "<none>"。
+func formatResults(results [][]reflect.Value) string {
+	var values []string
+	for _, result := range results {
+		for _, v := range result {
+			if v.Kind() == reflect.Interface && v.IsNil() {
+				continue
+			}
+			values = append(values, fmt.Sprintf("%v", v.Interface()))
+		}
+	}
+	if len(values) == 0 {
+		return "<none>"
+	}
+	return strings.Join(values, ",")
+}
+
+// relaxedJSONPath 允许省略模板外层的花括号，".name" 等价于 "{.name}"。
+func relaxedJSONPath(template string) string {
+	if strings.Contains(template, "{") {
+		return template
+	}
+	if !strings.HasPrefix(template, ".") {
+		template = "." + template
+	}
+	return "{" + template + "}"
+}
+
+// toJSONData 把对象转换为 JSON 对应的通用结构，使 JSONPath 使用 JSON 字段名。
+// 数字保留为 json.Number，避免大整数被打印为科学计数法。
+func toJSONData(obj interface{}) (interface{}, error) {
+	raw, err := json.Marshal(emptySliceIfNil(obj))
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,130 @@
+// file: internal/ecsm-cli/util/lookup.go
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// waitInterval 是等待平台操作完成时的轮询间隔
+const waitInterval = time.Second
+
+// ResolveNodeID 把节点的名称或 ID 解析为 ID。identifier 先作为 ID 匹配，再作为名称匹配，
+// 多个节点同名时返回错误并列出它们的 ID，由用户选择其中一个。
+func ResolveNodeID(ctx context.Context, cs clientset.Interface, identifier string) (string, error) {
+	allNodes, err := cs.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes to find identifier: %w", err)
+	}
+
+	var ids []string
+	for _, node := range allNodes {
+		if node.ID == identifier {
+			return identifier, nil
+		}
+		if node.Name == identifier {
+			ids = append(ids, node.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("node '%s' not found", identifier)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("multiple nodes found with name '%s', please use one of the following IDs: %v", identifier, ids)
+	}
+}
+
+// ResolveServiceID 把服务的名称或 ID 解析为 ID，规则与 ResolveNodeID 相同。
+func ResolveServiceID(ctx context.Context, cs clientset.Interface, identifier string) (string, error) {
+	allServices, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list services: %w", err)
+	}
+
+	var ids []string
+	for _, svc := range allServices {
+		if svc.ID == identifier {
+			return identifier, nil
+		}
+		if svc.Name == identifier {
+			ids = append(ids, svc.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("service '%s' not found", identifier)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("multiple services found with name '%s', please use one of the following IDs: %v", identifier, ids)
+	}
+}
+
+// WaitForTransaction 等待一个平台事务结束，事务失败或 timeout 内没有结束时返回错误。
+func WaitForTransaction(ctx context.Context, cs clientset.Interface, transactionID string, timeout time.Duration) error {
+	var tx *clientset.Transaction
+	err := wait.PollUntilContextTimeout(ctx, waitInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		tx, err = cs.Transactions().Get(ctx, transactionID)
+		if err != nil {
+			return false, err
+		}
+		return tx.Status != clientset.TransactionStatusRunning, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed waiting for transaction %s: %w", transactionID, err)
+	}
+	if tx.Status != clientset.TransactionStatusSuccess {
+		return fmt.Errorf("transaction %s finished with status %s: %v", transactionID, tx.Status, tx.Data)
+	}
+	return nil
+}
+
+// WaitForServicesGone 等待 ids 中的平台服务全部被删除。
+func WaitForServicesGone(ctx context.Context, cs clientset.Interface, ids []string, timeout time.Duration) error {
+	err := wait.PollUntilContextTimeout(ctx, waitInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		services, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, svc := range services {
+			for _, id := range ids {
+				if svc.ID == id {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed waiting for services %v to be deleted: %w", ids, err)
+	}
+	return nil
+}
+
+// WaitForNodeGone 等待一个节点从平台的节点列表中消失。
+func WaitForNodeGone(ctx context.Context, cs clientset.Interface, nodeID string, timeout time.Duration) error {
+	err := wait.PollUntilContextTimeout(ctx, waitInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		nodes, err := cs.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, node := range nodes {
+			if node.ID == nodeID {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed waiting for node %s to be deleted: %w", nodeID, err)
+	}
+	return nil
+}
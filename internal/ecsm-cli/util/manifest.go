@@ -0,0 +1,201 @@
+// file: internal/ecsm-cli/util/manifest.go
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/install"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	sigsjson "sigs.k8s.io/json"
+)
+
+// manifestScheme 包含 ecsm.sh 组的所有版本，用于把旧版本的清单转换为 v1
+var manifestScheme = newManifestScheme()
+
+func newManifestScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	install.Install(s)
+	return s
+}
+
+// Manifest 是清单文件中的一个文档
+type Manifest struct {
+	// Source 是文档所在的文件，从标准输入读取时为 "-"
+	Source string
+	// Raw 是文档的 JSON 形式
+	Raw []byte
+	metav1.TypeMeta
+}
+
+// ReadManifests 读取 filename 中的所有 YAML 或 JSON 文档。filename 为 "-" 时读取标准输入，
+// 是目录时按文件名的顺序读取其中所有 .yaml、.yml 和 .json 文件，不包括子目录。
+func ReadManifests(filename string) ([]Manifest, error) {
+	if filename == "-" {
+		return decodeManifests(os.Stdin, "-")
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{filename}
+	if info.IsDir() {
+		entries, err := os.ReadDir(filename)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if entry.Type().IsRegular() {
+					files = append(files, filepath.Join(filename, entry.Name()))
+				}
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var manifests []Manifest
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		m, err := decodeManifests(f, file)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m...)
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no objects found in %s", filename)
+	}
+	return manifests, nil
+}
+
+// ParseManifests 解析 data 中的所有 YAML 或 JSON 文档，source 用于错误信息。
+func ParseManifests(data []byte, source string) ([]Manifest, error) {
+	return decodeManifests(bytes.NewReader(data), source)
+}
+
+// decodeManifests 把一个流拆分为多个文档，跳过空文档。
+func decodeManifests(in io.Reader, source string) ([]Manifest, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(in, 4096)
+	var manifests []Manifest
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return manifests, nil
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+		}
+		if len(bytes.TrimSpace(raw)) == 0 || string(raw) == "null" {
+			continue
+		}
+		m := Manifest{Source: source, Raw: raw}
+		if err := json.Unmarshal(raw, &m.TypeMeta); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+		}
+		manifests = append(manifests, m)
+	}
+}
+
+// DecodeService 把清单解码为 v1 的 ECSMService，ecsm.sh 组其他版本的清单会被转换为 v1。
+func (m *Manifest) DecodeService() (*ecsmv1.ECSMService, error) {
+	gv, err := schema.ParseGroupVersion(m.APIVersion)
+	if err != nil || gv.Group != ecsmv1.GroupName || m.Kind != "ECSMService" {
+		return nil, fmt.Errorf("%s: unsupported object %s %s, only ECSMServices are supported", m.Source, m.APIVersion, m.Kind)
+	}
+
+	service := &ecsmv1.ECSMService{}
+	if gv == ecsmv1.SchemeGroupVersion {
+		if err := json.Unmarshal(m.Raw, service); err != nil {
+			return nil, fmt.Errorf("%s: invalid ECSMService: %w", m.Source, err)
+		}
+		return service, nil
+	}
+
+	in, err := manifestScheme.New(gv.WithKind(m.Kind))
+	if err != nil {
+		return nil, fmt.Errorf("%s: unsupported object %s %s: %w", m.Source, m.APIVersion, m.Kind, err)
+	}
+	if err := json.Unmarshal(m.Raw, in); err != nil {
+		return nil, fmt.Errorf("%s: invalid ECSMService: %w", m.Source, err)
+	}
+	if err := manifestScheme.Convert(in, service, nil); err != nil {
+		return nil, fmt.Errorf("%s: failed to convert %s ECSMService: %w", m.Source, m.APIVersion, err)
+	}
+	return service, nil
+}
+
+// DecodeObject 把清单解码为 ecsm.sh/v1 中同一 Kind 的对象，ecsm.sh 组其他版本的清单会被转换为 v1。
+// 清单中未知的字段和重复的字段以 strictErrs 返回，它们不影响解码的结果。
+func (m *Manifest) DecodeObject() (obj runtime.Object, strictErrs []error, err error) {
+	gv, err := schema.ParseGroupVersion(m.APIVersion)
+	if err != nil || gv.Group != ecsmv1.GroupName || m.Kind == "" {
+		return nil, nil, fmt.Errorf("%s: unsupported object %s %s, only ecsm.sh objects are supported", m.Source, m.APIVersion, m.Kind)
+	}
+	in, err := manifestScheme.New(gv.WithKind(m.Kind))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: unsupported object %s %s: %w", m.Source, m.APIVersion, m.Kind, err)
+	}
+	strictErrs, err = sigsjson.UnmarshalStrict(m.Raw, in, sigsjson.DisallowUnknownFields, sigsjson.DisallowDuplicateFields)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid %s: %w", m.Source, m.Kind, err)
+	}
+	if gv == ecsmv1.SchemeGroupVersion {
+		return in, strictErrs, nil
+	}
+
+	out, err := manifestScheme.New(ecsmv1.SchemeGroupVersion.WithKind(m.Kind))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s %s has no %s version: %w", m.Source, m.APIVersion, m.Kind, ecsmv1.SchemeGroupVersion, err)
+	}
+	if err := manifestScheme.Convert(in, out, nil); err != nil {
+		return nil, nil, fmt.Errorf("%s: failed to convert %s %s: %w", m.Source, m.APIVersion, m.Kind, err)
+	}
+	return out, strictErrs, nil
+}
+
+// ManifestVersions 返回 ecsm.sh 组的所有版本，首选版本 v1 在前。
+func ManifestVersions() []schema.GroupVersion {
+	return manifestScheme.PrioritizedVersionsForGroup(ecsmv1.GroupName)
+}
+
+// ConvertToVersion 把 DecodeObject 返回的对象转换为 ecsm.sh 组的 gv 版本，返回的对象设置了 apiVersion 和 kind。
+func ConvertToVersion(obj runtime.Object, gv schema.GroupVersion) (runtime.Object, error) {
+	gvks, _, err := manifestScheme.ObjectKinds(obj)
+	if err != nil {
+		return nil, err
+	}
+	kind := gvks[0].Kind
+	var out runtime.Object
+	if gvks[0].GroupVersion() == gv {
+		out = obj.DeepCopyObject()
+	} else {
+		if out, err = manifestScheme.New(gv.WithKind(kind)); err != nil {
+			return nil, fmt.Errorf("%s has no %s version", kind, gv)
+		}
+		if err := manifestScheme.Convert(obj, out, nil); err != nil {
+			return nil, fmt.Errorf("failed to convert %s to %s: %w", kind, gv, err)
+		}
+	}
+	out.GetObjectKind().SetGroupVersionKind(gv.WithKind(kind))
+	return out, nil
+}
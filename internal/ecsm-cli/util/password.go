@@ -0,0 +1,33 @@
+// file: internal/ecsm-cli/util/password.go
+
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ReadPassword 读取一个密码。标准输入是终端时显示 prompt 并以不回显的方式读取，
+// 否则 (例如 --password-stdin 时通过管道传入) 读取标准输入的第一行。
+func ReadPassword(prompt string) (string, error) {
+	if IsTerminal(os.Stdin) {
+		fmt.Fprint(os.Stderr, prompt)
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return string(password), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read password from standard input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -5,22 +5,31 @@ package util
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
 	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/snapshot"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 )
 
-// PrintNodesTable 将节点列表以表格形式打印到指定的 writer。
-func PrintNodesTable(out io.Writer, nodes []clientset.NodeInfo) {
+// PrintNodesTable 将节点列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印 TLS 和包括非 ECSM 容器在内的所有容器。
+func PrintNodesTable(out io.Writer, nodes []clientset.NodeInfo, opts PrintOptions) {
 	// 初始化 tabwriter
 	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
 	defer w.Flush()
 
 	// 打印表头
-	fmt.Fprintln(w, "NAME\tSTATUS\tADDRESS\tTYPE\tARCH\tCONTAINERS\tCREATED\tUPTIME\tID")
+	header := "NAME\tSTATUS\tADDRESS\tTYPE\tARCH\tCONTAINERS\tCREATED\tUPTIME\tID"
+	if opts.Wide {
+		header += "\tTLS\tALL CONTAINERS"
+	}
+	printHeader(w, header, opts)
 
 	// 打印每一行
 	for _, node := range nodes {
@@ -28,7 +37,7 @@ func PrintNodesTable(out io.Writer, nodes []clientset.NodeInfo) {
 		uptimeDuration := time.Duration(node.UpTime) * time.Second
 		uptimeStr := formatUptime(uptimeDuration)
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
 			node.Name,
 			node.Status,
 			node.Address,
@@ -37,8 +46,12 @@ func PrintNodesTable(out io.Writer, nodes []clientset.NodeInfo) {
 			containerInfo,
 			node.CreatedTime,
 			uptimeStr,
-			node.ID,
+			opts.id(node.ID),
 		)
+		if opts.Wide {
+			fmt.Fprintf(w, "\t%t\t%d/%d", node.TLS, node.ContainerRunning, node.ContainerTotal)
+		}
+		fmt.Fprintln(w)
 	}
 }
 
@@ -50,13 +63,17 @@ func formatUptime(d time.Duration) string {
 	return fmt.Sprintf("%dd%dh%dm", days, hours, minutes)
 }
 
-// PrintImagesTable 将镜像列表以表格形式打印到指定的 writer。
-func PrintImagesTable(out io.Writer, images []clientset.ImageListItem) {
+// PrintImagesTable 将镜像列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印 ID、是否已拉取和作者。
+func PrintImagesTable(out io.Writer, images []clientset.ImageListItem, opts PrintOptions) {
 	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
 	defer w.Flush()
 
 	// 打印表头
-	fmt.Fprintln(w, "NAME\tTAG\tOS\tARCH\tSIZE(MB)\tCREATED")
+	header := "NAME\tTAG\tOS\tARCH\tSIZE(MB)\tCREATED"
+	if opts.Wide {
+		header += "\tID\tPULLED\tAUTHOR"
+	}
+	printHeader(w, header, opts)
 
 	for _, img := range images {
 		// 解析并格式化创建时间
@@ -69,7 +86,7 @@ func PrintImagesTable(out io.Writer, images []clientset.ImageListItem) {
 			createdStr = "N/A" // 如果时间格式解析失败，则优雅地处理
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.2f\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.2f\t%s",
 			img.Name,
 			img.Tag,
 			img.OS,
@@ -77,6 +94,14 @@ func PrintImagesTable(out io.Writer, images []clientset.ImageListItem) {
 			img.Size,
 			createdStr,
 		)
+		if opts.Wide {
+			author := "<none>"
+			if img.Author != nil {
+				author = *img.Author
+			}
+			fmt.Fprintf(w, "\t%s\t%t\t%s", opts.id(img.ID), img.Pulled, author)
+		}
+		fmt.Fprintln(w)
 	}
 }
 
@@ -279,13 +304,20 @@ func PrintNodeDetails(out io.Writer, view *clientset.NodeView, metrics *clientse
 	}
 }
 
-// PrintServicesTable 将服务列表以表格形式打印到指定的 writer。
-func PrintServicesTable(out io.Writer, services []clientset.ProvisionListRow) {
+// PrintServicesTable 将服务列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印节点、路径标签和创建时间。
+func PrintServicesTable(out io.Writer, services []clientset.ProvisionListRow, opts PrintOptions) {
 	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 	defer w.Flush()
 
 	// 打印表头
-	fmt.Fprintln(w, "NAME\tDEPLOY_STATUS\tPOLICY\tONLINE\tDESIRED\tIMAGE\tID")
+	header := "NAME\tDEPLOY_STATUS\tPOLICY\tONLINE\tDESIRED\tIMAGE\tID"
+	if opts.Wide {
+		header += "\tNODES\tPATH_LABEL\tCREATED"
+	}
+	if opts.ShowLabels {
+		header += "\tLABELS"
+	}
+	printHeader(w, header, opts)
 
 	for _, svc := range services {
 		// 组合一个易于阅读的镜像名
@@ -295,15 +327,26 @@ func PrintServicesTable(out io.Writer, services []clientset.ProvisionListRow) {
 			imageName = fmt.Sprintf("%s:%s", img.Name, img.Tag)
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s",
 			svc.Name,
 			svc.Status,
 			svc.Policy,
 			svc.InstanceOnline,
 			svc.Factor, // Factor 代表期望的副本数
 			imageName,
-			svc.ID,
+			opts.id(svc.ID),
 		)
+		if opts.Wide {
+			var nodes []string
+			for _, node := range svc.NodeList {
+				nodes = append(nodes, node.NodeName)
+			}
+			fmt.Fprintf(w, "\t%s\t%s\t%s", orNone(strings.Join(nodes, ",")), orNone(svc.PathLabel), svc.CreatedTime)
+		}
+		if opts.ShowLabels {
+			fmt.Fprintf(w, "\t%s", orNone(strings.Join(svc.Labels, ",")))
+		}
+		fmt.Fprintln(w)
 	}
 }
 
@@ -358,19 +401,23 @@ func PrintServiceDetails(out io.Writer, details *clientset.ServiceGet, container
 	}
 }
 
-// PrintContainersTable 将容器列表以表格形式打印到指定的 writer。
-func PrintContainersTable(out io.Writer, containers []clientset.ContainerInfo) {
+// PrintContainersTable 将容器列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印资源使用、节点地址和 ID。
+func PrintContainersTable(out io.Writer, containers []clientset.ContainerInfo, opts PrintOptions) {
 	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 	defer w.Flush()
 
 	// 打印表头
-	fmt.Fprintln(w, "NAME\tSTATUS\tRESTARTS\tIMAGE\tSERVICE\tNODE")
+	header := "NAME\tSTATUS\tRESTARTS\tIMAGE\tSERVICE\tNODE"
+	if opts.Wide {
+		header += "\tCPU(%)\tMEMORY(MiB)\tADDRESS\tID"
+	}
+	printHeader(w, header, opts)
 
 	for _, c := range containers {
 		// 组合一个易于阅读的镜像名
 		imageRef := fmt.Sprintf("%s:%s", c.ImageName, c.ImageVersion)
 
-		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s",
 			c.Name,
 			c.Status,
 			c.RestartCount,
@@ -378,6 +425,10 @@ func PrintContainersTable(out io.Writer, containers []clientset.ContainerInfo) {
 			c.ServiceName,
 			c.NodeName,
 		)
+		if opts.Wide {
+			fmt.Fprintf(w, "\t%.2f\t%.2f\t%s\t%s", c.CPUUsage.Total, float64(c.MemoryUsage)/1024/1024, c.Address, opts.id(c.ID))
+		}
+		fmt.Fprintln(w)
 	}
 }
 
@@ -433,3 +484,288 @@ func PrintContainerDetails(out io.Writer, details *clientset.ContainerInfo, hist
 		fmt.Fprintf(out, "No action history found.\n")
 	}
 }
+
+// PrintEventsTable 将事件列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印首次发生时间和来源。
+func PrintEventsTable(out io.Writer, events []ecsmv1.Event, opts PrintOptions) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := "NAMESPACE\tLAST SEEN\tTYPE\tREASON\tOBJECT\tCOUNT\tMESSAGE"
+	if opts.Wide {
+		header += "\tFIRST SEEN\tSOURCE"
+	}
+	printHeader(w, header, opts)
+	for _, e := range events {
+		object := fmt.Sprintf("%s/%s", strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s",
+			e.Namespace, formatEventAge(e.LastTimestamp.Time), e.Type, e.Reason, object, e.Count, e.Message)
+		if opts.Wide {
+			fmt.Fprintf(w, "\t%s\t%s", formatEventAge(e.FirstTimestamp.Time), orNone(e.Source.Component))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// PrintJobsTable 将 ECSMJob 列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印镜像和节点池。
+func PrintJobsTable(out io.Writer, jobs []ecsmv1.ECSMJob, opts PrintOptions) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := "NAMESPACE\tNAME\tSTATUS\tCOMPLETIONS\tACTIVE\tFAILED\tAGE"
+	if opts.Wide {
+		header += "\tIMAGE\tNODE POOL"
+	}
+	printHeader(w, header, opts)
+	for _, job := range jobs {
+		completions := int32(1)
+		if job.Spec.Completions != nil {
+			completions = *job.Spec.Completions
+		}
+		status := "Running"
+		for _, cond := range job.Status.Conditions {
+			if cond.Status == metav1.ConditionTrue && (cond.Type == ecsmv1.JobComplete || cond.Type == ecsmv1.JobFailed) {
+				status = cond.Type
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%d\t%d\t%s",
+			job.Namespace, job.Name, status, job.Status.Succeeded, completions,
+			job.Status.Active, job.Status.Failed, formatEventAge(job.CreationTimestamp.Time))
+		if opts.Wide {
+			fmt.Fprintf(w, "\t%s\t%s", job.Spec.Template.Image, orNone(strings.Join(job.Spec.NodePool, ",")))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// PrintCronJobsTable 将 ECSMCronJob 列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印镜像和并发策略。
+func PrintCronJobsTable(out io.Writer, cronJobs []ecsmv1.ECSMCronJob, opts PrintOptions) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := "NAMESPACE\tNAME\tSCHEDULE\tSUSPEND\tACTIVE\tLAST SCHEDULE\tAGE"
+	if opts.Wide {
+		header += "\tIMAGE\tCONCURRENCY"
+	}
+	printHeader(w, header, opts)
+	for _, cj := range cronJobs {
+		suspend := cj.Spec.Suspend != nil && *cj.Spec.Suspend
+		lastSchedule := "<none>"
+		if cj.Status.LastScheduleTime != nil {
+			lastSchedule = formatEventAge(cj.Status.LastScheduleTime.Time)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%d\t%s\t%s",
+			cj.Namespace, cj.Name, cj.Spec.Schedule, suspend, len(cj.Status.Active),
+			lastSchedule, formatEventAge(cj.CreationTimestamp.Time))
+		if opts.Wide {
+			fmt.Fprintf(w, "\t%s\t%s", cj.Spec.JobTemplate.Spec.Template.Image, orNone(string(cj.Spec.ConcurrencyPolicy)))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// PrintNodeSetsTable 将 ECSMNodeSet 列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印镜像。
+func PrintNodeSetsTable(out io.Writer, nodeSets []ecsmv1.ECSMNodeSet, opts PrintOptions) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := "NAMESPACE\tNAME\tDESIRED\tCURRENT\tREADY\tNODE SELECTOR\tAGE"
+	if opts.Wide {
+		header += "\tIMAGE"
+	}
+	printHeader(w, header, opts)
+	for _, ns := range nodeSets {
+		var selector []string
+		for k, v := range ns.Spec.NodeSelector {
+			selector = append(selector, k+"="+v)
+		}
+		sort.Strings(selector)
+		selectorStr := "<none>"
+		if len(selector) > 0 {
+			selectorStr = strings.Join(selector, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s",
+			ns.Namespace, ns.Name, ns.Status.DesiredNumberScheduled, ns.Status.CurrentNumberScheduled,
+			ns.Status.NumberReady, selectorStr, formatEventAge(ns.CreationTimestamp.Time))
+		if opts.Wide {
+			fmt.Fprintf(w, "\t%s", ns.Spec.Template.Image)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// PrintConfigsTable 将 ECSMConfig 列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印键的名称。
+func PrintConfigsTable(out io.Writer, configs []ecsmv1.ECSMConfig, opts PrintOptions) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := "NAMESPACE\tNAME\tDATA\tAGE"
+	if opts.Wide {
+		header += "\tKEYS"
+	}
+	printHeader(w, header, opts)
+	for _, cfg := range configs {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s",
+			cfg.Namespace, cfg.Name, len(cfg.Data), formatEventAge(cfg.CreationTimestamp.Time))
+		if opts.Wide {
+			keys := make([]string, 0, len(cfg.Data))
+			for k := range cfg.Data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			fmt.Fprintf(w, "\t%s", orNone(strings.Join(keys, ",")))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// PrintSecretsTable 将 ECSMSecret 列表以表格形式打印到指定的 writer，只打印键的名称，不打印值。
+// 默认的表格已经包含了所有的列，wide 不会增加更多的列。
+func PrintSecretsTable(out io.Writer, secrets []ecsmv1.ECSMSecret, opts PrintOptions) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE\tNAME\tDATA\tKEYS\tAGE", opts)
+	for _, secret := range secrets {
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+			secret.Namespace, secret.Name, len(secret.Data), strings.Join(keys, ","), formatEventAge(secret.CreationTimestamp.Time))
+	}
+}
+
+// PrintSnapshotsTable 将 Registry 快照列表以表格形式打印到指定的 writer，opts.Wide 为 true 时打印完整的 sha256。
+func PrintSnapshotsTable(out io.Writer, snapshots []snapshot.Info, opts PrintOptions) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAME\tCREATED\tAGE\tSHA256", opts)
+	for _, s := range snapshots {
+		checksum := s.Checksum
+		if !opts.Wide {
+			checksum = checksum[:12]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			s.Name, s.Time.Format(time.RFC3339), formatEventAge(s.Time), checksum)
+	}
+}
+
+// PrintTransactionsTable 将事务列表以表格形式打印到指定的 writer，opts.Wide 为 true 时额外打印提交时间和失败原因。
+func PrintTransactionsTable(out io.Writer, transactions []clientset.Transaction, opts PrintOptions) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := "ID\tTYPE\tSTATUS\tAGE"
+	if opts.Wide {
+		header += "\tSUBMITTED\tMESSAGE"
+	}
+	printHeader(w, header, opts)
+	for _, tx := range transactions {
+		submitted := transactionTime(tx)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s", tx.ID, orNone(tx.Type), tx.Status, formatEventAge(submitted))
+		if opts.Wide {
+			fmt.Fprintf(w, "\t%s\t%s", formatTime(submitted), orNone(tx.Message))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// PrintTransactionDetails 打印事务的详细信息，事务携带的数据以 YAML 格式打印。
+func PrintTransactionDetails(out io.Writer, tx *clientset.Transaction) {
+	submitted := transactionTime(*tx)
+	fmt.Fprintf(out, "ID:             %s\n", tx.ID)
+	fmt.Fprintf(out, "Type:           %s\n", orNone(tx.Type))
+	fmt.Fprintf(out, "Status:         %s\n", tx.Status)
+	if tx.Message != "" {
+		fmt.Fprintf(out, "Message:        %s\n", tx.Message)
+	}
+	if submitted.IsZero() {
+		fmt.Fprintf(out, "Submitted:      <unknown>\n")
+	} else {
+		fmt.Fprintf(out, "Submitted:      %s (%s ago)\n", formatTime(submitted), formatEventAge(submitted))
+	}
+
+	if tx.Data == nil {
+		fmt.Fprintf(out, "Data:           <none>\n")
+		return
+	}
+	data, err := yaml.Marshal(tx.Data)
+	if err != nil {
+		fmt.Fprintf(out, "Data:           <invalid: %v>\n", err)
+		return
+	}
+	fmt.Fprintf(out, "Data:\n")
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		fmt.Fprintf(out, "  %s\n", line)
+	}
+}
+
+// transactionTime 返回事务的提交时间，平台没有返回时为零值。
+func transactionTime(tx clientset.Transaction) time.Time {
+	if tx.Timestamp == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(tx.Timestamp)
+}
+
+// formatTime 以 RFC 3339 格式打印本地时间，零值打印为 "<unknown>"。
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// PrintEventsSection 以 describe 风格打印与某个对象相关的事件。
+func PrintEventsSection(out io.Writer, events []ecsmv1.Event) {
+	if len(events) == 0 {
+		fmt.Fprintf(out, "Events:         <none>\n")
+		return
+	}
+	fmt.Fprintf(out, "Events:\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tREASON\tAGE\tFROM\tMESSAGE")
+	for _, e := range events {
+		age := formatEventAge(e.LastTimestamp.Time)
+		if e.Count > 1 {
+			age = fmt.Sprintf("%s (x%d over %s)", age, e.Count, formatEventAge(e.FirstTimestamp.Time))
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", e.Type, e.Reason, age, e.Source.Component, e.Message)
+	}
+	w.Flush()
+}
+
+// formatEventAge 把一个时间点格式化为距今的简短时长，例如 "5m" 或 "2h"。
+func formatEventAge(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// PrintServerInfo 打印 ECSM 服务器的版本、API 版本和能力。
+func PrintServerInfo(out io.Writer, info *clientset.ServerInfo) {
+	fmt.Fprintf(out, "Server Version: %s\n", info.Version)
+	fmt.Fprintf(out, "Server API Versions: %s\n", orNone(strings.Join(info.APIVersions, ", ")))
+	fmt.Fprintf(out, "Server Capabilities: %s\n", orNone(strings.Join(info.Capabilities, ", ")))
+}
+
+// orNone 在 s 为空时返回 "<none>"。
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+// file: internal/ecsm-cli/util/progress.go
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// progressInterval 是两次刷新进度之间的最短间隔，避免在大文件上传时刷屏
+const progressInterval = 200 * time.Millisecond
+
+// ProgressReader 包装一个 io.Reader，在读取时把已读取的字节数报告到 out。
+// 进度在同一行上用 "\r" 刷新，因此 out 应该是终端；out 为 nil 时不报告进度。
+type ProgressReader struct {
+	r     io.Reader
+	out   io.Writer
+	label string
+	total int64
+	read  int64
+	last  time.Time
+}
+
+// NewProgressReader 创建一个 ProgressReader。total 是预期的总字节数，未知时为 0。
+func NewProgressReader(r io.Reader, total int64, label string, out io.Writer) *ProgressReader {
+	return &ProgressReader{r: r, out: out, label: label, total: total}
+}
+
+// Read 实现了 io.Reader。
+func (p *ProgressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.read += int64(n)
+	if p.out != nil && time.Since(p.last) >= progressInterval {
+		p.last = time.Now()
+		p.print()
+	}
+	return n, err
+}
+
+// Done 打印最终的进度并换行。
+func (p *ProgressReader) Done() {
+	if p.out == nil {
+		return
+	}
+	p.print()
+	fmt.Fprintln(p.out)
+}
+
+func (p *ProgressReader) print() {
+	if p.total > 0 {
+		fmt.Fprintf(p.out, "\r%s: %s / %s (%d%%)", p.label, formatBytes(p.read), formatBytes(p.total), p.read*100/p.total)
+		return
+	}
+	fmt.Fprintf(p.out, "\r%s: %s", p.label, formatBytes(p.read))
+}
+
+// IsTerminal 判断 f 是否是终端 (字符设备)。
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// formatBytes 以 B、KiB、MiB 或 GiB 为单位格式化字节数。
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KiB"
+	for _, s := range []string{"MiB", "GiB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, s
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// spinnerFrames 是 Spinner 依次显示的字符
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// Spinner 在等待一个耗时操作时在同一行上显示一个旋转的指示符和说明。
+// out 为 nil 时不显示任何内容。
+type Spinner struct {
+	out   io.Writer
+	label string
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// StartSpinner 开始显示 Spinner，调用方必须调用 Stop 结束它。
+func StartSpinner(label string, out io.Writer) *Spinner {
+	s := &Spinner{out: out, label: label, stop: make(chan struct{}), done: make(chan struct{})}
+	if out == nil {
+		close(s.done)
+		return s
+	}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Fprintf(s.out, "\r%s %s", spinnerFrames[i%len(spinnerFrames)], s.label)
+			select {
+			case <-s.stop:
+				// 清除这一行，后续的输出从行首开始
+				fmt.Fprintf(s.out, "\r%s\r", strings.Repeat(" ", len(s.label)+2))
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// Stop 停止显示 Spinner 并清除它所在的行。
+func (s *Spinner) Stop() {
+	if s.out != nil {
+		close(s.stop)
+	}
+	<-s.done
+}
@@ -0,0 +1,83 @@
+// file: internal/ecsm-cli/util/registry.go
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/apiserver/client"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/spf13/viper"
+	bolt "go.etcd.io/bbolt"
+)
+
+// NewRegistryFromFlags 返回读取 operator Registry 的 registry.Interface。
+// 设置了 server 标志时通过 operator 的 API Server 访问，这是 operator 运行时唯一可用的方式；
+// 否则以只读方式直接打开 registry-db 标志指定的数据库文件，这只能在 operator 停止时使用。
+// 直接打开数据库时，设置了 encryption-key-file 才能读取 ECSMSecret；API Server 返回的 ECSMSecret 不带值。
+// 调用方负责在使用完毕后调用返回的 close 函数。
+func NewRegistryFromFlags() (registry.Interface, func() error, error) {
+	return openRegistryFromFlags(true)
+}
+
+// NewWritableRegistryFromFlags 与 NewRegistryFromFlags 相同，但直接打开数据库时以读写方式打开，用于修改对象的命令。
+func NewWritableRegistryFromFlags() (registry.Interface, func() error, error) {
+	return openRegistryFromFlags(false)
+}
+
+// IsRemoteRegistry 返回 NewRegistryFromFlags 是否会通过 API Server 访问 Registry。
+func IsRemoteRegistry() bool {
+	return viper.GetString("server") != ""
+}
+
+// HasRegistryFlags 返回是否设置了访问 Registry 的 server 或 registry-db 标志。
+func HasRegistryFlags() bool {
+	return viper.GetString("server") != "" || viper.GetString("registry-db") != ""
+}
+
+func openRegistryFromFlags(readOnly bool) (registry.Interface, func() error, error) {
+	if server := viper.GetString("server"); server != "" {
+		c, err := client.New(server, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, func() error { return nil }, nil
+	}
+
+	path := viper.GetString("registry-db")
+	if path == "" {
+		return nil, nil, fmt.Errorf("--server (the URL of the ecsm-operator API server) or --registry-db must be specified")
+	}
+
+	// operator 运行时持有数据库的写锁，这里使用超时避免无限等待
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: readOnly, Timeout: 2 * time.Second})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, nil, fmt.Errorf("registry database %s is locked, the ecsm-operator is probably running: use --server to go through its API server instead", path)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open registry database %s: %w", path, err)
+	}
+
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	// 只有提供了密钥才能读取 ECSMSecret
+	if keyFile := viper.GetString("encryption-key-file"); keyFile != "" {
+		key, err := registry.LoadEncryptionKey(keyFile)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		transformer, err := registry.NewAESGCMTransformer(key)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		reg.SetEncryption(transformer)
+	}
+	return reg, db.Close, nil
+}
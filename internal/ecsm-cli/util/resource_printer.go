@@ -0,0 +1,188 @@
+// file: internal/ecsm-cli/util/resource_printer.go
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// --output 标志支持的格式，空字符串表示默认的表格
+const (
+	OutputWide = "wide"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+	OutputName = "name"
+)
+
+// ResourcePrinter 把一个对象或对象列表以某种格式打印到 out。
+type ResourcePrinter interface {
+	PrintObj(obj interface{}, out io.Writer) error
+}
+
+// AddOutputFlag 为 cmd 注册 -o/--output 标志，返回保存标志值的变量。
+func AddOutputFlag(cmd *cobra.Command) *string {
+	output := new(string)
+	cmd.Flags().StringVarP(output, "output", "o", "", "Output format, one of json, yaml, wide, name, jsonpath=TEMPLATE or custom-columns=HEADER:JSONPATH,... A table is printed by default")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]cobra.Completion{OutputJSON, OutputYAML, OutputWide, OutputName}, cobra.ShellCompDirectiveNoFileComp))
+	return output
+}
+
+// IsTableOutput 判断 output 是否是给人阅读的表格格式，调用方据此决定是否打印 "No resources found." 之类的提示。
+func IsTableOutput(output string) bool {
+	return output == "" || output == OutputWide
+}
+
+// NewPrinter 返回 output 对应的 ResourcePrinter。kind 是 name 格式中的资源类型前缀，例如 "node"。
+// jsonpath 和 custom-columns 格式的参数写在等号之后，例如 "custom-columns=NAME:.name"。
+func NewPrinter(output, kind string) (ResourcePrinter, error) {
+	format, arg, _ := strings.Cut(output, "=")
+	switch format {
+	case "":
+		return &TablePrinter{}, nil
+	case OutputWide:
+		return &TablePrinter{PrintOptions: PrintOptions{Wide: true}}, nil
+	case OutputJSON:
+		return &JSONPrinter{}, nil
+	case OutputYAML:
+		return &YAMLPrinter{}, nil
+	case OutputName:
+		return &NamePrinter{Kind: kind}, nil
+	case OutputJSONPath:
+		return NewJSONPathPrinter(arg)
+	case OutputCustomColumns:
+		return NewCustomColumnsPrinter(arg)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, must be one of json, yaml, wide, name, jsonpath=TEMPLATE or custom-columns=SPEC", output)
+	}
+}
+
+// tableHandlers 以对象的类型为索引，保存把该类型打印为表格的函数
+var tableHandlers = map[reflect.Type]func(out io.Writer, obj interface{}, opts PrintOptions){}
+
+// registerTableHandler 注册类型 T 的表格打印函数。
+func registerTableHandler[T any](print func(out io.Writer, obj T, opts PrintOptions)) {
+	tableHandlers[reflect.TypeFor[T]()] = func(out io.Writer, obj interface{}, opts PrintOptions) {
+		print(out, obj.(T), opts)
+	}
+}
+
+// TablePrinter 使用为对象类型注册的函数打印表格。
+type TablePrinter struct {
+	PrintOptions
+	// FullIDs 为 true 时总是打印完整的 ID
+	FullIDs bool
+	// Width 是终端的宽度，表格比它宽时截短表格中的 ID；为 0 时不截短
+	Width int
+}
+
+func (p *TablePrinter) PrintObj(obj interface{}, out io.Writer) error {
+	handler, ok := tableHandlers[reflect.TypeOf(obj)]
+	if !ok {
+		return fmt.Errorf("no table printer registered for %T", obj)
+	}
+	var buf bytes.Buffer
+	handler(&buf, obj, p.PrintOptions)
+	if p.Width > 0 && !p.FullIDs && widestLine(buf.String()) > p.Width {
+		opts := p.PrintOptions
+		opts.ShortIDs = true
+		buf.Reset()
+		handler(&buf, obj, opts)
+	}
+	_, err := buf.WriteTo(out)
+	return err
+}
+
+// JSONPrinter 把对象打印为缩进的 JSON。
+type JSONPrinter struct{}
+
+func (p *JSONPrinter) PrintObj(obj interface{}, out io.Writer) error {
+	data, err := json.MarshalIndent(emptySliceIfNil(obj), "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
+
+// YAMLPrinter 把对象打印为 YAML。
+type YAMLPrinter struct{}
+
+func (p *YAMLPrinter) PrintObj(obj interface{}, out io.Writer) error {
+	data, err := yaml.Marshal(emptySliceIfNil(obj))
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// NamePrinter 为每个对象打印一行 "<Kind>/<名称>"，便于在脚本中使用。
+type NamePrinter struct {
+	Kind string
+}
+
+func (p *NamePrinter) PrintObj(obj interface{}, out io.Writer) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Slice {
+		return p.printOne(obj, out)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := p.printOne(v.Index(i).Addr().Interface(), out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *NamePrinter) printOne(obj interface{}, out io.Writer) error {
+	name, err := resourceName(obj)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s/%s\n", p.Kind, name)
+	return err
+}
+
+// namer 由没有 Name 字段或者需要特殊名称的类型实现
+type namer interface {
+	resourceName() string
+}
+
+// resourceName 返回对象的名称：优先使用 namer 和 metav1.Object，否则使用结构体的 Name 字段，
+// 没有名称的对象 (例如事务) 使用 ID 字段。
+func resourceName(obj interface{}) (string, error) {
+	switch o := obj.(type) {
+	case namer:
+		return o.resourceName(), nil
+	case metav1.Object:
+		return o.GetName(), nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	if v.Kind() == reflect.Struct {
+		for _, name := range []string{"Name", "ID"} {
+			if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+				return f.String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("cannot print the name of %T", obj)
+}
+
+// emptySliceIfNil 把 nil 切片替换为空切片，使没有对象时输出 [] 而不是 null。
+func emptySliceIfNil(obj interface{}) interface{} {
+	v := reflect.ValueOf(obj)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return obj
+}
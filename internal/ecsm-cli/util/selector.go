@@ -0,0 +1,138 @@
+// file: internal/ecsm-cli/util/selector.go
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+// PathLabelKey 是选择器中表示服务路径标签的键。路径标签不是 key=value 形式的标签，
+// 为了能在选择器中使用它，把它当作键为 PathLabelKey 的标签。
+const PathLabelKey = "ecsm.sh/path"
+
+// selectorOperator 是选择器中一个条件的运算符
+type selectorOperator string
+
+const (
+	selectorEquals       selectorOperator = "="
+	selectorNotEquals    selectorOperator = "!="
+	selectorExists       selectorOperator = "exists"
+	selectorDoesNotExist selectorOperator = "!"
+)
+
+// selectorRequirement 是选择器中的一个条件
+type selectorRequirement struct {
+	key      string
+	operator selectorOperator
+	value    string
+}
+
+// Selector 是一个标签选择器，所有条件都满足时才匹配。
+// 与 Kubernetes 的标签选择器不同，它不校验键和值的格式，因为 ECSM 平台的标签可以是任意字符串。
+type Selector []selectorRequirement
+
+// ParseSelector 解析 "key=value,key!=value,key,!key" 形式的选择器，"==" 与 "=" 相同。空字符串返回空的选择器。
+func ParseSelector(s string) (Selector, error) {
+	var selector Selector
+	if strings.TrimSpace(s) == "" {
+		return selector, nil
+	}
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		var req selectorRequirement
+		switch {
+		case strings.Contains(term, "!="):
+			key, value, _ := strings.Cut(term, "!=")
+			req = selectorRequirement{key: key, operator: selectorNotEquals, value: value}
+		case strings.Contains(term, "=="):
+			key, value, _ := strings.Cut(term, "==")
+			req = selectorRequirement{key: key, operator: selectorEquals, value: value}
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(term, "=")
+			req = selectorRequirement{key: key, operator: selectorEquals, value: value}
+		case strings.HasPrefix(term, "!"):
+			req = selectorRequirement{key: strings.TrimPrefix(term, "!"), operator: selectorDoesNotExist}
+		default:
+			req = selectorRequirement{key: term, operator: selectorExists}
+		}
+		req.key, req.value = strings.TrimSpace(req.key), strings.TrimSpace(req.value)
+		if req.key == "" {
+			return nil, fmt.Errorf("invalid selector %q: empty key in %q", s, term)
+		}
+		selector = append(selector, req)
+	}
+	return selector, nil
+}
+
+// Empty 判断选择器是否没有条件，空的选择器匹配所有对象。
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+// Matches 判断 labels 是否满足选择器的所有条件。
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, ok := labels[req.key]
+		switch req.operator {
+		case selectorEquals:
+			if !ok || value != req.value {
+				return false
+			}
+		case selectorNotEquals:
+			if ok && value == req.value {
+				return false
+			}
+		case selectorExists:
+			if !ok {
+				return false
+			}
+		case selectorDoesNotExist:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// PathLabel 返回选择器要求的路径标签，没有要求时返回空字符串。
+// ECSM 平台的 label 查询参数按路径标签过滤服务，调用方可以用它缩小服务端返回的结果。
+func (s Selector) PathLabel() string {
+	for _, req := range s {
+		if req.key == PathLabelKey && req.operator == selectorEquals {
+			return req.value
+		}
+	}
+	return ""
+}
+
+// ServiceLabels 返回选择器匹配服务时使用的标签：默认标签、用户标签和键为 PathLabelKey 的路径标签。
+// 没有 "=" 的标签被当作值为空的标签。
+func ServiceLabels(svc *clientset.ProvisionListRow) map[string]string {
+	labels := make(map[string]string, len(svc.DefaultLabels)+len(svc.Labels)+1)
+	for _, l := range append(append([]string(nil), svc.DefaultLabels...), svc.Labels...) {
+		k, v, _ := strings.Cut(l, "=")
+		labels[k] = v
+	}
+	if svc.PathLabel != "" {
+		labels[PathLabelKey] = svc.PathLabel
+	}
+	return labels
+}
+
+// FilterServices 返回满足选择器的服务。
+func FilterServices(services []clientset.ProvisionListRow, selector Selector) []clientset.ProvisionListRow {
+	if selector.Empty() {
+		return services
+	}
+	var result []clientset.ProvisionListRow
+	for i := range services {
+		if selector.Matches(ServiceLabels(&services[i])) {
+			result = append(result, services[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+// file: internal/ecsm-cli/util/sort.go
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// sortAliases 以对象的类型为索引，保存 --sort-by 中可以代替 JSONPath 使用的列名，
+// 例如容器的 "restarts" 对应 ".restartCnt"。
+var sortAliases = map[reflect.Type]map[string]string{}
+
+// registerSortAliases 注册类型 T 的排序列名，列名不区分大小写。
+func registerSortAliases[T any](aliases map[string]string) {
+	sortAliases[reflect.TypeFor[T]()] = aliases
+}
+
+// AddSortByFlag 为列出资源的 cmd 注册 --sort-by 标志，返回保存标志值的变量。
+func AddSortByFlag(cmd *cobra.Command) *string {
+	sortBy := new(string)
+	cmd.Flags().StringVar(sortBy, "sort-by", "", "Sort the list by a JSONPath expression such as '.name' or a column such as 'restarts', in ascending order")
+	return sortBy
+}
+
+// Sorter 按 JSONPath 表达式的值对对象列表排序。
+type Sorter struct {
+	field string
+}
+
+// NewSorter 返回按 sortBy 排序的 Sorter，sortBy 为空时返回 nil，nil 的 Sorter 不改变列表。
+// sortBy 是 JSONPath 表达式 (可以省略花括号)，或者是为对象类型注册的列名。
+func NewSorter(sortBy string) (*Sorter, error) {
+	if sortBy == "" {
+		return nil, nil
+	}
+	if err := jsonpath.New("sort-by").Parse(relaxedJSONPath(sortBy)); err != nil {
+		return nil, fmt.Errorf("invalid --sort-by %q: %w", sortBy, err)
+	}
+	return &Sorter{field: sortBy}, nil
+}
+
+// Sort 对切片 list 原地稳定排序。没有值的对象排在最前面。
+func (s *Sorter) Sort(list interface{}) error {
+	if s == nil {
+		return nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("cannot sort %T, expected a list", list)
+	}
+	if v.Len() < 2 {
+		return nil
+	}
+
+	jp := jsonpath.New("sort-by").AllowMissingKeys(true)
+	if err := jp.Parse(relaxedJSONPath(s.expression(v.Type().Elem()))); err != nil {
+		return fmt.Errorf("invalid --sort-by %q: %w", s.field, err)
+	}
+	data, err := toJSONData(list)
+	if err != nil {
+		return err
+	}
+	items := data.([]interface{})
+	keys := make([]interface{}, len(items))
+	for i, item := range items {
+		results, err := jp.FindResults(item)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate --sort-by %q: %w", s.field, err)
+		}
+		if len(results) > 0 && len(results[0]) > 0 {
+			keys[i] = results[0][0].Interface()
+		}
+	}
+
+	// 先排序下标，再按下标重排切片，使 keys 与元素保持对应
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return lessSortKey(keys[order[a]], keys[order[b]]) })
+	sorted := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	for i, j := range order {
+		sorted.Index(i).Set(v.Index(j))
+	}
+	reflect.Copy(v, sorted)
+	return nil
+}
+
+// expression 返回元素类型为 elem 时实际使用的 JSONPath 表达式：注册的列名被替换为对应的表达式，
+// Kubernetes 风格的对象可以省略 ".metadata"，例如 "name" 和 "creationTimestamp"。
+func (s *Sorter) expression(elem reflect.Type) string {
+	if strings.ContainsAny(s.field, ".{") {
+		return s.field
+	}
+	if expr, ok := sortAliases[elem][strings.ToLower(s.field)]; ok {
+		return expr
+	}
+	if reflect.PointerTo(elem).Implements(reflect.TypeFor[metav1.Object]()) {
+		if f, ok := reflect.TypeFor[metav1.ObjectMeta]().FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, s.field)
+		}); ok {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			return ".metadata." + name
+		}
+	}
+	return s.field
+}
+
+// lessSortKey 比较两个排序键：数字按数值，其他值按字符串比较，nil 最小。
+func lessSortKey(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	if na, ok := a.(json.Number); ok {
+		if nb, ok := b.(json.Number); ok {
+			fa, errA := na.Float64()
+			fb, errB := nb.Float64()
+			if errA == nil && errB == nil {
+				return fa < fb
+			}
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
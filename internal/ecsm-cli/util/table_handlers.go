@@ -0,0 +1,106 @@
+// file: internal/ecsm-cli/util/table_handlers.go
+
+package util
+
+import (
+	"fmt"
+	"io"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+// NodeDescription 是 "describe node" 聚合的信息
+type NodeDescription struct {
+	Node    *clientset.NodeView    `json:"node"`
+	Metrics *clientset.NodeMetrics `json:"metrics"`
+}
+
+func (d *NodeDescription) resourceName() string { return d.Node.Name }
+
+// ServiceDescription 是 "describe service" 聚合的信息
+type ServiceDescription struct {
+	Service    *clientset.ServiceGet     `json:"service"`
+	Containers []clientset.ContainerInfo `json:"containers"`
+	// Events 是 operator 记录的相关事件，没有指定 Registry 时为 nil
+	Events []ecsmv1.Event `json:"events,omitempty"`
+}
+
+func (d *ServiceDescription) resourceName() string { return d.Service.Name }
+
+// ContainerDescription 是 "describe container" 聚合的信息
+type ContainerDescription struct {
+	Container *clientset.ContainerInfo        `json:"container"`
+	History   *clientset.ContainerHistoryList `json:"history,omitempty"`
+}
+
+func (d *ContainerDescription) resourceName() string { return d.Container.Name }
+
+// 注册所有 get 和 describe 输出的类型的表格打印函数。describe 的输出没有 wide 格式，wide 与默认格式相同。
+func init() {
+	registerTableHandler(PrintNodesTable)
+	registerTableHandler(PrintImagesTable)
+	registerTableHandler(PrintServicesTable)
+	registerTableHandler(PrintContainersTable)
+	registerTableHandler(PrintEventsTable)
+	registerTableHandler(PrintJobsTable)
+	registerTableHandler(PrintCronJobsTable)
+	registerTableHandler(PrintNodeSetsTable)
+	registerTableHandler(PrintConfigsTable)
+	registerTableHandler(PrintSecretsTable)
+	registerTableHandler(PrintSnapshotsTable)
+	registerTableHandler(PrintTransactionsTable)
+
+	registerTableHandler(func(out io.Writer, d *clientset.ImageDetails, _ PrintOptions) {
+		PrintImageDetails(out, d)
+	})
+	registerTableHandler(func(out io.Writer, d *NodeDescription, _ PrintOptions) {
+		PrintNodeDetails(out, d.Node, d.Metrics)
+	})
+	registerTableHandler(func(out io.Writer, d *ServiceDescription, _ PrintOptions) {
+		PrintServiceDetails(out, d.Service, d.Containers)
+		if d.Events != nil {
+			fmt.Fprintf(out, "\n")
+			PrintEventsSection(out, d.Events)
+		}
+	})
+	registerTableHandler(func(out io.Writer, d *ContainerDescription, _ PrintOptions) {
+		PrintContainerDetails(out, d.Container, d.History)
+	})
+	registerTableHandler(func(out io.Writer, tx *clientset.Transaction, _ PrintOptions) {
+		PrintTransactionDetails(out, tx)
+	})
+}
+
+// 注册 --sort-by 可以使用的表格列名，只需要注册与 JSON 字段名不同的列。
+func init() {
+	registerSortAliases[clientset.NodeInfo](map[string]string{
+		"containers": ".containerRunning",
+		"created":    ".createdTime",
+	})
+	registerSortAliases[clientset.ImageListItem](map[string]string{
+		"created": ".createdTime",
+	})
+	registerSortAliases[clientset.ProvisionListRow](map[string]string{
+		"deploy_status": ".status",
+		"online":        ".instanceOnline",
+		"desired":       ".factor",
+		"path_label":    ".pathLabel",
+		"created":       ".createdTime",
+	})
+	registerSortAliases[clientset.ContainerInfo](map[string]string{
+		"restarts": ".restartCnt",
+		"image":    ".imageName",
+		"service":  ".serviceName",
+		"node":     ".nodeName",
+		"cpu":      ".cpuUsage.total",
+		"memory":   ".memoryUsage",
+	})
+	registerSortAliases[clientset.Transaction](map[string]string{
+		"submitted": ".timestamp",
+	})
+	registerSortAliases[ecsmv1.Event](map[string]string{
+		"last_seen":  ".lastTimestamp",
+		"first_seen": ".firstTimestamp",
+	})
+}
@@ -0,0 +1,89 @@
+// file: internal/ecsm-cli/util/table_options.go
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// shortIDLength 是截短后的 ID 长度，与 docker 的短 ID 相同
+const shortIDLength = 12
+
+// PrintOptions 控制表格打印函数的输出。
+type PrintOptions struct {
+	// Wide 为 true 时打印更多的列
+	Wide bool
+	// NoHeaders 为 true 时不打印表头
+	NoHeaders bool
+	// ShowLabels 为 true 时打印对象的标签 (目前只有服务支持)
+	ShowLabels bool
+	// ShortIDs 为 true 时把 ID 截短为 shortIDLength 个字符
+	ShortIDs bool
+}
+
+// id 按 ShortIDs 返回完整的或截短的 id。
+func (o PrintOptions) id(id string) string {
+	if o.ShortIDs && len(id) > shortIDLength {
+		return id[:shortIDLength]
+	}
+	return id
+}
+
+// printHeader 在没有设置 NoHeaders 时打印表头。
+func printHeader(w io.Writer, header string, opts PrintOptions) {
+	if !opts.NoHeaders {
+		fmt.Fprintln(w, header)
+	}
+}
+
+// widestLine 返回 s 中最长一行的字符数。
+func widestLine(s string) int {
+	widest := 0
+	for _, line := range strings.Split(s, "\n") {
+		widest = max(widest, utf8.RuneCountInString(line))
+	}
+	return widest
+}
+
+// TableFlags 是列出资源的命令共用的表格标志。
+type TableFlags struct {
+	NoHeaders  bool
+	ShowLabels bool
+	FullIDs    bool
+}
+
+// AddTableFlags 为 cmd 注册 --no-headers 和 --full-ids 标志，showLabels 为 true 时还注册 --show-labels。
+func AddTableFlags(cmd *cobra.Command, showLabels bool) *TableFlags {
+	f := &TableFlags{}
+	cmd.Flags().BoolVar(&f.NoHeaders, "no-headers", false, "Do not print the header line of the table or custom-columns output")
+	cmd.Flags().BoolVar(&f.FullIDs, "full-ids", false, "Always print full IDs; by default they are shortened when the table is wider than the terminal")
+	if showLabels {
+		cmd.Flags().BoolVar(&f.ShowLabels, "show-labels", false, "Print the labels as the last column of the table")
+	}
+	return f
+}
+
+// Apply 把标志设置到 printer 上，不是表格或 custom-columns 格式的 printer 不受影响。
+// 标准输出是终端时，表格按终端的宽度截短 ID。
+func (f *TableFlags) Apply(printer ResourcePrinter) {
+	switch p := printer.(type) {
+	case *TablePrinter:
+		p.NoHeaders = f.NoHeaders
+		p.ShowLabels = f.ShowLabels
+		p.FullIDs = f.FullIDs
+		if IsTerminal(os.Stdout) {
+			if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+				p.Width = width
+			}
+		}
+	case *CustomColumnsPrinter:
+		p.NoHeaders = f.NoHeaders
+	}
+}
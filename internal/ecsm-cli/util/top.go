@@ -0,0 +1,125 @@
+// file: internal/ecsm-cli/util/top.go
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+// top 命令支持的排序字段，空字符串表示保持平台返回的顺序
+const (
+	SortByCPU    = "cpu"
+	SortByMemory = "memory"
+)
+
+// ValidateSortBy 检查 --sort-by 标志的值。
+func ValidateSortBy(sortBy string) error {
+	switch sortBy {
+	case "", SortByCPU, SortByMemory:
+		return nil
+	default:
+		return fmt.Errorf("unsupported sort field %q, must be %s or %s", sortBy, SortByCPU, SortByMemory)
+	}
+}
+
+// NodeUsage 是一个节点的资源使用情况。Metrics 为 nil 表示无法获取该节点的指标，例如节点离线。
+type NodeUsage struct {
+	Node    clientset.NodeInfo
+	Metrics *clientset.NodeMetrics
+}
+
+// cpu 和 memory 返回排序使用的值，没有指标的节点排在最后
+func (u *NodeUsage) cpu() float64 {
+	if u.Metrics == nil {
+		return -1
+	}
+	return parsePercent(u.Metrics.CPU.Percent)
+}
+
+func (u *NodeUsage) memory() float64 {
+	if u.Metrics == nil {
+		return -1
+	}
+	return u.Metrics.RAM.Size
+}
+
+// SortNodeUsage 按 sortBy 从高到低排序节点，sortBy 为空时不排序。
+func SortNodeUsage(usages []NodeUsage, sortBy string) {
+	switch sortBy {
+	case SortByCPU:
+		sort.SliceStable(usages, func(i, j int) bool { return usages[i].cpu() > usages[j].cpu() })
+	case SortByMemory:
+		sort.SliceStable(usages, func(i, j int) bool { return usages[i].memory() > usages[j].memory() })
+	}
+}
+
+// SortContainerUsage 按 sortBy 从高到低排序容器，sortBy 为空时不排序。
+func SortContainerUsage(containers []clientset.ContainerInfo, sortBy string) {
+	switch sortBy {
+	case SortByCPU:
+		sort.SliceStable(containers, func(i, j int) bool { return containers[i].CPUUsage.Total > containers[j].CPUUsage.Total })
+	case SortByMemory:
+		sort.SliceStable(containers, func(i, j int) bool { return containers[i].MemoryUsage > containers[j].MemoryUsage })
+	}
+}
+
+// PrintNodeUsageTable 以表格形式打印节点的资源使用情况。
+func PrintNodeUsageTable(out io.Writer, usages []NodeUsage) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tCPU(%)\tMEMORY(MiB)\tMEMORY(%)\tDISK(%)\tPROCESSES\tCONTAINERS")
+	for _, u := range usages {
+		containers := fmt.Sprintf("%d/%d", u.Node.ContainerEcsmRunning, u.Node.ContainerEcsmTotal)
+		if u.Metrics == nil {
+			fmt.Fprintf(w, "%s\t<unknown>\t<unknown>\t<unknown>\t<unknown>\t<unknown>\t%s\n", u.Node.Name, containers)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\t%s\t%d\t%s\n",
+			u.Node.Name,
+			u.Metrics.CPU.Percent,
+			u.Metrics.RAM.Size/1024/1024,
+			u.Metrics.RAM.Percent,
+			u.Metrics.ROM.Percent,
+			u.Metrics.ProcessCount,
+			containers,
+		)
+	}
+}
+
+// PrintContainerUsageTable 以表格形式打印容器的资源使用情况，没有内存限制的容器不打印内存百分比。
+func PrintContainerUsageTable(out io.Writer, containers []clientset.ContainerInfo) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tSERVICE\tNODE\tCPU(%)\tMEMORY(MiB)\tMEMORY(%)")
+	for _, c := range containers {
+		memPercent := "<none>"
+		if c.MemoryLimit > 0 {
+			memPercent = fmt.Sprintf("%.2f", float64(c.MemoryUsage)*100/float64(c.MemoryLimit))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%.2f\t%s\n",
+			c.Name,
+			c.ServiceName,
+			c.NodeName,
+			c.CPUUsage.Total,
+			float64(c.MemoryUsage)/1024/1024,
+			memPercent,
+		)
+	}
+}
+
+// parsePercent 解析平台以字符串返回的百分比，无法解析时返回 0。
+func parsePercent(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
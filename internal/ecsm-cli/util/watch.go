@@ -0,0 +1,147 @@
+// file: internal/ecsm-cli/util/watch.go
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// 对象变化的类型，与 Kubernetes watch 事件的类型相同
+const (
+	WatchAdded    = "ADDED"
+	WatchModified = "MODIFIED"
+	WatchDeleted  = "DELETED"
+)
+
+// WatchEvent 是 --watch 模式下 json、yaml、name 和 jsonpath 格式输出的一个对象变化。
+type WatchEvent struct {
+	Type   string      `json:"type"`
+	Object interface{} `json:"object"`
+}
+
+func (e *WatchEvent) resourceName() string {
+	name, _ := resourceName(e.Object)
+	return name
+}
+
+// ListWatcher 周期性地列出一种对象，与上一次的结果比较后只打印新增、修改和删除的对象，
+// 类似 "kubectl get -w"。ECSM 平台没有 watch 接口，只能轮询。
+type ListWatcher[T any] struct {
+	// List 列出当前的所有对象
+	List func(ctx context.Context) ([]T, error)
+	// Key 返回对象的唯一标识，通常是 ID
+	Key func(obj *T) string
+	// Normalize 清除对象中每次查询都会变化的字段（例如运行时间），可以为 nil。
+	// 清除后的对象只用于比较，打印的仍是原对象。
+	Normalize func(obj T) T
+	// Interval 是轮询的间隔
+	Interval time.Duration
+}
+
+// Watch 打印当前的所有对象，然后每隔 Interval 打印发生变化的对象，直到 ctx 被取消。
+//
+// 表格和 custom-columns 格式在每行前增加 EVENT 列，表头 (如果有) 只打印一次；这要求表格打印函数为每个对象打印一行。
+// 其它格式为每个变化打印一个 WatchEvent。
+func (lw *ListWatcher[T]) Watch(ctx context.Context, out io.Writer, printer ResourcePrinter) error {
+	previous := map[string]T{}
+	var previousKeys []string
+	headerPrinted := false
+	// printed 是以 WatchEvent 打印的事件数，YAML 文档之间需要分隔符
+	printed := 0
+
+	err := wait.PollUntilContextCancel(ctx, lw.Interval, true, func(ctx context.Context) (bool, error) {
+		items, err := lw.List(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		var changed []T
+		var types []string
+		current := make(map[string]T, len(items))
+		currentKeys := make([]string, 0, len(items))
+		for i := range items {
+			key := lw.Key(&items[i])
+			current[key] = items[i]
+			currentKeys = append(currentKeys, key)
+			old, ok := previous[key]
+			switch {
+			case !ok:
+				changed, types = append(changed, items[i]), append(types, WatchAdded)
+			case !reflect.DeepEqual(lw.normalize(old), lw.normalize(items[i])):
+				changed, types = append(changed, items[i]), append(types, WatchModified)
+			}
+		}
+		for _, key := range previousKeys {
+			if _, ok := current[key]; !ok {
+				changed, types = append(changed, previous[key]), append(types, WatchDeleted)
+			}
+		}
+		previous, previousKeys = current, currentKeys
+
+		if _, ok := printer.(rowPrinter); !ok {
+			_, isYAML := printer.(*YAMLPrinter)
+			for i := range changed {
+				if isYAML && printed > 0 {
+					fmt.Fprintln(out, "---")
+				}
+				printed++
+				if err := printer.PrintObj(&WatchEvent{Type: types[i], Object: &changed[i]}, out); err != nil {
+					return false, err
+				}
+			}
+			return false, nil
+		}
+
+		// 第一次查询即使没有对象也打印表头，让用户知道命令在运行
+		if len(changed) == 0 && headerPrinted {
+			return false, nil
+		}
+		var buf bytes.Buffer
+		if err := printer.PrintObj(changed, &buf); err != nil {
+			return false, err
+		}
+		rows := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if printer.(rowPrinter).hasHeaders() {
+			if !headerPrinted {
+				fmt.Fprintf(out, "%-10s%s\n", "EVENT", rows[0])
+			}
+			rows = rows[1:]
+		}
+		headerPrinted = true
+		for i, line := range rows {
+			if i < len(types) {
+				fmt.Fprintf(out, "%-10s%s\n", types[i], line)
+			}
+		}
+		return false, nil
+	})
+	if ctx.Err() != nil {
+		// 用户中断了 watch，不是错误
+		return nil
+	}
+	return err
+}
+
+func (lw *ListWatcher[T]) normalize(obj T) T {
+	if lw.Normalize == nil {
+		return obj
+	}
+	return lw.Normalize(obj)
+}
+
+// rowPrinter 由每个对象打印一行的 printer 实现，--watch 模式为它们增加 EVENT 列
+type rowPrinter interface {
+	// hasHeaders 返回是否在第一行打印表头
+	hasHeaders() bool
+}
+
+func (p *TablePrinter) hasHeaders() bool         { return !p.NoHeaders }
+func (p *CustomColumnsPrinter) hasHeaders() bool { return !p.NoHeaders }
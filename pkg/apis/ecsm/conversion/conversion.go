@@ -0,0 +1,27 @@
+// file: pkg/apis/ecsm/conversion/conversion.go
+
+// Package conversion 定义了 ecsm.sh API 组的多版本转换模型。
+//
+// 采用 hub/spoke 模型：v1 是 hub，即所有版本在内存和 Registry 中统一使用的存储版本；
+// 其他版本 (例如 v1alpha1) 是 spoke，只需要实现与 hub 之间的双向转换，
+// 任意两个版本之间的转换都经过 hub 完成。
+package conversion
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Hub 标记了一个类型是其 Kind 的 hub 版本。
+type Hub interface {
+	runtime.Object
+	Hub()
+}
+
+// Convertible 是可以与 hub 版本互相转换的 spoke 类型。
+type Convertible interface {
+	runtime.Object
+	// ConvertTo 把当前对象转换为 hub 版本，写入 dst
+	ConvertTo(dst Hub) error
+	// ConvertFrom 把 hub 版本的 src 转换为当前版本，写入当前对象
+	ConvertFrom(src Hub) error
+}
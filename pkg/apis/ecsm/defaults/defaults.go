@@ -0,0 +1,128 @@
+// file: pkg/apis/ecsm/defaults/defaults.go
+
+// Package defaults 为 ecsm.sh/v1 的对象填充默认值。
+// Registry 在创建对象时调用它，控制器和客户端也可以用它得到对象被保存后的样子，
+// 例如在 dry-run 时展示最终的 spec，或者与 Registry 中已经填充过默认值的对象比较。
+package defaults
+
+import (
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// DefaultMaxSurge 和 DefaultMaxUnavailable 是滚动更新的默认值，与 Kubernetes Deployment 保持一致
+	DefaultMaxSurge       = "25%"
+	DefaultMaxUnavailable = "25%"
+
+	// DefaultRemediationFailureThreshold 和 DefaultMaxRemediations 是 RemediationPolicy 的默认值
+	DefaultRemediationFailureThreshold int32 = 3
+	DefaultMaxRemediations             int32 = 3
+
+	// VSOA 健康检查的默认值，单位为秒
+	DefaultHealthCheckTimeoutSeconds   int32 = 1
+	DefaultHealthCheckPeriodSeconds    int32 = 10
+	DefaultHealthCheckFailureThreshold int32 = 3
+)
+
+// RegisterDefaults 把默认值函数注册到 scheme 中，之后可以通过 scheme.Default(obj) 填充默认值。
+func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&ecsmv1.ECSMService{}, func(obj interface{}) {
+		SetServiceDefaults(obj.(*ecsmv1.ECSMService))
+	})
+	scheme.AddTypeDefaultingFunc(&ecsmv1.ECSMServiceList{}, func(obj interface{}) {
+		list := obj.(*ecsmv1.ECSMServiceList)
+		for i := range list.Items {
+			SetServiceDefaults(&list.Items[i])
+		}
+	})
+	return nil
+}
+
+// SetServiceDefaults 为 ECSMService 中未设置的字段填充默认值。已经设置的字段保持不变，重复调用的结果相同。
+func SetServiceDefaults(service *ecsmv1.ECSMService) {
+	spec := &service.Spec
+	if spec.DriftPolicy == "" {
+		spec.DriftPolicy = ecsmv1.DriftPolicyEnforce
+	}
+	SetUpgradeStrategyDefaults(&spec.UpgradeStrategy)
+	if spec.Remediation != nil {
+		SetRemediationPolicyDefaults(spec.Remediation)
+	}
+	SetContainerTemplateDefaults(&spec.Template, service.Name)
+}
+
+// SetUpgradeStrategyDefaults 为升级策略填充默认值。
+func SetUpgradeStrategyDefaults(strategy *ecsmv1.UpgradeStrategy) {
+	if strategy.Type == "" {
+		strategy.Type = ecsmv1.UpgradeStrategyTypeNever
+	}
+	if strategy.Rollout == "" {
+		strategy.Rollout = ecsmv1.RolloutTypeRollingUpdate
+	}
+	if strategy.Rollout != ecsmv1.RolloutTypeRollingUpdate {
+		return
+	}
+	if strategy.MaxSurge == nil {
+		surge := intstr.FromString(DefaultMaxSurge)
+		strategy.MaxSurge = &surge
+	}
+	if strategy.MaxUnavailable == nil {
+		unavailable := intstr.FromString(DefaultMaxUnavailable)
+		strategy.MaxUnavailable = &unavailable
+	}
+}
+
+// SetRemediationPolicyDefaults 为补救策略填充默认值。
+func SetRemediationPolicyDefaults(policy *ecsmv1.RemediationPolicy) {
+	if policy.Action == "" {
+		policy.Action = ecsmv1.RemediationActionRestart
+	}
+	if policy.FailureThreshold == nil {
+		threshold := DefaultRemediationFailureThreshold
+		policy.FailureThreshold = &threshold
+	}
+	if policy.MaxRemediations == nil {
+		maxRemediations := DefaultMaxRemediations
+		policy.MaxRemediations = &maxRemediations
+	}
+}
+
+// SetContainerTemplateDefaults 为容器模板填充默认值，name 是未设置主机名时使用的名称。
+// 模板的默认值参与计算模板哈希，因此只应该在对象创建时填充，
+// 否则已经运行的服务会因为新增的默认值而被滚动更新。
+func SetContainerTemplateDefaults(template *ecsmv1.ContainerTemplateSpec, name string) {
+	if template.ImagePullPolicy == "" {
+		template.ImagePullPolicy = ecsmv1.ImagePullPolicyIfNotPresent
+	}
+	if template.Hostname == "" {
+		template.Hostname = name
+	}
+	// 没有任何限制的 resources 与不设置相同，统一为 nil 以免两种写法产生不同的模板哈希
+	if template.Resources != nil && len(template.Resources.Limits) == 0 {
+		template.Resources = nil
+	}
+	if template.VSOA != nil && template.VSOA.HealthCheck != nil {
+		SetHealthCheckDefaults(template.VSOA.HealthCheck)
+	}
+	for _, probe := range []*ecsmv1.Probe{template.LivenessProbe, template.ReadinessProbe} {
+		if probe != nil {
+			SetHealthCheckDefaults(&probe.HealthCheckSpec)
+		}
+	}
+}
+
+// SetHealthCheckDefaults 为 VSOA 健康检查和探针的时间参数填充默认值。
+// InitialDelaySeconds 的默认值 0 与字段的零值相同，不需要处理。
+func SetHealthCheckDefaults(hc *ecsmv1.HealthCheckSpec) {
+	if hc.TimeoutSeconds == 0 {
+		hc.TimeoutSeconds = DefaultHealthCheckTimeoutSeconds
+	}
+	if hc.PeriodSeconds == 0 {
+		hc.PeriodSeconds = DefaultHealthCheckPeriodSeconds
+	}
+	if hc.FailureThreshold == 0 {
+		hc.FailureThreshold = DefaultHealthCheckFailureThreshold
+	}
+}
@@ -0,0 +1,90 @@
+package defaults
+
+import (
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestSetServiceDefaults(t *testing.T) {
+	service := &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			Remediation: &ecsmv1.RemediationPolicy{},
+			Template: ecsmv1.ContainerTemplateSpec{
+				Image:     "web@1.0",
+				Resources: &ecsmv1.ResourceRequirements{},
+				VSOA:      &ecsmv1.VSOASpec{HealthCheck: &ecsmv1.HealthCheckSpec{PeriodSeconds: 30}},
+			},
+		},
+	}
+	SetServiceDefaults(service)
+
+	surge := intstr.FromString(DefaultMaxSurge)
+	unavailable := intstr.FromString(DefaultMaxUnavailable)
+	threshold, maxRemediations := DefaultRemediationFailureThreshold, DefaultMaxRemediations
+	want := ecsmv1.ECSMServiceSpec{
+		DriftPolicy: ecsmv1.DriftPolicyEnforce,
+		UpgradeStrategy: ecsmv1.UpgradeStrategy{
+			Type:           ecsmv1.UpgradeStrategyTypeNever,
+			Rollout:        ecsmv1.RolloutTypeRollingUpdate,
+			MaxSurge:       &surge,
+			MaxUnavailable: &unavailable,
+		},
+		Remediation: &ecsmv1.RemediationPolicy{
+			Action:           ecsmv1.RemediationActionRestart,
+			FailureThreshold: &threshold,
+			MaxRemediations:  &maxRemediations,
+		},
+		Template: ecsmv1.ContainerTemplateSpec{
+			Image:           "web@1.0",
+			ImagePullPolicy: ecsmv1.ImagePullPolicyIfNotPresent,
+			Hostname:        "web",
+			VSOA: &ecsmv1.VSOASpec{HealthCheck: &ecsmv1.HealthCheckSpec{
+				TimeoutSeconds:   DefaultHealthCheckTimeoutSeconds,
+				PeriodSeconds:    30,
+				FailureThreshold: DefaultHealthCheckFailureThreshold,
+			}},
+		},
+	}
+	if !equality.Semantic.DeepEqual(service.Spec, want) {
+		t.Errorf("SetServiceDefaults() spec = %+v, want %+v", service.Spec, want)
+	}
+
+	// 重复调用不改变结果
+	again := service.DeepCopy()
+	SetServiceDefaults(again)
+	if !equality.Semantic.DeepEqual(again, service) {
+		t.Errorf("SetServiceDefaults() is not idempotent")
+	}
+}
+
+func TestSetUpgradeStrategyDefaultsCanary(t *testing.T) {
+	strategy := &ecsmv1.UpgradeStrategy{Type: ecsmv1.UpgradeStrategyTypeAlways, Rollout: ecsmv1.RolloutTypeCanary}
+	SetUpgradeStrategyDefaults(strategy)
+	if strategy.Type != ecsmv1.UpgradeStrategyTypeAlways {
+		t.Errorf("type = %q, want the explicit value to be kept", strategy.Type)
+	}
+	if strategy.MaxSurge != nil || strategy.MaxUnavailable != nil {
+		t.Errorf("maxSurge and maxUnavailable should only be defaulted for RollingUpdate")
+	}
+}
+
+func TestRegisterDefaults(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := ecsmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterDefaults(scheme); err != nil {
+		t.Fatal(err)
+	}
+	service := &ecsmv1.ECSMService{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	scheme.Default(service)
+	if service.Spec.Template.Hostname != "web" {
+		t.Errorf("scheme.Default() did not apply the service defaults")
+	}
+}
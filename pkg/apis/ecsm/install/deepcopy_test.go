@@ -0,0 +1,96 @@
+package install
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/randfill"
+)
+
+// TestDeepCopy 对每个注册的类型随机填充字段，检查 DeepCopyObject 的结果与原对象相等，
+// 并且副本中没有任何 map、slice 或指针与原对象共享内存。
+func TestDeepCopy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+
+	filler := randfill.New().NilChance(0.2).NumElements(1, 3)
+	for gvk, typ := range scheme.AllKnownTypes() {
+		// metav1.AddToGroupVersion 注册到组中的通用类型不属于这里
+		if !strings.HasPrefix(typ.PkgPath(), "github.com/fx147/ecsm-operator/") {
+			continue
+		}
+		t.Run(gvk.String(), func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				obj := reflect.New(typ).Interface().(runtime.Object)
+				filler.Fill(obj)
+
+				copied := obj.DeepCopyObject()
+				if !reflect.DeepEqual(obj, copied) {
+					t.Fatalf("DeepCopyObject() is not equal to the original")
+				}
+				if path, shared := findSharedMemory(reflect.ValueOf(obj), reflect.ValueOf(copied), ""); shared {
+					t.Fatalf("DeepCopyObject() shares memory with the original at %s", path)
+				}
+			}
+		})
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// findSharedMemory 返回 a 和 b 中第一个指向同一块内存的 map、slice 或指针的路径。
+func findSharedMemory(a, b reflect.Value, path string) (string, bool) {
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return "", false
+		}
+		if a.Pointer() == b.Pointer() {
+			return path, true
+		}
+		return findSharedMemory(a.Elem(), b.Elem(), path)
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return "", false
+		}
+		return findSharedMemory(a.Elem(), b.Elem(), path)
+	case reflect.Map:
+		if a.IsNil() || b.IsNil() {
+			return "", false
+		}
+		if a.Pointer() == b.Pointer() {
+			return path, true
+		}
+		for _, key := range a.MapKeys() {
+			if p, shared := findSharedMemory(a.MapIndex(key), b.MapIndex(key), path+"["+key.String()+"]"); shared {
+				return p, true
+			}
+		}
+	case reflect.Slice:
+		if a.Len() == 0 || b.Len() == 0 {
+			return "", false
+		}
+		if a.Pointer() == b.Pointer() {
+			return path, true
+		}
+		for i := 0; i < a.Len(); i++ {
+			if p, shared := findSharedMemory(a.Index(i), b.Index(i), path+"[]"); shared {
+				return p, true
+			}
+		}
+	case reflect.Struct:
+		// time.Time 中的 *Location 本来就是共享的
+		if a.Type() == timeType {
+			return "", false
+		}
+		for i := 0; i < a.NumField(); i++ {
+			if p, shared := findSharedMemory(a.Field(i), b.Field(i), path+"."+a.Type().Field(i).Name); shared {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,20 @@
+// file: pkg/apis/ecsm/install/install.go
+
+// Package install 把 ecsm.sh API 组的所有版本注册到一个 scheme 中。
+package install
+
+import (
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+// Install 注册 ecsm.sh 组的所有版本、它们之间的转换函数和默认值函数，v1 是首选版本。
+func Install(scheme *runtime.Scheme) {
+	utilruntime.Must(ecsmv1.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(defaults.RegisterDefaults(scheme))
+	utilruntime.Must(scheme.SetVersionPriority(ecsmv1.SchemeGroupVersion, v1alpha1.SchemeGroupVersion))
+}
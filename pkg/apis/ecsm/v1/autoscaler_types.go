@@ -0,0 +1,139 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMServiceAutoscaler 根据容器的 CPU 和内存使用率，自动调整同一命名空间中某个 ECSMService 的副本数。
+// 它的作用与 Kubernetes 的 HorizontalPodAutoscaler 类似，但只支持 Dynamic 部署策略的服务。
+type ECSMServiceAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ECSMServiceAutoscalerSpec   `json:"spec,omitempty"`
+	Status ECSMServiceAutoscalerStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMServiceAutoscalerList 包含 ECSMServiceAutoscaler 的列表
+type ECSMServiceAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMServiceAutoscaler `json:"items"`
+}
+
+// ECSMServiceAutoscalerSpec 定义了自动扩缩容的期望行为
+type ECSMServiceAutoscalerSpec struct {
+	// ScaleTargetRef 是被扩缩容的 ECSMService 的名称，它必须与自动扩缩容对象在同一个命名空间中
+	// +required
+	ScaleTargetRef string `json:"scaleTargetRef"`
+
+	// MinReplicas 是副本数的下限，默认为 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas 是副本数的上限，不能小于 MinReplicas
+	// +kubebuilder:validation:Minimum=1
+	// +required
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Metrics 是用于计算期望副本数的指标。存在多个指标时，取计算结果中最大的副本数。
+	// +required
+	Metrics []AutoscalerMetric `json:"metrics"`
+
+	// Behavior 配置了扩容和缩容的稳定窗口
+	// +optional
+	Behavior AutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// AutoscalerMetric 定义了一个资源指标及其目标值
+type AutoscalerMetric struct {
+	// Resource 是指标对应的资源
+	// +kubebuilder:validation:Enum=cpu;memory
+	// +required
+	Resource AutoscalerResource `json:"resource"`
+
+	// TargetAverageUtilization 是所有运行中容器的平均使用率 (百分比) 的目标值。
+	// 内存使用率相对于容器的内存限制计算。
+	// +kubebuilder:validation:Minimum=1
+	// +required
+	TargetAverageUtilization int32 `json:"targetAverageUtilization"`
+}
+
+// AutoscalerResource 是自动扩缩容支持的资源
+type AutoscalerResource string
+
+const (
+	AutoscalerResourceCPU    AutoscalerResource = "cpu"
+	AutoscalerResourceMemory AutoscalerResource = "memory"
+)
+
+// AutoscalerBehavior 配置了扩容和缩容的行为
+type AutoscalerBehavior struct {
+	// ScaleUp 是扩容的规则，默认没有稳定窗口
+	// +optional
+	ScaleUp *AutoscalerScalingRules `json:"scaleUp,omitempty"`
+
+	// ScaleDown 是缩容的规则，默认稳定窗口为 300 秒
+	// +optional
+	ScaleDown *AutoscalerScalingRules `json:"scaleDown,omitempty"`
+}
+
+// AutoscalerScalingRules 定义了一个方向上的扩缩容规则
+type AutoscalerScalingRules struct {
+	// StabilizationWindowSeconds 是稳定窗口的长度。控制器会参考窗口内所有的推荐副本数：
+	// 扩容时取其中最小的，缩容时取其中最大的，以避免指标抖动导致副本数反复变化。
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	StabilizationWindowSeconds *int32 `json:"stabilizationWindowSeconds,omitempty"`
+}
+
+// ECSMServiceAutoscaler 的状况类型
+const (
+	// AutoscalerAbleToScale 表示控制器能够获取并修改目标服务
+	AutoscalerAbleToScale = "AbleToScale"
+	// AutoscalerScalingActive 表示控制器能够获取指标并计算期望副本数
+	AutoscalerScalingActive = "ScalingActive"
+	// AutoscalerScalingLimited 表示期望副本数被 minReplicas 或 maxReplicas 限制了
+	AutoscalerScalingLimited = "ScalingLimited"
+)
+
+// ECSMServiceAutoscalerStatus 定义了自动扩缩容的观测状态
+type ECSMServiceAutoscalerStatus struct {
+	// ObservedGeneration 是控制器最近一次处理的 metadata.generation
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// CurrentReplicas 是控制器最近一次观察到的目标服务的副本数
+	CurrentReplicas int32 `json:"currentReplicas"`
+
+	// DesiredReplicas 是控制器最近一次计算出的期望副本数
+	DesiredReplicas int32 `json:"desiredReplicas"`
+
+	// LastScaleTime 是控制器最近一次修改目标服务副本数的时间
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// CurrentMetrics 是控制器最近一次观察到的各项指标的值
+	// +optional
+	CurrentMetrics []AutoscalerMetricStatus `json:"currentMetrics,omitempty"`
+
+	// Conditions 描述了自动扩缩容的当前状况，例如 "AbleToScale"
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// AutoscalerMetricStatus 是一项指标的观测值
+type AutoscalerMetricStatus struct {
+	// Resource 是指标对应的资源
+	Resource AutoscalerResource `json:"resource"`
+
+	// CurrentAverageUtilization 是所有运行中容器的平均使用率 (百分比)
+	CurrentAverageUtilization int32 `json:"currentAverageUtilization"`
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMConfig 保存一组非敏感的键值配置，类似于 Kubernetes 的 ConfigMap。
+// 容器模板可以通过 envFrom、env[].valueFrom 和 volumeMounts[].hostPathFrom 引用同一命名空间中的 ECSMConfig，
+// 控制器在部署前把引用替换为配置的值。配置的值变化后，引用它的服务会被滚动更新。
+type ECSMConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Data 是配置的内容
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMConfigList 包含 ECSMConfig 的列表
+type ECSMConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMConfig `json:"items"`
+}
+
+// EnvFromSource 把一个 ECSMConfig 或 ECSMSecret 中的所有键值注入为环境变量
+type EnvFromSource struct {
+	// Prefix 会被加在每个环境变量名称的前面
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// ConfigRef 引用了同一命名空间中的 ECSMConfig
+	// +optional
+	ConfigRef *ConfigReference `json:"configRef,omitempty"`
+
+	// SecretRef 引用了同一命名空间中的 ECSMSecret
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
+
+// ConfigReference 引用了同一命名空间中的一个 ECSMConfig
+type ConfigReference struct {
+	// Name 是 ECSMConfig 的名称
+	Name string `json:"name"`
+
+	// Optional 为 true 时，ECSMConfig 不存在也不会报错
+	// +optional
+	Optional *bool `json:"optional,omitempty"`
+}
+
+// EnvVarSource 描述了环境变量的值的来源
+type EnvVarSource struct {
+	// ConfigKeyRef 从 ECSMConfig 的一个键中读取值
+	// +optional
+	ConfigKeyRef *ConfigKeySelector `json:"configKeyRef,omitempty"`
+
+	// SecretKeyRef 从 ECSMSecret 的一个键中读取值
+	// +optional
+	SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// ConfigKeySelector 选择了同一命名空间中 ECSMConfig 的一个键
+type ConfigKeySelector struct {
+	// Name 是 ECSMConfig 的名称
+	Name string `json:"name"`
+
+	// Key 是要读取的键
+	Key string `json:"key"`
+
+	// Optional 为 true 时，ECSMConfig 或键不存在也不会报错，引用会被忽略
+	// +optional
+	Optional *bool `json:"optional,omitempty"`
+}
@@ -0,0 +1,4 @@
+package v1
+
+// Hub 把 v1 标记为 ECSMService 的 hub 版本，其他版本都与它互相转换。
+func (*ECSMService) Hub() {}
@@ -0,0 +1,101 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMCronJob 按照 cron 表达式周期性地创建 ECSMJob，例如在边缘节点上定时执行的数据采集任务。
+type ECSMCronJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ECSMCronJobSpec   `json:"spec,omitempty"`
+	Status ECSMCronJobStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMCronJobList 包含 ECSMCronJob 的列表
+type ECSMCronJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMCronJob `json:"items"`
+}
+
+// ECSMCronJobSpec 定义了定时作业的期望行为
+type ECSMCronJobSpec struct {
+	// Schedule 是标准的 5 字段 cron 表达式 (分 时 日 月 周)，也可以是 "@hourly"、"@daily" 等预定义的写法。
+	// 时间按 operator 所在主机的本地时区计算。
+	// +required
+	Schedule string `json:"schedule"`
+
+	// StartingDeadlineSeconds 是错过计划时间后仍然允许启动作业的最长时间，超过后这一次调度被跳过。
+	// 为空时没有限制。
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// ConcurrencyPolicy 指定了上一次创建的作业还在运行时如何处理新的调度，默认为 Allow
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Suspend 为 true 时不再创建新的作业，已经创建的作业不受影响
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// JobTemplate 是每次调度时创建的 ECSMJob 的模板
+	// +required
+	JobTemplate ECSMJobTemplateSpec `json:"jobTemplate"`
+
+	// SuccessfulJobsHistoryLimit 是保留的已完成作业的数量，默认为 3
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit 是保留的失败作业的数量，默认为 1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// ConcurrencyPolicy 描述了定时作业的并发策略
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent 允许多个作业同时运行
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent 在上一个作业结束之前跳过新的调度
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent 删除正在运行的作业，用新的作业替换它
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// ECSMJobTemplateSpec 描述了由 ECSMCronJob 创建的 ECSMJob
+type ECSMJobTemplateSpec struct {
+	// 作业的 labels 和 annotations，名称和命名空间由控制器决定
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec 是作业的 spec
+	// +required
+	Spec ECSMJobSpec `json:"spec"`
+}
+
+// ECSMCronJobStatus 定义了定时作业的观测状态
+type ECSMCronJobStatus struct {
+	// Active 是正在运行的作业
+	// +optional
+	Active []ObjectReference `json:"active,omitempty"`
+
+	// LastScheduleTime 是最近一次成功创建作业的计划时间
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime 是最近一次作业完成的时间
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+}
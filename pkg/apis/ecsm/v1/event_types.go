@@ -0,0 +1,86 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// EventTypeNormal 表示一切正常的、仅供参考的事件
+	EventTypeNormal string = "Normal"
+	// EventTypeWarning 表示可能需要关注的异常事件
+	EventTypeWarning string = "Warning"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Event 记录了控制器在处理某个对象时发生的一件值得关注的事情，
+// 例如扩容、创建失败、开始滚动更新等。相同的事件会被合并，并通过 Count 计数。
+type Event struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	// InvolvedObject 是该事件所描述的对象
+	InvolvedObject ObjectReference `json:"involvedObject"`
+
+	// Reason 是一个简短的、机器可读的事件原因，例如 "ScaledUp"
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message 是一段人类可读的事件描述
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Source 是产生该事件的组件
+	// +optional
+	Source EventSource `json:"source,omitempty"`
+
+	// FirstTimestamp 是该事件第一次发生的时间
+	// +optional
+	FirstTimestamp metav1.Time `json:"firstTimestamp,omitempty"`
+
+	// LastTimestamp 是该事件最近一次发生的时间
+	// +optional
+	LastTimestamp metav1.Time `json:"lastTimestamp,omitempty"`
+
+	// Count 是该事件发生的次数
+	// +optional
+	Count int32 `json:"count,omitempty"`
+
+	// Type 是事件的类型，Normal 或 Warning
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EventList 包含 Event 的列表
+type EventList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Event `json:"items"`
+}
+
+// ObjectReference 指向我们控制平面中的一个 API 对象
+type ObjectReference struct {
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	UID types.UID `json:"uid,omitempty"`
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// +optional
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// EventSource 描述了事件的来源
+type EventSource struct {
+	// Component 是产生事件的组件名称，例如 "ecsmservice-controller"
+	// +optional
+	Component string `json:"component,omitempty"`
+}
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMJob 在 ECSM 平台上运行一组执行到结束的容器，例如一次性的数据采集或迁移任务。
+// 控制器把它翻译成一个副本数为 completions 的平台服务：容器正常退出计为成功，
+// 失败或被 ECSM 重启计为失败，失败次数超过 backoffLimit 后作业失败。
+type ECSMJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ECSMJobSpec   `json:"spec,omitempty"`
+	Status ECSMJobStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMJobList 包含 ECSMJob 的列表
+type ECSMJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMJob `json:"items"`
+}
+
+// ECSMJobSpec 定义了作业的期望行为
+type ECSMJobSpec struct {
+	// Template 是运行作业的容器模板。
+	// 模板中的 platformSpecific.action 为 "Load" 时，容器只被创建而不会启动，需要在节点上手动触发。
+	// +required
+	Template ContainerTemplateSpec `json:"template"`
+
+	// NodePool 是可以运行作业容器的节点，由 ECSM 在其中放置容器
+	// +required
+	NodePool []string `json:"nodePool"`
+
+	// Completions 是作业完成所需的成功容器数量，默认为 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Completions *int32 `json:"completions,omitempty"`
+
+	// BackoffLimit 是作业被标记为失败之前允许的失败次数，默认为 6
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// ActiveDeadlineSeconds 是作业从开始运行起的最长时间，超时后作业的容器会被删除，作业失败。
+	// 为空时没有限制。
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
+// ECSMJob 的状况类型
+const (
+	// JobComplete 表示作业的成功容器数量达到了 completions
+	JobComplete = "Complete"
+	// JobFailed 表示作业的失败次数超过了 backoffLimit，或运行时间超过了 activeDeadlineSeconds
+	JobFailed = "Failed"
+)
+
+// ECSMJobStatus 定义了作业的观测状态
+type ECSMJobStatus struct {
+	// StartTime 是控制器开始运行作业的时间
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime 是作业完成的时间，只在作业成功时设置
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Active 是正在运行的容器数量
+	// +optional
+	Active int32 `json:"active,omitempty"`
+
+	// Succeeded 是正常退出的容器数量
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed 是失败的次数，包括失败退出的容器和容器被 ECSM 重启的次数
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// UnderlyingServiceID 是运行作业的平台服务的 ID
+	// +optional
+	UnderlyingServiceID string `json:"underlyingServiceID,omitempty"`
+
+	// Conditions 描述了作业的当前状况，例如 "Complete"
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
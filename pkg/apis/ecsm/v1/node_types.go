@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMNode 是 ECSM 平台上一个节点在控制平面中的镜像。
+// 它是集群级别的资源 (没有命名空间)，由 NodeController 根据平台上的节点自动创建和更新。
+// 节点的标签使用 metadata.labels，调度器根据服务的 nodeSelector 匹配它们。
+type ECSMNode struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ECSMNodeSpec   `json:"spec,omitempty"`
+	Status ECSMNodeStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMNodeList 包含 ECSMNode 的列表
+type ECSMNodeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMNode `json:"items"`
+}
+
+// ECSMNodeSpec 定义了节点的期望状态
+type ECSMNodeSpec struct {
+	// Address 是节点的访问地址
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// CredentialsRef 引用了保存节点登录密码的 Secret，密码本身不会保存在 ECSMNode 中。
+	// ECSM 在注册节点时需要它。
+	// +optional
+	CredentialsRef *NodeCredentialsReference `json:"credentialsRef,omitempty"`
+
+	// Taints 阻止不能容忍它们的服务被调度到该节点上
+	// +optional
+	Taints []NodeTaint `json:"taints,omitempty"`
+}
+
+// NodeCredentialsReference 引用了一个保存节点凭据的 Secret
+type NodeCredentialsReference struct {
+	// Name 是 Secret 的名称
+	// +required
+	Name string `json:"name"`
+
+	// Namespace 是 Secret 所在的命名空间，默认为 "default"
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key 是 Secret 中保存密码的键，默认为 "password"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// TaintEffect 定义了污点对不能容忍它的服务的影响
+type TaintEffect string
+
+const (
+	// TaintEffectNoSchedule 表示不能容忍该污点的服务不会被调度到节点上，
+	// 已经运行在节点上的实例不受影响
+	TaintEffectNoSchedule TaintEffect = "NoSchedule"
+	// TaintEffectPreferNoSchedule 表示调度器会尽量避免把不能容忍该污点的服务调度到节点上
+	TaintEffectPreferNoSchedule TaintEffect = "PreferNoSchedule"
+	// TaintEffectNoExecute 表示不能容忍该污点的服务不会被调度到节点上，
+	// 已经运行在节点上的实例也会被驱逐到其他节点，可用于在维护之前腾空节点。
+	// 驱逐只对 Dynamic 策略的服务和 ECSMNodeSet 生效，Static 策略的服务的节点由用户显式指定，不会被修改。
+	TaintEffectNoExecute TaintEffect = "NoExecute"
+)
+
+// NodeTaint 是节点上的一个污点
+type NodeTaint struct {
+	// Key 是污点的键
+	// +required
+	Key string `json:"key"`
+
+	// Value 是污点的值
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Effect 是污点的影响
+	// +kubebuilder:validation:Enum=NoSchedule;PreferNoSchedule;NoExecute
+	// +required
+	Effect TaintEffect `json:"effect"`
+}
+
+// ECSMNode 的状况类型
+const (
+	// NodeReady 表示节点在线，且控制器能持续收到它的心跳指标
+	NodeReady = "Ready"
+)
+
+// ECSMNodeStatus 定义了节点的观测状态
+type ECSMNodeStatus struct {
+	// NodeID 是节点在 ECSM 平台中的 ID
+	// +optional
+	NodeID string `json:"nodeID,omitempty"`
+
+	// Type 是节点的类型，例如操作系统
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Arch 是节点的 CPU 架构
+	// +optional
+	Arch string `json:"arch,omitempty"`
+
+	// EcsdVersion 是节点上运行的 ecsd 守护进程的版本
+	// +optional
+	EcsdVersion string `json:"ecsdVersion,omitempty"`
+
+	// Capacity 是节点的资源总量
+	// +optional
+	Capacity map[ResourceType]resource.Quantity `json:"capacity,omitempty"`
+
+	// Containers 是节点上容器数量的统计
+	// +optional
+	Containers NodeContainerCounts `json:"containers,omitempty"`
+
+	// LastHeartbeatTime 是控制器最近一次观察到节点上报新的指标的时间
+	// +optional
+	LastHeartbeatTime metav1.Time `json:"lastHeartbeatTime,omitempty"`
+
+	// Conditions 描述了节点的当前状况，例如 "Ready"
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// NodeContainerCounts 统计了节点上的容器数量
+type NodeContainerCounts struct {
+	// Total 是节点上所有容器的数量
+	Total int32 `json:"total"`
+	// Running 是节点上正在运行的容器数量
+	Running int32 `json:"running"`
+	// ManagedTotal 是由 ECSM 管理的容器数量
+	ManagedTotal int32 `json:"managedTotal"`
+	// ManagedRunning 是由 ECSM 管理且正在运行的容器数量
+	ManagedRunning int32 `json:"managedRunning"`
+}
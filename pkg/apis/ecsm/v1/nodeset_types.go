@@ -0,0 +1,71 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMNodeSet 保证每个满足 nodeSelector 的节点上恰好运行一个容器，类似于 Kubernetes 的 DaemonSet。
+// 与需要逐个列出节点名称的 Static 策略不同，节点加入或离开时，它运行的节点会自动随之变化。
+// 控制器为每个 ECSMNodeSet 维护一个同名的、Static 策略的 ECSMService，由后者完成实际的部署和滚动更新。
+type ECSMNodeSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ECSMNodeSetSpec   `json:"spec,omitempty"`
+	Status ECSMNodeSetStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMNodeSetList 包含 ECSMNodeSet 的列表
+type ECSMNodeSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMNodeSet `json:"items"`
+}
+
+// ECSMNodeSetSpec 定义了 ECSMNodeSet 的期望状态
+type ECSMNodeSetSpec struct {
+	// NodeSelector 是对节点标签 (ECSMNode 的 metadata.labels) 的要求，为空时选择所有节点
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations 允许容器运行在带有匹配的 NoSchedule 或 NoExecute 污点的节点上
+	// +optional
+	Tolerations []Toleration `json:"tolerations,omitempty"`
+
+	// Template 是在每个节点上运行的容器模板
+	// +required
+	Template ContainerTemplateSpec `json:"template"`
+
+	// UpgradeStrategy 是模板变化时替换容器的方式，会被原样传给 ECSMService
+	// +optional
+	UpgradeStrategy UpgradeStrategy `json:"upgradeStrategy,omitempty"`
+}
+
+// ECSMNodeSetStatus 定义了 ECSMNodeSet 的观测状态
+type ECSMNodeSetStatus struct {
+	// ObservedGeneration 是控制器最近一次处理的 spec 的 generation
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// DesiredNumberScheduled 是应该运行容器的节点数量
+	DesiredNumberScheduled int32 `json:"desiredNumberScheduled"`
+
+	// CurrentNumberScheduled 是已经运行着容器的节点数量
+	CurrentNumberScheduled int32 `json:"currentNumberScheduled"`
+
+	// NumberReady 是容器已经就绪的节点数量
+	NumberReady int32 `json:"numberReady"`
+
+	// Nodes 是当前选中的节点名称，按名称排序
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+
+	// ServiceName 是控制器为 ECSMNodeSet 维护的 ECSMService 的名称
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+}
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMNotification 把控制器记录的事件 (例如发布完成、服务降级、节点失联) 推送到一个外部的 HTTP webhook，
+// 例如 Slack 或钉钉的机器人。它是集群级别的资源，可以选择所有命名空间的事件。
+type ECSMNotification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ECSMNotificationSpec `json:"spec"`
+
+	// +optional
+	Status ECSMNotificationStatus `json:"status,omitempty"`
+}
+
+// ECSMNotificationSpec 定义了通知哪些事件以及如何发送
+type ECSMNotificationSpec struct {
+	// Selector 选择需要通知的事件，为空时选择所有事件
+	// +optional
+	Selector NotificationSelector `json:"selector,omitempty"`
+
+	// Webhook 是接收通知的地址
+	Webhook NotificationWebhook `json:"webhook"`
+
+	// Template 是消息文本的 Go text/template 模板，模板的数据是 Event 对象，例如 "{{.Reason}}: {{.Message}}"。
+	// 为空时使用包含事件类型、对象、原因和消息的默认模板。
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// MaxPerMinute 是每分钟最多发送的通知数量，超过的事件被丢弃并计入 status.dropped。
+	// 为 0 时使用默认值 10。
+	// +optional
+	MaxPerMinute int32 `json:"maxPerMinute,omitempty"`
+
+	// Suspend 为 true 时暂停发送，期间的事件不会在恢复后补发
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// NotificationSelector 选择事件。各个字段之间是 "与" 的关系，同一字段中的多个值之间是 "或" 的关系，空字段不做限制。
+type NotificationSelector struct {
+	// Types 是事件的类型，Normal 或 Warning
+	// +optional
+	Types []string `json:"types,omitempty"`
+
+	// Reasons 是事件的原因，例如 RolloutComplete、Degraded、NodeNotReady
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+
+	// Kinds 是事件所描述的对象的 kind，例如 ECSMService、ECSMNode
+	// +optional
+	Kinds []string `json:"kinds,omitempty"`
+
+	// Namespaces 是事件所在的命名空间，集群级对象 (例如 ECSMNode) 的事件没有命名空间，只在这个字段为空时被选择
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// NotificationWebhookType 决定通知请求体的格式
+type NotificationWebhookType string
+
+const (
+	// NotificationWebhookGeneric 发送 {"text": 消息, "event": 事件对象}
+	NotificationWebhookGeneric NotificationWebhookType = "Generic"
+	// NotificationWebhookSlack 发送 Slack incoming webhook 的格式 {"text": 消息}
+	NotificationWebhookSlack NotificationWebhookType = "Slack"
+	// NotificationWebhookDingTalk 发送钉钉机器人的文本消息 {"msgtype": "text", "text": {"content": 消息}}
+	NotificationWebhookDingTalk NotificationWebhookType = "DingTalk"
+)
+
+// NotificationWebhook 描述了接收通知的 HTTP webhook
+type NotificationWebhook struct {
+	// Type 为空时等同于 Generic
+	// +optional
+	Type NotificationWebhookType `json:"type,omitempty"`
+
+	// URL 是接收通知的地址，必须是 http 或 https
+	URL string `json:"url"`
+
+	// Headers 是附加在每个请求上的 HTTP 头，例如认证信息
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// TimeoutSeconds 是一次请求的超时时间，为 0 时使用 10 秒
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// ECSMNotificationStatus 记录了通知的发送情况
+type ECSMNotificationStatus struct {
+	// Sent 是成功发送的通知数量
+	// +optional
+	Sent int64 `json:"sent,omitempty"`
+
+	// Failed 是发送失败的通知数量，失败的通知不会重试
+	// +optional
+	Failed int64 `json:"failed,omitempty"`
+
+	// Dropped 是因为超过 maxPerMinute 或发送队列已满而被丢弃的通知数量
+	// +optional
+	Dropped int64 `json:"dropped,omitempty"`
+
+	// LastSentTime 是最近一次成功发送的时间
+	// +optional
+	LastSentTime *metav1.Time `json:"lastSentTime,omitempty"`
+
+	// LastError 是最近一次发送失败的原因，下一次发送成功后被清除
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMNotificationList 包含 ECSMNotification 的列表
+type ECSMNotificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMNotification `json:"items"`
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMPriorityClass 定义了一个优先级名称到整数优先级的映射，类似于 Kubernetes 的 PriorityClass。
+// 它是集群级别的资源，ECSMService 通过 spec.priorityClassName 引用它。
+// 节点资源紧张时，调度器允许高优先级的服务占用低优先级服务实例所使用的资源，
+// RemediationController 也会优先驱逐低优先级的实例，为失败的高优先级实例腾出资源。
+type ECSMPriorityClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Value 是优先级的值，值越大优先级越高
+	Value int32 `json:"value"`
+
+	// GlobalDefault 表示没有设置 priorityClassName 的服务使用这个优先级。
+	// 有多个 GlobalDefault 的优先级时，使用其中值最小的一个。
+	// +optional
+	GlobalDefault bool `json:"globalDefault,omitempty"`
+
+	// Description 是给用户看的说明，例如什么样的服务应该使用这个优先级
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMPriorityClassList 包含 ECSMPriorityClass 的列表
+type ECSMPriorityClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMPriorityClass `json:"items"`
+}
+
+// DefaultPriority 是没有设置 priorityClassName、也没有 GlobalDefault 的优先级时服务的优先级
+const DefaultPriority int32 = 0
@@ -27,6 +27,27 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&ECSMService{},
 		&ECSMServiceList{},
+		&ECSMServiceScale{},
+		&ECSMNode{},
+		&ECSMNodeList{},
+		&ECSMServiceAutoscaler{},
+		&ECSMServiceAutoscalerList{},
+		&ECSMJob{},
+		&ECSMJobList{},
+		&ECSMCronJob{},
+		&ECSMCronJobList{},
+		&ECSMNodeSet{},
+		&ECSMNodeSetList{},
+		&ECSMConfig{},
+		&ECSMConfigList{},
+		&ECSMSecret{},
+		&ECSMSecretList{},
+		&ECSMPriorityClass{},
+		&ECSMPriorityClassList{},
+		&ECSMNotification{},
+		&ECSMNotificationList{},
+		&Event{},
+		&EventList{},
 	)
 
 	// 这里注册通用的辅助性的元数据类型
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMServiceScale 是 ECSMService 的 scale 子资源，类似于 Kubernetes 的 autoscaling/v1 Scale。
+// 它只暴露服务的副本数，ecsm-cli scale 和自动扩缩容控制器通过它修改副本数，
+// 不需要读写、也不会覆盖 spec 中的其他字段。
+// 它的名称、命名空间和 resourceVersion 与所属的 ECSMService 相同。
+type ECSMServiceScale struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec 是期望的副本数
+	// +optional
+	Spec ScaleSpec `json:"spec,omitempty"`
+	// Status 是服务当前的副本数
+	// +optional
+	Status ScaleStatus `json:"status,omitempty"`
+}
+
+// ScaleSpec 描述了期望的副本数。
+type ScaleSpec struct {
+	// Replicas 是期望的副本数，对应 spec.deploymentStrategy.replicas
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+// ScaleStatus 描述了服务当前的副本数。
+type ScaleStatus struct {
+	// Replicas 是服务当前的容器实例数，对应 status.replicas
+	Replicas int32 `json:"replicas"`
+	// ReadyReplicas 是服务当前就绪的容器实例数，对应 status.readyReplicas
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+}
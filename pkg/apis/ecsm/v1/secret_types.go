@@ -0,0 +1,53 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMSecret 保存密码、令牌等敏感数据，类似于 Kubernetes 的 Secret。
+// 它在 Registry 中加密存储，operator 必须配置加密密钥才能创建和读取它。
+// 容器模板可以通过 envFrom、env[].valueFrom 和 vsoa.passwordFrom 引用同一命名空间中的 ECSMSecret，
+// ecsm-cli 只显示它的键，从不显示值。
+type ECSMSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Data 是敏感数据的内容
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMSecretList 包含 ECSMSecret 的列表
+type ECSMSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMSecret `json:"items"`
+}
+
+// SecretReference 引用了同一命名空间中的一个 ECSMSecret
+type SecretReference struct {
+	// Name 是 ECSMSecret 的名称
+	Name string `json:"name"`
+
+	// Optional 为 true 时，ECSMSecret 不存在也不会报错
+	// +optional
+	Optional *bool `json:"optional,omitempty"`
+}
+
+// SecretKeySelector 选择了同一命名空间中 ECSMSecret 的一个键
+type SecretKeySelector struct {
+	// Name 是 ECSMSecret 的名称
+	Name string `json:"name"`
+
+	// Key 是要读取的键
+	Key string `json:"key"`
+
+	// Optional 为 true 时，ECSMSecret 或键不存在也不会报错，引用会被忽略
+	// +optional
+	Optional *bool `json:"optional,omitempty"`
+}
@@ -1,6 +1,9 @@
 package v1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -23,8 +26,31 @@ type ECSMServiceList struct {
 	Items           []ECSMService `json:"items"`
 }
 
+// ServiceCleanupFinalizer 由控制器添加到每个 ECSMService 上。
+// 在它被移除之前，Registry 只会标记删除而不会真正删除对象，
+// 以保证控制器有机会先清理掉 ECSM 平台上对应的服务。
+const ServiceCleanupFinalizer = "ecsm.sh/platform-service-cleanup"
+
+// DryRunAnnotation 设置为 "true" 时，控制器只计算并记录它将要对该服务执行的操作，
+// 而不会真正修改 ECSM 平台。计划的操作会写入 status.plannedActions 并记录为 DryRun 事件。
+const DryRunAnnotation = "ecsm.sh/dry-run"
+
+// ClusterAnnotation 指定了负责该服务的 ECSM 集群的名称，与 spec.cluster 作用相同。
+// 两者同时设置时以 spec.cluster 为准。
+const ClusterAnnotation = "ecsm.sh/cluster"
+
+// PreviousTemplateAnnotation 由 Registry 在 spec.template 变化时写入，记录变化之前的模板 (JSON)，
+// 供 "ecsm-cli rollout undo" 回退到上一个修订版本。
+const PreviousTemplateAnnotation = "ecsm.sh/previous-template"
+
 // ECSMServiceSpec 定义了ECSM服务的期望状态
 type ECSMServiceSpec struct {
+	// Cluster 是负责该服务的 ECSM 集群的名称，它必须是 operator 配置中的一个集群。
+	// 为空时使用 ClusterAnnotation，两者都为空时使用默认集群。
+	// 服务被调谐过之后不能再更换集群。
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+
 	// 定义了服务的部署策略，决定了容器实例如何分布在节点上
 	// +required
 	DeploymentStrategy DeploymentStrategy `json:"deploymentStrategy"`
@@ -36,8 +62,116 @@ type ECSMServiceSpec struct {
 	// Template 是创建新容器实例的关键模版
 	// +required
 	Template ContainerTemplateSpec `json:"template"`
+
+	// Selector 是一组平台服务标签 (key=value)。ECSM 平台上已存在的、
+	// 不属于任何 ECSMService 且带有全部这些标签的服务，会被该 ECSMService 认领，而不是重复创建。
+	// 与服务同名或符合控制器命名规则 ("<name>-<hash>"，default 之外的命名空间在中间加上命名空间的哈希) 的平台服务总是会被认领。
+	// +optional
+	Selector map[string]string `json:"selector,omitempty"`
+
+	// DriftPolicy 决定了当有人绕过 operator 直接在 ECSM 平台上修改服务 (例如在控制台中扩缩容或更换镜像) 时，
+	// 控制器的处理方式。默认为 "Enforce"。
+	// +kubebuilder:validation:Enum=Enforce;ReportOnly
+	// +optional
+	DriftPolicy DriftPolicyType `json:"driftPolicy,omitempty"`
+
+	// Remediation 定义了容器反复失败时，在 ECSM 自身的重启机制之外控制器采取的补救措施。
+	// 为空时控制器不干预。
+	// +optional
+	Remediation *RemediationPolicy `json:"remediation,omitempty"`
+
+	// Affinity 定义了服务实例与其他服务实例之间的亲和与反亲和。
+	// 它只对默认集群中 Dynamic 策略的服务生效，由调度器在选择节点时遵守。
+	// +optional
+	Affinity *Affinity `json:"affinity,omitempty"`
+
+	// PriorityClassName 引用一个 ECSMPriorityClass，决定服务在节点资源紧张时的优先级。
+	// 为空时使用 GlobalDefault 的优先级，引用的优先级不存在时按默认优先级处理。
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// Affinity 是服务之间的调度约束。所有约束都是硬性的，无法满足时调度失败。
+type Affinity struct {
+	// ServiceAffinity 要求实例只被调度到已经运行着匹配服务实例的拓扑域中。
+	// 匹配的服务都还没有实例、且服务自身也匹配时，该约束被忽略，以便一组互相亲和的服务中的第一个能够被调度。
+	// +optional
+	ServiceAffinity []ServiceAffinityTerm `json:"serviceAffinity,omitempty"`
+
+	// ServiceAntiAffinity 要求实例不被调度到运行着匹配服务实例的拓扑域中。
+	// 服务自身也匹配时，它的各个实例会被分散到不同的拓扑域中，拓扑域不足时调度失败。
+	// 反亲和只约束声明它的服务，不会阻止其他服务被调度到它的实例旁边。
+	// +optional
+	ServiceAntiAffinity []ServiceAffinityTerm `json:"serviceAntiAffinity,omitempty"`
+}
+
+// ServiceAffinityTerm 选择一组服务，以及判断两个实例是否"在一起"的拓扑。
+type ServiceAffinityTerm struct {
+	// LabelSelector 根据 ECSMService 的 metadata.labels 选择服务，为空时不选择任何服务
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// Namespaces 是被选择的服务所在的命名空间，为空时为该服务自身所在的命名空间
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// TopologyKey 是 ECSMNode 的标签键，这个标签的值相同的节点属于同一个拓扑域。
+	// 为空时每个节点自成一个拓扑域。没有这个标签的节点不会被选中。
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+// RemediationActionType 定义了容器反复失败时的补救措施
+type RemediationActionType string
+
+const (
+	// RemediationActionRestart 重启失败的容器
+	RemediationActionRestart RemediationActionType = "Restart"
+	// RemediationActionReschedule 把失败的容器调度到节点池中的其他节点。
+	// 只对 Dynamic 策略生效，其他情况下退化为 Restart。
+	RemediationActionReschedule RemediationActionType = "Reschedule"
+	// RemediationActionNone 不做补救，只把服务标记为 Degraded
+	RemediationActionNone RemediationActionType = "None"
+)
+
+// RemediationPolicy 定义了容器的健康补救策略
+type RemediationPolicy struct {
+	// Action 是容器失败次数达到 FailureThreshold 时采取的措施。默认为 "Restart"。
+	// +kubebuilder:validation:Enum=Restart;Reschedule;None
+	// +optional
+	Action RemediationActionType `json:"action,omitempty"`
+
+	// FailureThreshold 是触发一次补救所需的失败次数。
+	// 容器的重启次数每增加一次，或控制器每次观察到容器没有在运行，都计为一次失败。默认为 3。
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// MaxRemediations 是同一个容器最多被补救的次数。
+	// 超过之后容器再次达到失败阈值时，服务会被标记为 Degraded，而不再继续补救。默认为 3。
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRemediations *int32 `json:"maxRemediations,omitempty"`
 }
 
+// ECSMService 的状况类型
+const (
+	// ServiceDegraded 表示服务中有容器持续失败，且补救措施已经用尽
+	ServiceDegraded = "Degraded"
+	// ServiceAvailable 表示服务的就绪副本数已经达到期望的副本数
+	ServiceAvailable = "Available"
+)
+
+// DriftPolicyType 定义了发现平台服务被带外修改时的处理方式
+type DriftPolicyType string
+
+const (
+	// DriftPolicyEnforce 会记录 DriftDetected 事件，并把平台服务恢复为声明的状态
+	DriftPolicyEnforce DriftPolicyType = "Enforce"
+	// DriftPolicyReportOnly 只记录 DriftDetected 事件，不修改平台服务
+	DriftPolicyReportOnly DriftPolicyType = "ReportOnly"
+)
+
 // ECSMServiceStatus 定义了 ECSMService 的状态
 type ECSMServiceStatus struct {
 	// Replicas 是在 ECSM 平台上实际找到的、属于此服务的容器实例总数。
@@ -48,6 +182,11 @@ type ECSMServiceStatus struct {
 	// 从查询 API 的 `instanceOnline` 字段获取。
 	ReadyReplicas int32 `json:"readyReplicas"`
 
+	// UpdatedReplicas 是已经运行着当前 spec.template 的容器实例数量。
+	// 滚动更新期间，该值会从 0 逐步增长到期望副本数。
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
 	// ObservedGeneration 是控制器最近一次处理的 ECSMService.metadata.generation。
 	// 这对于区分 spec 变更前后的状态非常重要。
 	// +optional
@@ -63,6 +202,54 @@ type ECSMServiceStatus struct {
 	// 从查询 API 的 `id` 字段获取。
 	// +optional
 	UnderlyingServiceID string `json:"underlyingServiceID,omitempty"`
+
+	// Cluster 是控制器调谐该服务时所使用的 ECSM 集群的名称
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+
+	// PendingTransactions 是控制器已经提交、但在 ECSM 平台上尚未完成的异步事务。
+	// 在它们完成之前，控制器不会基于平台的状态做出新的决策。
+	// +optional
+	PendingTransactions []PendingTransaction `json:"pendingTransactions,omitempty"`
+
+	// Rollout 记录了正在进行的 Canary 或蓝绿发布的进度，发布完成后被清除
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// PlannedActions 是 dry-run 模式下，控制器在最近一次调谐中本应执行、但被跳过的操作。
+	// 关闭 dry-run 后，这些操作会被真正执行。
+	// +optional
+	PlannedActions []string `json:"plannedActions,omitempty"`
+}
+
+// RolloutStatus 描述了一次 Canary 或蓝绿发布的进度
+type RolloutStatus struct {
+	// Revision 是正在发布的修订版本的模板哈希
+	Revision string `json:"revision"`
+
+	// Step 是 Canary 发布当前所处的步骤，从 0 开始
+	// +optional
+	Step int32 `json:"step,omitempty"`
+
+	// ReadyTime 是当前步骤的新实例全部就绪的时间，用于计算暂停和验证窗口
+	// +optional
+	ReadyTime *metav1.Time `json:"readyTime,omitempty"`
+}
+
+// PendingTransaction 描述了一个由控制器提交的 ECSM 异步事务
+type PendingTransaction struct {
+	// ID 是 ECSM 返回的事务 ID
+	ID string `json:"id"`
+
+	// Operation 是提交的操作，例如 "Delete"
+	Operation string `json:"operation"`
+
+	// Target 是该操作作用的平台服务名称
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// SubmittedAt 是事务提交的时间
+	SubmittedAt metav1.Time `json:"submittedAt"`
 }
 
 type DeploymentStrategyType string
@@ -95,6 +282,47 @@ type DeploymentStrategy struct {
 	// NodePool 是在动态策略下指定的节点池
 	// +optional
 	NodePool []string `json:"nodePool,omitempty"`
+
+	// NodeSelector 是动态策略下对节点标签 (ECSMNode 的 metadata.labels) 的要求。
+	// 只有带有全部这些标签的节点才会被调度器选中。与 NodePool 不同，之后注册的、带有匹配标签的节点会被自动纳入选择范围。
+	// 同时设置 NodePool 时，节点必须同时在节点池中并满足标签要求。
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations 允许调度器把服务调度到带有匹配污点的节点上，并使服务的实例不会因为匹配的 NoExecute 污点被驱逐
+	// +optional
+	Tolerations []Toleration `json:"tolerations,omitempty"`
+}
+
+// TolerationOperator 定义了容忍与污点的匹配方式
+type TolerationOperator string
+
+const (
+	// TolerationOpEqual 要求污点的键和值都与容忍相同
+	TolerationOpEqual TolerationOperator = "Equal"
+	// TolerationOpExists 只要求污点的键与容忍相同
+	TolerationOpExists TolerationOperator = "Exists"
+)
+
+// Toleration 表示服务能够容忍匹配的节点污点
+type Toleration struct {
+	// Key 是要容忍的污点的键，为空且 Operator 为 Exists 时容忍所有污点
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// Operator 是匹配方式，默认为 "Equal"
+	// +kubebuilder:validation:Enum=Equal;Exists
+	// +optional
+	Operator TolerationOperator `json:"operator,omitempty"`
+
+	// Value 是 Operator 为 Equal 时要容忍的污点的值
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Effect 是要容忍的污点的影响，为空时容忍所有影响
+	// +kubebuilder:validation:Enum=NoSchedule;PreferNoSchedule;NoExecute
+	// +optional
+	Effect TaintEffect `json:"effect,omitempty"`
 }
 
 type UpgradeStrategyType string
@@ -115,6 +343,76 @@ type UpgradeStrategy struct {
 	// +kubebuilder:validation:Enum=Never;Larger;Always
 	// +optional
 	Type UpgradeStrategyType `json:"type,omitempty"`
+
+	// MaxUnavailable 是滚动更新过程中允许不可用的最大实例数。
+	// 可以是绝对数值 (例如 1) 或期望副本数的百分比 (例如 "25%")，百分比向下取整。
+	// 当 MaxSurge 为 0 时，此字段不能为 0。默认为 "25%"。
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MaxSurge 是滚动更新过程中允许超出期望副本数的最大实例数。
+	// 可以是绝对数值 (例如 1) 或期望副本数的百分比 (例如 "25%")，百分比向上取整。
+	// 当 MaxUnavailable 为 0 时，此字段不能为 0。默认为 "25%"。
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// Rollout 决定了模板变化时新旧修订版本如何交替。默认为 "RollingUpdate"。
+	// MaxSurge 和 MaxUnavailable 只对 RollingUpdate 生效。
+	// +kubebuilder:validation:Enum=RollingUpdate;Canary;BlueGreen
+	// +optional
+	Rollout RolloutType `json:"rollout,omitempty"`
+
+	// Canary 是 Canary 发布的步骤，仅在 Rollout 为 "Canary" 时使用
+	// +optional
+	Canary *CanaryStrategy `json:"canary,omitempty"`
+
+	// BlueGreen 是蓝绿发布的参数，仅在 Rollout 为 "BlueGreen" 时使用
+	// +optional
+	BlueGreen *BlueGreenStrategy `json:"blueGreen,omitempty"`
+}
+
+// RolloutType 定义了新旧修订版本的交替方式
+type RolloutType string
+
+const (
+	// RolloutTypeRollingUpdate 在 maxSurge 和 maxUnavailable 的限制下逐步用新实例替换旧实例
+	RolloutTypeRollingUpdate RolloutType = "RollingUpdate"
+	// RolloutTypeCanary 按步骤把新修订版本的实例比例提升到 100%，
+	// 每一步都要等新实例全部就绪、并经过可选的暂停之后才会继续
+	RolloutTypeCanary RolloutType = "Canary"
+	// RolloutTypeBlueGreen 在旧修订版本旁边创建完整副本数的新修订版本，
+	// 新实例全部就绪并经过可选的验证窗口之后，一次性删除旧修订版本
+	RolloutTypeBlueGreen RolloutType = "BlueGreen"
+)
+
+// CanaryStrategy 定义了 Canary 发布的步骤
+type CanaryStrategy struct {
+	// Steps 是按顺序执行的发布步骤，它们的 Weight 必须严格递增。
+	// 最后一步的 Weight 小于 100 时，会在最后隐式地追加一个 100% 的步骤。
+	// 为空时使用 20%、50%、100% 三个步骤。
+	// +optional
+	Steps []CanaryStep `json:"steps,omitempty"`
+}
+
+// CanaryStep 是 Canary 发布中的一步
+type CanaryStep struct {
+	// Weight 是这一步中新修订版本的实例占期望副本数的百分比，向上取整
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +required
+	Weight int32 `json:"weight"`
+
+	// PauseSeconds 是这一步的新实例全部就绪之后，进入下一步之前的观察时间
+	// +optional
+	PauseSeconds *int32 `json:"pauseSeconds,omitempty"`
+}
+
+// BlueGreenStrategy 定义了蓝绿发布的参数
+type BlueGreenStrategy struct {
+	// PromotionDelaySeconds 是新修订版本全部就绪之后、删除旧修订版本之前的验证时间。
+	// 验证期间新实例出现不可用时会重新计时。默认为 0，即就绪后立即切换。
+	// +optional
+	PromotionDelaySeconds *int32 `json:"promotionDelaySeconds,omitempty"`
 }
 
 type ImagePullPolicyType string
@@ -154,6 +452,11 @@ type ContainerTemplateSpec struct {
 	// +optional
 	Env []EnvVar `json:"env,omitempty"`
 
+	// EnvFrom 把 ECSMConfig 或 ECSMSecret 中的全部键值注入为环境变量。
+	// 多个来源中重复的名称以后出现的为准，env 中显式声明的变量优先于 envFrom。
+	// +optional
+	EnvFrom []EnvFromSource `json:"envFrom,omitempty"`
+
 	// Resources 定义了容器的资源请求和限制。
 	// +optional
 	Resources *ResourceRequirements `json:"resources,omitempty"`
@@ -166,6 +469,15 @@ type ContainerTemplateSpec struct {
 	// +optional
 	VSOA *VSOASpec `json:"vsoa,omitempty"`
 
+	// LivenessProbe 周期性地检查容器是否存活。连续失败达到阈值时容器被视为失败，
+	// 由 RemediationController 按照 spec.remediation 处理，未声明补救策略时容器被重启。
+	// +optional
+	LivenessProbe *Probe `json:"livenessProbe,omitempty"`
+
+	// ReadinessProbe 周期性地检查容器是否能够提供服务，未就绪的容器不计入 status.readyReplicas。
+	// +optional
+	ReadinessProbe *Probe `json:"readinessProbe,omitempty"`
+
 	// PlatformSpecific 是一个"逃生舱口"，用于设置平台特有的、不常用的底层配置。
 	// 普通用户通常不需要关心此部分。
 	// +optional
@@ -178,6 +490,9 @@ type EnvVar struct {
 	Name string `json:"name"`
 	// Value 是环境变量的值。
 	Value string `json:"value"`
+	// ValueFrom 从 ECSMConfig 或 ECSMSecret 中读取环境变量的值，设置后 Value 被忽略。
+	// +optional
+	ValueFrom *EnvVarSource `json:"valueFrom,omitempty"`
 }
 
 type ResourceType string
@@ -203,6 +518,11 @@ type VolumeMount struct {
 	// HostPath 是主机上的路径，容器将在此路径下挂载卷。
 	HostPath string `json:"hostPath"`
 
+	// HostPathFrom 从 ECSMConfig 中读取主机上的路径，设置后 HostPath 被忽略。
+	// 适用于不同站点的数据目录不同的场景。
+	// +optional
+	HostPathFrom *ConfigKeySelector `json:"hostPathFrom,omitempty"`
+
 	// ContainerPath 是容器内的目标路径
 	ContainerPath string `json:"containerPath"`
 
@@ -216,6 +536,9 @@ type VSOASpec struct {
 	// Password 是 VSOA 服务的密码
 	// +optional
 	Password string `json:"password,omitempty"`
+	// PasswordFrom 从 ECSMSecret 中读取 VSOA 服务的密码，设置后 Password 被忽略
+	// +optional
+	PasswordFrom *SecretKeySelector `json:"passwordFrom,omitempty"`
 	// Port 是 VSOA 监听的端口
 	// 如果为0.表示由ECSM动态分配
 	// +optional
@@ -240,6 +563,50 @@ type HealthCheckSpec struct {
 	FailureThreshold int32 `json:"failureThreshold,omitempty"`
 }
 
+// Probe 描述了对容器的一种检查方式，以及检查的时间参数。
+type Probe struct {
+	// ProbeHandler 是检查的方式，必须且只能设置其中一种
+	ProbeHandler `json:",inline"`
+	// HealthCheckSpec 是检查的时间参数和失败阈值
+	HealthCheckSpec `json:",inline"`
+}
+
+// ProbeHandler 定义了检查容器的方式。
+type ProbeHandler struct {
+	// VSOA 使用 ECSM 内置的 VSOA 健康检查，要求容器模板配置了 vsoa。
+	// 检查由 ECSM 执行，ECSM 会原地重启检查失败的容器。
+	// +optional
+	VSOA *VSOAProbeAction `json:"vsoa,omitempty"`
+	// TCPSocket 检查容器的一个 TCP 端口能否建立连接，由控制器执行。
+	// +optional
+	TCPSocket *TCPSocketAction `json:"tcpSocket,omitempty"`
+	// Exec 在容器中执行命令，命令退出码为 0 时视为成功。
+	// ECSM 的 API 不提供在容器中执行命令的接口，需要部署方为控制器提供执行命令的方式，否则检查不会执行。
+	// +optional
+	Exec *ExecAction `json:"exec,omitempty"`
+}
+
+// VSOAProbeAction 描述了一个 VSOA 健康检查。
+type VSOAProbeAction struct {
+	// Path 是 VSOA 服务中用于健康检查的 URL 路径，为空时使用 ECSM 的默认路径
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// TCPSocketAction 描述了一个 TCP 连接检查。
+type TCPSocketAction struct {
+	// Port 是要连接的容器端口
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+}
+
+// ExecAction 描述了一个在容器中执行的命令。
+type ExecAction struct {
+	// Command 是要执行的命令及其参数，不经过 shell 解释
+	Command []string `json:"command"`
+}
+
 type ActionType string
 
 const (
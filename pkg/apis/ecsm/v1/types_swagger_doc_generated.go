@@ -0,0 +1,943 @@
+/*
+Copyright 2024 The ecsm-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by genswaggertypedocs. DO NOT EDIT.
+
+package v1
+
+var map_AutoscalerBehavior = map[string]string{
+	"":          "AutoscalerBehavior 配置了扩容和缩容的行为",
+	"scaleUp":   "ScaleUp 是扩容的规则，默认没有稳定窗口",
+	"scaleDown": "ScaleDown 是缩容的规则，默认稳定窗口为 300 秒",
+}
+
+func (AutoscalerBehavior) SwaggerDoc() map[string]string {
+	return map_AutoscalerBehavior
+}
+
+var map_AutoscalerMetric = map[string]string{
+	"":                         "AutoscalerMetric 定义了一个资源指标及其目标值",
+	"resource":                 "Resource 是指标对应的资源",
+	"targetAverageUtilization": "TargetAverageUtilization 是所有运行中容器的平均使用率 (百分比) 的目标值。 内存使用率相对于容器的内存限制计算。",
+}
+
+func (AutoscalerMetric) SwaggerDoc() map[string]string {
+	return map_AutoscalerMetric
+}
+
+var map_AutoscalerMetricStatus = map[string]string{
+	"":                          "AutoscalerMetricStatus 是一项指标的观测值",
+	"resource":                  "Resource 是指标对应的资源",
+	"currentAverageUtilization": "CurrentAverageUtilization 是所有运行中容器的平均使用率 (百分比)",
+}
+
+func (AutoscalerMetricStatus) SwaggerDoc() map[string]string {
+	return map_AutoscalerMetricStatus
+}
+
+var map_AutoscalerScalingRules = map[string]string{
+	"":                           "AutoscalerScalingRules 定义了一个方向上的扩缩容规则",
+	"stabilizationWindowSeconds": "StabilizationWindowSeconds 是稳定窗口的长度。控制器会参考窗口内所有的推荐副本数： 扩容时取其中最小的，缩容时取其中最大的，以避免指标抖动导致副本数反复变化。",
+}
+
+func (AutoscalerScalingRules) SwaggerDoc() map[string]string {
+	return map_AutoscalerScalingRules
+}
+
+var map_ECSMServiceAutoscaler = map[string]string{
+	"": "ECSMServiceAutoscaler 根据容器的 CPU 和内存使用率，自动调整同一命名空间中某个 ECSMService 的副本数。 它的作用与 Kubernetes 的 HorizontalPodAutoscaler 类似，但只支持 Dynamic 部署策略的服务。",
+}
+
+func (ECSMServiceAutoscaler) SwaggerDoc() map[string]string {
+	return map_ECSMServiceAutoscaler
+}
+
+var map_ECSMServiceAutoscalerList = map[string]string{
+	"": "ECSMServiceAutoscalerList 包含 ECSMServiceAutoscaler 的列表",
+}
+
+func (ECSMServiceAutoscalerList) SwaggerDoc() map[string]string {
+	return map_ECSMServiceAutoscalerList
+}
+
+var map_ECSMServiceAutoscalerSpec = map[string]string{
+	"":               "ECSMServiceAutoscalerSpec 定义了自动扩缩容的期望行为",
+	"scaleTargetRef": "ScaleTargetRef 是被扩缩容的 ECSMService 的名称，它必须与自动扩缩容对象在同一个命名空间中",
+	"minReplicas":    "MinReplicas 是副本数的下限，默认为 1",
+	"maxReplicas":    "MaxReplicas 是副本数的上限，不能小于 MinReplicas",
+	"metrics":        "Metrics 是用于计算期望副本数的指标。存在多个指标时，取计算结果中最大的副本数。",
+	"behavior":       "Behavior 配置了扩容和缩容的稳定窗口",
+}
+
+func (ECSMServiceAutoscalerSpec) SwaggerDoc() map[string]string {
+	return map_ECSMServiceAutoscalerSpec
+}
+
+var map_ECSMServiceAutoscalerStatus = map[string]string{
+	"":                   "ECSMServiceAutoscalerStatus 定义了自动扩缩容的观测状态",
+	"observedGeneration": "ObservedGeneration 是控制器最近一次处理的 metadata.generation",
+	"currentReplicas":    "CurrentReplicas 是控制器最近一次观察到的目标服务的副本数",
+	"desiredReplicas":    "DesiredReplicas 是控制器最近一次计算出的期望副本数",
+	"lastScaleTime":      "LastScaleTime 是控制器最近一次修改目标服务副本数的时间",
+	"currentMetrics":     "CurrentMetrics 是控制器最近一次观察到的各项指标的值",
+	"conditions":         "Conditions 描述了自动扩缩容的当前状况，例如 \"AbleToScale\"",
+}
+
+func (ECSMServiceAutoscalerStatus) SwaggerDoc() map[string]string {
+	return map_ECSMServiceAutoscalerStatus
+}
+
+var map_ConfigKeySelector = map[string]string{
+	"":         "ConfigKeySelector 选择了同一命名空间中 ECSMConfig 的一个键",
+	"name":     "Name 是 ECSMConfig 的名称",
+	"key":      "Key 是要读取的键",
+	"optional": "Optional 为 true 时，ECSMConfig 或键不存在也不会报错，引用会被忽略",
+}
+
+func (ConfigKeySelector) SwaggerDoc() map[string]string {
+	return map_ConfigKeySelector
+}
+
+var map_ConfigReference = map[string]string{
+	"":         "ConfigReference 引用了同一命名空间中的一个 ECSMConfig",
+	"name":     "Name 是 ECSMConfig 的名称",
+	"optional": "Optional 为 true 时，ECSMConfig 不存在也不会报错",
+}
+
+func (ConfigReference) SwaggerDoc() map[string]string {
+	return map_ConfigReference
+}
+
+var map_ECSMConfig = map[string]string{
+	"":     "ECSMConfig 保存一组非敏感的键值配置，类似于 Kubernetes 的 ConfigMap。 容器模板可以通过 envFrom、env[].valueFrom 和 volumeMounts[].hostPathFrom 引用同一命名空间中的 ECSMConfig， 控制器在部署前把引用替换为配置的值。配置的值变化后，引用它的服务会被滚动更新。",
+	"data": "Data 是配置的内容",
+}
+
+func (ECSMConfig) SwaggerDoc() map[string]string {
+	return map_ECSMConfig
+}
+
+var map_ECSMConfigList = map[string]string{
+	"": "ECSMConfigList 包含 ECSMConfig 的列表",
+}
+
+func (ECSMConfigList) SwaggerDoc() map[string]string {
+	return map_ECSMConfigList
+}
+
+var map_EnvFromSource = map[string]string{
+	"":          "EnvFromSource 把一个 ECSMConfig 或 ECSMSecret 中的所有键值注入为环境变量",
+	"prefix":    "Prefix 会被加在每个环境变量名称的前面",
+	"configRef": "ConfigRef 引用了同一命名空间中的 ECSMConfig",
+	"secretRef": "SecretRef 引用了同一命名空间中的 ECSMSecret",
+}
+
+func (EnvFromSource) SwaggerDoc() map[string]string {
+	return map_EnvFromSource
+}
+
+var map_EnvVarSource = map[string]string{
+	"":             "EnvVarSource 描述了环境变量的值的来源",
+	"configKeyRef": "ConfigKeyRef 从 ECSMConfig 的一个键中读取值",
+	"secretKeyRef": "SecretKeyRef 从 ECSMSecret 的一个键中读取值",
+}
+
+func (EnvVarSource) SwaggerDoc() map[string]string {
+	return map_EnvVarSource
+}
+
+var map_ECSMCronJob = map[string]string{
+	"": "ECSMCronJob 按照 cron 表达式周期性地创建 ECSMJob，例如在边缘节点上定时执行的数据采集任务。",
+}
+
+func (ECSMCronJob) SwaggerDoc() map[string]string {
+	return map_ECSMCronJob
+}
+
+var map_ECSMCronJobList = map[string]string{
+	"": "ECSMCronJobList 包含 ECSMCronJob 的列表",
+}
+
+func (ECSMCronJobList) SwaggerDoc() map[string]string {
+	return map_ECSMCronJobList
+}
+
+var map_ECSMCronJobSpec = map[string]string{
+	"":                           "ECSMCronJobSpec 定义了定时作业的期望行为",
+	"schedule":                   "Schedule 是标准的 5 字段 cron 表达式 (分 时 日 月 周)，也可以是 \"@hourly\"、\"@daily\" 等预定义的写法。 时间按 operator 所在主机的本地时区计算。",
+	"startingDeadlineSeconds":    "StartingDeadlineSeconds 是错过计划时间后仍然允许启动作业的最长时间，超过后这一次调度被跳过。 为空时没有限制。",
+	"concurrencyPolicy":          "ConcurrencyPolicy 指定了上一次创建的作业还在运行时如何处理新的调度，默认为 Allow",
+	"suspend":                    "Suspend 为 true 时不再创建新的作业，已经创建的作业不受影响",
+	"jobTemplate":                "JobTemplate 是每次调度时创建的 ECSMJob 的模板",
+	"successfulJobsHistoryLimit": "SuccessfulJobsHistoryLimit 是保留的已完成作业的数量，默认为 3",
+	"failedJobsHistoryLimit":     "FailedJobsHistoryLimit 是保留的失败作业的数量，默认为 1",
+}
+
+func (ECSMCronJobSpec) SwaggerDoc() map[string]string {
+	return map_ECSMCronJobSpec
+}
+
+var map_ECSMCronJobStatus = map[string]string{
+	"":                   "ECSMCronJobStatus 定义了定时作业的观测状态",
+	"active":             "Active 是正在运行的作业",
+	"lastScheduleTime":   "LastScheduleTime 是最近一次成功创建作业的计划时间",
+	"lastSuccessfulTime": "LastSuccessfulTime 是最近一次作业完成的时间",
+}
+
+func (ECSMCronJobStatus) SwaggerDoc() map[string]string {
+	return map_ECSMCronJobStatus
+}
+
+var map_ECSMJobTemplateSpec = map[string]string{
+	"":         "ECSMJobTemplateSpec 描述了由 ECSMCronJob 创建的 ECSMJob",
+	"metadata": "作业的 labels 和 annotations，名称和命名空间由控制器决定",
+	"spec":     "Spec 是作业的 spec",
+}
+
+func (ECSMJobTemplateSpec) SwaggerDoc() map[string]string {
+	return map_ECSMJobTemplateSpec
+}
+
+var map_Event = map[string]string{
+	"":               "Event 记录了控制器在处理某个对象时发生的一件值得关注的事情， 例如扩容、创建失败、开始滚动更新等。相同的事件会被合并，并通过 Count 计数。",
+	"involvedObject": "InvolvedObject 是该事件所描述的对象",
+	"reason":         "Reason 是一个简短的、机器可读的事件原因，例如 \"ScaledUp\"",
+	"message":        "Message 是一段人类可读的事件描述",
+	"source":         "Source 是产生该事件的组件",
+	"firstTimestamp": "FirstTimestamp 是该事件第一次发生的时间",
+	"lastTimestamp":  "LastTimestamp 是该事件最近一次发生的时间",
+	"count":          "Count 是该事件发生的次数",
+	"type":           "Type 是事件的类型，Normal 或 Warning",
+}
+
+func (Event) SwaggerDoc() map[string]string {
+	return map_Event
+}
+
+var map_EventList = map[string]string{
+	"": "EventList 包含 Event 的列表",
+}
+
+func (EventList) SwaggerDoc() map[string]string {
+	return map_EventList
+}
+
+var map_EventSource = map[string]string{
+	"":          "EventSource 描述了事件的来源",
+	"component": "Component 是产生事件的组件名称，例如 \"ecsmservice-controller\"",
+}
+
+func (EventSource) SwaggerDoc() map[string]string {
+	return map_EventSource
+}
+
+var map_ObjectReference = map[string]string{
+	"": "ObjectReference 指向我们控制平面中的一个 API 对象",
+}
+
+func (ObjectReference) SwaggerDoc() map[string]string {
+	return map_ObjectReference
+}
+
+var map_ECSMJob = map[string]string{
+	"": "ECSMJob 在 ECSM 平台上运行一组执行到结束的容器，例如一次性的数据采集或迁移任务。 控制器把它翻译成一个副本数为 completions 的平台服务：容器正常退出计为成功， 失败或被 ECSM 重启计为失败，失败次数超过 backoffLimit 后作业失败。",
+}
+
+func (ECSMJob) SwaggerDoc() map[string]string {
+	return map_ECSMJob
+}
+
+var map_ECSMJobList = map[string]string{
+	"": "ECSMJobList 包含 ECSMJob 的列表",
+}
+
+func (ECSMJobList) SwaggerDoc() map[string]string {
+	return map_ECSMJobList
+}
+
+var map_ECSMJobSpec = map[string]string{
+	"":                      "ECSMJobSpec 定义了作业的期望行为",
+	"template":              "Template 是运行作业的容器模板。 模板中的 platformSpecific.action 为 \"Load\" 时，容器只被创建而不会启动，需要在节点上手动触发。",
+	"nodePool":              "NodePool 是可以运行作业容器的节点，由 ECSM 在其中放置容器",
+	"completions":           "Completions 是作业完成所需的成功容器数量，默认为 1",
+	"backoffLimit":          "BackoffLimit 是作业被标记为失败之前允许的失败次数，默认为 6",
+	"activeDeadlineSeconds": "ActiveDeadlineSeconds 是作业从开始运行起的最长时间，超时后作业的容器会被删除，作业失败。 为空时没有限制。",
+}
+
+func (ECSMJobSpec) SwaggerDoc() map[string]string {
+	return map_ECSMJobSpec
+}
+
+var map_ECSMJobStatus = map[string]string{
+	"":                    "ECSMJobStatus 定义了作业的观测状态",
+	"startTime":           "StartTime 是控制器开始运行作业的时间",
+	"completionTime":      "CompletionTime 是作业完成的时间，只在作业成功时设置",
+	"active":              "Active 是正在运行的容器数量",
+	"succeeded":           "Succeeded 是正常退出的容器数量",
+	"failed":              "Failed 是失败的次数，包括失败退出的容器和容器被 ECSM 重启的次数",
+	"underlyingServiceID": "UnderlyingServiceID 是运行作业的平台服务的 ID",
+	"conditions":          "Conditions 描述了作业的当前状况，例如 \"Complete\"",
+}
+
+func (ECSMJobStatus) SwaggerDoc() map[string]string {
+	return map_ECSMJobStatus
+}
+
+var map_ECSMNode = map[string]string{
+	"": "ECSMNode 是 ECSM 平台上一个节点在控制平面中的镜像。 它是集群级别的资源 (没有命名空间)，由 NodeController 根据平台上的节点自动创建和更新。 节点的标签使用 metadata.labels，调度器根据服务的 nodeSelector 匹配它们。",
+}
+
+func (ECSMNode) SwaggerDoc() map[string]string {
+	return map_ECSMNode
+}
+
+var map_ECSMNodeList = map[string]string{
+	"": "ECSMNodeList 包含 ECSMNode 的列表",
+}
+
+func (ECSMNodeList) SwaggerDoc() map[string]string {
+	return map_ECSMNodeList
+}
+
+var map_ECSMNodeSpec = map[string]string{
+	"":               "ECSMNodeSpec 定义了节点的期望状态",
+	"address":        "Address 是节点的访问地址",
+	"credentialsRef": "CredentialsRef 引用了保存节点登录密码的 Secret，密码本身不会保存在 ECSMNode 中。 ECSM 在注册节点时需要它。",
+	"taints":         "Taints 阻止不能容忍它们的服务被调度到该节点上",
+}
+
+func (ECSMNodeSpec) SwaggerDoc() map[string]string {
+	return map_ECSMNodeSpec
+}
+
+var map_ECSMNodeStatus = map[string]string{
+	"":                  "ECSMNodeStatus 定义了节点的观测状态",
+	"nodeID":            "NodeID 是节点在 ECSM 平台中的 ID",
+	"type":              "Type 是节点的类型，例如操作系统",
+	"arch":              "Arch 是节点的 CPU 架构",
+	"ecsdVersion":       "EcsdVersion 是节点上运行的 ecsd 守护进程的版本",
+	"capacity":          "Capacity 是节点的资源总量",
+	"containers":        "Containers 是节点上容器数量的统计",
+	"lastHeartbeatTime": "LastHeartbeatTime 是控制器最近一次观察到节点上报新的指标的时间",
+	"conditions":        "Conditions 描述了节点的当前状况，例如 \"Ready\"",
+}
+
+func (ECSMNodeStatus) SwaggerDoc() map[string]string {
+	return map_ECSMNodeStatus
+}
+
+var map_NodeContainerCounts = map[string]string{
+	"":               "NodeContainerCounts 统计了节点上的容器数量",
+	"total":          "Total 是节点上所有容器的数量",
+	"running":        "Running 是节点上正在运行的容器数量",
+	"managedTotal":   "ManagedTotal 是由 ECSM 管理的容器数量",
+	"managedRunning": "ManagedRunning 是由 ECSM 管理且正在运行的容器数量",
+}
+
+func (NodeContainerCounts) SwaggerDoc() map[string]string {
+	return map_NodeContainerCounts
+}
+
+var map_NodeCredentialsReference = map[string]string{
+	"":          "NodeCredentialsReference 引用了一个保存节点凭据的 Secret",
+	"name":      "Name 是 Secret 的名称",
+	"namespace": "Namespace 是 Secret 所在的命名空间，默认为 \"default\"",
+	"key":       "Key 是 Secret 中保存密码的键，默认为 \"password\"",
+}
+
+func (NodeCredentialsReference) SwaggerDoc() map[string]string {
+	return map_NodeCredentialsReference
+}
+
+var map_NodeTaint = map[string]string{
+	"":       "NodeTaint 是节点上的一个污点",
+	"key":    "Key 是污点的键",
+	"value":  "Value 是污点的值",
+	"effect": "Effect 是污点的影响",
+}
+
+func (NodeTaint) SwaggerDoc() map[string]string {
+	return map_NodeTaint
+}
+
+var map_ECSMNodeSet = map[string]string{
+	"": "ECSMNodeSet 保证每个满足 nodeSelector 的节点上恰好运行一个容器，类似于 Kubernetes 的 DaemonSet。 与需要逐个列出节点名称的 Static 策略不同，节点加入或离开时，它运行的节点会自动随之变化。 控制器为每个 ECSMNodeSet 维护一个同名的、Static 策略的 ECSMService，由后者完成实际的部署和滚动更新。",
+}
+
+func (ECSMNodeSet) SwaggerDoc() map[string]string {
+	return map_ECSMNodeSet
+}
+
+var map_ECSMNodeSetList = map[string]string{
+	"": "ECSMNodeSetList 包含 ECSMNodeSet 的列表",
+}
+
+func (ECSMNodeSetList) SwaggerDoc() map[string]string {
+	return map_ECSMNodeSetList
+}
+
+var map_ECSMNodeSetSpec = map[string]string{
+	"":                "ECSMNodeSetSpec 定义了 ECSMNodeSet 的期望状态",
+	"nodeSelector":    "NodeSelector 是对节点标签 (ECSMNode 的 metadata.labels) 的要求，为空时选择所有节点",
+	"tolerations":     "Tolerations 允许容器运行在带有匹配的 NoSchedule 或 NoExecute 污点的节点上",
+	"template":        "Template 是在每个节点上运行的容器模板",
+	"upgradeStrategy": "UpgradeStrategy 是模板变化时替换容器的方式，会被原样传给 ECSMService",
+}
+
+func (ECSMNodeSetSpec) SwaggerDoc() map[string]string {
+	return map_ECSMNodeSetSpec
+}
+
+var map_ECSMNodeSetStatus = map[string]string{
+	"":                       "ECSMNodeSetStatus 定义了 ECSMNodeSet 的观测状态",
+	"observedGeneration":     "ObservedGeneration 是控制器最近一次处理的 spec 的 generation",
+	"desiredNumberScheduled": "DesiredNumberScheduled 是应该运行容器的节点数量",
+	"currentNumberScheduled": "CurrentNumberScheduled 是已经运行着容器的节点数量",
+	"numberReady":            "NumberReady 是容器已经就绪的节点数量",
+	"nodes":                  "Nodes 是当前选中的节点名称，按名称排序",
+	"serviceName":            "ServiceName 是控制器为 ECSMNodeSet 维护的 ECSMService 的名称",
+}
+
+func (ECSMNodeSetStatus) SwaggerDoc() map[string]string {
+	return map_ECSMNodeSetStatus
+}
+
+var map_ECSMNotification = map[string]string{
+	"": "ECSMNotification 把控制器记录的事件 (例如发布完成、服务降级、节点失联) 推送到一个外部的 HTTP webhook， 例如 Slack 或钉钉的机器人。它是集群级别的资源，可以选择所有命名空间的事件。",
+}
+
+func (ECSMNotification) SwaggerDoc() map[string]string {
+	return map_ECSMNotification
+}
+
+var map_ECSMNotificationList = map[string]string{
+	"": "ECSMNotificationList 包含 ECSMNotification 的列表",
+}
+
+func (ECSMNotificationList) SwaggerDoc() map[string]string {
+	return map_ECSMNotificationList
+}
+
+var map_ECSMNotificationSpec = map[string]string{
+	"":             "ECSMNotificationSpec 定义了通知哪些事件以及如何发送",
+	"selector":     "Selector 选择需要通知的事件，为空时选择所有事件",
+	"webhook":      "Webhook 是接收通知的地址",
+	"template":     "Template 是消息文本的 Go text/template 模板，模板的数据是 Event 对象，例如 \"{{.Reason}}: {{.Message}}\"。 为空时使用包含事件类型、对象、原因和消息的默认模板。",
+	"maxPerMinute": "MaxPerMinute 是每分钟最多发送的通知数量，超过的事件被丢弃并计入 status.dropped。 为 0 时使用默认值 10。",
+	"suspend":      "Suspend 为 true 时暂停发送，期间的事件不会在恢复后补发",
+}
+
+func (ECSMNotificationSpec) SwaggerDoc() map[string]string {
+	return map_ECSMNotificationSpec
+}
+
+var map_ECSMNotificationStatus = map[string]string{
+	"":             "ECSMNotificationStatus 记录了通知的发送情况",
+	"sent":         "Sent 是成功发送的通知数量",
+	"failed":       "Failed 是发送失败的通知数量，失败的通知不会重试",
+	"dropped":      "Dropped 是因为超过 maxPerMinute 而被丢弃的通知数量",
+	"lastSentTime": "LastSentTime 是最近一次成功发送的时间",
+	"lastError":    "LastError 是最近一次发送失败的原因，下一次发送成功后被清除",
+}
+
+func (ECSMNotificationStatus) SwaggerDoc() map[string]string {
+	return map_ECSMNotificationStatus
+}
+
+var map_NotificationSelector = map[string]string{
+	"":           "NotificationSelector 选择事件。各个字段之间是 \"与\" 的关系，同一字段中的多个值之间是 \"或\" 的关系，空字段不做限制。",
+	"types":      "Types 是事件的类型，Normal 或 Warning",
+	"reasons":    "Reasons 是事件的原因，例如 RolloutComplete、Degraded、NodeNotReady",
+	"kinds":      "Kinds 是事件所描述的对象的 kind，例如 ECSMService、ECSMNode",
+	"namespaces": "Namespaces 是事件所在的命名空间，集群级对象 (例如 ECSMNode) 的事件没有命名空间，只在这个字段为空时被选择",
+}
+
+func (NotificationSelector) SwaggerDoc() map[string]string {
+	return map_NotificationSelector
+}
+
+var map_NotificationWebhook = map[string]string{
+	"":               "NotificationWebhook 描述了接收通知的 HTTP webhook",
+	"type":           "Type 为空时等同于 Generic",
+	"url":            "URL 是接收通知的地址，必须是 http 或 https",
+	"headers":        "Headers 是附加在每个请求上的 HTTP 头，例如认证信息",
+	"timeoutSeconds": "TimeoutSeconds 是一次请求的超时时间，为 0 时使用 10 秒",
+}
+
+func (NotificationWebhook) SwaggerDoc() map[string]string {
+	return map_NotificationWebhook
+}
+
+var map_ECSMPriorityClass = map[string]string{
+	"":              "ECSMPriorityClass 定义了一个优先级名称到整数优先级的映射，类似于 Kubernetes 的 PriorityClass。 它是集群级别的资源，ECSMService 通过 spec.priorityClassName 引用它。 节点资源紧张时，调度器允许高优先级的服务占用低优先级服务实例所使用的资源， RemediationController 也会优先驱逐低优先级的实例，为失败的高优先级实例腾出资源。",
+	"value":         "Value 是优先级的值，值越大优先级越高",
+	"globalDefault": "GlobalDefault 表示没有设置 priorityClassName 的服务使用这个优先级。 有多个 GlobalDefault 的优先级时，使用其中值最小的一个。",
+	"description":   "Description 是给用户看的说明，例如什么样的服务应该使用这个优先级",
+}
+
+func (ECSMPriorityClass) SwaggerDoc() map[string]string {
+	return map_ECSMPriorityClass
+}
+
+var map_ECSMPriorityClassList = map[string]string{
+	"": "ECSMPriorityClassList 包含 ECSMPriorityClass 的列表",
+}
+
+func (ECSMPriorityClassList) SwaggerDoc() map[string]string {
+	return map_ECSMPriorityClassList
+}
+
+var map_ECSMServiceScale = map[string]string{
+	"":       "ECSMServiceScale 是 ECSMService 的 scale 子资源，类似于 Kubernetes 的 autoscaling/v1 Scale。 它只暴露服务的副本数，ecsm-cli scale 和自动扩缩容控制器通过它修改副本数， 不需要读写、也不会覆盖 spec 中的其他字段。 它的名称、命名空间和 resourceVersion 与所属的 ECSMService 相同。",
+	"spec":   "Spec 是期望的副本数",
+	"status": "Status 是服务当前的副本数",
+}
+
+func (ECSMServiceScale) SwaggerDoc() map[string]string {
+	return map_ECSMServiceScale
+}
+
+var map_ScaleSpec = map[string]string{
+	"":         "ScaleSpec 描述了期望的副本数。",
+	"replicas": "Replicas 是期望的副本数，对应 spec.deploymentStrategy.replicas",
+}
+
+func (ScaleSpec) SwaggerDoc() map[string]string {
+	return map_ScaleSpec
+}
+
+var map_ScaleStatus = map[string]string{
+	"":              "ScaleStatus 描述了服务当前的副本数。",
+	"replicas":      "Replicas 是服务当前的容器实例数，对应 status.replicas",
+	"readyReplicas": "ReadyReplicas 是服务当前就绪的容器实例数，对应 status.readyReplicas",
+}
+
+func (ScaleStatus) SwaggerDoc() map[string]string {
+	return map_ScaleStatus
+}
+
+var map_ECSMSecret = map[string]string{
+	"":     "ECSMSecret 保存密码、令牌等敏感数据，类似于 Kubernetes 的 Secret。 它在 Registry 中加密存储，operator 必须配置加密密钥才能创建和读取它。 容器模板可以通过 envFrom、env[].valueFrom 和 vsoa.passwordFrom 引用同一命名空间中的 ECSMSecret， ecsm-cli 只显示它的键，从不显示值。",
+	"data": "Data 是敏感数据的内容",
+}
+
+func (ECSMSecret) SwaggerDoc() map[string]string {
+	return map_ECSMSecret
+}
+
+var map_ECSMSecretList = map[string]string{
+	"": "ECSMSecretList 包含 ECSMSecret 的列表",
+}
+
+func (ECSMSecretList) SwaggerDoc() map[string]string {
+	return map_ECSMSecretList
+}
+
+var map_SecretKeySelector = map[string]string{
+	"":         "SecretKeySelector 选择了同一命名空间中 ECSMSecret 的一个键",
+	"name":     "Name 是 ECSMSecret 的名称",
+	"key":      "Key 是要读取的键",
+	"optional": "Optional 为 true 时，ECSMSecret 或键不存在也不会报错，引用会被忽略",
+}
+
+func (SecretKeySelector) SwaggerDoc() map[string]string {
+	return map_SecretKeySelector
+}
+
+var map_SecretReference = map[string]string{
+	"":         "SecretReference 引用了同一命名空间中的一个 ECSMSecret",
+	"name":     "Name 是 ECSMSecret 的名称",
+	"optional": "Optional 为 true 时，ECSMSecret 不存在也不会报错",
+}
+
+func (SecretReference) SwaggerDoc() map[string]string {
+	return map_SecretReference
+}
+
+var map_Affinity = map[string]string{
+	"":                    "Affinity 是服务之间的调度约束。所有约束都是硬性的，无法满足时调度失败。",
+	"serviceAffinity":     "ServiceAffinity 要求实例只被调度到已经运行着匹配服务实例的拓扑域中。 匹配的服务都还没有实例、且服务自身也匹配时，该约束被忽略，以便一组互相亲和的服务中的第一个能够被调度。",
+	"serviceAntiAffinity": "ServiceAntiAffinity 要求实例不被调度到运行着匹配服务实例的拓扑域中。 服务自身也匹配时，它的各个实例会被分散到不同的拓扑域中，拓扑域不足时调度失败。 反亲和只约束声明它的服务，不会阻止其他服务被调度到它的实例旁边。",
+}
+
+func (Affinity) SwaggerDoc() map[string]string {
+	return map_Affinity
+}
+
+var map_BlueGreenStrategy = map[string]string{
+	"":                      "BlueGreenStrategy 定义了蓝绿发布的参数",
+	"promotionDelaySeconds": "PromotionDelaySeconds 是新修订版本全部就绪之后、删除旧修订版本之前的验证时间。 验证期间新实例出现不可用时会重新计时。默认为 0，即就绪后立即切换。",
+}
+
+func (BlueGreenStrategy) SwaggerDoc() map[string]string {
+	return map_BlueGreenStrategy
+}
+
+var map_CanaryStep = map[string]string{
+	"":             "CanaryStep 是 Canary 发布中的一步",
+	"weight":       "Weight 是这一步中新修订版本的实例占期望副本数的百分比，向上取整",
+	"pauseSeconds": "PauseSeconds 是这一步的新实例全部就绪之后，进入下一步之前的观察时间",
+}
+
+func (CanaryStep) SwaggerDoc() map[string]string {
+	return map_CanaryStep
+}
+
+var map_CanaryStrategy = map[string]string{
+	"":      "CanaryStrategy 定义了 Canary 发布的步骤",
+	"steps": "Steps 是按顺序执行的发布步骤，它们的 Weight 必须严格递增。 最后一步的 Weight 小于 100 时，会在最后隐式地追加一个 100% 的步骤。 为空时使用 20%、50%、100% 三个步骤。",
+}
+
+func (CanaryStrategy) SwaggerDoc() map[string]string {
+	return map_CanaryStrategy
+}
+
+var map_ContainerTemplateSpec = map[string]string{
+	"":                 "ContainerTemplateSpec 定义了容器模版",
+	"image":            "Image 是要运行的容器镜像引用，格式为 \"name@tag\"。 例如: \"njust@1.1\"。",
+	"imagePullPolicy":  "ImagePullPolicy 定义了镜像拉取策略。默认为 \"IfNotPresent\"。",
+	"prepull":          "PrePull 定义了是否开启镜像预热，开启后将在部署时向所有节点同步镜像 默认为 False",
+	"hostname":         "Hostname 定义了容器的主机名。如果为空，控制器将默认使用服务名称。",
+	"command":          "Command 是容器的入口点。如果为空，则使用镜像默认的入口点。",
+	"env":              "Env 是要注入到容器中的环境变量列表。",
+	"envFrom":          "EnvFrom 把 ECSMConfig 或 ECSMSecret 中的全部键值注入为环境变量。 多个来源中重复的名称以后出现的为准，env 中显式声明的变量优先于 envFrom。",
+	"resources":        "Resources 定义了容器的资源请求和限制。",
+	"volumeMounts":     "VolumeMounts 是要挂载到容器中的卷列表。",
+	"vsoa":             "VSOA 包含了所有与 VSOA 服务相关的配置。",
+	"livenessProbe":    "LivenessProbe 周期性地检查容器是否存活。连续失败达到阈值时容器被视为失败， 由 RemediationController 按照 spec.remediation 处理，未声明补救策略时容器被重启。",
+	"readinessProbe":   "ReadinessProbe 周期性地检查容器是否能够提供服务，未就绪的容器不计入 status.readyReplicas。",
+	"platformSpecific": "PlatformSpecific 是一个\"逃生舱口\"，用于设置平台特有的、不常用的底层配置。 普通用户通常不需要关心此部分。",
+}
+
+func (ContainerTemplateSpec) SwaggerDoc() map[string]string {
+	return map_ContainerTemplateSpec
+}
+
+var map_DeploymentStrategy = map[string]string{
+	"":             "DeploymentStrategy 定义了服务的部署策略，即节点选择策略",
+	"type":         "Type 表示部署类型 Static：在 `nodes` 字段中指定的每个节点上都部署一个实例。 Dynamic：在 `nodePool` 提供的节点池中，部署 `replicas` 个实例。",
+	"replicas":     "Replicas 表示动态选择时的指定副本数量 在 Static 策略下此字段被忽略 kubebuilder:validation:Minimum=1",
+	"nodes":        "Nodes 是在静态策略下指定的节点列表",
+	"nodePool":     "NodePool 是在动态策略下指定的节点池",
+	"nodeSelector": "NodeSelector 是动态策略下对节点标签 (ECSMNode 的 metadata.labels) 的要求。 只有带有全部这些标签的节点才会被调度器选中。与 NodePool 不同，之后注册的、带有匹配标签的节点会被自动纳入选择范围。 同时设置 NodePool 时，节点必须同时在节点池中并满足标签要求。",
+	"tolerations":  "Tolerations 允许调度器把服务调度到带有匹配污点的节点上，并使服务的实例不会因为匹配的 NoExecute 污点被驱逐",
+}
+
+func (DeploymentStrategy) SwaggerDoc() map[string]string {
+	return map_DeploymentStrategy
+}
+
+var map_Device = map[string]string{
+	"path":   "文件路径",
+	"access": "访问权限",
+}
+
+func (Device) SwaggerDoc() map[string]string {
+	return map_Device
+}
+
+var map_ECSMService = map[string]string{
+	"": "ECSMService 代表一个ECSM服务实例，是ECSM平台上一个无状态应用的核心抽象",
+}
+
+func (ECSMService) SwaggerDoc() map[string]string {
+	return map_ECSMService
+}
+
+var map_ECSMServiceList = map[string]string{
+	"": "ECSMServiceList 包含 ECSMService 的列表",
+}
+
+func (ECSMServiceList) SwaggerDoc() map[string]string {
+	return map_ECSMServiceList
+}
+
+var map_ECSMServiceSpec = map[string]string{
+	"":                   "ECSMServiceSpec 定义了ECSM服务的期望状态",
+	"cluster":            "Cluster 是负责该服务的 ECSM 集群的名称，它必须是 operator 配置中的一个集群。 为空时使用 ClusterAnnotation，两者都为空时使用默认集群。 服务被调谐过之后不能再更换集群。",
+	"deploymentStrategy": "定义了服务的部署策略，决定了容器实例如何分布在节点上",
+	"upgradeStrategy":    "定义了当镜像更新时服务的升级策略",
+	"template":           "Template 是创建新容器实例的关键模版",
+	"selector":           "Selector 是一组平台服务标签 (key=value)。ECSM 平台上已存在的、 不属于任何 ECSMService 且带有全部这些标签的服务，会被该 ECSMService 认领，而不是重复创建。 与服务同名或符合控制器命名规则 (\"<name>-<hash>\"，default 之外的命名空间在中间加上命名空间的哈希) 的平台服务总是会被认领。",
+	"driftPolicy":        "DriftPolicy 决定了当有人绕过 operator 直接在 ECSM 平台上修改服务 (例如在控制台中扩缩容或更换镜像) 时， 控制器的处理方式。默认为 \"Enforce\"。",
+	"remediation":        "Remediation 定义了容器反复失败时，在 ECSM 自身的重启机制之外控制器采取的补救措施。 为空时控制器不干预。",
+	"affinity":           "Affinity 定义了服务实例与其他服务实例之间的亲和与反亲和。 它只对默认集群中 Dynamic 策略的服务生效，由调度器在选择节点时遵守。",
+	"priorityClassName":  "PriorityClassName 引用一个 ECSMPriorityClass，决定服务在节点资源紧张时的优先级。 为空时使用 GlobalDefault 的优先级，引用的优先级不存在时按默认优先级处理。",
+}
+
+func (ECSMServiceSpec) SwaggerDoc() map[string]string {
+	return map_ECSMServiceSpec
+}
+
+var map_ECSMServiceStatus = map[string]string{
+	"":                    "ECSMServiceStatus 定义了 ECSMService 的状态",
+	"replicas":            "Replicas 是在 ECSM 平台上实际找到的、属于此服务的容器实例总数。 从查询 API 的 `factor` 字段获取。",
+	"readyReplicas":       "ReadyReplicas 是当前处于在线且运行中的容器实例数量。 从查询 API 的 `instanceOnline` 字段获取。",
+	"updatedReplicas":     "UpdatedReplicas 是已经运行着当前 spec.template 的容器实例数量。 滚动更新期间，该值会从 0 逐步增长到期望副本数。",
+	"observedGeneration":  "ObservedGeneration 是控制器最近一次处理的 ECSMService.metadata.generation。 这对于区分 spec 变更前后的状态非常重要。",
+	"conditions":          "Conditions 提供了标准的机制来报告服务的当前状态。 例如，\"Available\", \"Progressing\", \"Degraded\"。",
+	"underlyingServiceID": "UnderlyingServiceID 是在 ECSM 平台中对应的真实服务 ID。 这对于调试和直接与 ECSM API 交互非常有用。 从查询 API 的 `id` 字段获取。",
+	"cluster":             "Cluster 是控制器调谐该服务时所使用的 ECSM 集群的名称",
+	"pendingTransactions": "PendingTransactions 是控制器已经提交、但在 ECSM 平台上尚未完成的异步事务。 在它们完成之前，控制器不会基于平台的状态做出新的决策。",
+	"rollout":             "Rollout 记录了正在进行的 Canary 或蓝绿发布的进度，发布完成后被清除",
+	"plannedActions":      "PlannedActions 是 dry-run 模式下，控制器在最近一次调谐中本应执行、但被跳过的操作。 关闭 dry-run 后，这些操作会被真正执行。",
+}
+
+func (ECSMServiceStatus) SwaggerDoc() map[string]string {
+	return map_ECSMServiceStatus
+}
+
+var map_EnvVar = map[string]string{
+	"":          "EnvVar 代表一个环境变量",
+	"name":      "Name 是环境变量的名称。",
+	"value":     "Value 是环境变量的值。",
+	"valueFrom": "ValueFrom 从 ECSMConfig 或 ECSMSecret 中读取环境变量的值，设置后 Value 被忽略。",
+}
+
+func (EnvVar) SwaggerDoc() map[string]string {
+	return map_EnvVar
+}
+
+var map_ExecAction = map[string]string{
+	"":        "ExecAction 描述了一个在容器中执行的命令。",
+	"command": "Command 是要执行的命令及其参数，不经过 shell 解释",
+}
+
+func (ExecAction) SwaggerDoc() map[string]string {
+	return map_ExecAction
+}
+
+var map_HealthCheckSpec = map[string]string{
+	"initialDelaySeconds": "InitialDelaySeconds 是健康检查的初始延迟时间，单位为秒",
+	"timeoutSeconds":      "TimeoutSeconds 是健康检查的超时时间，单位为秒",
+	"periodSeconds":       "PeriodSeconds 是健康检查的周期时间，单位为秒",
+	"failureThreshold":    "FailureThreshold 是健康检查失败的阈值，连续失败多少次后将容器视为不健康",
+}
+
+func (HealthCheckSpec) SwaggerDoc() map[string]string {
+	return map_HealthCheckSpec
+}
+
+var map_NetworkSpec = map[string]string{
+	"ftpd":    "FTPD 定义了是否启动FTPD服务器",
+	"telnetd": "TELNETD 定义了是否启动TELNETD服务器",
+}
+
+func (NetworkSpec) SwaggerDoc() map[string]string {
+	return map_NetworkSpec
+}
+
+var map_PendingTransaction = map[string]string{
+	"":            "PendingTransaction 描述了一个由控制器提交的 ECSM 异步事务",
+	"id":          "ID 是 ECSM 返回的事务 ID",
+	"operation":   "Operation 是提交的操作，例如 \"Delete\"",
+	"target":      "Target 是该操作作用的平台服务名称",
+	"submittedAt": "SubmittedAt 是事务提交的时间",
+}
+
+func (PendingTransaction) SwaggerDoc() map[string]string {
+	return map_PendingTransaction
+}
+
+var map_PlatformSpec = map[string]string{
+	"os":   "OS 代表镜像系统",
+	"arch": "Arch 代表镜像架构",
+}
+
+func (PlatformSpec) SwaggerDoc() map[string]string {
+	return map_PlatformSpec
+}
+
+var map_PlatformSpecificConfig = map[string]string{
+	"action":   "Action 定义了容器启动类型 run 表示创建并启动 load 表示只创建",
+	"root":     "Root 是容器运行的根文件系统路径",
+	"platform": "Platform 定义了容器的平台类型",
+	"sylixos":  "SylixOS 包含了所有针对 SylixOS 的底层配置",
+}
+
+func (PlatformSpecificConfig) SwaggerDoc() map[string]string {
+	return map_PlatformSpecificConfig
+}
+
+var map_Probe = map[string]string{
+	"": "Probe 描述了对容器的一种检查方式，以及检查的时间参数。",
+}
+
+func (Probe) SwaggerDoc() map[string]string {
+	return map_Probe
+}
+
+var map_ProbeHandler = map[string]string{
+	"":          "ProbeHandler 定义了检查容器的方式。",
+	"vsoa":      "VSOA 使用 ECSM 内置的 VSOA 健康检查，要求容器模板配置了 vsoa。 检查由 ECSM 执行，ECSM 会原地重启检查失败的容器。",
+	"tcpSocket": "TCPSocket 检查容器的一个 TCP 端口能否建立连接，由控制器执行。",
+	"exec":      "Exec 在容器中执行命令，命令退出码为 0 时视为成功。 ECSM 的 API 不提供在容器中执行命令的接口，需要部署方为控制器提供执行命令的方式，否则检查不会执行。",
+}
+
+func (ProbeHandler) SwaggerDoc() map[string]string {
+	return map_ProbeHandler
+}
+
+var map_RemediationPolicy = map[string]string{
+	"":                 "RemediationPolicy 定义了容器的健康补救策略",
+	"action":           "Action 是容器失败次数达到 FailureThreshold 时采取的措施。默认为 \"Restart\"。",
+	"failureThreshold": "FailureThreshold 是触发一次补救所需的失败次数。 容器的重启次数每增加一次，或控制器每次观察到容器没有在运行，都计为一次失败。默认为 3。",
+	"maxRemediations":  "MaxRemediations 是同一个容器最多被补救的次数。 超过之后容器再次达到失败阈值时，服务会被标记为 Degraded，而不再继续补救。默认为 3。",
+}
+
+func (RemediationPolicy) SwaggerDoc() map[string]string {
+	return map_RemediationPolicy
+}
+
+var map_ResourceRequirements = map[string]string{
+	"":       "对ECSM的资源模型进行了简化和抽象",
+	"limits": "Limits 定义了容器的资源限制，内存和硬盘。 CPU 优先级请通过高级配置进行设置",
+}
+
+func (ResourceRequirements) SwaggerDoc() map[string]string {
+	return map_ResourceRequirements
+}
+
+var map_RolloutStatus = map[string]string{
+	"":          "RolloutStatus 描述了一次 Canary 或蓝绿发布的进度",
+	"revision":  "Revision 是正在发布的修订版本的模板哈希",
+	"step":      "Step 是 Canary 发布当前所处的步骤，从 0 开始",
+	"readyTime": "ReadyTime 是当前步骤的新实例全部就绪的时间，用于计算暂停和验证窗口",
+}
+
+func (RolloutStatus) SwaggerDoc() map[string]string {
+	return map_RolloutStatus
+}
+
+var map_RootSpec = map[string]string{
+	"":         "RootSpec 定义了容器运行时的根文件系统路径",
+	"path":     "Path 是容器运行时的根文件系统路径",
+	"readOnly": "ReadOnly 是容器运行时的根文件系统是否只读",
+}
+
+func (RootSpec) SwaggerDoc() map[string]string {
+	return map_RootSpec
+}
+
+var map_ServiceAffinityTerm = map[string]string{
+	"":              "ServiceAffinityTerm 选择一组服务，以及判断两个实例是否\"在一起\"的拓扑。",
+	"labelSelector": "LabelSelector 根据 ECSMService 的 metadata.labels 选择服务，为空时不选择任何服务",
+	"namespaces":    "Namespaces 是被选择的服务所在的命名空间，为空时为该服务自身所在的命名空间",
+	"topologyKey":   "TopologyKey 是 ECSMNode 的标签键，这个标签的值相同的节点属于同一个拓扑域。 为空时每个节点自成一个拓扑域。没有这个标签的节点不会被选中。",
+}
+
+func (ServiceAffinityTerm) SwaggerDoc() map[string]string {
+	return map_ServiceAffinityTerm
+}
+
+var map_SylixOSCPUConfig = map[string]string{
+	"":            "SylixOSCPUConfig 包含专属于 SylixOS 的 CPU 优先级设置",
+	"highestPrio": "HighestPrio 是最高优先级",
+	"lowestPrio":  "LowestPrio 是最低优先级",
+}
+
+func (SylixOSCPUConfig) SwaggerDoc() map[string]string {
+	return map_SylixOSCPUConfig
+}
+
+var map_SylixOSConfig = map[string]string{
+	"devices": "Devices 定义了设备信息",
+	"network": "Network 暂时只定义了是否启动FTPD服务器和TELNETD服务器",
+	"cpu":     "CPU 相关的底层配置",
+	"memory":  "Memory 相关的底层配置",
+}
+
+func (SylixOSConfig) SwaggerDoc() map[string]string {
+	return map_SylixOSConfig
+}
+
+var map_SylixOSMemoryConfig = map[string]string{
+	"":           "SylixOSMemoryConfig 包含专属于 SylixOS 的、不常用的内存配置。 注意：常用的内存限制（memoryLimitMB）应该通过 spec.template.resources.limits.memory 来设置。",
+	"kheapLimit": "KheapLimit 是内核堆限制（字节）。 这是一个高级设置，大多数用户不需要关心。 如果用户未指定，控制器可以应用 ECSM 的默认值。",
+}
+
+func (SylixOSMemoryConfig) SwaggerDoc() map[string]string {
+	return map_SylixOSMemoryConfig
+}
+
+var map_TCPSocketAction = map[string]string{
+	"":     "TCPSocketAction 描述了一个 TCP 连接检查。",
+	"port": "Port 是要连接的容器端口",
+}
+
+func (TCPSocketAction) SwaggerDoc() map[string]string {
+	return map_TCPSocketAction
+}
+
+var map_Toleration = map[string]string{
+	"":         "Toleration 表示服务能够容忍匹配的节点污点",
+	"key":      "Key 是要容忍的污点的键，为空且 Operator 为 Exists 时容忍所有污点",
+	"operator": "Operator 是匹配方式，默认为 \"Equal\"",
+	"value":    "Value 是 Operator 为 Equal 时要容忍的污点的值",
+	"effect":   "Effect 是要容忍的污点的影响，为空时容忍所有影响",
+}
+
+func (Toleration) SwaggerDoc() map[string]string {
+	return map_Toleration
+}
+
+var map_UpgradeStrategy = map[string]string{
+	"":               "UpgradeStrategy 定义了服务的升级策略，即容器镜像更新策略",
+	"type":           "Type 对应 ECSM 的 autoUpgrade 字段。 \"Never\": 从不自动更新。 \"Larger\": 当有更高版本的镜像时更新。 \"Always\": 只要有新镜像就更新。 默认为 \"Never\"。",
+	"maxUnavailable": "MaxUnavailable 是滚动更新过程中允许不可用的最大实例数。 可以是绝对数值 (例如 1) 或期望副本数的百分比 (例如 \"25%\")，百分比向下取整。 当 MaxSurge 为 0 时，此字段不能为 0。默认为 \"25%\"。",
+	"maxSurge":       "MaxSurge 是滚动更新过程中允许超出期望副本数的最大实例数。 可以是绝对数值 (例如 1) 或期望副本数的百分比 (例如 \"25%\")，百分比向上取整。 当 MaxUnavailable 为 0 时，此字段不能为 0。默认为 \"25%\"。",
+	"rollout":        "Rollout 决定了模板变化时新旧修订版本如何交替。默认为 \"RollingUpdate\"。 MaxSurge 和 MaxUnavailable 只对 RollingUpdate 生效。",
+	"canary":         "Canary 是 Canary 发布的步骤，仅在 Rollout 为 \"Canary\" 时使用",
+	"blueGreen":      "BlueGreen 是蓝绿发布的参数，仅在 Rollout 为 \"BlueGreen\" 时使用",
+}
+
+func (UpgradeStrategy) SwaggerDoc() map[string]string {
+	return map_UpgradeStrategy
+}
+
+var map_VSOAProbeAction = map[string]string{
+	"":     "VSOAProbeAction 描述了一个 VSOA 健康检查。",
+	"path": "Path 是 VSOA 服务中用于健康检查的 URL 路径，为空时使用 ECSM 的默认路径",
+}
+
+func (VSOAProbeAction) SwaggerDoc() map[string]string {
+	return map_VSOAProbeAction
+}
+
+var map_VSOASpec = map[string]string{
+	"":             "VSOASpec 定义了 VSOA 服务的配置",
+	"password":     "Password 是 VSOA 服务的密码",
+	"passwordFrom": "PasswordFrom 从 ECSMSecret 中读取 VSOA 服务的密码，设置后 Password 被忽略",
+	"port":         "Port 是 VSOA 监听的端口 如果为0.表示由ECSM动态分配",
+	"healthCheck":  "HealthCheck 定义了容器的健康检查配置",
+}
+
+func (VSOASpec) SwaggerDoc() map[string]string {
+	return map_VSOASpec
+}
+
+var map_VolumeMount = map[string]string{
+	"":              "VolumeMount 定义了共享库的挂载点",
+	"name":          "Name 是挂载点的名称",
+	"hostPath":      "HostPath 是主机上的路径，容器将在此路径下挂载卷。",
+	"hostPathFrom":  "HostPathFrom 从 ECSMConfig 中读取主机上的路径，设置后 HostPath 被忽略。 适用于不同站点的数据目录不同的场景。",
+	"containerPath": "ContainerPath 是容器内的目标路径",
+	"readOnly":      "ReadOnly 如果为 true，容器将以只读模式挂载卷。",
+}
+
+func (VolumeMount) SwaggerDoc() map[string]string {
+	return map_VolumeMount
+}
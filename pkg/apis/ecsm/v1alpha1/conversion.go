@@ -0,0 +1,288 @@
+// file: pkg/apis/ecsm/v1alpha1/conversion.go
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/conversion"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ConversionDataAnnotation 保存了 v1 对象中无法用 v1alpha1 表示的 spec (JSON 格式)。
+// 把 v1 对象转换为 v1alpha1 时写入，转换回 v1 时读取并删除，使 v1 -> v1alpha1 -> v1 的往返不丢失字段。
+const ConversionDataAnnotation = "ecsm.sh/conversion-data"
+
+var _ conversion.Convertible = &ECSMService{}
+
+// upgradeTypes 是 v1alpha1 的 autoUpgrade 与 v1 的 upgradeStrategy.type 之间的对应关系
+var upgradeTypes = map[string]ecsmv1.UpgradeStrategyType{
+	"never":  ecsmv1.UpgradeStrategyTypeNever,
+	"larger": ecsmv1.UpgradeStrategyTypeLarger,
+	"always": ecsmv1.UpgradeStrategyTypeAlways,
+}
+
+// ConvertTo 把 v1alpha1 的 ECSMService 转换为 hub 版本 (v1)。
+func (src *ECSMService) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*ecsmv1.ECSMService)
+	if !ok {
+		return fmt.Errorf("cannot convert ECSMService to %T", dstRaw)
+	}
+	return Convert_v1alpha1_ECSMService_To_v1_ECSMService(src, dst, nil)
+}
+
+// ConvertFrom 把 hub 版本 (v1) 的 ECSMService 转换为 v1alpha1。
+func (dst *ECSMService) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*ecsmv1.ECSMService)
+	if !ok {
+		return fmt.Errorf("cannot convert %T to ECSMService", srcRaw)
+	}
+	return Convert_v1_ECSMService_To_v1alpha1_ECSMService(src, dst, nil)
+}
+
+// addConversionFuncs 把两个版本之间的转换函数注册到 scheme 中。
+func addConversionFuncs(scheme *runtime.Scheme) error {
+	if err := scheme.AddConversionFunc((*ECSMService)(nil), (*ecsmv1.ECSMService)(nil), func(a, b interface{}, scope apiconversion.Scope) error {
+		return Convert_v1alpha1_ECSMService_To_v1_ECSMService(a.(*ECSMService), b.(*ecsmv1.ECSMService), scope)
+	}); err != nil {
+		return err
+	}
+	if err := scheme.AddConversionFunc((*ecsmv1.ECSMService)(nil), (*ECSMService)(nil), func(a, b interface{}, scope apiconversion.Scope) error {
+		return Convert_v1_ECSMService_To_v1alpha1_ECSMService(a.(*ecsmv1.ECSMService), b.(*ECSMService), scope)
+	}); err != nil {
+		return err
+	}
+	if err := scheme.AddConversionFunc((*ECSMServiceList)(nil), (*ecsmv1.ECSMServiceList)(nil), func(a, b interface{}, scope apiconversion.Scope) error {
+		return Convert_v1alpha1_ECSMServiceList_To_v1_ECSMServiceList(a.(*ECSMServiceList), b.(*ecsmv1.ECSMServiceList), scope)
+	}); err != nil {
+		return err
+	}
+	return scheme.AddConversionFunc((*ecsmv1.ECSMServiceList)(nil), (*ECSMServiceList)(nil), func(a, b interface{}, scope apiconversion.Scope) error {
+		return Convert_v1_ECSMServiceList_To_v1alpha1_ECSMServiceList(a.(*ecsmv1.ECSMServiceList), b.(*ECSMServiceList), scope)
+	})
+}
+
+// Convert_v1alpha1_ECSMService_To_v1_ECSMService 把 v1alpha1 的 ECSMService 转换为 v1。
+// 如果对象带有 ConversionDataAnnotation，先从中恢复 v1 的 spec，再用 v1alpha1 中的字段覆盖它。
+func Convert_v1alpha1_ECSMService_To_v1_ECSMService(in *ECSMService, out *ecsmv1.ECSMService, _ apiconversion.Scope) error {
+	out.TypeMeta = metav1.TypeMeta{APIVersion: ecsmv1.SchemeGroupVersion.String(), Kind: "ECSMService"}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec = ecsmv1.ECSMServiceSpec{}
+	if data, ok := out.Annotations[ConversionDataAnnotation]; ok {
+		if err := json.Unmarshal([]byte(data), &out.Spec); err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", ConversionDataAnnotation, err)
+		}
+		delete(out.Annotations, ConversionDataAnnotation)
+		if len(out.Annotations) == 0 {
+			out.Annotations = nil
+		}
+	}
+	convertSpecToV1(&in.Spec, &out.Spec)
+
+	out.Status = ecsmv1.ECSMServiceStatus{
+		Replicas:            in.Status.Replicas,
+		ReadyReplicas:       in.Status.ReadyReplicas,
+		ObservedGeneration:  in.Status.ObservedGeneration,
+		Conditions:          copyConditions(in.Status.Conditions),
+		UnderlyingServiceID: in.Status.UnderlyingServiceID,
+	}
+	return nil
+}
+
+// Convert_v1_ECSMService_To_v1alpha1_ECSMService 把 v1 的 ECSMService 转换为 v1alpha1。
+// spec 中无法用 v1alpha1 表示的字段保存在 ConversionDataAnnotation 中；
+// status 只保留两个版本共有的字段，其余的由控制器在下一次调谐时重新计算。
+func Convert_v1_ECSMService_To_v1alpha1_ECSMService(in *ecsmv1.ECSMService, out *ECSMService, _ apiconversion.Scope) error {
+	out.TypeMeta = metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "ECSMService"}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec = convertSpecFromV1(&in.Spec)
+
+	// 只有转换会丢失字段时才写入注解
+	restored := ecsmv1.ECSMServiceSpec{}
+	convertSpecToV1(&out.Spec, &restored)
+	if !equality.Semantic.DeepEqual(restored, in.Spec) {
+		data, err := json.Marshal(&in.Spec)
+		if err != nil {
+			return err
+		}
+		if out.Annotations == nil {
+			out.Annotations = make(map[string]string)
+		}
+		out.Annotations[ConversionDataAnnotation] = string(data)
+	}
+
+	out.Status = ECSMServiceStatus{
+		Replicas:            in.Status.Replicas,
+		ReadyReplicas:       in.Status.ReadyReplicas,
+		ObservedGeneration:  in.Status.ObservedGeneration,
+		Conditions:          copyConditions(in.Status.Conditions),
+		UnderlyingServiceID: in.Status.UnderlyingServiceID,
+	}
+	return nil
+}
+
+// Convert_v1alpha1_ECSMServiceList_To_v1_ECSMServiceList 把 v1alpha1 的 ECSMServiceList 转换为 v1。
+func Convert_v1alpha1_ECSMServiceList_To_v1_ECSMServiceList(in *ECSMServiceList, out *ecsmv1.ECSMServiceList, scope apiconversion.Scope) error {
+	out.TypeMeta = metav1.TypeMeta{APIVersion: ecsmv1.SchemeGroupVersion.String(), Kind: "ECSMServiceList"}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]ecsmv1.ECSMService, len(in.Items))
+	for i := range in.Items {
+		if err := Convert_v1alpha1_ECSMService_To_v1_ECSMService(&in.Items[i], &out.Items[i], scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Convert_v1_ECSMServiceList_To_v1alpha1_ECSMServiceList 把 v1 的 ECSMServiceList 转换为 v1alpha1。
+func Convert_v1_ECSMServiceList_To_v1alpha1_ECSMServiceList(in *ecsmv1.ECSMServiceList, out *ECSMServiceList, scope apiconversion.Scope) error {
+	out.TypeMeta = metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "ECSMServiceList"}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]ECSMService, len(in.Items))
+	for i := range in.Items {
+		if err := Convert_v1_ECSMService_To_v1alpha1_ECSMService(&in.Items[i], &out.Items[i], scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertSpecToV1 把 v1alpha1 的 spec 写入 out。out 中 v1alpha1 无法表示的字段保持不变。
+func convertSpecToV1(in *ECSMServiceSpec, out *ecsmv1.ECSMServiceSpec) {
+	strategy := &out.DeploymentStrategy
+	if len(in.Nodes) > 0 {
+		strategy.Type = ecsmv1.DeploymentStrategyTypeStatic
+		strategy.Nodes = append([]string(nil), in.Nodes...)
+		strategy.Replicas, strategy.NodePool = nil, nil
+	} else {
+		strategy.Type = ecsmv1.DeploymentStrategyTypeDynamic
+		strategy.Nodes = nil
+		strategy.NodePool = append([]string(nil), in.NodePool...)
+		strategy.Replicas = nil
+		if in.Replicas != nil {
+			replicas := *in.Replicas
+			strategy.Replicas = &replicas
+		}
+	}
+
+	out.UpgradeStrategy.Type = ecsmv1.UpgradeStrategyType(in.AutoUpgrade)
+	if t, ok := upgradeTypes[in.AutoUpgrade]; ok {
+		out.UpgradeStrategy.Type = t
+	}
+
+	tmpl := &out.Template
+	tmpl.Image = in.Template.Image
+	tmpl.ImagePullPolicy = ecsmv1.ImagePullPolicyType(in.Template.ImagePullPolicy)
+	tmpl.Hostname = in.Template.Hostname
+	tmpl.Command = append([]string(nil), in.Template.Command...)
+
+	// 值为空的环境变量和主机路径为空的挂载点可能是 v1 中引用配置的项，保留它们的引用
+	valueFrom := make(map[string]*ecsmv1.EnvVarSource)
+	for _, e := range tmpl.Env {
+		if e.ValueFrom != nil {
+			valueFrom[e.Name] = e.ValueFrom
+		}
+	}
+	tmpl.Env = nil
+	for _, e := range in.Template.Env {
+		env := ecsmv1.EnvVar{Name: e.Name, Value: e.Value}
+		if e.Value == "" {
+			env.ValueFrom = valueFrom[e.Name]
+		}
+		tmpl.Env = append(tmpl.Env, env)
+	}
+	hostPathFrom := make(map[string]*ecsmv1.ConfigKeySelector)
+	for _, vm := range tmpl.VolumeMounts {
+		if vm.HostPathFrom != nil {
+			hostPathFrom[vm.Name] = vm.HostPathFrom
+		}
+	}
+	tmpl.VolumeMounts = nil
+	for _, vm := range in.Template.VolumeMounts {
+		mount := ecsmv1.VolumeMount{Name: vm.Name, HostPath: vm.HostPath, ContainerPath: vm.ContainerPath, ReadOnly: vm.ReadOnly}
+		if vm.HostPath == "" {
+			mount.HostPathFrom = hostPathFrom[vm.Name]
+		}
+		tmpl.VolumeMounts = append(tmpl.VolumeMounts, mount)
+	}
+
+	limits := make(map[ecsmv1.ResourceType]string)
+	if tmpl.Resources != nil {
+		for k, v := range tmpl.Resources.Limits {
+			limits[k] = v
+		}
+	}
+	setLimit := func(key ecsmv1.ResourceType, value string) {
+		if value == "" {
+			delete(limits, key)
+		} else {
+			limits[key] = value
+		}
+	}
+	setLimit(ecsmv1.ResourceTypeMemory, in.Template.MemoryLimit)
+	setLimit(ecsmv1.ResourceTypeDisk, in.Template.DiskLimit)
+	if len(limits) == 0 {
+		tmpl.Resources = nil
+	} else {
+		tmpl.Resources = &ecsmv1.ResourceRequirements{Limits: limits}
+	}
+}
+
+// convertSpecFromV1 返回 v1 的 spec 中能够用 v1alpha1 表示的部分。
+func convertSpecFromV1(in *ecsmv1.ECSMServiceSpec) ECSMServiceSpec {
+	var out ECSMServiceSpec
+	switch in.DeploymentStrategy.Type {
+	case ecsmv1.DeploymentStrategyTypeStatic:
+		out.Nodes = append([]string(nil), in.DeploymentStrategy.Nodes...)
+	default:
+		out.NodePool = append([]string(nil), in.DeploymentStrategy.NodePool...)
+		if in.DeploymentStrategy.Replicas != nil {
+			replicas := *in.DeploymentStrategy.Replicas
+			out.Replicas = &replicas
+		}
+	}
+
+	out.AutoUpgrade = string(in.UpgradeStrategy.Type)
+	for alpha, t := range upgradeTypes {
+		if t == in.UpgradeStrategy.Type {
+			out.AutoUpgrade = alpha
+		}
+	}
+
+	tmpl := &out.Template
+	tmpl.Image = in.Template.Image
+	tmpl.ImagePullPolicy = string(in.Template.ImagePullPolicy)
+	tmpl.Hostname = in.Template.Hostname
+	tmpl.Command = append([]string(nil), in.Template.Command...)
+	for _, e := range in.Template.Env {
+		tmpl.Env = append(tmpl.Env, EnvVar{Name: e.Name, Value: e.Value})
+	}
+	for _, vm := range in.Template.VolumeMounts {
+		tmpl.VolumeMounts = append(tmpl.VolumeMounts, VolumeMount{
+			Name: vm.Name, HostPath: vm.HostPath, ContainerPath: vm.ContainerPath, ReadOnly: vm.ReadOnly,
+		})
+	}
+	if in.Template.Resources != nil {
+		tmpl.MemoryLimit = in.Template.Resources.Limits[ecsmv1.ResourceTypeMemory]
+		tmpl.DiskLimit = in.Template.Resources.Limits[ecsmv1.ResourceTypeDisk]
+	}
+	return out
+}
+
+func copyConditions(in []metav1.Condition) []metav1.Condition {
+	if in == nil {
+		return nil
+	}
+	out := make([]metav1.Condition, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func TestConvertToHub(t *testing.T) {
+	alpha := &ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: ECSMServiceSpec{
+			Replicas:    int32Ptr(3),
+			NodePool:    []string{"node-a", "node-b"},
+			AutoUpgrade: "larger",
+			Template: ContainerTemplateSpec{
+				Image:       "web@1.0",
+				Env:         []EnvVar{{Name: "MODE", Value: "prod"}},
+				MemoryLimit: "64M",
+			},
+		},
+	}
+
+	hub := &ecsmv1.ECSMService{}
+	if err := alpha.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	want := ecsmv1.ECSMServiceSpec{
+		DeploymentStrategy: ecsmv1.DeploymentStrategy{
+			Type:     ecsmv1.DeploymentStrategyTypeDynamic,
+			Replicas: int32Ptr(3),
+			NodePool: []string{"node-a", "node-b"},
+		},
+		UpgradeStrategy: ecsmv1.UpgradeStrategy{Type: ecsmv1.UpgradeStrategyTypeLarger},
+		Template: ecsmv1.ContainerTemplateSpec{
+			Image:     "web@1.0",
+			Env:       []ecsmv1.EnvVar{{Name: "MODE", Value: "prod"}},
+			Resources: &ecsmv1.ResourceRequirements{Limits: map[ecsmv1.ResourceType]string{ecsmv1.ResourceTypeMemory: "64M"}},
+		},
+	}
+	if !equality.Semantic.DeepEqual(hub.Spec, want) {
+		t.Errorf("ConvertTo() spec = %+v, want %+v", hub.Spec, want)
+	}
+	if hub.APIVersion != "ecsm.sh/v1" {
+		t.Errorf("ConvertTo() apiVersion = %q, want ecsm.sh/v1", hub.APIVersion)
+	}
+
+	// v1alpha1 -> v1 -> v1alpha1 不丢失字段
+	back := &ECSMService{}
+	if err := back.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if !equality.Semantic.DeepEqual(back.Spec, alpha.Spec) {
+		t.Errorf("round trip spec = %+v, want %+v", back.Spec, alpha.Spec)
+	}
+	if _, ok := back.Annotations[ConversionDataAnnotation]; ok {
+		t.Errorf("lossless conversion should not add the %s annotation", ConversionDataAnnotation)
+	}
+}
+
+func TestHubRoundTrip(t *testing.T) {
+	maxSurge := intstr.FromString("50%")
+	hub := &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Annotations: map[string]string{"team": "a"}},
+		Spec: ecsmv1.ECSMServiceSpec{
+			Cluster: "edge",
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:         ecsmv1.DeploymentStrategyTypeStatic,
+				Nodes:        []string{"node-a"},
+				NodeSelector: map[string]string{"zone": "east"},
+			},
+			UpgradeStrategy: ecsmv1.UpgradeStrategy{Type: ecsmv1.UpgradeStrategyTypeAlways, MaxSurge: &maxSurge},
+			Template: ecsmv1.ContainerTemplateSpec{
+				Image: "web@2.0",
+				Env: []ecsmv1.EnvVar{
+					{Name: "MODE", Value: "prod"},
+					{Name: "TOKEN", ValueFrom: &ecsmv1.EnvVarSource{SecretKeyRef: &ecsmv1.SecretKeySelector{Name: "creds", Key: "token"}}},
+				},
+				VSOA: &ecsmv1.VSOASpec{Port: int32Ptr(3000)},
+			},
+			DriftPolicy: ecsmv1.DriftPolicyReportOnly,
+		},
+	}
+
+	alpha := &ECSMService{}
+	if err := alpha.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if _, ok := alpha.Annotations[ConversionDataAnnotation]; !ok {
+		t.Fatalf("expected the %s annotation for fields v1alpha1 cannot represent", ConversionDataAnnotation)
+	}
+	if alpha.Spec.AutoUpgrade != "always" || len(alpha.Spec.Nodes) != 1 {
+		t.Errorf("ConvertFrom() spec = %+v", alpha.Spec)
+	}
+
+	// 在 v1alpha1 中修改的字段覆盖注解中保存的值
+	alpha.Spec.Template.Image = "web@2.1"
+	restored := &ecsmv1.ECSMService{}
+	if err := alpha.ConvertTo(restored); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	want := hub.Spec.DeepCopy()
+	want.Template.Image = "web@2.1"
+	if !equality.Semantic.DeepEqual(&restored.Spec, want) {
+		t.Errorf("round trip spec = %+v, want %+v", restored.Spec, want)
+	}
+	if len(restored.Annotations) != 1 || restored.Annotations["team"] != "a" {
+		t.Errorf("round trip annotations = %v, want only the user annotations", restored.Annotations)
+	}
+}
+
+func TestSchemeConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := ecsmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	in := &ECSMServiceList{Items: []ECSMService{{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec:       ECSMServiceSpec{Nodes: []string{"node-a"}, Template: ContainerTemplateSpec{Image: "web@1.0"}},
+	}}}
+	out := &ecsmv1.ECSMServiceList{}
+	if err := scheme.Convert(in, out, nil); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(out.Items) != 1 || out.Items[0].Spec.DeploymentStrategy.Type != ecsmv1.DeploymentStrategyTypeStatic {
+		t.Errorf("Convert() = %+v, want one static service", out.Items)
+	}
+}
@@ -0,0 +1,9 @@
+// file: pkg/apis/ecsm/v1alpha1/doc.go
+
+// +k8s:deepcopy-gen=package
+// +groupName=ecsm.sh
+
+// Package v1alpha1 contains the deprecated v1alpha1 version of the ecsm API group.
+// 它只用于读取旧版本的清单和 Registry 中旧版本写入的对象，这些对象会被转换为 v1 使用。
+
+package v1alpha1
@@ -0,0 +1,116 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMService 是 v1alpha1 版本的 ECSMService。
+// 它的部署策略和升级策略直接使用 ECSM 平台的字段，v1 把它们整理为 deploymentStrategy 和 upgradeStrategy。
+type ECSMService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ECSMServiceSpec   `json:"spec,omitempty"`
+	Status ECSMServiceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ECSMServiceList 包含 ECSMService 的列表
+type ECSMServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ECSMService `json:"items"`
+}
+
+// ECSMServiceSpec 定义了ECSM服务的期望状态
+type ECSMServiceSpec struct {
+	// Nodes 非空时，在其中的每个节点上部署一个实例，对应 v1 的 Static 策略
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+
+	// Replicas 是 Nodes 为空时在 NodePool 中部署的实例数量，对应 v1 的 Dynamic 策略
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// NodePool 是 Nodes 为空时可供选择的节点
+	// +optional
+	NodePool []string `json:"nodePool,omitempty"`
+
+	// AutoUpgrade 是 ECSM 的镜像自动更新策略，取值为 "never"、"larger" 或 "always"
+	// +kubebuilder:validation:Enum=never;larger;always
+	// +optional
+	AutoUpgrade string `json:"autoUpgrade,omitempty"`
+
+	// Template 是创建新容器实例的模版
+	// +required
+	Template ContainerTemplateSpec `json:"template"`
+}
+
+// ContainerTemplateSpec 定义了容器模版
+type ContainerTemplateSpec struct {
+	// Image 是要运行的容器镜像引用，格式为 "name@tag"
+	// +required
+	Image string `json:"image"`
+
+	// ImagePullPolicy 定义了镜像拉取策略
+	// +optional
+	ImagePullPolicy string `json:"imagePullPolicy,omitempty"`
+
+	// Hostname 定义了容器的主机名
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// Command 是容器的入口点
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Env 是要注入到容器中的环境变量列表
+	// +optional
+	Env []EnvVar `json:"env,omitempty"`
+
+	// MemoryLimit 是容器的内存限制，对应 v1 的 resources.limits.memory
+	// +optional
+	MemoryLimit string `json:"memoryLimit,omitempty"`
+
+	// DiskLimit 是容器的硬盘限制，对应 v1 的 resources.limits.disk
+	// +optional
+	DiskLimit string `json:"diskLimit,omitempty"`
+
+	// VolumeMounts 是要挂载到容器中的卷列表
+	// +optional
+	VolumeMounts []VolumeMount `json:"volumeMounts,omitempty"`
+}
+
+// EnvVar 代表一个环境变量
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// VolumeMount 定义了共享库的挂载点
+type VolumeMount struct {
+	Name          string `json:"name"`
+	HostPath      string `json:"hostPath"`
+	ContainerPath string `json:"containerPath"`
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// ECSMServiceStatus 定义了 ECSMService 的状态
+type ECSMServiceStatus struct {
+	Replicas      int32 `json:"replicas"`
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// +optional
+	UnderlyingServiceID string `json:"underlyingServiceID,omitempty"`
+}
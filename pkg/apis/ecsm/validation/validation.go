@@ -0,0 +1,538 @@
+// file: pkg/apis/ecsm/validation/validation.go
+
+// Package validation 校验 ecsm.sh/v1 的对象。
+// 校验在默认值填充之后进行，Registry 在创建对象和修改对象的 spec 时调用它。
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"text/template"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// minResourceLimit 是资源限制的最小值。ECSM 以 MB 为单位设置限制，更小的值会被截断为 0
+var minResourceLimit = resource.MustParse("1Mi")
+
+// ValidateService 校验一个 ECSMService，返回所有不合法的字段。
+func ValidateService(service *ecsmv1.ECSMService) field.ErrorList {
+	var allErrs field.ErrorList
+	metaPath := field.NewPath("metadata")
+	if service.Name == "" {
+		allErrs = append(allErrs, field.Required(metaPath.Child("name"), ""))
+	}
+	if service.Namespace == "" {
+		allErrs = append(allErrs, field.Required(metaPath.Child("namespace"), ""))
+	}
+	allErrs = append(allErrs, ValidateServiceSpec(&service.Spec, field.NewPath("spec"))...)
+	return allErrs
+}
+
+// ValidateServiceSpec 校验 ECSMService 的 spec。
+func ValidateServiceSpec(spec *ecsmv1.ECSMServiceSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateDeploymentStrategy(&spec.DeploymentStrategy, fldPath.Child("deploymentStrategy"))...)
+	allErrs = append(allErrs, ValidateUpgradeStrategy(&spec.UpgradeStrategy, fldPath.Child("upgradeStrategy"))...)
+	allErrs = append(allErrs, ValidateContainerTemplate(&spec.Template, fldPath.Child("template"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabels(spec.Selector, fldPath.Child("selector"))...)
+
+	switch spec.DriftPolicy {
+	case "", ecsmv1.DriftPolicyEnforce, ecsmv1.DriftPolicyReportOnly:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("driftPolicy"), spec.DriftPolicy,
+			[]ecsmv1.DriftPolicyType{ecsmv1.DriftPolicyEnforce, ecsmv1.DriftPolicyReportOnly}))
+	}
+
+	if r := spec.Remediation; r != nil {
+		rPath := fldPath.Child("remediation")
+		switch r.Action {
+		case "", ecsmv1.RemediationActionRestart, ecsmv1.RemediationActionReschedule, ecsmv1.RemediationActionNone:
+		default:
+			allErrs = append(allErrs, field.NotSupported(rPath.Child("action"), r.Action,
+				[]ecsmv1.RemediationActionType{ecsmv1.RemediationActionRestart, ecsmv1.RemediationActionReschedule, ecsmv1.RemediationActionNone}))
+		}
+		if r.FailureThreshold != nil && *r.FailureThreshold < 1 {
+			allErrs = append(allErrs, field.Invalid(rPath.Child("failureThreshold"), *r.FailureThreshold, "must be at least 1"))
+		}
+		if r.MaxRemediations != nil && *r.MaxRemediations < 0 {
+			allErrs = append(allErrs, field.Invalid(rPath.Child("maxRemediations"), *r.MaxRemediations, "must not be negative"))
+		}
+	}
+
+	if spec.PriorityClassName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.PriorityClassName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("priorityClassName"), spec.PriorityClassName, msg))
+		}
+	}
+
+	if a := spec.Affinity; a != nil {
+		aPath := fldPath.Child("affinity")
+		for i := range a.ServiceAffinity {
+			allErrs = append(allErrs, validateServiceAffinityTerm(&a.ServiceAffinity[i], aPath.Child("serviceAffinity").Index(i))...)
+		}
+		for i := range a.ServiceAntiAffinity {
+			allErrs = append(allErrs, validateServiceAffinityTerm(&a.ServiceAntiAffinity[i], aPath.Child("serviceAntiAffinity").Index(i))...)
+		}
+	}
+	return allErrs
+}
+
+func validateServiceAffinityTerm(term *ecsmv1.ServiceAffinityTerm, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(term.LabelSelector,
+		metav1validation.LabelSelectorValidationOptions{}, fldPath.Child("labelSelector"))...)
+	for i, ns := range term.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespaces").Index(i), ns, msg))
+		}
+	}
+	if term.TopologyKey != "" {
+		for _, msg := range validation.IsQualifiedName(term.TopologyKey) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("topologyKey"), term.TopologyKey, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateDeploymentStrategy 校验部署策略：Static 策略需要节点列表，Dynamic 策略需要副本数。
+func validateDeploymentStrategy(strategy *ecsmv1.DeploymentStrategy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch strategy.Type {
+	case ecsmv1.DeploymentStrategyTypeStatic:
+		if len(strategy.Nodes) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("nodes"), "at least one node is required by the Static strategy"))
+		}
+		allErrs = append(allErrs, validateNodeNames(strategy.Nodes, fldPath.Child("nodes"))...)
+	case ecsmv1.DeploymentStrategyTypeDynamic:
+		if strategy.Replicas == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("replicas"), "replicas is required by the Dynamic strategy"))
+		} else if *strategy.Replicas < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *strategy.Replicas, "must not be negative"))
+		}
+		if len(strategy.Nodes) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("nodes"), "may only be set for the Static strategy, use nodePool instead"))
+		}
+		allErrs = append(allErrs, validateNodeNames(strategy.NodePool, fldPath.Child("nodePool"))...)
+	case "":
+		allErrs = append(allErrs, field.Required(fldPath.Child("type"), ""))
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), strategy.Type,
+			[]ecsmv1.DeploymentStrategyType{ecsmv1.DeploymentStrategyTypeStatic, ecsmv1.DeploymentStrategyTypeDynamic}))
+	}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(strategy.NodeSelector, fldPath.Child("nodeSelector"))...)
+	for i, t := range strategy.Tolerations {
+		allErrs = append(allErrs, validateToleration(&t, fldPath.Child("tolerations").Index(i))...)
+	}
+	return allErrs
+}
+
+func validateNodeNames(nodes []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := sets.New[string]()
+	for i, node := range nodes {
+		switch {
+		case node == "":
+			allErrs = append(allErrs, field.Required(fldPath.Index(i), "node name must not be empty"))
+		case seen.Has(node):
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), node))
+		}
+		seen.Insert(node)
+	}
+	return allErrs
+}
+
+func validateToleration(t *ecsmv1.Toleration, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch t.Operator {
+	case "", ecsmv1.TolerationOpEqual:
+		if t.Key == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("key"), "key is required when operator is Equal"))
+		}
+	case ecsmv1.TolerationOpExists:
+		if t.Value != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), t.Value, "must be empty when operator is Exists"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("operator"), t.Operator,
+			[]ecsmv1.TolerationOperator{ecsmv1.TolerationOpEqual, ecsmv1.TolerationOpExists}))
+	}
+	switch t.Effect {
+	case "", ecsmv1.TaintEffectNoSchedule, ecsmv1.TaintEffectPreferNoSchedule, ecsmv1.TaintEffectNoExecute:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("effect"), t.Effect,
+			[]ecsmv1.TaintEffect{ecsmv1.TaintEffectNoSchedule, ecsmv1.TaintEffectPreferNoSchedule, ecsmv1.TaintEffectNoExecute}))
+	}
+	return allErrs
+}
+
+// ValidateUpgradeStrategy 校验升级策略。
+func ValidateUpgradeStrategy(strategy *ecsmv1.UpgradeStrategy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch strategy.Type {
+	case "", ecsmv1.UpgradeStrategyTypeNever, ecsmv1.UpgradeStrategyTypeLarger, ecsmv1.UpgradeStrategyTypeAlways:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), strategy.Type,
+			[]ecsmv1.UpgradeStrategyType{ecsmv1.UpgradeStrategyTypeNever, ecsmv1.UpgradeStrategyTypeLarger, ecsmv1.UpgradeStrategyTypeAlways}))
+	}
+
+	surgeZero, surgeErrs := validateIntOrPercent(strategy.MaxSurge, fldPath.Child("maxSurge"))
+	unavailableZero, unavailableErrs := validateIntOrPercent(strategy.MaxUnavailable, fldPath.Child("maxUnavailable"))
+	allErrs = append(allErrs, surgeErrs...)
+	allErrs = append(allErrs, unavailableErrs...)
+	if surgeZero && unavailableZero {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), strategy.MaxUnavailable.String(),
+			"must not be 0 when maxSurge is 0"))
+	}
+
+	switch strategy.Rollout {
+	case "", ecsmv1.RolloutTypeRollingUpdate, ecsmv1.RolloutTypeCanary, ecsmv1.RolloutTypeBlueGreen:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("rollout"), strategy.Rollout,
+			[]ecsmv1.RolloutType{ecsmv1.RolloutTypeRollingUpdate, ecsmv1.RolloutTypeCanary, ecsmv1.RolloutTypeBlueGreen}))
+	}
+
+	if canary := strategy.Canary; canary != nil {
+		stepsPath := fldPath.Child("canary", "steps")
+		var last int32
+		for i, step := range canary.Steps {
+			switch {
+			case step.Weight < 1 || step.Weight > 100:
+				allErrs = append(allErrs, field.Invalid(stepsPath.Index(i).Child("weight"), step.Weight, validation.InclusiveRangeError(1, 100)))
+			case step.Weight <= last:
+				allErrs = append(allErrs, field.Invalid(stepsPath.Index(i).Child("weight"), step.Weight, "must be greater than the weight of the previous step"))
+			}
+			last = step.Weight
+			if step.PauseSeconds != nil && *step.PauseSeconds < 0 {
+				allErrs = append(allErrs, field.Invalid(stepsPath.Index(i).Child("pauseSeconds"), *step.PauseSeconds, "must not be negative"))
+			}
+		}
+	}
+	if bg := strategy.BlueGreen; bg != nil && bg.PromotionDelaySeconds != nil && *bg.PromotionDelaySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("blueGreen", "promotionDelaySeconds"), *bg.PromotionDelaySeconds, "must not be negative"))
+	}
+	return allErrs
+}
+
+// validateIntOrPercent 校验一个非负的整数或百分比，并返回它是否为 0。
+func validateIntOrPercent(v *intstr.IntOrString, fldPath *field.Path) (bool, field.ErrorList) {
+	if v == nil {
+		return false, nil
+	}
+	if v.Type == intstr.Int {
+		if v.IntVal < 0 {
+			return false, field.ErrorList{field.Invalid(fldPath, v.IntVal, "must not be negative")}
+		}
+		return v.IntVal == 0, nil
+	}
+	if msgs := validation.IsValidPercent(v.StrVal); len(msgs) > 0 {
+		return false, field.ErrorList{field.Invalid(fldPath, v.StrVal, strings.Join(msgs, ", "))}
+	}
+	percent, _ := intstr.GetScaledValueFromIntOrPercent(v, 100, true)
+	return percent == 0, nil
+}
+
+// ValidateContainerTemplate 校验容器模板。
+func ValidateContainerTemplate(template *ecsmv1.ContainerTemplateSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateImageRef(template.Image, fldPath.Child("image"))...)
+
+	switch template.ImagePullPolicy {
+	case "", ecsmv1.ImagePullPolicyAlways, ecsmv1.ImagePullPolicyIfNotPresent, ecsmv1.ImagePullPolicyNever:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("imagePullPolicy"), template.ImagePullPolicy,
+			[]ecsmv1.ImagePullPolicyType{ecsmv1.ImagePullPolicyAlways, ecsmv1.ImagePullPolicyIfNotPresent, ecsmv1.ImagePullPolicyNever}))
+	}
+
+	envPath := fldPath.Child("env")
+	for i, e := range template.Env {
+		allErrs = append(allErrs, validateEnvVar(&e, envPath.Index(i))...)
+	}
+	envFromPath := fldPath.Child("envFrom")
+	for i, from := range template.EnvFrom {
+		allErrs = append(allErrs, validateEnvFrom(&from, envFromPath.Index(i))...)
+	}
+
+	if r := template.Resources; r != nil {
+		limitsPath := fldPath.Child("resources", "limits")
+		for name, value := range r.Limits {
+			keyPath := limitsPath.Key(string(name))
+			if name != ecsmv1.ResourceTypeMemory && name != ecsmv1.ResourceTypeDisk {
+				allErrs = append(allErrs, field.NotSupported(keyPath, name,
+					[]ecsmv1.ResourceType{ecsmv1.ResourceTypeMemory, ecsmv1.ResourceTypeDisk}))
+				continue
+			}
+			q, err := resource.ParseQuantity(value)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(keyPath, value, err.Error()))
+				continue
+			}
+			if q.Cmp(minResourceLimit) < 0 {
+				allErrs = append(allErrs, field.Invalid(keyPath, value, fmt.Sprintf("must be at least %s", minResourceLimit.String())))
+			}
+		}
+	}
+
+	vmPath := fldPath.Child("volumeMounts")
+	names := sets.New[string]()
+	for i, vm := range template.VolumeMounts {
+		p := vmPath.Index(i)
+		if vm.Name == "" {
+			allErrs = append(allErrs, field.Required(p.Child("name"), ""))
+		} else if names.Has(vm.Name) {
+			allErrs = append(allErrs, field.Duplicate(p.Child("name"), vm.Name))
+		}
+		names.Insert(vm.Name)
+		if vm.ContainerPath == "" {
+			allErrs = append(allErrs, field.Required(p.Child("containerPath"), ""))
+		} else if !path.IsAbs(vm.ContainerPath) {
+			allErrs = append(allErrs, field.Invalid(p.Child("containerPath"), vm.ContainerPath, "must be an absolute path"))
+		}
+		if vm.HostPathFrom != nil {
+			allErrs = append(allErrs, validateKeySelector(vm.HostPathFrom.Name, vm.HostPathFrom.Key, p.Child("hostPathFrom"))...)
+		} else if vm.HostPath == "" {
+			allErrs = append(allErrs, field.Required(p.Child("hostPath"), "hostPath or hostPathFrom is required"))
+		}
+	}
+
+	if vsoa := template.VSOA; vsoa != nil {
+		vsoaPath := fldPath.Child("vsoa")
+		if vsoa.Port != nil {
+			if msgs := validation.IsInRange(int(*vsoa.Port), 0, 65535); len(msgs) > 0 {
+				allErrs = append(allErrs, field.Invalid(vsoaPath.Child("port"), *vsoa.Port, strings.Join(msgs, ", ")))
+			}
+		}
+		if vsoa.PasswordFrom != nil {
+			allErrs = append(allErrs, validateKeySelector(vsoa.PasswordFrom.Name, vsoa.PasswordFrom.Key, vsoaPath.Child("passwordFrom"))...)
+		}
+		if hc := vsoa.HealthCheck; hc != nil {
+			allErrs = append(allErrs, validateHealthCheck(hc, vsoaPath.Child("healthCheck"))...)
+		}
+	}
+
+	allErrs = append(allErrs, validateProbes(template, fldPath)...)
+
+	if ps := template.PlatformSpecific; ps != nil {
+		switch ps.Action {
+		case "", ecsmv1.ActionTypeRun, ecsmv1.ActionTypeLoad:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("platformSpecific", "action"), ps.Action,
+				[]ecsmv1.ActionType{ecsmv1.ActionTypeRun, ecsmv1.ActionTypeLoad}))
+		}
+	}
+	return allErrs
+}
+
+// validateImageRef 校验 "name@tag" 格式的镜像引用，tag 之后可以带有 "#os" 后缀。
+func validateImageRef(image string, fldPath *field.Path) field.ErrorList {
+	if image == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	if strings.ContainsAny(image, " \t\n") {
+		return field.ErrorList{field.Invalid(fldPath, image, "must not contain whitespace")}
+	}
+	name, tag, found := strings.Cut(image, "@")
+	tag, suffix, hasSuffix := strings.Cut(tag, "#")
+	if name == "" || !found || tag == "" || (hasSuffix && suffix == "") || strings.Contains(tag, "@") {
+		return field.ErrorList{field.Invalid(fldPath, image, `must be in the form "name@tag" or "name@tag#os", e.g. "app@1.0"`)}
+	}
+	return nil
+}
+
+func validateEnvVar(e *ecsmv1.EnvVar, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if e.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	} else {
+		for _, msg := range validation.IsEnvVarName(e.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), e.Name, msg))
+		}
+	}
+	if from := e.ValueFrom; from != nil {
+		fromPath := fldPath.Child("valueFrom")
+		if e.Value != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), e.Value, "may not be set when valueFrom is set"))
+		}
+		switch {
+		case from.ConfigKeyRef != nil && from.SecretKeyRef != nil:
+			allErrs = append(allErrs, field.Invalid(fromPath, "", "may not have more than one source specified"))
+		case from.ConfigKeyRef != nil:
+			allErrs = append(allErrs, validateKeySelector(from.ConfigKeyRef.Name, from.ConfigKeyRef.Key, fromPath.Child("configKeyRef"))...)
+		case from.SecretKeyRef != nil:
+			allErrs = append(allErrs, validateKeySelector(from.SecretKeyRef.Name, from.SecretKeyRef.Key, fromPath.Child("secretKeyRef"))...)
+		default:
+			allErrs = append(allErrs, field.Invalid(fromPath, "", "must specify one of configKeyRef or secretKeyRef"))
+		}
+	}
+	return allErrs
+}
+
+func validateEnvFrom(from *ecsmv1.EnvFromSource, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if from.Prefix != "" {
+		for _, msg := range validation.IsEnvVarName(from.Prefix) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("prefix"), from.Prefix, msg))
+		}
+	}
+	switch {
+	case from.ConfigRef != nil && from.SecretRef != nil:
+		allErrs = append(allErrs, field.Invalid(fldPath, "", "may not have more than one source specified"))
+	case from.ConfigRef != nil:
+		if from.ConfigRef.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("configRef", "name"), ""))
+		}
+	case from.SecretRef != nil:
+		if from.SecretRef.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("secretRef", "name"), ""))
+		}
+	default:
+		allErrs = append(allErrs, field.Invalid(fldPath, "", "must specify one of configRef or secretRef"))
+	}
+	return allErrs
+}
+
+func validateKeySelector(name, key string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+	if key == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("key"), ""))
+	}
+	return allErrs
+}
+
+// validateHealthCheck 校验健康检查的时间参数，它们都不能为负数。
+func validateHealthCheck(hc *ecsmv1.HealthCheckSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, f := range []struct {
+		name  string
+		value int32
+	}{
+		{"initialDelaySeconds", hc.InitialDelaySeconds},
+		{"timeoutSeconds", hc.TimeoutSeconds},
+		{"periodSeconds", hc.PeriodSeconds},
+		{"failureThreshold", hc.FailureThreshold},
+	} {
+		if f.value < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(f.name), f.value, "must not be negative"))
+		}
+	}
+	return allErrs
+}
+
+// validateProbes 校验容器模板的存活探针和就绪探针。
+// ECSM 的每个容器只有一个 VSOA 健康检查，所以 VSOA 探针不能与 vsoa.healthCheck 或另一个 VSOA 探针同时使用。
+func validateProbes(template *ecsmv1.ContainerTemplateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	vsoaProbes := 0
+	for _, p := range []struct {
+		name  string
+		probe *ecsmv1.Probe
+	}{
+		{"livenessProbe", template.LivenessProbe},
+		{"readinessProbe", template.ReadinessProbe},
+	} {
+		if p.probe == nil {
+			continue
+		}
+		probePath := fldPath.Child(p.name)
+		allErrs = append(allErrs, validateProbeHandler(&p.probe.ProbeHandler, probePath)...)
+		allErrs = append(allErrs, validateHealthCheck(&p.probe.HealthCheckSpec, probePath)...)
+
+		if p.probe.VSOA == nil {
+			continue
+		}
+		vsoaProbes++
+		switch {
+		case template.VSOA == nil:
+			allErrs = append(allErrs, field.Required(fldPath.Child("vsoa"), fmt.Sprintf("required by %s.vsoa", p.name)))
+		case template.VSOA.HealthCheck != nil:
+			allErrs = append(allErrs, field.Forbidden(probePath.Child("vsoa"), "may not be used together with vsoa.healthCheck"))
+		case vsoaProbes > 1:
+			allErrs = append(allErrs, field.Forbidden(probePath.Child("vsoa"), "only one probe may use vsoa"))
+		}
+	}
+	return allErrs
+}
+
+// validateProbeHandler 校验探针必须且只能设置一种检查方式。
+func validateProbeHandler(handler *ecsmv1.ProbeHandler, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	handlers := 0
+	if handler.VSOA != nil {
+		handlers++
+	}
+	if tcp := handler.TCPSocket; tcp != nil {
+		handlers++
+		if msgs := validation.IsValidPortNum(int(tcp.Port)); len(msgs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("tcpSocket", "port"), tcp.Port, strings.Join(msgs, ", ")))
+		}
+	}
+	if exec := handler.Exec; exec != nil {
+		handlers++
+		if len(exec.Command) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("exec", "command"), ""))
+		}
+	}
+	switch {
+	case handlers == 0:
+		allErrs = append(allErrs, field.Required(fldPath, "must specify one of vsoa, tcpSocket or exec"))
+	case handlers > 1:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "may not specify more than one of vsoa, tcpSocket or exec"))
+	}
+	return allErrs
+}
+
+// ValidateNotification 校验一个 ECSMNotification，返回所有不合法的字段。
+func ValidateNotification(notification *ecsmv1.ECSMNotification) field.ErrorList {
+	var allErrs field.ErrorList
+	if notification.Name == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("metadata", "name"), ""))
+	}
+
+	specPath := field.NewPath("spec")
+	spec := &notification.Spec
+	for i, t := range spec.Selector.Types {
+		if t != ecsmv1.EventTypeNormal && t != ecsmv1.EventTypeWarning {
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("selector", "types").Index(i), t,
+				[]string{ecsmv1.EventTypeNormal, ecsmv1.EventTypeWarning}))
+		}
+	}
+
+	webhookPath := specPath.Child("webhook")
+	switch spec.Webhook.Type {
+	case "", ecsmv1.NotificationWebhookGeneric, ecsmv1.NotificationWebhookSlack, ecsmv1.NotificationWebhookDingTalk:
+	default:
+		allErrs = append(allErrs, field.NotSupported(webhookPath.Child("type"), spec.Webhook.Type, []string{
+			string(ecsmv1.NotificationWebhookGeneric), string(ecsmv1.NotificationWebhookSlack), string(ecsmv1.NotificationWebhookDingTalk),
+		}))
+	}
+	if spec.Webhook.URL == "" {
+		allErrs = append(allErrs, field.Required(webhookPath.Child("url"), ""))
+	} else if u, err := url.Parse(spec.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(webhookPath.Child("url"), spec.Webhook.URL, "must be an http or https URL"))
+	}
+	if spec.Webhook.TimeoutSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(webhookPath.Child("timeoutSeconds"), spec.Webhook.TimeoutSeconds, "must not be negative"))
+	}
+
+	if spec.Template != "" {
+		if _, err := template.New("notification").Parse(spec.Template); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("template"), spec.Template, err.Error()))
+		}
+	}
+	if spec.MaxPerMinute < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxPerMinute"), spec.MaxPerMinute, "must not be negative"))
+	}
+	return allErrs
+}
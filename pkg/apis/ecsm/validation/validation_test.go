@@ -0,0 +1,169 @@
+package validation
+
+import (
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func newValidService() *ecsmv1.ECSMService {
+	return &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:     ecsmv1.DeploymentStrategyTypeDynamic,
+				Replicas: int32Ptr(2),
+				NodePool: []string{"node-a", "node-b"},
+			},
+			Template: ecsmv1.ContainerTemplateSpec{
+				Image:     "web@1.0#sylixos",
+				Env:       []ecsmv1.EnvVar{{Name: "MODE", Value: "prod"}},
+				Resources: &ecsmv1.ResourceRequirements{Limits: map[ecsmv1.ResourceType]string{ecsmv1.ResourceTypeMemory: "64Mi"}},
+				VolumeMounts: []ecsmv1.VolumeMount{
+					{Name: "data", HostPath: "/data", ContainerPath: "/mnt/data"},
+				},
+				VSOA: &ecsmv1.VSOASpec{Port: int32Ptr(3000)},
+			},
+		},
+	}
+}
+
+func TestValidateService(t *testing.T) {
+	if errs := ValidateService(newValidService()); len(errs) > 0 {
+		t.Fatalf("ValidateService() on a valid service = %v", errs)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*ecsmv1.ECSMService)
+		field  string
+	}{
+		{"dynamic without replicas", func(s *ecsmv1.ECSMService) { s.Spec.DeploymentStrategy.Replicas = nil }, "spec.deploymentStrategy.replicas"},
+		{"static without nodes", func(s *ecsmv1.ECSMService) {
+			s.Spec.DeploymentStrategy = ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeStatic}
+		}, "spec.deploymentStrategy.nodes"},
+		{"duplicate node", func(s *ecsmv1.ECSMService) { s.Spec.DeploymentStrategy.NodePool = []string{"a", "a"} }, "spec.deploymentStrategy.nodePool[1]"},
+		{"unknown strategy", func(s *ecsmv1.ECSMService) { s.Spec.DeploymentStrategy.Type = "Random" }, "spec.deploymentStrategy.type"},
+		{"image without tag", func(s *ecsmv1.ECSMService) { s.Spec.Template.Image = "web" }, "spec.template.image"},
+		{"image with empty os", func(s *ecsmv1.ECSMService) { s.Spec.Template.Image = "web@1.0#" }, "spec.template.image"},
+		{"unparsable memory", func(s *ecsmv1.ECSMService) {
+			s.Spec.Template.Resources.Limits[ecsmv1.ResourceTypeMemory] = "lots"
+		}, "spec.template.resources.limits[memory]"},
+		{"memory below 1Mi", func(s *ecsmv1.ECSMService) {
+			s.Spec.Template.Resources.Limits[ecsmv1.ResourceTypeMemory] = "512Ki"
+		}, "spec.template.resources.limits[memory]"},
+		{"unknown resource", func(s *ecsmv1.ECSMService) { s.Spec.Template.Resources.Limits["cpu"] = "1" }, "spec.template.resources.limits[cpu]"},
+		{"port out of range", func(s *ecsmv1.ECSMService) { s.Spec.Template.VSOA.Port = int32Ptr(70000) }, "spec.template.vsoa.port"},
+		{"invalid env name", func(s *ecsmv1.ECSMService) { s.Spec.Template.Env[0].Name = "1=MODE" }, "spec.template.env[0].name"},
+		{"env with value and valueFrom", func(s *ecsmv1.ECSMService) {
+			s.Spec.Template.Env[0].ValueFrom = &ecsmv1.EnvVarSource{ConfigKeyRef: &ecsmv1.ConfigKeySelector{Name: "cfg", Key: "mode"}}
+		}, "spec.template.env[0].value"},
+		{"relative container path", func(s *ecsmv1.ECSMService) { s.Spec.Template.VolumeMounts[0].ContainerPath = "data" }, "spec.template.volumeMounts[0].containerPath"},
+		{"both fenceposts zero", func(s *ecsmv1.ECSMService) {
+			zero := intstr.FromInt32(0)
+			percent := intstr.FromString("0%")
+			s.Spec.UpgradeStrategy.MaxSurge, s.Spec.UpgradeStrategy.MaxUnavailable = &zero, &percent
+		}, "spec.upgradeStrategy.maxUnavailable"},
+		{"canary weights not increasing", func(s *ecsmv1.ECSMService) {
+			s.Spec.UpgradeStrategy.Canary = &ecsmv1.CanaryStrategy{Steps: []ecsmv1.CanaryStep{{Weight: 50}, {Weight: 20}}}
+		}, "spec.upgradeStrategy.canary.steps[1].weight"},
+		{"invalid topology key", func(s *ecsmv1.ECSMService) {
+			s.Spec.Affinity = &ecsmv1.Affinity{ServiceAntiAffinity: []ecsmv1.ServiceAffinityTerm{{TopologyKey: "-zone"}}}
+		}, "spec.affinity.serviceAntiAffinity[0].topologyKey"},
+		{"invalid affinity selector", func(s *ecsmv1.ECSMService) {
+			s.Spec.Affinity = &ecsmv1.Affinity{ServiceAffinity: []ecsmv1.ServiceAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Near"}}},
+			}}}
+		}, "spec.affinity.serviceAffinity[0].labelSelector.matchExpressions[0].operator"},
+		{"invalid priority class name", func(s *ecsmv1.ECSMService) { s.Spec.PriorityClassName = "High_Priority" }, "spec.priorityClassName"},
+		{"probe without handler", func(s *ecsmv1.ECSMService) {
+			s.Spec.Template.LivenessProbe = &ecsmv1.Probe{}
+		}, "spec.template.livenessProbe"},
+		{"probe with two handlers", func(s *ecsmv1.ECSMService) {
+			s.Spec.Template.ReadinessProbe = &ecsmv1.Probe{ProbeHandler: ecsmv1.ProbeHandler{
+				TCPSocket: &ecsmv1.TCPSocketAction{Port: 80},
+				Exec:      &ecsmv1.ExecAction{Command: []string{"true"}},
+			}}
+		}, "spec.template.readinessProbe"},
+		{"tcp probe without port", func(s *ecsmv1.ECSMService) {
+			s.Spec.Template.LivenessProbe = &ecsmv1.Probe{ProbeHandler: ecsmv1.ProbeHandler{TCPSocket: &ecsmv1.TCPSocketAction{}}}
+		}, "spec.template.livenessProbe.tcpSocket.port"},
+		{"vsoa probe without vsoa", func(s *ecsmv1.ECSMService) {
+			s.Spec.Template.VSOA = nil
+			s.Spec.Template.LivenessProbe = &ecsmv1.Probe{ProbeHandler: ecsmv1.ProbeHandler{VSOA: &ecsmv1.VSOAProbeAction{}}}
+		}, "spec.template.vsoa"},
+		{"two vsoa probes", func(s *ecsmv1.ECSMService) {
+			vsoa := ecsmv1.ProbeHandler{VSOA: &ecsmv1.VSOAProbeAction{}}
+			s.Spec.Template.LivenessProbe = &ecsmv1.Probe{ProbeHandler: vsoa}
+			s.Spec.Template.ReadinessProbe = &ecsmv1.Probe{ProbeHandler: vsoa}
+		}, "spec.template.readinessProbe.vsoa"},
+		{"negative probe period", func(s *ecsmv1.ECSMService) {
+			s.Spec.Template.ReadinessProbe = &ecsmv1.Probe{
+				ProbeHandler:    ecsmv1.ProbeHandler{Exec: &ecsmv1.ExecAction{Command: []string{"true"}}},
+				HealthCheckSpec: ecsmv1.HealthCheckSpec{PeriodSeconds: -1},
+			}
+		}, "spec.template.readinessProbe.periodSeconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newValidService()
+			tt.mutate(service)
+			errs := ValidateService(service)
+			if len(errs) == 0 {
+				t.Fatalf("ValidateService() succeeded, want an error for %s", tt.field)
+			}
+			for _, err := range errs {
+				if err.Field == tt.field {
+					return
+				}
+			}
+			t.Errorf("ValidateService() = %v, want an error for %s", errs, tt.field)
+		})
+	}
+}
+
+func TestValidateNotification(t *testing.T) {
+	newNotification := func() *ecsmv1.ECSMNotification {
+		return &ecsmv1.ECSMNotification{
+			ObjectMeta: metav1.ObjectMeta{Name: "ops"},
+			Spec: ecsmv1.ECSMNotificationSpec{
+				Selector: ecsmv1.NotificationSelector{Types: []string{ecsmv1.EventTypeWarning}},
+				Webhook:  ecsmv1.NotificationWebhook{Type: ecsmv1.NotificationWebhookSlack, URL: "https://hooks.example.com/ops"},
+				Template: "{{.Reason}}: {{.Message}}",
+			},
+		}
+	}
+	if errs := ValidateNotification(newNotification()); len(errs) > 0 {
+		t.Fatalf("ValidateNotification() = %v, want no errors", errs)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(n *ecsmv1.ECSMNotification)
+		field  string
+	}{
+		{"unknown event type", func(n *ecsmv1.ECSMNotification) { n.Spec.Selector.Types = []string{"Error"} }, "spec.selector.types[0]"},
+		{"unknown webhook type", func(n *ecsmv1.ECSMNotification) { n.Spec.Webhook.Type = "Teams" }, "spec.webhook.type"},
+		{"missing url", func(n *ecsmv1.ECSMNotification) { n.Spec.Webhook.URL = "" }, "spec.webhook.url"},
+		{"non-http url", func(n *ecsmv1.ECSMNotification) { n.Spec.Webhook.URL = "ftp://example.com" }, "spec.webhook.url"},
+		{"invalid template", func(n *ecsmv1.ECSMNotification) { n.Spec.Template = "{{.Reason" }, "spec.template"},
+		{"negative rate", func(n *ecsmv1.ECSMNotification) { n.Spec.MaxPerMinute = -1 }, "spec.maxPerMinute"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNotification()
+			tt.mutate(n)
+			errs := ValidateNotification(n)
+			for _, err := range errs {
+				if err.Field == tt.field {
+					return
+				}
+			}
+			t.Errorf("ValidateNotification() = %v, want an error for %s", errs, tt.field)
+		})
+	}
+}
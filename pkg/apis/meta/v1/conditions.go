@@ -0,0 +1,75 @@
+// file: pkg/apis/meta/v1/conditions.go
+
+// Package v1 提供了操作 metav1.Condition 列表的辅助函数，供各个控制器维护对象的 status.conditions。
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetCondition 返回类型为 condType 的状况，不存在时返回 nil。
+// 返回的指针指向 conditions 中的元素，修改它会直接修改列表。
+func GetCondition(conditions []metav1.Condition, condType string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == condType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue 判断类型为 condType 的状况是否存在且为 True。
+func IsConditionTrue(conditions []metav1.Condition, condType string) bool {
+	cond := GetCondition(conditions, condType)
+	return cond != nil && cond.Status == metav1.ConditionTrue
+}
+
+// ConditionChanged 判断把 cond 写入 conditions 是否会改变它们，即同类型的状况不存在，
+// 或者它的 status、reason、message、observedGeneration 与 cond 不同。LastTransitionTime 不参与比较。
+// 控制器可以用它避免在状况没有变化时写回对象。
+func ConditionChanged(conditions []metav1.Condition, cond metav1.Condition) bool {
+	existing := GetCondition(conditions, cond.Type)
+	if existing == nil {
+		return true
+	}
+	cond.Reason = conditionReason(cond)
+	return existing.Status != cond.Status ||
+		existing.Reason != cond.Reason ||
+		existing.Message != cond.Message ||
+		existing.ObservedGeneration != cond.ObservedGeneration
+}
+
+// SetCondition 把 cond 写入 conditions，返回 conditions 是否发生了变化。
+// LastTransitionTime 只在状况第一次出现或 status 变化时更新：cond 设置了它时使用 cond 的值，否则使用当前时间；
+// status 不变时保留原来的 LastTransitionTime，使它始终表示状况最后一次翻转的时间。
+// Reason 为空时使用 status 的值 (例如 "True")，因为 metav1.Condition 要求 reason 不能为空。
+func SetCondition(conditions *[]metav1.Condition, cond metav1.Condition) bool {
+	cond.Reason = conditionReason(cond)
+	if cond.LastTransitionTime.IsZero() {
+		cond.LastTransitionTime = metav1.Now()
+	}
+
+	existing := GetCondition(*conditions, cond.Type)
+	if existing == nil {
+		*conditions = append(*conditions, cond)
+		return true
+	}
+	if !ConditionChanged(*conditions, cond) {
+		return false
+	}
+	if existing.Status != cond.Status {
+		existing.LastTransitionTime = cond.LastTransitionTime
+	}
+	existing.Status = cond.Status
+	existing.Reason = cond.Reason
+	existing.Message = cond.Message
+	existing.ObservedGeneration = cond.ObservedGeneration
+	return true
+}
+
+func conditionReason(cond metav1.Condition) string {
+	if cond.Reason != "" {
+		return cond.Reason
+	}
+	return string(cond.Status)
+}
@@ -0,0 +1,50 @@
+// file: pkg/apis/meta/v1/conditions_test.go
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetCondition(t *testing.T) {
+	t0 := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	t1 := metav1.NewTime(t0.Add(time.Minute))
+
+	var conditions []metav1.Condition
+	if !SetCondition(&conditions, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, LastTransitionTime: t0}) {
+		t.Fatal("SetCondition() on a new condition = false, want true")
+	}
+	if got := GetCondition(conditions, "Ready"); got == nil || got.Reason != "False" || !got.LastTransitionTime.Equal(&t0) {
+		t.Fatalf("GetCondition() = %+v, want reason defaulted to the status and transition time %v", got, t0)
+	}
+
+	// status 不变时只更新 message，不更新 LastTransitionTime
+	msg := metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Message: "waiting", LastTransitionTime: t1}
+	if !ConditionChanged(conditions, msg) || !SetCondition(&conditions, msg) {
+		t.Fatal("changing the message was not reported as a change")
+	}
+	if got := GetCondition(conditions, "Ready"); got.Message != "waiting" || !got.LastTransitionTime.Equal(&t0) {
+		t.Errorf("after a message change = %+v, want message updated and transition time %v", got, t0)
+	}
+	if ConditionChanged(conditions, msg) || SetCondition(&conditions, msg) {
+		t.Error("setting the same condition again was reported as a change")
+	}
+
+	// status 翻转时更新 LastTransitionTime
+	if !SetCondition(&conditions, metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Online", LastTransitionTime: t1}) {
+		t.Fatal("SetCondition() on a status change = false, want true")
+	}
+	got := GetCondition(conditions, "Ready")
+	if !IsConditionTrue(conditions, "Ready") || got.Reason != "Online" || got.Message != "" || !got.LastTransitionTime.Equal(&t1) {
+		t.Errorf("after a status change = %+v, want True/Online with transition time %v", got, t1)
+	}
+	if len(conditions) != 1 {
+		t.Errorf("len(conditions) = %d, want 1", len(conditions))
+	}
+	if IsConditionTrue(conditions, "Degraded") || GetCondition(conditions, "Degraded") != nil {
+		t.Error("a missing condition was reported as present")
+	}
+}
@@ -0,0 +1,58 @@
+// file: pkg/apis/meta/v1/owner.go
+
+package v1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// AlreadyOwnedError 表示对象已经被另一个控制者拥有，一个对象至多只能有一个控制者。
+type AlreadyOwnedError struct {
+	Object metav1.Object
+	Owner  metav1.OwnerReference
+}
+
+func (e *AlreadyOwnedError) Error() string {
+	return fmt.Sprintf("object %s/%s is already owned by another %s controller %s",
+		e.Object.GetNamespace(), e.Object.GetName(), e.Owner.Kind, e.Owner.Name)
+}
+
+// SetControllerReference 把 owner 设置为 object 的控制者 (controller 和 blockOwnerDeletion 均为 true)。
+// object 已经有指向 owner 的引用时原地替换它，已经被其他对象控制时返回 AlreadyOwnedError。
+// gvk 是 owner 的类型，Registry 中的对象通常不带 TypeMeta，所以需要由调用方给出。
+func SetControllerReference(owner, object metav1.Object, gvk schema.GroupVersionKind) error {
+	if existing := GetControllerOf(object); existing != nil && existing.UID != owner.GetUID() {
+		return &AlreadyOwnedError{Object: object, Owner: *existing}
+	}
+	ref := *metav1.NewControllerRef(owner, gvk)
+
+	refs := object.GetOwnerReferences()
+	for i := range refs {
+		if refs[i].UID == owner.GetUID() {
+			refs[i] = ref
+			object.SetOwnerReferences(refs)
+			return nil
+		}
+	}
+	object.SetOwnerReferences(append(refs, ref))
+	return nil
+}
+
+// GetControllerOf 返回 object 的控制者引用的副本，没有控制者时返回 nil。
+func GetControllerOf(object metav1.Object) *metav1.OwnerReference {
+	for _, ref := range object.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller {
+			return &ref
+		}
+	}
+	return nil
+}
+
+// IsControlledBy 判断 object 的控制者是否是 owner。
+func IsControlledBy(object, owner metav1.Object) bool {
+	ref := GetControllerOf(object)
+	return ref != nil && ref.UID == owner.GetUID()
+}
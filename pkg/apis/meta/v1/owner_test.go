@@ -0,0 +1,50 @@
+// file: pkg/apis/meta/v1/owner_test.go
+
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSetControllerReference(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "ecsm.sh", Version: "v1", Kind: "ECSMCronJob"}
+	owner := &metav1.ObjectMeta{Namespace: "default", Name: "nightly", UID: "uid-1"}
+	other := &metav1.ObjectMeta{Namespace: "default", Name: "weekly", UID: "uid-2"}
+	object := &metav1.ObjectMeta{
+		Namespace:       "default",
+		Name:            "job",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ECSMConfig", Name: "cfg", UID: "uid-3"}},
+	}
+
+	if GetControllerOf(object) != nil {
+		t.Fatal("GetControllerOf() on an object without a controller != nil")
+	}
+	if err := SetControllerReference(owner, object, gvk); err != nil {
+		t.Fatalf("SetControllerReference() error = %v", err)
+	}
+	// 重复设置同一个控制者不会增加引用
+	if err := SetControllerReference(owner, object, gvk); err != nil {
+		t.Fatalf("SetControllerReference() with the same owner error = %v", err)
+	}
+	if len(object.OwnerReferences) != 2 {
+		t.Fatalf("OwnerReferences = %v, want the existing reference and one controller reference", object.OwnerReferences)
+	}
+	ref := GetControllerOf(object)
+	if ref == nil || ref.UID != owner.UID || ref.Kind != gvk.Kind || ref.APIVersion != "ecsm.sh/v1" ||
+		ref.BlockOwnerDeletion == nil || !*ref.BlockOwnerDeletion {
+		t.Errorf("GetControllerOf() = %+v, want a blocking controller reference to %s", ref, owner.Name)
+	}
+	if !IsControlledBy(object, owner) || IsControlledBy(object, other) {
+		t.Error("IsControlledBy() does not match the controller reference")
+	}
+
+	err := SetControllerReference(other, object, gvk)
+	var owned *AlreadyOwnedError
+	if !errors.As(err, &owned) || owned.Owner.UID != owner.UID {
+		t.Errorf("SetControllerReference() with another owner error = %v, want AlreadyOwnedError", err)
+	}
+}
@@ -0,0 +1,200 @@
+// file: pkg/apiserver/admission/admission.go
+
+package admission
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Operation 是被准入控制的写操作
+type Operation string
+
+const (
+	Create Operation = "CREATE"
+	Update Operation = "UPDATE"
+	Delete Operation = "DELETE"
+)
+
+// Attributes 描述了一次需要准入控制的写请求。
+type Attributes struct {
+	Operation Operation
+	// Resource 是资源的复数名称，例如 "ecsmservices"
+	Resource string
+	// Subresource 是子资源的名称，例如 "status" 或 "scale"，访问资源本身时为空
+	Subresource string
+	// Kind 是 Object 的 Kind
+	Kind      string
+	Namespace string
+	Name      string
+	// Object 是将要写入的对象，变更插件可以把它替换为修改后的对象。DELETE 时为 nil
+	Object runtime.Object
+	// OldObject 是 UPDATE 和 DELETE 之前的对象，CREATE 时为 nil
+	OldObject runtime.Object
+}
+
+// MutationInterface 是变更插件：它可以修改 a.Object，返回错误时拒绝请求。
+type MutationInterface interface {
+	Admit(ctx context.Context, a *Attributes) error
+}
+
+// ValidationInterface 是校验插件：它只能检查 a.Object，返回错误时拒绝请求。
+type ValidationInterface interface {
+	Validate(ctx context.Context, a *Attributes) error
+}
+
+// MutationFunc 把一个函数适配为 MutationInterface。
+type MutationFunc func(ctx context.Context, a *Attributes) error
+
+func (f MutationFunc) Admit(ctx context.Context, a *Attributes) error { return f(ctx, a) }
+
+// ValidationFunc 把一个函数适配为 ValidationInterface。
+type ValidationFunc func(ctx context.Context, a *Attributes) error
+
+func (f ValidationFunc) Validate(ctx context.Context, a *Attributes) error { return f(ctx, a) }
+
+type namedMutation struct {
+	name   string
+	plugin MutationInterface
+}
+
+type namedValidation struct {
+	name   string
+	plugin ValidationInterface
+}
+
+// Chain 按照注册顺序运行准入插件：先运行所有变更插件，再用变更后的对象运行所有校验插件。
+// 任何一个插件返回错误都会拒绝请求。nil 的 Chain 允许所有请求。
+type Chain struct {
+	mutations   []namedMutation
+	validations []namedValidation
+}
+
+// NewChain 创建一个空的 Chain。
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Register 注册一个进程内的准入插件，plugin 必须实现 MutationInterface 或 ValidationInterface，
+// 两者都实现时会分别在两个阶段被调用。
+func (c *Chain) Register(name string, plugin interface{}) error {
+	m, isMutation := plugin.(MutationInterface)
+	v, isValidation := plugin.(ValidationInterface)
+	if !isMutation && !isValidation {
+		return fmt.Errorf("admission plugin %q implements neither MutationInterface nor ValidationInterface", name)
+	}
+	for _, existing := range c.Names() {
+		if existing == name {
+			return fmt.Errorf("admission plugin %q is already registered", name)
+		}
+	}
+	if isMutation {
+		c.mutations = append(c.mutations, namedMutation{name: name, plugin: m})
+	}
+	if isValidation {
+		c.validations = append(c.validations, namedValidation{name: name, plugin: v})
+	}
+	return nil
+}
+
+// Names 返回所有已注册插件的名称。
+func (c *Chain) Names() []string {
+	if c == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range c.mutations {
+		if !seen[m.name] {
+			seen[m.name] = true
+			names = append(names, m.name)
+		}
+	}
+	for _, v := range c.validations {
+		if !seen[v.name] {
+			seen[v.name] = true
+			names = append(names, v.name)
+		}
+	}
+	return names
+}
+
+// Admit 对请求运行所有插件。变更插件可能替换 a.Object。
+func (c *Chain) Admit(ctx context.Context, a *Attributes) error {
+	if c == nil {
+		return nil
+	}
+	for _, m := range c.mutations {
+		if err := m.plugin.Admit(ctx, a); err != nil {
+			return denied(m.name, a, err)
+		}
+	}
+	for _, v := range c.validations {
+		if err := v.plugin.Validate(ctx, a); err != nil {
+			return denied(v.name, a, err)
+		}
+	}
+	return nil
+}
+
+// denied 把插件返回的错误转换为 API 错误。插件已经返回 API 错误时 (例如 Invalid) 保持不变，
+// 其他错误视为插件拒绝了请求。
+func denied(name string, a *Attributes, err error) error {
+	var apiStatus errors.APIStatus
+	if stderrors.As(err, &apiStatus) {
+		return err
+	}
+	resource := a.Resource
+	if a.Subresource != "" {
+		resource += "/" + a.Subresource
+	}
+	return errors.NewForbidden(ecsmv1.Resource(resource), a.Name,
+		fmt.Errorf("admission plugin %q denied the request: %w", name, err))
+}
+
+// matches 判断请求是否被 resources 和 operations 选中，两者为空或包含 "*" 时选中所有。
+// resources 中的条目可以是 "ecsmservices"、"ecsmservices/status" 或 "ecsmservices/*"，
+// 只写资源名时不选中它的子资源。
+func matches(a *Attributes, resources []string, operations []Operation) bool {
+	return matchesResource(a, resources) && matchesOperation(a.Operation, operations)
+}
+
+func matchesResource(a *Attributes, resources []string) bool {
+	if len(resources) == 0 {
+		return true
+	}
+	for _, r := range resources {
+		if r == "*" {
+			return true
+		}
+		name, sub, hasSub := strings.Cut(r, "/")
+		if name != a.Resource && name != "*" {
+			continue
+		}
+		if !hasSub && a.Subresource == "" {
+			return true
+		}
+		if hasSub && a.Subresource != "" && (sub == "*" || sub == a.Subresource) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesOperation(op Operation, operations []Operation) bool {
+	if len(operations) == 0 {
+		return true
+	}
+	for _, o := range operations {
+		if o == op || o == "*" {
+			return true
+		}
+	}
+	return false
+}
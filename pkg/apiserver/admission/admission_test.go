@@ -0,0 +1,229 @@
+// file: pkg/apiserver/admission/admission_test.go
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newAttributes() *Attributes {
+	return &Attributes{
+		Operation: Create,
+		Resource:  "ecsmservices",
+		Kind:      "ECSMService",
+		Namespace: "default",
+		Name:      "web",
+		Object: &ecsmv1.ECSMService{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+			Spec:       ecsmv1.ECSMServiceSpec{Template: ecsmv1.ContainerTemplateSpec{Image: "web@1.0"}},
+		},
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	chain := NewChain()
+	chain.Register("check", ValidationFunc(func(ctx context.Context, a *Attributes) error {
+		calls = append(calls, "validate")
+		if a.Object.(*ecsmv1.ECSMService).Labels["team"] == "" {
+			return errors.New("label team is required")
+		}
+		return nil
+	}))
+	chain.Register("label", MutationFunc(func(ctx context.Context, a *Attributes) error {
+		calls = append(calls, "mutate")
+		a.Object.(*ecsmv1.ECSMService).Labels = map[string]string{"team": "edge"}
+		return nil
+	}))
+	if err := chain.Register("label", MutationFunc(nil)); err == nil {
+		t.Error("registering a duplicate name succeeded")
+	}
+	if err := chain.Register("bad", struct{}{}); err == nil {
+		t.Error("registering a value that is not a plugin succeeded")
+	}
+
+	// 变更插件总是先于校验插件运行
+	if err := chain.Admit(context.Background(), newAttributes()); err != nil {
+		t.Fatalf("Admit failed: %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "mutate" || calls[1] != "validate" {
+		t.Errorf("plugins ran in order %v, want mutate, validate", calls)
+	}
+
+	deny := NewChain()
+	deny.Register("check", ValidationFunc(func(ctx context.Context, a *Attributes) error {
+		return errors.New("nope")
+	}))
+	if err := deny.Admit(context.Background(), newAttributes()); !apierrors.IsForbidden(err) {
+		t.Errorf("denied request returned %v, want Forbidden", err)
+	}
+
+	var nilChain *Chain
+	if err := nilChain.Admit(context.Background(), newAttributes()); err != nil {
+		t.Errorf("nil chain returned %v", err)
+	}
+}
+
+func TestMatchesResource(t *testing.T) {
+	tests := []struct {
+		resources   []string
+		subresource string
+		want        bool
+	}{
+		{nil, "status", true},
+		{[]string{"*"}, "scale", true},
+		{[]string{"ecsmservices"}, "", true},
+		{[]string{"ecsmservices"}, "scale", false},
+		{[]string{"ecsmservices/scale"}, "scale", true},
+		{[]string{"ecsmservices/*"}, "status", true},
+		{[]string{"ecsmservices/*"}, "", false},
+		{[]string{"ecsmjobs"}, "", false},
+	}
+	for _, tt := range tests {
+		a := &Attributes{Resource: "ecsmservices", Subresource: tt.subresource}
+		if got := matchesResource(a, tt.resources); got != tt.want {
+			t.Errorf("matchesResource(%v, subresource %q) = %v, want %v", tt.resources, tt.subresource, got, tt.want)
+		}
+	}
+}
+
+// newWebhookServer 启动一个 webhook，它对每个请求调用 respond 生成响应。
+func newWebhookServer(t *testing.T, respond func(req *ReviewRequest) *ReviewResponse) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review Review
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+			http.Error(w, "bad review", http.StatusBadRequest)
+			return
+		}
+		response := respond(review.Request)
+		response.UID = review.Request.UID
+		json.NewEncoder(w).Encode(&Review{Response: response})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhooks(t *testing.T) {
+	mutating := newWebhookServer(t, func(req *ReviewRequest) *ReviewResponse {
+		return &ReviewResponse{Allowed: true, Patch: json.RawMessage(`{"metadata":{"labels":{"team":"edge"}}}`)}
+	})
+	validating := newWebhookServer(t, func(req *ReviewRequest) *ReviewResponse {
+		var svc ecsmv1.ECSMService
+		json.Unmarshal(req.Object, &svc)
+		if svc.Kind != "ECSMService" || svc.Labels["team"] == "" {
+			return &ReviewResponse{Allowed: false, Result: &metav1.Status{Message: "label team is required"}}
+		}
+		return &ReviewResponse{Allowed: true}
+	})
+
+	chain := NewChain()
+	err := chain.RegisterWebhooks([]WebhookConfig{
+		{Name: "policy", Type: ValidatingWebhook, URL: validating.URL, Resources: []string{"ecsmservices"}},
+		{Name: "labels", Type: MutatingWebhook, URL: mutating.URL, Operations: []Operation{Create}},
+	})
+	if err != nil {
+		t.Fatalf("RegisterWebhooks failed: %v", err)
+	}
+	a := newAttributes()
+	if err := chain.Admit(context.Background(), a); err != nil {
+		t.Fatalf("Admit failed: %v", err)
+	}
+	if got := a.Object.(*ecsmv1.ECSMService).Labels["team"]; got != "edge" {
+		t.Errorf("label team after the mutating webhook = %q, want edge", got)
+	}
+
+	// 更新请求不经过只选择 CREATE 的变更 webhook，因此被校验 webhook 拒绝
+	a = newAttributes()
+	a.Operation = Update
+	if err := chain.Admit(context.Background(), a); !apierrors.IsForbidden(err) {
+		t.Errorf("update without the label returned %v, want Forbidden", err)
+	}
+
+	// 无法调用的 webhook 按照 failurePolicy 处理
+	for _, policy := range []FailurePolicy{Fail, Ignore} {
+		chain := NewChain()
+		if err := chain.RegisterWebhooks([]WebhookConfig{{Name: "down", Type: ValidatingWebhook, URL: "http://127.0.0.1:1", FailurePolicy: policy}}); err != nil {
+			t.Fatal(err)
+		}
+		err := chain.Admit(context.Background(), newAttributes())
+		if policy == Fail && !apierrors.IsInternalError(err) {
+			t.Errorf("unreachable webhook with policy Fail returned %v, want InternalError", err)
+		}
+		if policy == Ignore && err != nil {
+			t.Errorf("unreachable webhook with policy Ignore returned %v", err)
+		}
+	}
+}
+
+// TestWebhookDenial 测试 webhook 的拒绝本身就是 API 错误：没有状态码时是 403，给出状态码时沿用它。
+func TestWebhookDenial(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *metav1.Status
+		want   int32
+	}{
+		{"no result", nil, http.StatusForbidden},
+		{"message only", &metav1.Status{Message: "label team is required"}, http.StatusForbidden},
+		{"with code", &metav1.Status{Code: http.StatusUnprocessableEntity, Reason: metav1.StatusReasonInvalid, Message: "bad"}, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newWebhookServer(t, func(req *ReviewRequest) *ReviewResponse {
+				return &ReviewResponse{Allowed: false, Result: tt.result}
+			})
+			w, err := newWebhook(WebhookConfig{Name: "policy", Type: ValidatingWebhook, URL: server.URL})
+			if err != nil {
+				t.Fatalf("newWebhook failed: %v", err)
+			}
+			err = validatingWebhook{w}.Validate(context.Background(), newAttributes())
+			var status apierrors.APIStatus
+			if !errors.As(err, &status) {
+				t.Fatalf("denial returned %v (%T), want an API error", err, err)
+			}
+			if got := status.Status().Code; got != tt.want {
+				t.Errorf("denial code = %d, want %d: %v", got, tt.want, err)
+			}
+			if tt.result != nil && !strings.Contains(err.Error(), tt.result.Message) {
+				t.Errorf("denial %q does not contain the webhook message %q", err, tt.result.Message)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "admission.yaml")
+	data := `webhooks:
+- name: images
+  type: Validating
+  url: https://policy.example.com/images
+  resources: ["ecsmservices", "ecsmjobs"]
+  operations: ["CREATE", "UPDATE"]
+  failurePolicy: Ignore
+  timeoutSeconds: 3
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if len(config.Webhooks) != 1 || config.Webhooks[0].FailurePolicy != Ignore || len(config.Webhooks[0].Resources) != 2 {
+		t.Errorf("loaded config = %+v", config)
+	}
+	if err := NewChain().RegisterWebhooks([]WebhookConfig{{Name: "bad", Type: "Other", URL: "http://x"}}); err == nil {
+		t.Error("webhook with an invalid type was accepted")
+	}
+}
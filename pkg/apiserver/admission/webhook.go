@@ -0,0 +1,315 @@
+// file: pkg/apiserver/admission/webhook.go
+
+package admission
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/util"
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// ReviewAPIVersion 和 ReviewKind 是发送给外部 webhook 的 Review 的类型
+const (
+	ReviewAPIVersion = "admission.ecsm.sh/v1"
+	ReviewKind       = "AdmissionReview"
+)
+
+// defaultWebhookTimeout 是没有指定 timeoutSeconds 时调用 webhook 的超时时间
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookType 决定 webhook 在哪个阶段被调用
+type WebhookType string
+
+const (
+	// MutatingWebhook 可以通过返回 JSON Merge Patch 修改对象
+	MutatingWebhook WebhookType = "Mutating"
+	// ValidatingWebhook 只能允许或拒绝请求
+	ValidatingWebhook WebhookType = "Validating"
+)
+
+// FailurePolicy 决定 webhook 无法调用或返回无效响应时如何处理请求
+type FailurePolicy string
+
+const (
+	// Fail 拒绝请求，这是默认值
+	Fail FailurePolicy = "Fail"
+	// Ignore 忽略这个 webhook，继续处理请求
+	Ignore FailurePolicy = "Ignore"
+)
+
+// WebhookConfig 描述了一个外部 HTTP 准入 webhook。
+type WebhookConfig struct {
+	// Name 是 webhook 的唯一名称
+	Name string      `json:"name"`
+	Type WebhookType `json:"type"`
+	// URL 是接收 Review 的地址，必须是 http 或 https
+	URL string `json:"url"`
+	// Resources 选择需要经过 webhook 的资源，为空时选择所有资源，格式见 matches
+	Resources []string `json:"resources,omitempty"`
+	// Operations 选择需要经过 webhook 的操作，为空时选择所有操作
+	Operations []Operation `json:"operations,omitempty"`
+	// FailurePolicy 为空时等同于 Fail
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+	// TimeoutSeconds 是调用的超时时间，为 0 时使用 10 秒
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// CAFile 是用于校验 https webhook 证书的 PEM 格式 CA 文件，为空时使用系统的 CA
+	CAFile string `json:"caFile,omitempty"`
+}
+
+// Config 是准入配置文件的内容。
+type Config struct {
+	Webhooks []WebhookConfig `json:"webhooks"`
+}
+
+// LoadConfig 从 YAML 或 JSON 文件读取准入配置。
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read admission config %s: %w", path, err)
+	}
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse admission config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Review 是发送给 webhook 的请求和 webhook 返回的响应的外层结构。
+type Review struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *ReviewRequest  `json:"request,omitempty"`
+	Response        *ReviewResponse `json:"response,omitempty"`
+}
+
+// ReviewRequest 描述了被审查的写请求，对象以 JSON 形式给出。
+type ReviewRequest struct {
+	// UID 唯一标识这次审查，响应中必须原样返回
+	UID         types.UID       `json:"uid"`
+	Kind        string          `json:"kind"`
+	Resource    string          `json:"resource"`
+	SubResource string          `json:"subResource,omitempty"`
+	Namespace   string          `json:"namespace,omitempty"`
+	Name        string          `json:"name"`
+	Operation   Operation       `json:"operation"`
+	Object      json.RawMessage `json:"object,omitempty"`
+	OldObject   json.RawMessage `json:"oldObject,omitempty"`
+}
+
+// ReviewResponse 是 webhook 的结论。
+type ReviewResponse struct {
+	UID     types.UID `json:"uid"`
+	Allowed bool      `json:"allowed"`
+	// Result 在拒绝时说明原因
+	Result *metav1.Status `json:"result,omitempty"`
+	// Patch 是一个应用到对象上的 JSON Merge Patch，只有变更 webhook 可以返回
+	Patch json.RawMessage `json:"patch,omitempty"`
+}
+
+// webhook 通过 HTTP 调用一个外部准入 webhook，RegisterWebhooks 根据它的类型把它包装成
+// MutationInterface 或 ValidationInterface。
+type webhook struct {
+	config WebhookConfig
+	client *http.Client
+}
+
+// mutatingWebhook 和 validatingWebhook 让 Chain 只在对应的阶段调用 webhook
+type mutatingWebhook struct{ *webhook }
+
+func (w mutatingWebhook) Admit(ctx context.Context, a *Attributes) error { return w.call(ctx, a, true) }
+
+type validatingWebhook struct{ *webhook }
+
+func (w validatingWebhook) Validate(ctx context.Context, a *Attributes) error {
+	return w.call(ctx, a, false)
+}
+
+// RegisterWebhooks 按顺序把配置中的 webhook 注册到 Chain 中。
+func (c *Chain) RegisterWebhooks(configs []WebhookConfig) error {
+	for _, config := range configs {
+		wh, err := newWebhook(config)
+		if err != nil {
+			return fmt.Errorf("webhook %q: %w", config.Name, err)
+		}
+		var plugin interface{} = validatingWebhook{wh}
+		if config.Type == MutatingWebhook {
+			plugin = mutatingWebhook{wh}
+		}
+		if err := c.Register(config.Name, plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newWebhook(config WebhookConfig) (*webhook, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("name must be specified")
+	}
+	if config.Type != MutatingWebhook && config.Type != ValidatingWebhook {
+		return nil, fmt.Errorf("type must be %s or %s", MutatingWebhook, ValidatingWebhook)
+	}
+	switch config.FailurePolicy {
+	case "":
+		config.FailurePolicy = Fail
+	case Fail, Ignore:
+	default:
+		return nil, fmt.Errorf("failurePolicy must be %s or %s", Fail, Ignore)
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", config.URL)
+	}
+	if config.TimeoutSeconds < 0 {
+		return nil, fmt.Errorf("timeoutSeconds must not be negative")
+	}
+	timeout := defaultWebhookTimeout
+	if config.TimeoutSeconds > 0 {
+		timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read caFile: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("caFile %s contains no certificates", config.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &webhook{config: config, client: &http.Client{Transport: transport, Timeout: timeout}}, nil
+}
+
+// call 把请求发送给 webhook 并处理它的结论。mutate 为 true 时应用 webhook 返回的补丁。
+func (w *webhook) call(ctx context.Context, a *Attributes, mutate bool) error {
+	if !matches(a, w.config.Resources, w.config.Operations) {
+		return nil
+	}
+	response, err := w.review(ctx, a)
+	if err != nil {
+		if w.config.FailurePolicy == Ignore {
+			klog.Warningf("Ignoring failed admission webhook %q: %v", w.config.Name, err)
+			return nil
+		}
+		return errors.NewInternalError(fmt.Errorf("failed calling admission webhook %q: %w", w.config.Name, err))
+	}
+
+	if !response.Allowed {
+		if response.Result != nil && response.Result.Code != 0 {
+			status := *response.Result
+			status.Status = metav1.StatusFailure
+			return &errors.StatusError{ErrStatus: status}
+		}
+		// 没有给出状态码的拒绝返回 403，而不是作为普通错误变成 500
+		message := "denied"
+		if response.Result != nil && response.Result.Message != "" {
+			message = response.Result.Message
+		}
+		resource := a.Resource
+		if a.Subresource != "" {
+			resource += "/" + a.Subresource
+		}
+		return errors.NewForbidden(ecsmv1.Resource(resource), a.Name,
+			fmt.Errorf("admission webhook %q denied the request: %s", w.config.Name, message))
+	}
+
+	if len(response.Patch) == 0 {
+		return nil
+	}
+	if !mutate || a.Object == nil {
+		return errors.NewInternalError(fmt.Errorf("admission webhook %q returned a patch, but only mutating webhooks can patch objects", w.config.Name))
+	}
+	patched, err := util.ApplyMergePatch(a.Object, response.Patch)
+	if err != nil {
+		return errors.NewInternalError(fmt.Errorf("admission webhook %q returned an invalid patch: %w", w.config.Name, err))
+	}
+	a.Object = patched
+	return nil
+}
+
+// review 发送 Review 并返回 webhook 的响应。
+func (w *webhook) review(ctx context.Context, a *Attributes) (*ReviewResponse, error) {
+	request := &ReviewRequest{
+		UID:         types.UID(uuid.New().String()),
+		Kind:        a.Kind,
+		Resource:    a.Resource,
+		SubResource: a.Subresource,
+		Namespace:   a.Namespace,
+		Name:        a.Name,
+		Operation:   a.Operation,
+	}
+	var err error
+	if request.Object, err = encodeObject(a.Object, a.Kind); err != nil {
+		return nil, err
+	}
+	if request.OldObject, err = encodeObject(a.OldObject, a.Kind); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&Review{
+		TypeMeta: metav1.TypeMeta{APIVersion: ReviewAPIVersion, Kind: ReviewKind},
+		Request:  request,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	review := &Review{}
+	if err := json.Unmarshal(data, review); err != nil {
+		return nil, fmt.Errorf("invalid review response: %w", err)
+	}
+	if review.Response == nil {
+		return nil, fmt.Errorf("review response is missing")
+	}
+	if review.Response.UID != request.UID {
+		return nil, fmt.Errorf("review response uid %q does not match request uid %q", review.Response.UID, request.UID)
+	}
+	return review.Response, nil
+}
+
+// encodeObject 序列化一个对象的副本，并设置它的 apiVersion 和 kind，nil 对象序列化为空。
+func encodeObject(obj runtime.Object, kind string) (json.RawMessage, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(ecsmv1.SchemeGroupVersion.WithKind(kind))
+	return json.Marshal(obj)
+}
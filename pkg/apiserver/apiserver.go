@@ -0,0 +1,692 @@
+// file: pkg/apiserver/apiserver.go
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apiserver/admission"
+	"github.com/fx147/ecsm-operator/pkg/openapi"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/fx147/ecsm-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// maxRequestBodyBytes 是请求体的最大长度
+const maxRequestBodyBytes = 3 << 20
+
+// mergePatchType 是唯一支持的 PATCH 格式 (RFC 7386 JSON Merge Patch)
+const mergePatchType = "application/merge-patch+json"
+
+// APIPrefix 是所有资源路径的前缀
+const APIPrefix = "/apis/" + ecsmv1.GroupName + "/v1"
+
+// Server 以 Kubernetes 风格的 REST 接口暴露 Registry，使远程的 CLI 和其他工具可以通过网络使用控制面：
+//
+//	GET    /apis/ecsm.sh/v1                                          资源列表
+//	GET    /apis/ecsm.sh/v1/{resource}                               列出所有命名空间的对象
+//	GET    /apis/ecsm.sh/v1/namespaces/{ns}/{resource}[?watch=true]  列出或监听对象 (JSON 行或 Server-Sent Events)
+//	POST   /apis/ecsm.sh/v1/namespaces/{ns}/{resource}               创建对象
+//	GET    /apis/ecsm.sh/v1/namespaces/{ns}/{resource}/{name}        获取对象
+//	PUT    /apis/ecsm.sh/v1/namespaces/{ns}/{resource}/{name}        替换对象
+//	PATCH  /apis/ecsm.sh/v1/namespaces/{ns}/{resource}/{name}        以 JSON Merge Patch 修改对象
+//	DELETE /apis/ecsm.sh/v1/namespaces/{ns}/{resource}/{name}        删除对象
+//
+// 对象路径之后还可以跟 /status 或 /scale 子资源，集群级资源的路径没有 namespaces/{ns} 部分。
+// 所有路径和类型的 OpenAPI v3 文档由 GET /openapi/v3 提供。
+// 写请求先经过准入插件，默认值、校验和乐观并发控制都由 Registry 完成，错误以 metav1.Status 返回。
+// Server 本身不做认证和授权，只应该监听在可信的网络上；响应和 WATCH 事件中 ECSMSecret 的值总是为空，
+// PUT 的 ECSMSecret 中值为空的键保留当前保存的值。
+type Server struct {
+	registry   registry.Interface
+	resources  map[string]*resource
+	admission  *admission.Chain
+	openapi    http.Handler
+	watchCache *watchCache
+}
+
+// New 创建一个由 reg 支撑的 Server，chain 为 nil 时不做准入控制。
+// Server 从创建时开始缓存 Registry 的事件，不再使用时需要调用 Close。
+func New(reg registry.Interface, chain *admission.Chain) (*Server, error) {
+	cache, err := newWatchCache(reg)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{registry: reg, resources: newResources(reg), admission: chain, watchCache: cache}
+	s.openapi = openapi.Handler(s.openAPIDocument())
+	return s, nil
+}
+
+// Close 停止缓存事件并结束所有 WATCH 请求。
+func (s *Server) Close() {
+	s.watchCache.stop()
+}
+
+// target 是一个请求路径解析之后的结果
+type target struct {
+	resource    *resource
+	namespace   string
+	name        string
+	subresource string
+}
+
+// ServeHTTP 实现 http.Handler。
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if path == openapi.Path {
+		s.openapi.ServeHTTP(w, r)
+		return
+	}
+	if path == APIPrefix {
+		if r.Method != http.MethodGet {
+			writeError(w, errors.NewMethodNotSupported(ecsmv1.Resource(""), r.Method))
+			return
+		}
+		writeJSON(w, http.StatusOK, s.discovery())
+		return
+	}
+	if !strings.HasPrefix(path, APIPrefix+"/") {
+		writeError(w, errors.NewNotFound(ecsmv1.Resource(""), path))
+		return
+	}
+	t, err := s.parse(strings.Split(strings.TrimPrefix(path, APIPrefix+"/"), "/"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch {
+	case t.name == "":
+		s.serveCollection(w, r, t)
+	case t.subresource == "":
+		s.serveObject(w, r, t)
+	default:
+		s.serveSubresource(w, r, t)
+	}
+}
+
+// parse 把前缀之后的路径段解析为 target。
+func (s *Server) parse(segments []string) (*target, error) {
+	t := &target{}
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		t.namespace = segments[1]
+		segments = segments[2:]
+	}
+	if len(segments) == 0 || len(segments) > 3 || segments[0] == "" {
+		return nil, errors.NewNotFound(ecsmv1.Resource(""), strings.Join(segments, "/"))
+	}
+
+	res, ok := s.resources[segments[0]]
+	if !ok {
+		return nil, errors.NewNotFound(ecsmv1.Resource(segments[0]), "")
+	}
+	t.resource = res
+	if t.namespace != "" && !res.namespaced {
+		return nil, errors.NewBadRequest(fmt.Sprintf("%s is not a namespaced resource", res.name))
+	}
+	if len(segments) > 1 {
+		t.name = segments[1]
+		// 命名空间级资源的单个对象只能通过带命名空间的路径访问
+		if res.namespaced && t.namespace == "" {
+			return nil, errors.NewNotFound(ecsmv1.Resource(res.name), t.name)
+		}
+	}
+	if len(segments) > 2 {
+		t.subresource = segments[2]
+	}
+	return t, nil
+}
+
+// serveCollection 处理 LIST、WATCH 和 CREATE。
+func (s *Server) serveCollection(w http.ResponseWriter, r *http.Request, t *target) {
+	res := t.resource
+	switch r.Method {
+	case http.MethodGet:
+		selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+		if err != nil {
+			writeError(w, errors.NewBadRequest(fmt.Sprintf("invalid label selector: %v", err)))
+			return
+		}
+		if watch, _ := strconv.ParseBool(r.URL.Query().Get("watch")); watch {
+			s.watch(w, r, t, selector)
+			return
+		}
+		list, err := res.list(r.Context(), t.namespace)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if !selector.Empty() {
+			if err := filterList(list, selector); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+		writeObject(w, http.StatusOK, list, res.kind+"List", res.redact)
+
+	case http.MethodPost:
+		if res.namespaced && t.namespace == "" {
+			writeError(w, errors.NewBadRequest(fmt.Sprintf("%s must be created in a namespace", res.name)))
+			return
+		}
+		obj, err := decodeObject(r, res.newObject, res.kind, t)
+		if err == nil {
+			obj, err = s.admit(r.Context(), t, res.kind, admission.Create, obj, nil)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		created, err := res.create(r.Context(), obj)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeObject(w, http.StatusCreated, created, res.kind, res.redact)
+
+	default:
+		writeError(w, errors.NewMethodNotSupported(ecsmv1.Resource(res.name), r.Method))
+	}
+}
+
+// serveObject 处理单个对象的 GET、PUT、PATCH 和 DELETE。
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, t *target) {
+	res := t.resource
+	if r.Method == http.MethodDelete {
+		// Registry 删除不存在的对象时不返回错误，先读取一次以返回 NotFound
+		current, err := res.get(r.Context(), t.namespace, t.name)
+		if err == nil {
+			_, err = s.admit(r.Context(), t, res.kind, admission.Delete, nil, current)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if err := res.delete(r.Context(), t.namespace, t.name); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, &metav1.Status{
+			TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+			Status:   metav1.StatusSuccess,
+			Details: &metav1.StatusDetails{
+				Name:  t.name,
+				Group: ecsmv1.GroupName,
+				Kind:  res.name,
+			},
+		})
+		return
+	}
+	s.serveAccessor(w, r, t, &accessor{kind: res.kind, newObject: res.newObject, get: res.get, update: res.update, redact: res.redact, unredact: res.unredact})
+}
+
+// serveSubresource 处理 status 和 scale 子资源。
+func (s *Server) serveSubresource(w http.ResponseWriter, r *http.Request, t *target) {
+	res := t.resource
+	var acc *accessor
+	switch {
+	case t.subresource == "status" && res.updateStatus != nil:
+		acc = &accessor{kind: res.kind, newObject: res.newObject, get: res.get, update: res.updateStatus, redact: res.redact}
+	case t.subresource == "scale" && res.scale != nil:
+		acc = res.scale
+	default:
+		writeError(w, errors.NewNotFound(ecsmv1.Resource(res.name+"/"+t.subresource), t.name))
+		return
+	}
+	s.serveAccessor(w, r, t, acc)
+}
+
+// serveAccessor 处理一个可以读取和整体替换的对象的 GET、PUT 和 PATCH。
+func (s *Server) serveAccessor(w http.ResponseWriter, r *http.Request, t *target, acc *accessor) {
+	var (
+		obj runtime.Object
+		err error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		obj, err = acc.get(r.Context(), t.namespace, t.name)
+	case http.MethodPut:
+		var incoming runtime.Object
+		incoming, err = decodeObject(r, acc.newObject, acc.kind, t)
+		if err == nil && acc.unredact != nil {
+			var current runtime.Object
+			if current, err = acc.get(r.Context(), t.namespace, t.name); err == nil {
+				acc.unredact(incoming, current)
+			}
+		}
+		if err == nil {
+			incoming, err = s.admitUpdate(r.Context(), t, acc, incoming)
+		}
+		if err == nil {
+			obj, err = acc.update(r.Context(), incoming)
+		}
+	case http.MethodPatch:
+		obj, err = s.patch(r, t, acc)
+	default:
+		err = errors.NewMethodNotSupported(ecsmv1.Resource(t.resource.name), r.Method)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeObject(w, http.StatusOK, obj, acc.kind, acc.redact)
+}
+
+// patch 把请求体作为 JSON Merge Patch 应用到当前对象上并写回。
+// 补丁没有指定 metadata.resourceVersion 时，冲突会以最新的对象自动重试。
+func (s *Server) patch(r *http.Request, t *target, acc *accessor) (runtime.Object, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergePatchType {
+		return nil, &errors.StatusError{ErrStatus: metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusUnsupportedMediaType,
+			Reason:  metav1.StatusReasonUnsupportedMediaType,
+			Message: fmt.Sprintf("unsupported patch type %q, only %s is supported", mediaType, mergePatchType),
+		}}
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return nil, errors.NewBadRequest(fmt.Sprintf("invalid merge patch: %v", err))
+	}
+
+	var result runtime.Object
+	apply := func() error {
+		current, err := acc.get(r.Context(), t.namespace, t.name)
+		if err != nil {
+			return err
+		}
+		obj, err := util.ApplyMergePatch(current, body)
+		if err != nil {
+			return errors.NewBadRequest(err.Error())
+		}
+		if err := checkObjectMeta(obj, acc.kind, t); err != nil {
+			return err
+		}
+		if obj, err = s.admit(r.Context(), t, acc.kind, admission.Update, obj, current); err != nil {
+			return err
+		}
+		result, err = acc.update(r.Context(), obj)
+		return err
+	}
+
+	if _, ok := nestedField(patch, "metadata", "resourceVersion"); ok {
+		return result, apply()
+	}
+	return result, retry.RetryOnConflict(retry.DefaultRetry, apply)
+}
+
+// admit 对写请求运行准入插件，返回可能被变更插件修改过的对象。
+// 变更之后的对象仍然必须与请求路径一致。
+func (s *Server) admit(ctx context.Context, t *target, kind string, op admission.Operation, obj, old runtime.Object) (runtime.Object, error) {
+	a := &admission.Attributes{
+		Operation:   op,
+		Resource:    t.resource.name,
+		Subresource: t.subresource,
+		Kind:        kind,
+		Namespace:   t.namespace,
+		Name:        t.name,
+		Object:      obj,
+		OldObject:   old,
+	}
+	if a.Name == "" && obj != nil {
+		if m, err := meta.Accessor(obj); err == nil {
+			a.Name = m.GetName()
+		}
+	}
+	if err := s.admission.Admit(ctx, a); err != nil {
+		return nil, err
+	}
+	if a.Object != nil {
+		if err := checkObjectMeta(a.Object, kind, t); err != nil {
+			return nil, err
+		}
+	}
+	return a.Object, nil
+}
+
+// admitUpdate 读取当前对象作为 OldObject，然后对 PUT 请求运行准入插件。
+func (s *Server) admitUpdate(ctx context.Context, t *target, acc *accessor, obj runtime.Object) (runtime.Object, error) {
+	if s.admission == nil {
+		return obj, nil
+	}
+	current, err := acc.get(ctx, t.namespace, t.name)
+	if err != nil {
+		return nil, err
+	}
+	return s.admit(ctx, t, acc.kind, admission.Update, obj, current)
+}
+
+// nestedField 返回嵌套对象中 path 指向的值。
+func nestedField(obj map[string]interface{}, path ...string) (interface{}, bool) {
+	var v interface{} = obj
+	for _, k := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// watchEvent 是 WATCH 响应中的一行
+type watchEvent struct {
+	Type   string         `json:"type"`
+	Object runtime.Object `json:"object"`
+}
+
+// eventStreamType 是 Server-Sent Events 的媒体类型
+const eventStreamType = "text/event-stream"
+
+// heartbeatInterval 是 Server-Sent Events 流在没有事件时发送注释行的间隔，防止代理关闭空闲的连接
+const heartbeatInterval = 30 * time.Second
+
+// watch 把 Registry 中这种资源的变更事件流式返回，直到客户端断开或超时。
+// 默认每行一个 JSON 对象；请求的 Accept 为 text/event-stream 时以 Server-Sent Events 返回，
+// 每个事件的 id 是它的 resourceVersion，event 是事件类型，data 是对象。
+//
+// 事件中对象的 resourceVersion 是恢复监听的令牌 (删除事件中是删除操作的版本)：
+// 带上 resourceVersion 参数 (或 SSE 客户端重连时自动发送的 Last-Event-ID) 时，从这个版本之后的事件开始返回，
+// 因此客户端可以先 LIST，再从列表的 resourceVersion 开始 WATCH，断线后从最后收到的事件继续。
+// 版本太旧时返回 410 Gone，客户端需要重新 LIST。没有指定版本时只返回请求到达之后的事件。
+func (s *Server) watch(w http.ResponseWriter, r *http.Request, t *target, selector labels.Selector) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, errors.NewInternalError(stderrors.New("streaming is not supported")))
+		return
+	}
+	ctx := r.Context()
+	if v := r.URL.Query().Get("timeoutSeconds"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			writeError(w, errors.NewBadRequest(fmt.Sprintf("invalid timeoutSeconds %q", v)))
+			return
+		}
+		if seconds > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+			defer cancel()
+		}
+	}
+	rv := r.URL.Query().Get("resourceVersion")
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		rv = id
+	}
+	after, err := parseResourceVersion(rv)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	watcher, err := s.watchCache.watch(after)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer watcher.stop()
+
+	sse := strings.Contains(r.Header.Get("Accept"), eventStreamType)
+	var heartbeat <-chan time.Time
+	if sse {
+		w.Header().Set("Content-Type", eventStreamType)
+		w.Header().Set("Cache-Control", "no-cache")
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(eventType string, id uint64, obj runtime.Object) error {
+		var err error
+		if sse {
+			var data []byte
+			if data, err = json.Marshal(obj); err == nil {
+				if id > 0 {
+					_, err = fmt.Fprintf(w, "id: %d\n", id)
+				}
+				if err == nil {
+					_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+				}
+			}
+		} else {
+			err = json.NewEncoder(w).Encode(&watchEvent{Type: eventType, Object: obj})
+		}
+		flusher.Flush()
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-watcher.result:
+			if !ok {
+				if watcher.expired {
+					// 客户端落后太多，需要重新 LIST
+					status := errors.NewResourceExpired("the watch fell too far behind and was closed").Status()
+					status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+					send("ERROR", 0, &status)
+				}
+				return
+			}
+			if !t.resource.matches(event.Object) {
+				continue
+			}
+			m, err := meta.Accessor(event.Object)
+			if err != nil {
+				continue
+			}
+			if t.namespace != "" && m.GetNamespace() != t.namespace {
+				continue
+			}
+			if !selector.Matches(labels.Set(m.GetLabels())) {
+				continue
+			}
+			// 事件中的对象被所有订阅者共享，设置 TypeMeta 之前需要复制
+			obj := event.Object.DeepCopyObject()
+			setKind(obj, t.resource.kind)
+			if t.resource.redact != nil {
+				t.resource.redact(obj)
+			}
+			if event.Type == registry.Deleted {
+				// 被删除的对象带着删除前的版本，把它换成删除操作的版本，使客户端可以从这个事件之后恢复
+				m, _ := meta.Accessor(obj)
+				m.SetResourceVersion(strconv.FormatUint(event.Revision, 10))
+			}
+			if err := send(string(event.Type), event.Revision, obj); err != nil {
+				klog.V(4).Infof("Watch of %s ended: %v", t.resource.name, err)
+				return
+			}
+		}
+	}
+}
+
+// discovery 返回所有资源及其支持的操作。
+func (s *Server) discovery() *metav1.APIResourceList {
+	list := &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: ecsmv1.SchemeGroupVersion.String(),
+	}
+	verbs := metav1.Verbs{"create", "delete", "get", "list", "patch", "update", "watch"}
+	subresourceVerbs := metav1.Verbs{"get", "patch", "update"}
+	for _, res := range s.resources {
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name: res.name, Namespaced: res.namespaced, Kind: res.kind, Verbs: verbs,
+		})
+		if res.updateStatus != nil {
+			list.APIResources = append(list.APIResources, metav1.APIResource{
+				Name: res.name + "/status", Namespaced: res.namespaced, Kind: res.kind, Verbs: subresourceVerbs,
+			})
+		}
+		if res.scale != nil {
+			list.APIResources = append(list.APIResources, metav1.APIResource{
+				Name: res.name + "/scale", Namespaced: res.namespaced, Kind: res.scale.kind, Verbs: subresourceVerbs,
+			})
+		}
+	}
+	sort.Slice(list.APIResources, func(i, j int) bool {
+		return list.APIResources[i].Name < list.APIResources[j].Name
+	})
+	return list
+}
+
+// readBody 读取请求体，超过 maxRequestBodyBytes 时返回错误。
+func readBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodyBytes+1))
+	if err != nil {
+		return nil, errors.NewBadRequest(fmt.Sprintf("failed to read request body: %v", err))
+	}
+	if len(body) > maxRequestBodyBytes {
+		return nil, errors.NewRequestEntityTooLargeError(fmt.Sprintf("request body exceeds %d bytes", maxRequestBodyBytes))
+	}
+	return body, nil
+}
+
+// decodeObject 从请求体解码一个对象，并用路径中的命名空间和名称补全或检查它的 metadata。
+func decodeObject(r *http.Request, newObject func() runtime.Object, kind string, t *target) (runtime.Object, error) {
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	obj := newObject()
+	if err := json.Unmarshal(body, obj); err != nil {
+		return nil, errors.NewBadRequest(fmt.Sprintf("failed to decode %s: %v", kind, err))
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if m.GetNamespace() == "" {
+		m.SetNamespace(t.namespace)
+	}
+	if m.GetName() == "" {
+		m.SetName(t.name)
+	}
+	if err := checkObjectMeta(obj, kind, t); err != nil {
+		return nil, err
+	}
+	if m.GetName() == "" {
+		return nil, errors.NewBadRequest("metadata.name is required")
+	}
+	return obj, nil
+}
+
+// checkObjectMeta 检查对象的类型、命名空间和名称与请求路径一致。
+func checkObjectMeta(obj runtime.Object, kind string, t *target) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Kind != "" && gvk.Kind != kind {
+		return errors.NewBadRequest(fmt.Sprintf("kind %s does not match the expected kind %s", gvk.Kind, kind))
+	}
+	if apiVersion := gvk.GroupVersion().String(); apiVersion != "" && apiVersion != ecsmv1.SchemeGroupVersion.String() {
+		return errors.NewBadRequest(fmt.Sprintf("apiVersion %s is not supported, use %s", apiVersion, ecsmv1.SchemeGroupVersion))
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+	if !t.resource.namespaced && m.GetNamespace() != "" {
+		return errors.NewBadRequest(fmt.Sprintf("%s is not a namespaced resource", t.resource.name))
+	}
+	if t.resource.namespaced && m.GetNamespace() != t.namespace {
+		return errors.NewBadRequest(fmt.Sprintf("the namespace of the object (%s) does not match the namespace of the request (%s)", m.GetNamespace(), t.namespace))
+	}
+	if t.name != "" && m.GetName() != t.name {
+		return errors.NewBadRequest(fmt.Sprintf("the name of the object (%s) does not match the name in the URL (%s)", m.GetName(), t.name))
+	}
+	return nil
+}
+
+// filterList 从列表中去掉不匹配标签选择器的对象。
+func filterList(list runtime.Object, selector labels.Selector) error {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	filtered := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		m, err := meta.Accessor(item)
+		if err != nil {
+			return err
+		}
+		if selector.Matches(labels.Set(m.GetLabels())) {
+			filtered = append(filtered, item)
+		}
+	}
+	return meta.SetList(list, filtered)
+}
+
+// setKind 设置对象的 apiVersion 和 kind，Registry 返回的对象不带 TypeMeta。
+func setKind(obj runtime.Object, kind string) {
+	obj.GetObjectKind().SetGroupVersionKind(ecsmv1.SchemeGroupVersion.WithKind(kind))
+}
+
+// writeObject 复制对象、设置它的 TypeMeta 并写入响应，redact 不为 nil 时清除对象 (或列表中每个对象) 的敏感字段。
+// Registry 返回的对象可能同时被发布给了订阅者，不能在原地修改。
+func writeObject(w http.ResponseWriter, code int, obj runtime.Object, kind string, redact func(runtime.Object)) {
+	obj = obj.DeepCopyObject()
+	setKind(obj, kind)
+	if redact != nil {
+		if meta.IsListType(obj) {
+			meta.EachListItem(obj, func(item runtime.Object) error {
+				redact(item)
+				return nil
+			})
+		} else {
+			redact(obj)
+		}
+	}
+	writeJSON(w, code, obj)
+}
+
+// writeError 把错误转换为 metav1.Status 写入响应，非 API 错误视为内部错误。
+func writeError(w http.ResponseWriter, err error) {
+	var apiStatus errors.APIStatus
+	if !stderrors.As(err, &apiStatus) {
+		apiStatus = errors.NewInternalError(err)
+	}
+	status := apiStatus.Status()
+	status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	if status.Code == 0 {
+		status.Code = http.StatusInternalServerError
+	}
+	writeJSON(w, int(status.Code), &status)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.V(4).Infof("Failed to write API response: %v", err)
+	}
+}
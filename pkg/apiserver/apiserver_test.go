@@ -0,0 +1,497 @@
+// file: pkg/apiserver/apiserver_test.go
+
+package apiserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apiserver/admission"
+	"github.com/fx147/ecsm-operator/pkg/openapi"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	bolt "go.etcd.io/bbolt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestServer(t *testing.T, chain *admission.Chain) *httptest.Server {
+	server, _ := newTestServerWithRegistry(t, chain)
+	return server
+}
+
+// newTestServerWithRegistry 与 newTestServer 相同，同时返回底层的 Registry，用来检查不会出现在响应中的数据。
+func newTestServerWithRegistry(t *testing.T, chain *admission.Chain) (*httptest.Server, *registry.Registry) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	transformer, err := registry.NewAESGCMTransformer(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create transformer: %v", err)
+	}
+	reg.SetEncryption(transformer)
+	api, err := New(reg, chain)
+	if err != nil {
+		t.Fatalf("Failed to create API server: %v", err)
+	}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	t.Cleanup(api.Close)
+	return server, reg
+}
+
+func newTestService(name string) *ecsmv1.ECSMService {
+	replicas := int32(2)
+	return &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"app": name}},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:     ecsmv1.DeploymentStrategyTypeDynamic,
+				Replicas: &replicas,
+			},
+			Template: ecsmv1.ContainerTemplateSpec{Image: name + "@1.0"},
+		},
+	}
+}
+
+// do 发送一个请求，把响应解码到 out 中 (out 为 nil 时忽略响应体)，返回状态码。
+func do(t *testing.T, server *httptest.Server, method, path, contentType string, body, out interface{}) int {
+	t.Helper()
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, server.URL+APIPrefix+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServiceCRUD(t *testing.T) {
+	server := newTestServer(t, nil)
+	const base = "/namespaces/default/ecsmservices"
+
+	var created ecsmv1.ECSMService
+	if code := do(t, server, http.MethodPost, base, "application/json", newTestService("web"), &created); code != http.StatusCreated {
+		t.Fatalf("create returned %d, want 201", code)
+	}
+	if created.Namespace != "default" || created.ResourceVersion == "" || created.Kind != "ECSMService" {
+		t.Fatalf("created object = %+v, want namespace, resourceVersion and kind set", created.ObjectMeta)
+	}
+	if code := do(t, server, http.MethodPost, base, "application/json", newTestService("web"), nil); code != http.StatusConflict {
+		t.Errorf("duplicate create returned %d, want 409", code)
+	}
+	if code := do(t, server, http.MethodPost, base, "application/json", newTestService("api"), nil); code != http.StatusCreated {
+		t.Fatalf("create returned %d, want 201", code)
+	}
+
+	var list ecsmv1.ECSMServiceList
+	if code := do(t, server, http.MethodGet, base+"?labelSelector=app%3Dweb", "", nil, &list); code != http.StatusOK {
+		t.Fatalf("list returned %d", code)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "web" || list.ResourceVersion == "" {
+		t.Errorf("list with label selector = %d item(s) at resourceVersion %q, want only web", len(list.Items), list.ResourceVersion)
+	}
+	if code := do(t, server, http.MethodGet, "/ecsmservices", "", nil, &list); code != http.StatusOK || len(list.Items) != 2 {
+		t.Errorf("list across namespaces returned %d with %d item(s), want 2", code, len(list.Items))
+	}
+
+	// 旧的 resourceVersion 会导致冲突
+	stale := created.DeepCopy()
+	stale.Spec.Template.Image = "web@2.0"
+	var updated ecsmv1.ECSMService
+	if code := do(t, server, http.MethodPut, base+"/web", "application/json", stale, &updated); code != http.StatusOK {
+		t.Fatalf("update returned %d", code)
+	}
+	if code := do(t, server, http.MethodPut, base+"/web", "application/json", stale, nil); code != http.StatusConflict {
+		t.Errorf("update with a stale resourceVersion returned %d, want 409", code)
+	}
+	if code := do(t, server, http.MethodPut, base+"/api", "application/json", stale, nil); code != http.StatusBadRequest {
+		t.Errorf("update with a mismatched name returned %d, want 400", code)
+	}
+
+	// 没有 resourceVersion 的补丁作用于最新的对象
+	var patched ecsmv1.ECSMService
+	patch := `{"metadata":{"labels":{"tier":"frontend"}},"spec":{"template":{"image":"web@3.0"}}}`
+	if code := do(t, server, http.MethodPatch, base+"/web", mergePatchType, patch, &patched); code != http.StatusOK {
+		t.Fatalf("patch returned %d", code)
+	}
+	if patched.Spec.Template.Image != "web@3.0" || patched.Labels["tier"] != "frontend" || patched.Labels["app"] != "web" {
+		t.Errorf("patched object = image %s labels %v", patched.Spec.Template.Image, patched.Labels)
+	}
+	if code := do(t, server, http.MethodPatch, base+"/web", "application/json-patch+json", "[]", nil); code != http.StatusUnsupportedMediaType {
+		t.Errorf("JSON patch returned %d, want 415", code)
+	}
+
+	var scale ecsmv1.ECSMServiceScale
+	if code := do(t, server, http.MethodPatch, base+"/web/scale", mergePatchType, `{"spec":{"replicas":5}}`, &scale); code != http.StatusOK {
+		t.Fatalf("scale patch returned %d", code)
+	}
+	var got ecsmv1.ECSMService
+	do(t, server, http.MethodGet, base+"/web", "", nil, &got)
+	if got.Spec.DeploymentStrategy.Replicas == nil || *got.Spec.DeploymentStrategy.Replicas != 5 {
+		t.Errorf("replicas after scaling = %v, want 5", got.Spec.DeploymentStrategy.Replicas)
+	}
+
+	if code := do(t, server, http.MethodDelete, base+"/web", "", nil, nil); code != http.StatusOK {
+		t.Errorf("delete returned %d", code)
+	}
+	var status metav1.Status
+	if code := do(t, server, http.MethodGet, base+"/web", "", nil, &status); code != http.StatusNotFound || status.Reason != metav1.StatusReasonNotFound {
+		t.Errorf("get after delete returned %d %q, want 404 NotFound", code, status.Reason)
+	}
+	if code := do(t, server, http.MethodDelete, base+"/web", "", nil, nil); code != http.StatusNotFound {
+		t.Errorf("second delete returned %d, want 404", code)
+	}
+}
+
+func TestClusterScopedResource(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	pc := &ecsmv1.ECSMPriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}, Value: 1000}
+	if code := do(t, server, http.MethodPost, "/ecsmpriorityclasses", "application/json", pc, nil); code != http.StatusCreated {
+		t.Fatalf("create returned %d, want 201", code)
+	}
+	var got ecsmv1.ECSMPriorityClass
+	if code := do(t, server, http.MethodGet, "/ecsmpriorityclasses/high", "", nil, &got); code != http.StatusOK || got.Value != 1000 {
+		t.Errorf("get returned %d with value %d", code, got.Value)
+	}
+	if code := do(t, server, http.MethodGet, "/namespaces/default/ecsmpriorityclasses", "", nil, nil); code != http.StatusBadRequest {
+		t.Errorf("namespaced path of a cluster-scoped resource returned %d, want 400", code)
+	}
+	if code := do(t, server, http.MethodGet, "/ecsmpriorityclasses/high/status", "", nil, nil); code != http.StatusNotFound {
+		t.Errorf("missing status subresource returned %d, want 404", code)
+	}
+	if code := do(t, server, http.MethodGet, "/unknowns", "", nil, nil); code != http.StatusNotFound {
+		t.Errorf("unknown resource returned %d, want 404", code)
+	}
+
+	var resources metav1.APIResourceList
+	do(t, server, http.MethodGet, "", "", nil, &resources)
+	names := make(map[string]bool)
+	for _, res := range resources.APIResources {
+		names[res.Name] = true
+	}
+	for _, name := range []string{"ecsmservices", "ecsmservices/scale", "ecsmservices/status", "ecsmnodes", "ecsmpriorityclasses"} {
+		if !names[name] {
+			t.Errorf("discovery is missing %s", name)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	resp, err := server.Client().Get(server.URL + APIPrefix + "/namespaces/default/ecsmconfigs?watch=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	other := &ecsmv1.ECSMConfig{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	do(t, server, http.MethodPost, "/namespaces/kube/ecsmconfigs", "application/json", other, nil)
+	cfg := &ecsmv1.ECSMConfig{ObjectMeta: metav1.ObjectMeta{Name: "app"}, Data: map[string]string{"k": "v"}}
+	do(t, server, http.MethodPost, "/namespaces/default/ecsmconfigs", "application/json", cfg, nil)
+	do(t, server, http.MethodDelete, "/namespaces/default/ecsmconfigs/app", "", nil, nil)
+
+	// 其他命名空间的对象被过滤掉
+	scanner := bufio.NewScanner(resp.Body)
+	var types []string
+	for len(types) < 2 && scanner.Scan() {
+		var event struct {
+			Type   string            `json:"type"`
+			Object ecsmv1.ECSMConfig `json:"object"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid watch event %q: %v", scanner.Text(), err)
+		}
+		if event.Object.Name != "app" || event.Object.Kind != "ECSMConfig" {
+			t.Errorf("watch event for %s/%s of kind %q, want default/app", event.Object.Namespace, event.Object.Name, event.Object.Kind)
+		}
+		types = append(types, event.Type)
+	}
+	if got := strings.Join(types, ","); got != "ADDED,DELETED" {
+		t.Errorf("watch events = %s, want ADDED,DELETED", got)
+	}
+}
+
+func TestWatchResume(t *testing.T) {
+	server := newTestServer(t, nil)
+	base := "/namespaces/default/ecsmconfigs"
+
+	// 空的 Registry 没有版本，先写入一个对象
+	do(t, server, http.MethodPost, base, "application/json", &ecsmv1.ECSMConfig{ObjectMeta: metav1.ObjectMeta{Name: "first"}}, nil)
+	var list ecsmv1.ECSMConfigList
+	do(t, server, http.MethodGet, base, "", nil, &list)
+	cfg := &ecsmv1.ECSMConfig{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	do(t, server, http.MethodPost, base, "application/json", cfg, nil)
+	do(t, server, http.MethodDelete, base+"/app", "", nil, nil)
+
+	// 从 LIST 的版本开始监听，之前错过的事件被补发
+	watch := func(header, value string, query string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+APIPrefix+base+"?watch=true"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	resp := watch("", "", "&resourceVersion="+list.ResourceVersion)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	// readEvent 读取一个 SSE 事件，跳过心跳注释
+	readEvent := func(reader *bufio.Reader) (id, eventType string, obj ecsmv1.ECSMConfig) {
+		t.Helper()
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read event: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "" && eventType != "":
+				return id, eventType, obj
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				eventType = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &obj); err != nil {
+					t.Fatalf("invalid event data %q: %v", line, err)
+				}
+			}
+		}
+	}
+	reader := bufio.NewReader(resp.Body)
+	addedID, added, obj := readEvent(reader)
+	if added != "ADDED" || obj.Name != "app" || obj.ResourceVersion != addedID {
+		t.Errorf("first event = %s %s with id %s, want ADDED app", added, obj.Name, addedID)
+	}
+	deletedID, deleted, obj := readEvent(reader)
+	if deleted != "DELETED" || obj.ResourceVersion != deletedID || deletedID == addedID {
+		t.Errorf("second event = %s with id %s, want DELETED with a new resource version", deleted, deletedID)
+	}
+
+	// 重连时带上 Last-Event-ID，只收到之后的事件
+	resp = watch("Last-Event-ID", addedID, "")
+	do(t, server, http.MethodPost, base, "application/json", &ecsmv1.ECSMConfig{ObjectMeta: metav1.ObjectMeta{Name: "next"}}, nil)
+	reader = bufio.NewReader(resp.Body)
+	if id, eventType, _ := readEvent(reader); id != deletedID || eventType != "DELETED" {
+		t.Errorf("resumed watch started with %s %s, want DELETED %s", eventType, id, deletedID)
+	}
+	if _, eventType, obj := readEvent(reader); eventType != "ADDED" || obj.Name != "next" {
+		t.Errorf("resumed watch then got %s %s, want ADDED next", eventType, obj.Name)
+	}
+
+	if code := do(t, server, http.MethodGet, base+"?watch=true&resourceVersion=abc", "", nil, nil); code != http.StatusBadRequest {
+		t.Errorf("watch with an invalid resourceVersion returned %d, want 400", code)
+	}
+}
+
+// TestSecretRedaction 测试 ECSMSecret 的值不会出现在任何响应和 WATCH 事件中，键的名称仍然可见。
+func TestSecretRedaction(t *testing.T) {
+	server := newTestServer(t, nil)
+	base := "/namespaces/default/ecsmsecrets"
+
+	resp, err := server.Client().Get(server.URL + APIPrefix + base + "?watch=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	check := func(what string, body []byte, keys ...string) {
+		t.Helper()
+		for _, value := range []string{"s3cret", "t0ken"} {
+			if bytes.Contains(body, []byte(value)) {
+				t.Errorf("%s contains the secret value %q: %s", what, value, body)
+			}
+		}
+		for _, key := range keys {
+			if !bytes.Contains(body, []byte(`"`+key+`":""`)) {
+				t.Errorf("%s is missing the key %q: %s", what, key, body)
+			}
+		}
+	}
+
+	secret := &ecsmv1.ECSMSecret{ObjectMeta: metav1.ObjectMeta{Name: "db"}, Data: map[string]string{"password": "s3cret"}}
+	var body json.RawMessage
+	if code := do(t, server, http.MethodPost, base, "application/json", secret, &body); code != http.StatusCreated {
+		t.Fatalf("create returned %d: %s", code, body)
+	}
+	check("CREATE", body, "password")
+	do(t, server, http.MethodGet, base+"/db", "", nil, &body)
+	check("GET", body, "password")
+	do(t, server, http.MethodGet, base, "", nil, &body)
+	check("LIST", body, "password")
+	do(t, server, http.MethodGet, "/ecsmsecrets", "", nil, &body)
+	check("LIST across namespaces", body, "password")
+	if code := do(t, server, http.MethodPatch, base+"/db", mergePatchType, `{"data":{"token":"t0ken"}}`, &body); code != http.StatusOK {
+		t.Fatalf("patch returned %d: %s", code, body)
+	}
+	check("PATCH", body, "password", "token")
+
+	scanner := bufio.NewScanner(resp.Body)
+	for i := 0; i < 2 && scanner.Scan(); i++ {
+		check("WATCH event", scanner.Bytes(), "password")
+	}
+}
+
+// TestSecretPutRoundTrip 测试读取被清空值的 ECSMSecret 之后原样 PUT 回去不会丢失保存的值，
+// 只有请求中非空的值和新增的键会被写入，请求中去掉的键会被删除。
+func TestSecretPutRoundTrip(t *testing.T) {
+	server, reg := newTestServerWithRegistry(t, nil)
+	base := "/namespaces/default/ecsmsecrets"
+
+	secret := &ecsmv1.ECSMSecret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Data:       map[string]string{"password": "s3cret", "token": "t0ken", "old": "x"},
+	}
+	var body json.RawMessage
+	if code := do(t, server, http.MethodPost, base, "application/json", secret, &body); code != http.StatusCreated {
+		t.Fatalf("create returned %d: %s", code, body)
+	}
+
+	var got ecsmv1.ECSMSecret
+	if code := do(t, server, http.MethodGet, base+"/db", "", nil, &got); code != http.StatusOK {
+		t.Fatalf("get returned %d", code)
+	}
+	got.Data["token"] = "n3w"
+	got.Data["user"] = "admin"
+	delete(got.Data, "old")
+	if code := do(t, server, http.MethodPut, base+"/db", "application/json", &got, &body); code != http.StatusOK {
+		t.Fatalf("put returned %d: %s", code, body)
+	}
+
+	stored, err := reg.GetSecret(context.Background(), "default", "db")
+	if err != nil {
+		t.Fatalf("GetSecret failed: %v", err)
+	}
+	want := map[string]string{"password": "s3cret", "token": "n3w", "user": "admin"}
+	if fmt.Sprint(stored.Data) != fmt.Sprint(want) {
+		t.Errorf("stored data = %v, want %v", stored.Data, want)
+	}
+}
+
+func TestAdmission(t *testing.T) {
+	chain := admission.NewChain()
+	// 只允许来自 registry.local 的镜像，并为没有 team 标签的服务补上默认值
+	chain.Register("allowed-images", admission.ValidationFunc(func(ctx context.Context, a *admission.Attributes) error {
+		svc, ok := a.Object.(*ecsmv1.ECSMService)
+		if !ok {
+			return nil
+		}
+		if !strings.HasPrefix(svc.Spec.Template.Image, "registry.local/") {
+			return fmt.Errorf("image %s is not allowed", svc.Spec.Template.Image)
+		}
+		return nil
+	}))
+	chain.Register("default-team", admission.MutationFunc(func(ctx context.Context, a *admission.Attributes) error {
+		if svc, ok := a.Object.(*ecsmv1.ECSMService); ok && svc.Labels["team"] == "" {
+			svc.Labels["team"] = "edge"
+		}
+		return nil
+	}))
+	server := newTestServer(t, chain)
+	const base = "/namespaces/default/ecsmservices"
+
+	var status metav1.Status
+	if code := do(t, server, http.MethodPost, base, "application/json", newTestService("web"), &status); code != http.StatusForbidden {
+		t.Fatalf("create with a disallowed image returned %d, want 403", code)
+	}
+	if !strings.Contains(status.Message, "allowed-images") {
+		t.Errorf("denial message %q does not name the plugin", status.Message)
+	}
+
+	svc := newTestService("web")
+	svc.Spec.Template.Image = "registry.local/web@1.0"
+	var created ecsmv1.ECSMService
+	if code := do(t, server, http.MethodPost, base, "application/json", svc, &created); code != http.StatusCreated {
+		t.Fatalf("create returned %d, want 201", code)
+	}
+	if created.Labels["team"] != "edge" {
+		t.Errorf("labels of the created service = %v, want team=edge", created.Labels)
+	}
+
+	// 补丁之后的对象同样要经过准入控制
+	patch := `{"spec":{"template":{"image":"docker.io/web@2.0"}}}`
+	if code := do(t, server, http.MethodPatch, base+"/web", mergePatchType, patch, nil); code != http.StatusForbidden {
+		t.Errorf("patch to a disallowed image returned %d, want 403", code)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	server := newTestServer(t, nil)
+	resp, err := server.Client().Get(server.URL + openapi.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var doc openapi.Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+
+	item := doc.Paths[APIPrefix+"/namespaces/{namespace}/ecsmservices/{name}"]
+	if item == nil || item.Get == nil || item.Put == nil || item.Patch == nil || item.Delete == nil {
+		t.Fatalf("ecsmservices item path = %+v", item)
+	}
+	if item.Get.Responses["200"].Content["application/json"].Schema.Ref == "" {
+		t.Error("GET ecsmservices has no response schema")
+	}
+	if doc.Paths[APIPrefix+"/namespaces/{namespace}/ecsmservices/{name}/scale"] == nil {
+		t.Error("scale subresource is missing")
+	}
+	if doc.Paths[APIPrefix+"/ecsmnodes/{name}"] == nil || doc.Paths[APIPrefix+"/ecsmservices"] == nil {
+		t.Error("cluster-scoped or cross-namespace paths are missing")
+	}
+	if _, ok := doc.SchemaForKind("ECSMService"); !ok {
+		t.Error("ECSMService schema is missing")
+	}
+}
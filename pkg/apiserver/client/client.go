@@ -0,0 +1,339 @@
+// file: pkg/apiserver/client/client.go
+
+// Package client 通过 ecsm-operator 的 API Server 访问 Registry。
+//
+// operator 运行时独占 Registry 的 bbolt 数据库文件，其他进程无法打开它，
+// 因此 ecsm-cli 等工具应该使用 Client，它以 HTTP 请求实现了 registry.Interface：
+//
+//	c, err := client.New("http://127.0.0.1:8090", nil)
+//	svc, err := c.GetService(ctx, "default", "web")
+//
+// 与直接使用 Registry 相比有两点不同：API Server 不返回 ECSMSecret 的值，读取的 Data 中所有的值都为空，
+// 更新时值为空的键保留保存的值；
+// Subscribe 通过 WATCH 请求实现，只能收到订阅之后发生的事件。
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apiserver"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// mergePatchType 是 API Server 支持的 PATCH 格式
+const mergePatchType = "application/merge-patch+json"
+
+// watchRetryInterval 是 WATCH 连接断开之后重新连接之前等待的时间
+const watchRetryInterval = time.Second
+
+// Client 通过 API Server 读写 Registry 中的对象，它可以被多个 goroutine 同时使用。
+type Client struct {
+	base       string
+	httpClient *http.Client
+}
+
+var _ registry.Interface = &Client{}
+
+// New 创建一个访问 server 上的 API Server 的 Client，server 是 API Server 的 URL，例如 "http://127.0.0.1:8090"。
+// httpClient 为 nil 时使用 http.DefaultClient，它不能设置 Timeout，否则 WATCH 请求会被中断，
+// 单个请求的超时应该通过 context 控制。
+func New(server string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL %q: %w", server, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q: must be http://HOST:PORT or https://HOST:PORT", server)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{base: strings.TrimSuffix(server, "/") + apiserver.APIPrefix, httpClient: httpClient}, nil
+}
+
+// objectPtr 约束了 API 对象的指针类型，例如 *ecsmv1.ECSMService。
+type objectPtr[T any] interface {
+	*T
+	runtime.Object
+	metav1.Object
+}
+
+// listPtr 约束了 API 对象列表的指针类型，例如 *ecsmv1.ECSMServiceList。
+type listPtr[T any] interface {
+	*T
+	runtime.Object
+	metav1.ListInterface
+}
+
+// path 返回资源的 URL 路径，namespace 为空时是集群级的路径，name 和 subresource 为空时省略。
+func path(resource, namespace, name, subresource string) string {
+	p := "/" + resource
+	if namespace != "" {
+		p = "/namespaces/" + url.PathEscape(namespace) + p
+	}
+	if name != "" {
+		p += "/" + url.PathEscape(name)
+	}
+	if subresource != "" {
+		p += "/" + subresource
+	}
+	return p
+}
+
+// do 发送一个请求并把响应解码到 out 中 (out 为 nil 时忽略响应体)。
+// API Server 返回的 metav1.Status 被转换为 *errors.StatusError，因此可以使用 errors.IsNotFound 等函数判断。
+func (c *Client) do(ctx context.Context, method, p, contentType string, body interface{}, out runtime.Object) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+p, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode the response of %s %s: %w", method, p, err)
+	}
+	// 与 Registry 返回的对象一样不带 TypeMeta
+	out.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+	return nil
+}
+
+// decodeError 把失败的响应转换为错误。
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var status metav1.Status
+	if err := json.Unmarshal(data, &status); err == nil && status.Kind == "Status" {
+		if status.Code == 0 {
+			status.Code = int32(resp.StatusCode)
+		}
+		return &errors.StatusError{ErrStatus: status}
+	}
+	return errors.NewGenericServerResponse(resp.StatusCode, resp.Request.Method, schema.GroupResource{}, "", strings.TrimSpace(string(data)), 0, false)
+}
+
+func get[T any, P objectPtr[T]](ctx context.Context, c *Client, resource, namespace, name, subresource string) (P, error) {
+	obj := P(new(T))
+	if err := c.do(ctx, http.MethodGet, path(resource, namespace, name, subresource), "", nil, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func list[T any, P listPtr[T]](ctx context.Context, c *Client, resource, namespace string) (P, string, error) {
+	obj := P(new(T))
+	if err := c.do(ctx, http.MethodGet, path(resource, namespace, "", ""), "", nil, obj); err != nil {
+		return nil, "", err
+	}
+	return obj, obj.GetResourceVersion(), nil
+}
+
+func create[T any, P objectPtr[T]](ctx context.Context, c *Client, resource string, obj P) (P, error) {
+	created := P(new(T))
+	if err := c.do(ctx, http.MethodPost, path(resource, obj.GetNamespace(), "", ""), "application/json", obj, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func update[T any, P objectPtr[T]](ctx context.Context, c *Client, resource, subresource string, obj P) (P, error) {
+	updated := P(new(T))
+	p := path(resource, obj.GetNamespace(), obj.GetName(), subresource)
+	if err := c.do(ctx, http.MethodPut, p, "application/json", obj, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// remove 删除一个对象。与 Registry 一样，对象不存在时不返回错误。
+func (c *Client) remove(ctx context.Context, resource, namespace, name string) error {
+	err := c.do(ctx, http.MethodDelete, path(resource, namespace, name, ""), "", nil, nil)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Patch 以 JSON Merge Patch 修改一个对象并把结果解码到 out 中，namespace 为空时是集群级资源。
+// 与先读取再整体替换不同，补丁中没有出现的字段 (例如 ECSMSecret 的值) 保持不变。
+func (c *Client) Patch(ctx context.Context, resource, namespace, name string, patch []byte, out runtime.Object) error {
+	return c.do(ctx, http.MethodPatch, path(resource, namespace, name, ""), mergePatchType, json.RawMessage(patch), out)
+}
+
+// watchedResource 是 Subscribe 监听的一种资源
+type watchedResource struct {
+	name      string
+	newObject func() runtime.Object
+}
+
+var watchedResources = []watchedResource{
+	{"ecsmservices", func() runtime.Object { return &ecsmv1.ECSMService{} }},
+	{"ecsmserviceautoscalers", func() runtime.Object { return &ecsmv1.ECSMServiceAutoscaler{} }},
+	{"ecsmjobs", func() runtime.Object { return &ecsmv1.ECSMJob{} }},
+	{"ecsmcronjobs", func() runtime.Object { return &ecsmv1.ECSMCronJob{} }},
+	{"ecsmnodesets", func() runtime.Object { return &ecsmv1.ECSMNodeSet{} }},
+	{"ecsmconfigs", func() runtime.Object { return &ecsmv1.ECSMConfig{} }},
+	{"ecsmsecrets", func() runtime.Object { return &ecsmv1.ECSMSecret{} }},
+	{"events", func() runtime.Object { return &ecsmv1.Event{} }},
+	{"ecsmnodes", func() runtime.Object { return &ecsmv1.ECSMNode{} }},
+	{"ecsmpriorityclasses", func() runtime.Object { return &ecsmv1.ECSMPriorityClass{} }},
+	{"ecsmnotifications", func() runtime.Object { return &ecsmv1.ECSMNotification{} }},
+}
+
+// Subscribe 实现了 registry.Interface 的同名方法。它为每种资源发起一个 WATCH 请求，
+// 连接断开时从收到的最后一个事件之后继续，直到调用返回的取消函数。
+// 不同资源的事件之间不保证按 Revision 排序。
+func (c *Client) Subscribe() (<-chan registry.Event, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan registry.Event, 100)
+	var wg sync.WaitGroup
+	for _, res := range watchedResources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.watchLoop(ctx, res, ch)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+	return ch, cancel
+}
+
+// watchLoop 持续监听一种资源，把事件发送到 ch，直到 ctx 被取消。
+func (c *Client) watchLoop(ctx context.Context, res watchedResource, ch chan<- registry.Event) {
+	var after uint64
+	for ctx.Err() == nil {
+		err := c.watch(ctx, res, after, func(event registry.Event) bool {
+			after = event.Revision
+			select {
+			case ch <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.IsResourceExpired(err) || errors.IsGone(err) {
+			// 错过的事件已经无法补发，从当前时间重新开始
+			klog.Warningf("Watch of %s fell behind, some events were lost", res.name)
+			after = 0
+		} else if err != nil {
+			klog.V(4).Infof("Watch of %s ended: %v", res.name, err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
+
+// watch 发起一个 WATCH 请求，对每个事件调用 handle，直到连接断开或 handle 返回 false。
+// after 不为 0 时从这个版本之后的事件开始。
+func (c *Client) watch(ctx context.Context, res watchedResource, after uint64, handle func(registry.Event) bool) error {
+	p := path(res.name, "", "", "") + "?watch=true"
+	if after > 0 {
+		p += "&resourceVersion=" + strconv.FormatUint(after, 10)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+p, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var line struct {
+			Type   string          `json:"type"`
+			Object json.RawMessage `json:"object"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("invalid watch event: %w", err)
+		}
+		if line.Type == "ERROR" {
+			var status metav1.Status
+			if err := json.Unmarshal(line.Object, &status); err != nil {
+				return fmt.Errorf("invalid watch error: %w", err)
+			}
+			return &errors.StatusError{ErrStatus: status}
+		}
+		obj := res.newObject()
+		if err := json.Unmarshal(line.Object, obj); err != nil {
+			return fmt.Errorf("invalid %s in watch event: %w", res.name, err)
+		}
+		obj.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		// 事件中对象的 resourceVersion 就是事件的版本，删除事件中是删除操作的版本
+		revision, _ := strconv.ParseUint(m.GetResourceVersion(), 10, 64)
+		key := m.GetName()
+		if m.GetNamespace() != "" {
+			key = m.GetNamespace() + "/" + key
+		}
+		event := registry.Event{
+			Type:            registry.EventType(line.Type),
+			Key:             key,
+			Object:          obj,
+			ResourceVersion: m.GetResourceVersion(),
+			Revision:        revision,
+		}
+		if !handle(event) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
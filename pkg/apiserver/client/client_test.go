@@ -0,0 +1,227 @@
+// file: pkg/apiserver/client/client_test.go
+
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apiserver"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	bolt "go.etcd.io/bbolt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestClient 启动一个使用临时 Registry 的 API Server，返回访问它的 Client 和底层的 Registry。
+func newTestClient(t *testing.T) (*Client, *registry.Registry) {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	transformer, err := registry.NewAESGCMTransformer(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create transformer: %v", err)
+	}
+	reg.SetEncryption(transformer)
+	api, err := apiserver.New(reg, nil)
+	if err != nil {
+		t.Fatalf("Failed to create API server: %v", err)
+	}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	t.Cleanup(api.Close)
+
+	c, err := New(server.URL, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c, reg
+}
+
+func newTestService(name string) *ecsmv1.ECSMService {
+	replicas := int32(2)
+	return &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:     ecsmv1.DeploymentStrategyTypeDynamic,
+				Replicas: &replicas,
+			},
+			Template: ecsmv1.ContainerTemplateSpec{Image: name + "@1.0"},
+		},
+	}
+}
+
+func TestNew_InvalidServer(t *testing.T) {
+	for _, server := range []string{"", "127.0.0.1:8090", "ftp://127.0.0.1", "http://"} {
+		if _, err := New(server, nil); err == nil {
+			t.Errorf("New(%q) error = nil, want an error", server)
+		}
+	}
+}
+
+func TestClient_ServiceLifecycle(t *testing.T) {
+	c, reg := newTestClient(t)
+	ctx := context.Background()
+
+	created, err := c.CreateService(ctx, newTestService("web"))
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if created.ResourceVersion == "" || created.Kind != "" {
+		t.Errorf("created = rv %q kind %q, want a resourceVersion and no TypeMeta", created.ResourceVersion, created.Kind)
+	}
+	if _, err := c.CreateService(ctx, newTestService("web")); !errors.IsAlreadyExists(err) {
+		t.Errorf("CreateService() of an existing service error = %v, want AlreadyExists", err)
+	}
+
+	// 写入的对象可以直接从 Registry 读到
+	stored, err := reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("registry GetService() error = %v", err)
+	}
+	if stored.ResourceVersion != created.ResourceVersion {
+		t.Errorf("stored resourceVersion = %q, want %q", stored.ResourceVersion, created.ResourceVersion)
+	}
+
+	list, rv, err := c.ListAllServices(ctx, "default")
+	if err != nil {
+		t.Fatalf("ListAllServices() error = %v", err)
+	}
+	if len(list.Items) != 1 || rv == "" {
+		t.Errorf("ListAllServices() = %d items, rv %q, want 1 item and a resourceVersion", len(list.Items), rv)
+	}
+
+	scale, err := c.GetServiceScale(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetServiceScale() error = %v", err)
+	}
+	scale.Spec.Replicas = 5
+	if _, err := c.UpdateServiceScale(ctx, scale); err != nil {
+		t.Fatalf("UpdateServiceScale() error = %v", err)
+	}
+	got, err := c.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService() error = %v", err)
+	}
+	if *got.Spec.DeploymentStrategy.Replicas != 5 {
+		t.Errorf("replicas = %d, want 5", *got.Spec.DeploymentStrategy.Replicas)
+	}
+
+	// 过期的 resourceVersion 会被拒绝
+	if _, err := c.UpdateService(ctx, created); !errors.IsConflict(err) {
+		t.Errorf("UpdateService() with a stale resourceVersion error = %v, want Conflict", err)
+	}
+
+	if err := c.DeleteService(ctx, "default", "web"); err != nil {
+		t.Fatalf("DeleteService() error = %v", err)
+	}
+	if _, err := c.GetService(ctx, "default", "web"); !errors.IsNotFound(err) {
+		t.Errorf("GetService() after delete error = %v, want NotFound", err)
+	}
+	// 与 Registry 一样，删除不存在的对象不是错误
+	if err := c.DeleteService(ctx, "default", "web"); err != nil {
+		t.Errorf("DeleteService() of a missing service error = %v, want nil", err)
+	}
+}
+
+func TestClient_ClusterScoped(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	pc := &ecsmv1.ECSMPriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}, Value: 1000}
+	if _, err := c.CreatePriorityClass(ctx, pc); err != nil {
+		t.Fatalf("CreatePriorityClass() error = %v", err)
+	}
+	got, err := c.GetPriorityClass(ctx, "high")
+	if err != nil {
+		t.Fatalf("GetPriorityClass() error = %v", err)
+	}
+	if got.Value != 1000 {
+		t.Errorf("value = %d, want 1000", got.Value)
+	}
+	list, _, err := c.ListPriorityClasses(ctx)
+	if err != nil {
+		t.Fatalf("ListPriorityClasses() error = %v", err)
+	}
+	if len(list.Items) != 1 {
+		t.Errorf("ListPriorityClasses() = %d items, want 1", len(list.Items))
+	}
+}
+
+func TestClient_Patch(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	secret := &ecsmv1.ECSMSecret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string]string{"password": "s3cret"},
+	}
+	if _, err := c.CreateSecret(ctx, secret); err != nil {
+		t.Fatalf("CreateSecret() error = %v", err)
+	}
+	var patched ecsmv1.ECSMSecret
+	if err := c.Patch(ctx, "ecsmsecrets", "default", "db", []byte(`{"metadata":{"labels":{"tier":"db"}}}`), &patched); err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if patched.Labels["tier"] != "db" {
+		t.Errorf("labels = %v, want tier=db", patched.Labels)
+	}
+	// API Server 不返回 Secret 的值
+	if v, ok := patched.Data["password"]; !ok || v != "" {
+		t.Errorf("data = %v, want the password key with an empty value", patched.Data)
+	}
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	c, reg := newTestClient(t)
+	ctx := context.Background()
+
+	events, cancel := c.Subscribe()
+	defer cancel()
+
+	// WATCH 请求建立之前的事件收不到，反复写入直到收到第一个事件
+	deadline := time.After(10 * time.Second)
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	if _, err := reg.CreateNode(ctx, &ecsmv1.ECSMNode{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}); err != nil {
+		t.Fatalf("CreateNode() error = %v", err)
+	}
+	for {
+		select {
+		case event := <-events:
+			if event.Key != "node-1" || event.Object == nil || event.Revision == 0 {
+				t.Fatalf("event = %+v, want an event of node-1 with a revision", event)
+			}
+			if _, ok := event.Object.(*ecsmv1.ECSMNode); !ok {
+				t.Fatalf("event object = %T, want *ECSMNode", event.Object)
+			}
+			cancel()
+			for range events {
+			}
+			return
+		case <-ticker.C:
+			node, err := reg.GetNode(ctx, "node-1")
+			if err != nil {
+				t.Fatalf("GetNode() error = %v", err)
+			}
+			node.Labels = map[string]string{"tick": time.Now().Format("150405.000000")}
+			if _, err := reg.UpdateNode(ctx, node); err != nil {
+				t.Fatalf("UpdateNode() error = %v", err)
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for an event")
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// file: pkg/apiserver/client/resources.go
+
+package client
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+// 以下方法实现了 registry.Interface 中对应的方法。
+
+func (c *Client) CreateService(ctx context.Context, service *ecsmv1.ECSMService) (*ecsmv1.ECSMService, error) {
+	return create(ctx, c, "ecsmservices", service)
+}
+
+func (c *Client) UpdateService(ctx context.Context, service *ecsmv1.ECSMService) (*ecsmv1.ECSMService, error) {
+	return update(ctx, c, "ecsmservices", "", service)
+}
+
+func (c *Client) UpdateServiceStatus(ctx context.Context, service *ecsmv1.ECSMService) (*ecsmv1.ECSMService, error) {
+	return update(ctx, c, "ecsmservices", "status", service)
+}
+
+func (c *Client) GetService(ctx context.Context, namespace, name string) (*ecsmv1.ECSMService, error) {
+	return get[ecsmv1.ECSMService](ctx, c, "ecsmservices", namespace, name, "")
+}
+
+func (c *Client) ListAllServices(ctx context.Context, namespace string) (*ecsmv1.ECSMServiceList, string, error) {
+	return list[ecsmv1.ECSMServiceList](ctx, c, "ecsmservices", namespace)
+}
+
+func (c *Client) DeleteService(ctx context.Context, namespace, name string) error {
+	return c.remove(ctx, "ecsmservices", namespace, name)
+}
+
+func (c *Client) GetServiceScale(ctx context.Context, namespace, name string) (*ecsmv1.ECSMServiceScale, error) {
+	return get[ecsmv1.ECSMServiceScale](ctx, c, "ecsmservices", namespace, name, "scale")
+}
+
+func (c *Client) UpdateServiceScale(ctx context.Context, scale *ecsmv1.ECSMServiceScale) (*ecsmv1.ECSMServiceScale, error) {
+	return update(ctx, c, "ecsmservices", "scale", scale)
+}
+
+func (c *Client) CreateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error) {
+	return create(ctx, c, "events", event)
+}
+
+func (c *Client) UpdateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error) {
+	return update(ctx, c, "events", "", event)
+}
+
+func (c *Client) GetEvent(ctx context.Context, namespace, name string) (*ecsmv1.Event, error) {
+	return get[ecsmv1.Event](ctx, c, "events", namespace, name, "")
+}
+
+func (c *Client) ListEvents(ctx context.Context, namespace string) (*ecsmv1.EventList, string, error) {
+	return list[ecsmv1.EventList](ctx, c, "events", namespace)
+}
+
+func (c *Client) DeleteEvent(ctx context.Context, namespace, name string) error {
+	return c.remove(ctx, "events", namespace, name)
+}
+
+func (c *Client) CreateNode(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error) {
+	return create(ctx, c, "ecsmnodes", node)
+}
+
+func (c *Client) UpdateNode(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error) {
+	return update(ctx, c, "ecsmnodes", "", node)
+}
+
+func (c *Client) UpdateNodeStatus(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error) {
+	return update(ctx, c, "ecsmnodes", "status", node)
+}
+
+func (c *Client) GetNode(ctx context.Context, name string) (*ecsmv1.ECSMNode, error) {
+	return get[ecsmv1.ECSMNode](ctx, c, "ecsmnodes", "", name, "")
+}
+
+func (c *Client) ListNodes(ctx context.Context) (*ecsmv1.ECSMNodeList, string, error) {
+	return list[ecsmv1.ECSMNodeList](ctx, c, "ecsmnodes", "")
+}
+
+func (c *Client) DeleteNode(ctx context.Context, name string) error {
+	return c.remove(ctx, "ecsmnodes", "", name)
+}
+
+func (c *Client) CreateAutoscaler(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error) {
+	return create(ctx, c, "ecsmserviceautoscalers", autoscaler)
+}
+
+func (c *Client) UpdateAutoscaler(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error) {
+	return update(ctx, c, "ecsmserviceautoscalers", "", autoscaler)
+}
+
+func (c *Client) UpdateAutoscalerStatus(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error) {
+	return update(ctx, c, "ecsmserviceautoscalers", "status", autoscaler)
+}
+
+func (c *Client) GetAutoscaler(ctx context.Context, namespace, name string) (*ecsmv1.ECSMServiceAutoscaler, error) {
+	return get[ecsmv1.ECSMServiceAutoscaler](ctx, c, "ecsmserviceautoscalers", namespace, name, "")
+}
+
+func (c *Client) ListAutoscalers(ctx context.Context, namespace string) (*ecsmv1.ECSMServiceAutoscalerList, string, error) {
+	return list[ecsmv1.ECSMServiceAutoscalerList](ctx, c, "ecsmserviceautoscalers", namespace)
+}
+
+func (c *Client) DeleteAutoscaler(ctx context.Context, namespace, name string) error {
+	return c.remove(ctx, "ecsmserviceautoscalers", namespace, name)
+}
+
+func (c *Client) CreateJob(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error) {
+	return create(ctx, c, "ecsmjobs", job)
+}
+
+func (c *Client) UpdateJob(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error) {
+	return update(ctx, c, "ecsmjobs", "", job)
+}
+
+func (c *Client) UpdateJobStatus(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error) {
+	return update(ctx, c, "ecsmjobs", "status", job)
+}
+
+func (c *Client) GetJob(ctx context.Context, namespace, name string) (*ecsmv1.ECSMJob, error) {
+	return get[ecsmv1.ECSMJob](ctx, c, "ecsmjobs", namespace, name, "")
+}
+
+func (c *Client) ListJobs(ctx context.Context, namespace string) (*ecsmv1.ECSMJobList, string, error) {
+	return list[ecsmv1.ECSMJobList](ctx, c, "ecsmjobs", namespace)
+}
+
+func (c *Client) DeleteJob(ctx context.Context, namespace, name string) error {
+	return c.remove(ctx, "ecsmjobs", namespace, name)
+}
+
+func (c *Client) CreateCronJob(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error) {
+	return create(ctx, c, "ecsmcronjobs", cronJob)
+}
+
+func (c *Client) UpdateCronJob(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error) {
+	return update(ctx, c, "ecsmcronjobs", "", cronJob)
+}
+
+func (c *Client) UpdateCronJobStatus(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error) {
+	return update(ctx, c, "ecsmcronjobs", "status", cronJob)
+}
+
+func (c *Client) GetCronJob(ctx context.Context, namespace, name string) (*ecsmv1.ECSMCronJob, error) {
+	return get[ecsmv1.ECSMCronJob](ctx, c, "ecsmcronjobs", namespace, name, "")
+}
+
+func (c *Client) ListCronJobs(ctx context.Context, namespace string) (*ecsmv1.ECSMCronJobList, string, error) {
+	return list[ecsmv1.ECSMCronJobList](ctx, c, "ecsmcronjobs", namespace)
+}
+
+func (c *Client) DeleteCronJob(ctx context.Context, namespace, name string) error {
+	return c.remove(ctx, "ecsmcronjobs", namespace, name)
+}
+
+func (c *Client) CreateNodeSet(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error) {
+	return create(ctx, c, "ecsmnodesets", nodeSet)
+}
+
+func (c *Client) UpdateNodeSet(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error) {
+	return update(ctx, c, "ecsmnodesets", "", nodeSet)
+}
+
+func (c *Client) UpdateNodeSetStatus(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error) {
+	return update(ctx, c, "ecsmnodesets", "status", nodeSet)
+}
+
+func (c *Client) GetNodeSet(ctx context.Context, namespace, name string) (*ecsmv1.ECSMNodeSet, error) {
+	return get[ecsmv1.ECSMNodeSet](ctx, c, "ecsmnodesets", namespace, name, "")
+}
+
+func (c *Client) ListNodeSets(ctx context.Context, namespace string) (*ecsmv1.ECSMNodeSetList, string, error) {
+	return list[ecsmv1.ECSMNodeSetList](ctx, c, "ecsmnodesets", namespace)
+}
+
+func (c *Client) DeleteNodeSet(ctx context.Context, namespace, name string) error {
+	return c.remove(ctx, "ecsmnodesets", namespace, name)
+}
+
+func (c *Client) CreateConfig(ctx context.Context, config *ecsmv1.ECSMConfig) (*ecsmv1.ECSMConfig, error) {
+	return create(ctx, c, "ecsmconfigs", config)
+}
+
+func (c *Client) UpdateConfig(ctx context.Context, config *ecsmv1.ECSMConfig) (*ecsmv1.ECSMConfig, error) {
+	return update(ctx, c, "ecsmconfigs", "", config)
+}
+
+func (c *Client) GetConfig(ctx context.Context, namespace, name string) (*ecsmv1.ECSMConfig, error) {
+	return get[ecsmv1.ECSMConfig](ctx, c, "ecsmconfigs", namespace, name, "")
+}
+
+func (c *Client) ListConfigs(ctx context.Context, namespace string) (*ecsmv1.ECSMConfigList, string, error) {
+	return list[ecsmv1.ECSMConfigList](ctx, c, "ecsmconfigs", namespace)
+}
+
+func (c *Client) DeleteConfig(ctx context.Context, namespace, name string) error {
+	return c.remove(ctx, "ecsmconfigs", namespace, name)
+}
+
+func (c *Client) CreateSecret(ctx context.Context, secret *ecsmv1.ECSMSecret) (*ecsmv1.ECSMSecret, error) {
+	return create(ctx, c, "ecsmsecrets", secret)
+}
+
+func (c *Client) UpdateSecret(ctx context.Context, secret *ecsmv1.ECSMSecret) (*ecsmv1.ECSMSecret, error) {
+	return update(ctx, c, "ecsmsecrets", "", secret)
+}
+
+func (c *Client) GetSecret(ctx context.Context, namespace, name string) (*ecsmv1.ECSMSecret, error) {
+	return get[ecsmv1.ECSMSecret](ctx, c, "ecsmsecrets", namespace, name, "")
+}
+
+func (c *Client) ListSecrets(ctx context.Context, namespace string) (*ecsmv1.ECSMSecretList, string, error) {
+	return list[ecsmv1.ECSMSecretList](ctx, c, "ecsmsecrets", namespace)
+}
+
+func (c *Client) DeleteSecret(ctx context.Context, namespace, name string) error {
+	return c.remove(ctx, "ecsmsecrets", namespace, name)
+}
+
+func (c *Client) CreatePriorityClass(ctx context.Context, pc *ecsmv1.ECSMPriorityClass) (*ecsmv1.ECSMPriorityClass, error) {
+	return create(ctx, c, "ecsmpriorityclasses", pc)
+}
+
+func (c *Client) UpdatePriorityClass(ctx context.Context, pc *ecsmv1.ECSMPriorityClass) (*ecsmv1.ECSMPriorityClass, error) {
+	return update(ctx, c, "ecsmpriorityclasses", "", pc)
+}
+
+func (c *Client) GetPriorityClass(ctx context.Context, name string) (*ecsmv1.ECSMPriorityClass, error) {
+	return get[ecsmv1.ECSMPriorityClass](ctx, c, "ecsmpriorityclasses", "", name, "")
+}
+
+func (c *Client) ListPriorityClasses(ctx context.Context) (*ecsmv1.ECSMPriorityClassList, string, error) {
+	return list[ecsmv1.ECSMPriorityClassList](ctx, c, "ecsmpriorityclasses", "")
+}
+
+func (c *Client) DeletePriorityClass(ctx context.Context, name string) error {
+	return c.remove(ctx, "ecsmpriorityclasses", "", name)
+}
+
+func (c *Client) CreateNotification(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error) {
+	return create(ctx, c, "ecsmnotifications", notification)
+}
+
+func (c *Client) UpdateNotification(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error) {
+	return update(ctx, c, "ecsmnotifications", "", notification)
+}
+
+func (c *Client) UpdateNotificationStatus(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error) {
+	return update(ctx, c, "ecsmnotifications", "status", notification)
+}
+
+func (c *Client) GetNotification(ctx context.Context, name string) (*ecsmv1.ECSMNotification, error) {
+	return get[ecsmv1.ECSMNotification](ctx, c, "ecsmnotifications", "", name, "")
+}
+
+func (c *Client) ListNotifications(ctx context.Context) (*ecsmv1.ECSMNotificationList, string, error) {
+	return list[ecsmv1.ECSMNotificationList](ctx, c, "ecsmnotifications", "")
+}
+
+func (c *Client) DeleteNotification(ctx context.Context, name string) error {
+	return c.remove(ctx, "ecsmnotifications", "", name)
+}
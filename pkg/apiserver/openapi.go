@@ -0,0 +1,92 @@
+// file: pkg/apiserver/openapi.go
+
+package apiserver
+
+import (
+	"github.com/fx147/ecsm-operator/pkg/openapi"
+)
+
+// openAPIDocument 返回描述 Server 所有路径的 OpenAPI 文档，类型的 schema 与 openapi.NewDocument 共享。
+func (s *Server) openAPIDocument() *openapi.Document {
+	doc := *openapi.NewDocument()
+	doc.Paths = make(map[string]*openapi.PathItem)
+	for _, res := range s.resources {
+		collection := APIPrefix + "/" + res.name
+		var params []openapi.Parameter
+		if res.namespaced {
+			// 命名空间资源还可以跨命名空间列出
+			doc.Paths[collection] = &openapi.PathItem{Get: listOperation(res, "listAll")}
+			collection = APIPrefix + "/namespaces/{namespace}/" + res.name
+			params = append(params, pathParameter("namespace"))
+		}
+		doc.Paths[collection] = &openapi.PathItem{
+			Get:        listOperation(res, "list"),
+			Post:       &openapi.Operation{OperationID: "create" + res.kind, RequestBody: body(jsonType, res.kind), Responses: responses("201", res.kind)},
+			Parameters: params,
+		}
+
+		item := collection + "/{name}"
+		params = append(params, pathParameter("name"))
+		doc.Paths[item] = accessorPathItem(res.kind, res.kind, params)
+		doc.Paths[item].Delete = &openapi.Operation{
+			OperationID: "delete" + res.kind,
+			Responses:   map[string]*openapi.Response{"200": {Description: "Status"}},
+		}
+		if res.updateStatus != nil {
+			doc.Paths[item+"/status"] = accessorPathItem(res.kind+"Status", res.kind, params)
+		}
+		if res.scale != nil {
+			doc.Paths[item+"/scale"] = accessorPathItem(res.kind+"Scale", res.scale.kind, params)
+		}
+	}
+	return &doc
+}
+
+// jsonType 是请求体和响应的媒体类型
+const jsonType = "application/json"
+
+// accessorPathItem 返回可以读取、替换和修改 kind 对象的路径，operation 是操作 ID 的后缀。
+func accessorPathItem(operation, kind string, params []openapi.Parameter) *openapi.PathItem {
+	return &openapi.PathItem{
+		Get:        &openapi.Operation{OperationID: "read" + operation, Responses: responses("200", kind)},
+		Put:        &openapi.Operation{OperationID: "replace" + operation, RequestBody: body(jsonType, kind), Responses: responses("200", kind)},
+		Patch:      &openapi.Operation{OperationID: "patch" + operation, RequestBody: body(mergePatchType, kind), Responses: responses("200", kind)},
+		Parameters: params,
+	}
+}
+
+// listOperation 返回列出或监听 res 的操作。
+func listOperation(res *resource, prefix string) *openapi.Operation {
+	return &openapi.Operation{
+		OperationID: prefix + res.kind,
+		Parameters: []openapi.Parameter{
+			queryParameter("labelSelector", "string", "只返回标签匹配的对象"),
+			queryParameter("watch", "boolean", "为 true 时以换行分隔的 JSON 流 (Accept 为 text/event-stream 时以 Server-Sent Events) 返回之后的变化"),
+			queryParameter("resourceVersion", "string", "监听时从这个版本之后的变化开始返回，版本太旧时返回 410"),
+			queryParameter("timeoutSeconds", "integer", "监听的超时时间"),
+		},
+		Responses: responses("200", res.kind+"List"),
+	}
+}
+
+func pathParameter(name string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+}
+
+func queryParameter(name, typ, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: &openapi.Schema{Type: typ}}
+}
+
+func body(mediaType, kind string) *openapi.RequestBody {
+	return &openapi.RequestBody{
+		Required: true,
+		Content:  map[string]*openapi.MediaType{mediaType: {Schema: openapi.KindRef(kind)}},
+	}
+}
+
+func responses(code, kind string) map[string]*openapi.Response {
+	return map[string]*openapi.Response{code: {
+		Description: kind,
+		Content:     map[string]*openapi.MediaType{jsonType: {Schema: openapi.KindRef(kind)}},
+	}}
+}
@@ -0,0 +1,262 @@
+// file: pkg/apiserver/resources.go
+
+package apiserver
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// objectPtr 约束了 API 对象的指针类型，例如 *ecsmv1.ECSMJob。
+type objectPtr[T any] interface {
+	*T
+	runtime.Object
+	metav1.Object
+}
+
+// operations 是一种资源在 Registry 上的类型化操作，不支持的操作为 nil。
+// 集群级资源的 get 和 delete 忽略 namespace 参数。
+type operations[P runtime.Object, L runtime.Object] struct {
+	create       func(ctx context.Context, obj P) (P, error)
+	update       func(ctx context.Context, obj P) (P, error)
+	updateStatus func(ctx context.Context, obj P) (P, error)
+	get          func(ctx context.Context, namespace, name string) (P, error)
+	list         func(ctx context.Context, namespace string) (L, string, error)
+	delete       func(ctx context.Context, namespace, name string) error
+}
+
+// resource 描述了 API Server 暴露的一种资源，它的操作已经被擦除为 runtime.Object。
+type resource struct {
+	// name 是资源的复数名称，即 URL 中的路径段
+	name string
+	// kind 是资源的 Kind，列表的 Kind 为 kind + "List"
+	kind       string
+	namespaced bool
+
+	newObject func() runtime.Object
+	// matches 判断 Registry 事件中的对象是否属于这种资源
+	matches func(obj runtime.Object) bool
+
+	create       func(ctx context.Context, obj runtime.Object) (runtime.Object, error)
+	update       func(ctx context.Context, obj runtime.Object) (runtime.Object, error)
+	updateStatus func(ctx context.Context, obj runtime.Object) (runtime.Object, error)
+	get          func(ctx context.Context, namespace, name string) (runtime.Object, error)
+	list         func(ctx context.Context, namespace string) (runtime.Object, error)
+	delete       func(ctx context.Context, namespace, name string) error
+
+	// scale 不为 nil 时资源支持 scale 子资源
+	scale *accessor
+	// redact 不为 nil 时在对象写入响应 (包括 WATCH 事件) 之前清除其中的敏感字段，它修改的是对象的副本
+	redact func(obj runtime.Object)
+	// unredact 不为 nil 时在 PUT 写入之前，用当前对象补回请求中被 redact 清空的字段，
+	// 使读取之后原样写回的对象不会丢失敏感数据
+	unredact func(obj, current runtime.Object)
+}
+
+// accessor 是一个可以读取和整体替换的对象：资源本身、它的 status 子资源或 ecsmservices/scale 这样的子资源。
+type accessor struct {
+	kind      string
+	newObject func() runtime.Object
+	get       func(ctx context.Context, namespace, name string) (runtime.Object, error)
+	update    func(ctx context.Context, obj runtime.Object) (runtime.Object, error)
+	redact    func(obj runtime.Object)
+	unredact  func(obj, current runtime.Object)
+}
+
+// newResource 把类型化的 operations 包装成 resource。
+func newResource[T any, P objectPtr[T], L runtime.Object](name, kind string, namespaced bool, ops operations[P, L]) *resource {
+	res := &resource{
+		name:       name,
+		kind:       kind,
+		namespaced: namespaced,
+		newObject:  func() runtime.Object { return P(new(T)) },
+		matches: func(obj runtime.Object) bool {
+			_, ok := obj.(P)
+			return ok
+		},
+		create: func(ctx context.Context, obj runtime.Object) (runtime.Object, error) {
+			return ops.create(ctx, obj.(P))
+		},
+		update: func(ctx context.Context, obj runtime.Object) (runtime.Object, error) {
+			return ops.update(ctx, obj.(P))
+		},
+		get: func(ctx context.Context, namespace, name string) (runtime.Object, error) {
+			return ops.get(ctx, namespace, name)
+		},
+		list: func(ctx context.Context, namespace string) (runtime.Object, error) {
+			list, rv, err := ops.list(ctx, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if lm, ok := any(list).(metav1.ListInterface); ok {
+				lm.SetResourceVersion(rv)
+			}
+			return list, nil
+		},
+		delete: ops.delete,
+	}
+	if ops.updateStatus != nil {
+		res.updateStatus = func(ctx context.Context, obj runtime.Object) (runtime.Object, error) {
+			return ops.updateStatus(ctx, obj.(P))
+		}
+	}
+	return res
+}
+
+// newResources 返回 API Server 暴露的所有资源，以复数名称为索引。
+func newResources(reg registry.Interface) map[string]*resource {
+	resources := []*resource{
+		newResource[ecsmv1.ECSMService]("ecsmservices", "ECSMService", true, operations[*ecsmv1.ECSMService, *ecsmv1.ECSMServiceList]{
+			create:       reg.CreateService,
+			update:       reg.UpdateService,
+			updateStatus: reg.UpdateServiceStatus,
+			get:          reg.GetService,
+			list:         reg.ListAllServices,
+			delete:       reg.DeleteService,
+		}),
+		newResource[ecsmv1.ECSMServiceAutoscaler]("ecsmserviceautoscalers", "ECSMServiceAutoscaler", true, operations[*ecsmv1.ECSMServiceAutoscaler, *ecsmv1.ECSMServiceAutoscalerList]{
+			create:       reg.CreateAutoscaler,
+			update:       reg.UpdateAutoscaler,
+			updateStatus: reg.UpdateAutoscalerStatus,
+			get:          reg.GetAutoscaler,
+			list:         reg.ListAutoscalers,
+			delete:       reg.DeleteAutoscaler,
+		}),
+		newResource[ecsmv1.ECSMJob]("ecsmjobs", "ECSMJob", true, operations[*ecsmv1.ECSMJob, *ecsmv1.ECSMJobList]{
+			create:       reg.CreateJob,
+			update:       reg.UpdateJob,
+			updateStatus: reg.UpdateJobStatus,
+			get:          reg.GetJob,
+			list:         reg.ListJobs,
+			delete:       reg.DeleteJob,
+		}),
+		newResource[ecsmv1.ECSMCronJob]("ecsmcronjobs", "ECSMCronJob", true, operations[*ecsmv1.ECSMCronJob, *ecsmv1.ECSMCronJobList]{
+			create:       reg.CreateCronJob,
+			update:       reg.UpdateCronJob,
+			updateStatus: reg.UpdateCronJobStatus,
+			get:          reg.GetCronJob,
+			list:         reg.ListCronJobs,
+			delete:       reg.DeleteCronJob,
+		}),
+		newResource[ecsmv1.ECSMNodeSet]("ecsmnodesets", "ECSMNodeSet", true, operations[*ecsmv1.ECSMNodeSet, *ecsmv1.ECSMNodeSetList]{
+			create:       reg.CreateNodeSet,
+			update:       reg.UpdateNodeSet,
+			updateStatus: reg.UpdateNodeSetStatus,
+			get:          reg.GetNodeSet,
+			list:         reg.ListNodeSets,
+			delete:       reg.DeleteNodeSet,
+		}),
+		newResource[ecsmv1.ECSMConfig]("ecsmconfigs", "ECSMConfig", true, operations[*ecsmv1.ECSMConfig, *ecsmv1.ECSMConfigList]{
+			create: reg.CreateConfig,
+			update: reg.UpdateConfig,
+			get:    reg.GetConfig,
+			list:   reg.ListConfigs,
+			delete: reg.DeleteConfig,
+		}),
+		newResource[ecsmv1.ECSMSecret]("ecsmsecrets", "ECSMSecret", true, operations[*ecsmv1.ECSMSecret, *ecsmv1.ECSMSecretList]{
+			create: reg.CreateSecret,
+			update: reg.UpdateSecret,
+			get:    reg.GetSecret,
+			list:   reg.ListSecrets,
+			delete: reg.DeleteSecret,
+		}),
+		newResource[ecsmv1.Event]("events", "Event", true, operations[*ecsmv1.Event, *ecsmv1.EventList]{
+			create: reg.CreateEvent,
+			update: reg.UpdateEvent,
+			get:    reg.GetEvent,
+			list:   reg.ListEvents,
+			delete: reg.DeleteEvent,
+		}),
+		newResource[ecsmv1.ECSMNode]("ecsmnodes", "ECSMNode", false, operations[*ecsmv1.ECSMNode, *ecsmv1.ECSMNodeList]{
+			create:       reg.CreateNode,
+			update:       reg.UpdateNode,
+			updateStatus: reg.UpdateNodeStatus,
+			get: func(ctx context.Context, _, name string) (*ecsmv1.ECSMNode, error) {
+				return reg.GetNode(ctx, name)
+			},
+			list: func(ctx context.Context, _ string) (*ecsmv1.ECSMNodeList, string, error) {
+				return reg.ListNodes(ctx)
+			},
+			delete: func(ctx context.Context, _, name string) error {
+				return reg.DeleteNode(ctx, name)
+			},
+		}),
+		newResource[ecsmv1.ECSMPriorityClass]("ecsmpriorityclasses", "ECSMPriorityClass", false, operations[*ecsmv1.ECSMPriorityClass, *ecsmv1.ECSMPriorityClassList]{
+			create: reg.CreatePriorityClass,
+			update: reg.UpdatePriorityClass,
+			get: func(ctx context.Context, _, name string) (*ecsmv1.ECSMPriorityClass, error) {
+				return reg.GetPriorityClass(ctx, name)
+			},
+			list: func(ctx context.Context, _ string) (*ecsmv1.ECSMPriorityClassList, string, error) {
+				return reg.ListPriorityClasses(ctx)
+			},
+			delete: func(ctx context.Context, _, name string) error {
+				return reg.DeletePriorityClass(ctx, name)
+			},
+		}),
+		newResource[ecsmv1.ECSMNotification]("ecsmnotifications", "ECSMNotification", false, operations[*ecsmv1.ECSMNotification, *ecsmv1.ECSMNotificationList]{
+			create:       reg.CreateNotification,
+			update:       reg.UpdateNotification,
+			updateStatus: reg.UpdateNotificationStatus,
+			get: func(ctx context.Context, _, name string) (*ecsmv1.ECSMNotification, error) {
+				return reg.GetNotification(ctx, name)
+			},
+			list: func(ctx context.Context, _ string) (*ecsmv1.ECSMNotificationList, string, error) {
+				return reg.ListNotifications(ctx)
+			},
+			delete: func(ctx context.Context, _, name string) error {
+				return reg.DeleteNotification(ctx, name)
+			},
+		}),
+	}
+
+	byName := make(map[string]*resource, len(resources))
+	for _, res := range resources {
+		byName[res.name] = res
+	}
+	byName["ecsmsecrets"].redact = redactSecret
+	byName["ecsmsecrets"].unredact = unredactSecret
+	byName["ecsmservices"].scale = &accessor{
+		kind:      "ECSMServiceScale",
+		newObject: func() runtime.Object { return &ecsmv1.ECSMServiceScale{} },
+		get: func(ctx context.Context, namespace, name string) (runtime.Object, error) {
+			return reg.GetServiceScale(ctx, namespace, name)
+		},
+		update: func(ctx context.Context, obj runtime.Object) (runtime.Object, error) {
+			return reg.UpdateServiceScale(ctx, obj.(*ecsmv1.ECSMServiceScale))
+		},
+	}
+	return byName
+}
+
+// redactSecret 清除 ECSMSecret 中所有的值，只保留键的名称。
+// API Server 没有认证，与 ecsm-cli get secrets 一样不返回敏感数据。
+func redactSecret(obj runtime.Object) {
+	if secret, ok := obj.(*ecsmv1.ECSMSecret); ok {
+		for k := range secret.Data {
+			secret.Data[k] = ""
+		}
+	}
+}
+
+// unredactSecret 把 PUT 请求中值为空的键恢复为当前保存的值，新增的键和非空的值保持请求中的内容，
+// 因此读取之后修改部分的值再整体写回不会清空其余的值。删除一个键需要在请求中去掉它。
+func unredactSecret(obj, current runtime.Object) {
+	secret, ok := obj.(*ecsmv1.ECSMSecret)
+	if !ok {
+		return
+	}
+	stored, ok := current.(*ecsmv1.ECSMSecret)
+	if !ok {
+		return
+	}
+	for k, v := range secret.Data {
+		if old, exists := stored.Data[k]; v == "" && exists {
+			secret.Data[k] = old
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// file: pkg/apiserver/watchcache.go
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// watchCacheSize 是 watchCache 保留的最近事件的数量，决定了客户端断开后最多可以落后多少个版本恢复监听
+	watchCacheSize = 1000
+	// watcherBufferSize 是每个监听者在补发的事件之外可以积压的事件数量，积压更多时监听者被强制结束
+	watcherBufferSize = 100
+)
+
+// watchCache 订阅 Registry 的事件并保留最近的一段，使客户端可以从某个 resourceVersion 之后恢复监听，
+// 断线重连时不会丢失中间的事件。
+type watchCache struct {
+	cancel func()
+
+	lock sync.Mutex
+	// events 是按 Revision 递增排列的最近事件
+	events []registry.Event
+	// oldest 是可以恢复监听的最小版本：Revision 大于 oldest 的事件全部在 events 中
+	oldest uint64
+	// latest 是最后一个收到的事件的版本
+	latest   uint64
+	watchers map[int]*cacheWatcher
+	nextID   int
+}
+
+// newWatchCache 创建一个 watchCache，它从当前的全局版本开始记录 reg 的事件。
+func newWatchCache(reg registry.Interface) (*watchCache, error) {
+	// 先订阅再读取版本，读取期间发生的写操作不会被遗漏
+	events, cancel := reg.Subscribe()
+	// 任何一次 LIST 都返回全局版本
+	_, rv, err := reg.ListPriorityClasses(context.Background())
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to get the current resource version: %w", err)
+	}
+	current, err := parseResourceVersion(rv)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	c := &watchCache{
+		cancel:   cancel,
+		oldest:   current,
+		latest:   current,
+		watchers: make(map[int]*cacheWatcher),
+	}
+	go func() {
+		for event := range events {
+			c.add(event)
+		}
+	}()
+	return c, nil
+}
+
+// stop 取消订阅并结束所有监听者。
+func (c *watchCache) stop() {
+	c.cancel()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for id, w := range c.watchers {
+		delete(c.watchers, id)
+		close(w.result)
+	}
+}
+
+// add 记录一个事件并把它分发给所有监听者。
+func (c *watchCache) add(event registry.Event) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if event.Revision <= c.latest {
+		// 订阅之后、读取版本之前发生的写操作
+		return
+	}
+	if event.Revision != c.latest+1 {
+		// Registry 在订阅者的 channel 满时会丢弃事件，此后缓存不再完整，
+		// 从这个事件重新开始记录，所有监听者需要重新 LIST
+		klog.Warningf("Watch cache missed events between resource versions %d and %d, expiring all watchers", c.latest, event.Revision)
+		c.events = c.events[:0]
+		c.oldest = event.Revision - 1
+		for id, w := range c.watchers {
+			delete(c.watchers, id)
+			w.expire()
+		}
+	}
+
+	if len(c.events) == watchCacheSize {
+		c.oldest = c.events[0].Revision
+		c.events = append(c.events[:0], c.events[1:]...)
+	}
+	c.events = append(c.events, event)
+	c.latest = event.Revision
+
+	for id, w := range c.watchers {
+		if event.Revision <= w.after {
+			continue
+		}
+		select {
+		case w.result <- event:
+		default:
+			klog.V(2).Infof("Watcher %d is too slow, closing it", id)
+			delete(c.watchers, id)
+			w.expire()
+		}
+	}
+}
+
+// watch 返回一个接收 Revision 大于 after 的所有事件的监听者，缓存中已有的事件先被补发。
+// after 为 0 时只接收之后发生的事件。after 早于缓存中最早的事件时返回 Expired 错误。
+func (c *watchCache) watch(after uint64) (*cacheWatcher, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if after == 0 {
+		after = c.latest
+	}
+	if after < c.oldest {
+		return nil, errors.NewResourceExpired(fmt.Sprintf("too old resource version: %d (%d)", after, c.oldest))
+	}
+
+	var backlog []registry.Event
+	for _, event := range c.events {
+		if event.Revision > after {
+			backlog = append(backlog, event)
+		}
+	}
+	w := &cacheWatcher{
+		result: make(chan registry.Event, len(backlog)+watcherBufferSize),
+		after:  after,
+	}
+	for _, event := range backlog {
+		w.result <- event
+	}
+
+	id := c.nextID
+	c.nextID++
+	c.watchers[id] = w
+	w.stop = func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if _, ok := c.watchers[id]; ok {
+			delete(c.watchers, id)
+			close(w.result)
+		}
+	}
+	return w, nil
+}
+
+// cacheWatcher 是 watchCache 的一个监听者。
+type cacheWatcher struct {
+	// result 按 Revision 递增的顺序传递事件，监听结束时被关闭
+	result chan registry.Event
+	// after 是监听开始的版本，不大于它的事件不会被传递
+	after uint64
+	// expired 在监听者因为落后太多被结束时设置，只能在 result 被关闭之后读取
+	expired bool
+	stop    func()
+}
+
+// expire 结束一个已经从 watchCache 中移除的监听者，调用时必须持有 watchCache 的锁。
+func (w *cacheWatcher) expire() {
+	w.expired = true
+	close(w.result)
+}
+
+// parseResourceVersion 解析 resourceVersion，空字符串表示 0。
+func parseResourceVersion(rv string) (uint64, error) {
+	if rv == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(rv, 10, 64)
+	if err != nil {
+		return 0, errors.NewBadRequest(fmt.Sprintf("invalid resource version %q", rv))
+	}
+	return v, nil
+}
@@ -0,0 +1,85 @@
+// file: pkg/apiserver/watchcache_test.go
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+func newTestWatchCache(latest uint64) *watchCache {
+	return &watchCache{cancel: func() {}, oldest: latest, latest: latest, watchers: make(map[int]*cacheWatcher)}
+}
+
+// revisions 读出监听者中已经积压的所有事件的版本
+func revisions(w *cacheWatcher) []uint64 {
+	var revs []uint64
+	for {
+		select {
+		case event, ok := <-w.result:
+			if !ok {
+				return revs
+			}
+			revs = append(revs, event.Revision)
+		default:
+			return revs
+		}
+	}
+}
+
+func TestWatchCache(t *testing.T) {
+	c := newTestWatchCache(10)
+	// 订阅之前的事件被忽略
+	c.add(registry.Event{Revision: 10})
+	for rev := uint64(11); rev <= 10+watchCacheSize+5; rev++ {
+		c.add(registry.Event{Revision: rev})
+	}
+	if c.oldest != 15 || len(c.events) != watchCacheSize {
+		t.Fatalf("oldest = %d with %d events, want 15 with %d", c.oldest, len(c.events), watchCacheSize)
+	}
+
+	if _, err := c.watch(14); !errors.IsResourceExpired(err) {
+		t.Errorf("watch from an evicted version returned %v, want Expired", err)
+	}
+	w, err := c.watch(c.latest - 2)
+	if err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	if got := revisions(w); len(got) != 2 || got[0] != c.latest-1 || got[1] != c.latest {
+		t.Errorf("replayed revisions = %v, want the last two", got)
+	}
+	// 从当前或未来的版本开始监听
+	future, err := c.watch(c.latest + 1)
+	if err != nil {
+		t.Fatalf("watch from a future version failed: %v", err)
+	}
+	c.add(registry.Event{Revision: c.latest + 1})
+	c.add(registry.Event{Revision: c.latest + 1})
+	if got := revisions(w); len(got) != 2 {
+		t.Errorf("watcher got %v, want two new events", got)
+	}
+	if got := revisions(future); len(got) != 1 {
+		t.Errorf("watcher from a future version got %v, want one event", got)
+	}
+
+	// 丢失的事件使所有监听者过期
+	c.add(registry.Event{Revision: c.latest + 2})
+	if _, ok := <-w.result; ok || !w.expired {
+		t.Error("watcher was not expired after a gap in the event stream")
+	}
+	if _, err := c.watch(c.latest - 2); !errors.IsResourceExpired(err) {
+		t.Errorf("watch from before the gap returned %v, want Expired", err)
+	}
+	w.stop()
+
+	// 积压太多事件的监听者被结束
+	slow, _ := c.watch(0)
+	for i := 0; i <= watcherBufferSize; i++ {
+		c.add(registry.Event{Revision: c.latest + 1})
+	}
+	if len(revisions(slow)) != watcherBufferSize || !slow.expired {
+		t.Error("slow watcher was not expired")
+	}
+}
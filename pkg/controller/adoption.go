@@ -0,0 +1,153 @@
+// file: pkg/controller/adoption.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"k8s.io/klog/v2"
+)
+
+// 控制器写在平台服务上的标签。ECSM 的标签是 "key=value" 形式的字符串。
+const (
+	// LabelOwnerUID 记录了拥有该平台服务的 ECSMService 的 UID
+	LabelOwnerUID = "ecsm.sh/owner-uid"
+	// LabelOwnerName 记录了拥有该平台服务的 ECSMService 的 "<namespace>.<name>"，便于人工排查
+	LabelOwnerName = "ecsm.sh/owner"
+	// LabelTemplateHash 记录了该平台服务所对应的模板修订版本
+	LabelTemplateHash = "ecsm.sh/template-hash"
+
+	// legacyTemplateHash 用于被认领、但无法确定其模板修订版本的平台服务，
+	// 它们会被当作旧修订版本，在滚动更新中逐步替换掉。
+	legacyTemplateHash = "legacy"
+)
+
+// parseLabels 把 ECSM 的 "key=value" 标签列表解析成 map，没有 "=" 的标签值为空。
+func parseLabels(labels []string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for _, l := range labels {
+		k, v, _ := strings.Cut(l, "=")
+		result[k] = v
+	}
+	return result
+}
+
+// formatLabels 把标签 map 转换成按 key 排序的 "key=value" 列表。
+func formatLabels(labels map[string]string) []string {
+	result := make([]string, 0, len(labels))
+	for k, v := range labels {
+		result = append(result, k+"="+v)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ownerLabels 返回一个修订版本的平台服务应该带有的控制器标签。
+func ownerLabels(service *ecsmv1.ECSMService, hash string) map[string]string {
+	return map[string]string{
+		LabelOwnerUID:     string(service.UID),
+		LabelOwnerName:    service.Namespace + "." + service.Name,
+		LabelTemplateHash: hash,
+	}
+}
+
+// rowLabels 返回一个平台服务上的全部标签。
+func rowLabels(row clientset.ProvisionListRow) map[string]string {
+	return parseLabels(append(append([]string(nil), row.DefaultLabels...), row.Labels...))
+}
+
+// matchesSelector 判断标签是否满足 ECSMService 的 selector。空的 selector 不匹配任何服务。
+func matchesSelector(labels map[string]string, selector map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for k, v := range selector {
+		if actual, ok := labels[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+// claimPlatformService 判断一个平台服务与 ECSMService 的归属关系。
+// 返回值 owned 表示它已经属于该服务，orphan 表示它不属于任何服务、但可以被该服务认领。
+func claimPlatformService(service *ecsmv1.ECSMService, row clientset.ProvisionListRow) (owned, orphan bool) {
+	labels := rowLabels(row)
+	if uid, ok := labels[LabelOwnerUID]; ok && uid != "" {
+		return uid == string(service.UID), false
+	}
+	if _, ok := parsePlatformServiceName(service, row.Name); ok {
+		return false, true
+	}
+	if row.Name == service.Name {
+		return false, true
+	}
+	return false, matchesSelector(labels, service.Spec.Selector)
+}
+
+// revisionHash 返回一个已归属的平台服务的模板哈希，优先使用标签，其次使用名称。
+func revisionHash(service *ecsmv1.ECSMService, row clientset.ProvisionListRow) string {
+	if hash := rowLabels(row)[LabelTemplateHash]; hash != "" {
+		return hash
+	}
+	if hash, ok := parsePlatformServiceName(service, row.Name); ok {
+		return hash
+	}
+	return legacyTemplateHash
+}
+
+// adoptionHash 决定一个被认领的平台服务应该归入哪个修订版本。
+// 按命名规则创建的服务沿用名称中的哈希；其他服务如果运行着模板中的镜像，
+// 则直接归入当前修订版本，避免重复创建；否则作为旧修订版本等待被替换。
+func adoptionHash(service *ecsmv1.ECSMService, row clientset.ProvisionListRow) string {
+	if hash, ok := parsePlatformServiceName(service, row.Name); ok {
+		return hash
+	}
+	if rowRunsTemplateImage(row, &service.Spec.Template) {
+		return computeTemplateHash(&service.Spec.Template)
+	}
+	return legacyTemplateHash
+}
+
+// adoptPlatformService 给一个孤儿平台服务打上属主标签，使其归属于该 ECSMService。
+func (c *ECSMServiceController) adoptPlatformService(ctx context.Context, service *ecsmv1.ECSMService, row clientset.ProvisionListRow) (string, error) {
+	hash := adoptionHash(service, row)
+	if c.isDryRun(service) {
+		c.planAction(service, "adopt platform service %s as revision %s", row.Name, hash)
+		return hash, nil
+	}
+
+	current, err := c.clientFor(service).Services().Get(ctx, row.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get platform service %s: %w", row.Name, err)
+	}
+
+	// 认领前再检查一次，避免覆盖在此期间被其他 ECSMService 认领的服务
+	if uid := parseLabels(current.Labels)[LabelOwnerUID]; uid != "" && uid != string(service.UID) {
+		return "", fmt.Errorf("platform service %s has been claimed by another owner %s", row.Name, uid)
+	}
+
+	labels := parseLabels(current.Labels)
+	for k, v := range ownerLabels(service, hash) {
+		labels[k] = v
+	}
+	// 以认领时的副本数为基准，之后的带外修改才会被视为漂移
+	labels[LabelDesiredReplicas] = strconv.Itoa(current.Factor)
+
+	req := current.UpdateRequest()
+	req.Labels = formatLabels(labels)
+
+	if _, err := c.clientFor(service).Services().Update(ctx, req.ID, req); err != nil {
+		return "", fmt.Errorf("failed to adopt platform service %s: %w", row.Name, err)
+	}
+
+	klog.Infof("Service %s/%s: adopted platform service %s as revision %s", service.Namespace, service.Name, row.Name, hash)
+	c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonAdopted, "Adopted platform service %s as revision %s", row.Name, hash)
+	return hash, nil
+}
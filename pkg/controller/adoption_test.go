@@ -0,0 +1,66 @@
+// file: pkg/controller/adoption_test.go
+
+package controller
+
+import (
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClaimPlatformService(t *testing.T) {
+	svc := &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "uid-1"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			Selector: map[string]string{"app": "web", "tier": "front"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		row        clientset.ProvisionListRow
+		wantOwned  bool
+		wantOrphan bool
+	}{
+		{"owned by uid label", clientset.ProvisionListRow{Name: "anything", Labels: []string{LabelOwnerUID + "=uid-1"}}, true, false},
+		{"owned by another service", clientset.ProvisionListRow{Name: "web-abc", Labels: []string{LabelOwnerUID + "=uid-2"}}, false, false},
+		{"orphan with revision name", clientset.ProvisionListRow{Name: "web-abc"}, false, true},
+		{"orphan with same name", clientset.ProvisionListRow{Name: "web"}, false, true},
+		{"orphan matching selector", clientset.ProvisionListRow{Name: "legacy-web", DefaultLabels: []string{"app=web", "tier=front"}}, false, true},
+		{"partial selector match", clientset.ProvisionListRow{Name: "legacy-web", Labels: []string{"app=web"}}, false, false},
+		{"other service with shared prefix", clientset.ProvisionListRow{Name: "web-foo-abc"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owned, orphan := claimPlatformService(svc, tt.row)
+			if owned != tt.wantOwned || orphan != tt.wantOrphan {
+				t.Errorf("claimPlatformService() = (%v, %v), want (%v, %v)", owned, orphan, tt.wantOwned, tt.wantOrphan)
+			}
+		})
+	}
+}
+
+func TestAdoptionHash(t *testing.T) {
+	svc := &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			Template: ecsmv1.ContainerTemplateSpec{Image: "nginx@1.2#linux"},
+		},
+	}
+	current := computeTemplateHash(&svc.Spec.Template)
+
+	if got := adoptionHash(svc, clientset.ProvisionListRow{Name: "web-xyz"}); got != "xyz" {
+		t.Errorf("adoptionHash() for revision name = %q, want %q", got, "xyz")
+	}
+	matching := clientset.ProvisionListRow{Name: "web", ImageList: []clientset.ImageListEntry{{Name: "nginx", Tag: "1.2"}}}
+	if got := adoptionHash(svc, matching); got != current {
+		t.Errorf("adoptionHash() for matching image = %q, want current revision %q", got, current)
+	}
+	stale := clientset.ProvisionListRow{Name: "web", ImageList: []clientset.ImageListEntry{{Name: "nginx", Tag: "1.1"}}}
+	if got := adoptionHash(svc, stale); got != legacyTemplateHash {
+		t.Errorf("adoptionHash() for stale image = %q, want %q", got, legacyTemplateHash)
+	}
+}
@@ -0,0 +1,445 @@
+// file: pkg/controller/autoscaler_controller.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultAutoscalerSyncPeriod 是 AutoscalerController 默认的同步周期，与 Kubernetes HPA 一致
+	DefaultAutoscalerSyncPeriod = 15 * time.Second
+
+	// defaultScaleDownStabilizationWindow 是缩容默认的稳定窗口，扩容默认没有稳定窗口
+	defaultScaleDownStabilizationWindow = 5 * time.Minute
+
+	// autoscalerTolerance 是使用率与目标值之比偏离 1.0 的容忍范围，在此范围内不会调整副本数
+	autoscalerTolerance = 0.1
+
+	// autoscalerSyncTimeout 是一次全量同步的最长时间
+	autoscalerSyncTimeout = time.Minute
+
+	// autoscalerControllerName 是 AutoscalerController 在指标中的名称
+	autoscalerControllerName = "autoscaler"
+)
+
+// 自动扩缩容相关的事件和状况原因
+const (
+	ReasonSuccessfulRescale = "SuccessfulRescale"
+	ReasonFailedRescale     = "FailedRescale"
+
+	autoscalerReasonSucceededGetScale   = "SucceededGetScale"
+	autoscalerReasonFailedGetScale      = "FailedGetScale"
+	autoscalerReasonInvalidSpec         = "InvalidSpec"
+	autoscalerReasonValidMetricFound    = "ValidMetricFound"
+	autoscalerReasonFailedGetMetrics    = "FailedGetResourceMetric"
+	autoscalerReasonTooFewReplicas      = "TooFewReplicas"
+	autoscalerReasonTooManyReplicas     = "TooManyReplicas"
+	autoscalerReasonDesiredWithinRange  = "DesiredWithinRange"
+	autoscalerReasonUnsupportedStrategy = "UnsupportedStrategy"
+)
+
+// timestampedRecommendation 是某一时刻计算出的推荐副本数
+type timestampedRecommendation struct {
+	replicas  int32
+	timestamp time.Time
+}
+
+// AutoscalerController 周期性地根据容器的资源使用率，调整 ECSMServiceAutoscaler 所指向的 ECSMService 的副本数。
+// 它只修改 Registry 中 ECSMService 的 spec.deploymentStrategy.replicas，
+// 真正的扩缩容仍然由 ECSMServiceController 完成。
+type AutoscalerController struct {
+	clusters *ClusterClients
+	registry registry.Interface
+	recorder record.EventRecorder
+
+	syncPeriod time.Duration
+	clock      func() time.Time
+
+	// recommendations 以 "namespace/name" 为索引，记录了每个自动扩缩容对象在稳定窗口内的推荐副本数
+	recommendations map[string][]timestampedRecommendation
+	lock            sync.Mutex
+}
+
+// NewAutoscalerController 创建一个新的 AutoscalerController。
+func NewAutoscalerController(
+	clusters *ClusterClients,
+	reg registry.Interface,
+	recorder record.EventRecorder,
+	syncPeriod time.Duration,
+) *AutoscalerController {
+	return &AutoscalerController{
+		clusters:        clusters,
+		registry:        reg,
+		recorder:        recorder,
+		syncPeriod:      syncPeriod,
+		clock:           time.Now,
+		recommendations: make(map[string][]timestampedRecommendation),
+	}
+}
+
+// Run 启动周期性同步，直到 stopCh 被关闭。
+func (c *AutoscalerController) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting autoscaler controller")
+	defer klog.Info("Shutting down autoscaler controller")
+
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), autoscalerSyncTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := c.syncAll(ctx)
+		result := reconcileResult(ctx, err)
+		reconcileTotal.WithLabelValues(autoscalerControllerName, result).Inc()
+		reconcileDuration.WithLabelValues(autoscalerControllerName).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("failed to sync autoscalers: %w", err))
+		}
+	}, c.syncPeriod, stopCh)
+}
+
+// syncAll 同步所有的 ECSMServiceAutoscaler。
+func (c *AutoscalerController) syncAll(ctx context.Context) error {
+	autoscalers, _, err := c.registry.ListAutoscalers(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMServiceAutoscalers: %w", err)
+	}
+
+	seen := make(map[string]bool, len(autoscalers.Items))
+	var errs []error
+	for i := range autoscalers.Items {
+		as := &autoscalers.Items[i]
+		key := as.Namespace + "/" + as.Name
+		seen[key] = true
+		if err := c.syncAutoscaler(ctx, key, as); err != nil {
+			errs = append(errs, fmt.Errorf("autoscaler %s: %w", key, err))
+		}
+	}
+	c.forgetRecommendations(seen)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// syncAutoscaler 为一个自动扩缩容对象计算期望副本数，必要时修改目标服务，并更新自己的状态。
+func (c *AutoscalerController) syncAutoscaler(ctx context.Context, key string, as *ecsmv1.ECSMServiceAutoscaler) error {
+	newStatus := as.Status.DeepCopy()
+	newStatus.ObservedGeneration = as.Generation
+
+	syncErr := c.computeAndScale(ctx, key, as, newStatus)
+
+	if reflect.DeepEqual(&as.Status, newStatus) {
+		return syncErr
+	}
+	toUpdate := as.DeepCopy()
+	toUpdate.Status = *newStatus
+	if _, err := c.registry.UpdateAutoscalerStatus(ctx, toUpdate); err != nil {
+		return fmt.Errorf("failed to update ECSMServiceAutoscaler status: %w", err)
+	}
+	return syncErr
+}
+
+// computeAndScale 完成一次自动扩缩容的计算，结果写入 status。
+// 只有需要在下个周期重试的错误才会被返回，spec 不合法等问题只体现在状况中。
+func (c *AutoscalerController) computeAndScale(ctx context.Context, key string, as *ecsmv1.ECSMServiceAutoscaler, status *ecsmv1.ECSMServiceAutoscalerStatus) error {
+	if err := validateAutoscaler(as); err != nil {
+		setAutoscalerCondition(status, ecsmv1.AutoscalerAbleToScale, metav1.ConditionFalse, autoscalerReasonInvalidSpec, err.Error())
+		return nil
+	}
+
+	service, err := c.registry.GetService(ctx, as.Namespace, as.Spec.ScaleTargetRef)
+	if err != nil {
+		msg := fmt.Sprintf("the autoscaler was unable to get the target service: %v", err)
+		setAutoscalerCondition(status, ecsmv1.AutoscalerAbleToScale, metav1.ConditionFalse, autoscalerReasonFailedGetScale, msg)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if service.Spec.DeploymentStrategy.Type != ecsmv1.DeploymentStrategyTypeDynamic {
+		msg := fmt.Sprintf("only services with the %s deployment strategy can be autoscaled", ecsmv1.DeploymentStrategyTypeDynamic)
+		setAutoscalerCondition(status, ecsmv1.AutoscalerAbleToScale, metav1.ConditionFalse, autoscalerReasonUnsupportedStrategy, msg)
+		return nil
+	}
+	ecsmClient, err := c.clusters.ForService(service)
+	if err != nil {
+		msg := fmt.Sprintf("the autoscaler was unable to reach the cluster of the target service: %v", err)
+		setAutoscalerCondition(status, ecsmv1.AutoscalerAbleToScale, metav1.ConditionFalse, autoscalerReasonFailedGetScale, msg)
+		return nil
+	}
+	setAutoscalerCondition(status, ecsmv1.AutoscalerAbleToScale, metav1.ConditionTrue, autoscalerReasonSucceededGetScale, "the autoscaler was able to get the target's current scale")
+
+	currentReplicas := desiredReplicas(service)
+	status.CurrentReplicas = currentReplicas
+
+	containers, err := listOwnedContainers(ctx, ecsmClient, service)
+	if err != nil {
+		setAutoscalerCondition(status, ecsmv1.AutoscalerScalingActive, metav1.ConditionFalse, autoscalerReasonFailedGetMetrics, err.Error())
+		return err
+	}
+	var running []clientset.ContainerInfo
+	for _, co := range containers {
+		if isContainerReady(co) {
+			running = append(running, co)
+		}
+	}
+	metricStatuses, proposed, err := computeReplicasForMetrics(as.Spec.Metrics, running, currentReplicas)
+	status.CurrentMetrics = metricStatuses
+	if err != nil {
+		setAutoscalerCondition(status, ecsmv1.AutoscalerScalingActive, metav1.ConditionFalse, autoscalerReasonFailedGetMetrics, err.Error())
+		status.DesiredReplicas = currentReplicas
+		return nil
+	}
+	setAutoscalerCondition(status, ecsmv1.AutoscalerScalingActive, metav1.ConditionTrue, autoscalerReasonValidMetricFound, "the autoscaler was able to compute the replica count from resource utilization")
+
+	minReplicas := autoscalerMinReplicas(as)
+	limited, reason, msg := clampReplicas(proposed, minReplicas, as.Spec.MaxReplicas)
+	if reason == autoscalerReasonDesiredWithinRange {
+		setAutoscalerCondition(status, ecsmv1.AutoscalerScalingLimited, metav1.ConditionFalse, reason, msg)
+	} else {
+		setAutoscalerCondition(status, ecsmv1.AutoscalerScalingLimited, metav1.ConditionTrue, reason, msg)
+	}
+
+	upWindow, downWindow := stabilizationWindows(as.Spec.Behavior)
+	desired := c.stabilize(key, limited, currentReplicas, upWindow, downWindow)
+	status.DesiredReplicas = desired
+
+	if desired == currentReplicas {
+		return nil
+	}
+
+	// 通过 scale 子资源只修改副本数，不会与用户同时对 spec 其他字段的修改冲突
+	scale := &ecsmv1.ECSMServiceScale{
+		ObjectMeta: metav1.ObjectMeta{Name: service.Name, Namespace: service.Namespace},
+		Spec:       ecsmv1.ScaleSpec{Replicas: desired},
+	}
+	if _, err := c.registry.UpdateServiceScale(ctx, scale); err != nil {
+		c.recorder.Eventf(as, ecsmv1.EventTypeWarning, ReasonFailedRescale, "Failed to scale service %s to %d: %v", service.Name, desired, err)
+		return fmt.Errorf("failed to scale ECSMService %s: %w", service.Name, err)
+	}
+	klog.Infof("Autoscaler %s scaled ECSMService %s from %d to %d replicas", key, service.Name, currentReplicas, desired)
+	c.recorder.Eventf(as, ecsmv1.EventTypeNormal, ReasonSuccessfulRescale, "New size: %d; reason: resource utilization is %s", desired, describeMetrics(metricStatuses))
+	now := metav1.NewTime(c.clock())
+	status.LastScaleTime = &now
+	status.CurrentReplicas = desired
+	return nil
+}
+
+// validateAutoscaler 检查自动扩缩容对象的 spec 是否合法。
+func validateAutoscaler(as *ecsmv1.ECSMServiceAutoscaler) error {
+	spec := as.Spec
+	if spec.ScaleTargetRef == "" {
+		return fmt.Errorf("scaleTargetRef must be specified")
+	}
+	minReplicas := autoscalerMinReplicas(as)
+	if minReplicas < 1 {
+		return fmt.Errorf("minReplicas must be at least 1, got %d", minReplicas)
+	}
+	if spec.MaxReplicas < minReplicas {
+		return fmt.Errorf("maxReplicas (%d) must not be less than minReplicas (%d)", spec.MaxReplicas, minReplicas)
+	}
+	if len(spec.Metrics) == 0 {
+		return fmt.Errorf("at least one metric must be specified")
+	}
+	for _, m := range spec.Metrics {
+		if m.Resource != ecsmv1.AutoscalerResourceCPU && m.Resource != ecsmv1.AutoscalerResourceMemory {
+			return fmt.Errorf("unsupported metric resource %q", m.Resource)
+		}
+		if m.TargetAverageUtilization < 1 {
+			return fmt.Errorf("targetAverageUtilization of %s must be at least 1, got %d", m.Resource, m.TargetAverageUtilization)
+		}
+	}
+	return nil
+}
+
+func autoscalerMinReplicas(as *ecsmv1.ECSMServiceAutoscaler) int32 {
+	if as.Spec.MinReplicas != nil {
+		return *as.Spec.MinReplicas
+	}
+	return 1
+}
+
+// computeReplicasForMetrics 计算每项指标的当前使用率，返回所有指标推荐的副本数中最大的一个。
+func computeReplicasForMetrics(metrics []ecsmv1.AutoscalerMetric, containers []clientset.ContainerInfo, currentReplicas int32) ([]ecsmv1.AutoscalerMetricStatus, int32, error) {
+	if len(containers) == 0 {
+		return nil, 0, fmt.Errorf("no running containers to get resource metrics from")
+	}
+
+	var statuses []ecsmv1.AutoscalerMetricStatus
+	var proposed int32
+	var errs []error
+	for _, m := range metrics {
+		utilization, ok := averageUtilization(m.Resource, containers)
+		if !ok {
+			errs = append(errs, fmt.Errorf("no %s metrics available for the running containers", m.Resource))
+			continue
+		}
+		statuses = append(statuses, ecsmv1.AutoscalerMetricStatus{
+			Resource:                  m.Resource,
+			CurrentAverageUtilization: int32(math.Round(utilization)),
+		})
+		if replicas := replicasForUtilization(currentReplicas, utilization, m.TargetAverageUtilization); replicas > proposed {
+			proposed = replicas
+		}
+	}
+	if len(statuses) == 0 {
+		return nil, 0, fmt.Errorf("%v", errs)
+	}
+	return statuses, proposed, nil
+}
+
+// averageUtilization 返回容器某项资源的平均使用率 (百分比)。
+// CPU 使用率直接取平台上报的值；内存使用率相对于容器的内存限制计算，没有限制的容器不参与计算。
+func averageUtilization(resource ecsmv1.AutoscalerResource, containers []clientset.ContainerInfo) (float64, bool) {
+	var sum float64
+	var count int
+	for _, co := range containers {
+		switch resource {
+		case ecsmv1.AutoscalerResourceCPU:
+			sum += co.CPUUsage.Total
+			count++
+		case ecsmv1.AutoscalerResourceMemory:
+			if co.MemoryLimit <= 0 {
+				continue
+			}
+			sum += float64(co.MemoryUsage) * 100 / float64(co.MemoryLimit)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// replicasForUtilization 按 当前副本数 * 当前使用率 / 目标使用率 计算推荐副本数 (向上取整)。
+// 比值在容忍范围内时保持当前副本数，避免指标的小幅波动引起扩缩容。
+func replicasForUtilization(currentReplicas int32, utilization float64, target int32) int32 {
+	ratio := utilization / float64(target)
+	if math.Abs(ratio-1.0) <= autoscalerTolerance {
+		return currentReplicas
+	}
+	return int32(math.Ceil(ratio * float64(currentReplicas)))
+}
+
+// clampReplicas 把推荐副本数限制在 [minReplicas, maxReplicas] 之间，并返回 ScalingLimited 状况的原因和消息。
+func clampReplicas(replicas, minReplicas, maxReplicas int32) (int32, string, string) {
+	switch {
+	case replicas < minReplicas:
+		return minReplicas, autoscalerReasonTooFewReplicas, "the desired replica count is less than the minimum replica count"
+	case replicas > maxReplicas:
+		return maxReplicas, autoscalerReasonTooManyReplicas, "the desired replica count is more than the maximum replica count"
+	default:
+		return replicas, autoscalerReasonDesiredWithinRange, "the desired count is within the acceptable range"
+	}
+}
+
+// stabilizationWindows 返回扩容和缩容的稳定窗口。
+func stabilizationWindows(behavior ecsmv1.AutoscalerBehavior) (time.Duration, time.Duration) {
+	var up time.Duration
+	down := defaultScaleDownStabilizationWindow
+	if behavior.ScaleUp != nil && behavior.ScaleUp.StabilizationWindowSeconds != nil {
+		up = time.Duration(*behavior.ScaleUp.StabilizationWindowSeconds) * time.Second
+	}
+	if behavior.ScaleDown != nil && behavior.ScaleDown.StabilizationWindowSeconds != nil {
+		down = time.Duration(*behavior.ScaleDown.StabilizationWindowSeconds) * time.Second
+	}
+	return up, down
+}
+
+// stabilize 记录本次的推荐副本数，并根据稳定窗口内的历史推荐计算最终的期望副本数。
+func (c *AutoscalerController) stabilize(key string, recommendation, currentReplicas int32, upWindow, downWindow time.Duration) int32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.clock()
+	longest := max(upWindow, downWindow)
+	history := []timestampedRecommendation{{replicas: recommendation, timestamp: now}}
+	for _, rec := range c.recommendations[key] {
+		if now.Sub(rec.timestamp) <= longest {
+			history = append(history, rec)
+		}
+	}
+	c.recommendations[key] = history
+
+	return stabilizeRecommendation(history, now, currentReplicas, upWindow, downWindow)
+}
+
+// stabilizeRecommendation 实现了与 Kubernetes HPA 相同的稳定算法：
+// 扩容时最多扩到扩容窗口内推荐值中最小的一个，缩容时最多缩到缩容窗口内推荐值中最大的一个。
+// 这样只有在整个窗口内都持续需要扩容 (或缩容) 时，副本数才会改变。
+func stabilizeRecommendation(history []timestampedRecommendation, now time.Time, currentReplicas int32, upWindow, downWindow time.Duration) int32 {
+	upRecommendation := int32(math.MaxInt32)
+	downRecommendation := int32(math.MinInt32)
+	for _, rec := range history {
+		age := now.Sub(rec.timestamp)
+		if age <= upWindow {
+			upRecommendation = min(upRecommendation, rec.replicas)
+		}
+		if age <= downWindow {
+			downRecommendation = max(downRecommendation, rec.replicas)
+		}
+	}
+
+	result := currentReplicas
+	if upRecommendation != math.MaxInt32 && result < upRecommendation {
+		result = upRecommendation
+	}
+	if downRecommendation != math.MinInt32 && result > downRecommendation {
+		result = downRecommendation
+	}
+	return result
+}
+
+// forgetRecommendations 丢弃已经被删除的自动扩缩容对象的推荐历史。
+func (c *AutoscalerController) forgetRecommendations(existing map[string]bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key := range c.recommendations {
+		if !existing[key] {
+			delete(c.recommendations, key)
+		}
+	}
+}
+
+func setAutoscalerCondition(status *ecsmv1.ECSMServiceAutoscalerStatus, condType string, condStatus metav1.ConditionStatus, reason, message string) {
+	ecsmmeta.SetCondition(&status.Conditions, metav1.Condition{
+		Type:    condType,
+		Status:  condStatus,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// describeMetrics 把指标的观测值格式化为事件消息，例如 "cpu 85%, memory 40%"。
+func describeMetrics(metrics []ecsmv1.AutoscalerMetricStatus) string {
+	var s string
+	for i, m := range metrics {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%s %d%%", m.Resource, m.CurrentAverageUtilization)
+	}
+	return s
+}
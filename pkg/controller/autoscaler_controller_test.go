@@ -0,0 +1,86 @@
+// file: pkg/controller/autoscaler_controller_test.go
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+func TestComputeReplicasForMetrics(t *testing.T) {
+	containers := []clientset.ContainerInfo{
+		{CPUUsage: clientset.CPUUsage{Total: 90}, MemoryUsage: 30, MemoryLimit: 100},
+		{CPUUsage: clientset.CPUUsage{Total: 70}, MemoryUsage: 50, MemoryLimit: 100},
+	}
+	metrics := []ecsmv1.AutoscalerMetric{
+		{Resource: ecsmv1.AutoscalerResourceCPU, TargetAverageUtilization: 40},
+		{Resource: ecsmv1.AutoscalerResourceMemory, TargetAverageUtilization: 80},
+	}
+
+	statuses, replicas, err := computeReplicasForMetrics(metrics, containers, 2)
+	if err != nil {
+		t.Fatalf("computeReplicasForMetrics() error = %v", err)
+	}
+	// CPU: 2 * 80 / 40 = 4，内存: 2 * 40 / 80 = 1，取较大者
+	if replicas != 4 {
+		t.Errorf("replicas = %d, want 4", replicas)
+	}
+	if len(statuses) != 2 || statuses[0].CurrentAverageUtilization != 80 || statuses[1].CurrentAverageUtilization != 40 {
+		t.Errorf("statuses = %+v, want cpu 80%% and memory 40%%", statuses)
+	}
+
+	if _, _, err := computeReplicasForMetrics(metrics, nil, 2); err == nil {
+		t.Error("expected an error without running containers")
+	}
+}
+
+func TestReplicasForUtilizationTolerance(t *testing.T) {
+	if got := replicasForUtilization(3, 54, 50); got != 3 {
+		t.Errorf("replicasForUtilization() within tolerance = %d, want 3", got)
+	}
+	if got := replicasForUtilization(3, 10, 50); got != 1 {
+		t.Errorf("replicasForUtilization() = %d, want 1", got)
+	}
+}
+
+func TestStabilizeRecommendation(t *testing.T) {
+	now := time.Unix(1000, 0)
+	history := []timestampedRecommendation{
+		{replicas: 2, timestamp: now},
+		{replicas: 5, timestamp: now.Add(-2 * time.Minute)},
+		{replicas: 3, timestamp: now.Add(-10 * time.Minute)},
+	}
+
+	// 缩容时取 5 分钟窗口内最大的推荐值
+	if got := stabilizeRecommendation(history, now, 6, 0, 5*time.Minute); got != 5 {
+		t.Errorf("scale down = %d, want 5", got)
+	}
+	// 扩容时取扩容窗口内最小的推荐值
+	if got := stabilizeRecommendation(history, now, 1, 3*time.Minute, 5*time.Minute); got != 2 {
+		t.Errorf("scale up = %d, want 2", got)
+	}
+	// 窗口内的推荐值涵盖当前副本数时保持不变
+	if got := stabilizeRecommendation(history, now, 4, 3*time.Minute, 5*time.Minute); got != 4 {
+		t.Errorf("stable = %d, want 4", got)
+	}
+}
+
+func TestValidateAutoscaler(t *testing.T) {
+	minReplicas := int32(3)
+	as := &ecsmv1.ECSMServiceAutoscaler{Spec: ecsmv1.ECSMServiceAutoscalerSpec{
+		ScaleTargetRef: "web",
+		MinReplicas:    &minReplicas,
+		MaxReplicas:    2,
+		Metrics:        []ecsmv1.AutoscalerMetric{{Resource: ecsmv1.AutoscalerResourceCPU, TargetAverageUtilization: 50}},
+	}}
+	if err := validateAutoscaler(as); err == nil {
+		t.Error("expected an error when maxReplicas < minReplicas")
+	}
+	as.Spec.MaxReplicas = 5
+	if err := validateAutoscaler(as); err != nil {
+		t.Errorf("validateAutoscaler() error = %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+// file: pkg/controller/bluegreen.go
+
+package controller
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/klog/v2"
+)
+
+// rolloutBlueGreen 推进一步蓝绿发布：在旧修订版本 (蓝) 旁边创建完整副本数的新修订版本 (绿)，
+// 绿的实例全部就绪、并经过 promotionDelaySeconds 的验证之后，一次性删除所有旧修订版本。
+// 验证期间绿出现不可用的实例时会重新计时，旧修订版本在切换之前始终保持不变。
+func (c *ECSMServiceController) rolloutBlueGreen(ctx context.Context, service *ecsmv1.ECSMService, hash string, newRev *platformService, oldRevs []*platformService) (*platformService, error) {
+	desired := desiredReplicas(service)
+	state := rolloutState(service, hash)
+
+	newRev, scaled, err := c.scaleNewRevision(ctx, service, hash, newRev, desired)
+	if err != nil || scaled {
+		state.ReadyTime = nil
+		return newRev, err
+	}
+
+	if newRev.readyReplicas() < desired {
+		klog.V(2).Infof("Service %s/%s: blue-green waiting for %d/%d new replica(s) to become ready",
+			service.Namespace, service.Name, newRev.readyReplicas(), desired)
+		state.ReadyTime = nil
+		return newRev, nil
+	}
+
+	var delay *int32
+	if bg := service.Spec.UpgradeStrategy.BlueGreen; bg != nil {
+		delay = bg.PromotionDelaySeconds
+	}
+	if !pauseElapsed(state, delay) {
+		return newRev, nil
+	}
+
+	klog.Infof("Service %s/%s: promoting revision %s, deleting %d old revision(s)",
+		service.Namespace, service.Name, newRev.Row.Name, len(oldRevs))
+	if !c.isDryRun(service) {
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonPromoted,
+			"Promoted revision %s, deleting the previous revision(s)", hash)
+	}
+	_, err = c.scaleOldRevisionsTo(ctx, service, oldRevs, 0)
+	return newRev, err
+}
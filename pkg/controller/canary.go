@@ -0,0 +1,176 @@
+// file: pkg/controller/canary.go
+
+package controller
+
+import (
+	"context"
+	"math"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ReasonCanaryStep 表示 Canary 发布进入了下一步
+	ReasonCanaryStep = "CanaryStep"
+	// ReasonPromoted 表示蓝绿发布把服务切换到了新修订版本
+	ReasonPromoted = "Promoted"
+)
+
+// defaultCanarySteps 是 CanaryStrategy 没有指定步骤时使用的步骤
+var defaultCanarySteps = []ecsmv1.CanaryStep{{Weight: 20}, {Weight: 50}, {Weight: 100}}
+
+// rolloutCanary 推进一步 Canary 发布。每一步依次：
+//  1. 把新修订版本扩容到这一步的比例；
+//  2. 等待新实例全部就绪；
+//  3. 缩容旧修订版本，使总副本数回到期望值；
+//  4. 经过这一步的暂停时间之后进入下一步。
+//
+// 最后一步的比例为 100%，旧修订版本在这一步被全部删除。
+func (c *ECSMServiceController) rolloutCanary(ctx context.Context, service *ecsmv1.ECSMService, hash string, newRev *platformService, oldRevs []*platformService) (*platformService, error) {
+	steps, err := canarySteps(service.Spec.UpgradeStrategy.Canary)
+	if err != nil {
+		return newRev, err
+	}
+	desired := desiredReplicas(service)
+	state := rolloutState(service, hash)
+	if int(state.Step) >= len(steps) {
+		state.Step = int32(len(steps) - 1)
+	}
+	step := steps[state.Step]
+	target := canaryReplicas(desired, step.Weight)
+
+	// --- 1. 扩容新修订版本 ---
+	newRev, scaled, err := c.scaleNewRevision(ctx, service, hash, newRev, target)
+	if err != nil || scaled {
+		state.ReadyTime = nil
+		return newRev, err
+	}
+
+	// --- 2. 等待新实例就绪 ---
+	if newRev.readyReplicas() < target {
+		klog.V(2).Infof("Service %s/%s: canary step %d waiting for %d/%d new replica(s) to become ready",
+			service.Namespace, service.Name, state.Step, newRev.readyReplicas(), target)
+		state.ReadyTime = nil
+		return newRev, nil
+	}
+
+	// --- 3. 缩容旧修订版本 ---
+	changed, err := c.scaleOldRevisionsTo(ctx, service, oldRevs, desired-target)
+	if err != nil || changed {
+		return newRev, err
+	}
+
+	// --- 4. 暂停，然后进入下一步 ---
+	if !pauseElapsed(state, step.PauseSeconds) {
+		return newRev, nil
+	}
+	if int(state.Step) < len(steps)-1 {
+		state.Step++
+		state.ReadyTime = nil
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonCanaryStep,
+			"Canary step %d/%d: shifting %d%% of replicas to revision %s", state.Step+1, len(steps), steps[state.Step].Weight, hash)
+	}
+	return newRev, nil
+}
+
+// canarySteps 返回校验过的 Canary 步骤，最后一步的比例总是 100%。
+func canarySteps(strategy *ecsmv1.CanaryStrategy) ([]ecsmv1.CanaryStep, error) {
+	if strategy == nil || len(strategy.Steps) == 0 {
+		return defaultCanarySteps, nil
+	}
+	var last int32
+	for i, step := range strategy.Steps {
+		if step.Weight < 1 || step.Weight > 100 {
+			return nil, newSpecError("canary step %d: weight must be between 1 and 100, got %d", i, step.Weight)
+		}
+		if step.Weight <= last {
+			return nil, newSpecError("canary step %d: weight %d must be greater than the previous step's %d", i, step.Weight, last)
+		}
+		last = step.Weight
+	}
+	steps := strategy.Steps
+	if last < 100 {
+		steps = append(append([]ecsmv1.CanaryStep(nil), steps...), ecsmv1.CanaryStep{Weight: 100})
+	}
+	return steps, nil
+}
+
+// canaryReplicas 返回比例为 weight 时新修订版本的副本数，向上取整，且至少为 1。
+func canaryReplicas(desired, weight int32) int32 {
+	replicas := int32(math.Ceil(float64(desired) * float64(weight) / 100))
+	return max(min(replicas, desired), 1)
+}
+
+// rolloutState 返回服务当前的发布进度。发布的修订版本发生变化时，进度会被重置。
+func rolloutState(service *ecsmv1.ECSMService, hash string) *ecsmv1.RolloutStatus {
+	if service.Status.Rollout == nil || service.Status.Rollout.Revision != hash {
+		service.Status.Rollout = &ecsmv1.RolloutStatus{Revision: hash}
+	}
+	return service.Status.Rollout
+}
+
+// pauseElapsed 在新实例就绪之后经过了 seconds 秒时返回 true，第一次调用时开始计时。
+func pauseElapsed(state *ecsmv1.RolloutStatus, seconds *int32) bool {
+	if state.ReadyTime == nil {
+		now := metav1.Now()
+		state.ReadyTime = &now
+	}
+	if seconds == nil || *seconds <= 0 {
+		return true
+	}
+	return time.Since(state.ReadyTime.Time) >= time.Duration(*seconds)*time.Second
+}
+
+// scaleNewRevision 确保新修订版本至少有 target 个副本，必要时创建它。返回是否做了修改。
+func (c *ECSMServiceController) scaleNewRevision(ctx context.Context, service *ecsmv1.ECSMService, hash string, newRev *platformService, target int32) (*platformService, bool, error) {
+	var current int32
+	if newRev != nil {
+		current = newRev.replicas()
+	}
+	if newRev != nil && current >= target {
+		return newRev, false, nil
+	}
+	klog.Infof("Service %s/%s: scaling new revision %s from %d to %d",
+		service.Namespace, service.Name, platformServiceName(service, hash), current, target)
+	var err error
+	if newRev == nil {
+		newRev, err = c.createPlatformService(ctx, service, hash, target)
+	} else {
+		err = c.scalePlatformService(ctx, service, newRev, target)
+	}
+	if err != nil {
+		return newRev, false, err
+	}
+	if !c.isDryRun(service) {
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonRollingUpdate,
+			"Scaled up new revision %s from %d to %d", newRev.Row.Name, current, target)
+	}
+	return newRev, true, nil
+}
+
+// scaleOldRevisionsTo 缩容旧修订版本，使它们的副本总数不超过 remaining。返回是否做了修改。
+func (c *ECSMServiceController) scaleOldRevisionsTo(ctx context.Context, service *ecsmv1.ECSMService, oldRevs []*platformService, remaining int32) (bool, error) {
+	var total int32
+	for _, old := range oldRevs {
+		total += old.replicas()
+	}
+	excess := total - max(remaining, 0)
+	changed := false
+	// 从后往前缩容，尽量保留靠前的修订版本完整
+	for i := len(oldRevs) - 1; i >= 0 && excess > 0; i-- {
+		old := oldRevs[i]
+		if old.replicas() <= 0 {
+			continue
+		}
+		step := min(old.replicas(), excess)
+		if err := c.scaleDownOldRevision(ctx, service, old, old.replicas()-step); err != nil {
+			return changed, err
+		}
+		excess -= step
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,111 @@
+// file: pkg/controller/canary_test.go
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCanarySteps(t *testing.T) {
+	steps, err := canarySteps(&ecsmv1.CanaryStrategy{Steps: []ecsmv1.CanaryStep{{Weight: 10}, {Weight: 40}}})
+	if err != nil {
+		t.Fatalf("canarySteps() error = %v", err)
+	}
+	if len(steps) != 3 || steps[2].Weight != 100 {
+		t.Errorf("canarySteps() = %v, want an implicit 100%% step at the end", steps)
+	}
+
+	if _, err := canarySteps(&ecsmv1.CanaryStrategy{Steps: []ecsmv1.CanaryStep{{Weight: 50}, {Weight: 30}}}); !isSpecError(err) {
+		t.Errorf("decreasing weights: error = %v, want a spec error", err)
+	}
+}
+
+func TestCanaryReplicas(t *testing.T) {
+	tests := []struct {
+		desired, weight, want int32
+	}{
+		{10, 25, 3},
+		{4, 1, 1},
+		{4, 100, 4},
+	}
+	for _, tt := range tests {
+		if got := canaryReplicas(tt.desired, tt.weight); got != tt.want {
+			t.Errorf("canaryReplicas(%d, %d) = %d, want %d", tt.desired, tt.weight, got, tt.want)
+		}
+	}
+}
+
+// TestRolloutCanaryDryRun 在 dry-run 模式下走完一次两步的 Canary 发布，
+// dry-run 下的扩缩容只修改内存中的平台服务，不需要 ECSM 客户端。
+func TestRolloutCanaryDryRun(t *testing.T) {
+	replicas := int32(4)
+	service := &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "web",
+			Annotations: map[string]string{ecsmv1.DryRunAnnotation: "true"},
+		},
+		Spec: ecsmv1.ECSMServiceSpec{
+			Template:           ecsmv1.ContainerTemplateSpec{Image: "nginx@1.2#linux"},
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeDynamic, Replicas: &replicas},
+			UpgradeStrategy: ecsmv1.UpgradeStrategy{
+				Rollout: ecsmv1.RolloutTypeCanary,
+				Canary:  &ecsmv1.CanaryStrategy{Steps: []ecsmv1.CanaryStep{{Weight: 25}}},
+			},
+		},
+	}
+	hash := computeTemplateHash(&service.Spec.Template)
+	c := &ECSMServiceController{recorder: record.NewFakeRecorder(100), expectations: newControllerExpectations()}
+	ctx := context.Background()
+
+	running := func(n int) []clientset.ContainerInfo {
+		containers := make([]clientset.ContainerInfo, n)
+		for i := range containers {
+			containers[i].Status = "running"
+		}
+		return containers
+	}
+	old := &platformService{Row: clientset.ProvisionListRow{Name: "web-old", Factor: 4}, TemplateHash: "old", Containers: running(4)}
+
+	// 第一步：创建 25% 的新实例
+	newRev, err := c.rolloutCanary(ctx, service, hash, nil, []*platformService{old})
+	if err != nil || newRev == nil || newRev.replicas() != 1 {
+		t.Fatalf("step 0 scale up: newRev = %+v, err = %v", newRev, err)
+	}
+
+	// 新实例就绪后缩容旧修订版本，然后进入下一步
+	newRev.Containers = running(1)
+	if _, err := c.rolloutCanary(ctx, service, hash, newRev, []*platformService{old}); err != nil {
+		t.Fatal(err)
+	}
+	if old.replicas() != 3 {
+		t.Fatalf("old revision replicas = %d, want 3", old.replicas())
+	}
+	if _, err := c.rolloutCanary(ctx, service, hash, newRev, []*platformService{old}); err != nil {
+		t.Fatal(err)
+	}
+	if service.Status.Rollout == nil || service.Status.Rollout.Step != 1 {
+		t.Fatalf("rollout status = %+v, want step 1", service.Status.Rollout)
+	}
+
+	// 最后一步：新修订版本扩容到 100%，就绪后删除旧修订版本
+	if _, err := c.rolloutCanary(ctx, service, hash, newRev, []*platformService{old}); err != nil {
+		t.Fatal(err)
+	}
+	if newRev.replicas() != 4 {
+		t.Fatalf("new revision replicas = %d, want 4", newRev.replicas())
+	}
+	newRev.Containers = running(4)
+	if _, err := c.rolloutCanary(ctx, service, hash, newRev, []*platformService{old}); err != nil {
+		t.Fatal(err)
+	}
+	if old.replicas() != 0 {
+		t.Errorf("old revision replicas = %d, want it deleted", old.replicas())
+	}
+}
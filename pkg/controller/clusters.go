@@ -0,0 +1,115 @@
+// file: pkg/controller/clusters.go
+
+package controller
+
+import (
+	"sort"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+// DefaultClusterName 是通过 --host/--port 配置的 ECSM 集群的默认名称
+const DefaultClusterName = "default"
+
+// ClusterClients 以集群名称为索引，保存了 operator 管理的每个 ECSM 集群的客户端。
+// 没有指定集群的 ECSMService 由默认集群负责。
+type ClusterClients struct {
+	defaultCluster string
+	clients        map[string]clientset.Interface
+}
+
+// NewClusterClients 创建一个只包含默认集群的 ClusterClients。
+func NewClusterClients(defaultCluster string, defaultClient clientset.Interface) *ClusterClients {
+	return &ClusterClients{
+		defaultCluster: defaultCluster,
+		clients:        map[string]clientset.Interface{defaultCluster: defaultClient},
+	}
+}
+
+// Add 注册一个具名的集群。它必须在控制器启动之前调用。
+func (c *ClusterClients) Add(name string, client clientset.Interface) {
+	c.clients[name] = client
+}
+
+// DefaultCluster 返回默认集群的名称。
+func (c *ClusterClients) DefaultCluster() string {
+	return c.defaultCluster
+}
+
+// Default 返回默认集群的客户端。
+func (c *ClusterClients) Default() clientset.Interface {
+	return c.clients[c.defaultCluster]
+}
+
+// Names 返回所有集群的名称，按字母顺序排列。
+func (c *ClusterClients) Names() []string {
+	names := make([]string, 0, len(c.clients))
+	for name := range c.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get 返回名为 name 的集群的客户端，name 为空时返回默认集群的客户端。
+// 集群没有被配置时返回 specError，直到用户修改 spec 或 operator 的配置之前重试都不会成功。
+func (c *ClusterClients) Get(name string) (clientset.Interface, error) {
+	if name == "" {
+		name = c.defaultCluster
+	}
+	client, ok := c.clients[name]
+	if !ok {
+		return nil, newSpecError("unknown ECSM cluster %q, known clusters are %v", name, c.Names())
+	}
+	return client, nil
+}
+
+// ForService 返回 ECSMService 所在集群的客户端。
+// 服务被调谐过之后，以 status.cluster 中记录的集群为准。
+func (c *ClusterClients) ForService(service *ecsmv1.ECSMService) (clientset.Interface, error) {
+	if service.Status.Cluster != "" {
+		return c.Get(service.Status.Cluster)
+	}
+	return c.Get(desiredCluster(service))
+}
+
+// desiredCluster 返回 ECSMService 指定的集群名称，spec.cluster 优先于 ClusterAnnotation。
+// 两者都为空时返回空字符串，表示默认集群。
+func desiredCluster(service *ecsmv1.ECSMService) string {
+	if service.Spec.Cluster != "" {
+		return service.Spec.Cluster
+	}
+	return service.Annotations[ecsmv1.ClusterAnnotation]
+}
+
+// resolveCluster 确定负责 ECSMService 的集群，并把它记录到 status.cluster 中。
+// 平台服务只存在于最初的集群中，所以被调谐过的服务不能再更换集群；
+// 不过删除服务时总是清理最初的集群，即使 spec 中的集群已经被修改。
+func (c *ECSMServiceController) resolveCluster(service *ecsmv1.ECSMService) error {
+	current := service.Status.Cluster
+	if current != "" && service.DeletionTimestamp != nil {
+		_, err := c.clusters.Get(current)
+		return err
+	}
+
+	name := desiredCluster(service)
+	if name == "" {
+		name = c.clusters.DefaultCluster()
+	}
+	if _, err := c.clusters.Get(name); err != nil {
+		return err
+	}
+	if current != "" && current != name {
+		return newSpecError("service was deployed to ECSM cluster %q and cannot be moved to %q, delete and recreate it instead", current, name)
+	}
+	service.Status.Cluster = name
+	return nil
+}
+
+// clientFor 返回 ECSMService 所在集群的客户端。
+// reconcile 在调谐开始时已经通过 resolveCluster 校验了集群，所以这里不会失败。
+func (c *ECSMServiceController) clientFor(service *ecsmv1.ECSMService) clientset.Interface {
+	client, _ := c.clusters.ForService(service)
+	return client
+}
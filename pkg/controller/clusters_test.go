@@ -0,0 +1,91 @@
+// file: pkg/controller/clusters_test.go
+
+package controller
+
+import (
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolveCluster(t *testing.T) {
+	clusters := NewClusterClients(DefaultClusterName, &clientset.Clientset{})
+	clusters.Add("edge", &clientset.Clientset{})
+	c := &ECSMServiceController{clusters: clusters}
+
+	now := metav1.Now()
+	tests := []struct {
+		name        string
+		service     ecsmv1.ECSMService
+		wantCluster string
+		wantSpecErr bool
+	}{
+		{
+			name:        "default cluster",
+			wantCluster: DefaultClusterName,
+		},
+		{
+			name:        "spec.cluster",
+			service:     ecsmv1.ECSMService{Spec: ecsmv1.ECSMServiceSpec{Cluster: "edge"}},
+			wantCluster: "edge",
+		},
+		{
+			name: "annotation",
+			service: ecsmv1.ECSMService{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ecsmv1.ClusterAnnotation: "edge"}},
+			},
+			wantCluster: "edge",
+		},
+		{
+			name: "spec.cluster takes precedence over the annotation",
+			service: ecsmv1.ECSMService{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ecsmv1.ClusterAnnotation: "edge"}},
+				Spec:       ecsmv1.ECSMServiceSpec{Cluster: DefaultClusterName},
+			},
+			wantCluster: DefaultClusterName,
+		},
+		{
+			name:        "unknown cluster",
+			service:     ecsmv1.ECSMService{Spec: ecsmv1.ECSMServiceSpec{Cluster: "missing"}},
+			wantSpecErr: true,
+		},
+		{
+			name: "moving to another cluster",
+			service: ecsmv1.ECSMService{
+				Spec:   ecsmv1.ECSMServiceSpec{Cluster: "edge"},
+				Status: ecsmv1.ECSMServiceStatus{Cluster: DefaultClusterName},
+			},
+			wantSpecErr: true,
+		},
+		{
+			name: "deleting after the cluster was changed",
+			service: ecsmv1.ECSMService{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+				Spec:       ecsmv1.ECSMServiceSpec{Cluster: "missing"},
+				Status:     ecsmv1.ECSMServiceStatus{Cluster: "edge"},
+			},
+			wantCluster: "edge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tt.service.DeepCopy()
+			err := c.resolveCluster(service)
+			if tt.wantSpecErr {
+				if !isSpecError(err) {
+					t.Fatalf("resolveCluster() error = %v, want a spec error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveCluster() error = %v", err)
+			}
+			if service.Status.Cluster != tt.wantCluster {
+				t.Errorf("status.cluster = %q, want %q", service.Status.Cluster, tt.wantCluster)
+			}
+		})
+	}
+}
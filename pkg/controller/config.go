@@ -0,0 +1,281 @@
+// file: pkg/controller/config.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+)
+
+// ReasonConfigError 表示容器模板引用的 ECSMConfig、ECSMSecret 或键不存在
+const ReasonConfigError = "ConfigError"
+
+// resolveTemplateConfigs 把容器模板中对 ECSMConfig 和 ECSMSecret 的引用替换为它们的值：
+// envFrom 和 env[].valueFrom 被展开为普通的环境变量，volumeMounts[].hostPathFrom 被替换为 hostPath，
+// vsoa.passwordFrom 被替换为 vsoa.password。
+// 模板在原地被修改，调用方应该传入一个不会被写回 Registry 的副本，以免 ECSMSecret 的值被明文保存。
+// 展开后的模板参与计算模板哈希，因此配置的值变化会产生新的修订版本，触发滚动更新。
+func resolveTemplateConfigs(ctx context.Context, reg registry.Interface, namespace string, template *ecsmv1.ContainerTemplateSpec) error {
+	if len(templateConfigNames(template)) == 0 && len(templateSecretNames(template)) == 0 {
+		return nil
+	}
+
+	configs := make(map[string]map[string]string)
+	getConfig := func(name string) (map[string]string, bool, error) {
+		if data, ok := configs[name]; ok {
+			return data, data != nil, nil
+		}
+		cfg, err := reg.GetConfig(ctx, namespace, name)
+		if errors.IsNotFound(err) {
+			configs[name] = nil
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get config %s/%s: %w", namespace, name, err)
+		}
+		configs[name] = cfg.Data
+		if configs[name] == nil {
+			configs[name] = map[string]string{}
+		}
+		return configs[name], true, nil
+	}
+	secrets := make(map[string]map[string]string)
+	getSecret := func(name string) (map[string]string, bool, error) {
+		if data, ok := secrets[name]; ok {
+			return data, data != nil, nil
+		}
+		secret, err := reg.GetSecret(ctx, namespace, name)
+		if errors.IsNotFound(err) {
+			secrets[name] = nil
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+		}
+		secrets[name] = secret.Data
+		if secrets[name] == nil {
+			secrets[name] = map[string]string{}
+		}
+		return secrets[name], true, nil
+	}
+
+	// lookup 读取一个键，kind 只用于错误信息
+	lookup := func(get func(string) (map[string]string, bool, error), kind, name, key string, optional *bool) (string, bool, error) {
+		data, found, err := get(name)
+		if err != nil {
+			return "", false, err
+		}
+		isOptional := optional != nil && *optional
+		if !found {
+			if isOptional {
+				return "", false, nil
+			}
+			return "", false, fmt.Errorf("%s %s/%s not found", kind, namespace, name)
+		}
+		value, ok := data[key]
+		if !ok && !isOptional {
+			return "", false, fmt.Errorf("key %q not found in %s %s/%s", key, kind, namespace, name)
+		}
+		return value, ok, nil
+	}
+
+	// 按照出现顺序合并环境变量，后出现的同名变量覆盖之前的
+	var env []ecsmv1.EnvVar
+	index := make(map[string]int)
+	setEnv := func(name, value string) {
+		if i, ok := index[name]; ok {
+			env[i].Value = value
+			return
+		}
+		index[name] = len(env)
+		env = append(env, ecsmv1.EnvVar{Name: name, Value: value})
+	}
+
+	for _, from := range template.EnvFrom {
+		var (
+			data     map[string]string
+			found    bool
+			err      error
+			kind     string
+			name     string
+			optional *bool
+		)
+		switch {
+		case from.ConfigRef != nil:
+			kind, name, optional = "config", from.ConfigRef.Name, from.ConfigRef.Optional
+			data, found, err = getConfig(name)
+		case from.SecretRef != nil:
+			kind, name, optional = "secret", from.SecretRef.Name, from.SecretRef.Optional
+			data, found, err = getSecret(name)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !found {
+			if optional != nil && *optional {
+				continue
+			}
+			return fmt.Errorf("%s %s/%s not found", kind, namespace, name)
+		}
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			setEnv(from.Prefix+k, data[k])
+		}
+	}
+
+	for _, e := range template.Env {
+		var (
+			value string
+			ok    bool
+			err   error
+		)
+		switch {
+		case e.ValueFrom != nil && e.ValueFrom.ConfigKeyRef != nil:
+			sel := e.ValueFrom.ConfigKeyRef
+			value, ok, err = lookup(getConfig, "config", sel.Name, sel.Key, sel.Optional)
+		case e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil:
+			sel := e.ValueFrom.SecretKeyRef
+			value, ok, err = lookup(getSecret, "secret", sel.Name, sel.Key, sel.Optional)
+		default:
+			value, ok = e.Value, true
+		}
+		if err != nil {
+			return fmt.Errorf("env %s: %w", e.Name, err)
+		}
+		if ok {
+			setEnv(e.Name, value)
+		}
+	}
+
+	for i := range template.VolumeMounts {
+		vm := &template.VolumeMounts[i]
+		if vm.HostPathFrom == nil {
+			continue
+		}
+		sel := vm.HostPathFrom
+		value, ok, err := lookup(getConfig, "config", sel.Name, sel.Key, sel.Optional)
+		if err != nil {
+			return fmt.Errorf("volume mount %s: %w", vm.Name, err)
+		}
+		if ok {
+			vm.HostPath = value
+		}
+		vm.HostPathFrom = nil
+	}
+
+	if vsoa := template.VSOA; vsoa != nil && vsoa.PasswordFrom != nil {
+		sel := vsoa.PasswordFrom
+		value, ok, err := lookup(getSecret, "secret", sel.Name, sel.Key, sel.Optional)
+		if err != nil {
+			return fmt.Errorf("vsoa password: %w", err)
+		}
+		if ok {
+			vsoa.Password = value
+		}
+		vsoa.PasswordFrom = nil
+	}
+
+	template.Env = env
+	template.EnvFrom = nil
+	return nil
+}
+
+// templateConfigNames 返回容器模板引用的所有 ECSMConfig 的名称。
+func templateConfigNames(template *ecsmv1.ContainerTemplateSpec) []string {
+	var names []string
+	for _, from := range template.EnvFrom {
+		if from.ConfigRef != nil {
+			names = append(names, from.ConfigRef.Name)
+		}
+	}
+	for _, e := range template.Env {
+		if e.ValueFrom != nil && e.ValueFrom.ConfigKeyRef != nil {
+			names = append(names, e.ValueFrom.ConfigKeyRef.Name)
+		}
+	}
+	for _, vm := range template.VolumeMounts {
+		if vm.HostPathFrom != nil {
+			names = append(names, vm.HostPathFrom.Name)
+		}
+	}
+	return names
+}
+
+// templateSecretNames 返回容器模板引用的所有 ECSMSecret 的名称。
+func templateSecretNames(template *ecsmv1.ContainerTemplateSpec) []string {
+	var names []string
+	for _, from := range template.EnvFrom {
+		if from.SecretRef != nil {
+			names = append(names, from.SecretRef.Name)
+		}
+	}
+	for _, e := range template.Env {
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+			names = append(names, e.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	if template.VSOA != nil && template.VSOA.PasswordFrom != nil {
+		names = append(names, template.VSOA.PasswordFrom.Name)
+	}
+	return names
+}
+
+// watchRegistry 订阅 Registry 的变更事件，在 ECSMConfig 或 ECSMSecret 变化时将引用它的 ECSMService 加入队列，
+// 在 ECSMNode 注册、变化或删除时将调度受它影响的 ECSMService 加入队列。
+func (c *ECSMServiceController) watchRegistry(stopCh <-chan struct{}) {
+	eventCh, cancel := c.registry.Subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case event, ok := <-eventCh:
+			if !ok {
+				klog.Warningf("Registry event channel closed, no longer watching ECSMConfigs, ECSMSecrets and ECSMNodes")
+				return
+			}
+			switch obj := event.Object.(type) {
+			case *ecsmv1.ECSMConfig:
+				c.enqueueServicesReferencing(obj.Namespace, obj.Name, "ECSMConfig", templateConfigNames)
+			case *ecsmv1.ECSMSecret:
+				c.enqueueServicesReferencing(obj.Namespace, obj.Name, "ECSMSecret", templateSecretNames)
+			case *ecsmv1.ECSMNode:
+				c.enqueueServicesForNode(obj)
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// enqueueServicesReferencing 将模板引用了 namespace/name 的 ECSMService 加入队列，
+// names 返回模板引用的某一类对象的名称。
+func (c *ECSMServiceController) enqueueServicesReferencing(namespace, name, kind string, names func(*ecsmv1.ContainerTemplateSpec) []string) {
+	services, _, err := c.registry.ListAllServices(context.Background(), namespace)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list services for %s %s/%s: %w", kind, namespace, name, err))
+		return
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		for _, ref := range names(&svc.Spec.Template) {
+			if ref == name {
+				klog.V(2).Infof("%s %s/%s changed, requeueing service %s", kind, namespace, name, serviceKey(svc))
+				c.queue.Add(serviceKey(svc))
+				break
+			}
+		}
+	}
+}
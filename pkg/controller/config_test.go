@@ -0,0 +1,123 @@
+// file: pkg/controller/config_test.go
+
+package controller
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	bolt "go.etcd.io/bbolt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolveTemplateConfigs(t *testing.T) {
+	ctx := context.Background()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	cfg, err := reg.CreateConfig(ctx, &ecsmv1.ECSMConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "site"},
+		Data:       map[string]string{"REGION": "east", "LEVEL": "debug", "dataDir": "/data/east"},
+	})
+	if err != nil {
+		t.Fatalf("CreateConfig failed: %v", err)
+	}
+
+	optional := true
+	newTemplate := func() *ecsmv1.ContainerTemplateSpec {
+		return &ecsmv1.ContainerTemplateSpec{
+			Image:   "app@1.0",
+			EnvFrom: []ecsmv1.EnvFromSource{{Prefix: "SITE_", ConfigRef: &ecsmv1.ConfigReference{Name: "site"}}},
+			Env: []ecsmv1.EnvVar{
+				{Name: "SITE_LEVEL", Value: "info"},
+				{Name: "DIR", ValueFrom: &ecsmv1.EnvVarSource{ConfigKeyRef: &ecsmv1.ConfigKeySelector{Name: "site", Key: "dataDir"}}},
+				{Name: "OPTIONAL", ValueFrom: &ecsmv1.EnvVarSource{ConfigKeyRef: &ecsmv1.ConfigKeySelector{Name: "missing", Key: "x", Optional: &optional}}},
+			},
+			VolumeMounts: []ecsmv1.VolumeMount{{
+				Name: "data", ContainerPath: "/data",
+				HostPathFrom: &ecsmv1.ConfigKeySelector{Name: "site", Key: "dataDir"},
+			}},
+		}
+	}
+
+	template := newTemplate()
+	if err := resolveTemplateConfigs(ctx, reg, "default", template); err != nil {
+		t.Fatalf("resolveTemplateConfigs() error = %v", err)
+	}
+	wantEnv := []ecsmv1.EnvVar{
+		{Name: "SITE_LEVEL", Value: "info"},
+		{Name: "SITE_REGION", Value: "east"},
+		{Name: "SITE_dataDir", Value: "/data/east"},
+		{Name: "DIR", Value: "/data/east"},
+	}
+	if !reflect.DeepEqual(template.Env, wantEnv) {
+		t.Errorf("env = %v, want %v", template.Env, wantEnv)
+	}
+	if template.EnvFrom != nil {
+		t.Errorf("envFrom = %v, want it to be expanded", template.EnvFrom)
+	}
+	if vm := template.VolumeMounts[0]; vm.HostPath != "/data/east" || vm.HostPathFrom != nil {
+		t.Errorf("volume mount = %+v, want hostPath /data/east", vm)
+	}
+
+	// 配置的值变化后，展开后的模板会产生新的哈希
+	hash := computeTemplateHash(template)
+	cfg.Data["REGION"] = "west"
+	if _, err := reg.UpdateConfig(ctx, cfg); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	template = newTemplate()
+	if err := resolveTemplateConfigs(ctx, reg, "default", template); err != nil {
+		t.Fatalf("resolveTemplateConfigs() error = %v", err)
+	}
+	if computeTemplateHash(template) == hash {
+		t.Errorf("template hash did not change after the config changed")
+	}
+
+	// 必需的配置不存在时返回错误
+	template = newTemplate()
+	template.EnvFrom[0].ConfigRef.Name = "missing"
+	if err := resolveTemplateConfigs(ctx, reg, "default", template); err == nil {
+		t.Errorf("resolveTemplateConfigs() succeeded with a missing config, want an error")
+	}
+
+	// ECSMSecret 的值被展开到环境变量和 VSOA 密码中
+	transformer, err := registry.NewAESGCMTransformer(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("NewAESGCMTransformer failed: %v", err)
+	}
+	reg.SetEncryption(transformer)
+	if _, err := reg.CreateSecret(ctx, &ecsmv1.ECSMSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "creds"},
+		Data:       map[string]string{"token": "abc", "vsoa": "pw"},
+	}); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+	template = &ecsmv1.ContainerTemplateSpec{
+		Image: "app@1.0",
+		Env: []ecsmv1.EnvVar{
+			{Name: "TOKEN", ValueFrom: &ecsmv1.EnvVarSource{SecretKeyRef: &ecsmv1.SecretKeySelector{Name: "creds", Key: "token"}}},
+		},
+		VSOA: &ecsmv1.VSOASpec{PasswordFrom: &ecsmv1.SecretKeySelector{Name: "creds", Key: "vsoa"}},
+	}
+	if err := resolveTemplateConfigs(ctx, reg, "default", template); err != nil {
+		t.Fatalf("resolveTemplateConfigs() error = %v", err)
+	}
+	if want := []ecsmv1.EnvVar{{Name: "TOKEN", Value: "abc"}}; !reflect.DeepEqual(template.Env, want) {
+		t.Errorf("env = %v, want %v", template.Env, want)
+	}
+	if template.VSOA.Password != "pw" || template.VSOA.PasswordFrom != nil {
+		t.Errorf("vsoa = %+v, want password pw", template.VSOA)
+	}
+}
@@ -0,0 +1,190 @@
+// file: pkg/controller/cron_schedule.go
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule 是解析后的 5 字段 cron 表达式，每个字段用一个位图表示允许的取值。
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar 和 dowStar 记录了日和周字段是否为 "*"。
+	// 两者都被限定时，只要满足其中一个即可，这与 Vixie cron 的行为一致。
+	domStar, dowStar bool
+}
+
+// cronField 描述了 cron 表达式中一个字段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 周字段中 0 和 7 都表示周日
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronMacros 是预定义的调度写法
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxCronSearchYears 是查找下一次调度时间时最多向后搜索的年数，
+// 用于识别永远不会触发的表达式 (例如 "0 0 30 2 *")
+const maxCronSearchYears = 5
+
+// parseCronSchedule 解析一个标准的 5 字段 cron 表达式。
+// 每个字段支持 "*"、单个值、范围 "a-b"、步长 "*/n" 或 "a-b/n" 以及逗号分隔的列表，
+// 月和周字段还可以使用英文缩写，例如 "jan" 和 "mon"。
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &cronSchedule{}
+	var err error
+	if s.minute, _, err = parseCronField(fields[0], cronMinute); err != nil {
+		return nil, err
+	}
+	if s.hour, _, err = parseCronField(fields[1], cronHour); err != nil {
+		return nil, err
+	}
+	if s.dom, s.domStar, err = parseCronField(fields[2], cronDom); err != nil {
+		return nil, err
+	}
+	if s.month, _, err = parseCronField(fields[3], cronMonth); err != nil {
+		return nil, err
+	}
+	if s.dow, s.dowStar, err = parseCronField(fields[4], cronDow); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField 解析一个字段，返回允许取值的位图，以及该字段是否为 "*"。
+func parseCronField(expr string, field cronField) (uint64, bool, error) {
+	var bits uint64
+	star := false
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n <= 0 {
+				return 0, false, fmt.Errorf("invalid step %q in %s field %q", stepExpr, field.name, expr)
+			}
+			step = n
+		}
+
+		var start, end int
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			start, end = field.min, field.max
+			star = star || !hasStep
+		default:
+			lo, hi, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if start, err = parseCronValue(lo, field); err != nil {
+				return 0, false, fmt.Errorf("invalid %s field %q: %w", field.name, expr, err)
+			}
+			end = start
+			switch {
+			case isRange:
+				if end, err = parseCronValue(hi, field); err != nil {
+					return 0, false, fmt.Errorf("invalid %s field %q: %w", field.name, expr, err)
+				}
+			case hasStep:
+				// "a/n" 表示从 a 开始直到最大值
+				end = field.max
+			}
+			if start > end {
+				return 0, false, fmt.Errorf("invalid %s field %q: range start is greater than end", field.name, expr)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
+
+func parseCronValue(s string, field cronField) (int, error) {
+	if v, ok := field.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < field.min || v > field.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, field.min, field.max)
+	}
+	return v, nil
+}
+
+// next 返回 t 之后 (不含 t) 的第一个调度时间。表达式永远不会触发时返回零值。
+func (s *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxCronSearchYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
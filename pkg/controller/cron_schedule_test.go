@@ -0,0 +1,63 @@
+// file: pkg/controller/cron_schedule_test.go
+
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// 2025-03-15 是周六
+	from := time.Date(2025, 3, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		schedule string
+		want     time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, 3, 15, 10, 15, 0, 0, time.UTC)},
+		{"7 10 * * *", time.Date(2025, 3, 16, 10, 7, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2025, 4, 1, 2, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 3, 15, 11, 0, 0, 0, time.UTC)},
+		// 日和周都被限定时满足其中一个即可：3 月 20 日 (周四) 比 4 月 1 日更早
+		{"0 0 1 * thu", time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)},
+		// 周字段中的 7 也表示周日
+		{"0 0 * * 7", time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		// 永远不会触发
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			s, err := parseCronSchedule(tt.schedule)
+			if err != nil {
+				t.Fatalf("parseCronSchedule(%q) error = %v", tt.schedule, err)
+			}
+			if got := s.next(from); !got.Equal(tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	for _, schedule := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@every 5m",
+	} {
+		if _, err := parseCronSchedule(schedule); err == nil {
+			t.Errorf("parseCronSchedule(%q) succeeded, want an error", schedule)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+// file: pkg/controller/cronjob_controller.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultCronJobSyncPeriod 是 CronJobController 默认的同步周期。
+	// cron 表达式的精度是分钟，同步周期应该明显小于一分钟。
+	DefaultCronJobSyncPeriod = 10 * time.Second
+
+	// defaultSuccessfulJobsHistoryLimit 和 defaultFailedJobsHistoryLimit 是历史作业数量的默认上限
+	defaultSuccessfulJobsHistoryLimit = 3
+	defaultFailedJobsHistoryLimit     = 1
+
+	// tooManyMissedSchedules 是错过的调度次数超过后记录警告事件的阈值
+	tooManyMissedSchedules = 100
+
+	// cronJobSyncTimeout 是一次全量同步的最长时间
+	cronJobSyncTimeout = time.Minute
+
+	// cronJobControllerName 是 CronJobController 在指标中的名称
+	cronJobControllerName = "cronjob"
+)
+
+// 定时作业相关的事件原因
+const (
+	ReasonSuccessfulCreate = "SuccessfulCreate"
+	ReasonSuccessfulDelete = "SuccessfulDelete"
+	ReasonMissSchedule     = "MissSchedule"
+	ReasonTooManyMissed    = "TooManyMissedTimes"
+	ReasonJobAlreadyActive = "JobAlreadyActive"
+	ReasonInvalidSchedule  = "InvalidSchedule"
+	ReasonSawCompletedJob  = "SawCompletedJob"
+	ReasonFailedCreateJob  = "FailedCreate"
+	ReasonFailedDeleteJob  = "FailedDelete"
+)
+
+const (
+	// cronJobOwnerKind 是 ECSMCronJob 创建的作业上 ownerReference 的 kind
+	cronJobOwnerKind = "ECSMCronJob"
+
+	// AnnotationScheduledTime 记录了作业对应的计划时间 (RFC3339，UTC)
+	AnnotationScheduledTime = "ecsm.sh/scheduled-time"
+)
+
+// CronJobController 周期性地同步所有 ECSMCronJob：在计划时间到达时创建 ECSMJob，
+// 按照并发策略处理仍在运行的作业，并清理超过历史上限的已结束作业。
+// 它创建的作业通过 ownerReferences 指向所属的 ECSMCronJob，ECSMCronJob 被删除后，这些作业也会被删除。
+type CronJobController struct {
+	registry registry.Interface
+	recorder record.EventRecorder
+
+	syncPeriod time.Duration
+	clock      func() time.Time
+}
+
+// NewCronJobController 创建一个新的 CronJobController。
+func NewCronJobController(reg registry.Interface, recorder record.EventRecorder, syncPeriod time.Duration) *CronJobController {
+	return &CronJobController{
+		registry:   reg,
+		recorder:   recorder,
+		syncPeriod: syncPeriod,
+		clock:      time.Now,
+	}
+}
+
+// Run 启动周期性同步，直到 stopCh 被关闭。
+func (c *CronJobController) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting cronjob controller")
+	defer klog.Info("Shutting down cronjob controller")
+
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cronJobSyncTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := c.syncAll(ctx)
+		result := reconcileResult(ctx, err)
+		reconcileTotal.WithLabelValues(cronJobControllerName, result).Inc()
+		reconcileDuration.WithLabelValues(cronJobControllerName).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("failed to sync cronjobs: %w", err))
+		}
+	}, c.syncPeriod, stopCh)
+}
+
+// syncAll 同步所有 ECSMCronJob，并删除属主已经不存在的作业。
+func (c *CronJobController) syncAll(ctx context.Context) error {
+	cronJobs, _, err := c.registry.ListCronJobs(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMCronJobs: %w", err)
+	}
+	jobs, _, err := c.registry.ListJobs(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMJobs: %w", err)
+	}
+
+	// 按属主 UID 对作业分组
+	jobsByOwner := make(map[types.UID][]ecsmv1.ECSMJob)
+	for _, job := range jobs.Items {
+		if owner := ecsmmeta.GetControllerOf(&job); owner != nil && owner.Kind == cronJobOwnerKind {
+			jobsByOwner[owner.UID] = append(jobsByOwner[owner.UID], job)
+		}
+	}
+
+	var errs []error
+	for i := range cronJobs.Items {
+		cronJob := &cronJobs.Items[i]
+		owned := jobsByOwner[cronJob.UID]
+		delete(jobsByOwner, cronJob.UID)
+		if err := c.syncCronJob(ctx, cronJob, owned); err != nil {
+			errs = append(errs, fmt.Errorf("cronjob %s/%s: %w", cronJob.Namespace, cronJob.Name, err))
+		}
+	}
+
+	// 剩下的作业属于已经被删除的 ECSMCronJob。作业的平台服务随后由垃圾回收器清理。
+	for _, orphans := range jobsByOwner {
+		for _, job := range orphans {
+			klog.Infof("Deleting job %s/%s, its cronjob no longer exists", job.Namespace, job.Name)
+			if err := c.registry.DeleteJob(ctx, job.Namespace, job.Name); err != nil && !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete orphaned job %s/%s: %w", job.Namespace, job.Name, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// syncCronJob 推进一个定时作业，并在状态变化时写回 Registry。jobs 是它创建的所有作业。
+func (c *CronJobController) syncCronJob(ctx context.Context, cronJob *ecsmv1.ECSMCronJob, jobs []ecsmv1.ECSMJob) error {
+	newStatus := cronJob.Status.DeepCopy()
+	syncErr := c.reconcileCronJob(ctx, cronJob, jobs, newStatus)
+
+	if reflect.DeepEqual(&cronJob.Status, newStatus) {
+		return syncErr
+	}
+	toUpdate := cronJob.DeepCopy()
+	toUpdate.Status = *newStatus
+	if _, err := c.registry.UpdateCronJobStatus(ctx, toUpdate); err != nil {
+		return fmt.Errorf("failed to update ECSMCronJob status: %w", err)
+	}
+	return syncErr
+}
+
+// reconcileCronJob 完成一次定时作业的调谐，结果写入 status。
+func (c *CronJobController) reconcileCronJob(ctx context.Context, cronJob *ecsmv1.ECSMCronJob, jobs []ecsmv1.ECSMJob, status *ecsmv1.ECSMCronJobStatus) error {
+	now := c.clock()
+
+	// 1. 根据作业的当前状态刷新 active 列表和最近一次成功的时间
+	status.Active = nil
+	for i := range jobs {
+		job := &jobs[i]
+		if !isJobFinished(job) {
+			status.Active = append(status.Active, jobReference(job))
+			continue
+		}
+		if ecsmmeta.IsConditionTrue(job.Status.Conditions, ecsmv1.JobComplete) && job.Status.CompletionTime != nil {
+			if status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(job.Status.CompletionTime) {
+				status.LastSuccessfulTime = job.Status.CompletionTime.DeepCopy()
+				c.recorder.Eventf(cronJob, ecsmv1.EventTypeNormal, ReasonSawCompletedJob, "Saw completed job: %s", job.Name)
+			}
+		}
+	}
+
+	// 2. 清理超过历史上限的已结束作业
+	if err := c.cleanupFinishedJobs(ctx, cronJob, jobs); err != nil {
+		return err
+	}
+
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+		klog.V(4).Infof("CronJob %s/%s is suspended, not scheduling", cronJob.Namespace, cronJob.Name)
+		return nil
+	}
+
+	// 3. 找出最近一次未处理的计划时间
+	schedule, err := parseCronSchedule(cronJob.Spec.Schedule)
+	if err != nil {
+		// 表达式无效时重试没有意义，等待用户修改 spec
+		c.recorder.Eventf(cronJob, ecsmv1.EventTypeWarning, ReasonInvalidSchedule, "%v", err)
+		return nil
+	}
+	scheduledTime, missed := mostRecentScheduleTime(cronJob, schedule, now)
+	if scheduledTime == nil {
+		return nil
+	}
+	if missed > tooManyMissedSchedules {
+		c.recorder.Eventf(cronJob, ecsmv1.EventTypeWarning, ReasonTooManyMissed,
+			"Missed %d start times, only the most recent one at %s will be run", missed, scheduledTime.Format(time.RFC3339))
+	}
+	if deadline := cronJob.Spec.StartingDeadlineSeconds; deadline != nil && now.Sub(*scheduledTime) > time.Duration(*deadline)*time.Second {
+		c.recorder.Eventf(cronJob, ecsmv1.EventTypeWarning, ReasonMissSchedule,
+			"Missed scheduled time to start a job: %s", scheduledTime.Format(time.RFC3339))
+		return nil
+	}
+
+	// 4. 按照并发策略处理仍在运行的作业
+	switch cronJob.Spec.ConcurrencyPolicy {
+	case ecsmv1.ForbidConcurrent:
+		if len(status.Active) > 0 {
+			klog.V(2).Infof("CronJob %s/%s: not starting a job because %d job(s) are still active", cronJob.Namespace, cronJob.Name, len(status.Active))
+			c.recorder.Eventf(cronJob, ecsmv1.EventTypeNormal, ReasonJobAlreadyActive,
+				"Not starting job because prior execution is running and concurrency policy is Forbid")
+			return nil
+		}
+	case ecsmv1.ReplaceConcurrent:
+		for _, ref := range status.Active {
+			if err := c.registry.DeleteJob(ctx, ref.Namespace, ref.Name); err != nil && !errors.IsNotFound(err) {
+				c.recorder.Eventf(cronJob, ecsmv1.EventTypeWarning, ReasonFailedDeleteJob, "Failed to delete active job %s: %v", ref.Name, err)
+				return fmt.Errorf("failed to delete active job %s: %w", ref.Name, err)
+			}
+			c.recorder.Eventf(cronJob, ecsmv1.EventTypeNormal, ReasonSuccessfulDelete, "Deleted job %s", ref.Name)
+		}
+		status.Active = nil
+	}
+
+	// 5. 创建作业
+	job := jobFromTemplate(cronJob, *scheduledTime)
+	created, err := c.registry.CreateJob(ctx, job)
+	switch {
+	case errors.IsAlreadyExists(err):
+		// 上一次同步已经创建了这个作业，但没能写回 status
+		klog.V(2).Infof("CronJob %s/%s: job %s already exists", cronJob.Namespace, cronJob.Name, job.Name)
+	case err != nil:
+		c.recorder.Eventf(cronJob, ecsmv1.EventTypeWarning, ReasonFailedCreateJob, "Error creating job %s: %v", job.Name, err)
+		return fmt.Errorf("failed to create job %s: %w", job.Name, err)
+	default:
+		klog.Infof("CronJob %s/%s: created job %s for %s", cronJob.Namespace, cronJob.Name, created.Name, scheduledTime.Format(time.RFC3339))
+		c.recorder.Eventf(cronJob, ecsmv1.EventTypeNormal, ReasonSuccessfulCreate, "Created job %s", created.Name)
+		status.Active = append(status.Active, jobReference(created))
+	}
+	lastSchedule := metav1.NewTime(*scheduledTime)
+	status.LastScheduleTime = &lastSchedule
+	return nil
+}
+
+// cleanupFinishedJobs 按照历史上限删除最旧的已完成和已失败的作业。
+func (c *CronJobController) cleanupFinishedJobs(ctx context.Context, cronJob *ecsmv1.ECSMCronJob, jobs []ecsmv1.ECSMJob) error {
+	var succeeded, failed []ecsmv1.ECSMJob
+	for _, job := range jobs {
+		switch {
+		case ecsmmeta.IsConditionTrue(job.Status.Conditions, ecsmv1.JobComplete):
+			succeeded = append(succeeded, job)
+		case ecsmmeta.IsConditionTrue(job.Status.Conditions, ecsmv1.JobFailed):
+			failed = append(failed, job)
+		}
+	}
+
+	successfulLimit := historyLimit(cronJob.Spec.SuccessfulJobsHistoryLimit, defaultSuccessfulJobsHistoryLimit)
+	failedLimit := historyLimit(cronJob.Spec.FailedJobsHistoryLimit, defaultFailedJobsHistoryLimit)
+
+	var toDelete []ecsmv1.ECSMJob
+	toDelete = append(toDelete, oldestJobsOverLimit(succeeded, successfulLimit)...)
+	toDelete = append(toDelete, oldestJobsOverLimit(failed, failedLimit)...)
+	for _, job := range toDelete {
+		if err := c.registry.DeleteJob(ctx, job.Namespace, job.Name); err != nil && !errors.IsNotFound(err) {
+			c.recorder.Eventf(cronJob, ecsmv1.EventTypeWarning, ReasonFailedDeleteJob, "Failed to delete finished job %s: %v", job.Name, err)
+			return fmt.Errorf("failed to delete finished job %s: %w", job.Name, err)
+		}
+		c.recorder.Eventf(cronJob, ecsmv1.EventTypeNormal, ReasonSuccessfulDelete, "Deleted job %s", job.Name)
+	}
+	return nil
+}
+
+// mostRecentScheduleTime 返回上一次调度之后、now 之前 (含 now) 最近的一个计划时间，以及其间错过的计划时间数量。
+// 没有需要处理的计划时间时返回 nil。
+func mostRecentScheduleTime(cronJob *ecsmv1.ECSMCronJob, schedule *cronSchedule, now time.Time) (*time.Time, int) {
+	earliest := cronJob.CreationTimestamp.Time
+	if cronJob.Status.LastScheduleTime != nil {
+		earliest = cronJob.Status.LastScheduleTime.Time
+	}
+	// 超过启动期限的计划时间无论如何都不会执行，不需要遍历
+	if deadline := cronJob.Spec.StartingDeadlineSeconds; deadline != nil {
+		if limit := now.Add(-time.Duration(*deadline) * time.Second); limit.After(earliest) {
+			earliest = limit
+		}
+	}
+
+	var last *time.Time
+	missed := 0
+	for t := schedule.next(earliest.In(now.Location())); !t.IsZero() && !t.After(now); t = schedule.next(t) {
+		scheduled := t
+		last = &scheduled
+		missed++
+	}
+	return last, missed
+}
+
+// jobFromTemplate 根据定时作业的模板构造一个计划时间为 scheduledTime 的作业。
+// 作业名包含计划时间，同一个计划时间重复创建会因为重名而失败。
+func jobFromTemplate(cronJob *ecsmv1.ECSMCronJob, scheduledTime time.Time) *ecsmv1.ECSMJob {
+	template := cronJob.Spec.JobTemplate.DeepCopy()
+	job := &ecsmv1.ECSMJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   cronJob.Namespace,
+			Name:        fmt.Sprintf("%s-%d", cronJob.Name, scheduledTime.Unix()/60),
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronJob, ecsmv1.SchemeGroupVersion.WithKind(cronJobOwnerKind)),
+			},
+		},
+		Spec: template.Spec,
+	}
+	if job.Annotations == nil {
+		job.Annotations = make(map[string]string)
+	}
+	job.Annotations[AnnotationScheduledTime] = scheduledTime.UTC().Format(time.RFC3339)
+	return job
+}
+
+// oldestJobsOverLimit 返回按创建时间排序后超出 limit 的最旧的作业。
+func oldestJobsOverLimit(jobs []ecsmv1.ECSMJob, limit int) []ecsmv1.ECSMJob {
+	if len(jobs) <= limit {
+		return nil
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+	})
+	return jobs[:len(jobs)-limit]
+}
+
+func historyLimit(limit *int32, defaultLimit int) int {
+	if limit != nil && *limit >= 0 {
+		return int(*limit)
+	}
+	return defaultLimit
+}
+
+func jobReference(job *ecsmv1.ECSMJob) ecsmv1.ObjectReference {
+	return ecsmv1.ObjectReference{
+		Kind:       "ECSMJob",
+		APIVersion: ecsmv1.SchemeGroupVersion.String(),
+		Namespace:  job.Namespace,
+		Name:       job.Name,
+		UID:        job.UID,
+	}
+}
@@ -0,0 +1,113 @@
+// file: pkg/controller/cronjob_controller_test.go
+
+package controller
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	bolt "go.etcd.io/bbolt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCronJobControllerForbidConcurrent(t *testing.T) {
+	ctx := context.Background()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	cronJob, err := reg.CreateCronJob(ctx, &ecsmv1.ECSMCronJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "collect"},
+		Spec: ecsmv1.ECSMCronJobSpec{
+			Schedule:          "*/5 * * * *",
+			ConcurrencyPolicy: ecsmv1.ForbidConcurrent,
+			JobTemplate: ecsmv1.ECSMJobTemplateSpec{
+				Spec: ecsmv1.ECSMJobSpec{NodePool: []string{"node-1"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateCronJob failed: %v", err)
+	}
+
+	now := cronJob.CreationTimestamp.Time
+	c := NewCronJobController(reg, &record.FakeRecorder{}, time.Second)
+	c.clock = func() time.Time { return now }
+
+	sync := func() []ecsmv1.ECSMJob {
+		t.Helper()
+		if err := c.syncAll(ctx); err != nil {
+			t.Fatalf("syncAll failed: %v", err)
+		}
+		jobs, _, err := reg.ListJobs(ctx, "default")
+		if err != nil {
+			t.Fatalf("ListJobs failed: %v", err)
+		}
+		return jobs.Items
+	}
+
+	// 还没有到计划时间
+	if jobs := sync(); len(jobs) != 0 {
+		t.Fatalf("got %d jobs before the first scheduled time, want 0", len(jobs))
+	}
+
+	now = now.Add(6 * time.Minute)
+	jobs := sync()
+	if len(jobs) != 1 {
+		t.Fatalf("got %d jobs after the first scheduled time, want 1", len(jobs))
+	}
+	if !ecsmmeta.IsControlledBy(&jobs[0], cronJob) {
+		t.Errorf("job is not owned by the cronjob: %v", jobs[0].OwnerReferences)
+	}
+
+	// 上一个作业仍在运行，Forbid 策略下不会创建新的作业
+	now = now.Add(5 * time.Minute)
+	if jobs := sync(); len(jobs) != 1 {
+		t.Fatalf("got %d jobs while the first one is active, want 1", len(jobs))
+	}
+
+	// 作业完成后，错过的计划时间会被补上
+	completed := jobs[0].DeepCopy()
+	completionTime := metav1.NewTime(now)
+	completed.Status.CompletionTime = &completionTime
+	ecsmmeta.SetCondition(&completed.Status.Conditions, metav1.Condition{
+		Type: ecsmv1.JobComplete, Status: metav1.ConditionTrue, Reason: ReasonJobCompleted,
+	})
+	if _, err := reg.UpdateJobStatus(ctx, completed); err != nil {
+		t.Fatalf("UpdateJobStatus failed: %v", err)
+	}
+	if jobs := sync(); len(jobs) != 2 {
+		t.Fatalf("got %d jobs after the first one completed, want 2", len(jobs))
+	}
+
+	updated, err := reg.GetCronJob(ctx, "default", "collect")
+	if err != nil {
+		t.Fatalf("GetCronJob failed: %v", err)
+	}
+	if len(updated.Status.Active) != 1 {
+		t.Errorf("status.active has %d jobs, want 1", len(updated.Status.Active))
+	}
+	if updated.Status.LastSuccessfulTime == nil {
+		t.Errorf("status.lastSuccessfulTime is not set")
+	}
+
+	// 删除 ECSMCronJob 之后，它创建的作业也会被删除
+	if err := reg.DeleteCronJob(ctx, "default", "collect"); err != nil {
+		t.Fatalf("DeleteCronJob failed: %v", err)
+	}
+	if jobs := sync(); len(jobs) != 0 {
+		t.Errorf("got %d jobs after the cronjob was deleted, want 0", len(jobs))
+	}
+}
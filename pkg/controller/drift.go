@@ -0,0 +1,137 @@
+// file: pkg/controller/drift.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"k8s.io/klog/v2"
+)
+
+// LabelDesiredReplicas 记录了控制器最近一次给平台服务设置的副本数。
+// 平台上的实际副本数与它不一致，说明有人绕过 operator 直接修改了平台服务；
+// 它与 spec 中的副本数不一致，则说明是用户修改了 spec，属于正常的扩缩容。
+const LabelDesiredReplicas = "ecsm.sh/desired-replicas"
+
+// detectDrift 比较当前修订版本在平台上的实际配置与声明的状态，返回人类可读的差异列表。
+// 它只依赖平台服务列表中的数据，不会额外调用 ECSM API。
+func detectDrift(service *ecsmv1.ECSMService, ps *platformService) []string {
+	var diffs []string
+	row := ps.Row
+	strategy := service.Spec.DeploymentStrategy
+
+	if !rowRunsTemplateImage(row, &service.Spec.Template) {
+		var images []string
+		for _, img := range row.ImageList {
+			images = append(images, img.Name+"@"+img.Tag)
+		}
+		diffs = append(diffs, fmt.Sprintf("image is %v, expected %s", images, service.Spec.Template.Image))
+	}
+
+	if policy := strings.ToLower(string(strategy.Type)); row.Policy != "" && row.Policy != policy {
+		diffs = append(diffs, fmt.Sprintf("policy is %q, expected %q", row.Policy, policy))
+	}
+
+	if strategy.Type == ecsmv1.DeploymentStrategyTypeStatic {
+		var actual []string
+		for _, n := range row.NodeList {
+			actual = append(actual, n.NodeName)
+		}
+		expected := append([]string(nil), strategy.Nodes...)
+		sort.Strings(actual)
+		sort.Strings(expected)
+		if strings.Join(actual, ",") != strings.Join(expected, ",") {
+			diffs = append(diffs, fmt.Sprintf("nodes are %v, expected %v", actual, expected))
+		}
+	} else if v, ok := rowLabels(row)[LabelDesiredReplicas]; ok {
+		if applied, err := strconv.Atoi(v); err == nil && applied != row.Factor {
+			diffs = append(diffs, fmt.Sprintf("replicas changed from %d to %d", applied, row.Factor))
+		}
+	}
+
+	return diffs
+}
+
+// rowRunsTemplateImage 判断平台服务是否运行着模板中的镜像。
+func rowRunsTemplateImage(row clientset.ProvisionListRow, template *ecsmv1.ContainerTemplateSpec) bool {
+	name, tag, _ := strings.Cut(template.Image, "@")
+	// tag 后可能带有 "#os" 后缀
+	tag, _, _ = strings.Cut(tag, "#")
+	for _, img := range row.ImageList {
+		if img.Name == name && (tag == "" || img.Tag == tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDrift 处理当前修订版本的带外修改。返回 true 表示发现了漂移。
+// Enforce 策略下会把平台服务恢复为声明的状态，ReportOnly 策略下只记录事件。
+func (c *ECSMServiceController) handleDrift(ctx context.Context, service *ecsmv1.ECSMService, ps *platformService) (bool, error) {
+	diffs := detectDrift(service, ps)
+	if len(diffs) == 0 {
+		return false, nil
+	}
+	detail := strings.Join(diffs, "; ")
+
+	if service.Spec.DriftPolicy == ecsmv1.DriftPolicyReportOnly {
+		klog.Warningf("Service %s/%s: platform service %s was modified outside of the operator (%s), not reverting because driftPolicy is ReportOnly",
+			service.Namespace, service.Name, ps.Row.Name, detail)
+		c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonDriftDetected,
+			"Platform service %s was modified outside of the operator: %s", ps.Row.Name, detail)
+		return true, nil
+	}
+
+	klog.Warningf("Service %s/%s: platform service %s was modified outside of the operator (%s), reverting",
+		service.Namespace, service.Name, ps.Row.Name, detail)
+	c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonDriftDetected,
+		"Platform service %s was modified outside of the operator: %s; reverting to the declared spec", ps.Row.Name, detail)
+	return true, c.restorePlatformService(ctx, service, ps, desiredReplicas(service))
+}
+
+// restorePlatformService 用声明的模板、节点和副本数整体覆盖一个平台服务的配置。
+func (c *ECSMServiceController) restorePlatformService(ctx context.Context, service *ecsmv1.ECSMService, ps *platformService, replicas int32) error {
+	if c.isDryRun(service) {
+		c.planAction(service, "restore platform service %s to the declared spec with %d replica(s)", ps.Row.Name, replicas)
+		return nil
+	}
+	image, err := buildImageSpec(service)
+	if err != nil {
+		return err
+	}
+	current, err := c.clientFor(service).Services().Get(ctx, ps.Row.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get platform service %s: %w", ps.Row.Name, err)
+	}
+
+	req := &clientset.UpdateServiceRequest{
+		ID:     current.ID,
+		Name:   current.Name,
+		Image:  *image,
+		Policy: strings.ToLower(string(service.Spec.DeploymentStrategy.Type)),
+		Labels: withDesiredReplicas(current.Labels, replicas),
+	}
+	req.Node, req.Factor = nodeSpecFor(service, replicas)
+	if err := c.scheduleNodes(ctx, service, replicas, &req.Node, rowNodeNames(ps.Row)); err != nil {
+		return err
+	}
+
+	if _, err := c.clientFor(service).Services().Update(ctx, req.ID, req); err != nil {
+		return fmt.Errorf("failed to restore platform service %s: %w", ps.Row.Name, err)
+	}
+	ps.Row.Factor = int(replicas)
+	return nil
+}
+
+// withDesiredReplicas 返回设置了 LabelDesiredReplicas 之后的标签列表。
+func withDesiredReplicas(labels []string, replicas int32) []string {
+	parsed := parseLabels(labels)
+	parsed[LabelDesiredReplicas] = strconv.Itoa(int(replicas))
+	return formatLabels(parsed)
+}
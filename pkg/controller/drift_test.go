@@ -0,0 +1,49 @@
+// file: pkg/controller/drift_test.go
+
+package controller
+
+import (
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+func TestDetectDrift(t *testing.T) {
+	replicas := int32(3)
+	dynamic := &ecsmv1.ECSMService{
+		Spec: ecsmv1.ECSMServiceSpec{
+			Template:           ecsmv1.ContainerTemplateSpec{Image: "nginx@1.2#linux"},
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeDynamic, Replicas: &replicas},
+		},
+	}
+	static := dynamic.DeepCopy()
+	static.Spec.DeploymentStrategy = ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeStatic, Nodes: []string{"n1", "n2"}}
+
+	image := []clientset.ImageListEntry{{Name: "nginx", Tag: "1.2"}}
+
+	tests := []struct {
+		name    string
+		service *ecsmv1.ECSMService
+		row     clientset.ProvisionListRow
+		want    int
+	}{
+		{"in sync", dynamic, clientset.ProvisionListRow{Policy: "dynamic", Factor: 3, ImageList: image, Labels: []string{LabelDesiredReplicas + "=3"}}, 0},
+		{"scaled out of band", dynamic, clientset.ProvisionListRow{Policy: "dynamic", Factor: 5, ImageList: image, Labels: []string{LabelDesiredReplicas + "=3"}}, 1},
+		{"spec replicas changed", dynamic, clientset.ProvisionListRow{Policy: "dynamic", Factor: 2, ImageList: image, Labels: []string{LabelDesiredReplicas + "=2"}}, 0},
+		{"no replicas label", dynamic, clientset.ProvisionListRow{Policy: "dynamic", Factor: 5, ImageList: image}, 0},
+		{"image changed", dynamic, clientset.ProvisionListRow{Policy: "dynamic", Factor: 3, ImageList: []clientset.ImageListEntry{{Name: "nginx", Tag: "1.3"}}}, 1},
+		{"policy changed", dynamic, clientset.ProvisionListRow{Policy: "static", Factor: 3, ImageList: image}, 1},
+		{"static nodes in sync", static, clientset.ProvisionListRow{Policy: "static", ImageList: image, NodeList: []clientset.ServiceNodeInfo{{NodeName: "n2"}, {NodeName: "n1"}}}, 0},
+		{"static nodes changed", static, clientset.ProvisionListRow{Policy: "static", ImageList: image, NodeList: []clientset.ServiceNodeInfo{{NodeName: "n1"}}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := detectDrift(tt.service, &platformService{Row: tt.row})
+			if len(diffs) != tt.want {
+				t.Errorf("detectDrift() = %v, want %d difference(s)", diffs, tt.want)
+			}
+		})
+	}
+}
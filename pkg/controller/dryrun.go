@@ -0,0 +1,28 @@
+// file: pkg/controller/dryrun.go
+
+package controller
+
+import (
+	"fmt"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/klog/v2"
+)
+
+// ReasonDryRun 表示控制器在 dry-run 模式下跳过了一个本应执行的操作
+const ReasonDryRun = "DryRun"
+
+// isDryRun 判断是否只计划、不执行对该服务的操作。
+// 全局的 dry-run 选项和对象上的 DryRunAnnotation 任一生效即可。
+func (c *ECSMServiceController) isDryRun(service *ecsmv1.ECSMService) bool {
+	return c.dryRun || service.Annotations[ecsmv1.DryRunAnnotation] == "true"
+}
+
+// planAction 记录一个在 dry-run 模式下被跳过的操作。
+// 操作会追加到 status.plannedActions 中，并记录一个 DryRun 事件。
+func (c *ECSMServiceController) planAction(service *ecsmv1.ECSMService, format string, args ...interface{}) {
+	action := fmt.Sprintf(format, args...)
+	klog.Infof("Service %s/%s (dry-run): would %s", service.Namespace, service.Name, action)
+	service.Status.PlannedActions = append(service.Status.PlannedActions, action)
+	c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonDryRun, "Would %s", action)
+}
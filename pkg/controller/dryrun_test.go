@@ -0,0 +1,64 @@
+// file: pkg/controller/dryrun_test.go
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncRevisionsDryRun(t *testing.T) {
+	replicas := int32(3)
+	svc := &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "web",
+			Annotations: map[string]string{ecsmv1.DryRunAnnotation: "true"},
+		},
+		Spec: ecsmv1.ECSMServiceSpec{
+			Template:           ecsmv1.ContainerTemplateSpec{Image: "nginx@1.2#linux"},
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeDynamic, Replicas: &replicas},
+		},
+	}
+	hash := computeTemplateHash(&svc.Spec.Template)
+
+	tests := []struct {
+		name       string
+		revisions  []*platformService
+		wantAction string
+	}{
+		{"create", nil, "create platform service web-" + hash + " with 3 replica(s)"},
+		{"scale", []*platformService{{
+			Row:          clientset.ProvisionListRow{Name: "web-" + hash, Factor: 1, ImageList: []clientset.ImageListEntry{{Name: "nginx", Tag: "1.2"}}},
+			TemplateHash: hash,
+		}}, "scale platform service web-" + hash + " from 1 to 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			// ecsmClient 为 nil：dry-run 模式下任何对平台的修改都会导致 panic
+			c := &ECSMServiceController{recorder: recorder, expectations: newControllerExpectations()}
+			service := svc.DeepCopy()
+
+			if _, _, err := c.syncRevisions(context.Background(), service, tt.revisions); err != nil {
+				t.Fatalf("syncRevisions() error = %v", err)
+			}
+			if len(service.Status.PlannedActions) != 1 || !strings.HasPrefix(service.Status.PlannedActions[0], tt.wantAction) {
+				t.Errorf("PlannedActions = %v, want one action starting with %q", service.Status.PlannedActions, tt.wantAction)
+			}
+			if len(recorder.Events) != 1 {
+				t.Fatalf("expected exactly one event, got %d", len(recorder.Events))
+			}
+			if event := <-recorder.Events; !strings.Contains(event, ReasonDryRun) {
+				t.Errorf("event = %q, want a %s event", event, ReasonDryRun)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// file: pkg/controller/errors.go
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// validationRetryDelay 是 ECSM 平台拒绝请求 (4xx) 后的重试间隔。
+// 这类错误通常需要用户修改 spec 或平台配置才能恢复，频繁重试没有意义。
+const validationRetryDelay = 5 * time.Minute
+
+// errorClass 决定了调谐失败后的重试方式
+type errorClass int
+
+const (
+	// errorClassTransient 是网络错误、ECSM 平台 5xx 等暂时性错误，按指数退避重试
+	errorClassTransient errorClass = iota
+	// errorClassConflict 是 Registry 中的写冲突，重新读取对象后立即重试即可解决
+	errorClassConflict
+	// errorClassTimeout 是调谐超时，按指数退避一直重试
+	errorClassTimeout
+	// errorClassValidation 是 ECSM 平台拒绝了请求 (4xx)，间隔较长时间后重试
+	errorClassValidation
+	// errorClassInvalidSpec 是 spec 本身无法被翻译成 ECSM 请求，重试无法修复，直接放弃
+	errorClassInvalidSpec
+)
+
+// specError 表示 ECSMService 的 spec 本身有问题，只有用户修改 spec 之后才可能成功。
+// 修改 spec 会产生新的事件并重新入队，所以控制器不需要重试它。
+type specError struct {
+	err error
+}
+
+func (e *specError) Error() string { return e.err.Error() }
+func (e *specError) Unwrap() error { return e.err }
+
+// newSpecError 创建一个 specError。
+func newSpecError(format string, args ...interface{}) error {
+	return &specError{err: fmt.Errorf(format, args...)}
+}
+
+// isSpecError 判断错误链中是否包含 specError。
+func isSpecError(err error) bool {
+	var se *specError
+	return errors.As(err, &se)
+}
+
+// classifyError 根据错误的类型决定重试方式。ctx 是调谐使用的 context。
+func classifyError(ctx context.Context, err error) errorClass {
+	if reconcileResult(ctx, err) == resultTimeout {
+		return errorClassTimeout
+	}
+	if isSpecError(err) {
+		return errorClassInvalidSpec
+	}
+	if apierrors.IsConflict(err) {
+		return errorClassConflict
+	}
+	if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+		return errorClassValidation
+	}
+
+	var aerr *rest.Aerror
+	if errors.As(err, &aerr) {
+		switch {
+		case aerr.Status == http.StatusTooManyRequests, aerr.Status >= 500:
+			return errorClassTransient
+		case aerr.Status >= 400:
+			return errorClassValidation
+		}
+	}
+	return errorClassTransient
+}
@@ -0,0 +1,37 @@
+// file: pkg/controller/errors_test.go
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want errorClass
+	}{
+		{"network error", errors.New("connection refused"), errorClassTransient},
+		{"ecsm 5xx", fmt.Errorf("update: %w", &rest.Aerror{Status: 503}), errorClassTransient},
+		{"ecsm throttled", &rest.Aerror{Status: 429}, errorClassTransient},
+		{"ecsm rejected", fmt.Errorf("create: %w", &rest.Aerror{Status: 400, Message: "bad image"}), errorClassValidation},
+		{"registry conflict", fmt.Errorf("status: %w", apierrors.NewConflict(ecsmv1.Resource("ecsmservices"), "web", errors.New("modified"))), errorClassConflict},
+		{"invalid spec", fmt.Errorf("sync: %w", newSpecError("invalid memory limit %q", "x")), errorClassInvalidSpec},
+		{"timeout", fmt.Errorf("list: %w", context.DeadlineExceeded), errorClassTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(context.Background(), tt.err); got != tt.want {
+				t.Errorf("classifyError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// file: pkg/controller/expectations.go
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// expectationsTimeout 是一个期望在没有被观察到的情况下保持有效的最长时间。
+// 超时后认为期望已经满足，避免 ECSM 丢失了某个操作时控制器永远卡住。
+const expectationsTimeout = 5 * time.Minute
+
+// revisionExpectations 记录了一个 ECSMService 已经提交、但还没有在平台列表中观察到结果的操作。
+type revisionExpectations struct {
+	// creates 是已提交创建的平台服务名称
+	creates sets.Set[string]
+	// deletes 是已提交删除的平台服务 ID
+	deletes sets.Set[string]
+	// timestamp 是最近一次设置期望的时间
+	timestamp time.Time
+}
+
+// controllerExpectations 是一个以 ECSMService key 为索引的期望缓存，
+// 思路与 Kubernetes ReplicaSet 控制器的 ControllerExpectations 一致。
+//
+// ECSM 的创建和删除是异步事务：接口返回后，平台服务列表可能还没有体现出变化。
+// 如果此时由于 Informer 事件或重新入队而再次调谐，就会重复创建或重复删除。
+// 在期望被满足之前，控制器只更新状态，不再提交新的创建或删除。
+type controllerExpectations struct {
+	lock  sync.Mutex
+	store map[string]*revisionExpectations
+	clock func() time.Time
+}
+
+func newControllerExpectations() *controllerExpectations {
+	return &controllerExpectations{
+		store: make(map[string]*revisionExpectations),
+		clock: time.Now,
+	}
+}
+
+// get 返回 key 对应的期望，不存在时创建一个新的。调用方必须持有锁。
+func (e *controllerExpectations) get(key string) *revisionExpectations {
+	exp, ok := e.store[key]
+	if !ok {
+		exp = &revisionExpectations{creates: sets.New[string](), deletes: sets.New[string]()}
+		e.store[key] = exp
+	}
+	return exp
+}
+
+// expectCreation 记录一个已提交创建的平台服务。
+func (e *controllerExpectations) expectCreation(key, name string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	exp := e.get(key)
+	exp.creates.Insert(name)
+	exp.timestamp = e.clock()
+}
+
+// expectDeletion 记录一个已提交删除的平台服务。
+func (e *controllerExpectations) expectDeletion(key, id string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	exp := e.get(key)
+	exp.deletes.Insert(id)
+	exp.timestamp = e.clock()
+}
+
+// satisfiedExpectations 用最新列出的平台服务核对期望，清除已经观察到的部分，
+// 并返回所有期望是否都已满足 (或已超时)。
+func (e *controllerExpectations) satisfiedExpectations(key string, revisions []*platformService) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	exp, ok := e.store[key]
+	if !ok {
+		return true
+	}
+
+	present := sets.New[string]()
+	for _, rev := range revisions {
+		exp.creates.Delete(rev.Row.Name)
+		present.Insert(rev.Row.ID)
+	}
+	// 不再出现在列表中的服务说明删除已经生效
+	exp.deletes = exp.deletes.Intersection(present)
+
+	if exp.creates.Len() == 0 && exp.deletes.Len() == 0 {
+		delete(e.store, key)
+		return true
+	}
+	if e.clock().Sub(exp.timestamp) > expectationsTimeout {
+		klog.Warningf("Expectations for service %s timed out (pending creates: %v, pending deletes: %v), resuming",
+			key, sets.List(exp.creates), sets.List(exp.deletes))
+		delete(e.store, key)
+		return true
+	}
+	klog.V(2).Infof("Expectations for service %s not yet satisfied (pending creates: %v, pending deletes: %v)",
+		key, sets.List(exp.creates), sets.List(exp.deletes))
+	return false
+}
+
+// deleteExpectations 清除一个 ECSMService 的所有期望，通常在它被删除之后调用。
+func (e *controllerExpectations) deleteExpectations(key string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	delete(e.store, key)
+}
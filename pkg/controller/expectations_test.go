@@ -0,0 +1,47 @@
+// file: pkg/controller/expectations_test.go
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+func TestControllerExpectations(t *testing.T) {
+	now := time.Now()
+	e := newControllerExpectations()
+	e.clock = func() time.Time { return now }
+
+	rev := func(id, name string) *platformService {
+		return &platformService{Row: clientset.ProvisionListRow{ID: id, Name: name}}
+	}
+
+	if !e.satisfiedExpectations("default/web", nil) {
+		t.Fatalf("expected no expectations to be satisfied")
+	}
+
+	e.expectCreation("default/web", "web-new")
+	e.expectDeletion("default/web", "old-id")
+
+	// 新服务还没出现、旧服务还在
+	if e.satisfiedExpectations("default/web", []*platformService{rev("old-id", "web-old")}) {
+		t.Fatalf("expected expectations to be pending")
+	}
+	// 新服务出现了，但旧服务还在
+	if e.satisfiedExpectations("default/web", []*platformService{rev("old-id", "web-old"), rev("new-id", "web-new")}) {
+		t.Fatalf("expected pending deletion to block")
+	}
+	// 旧服务也消失了
+	if !e.satisfiedExpectations("default/web", []*platformService{rev("new-id", "web-new")}) {
+		t.Fatalf("expected expectations to be satisfied")
+	}
+
+	// 期望超时后也被视为满足
+	e.expectCreation("default/web", "web-lost")
+	now = now.Add(expectationsTimeout + time.Second)
+	if !e.satisfiedExpectations("default/web", nil) {
+		t.Fatalf("expected expired expectations to be satisfied")
+	}
+}
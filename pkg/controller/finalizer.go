@@ -0,0 +1,103 @@
+// file: pkg/controller/finalizer.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"k8s.io/klog/v2"
+)
+
+// ensureFinalizer 确保 ECSMService 上带有清理用的 finalizer，返回 (可能被更新过的) 对象。
+func (c *ECSMServiceController) ensureFinalizer(ctx context.Context, service *ecsmv1.ECSMService) (*ecsmv1.ECSMService, error) {
+	if containsString(service.Finalizers, ecsmv1.ServiceCleanupFinalizer) {
+		return service, nil
+	}
+	toUpdate := service.DeepCopy()
+	toUpdate.Finalizers = append(toUpdate.Finalizers, ecsmv1.ServiceCleanupFinalizer)
+	updated, err := c.registry.UpdateService(ctx, toUpdate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add finalizer: %w", err)
+	}
+	// Registry 返回的是已存储的 status，保留本轮调谐中尚未写回的修改
+	updated.Status = *service.Status.DeepCopy()
+	return updated, nil
+}
+
+// finalizeService 在 ECSMService 被标记删除后，删除它在 ECSM 平台上的所有修订版本。
+// 删除事务会被记录到状态中，等到它们完成、平台上不再有任何修订版本后，
+// 才移除 finalizer，让 Registry 真正删除该对象。
+func (c *ECSMServiceController) finalizeService(ctx context.Context, service *ecsmv1.ECSMService, originalStatus *ecsmv1.ECSMServiceStatus) error {
+	if !containsString(service.Finalizers, ecsmv1.ServiceCleanupFinalizer) {
+		// 不归我们清理，等待其他 finalizer 的持有者
+		return nil
+	}
+
+	revisions, err := c.listPlatformServices(ctx, service)
+	if err != nil {
+		return fmt.Errorf("failed to list platform services for cleanup: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		// 所有平台服务都已清理完毕，移除 finalizer
+		toUpdate := service.DeepCopy()
+		toUpdate.Finalizers = removeString(toUpdate.Finalizers, ecsmv1.ServiceCleanupFinalizer)
+		if _, err := c.registry.UpdateService(ctx, toUpdate); err != nil {
+			return fmt.Errorf("failed to remove finalizer: %w", err)
+		}
+		c.expectations.deleteExpectations(serviceKey(service))
+		klog.Infof("Service %s/%s: platform services cleaned up, finalizer removed", service.Namespace, service.Name)
+		return nil
+	}
+
+	if c.isDryRun(service) {
+		// 不删除平台服务，也不移除 finalizer：关闭 dry-run 之后清理才会真正执行
+		for _, rev := range revisions {
+			c.planAction(service, "delete platform service %s", rev.Row.Name)
+		}
+		return c.updateStatus(ctx, service, originalStatus)
+	}
+
+	for _, rev := range revisions {
+		klog.Infof("Service %s/%s is being deleted, deleting platform service %s", service.Namespace, service.Name, rev.Row.Name)
+		resp, err := c.clientFor(service).Services().Delete(ctx, rev.Row.ID)
+		if rest.IsNotFound(err) {
+			// 平台服务已经被其它途径删除
+			klog.V(2).Infof("Service %s/%s: platform service %s is already gone", service.Namespace, service.Name, rev.Row.Name)
+			continue
+		}
+		if err != nil {
+			c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonCleanupFailed,
+				"Failed to delete platform service %s: %v", rev.Row.Name, err)
+			return fmt.Errorf("failed to delete platform service %s: %w", rev.Row.Name, err)
+		}
+		c.trackTransaction(service, resp.ID, operationDelete, rev.Row.Name)
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonScaledDown, "Deleting platform service %s", rev.Row.Name)
+	}
+
+	// 等删除事务完成后再检查一次，届时平台上应该已经没有任何修订版本
+	c.queue.AddAfter(serviceKey(service), transactionRequeueInterval)
+	return c.updateStatus(ctx, service, originalStatus)
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(slice []string, s string) []string {
+	var result []string
+	for _, item := range slice {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
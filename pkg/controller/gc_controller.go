@@ -0,0 +1,193 @@
+// file: pkg/controller/gc_controller.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultGCPeriod 是垃圾回收器默认的扫描周期
+	DefaultGCPeriod = time.Minute
+	// DefaultGCGracePeriod 是平台服务被发现没有属主之后、被删除之前默认的等待时间
+	DefaultGCGracePeriod = 10 * time.Minute
+
+	// gcSyncTimeout 是一次扫描的最长时间
+	gcSyncTimeout = time.Minute
+
+	// gcControllerName 是 GarbageCollector 在指标中的名称
+	gcControllerName = "gc"
+)
+
+// GarbageCollectorOptions 包含 GarbageCollector 的可调参数。
+type GarbageCollectorOptions struct {
+	// Period 是扫描平台服务的周期
+	Period time.Duration
+	// GracePeriod 是一个平台服务需要持续没有属主多久才会被删除。
+	// 它用于容忍 Registry 与平台之间短暂的不一致，例如一个 ECSMService 被删除后又以相同的名称重建。
+	GracePeriod time.Duration
+	// DryRun 为 true 时只记录日志，不删除任何平台服务
+	DryRun bool
+}
+
+// DefaultGarbageCollectorOptions 返回 GarbageCollector 的默认参数。
+func DefaultGarbageCollectorOptions() GarbageCollectorOptions {
+	return GarbageCollectorOptions{
+		Period:      DefaultGCPeriod,
+		GracePeriod: DefaultGCGracePeriod,
+	}
+}
+
+// GarbageCollector 周期性地扫描 ECSM 平台上带有 operator 属主标签的服务，
+// 删除那些属主已经不在 Registry 中的服务。
+// 正常情况下 finalizer 会在 ECSMService 被删除前清理掉它的平台服务；
+// 垃圾回收器负责兜底，例如 operator 在创建平台服务的中途崩溃、或 Registry 被手动修改之后留下的服务。
+type GarbageCollector struct {
+	ecsmClient clientset.Interface
+	registry   registry.Interface
+
+	opts  GarbageCollectorOptions
+	clock func() time.Time
+
+	// orphanedSince 记录了每个无主平台服务 (以 ID 为索引) 第一次被发现的时间
+	orphanedSince map[string]time.Time
+	lock          sync.Mutex
+}
+
+// NewGarbageCollector 创建一个新的 GarbageCollector。
+func NewGarbageCollector(ecsmClient clientset.Interface, reg registry.Interface, opts GarbageCollectorOptions) *GarbageCollector {
+	return &GarbageCollector{
+		ecsmClient:    ecsmClient,
+		registry:      reg,
+		opts:          opts,
+		clock:         time.Now,
+		orphanedSince: make(map[string]time.Time),
+	}
+}
+
+// Run 启动周期性扫描，直到 stopCh 被关闭。
+func (gc *GarbageCollector) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting garbage collector")
+	defer klog.Info("Shutting down garbage collector")
+
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), gcSyncTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := gc.collect(ctx)
+		result := reconcileResult(ctx, err)
+		reconcileTotal.WithLabelValues(gcControllerName, result).Inc()
+		reconcileDuration.WithLabelValues(gcControllerName).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("garbage collection failed: %w", err))
+		}
+	}, gc.opts.Period, stopCh)
+}
+
+// collect 执行一次扫描，删除无主时间超过宽限期的平台服务。
+func (gc *GarbageCollector) collect(ctx context.Context) error {
+	// 先列出平台服务，再列出 Registry：ECSMService 和 ECSMJob 总是先于它们的平台服务被创建，
+	// 按这个顺序列出，刚刚创建的平台服务一定能在 Registry 中找到属主
+	rows, err := gc.ecsmClient.Services().ListAll(ctx, clientset.ListServicesOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list platform services: %w", err)
+	}
+	services, _, err := gc.registry.ListAllServices(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMServices: %w", err)
+	}
+	jobs, _, err := gc.registry.ListJobs(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMJobs: %w", err)
+	}
+
+	owners := make(map[string]bool, len(services.Items)+len(jobs.Items))
+	for _, svc := range services.Items {
+		owners[string(svc.UID)] = true
+	}
+	for _, job := range jobs.Items {
+		owners[string(job.UID)] = true
+	}
+
+	var errs []error
+	for _, row := range gc.dueForDeletion(orphanedRows(rows, owners)) {
+		owner := rowLabels(row)[LabelOwnerName]
+		if gc.opts.DryRun {
+			klog.Infof("Garbage collector (dry-run): would delete platform service %s (%s), its owner %s no longer exists", row.Name, row.ID, owner)
+			continue
+		}
+		klog.Infof("Garbage collector: deleting platform service %s (%s), its owner %s no longer exists", row.Name, row.ID, owner)
+		if _, err := gc.ecsmClient.Services().Delete(ctx, row.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete platform service %s: %w", row.Name, err))
+			continue
+		}
+		gc.forget(row.ID)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// orphanedRows 返回带有属主标签、但属主 UID 不在 owners 中的平台服务。
+// 没有属主标签的平台服务不归 operator 管理，永远不会被回收。
+func orphanedRows(rows []clientset.ProvisionListRow, owners map[string]bool) []clientset.ProvisionListRow {
+	var orphans []clientset.ProvisionListRow
+	for _, row := range rows {
+		uid, ok := rowLabels(row)[LabelOwnerUID]
+		if !ok || uid == "" || owners[uid] {
+			continue
+		}
+		orphans = append(orphans, row)
+	}
+	return orphans
+}
+
+// dueForDeletion 记录本次发现的无主平台服务，返回其中无主时间已经超过宽限期的那些。
+// 不再无主 (被重新认领或已被删除) 的平台服务会被遗忘，下次发现时重新计时。
+func (gc *GarbageCollector) dueForDeletion(orphans []clientset.ProvisionListRow) []clientset.ProvisionListRow {
+	gc.lock.Lock()
+	defer gc.lock.Unlock()
+
+	now := gc.clock()
+	seen := make(map[string]bool, len(orphans))
+	var due []clientset.ProvisionListRow
+	for _, row := range orphans {
+		seen[row.ID] = true
+		since, ok := gc.orphanedSince[row.ID]
+		if !ok {
+			klog.V(2).Infof("Garbage collector: platform service %s (%s) has no owner, deleting it after %v", row.Name, row.ID, gc.opts.GracePeriod)
+			gc.orphanedSince[row.ID] = now
+			since = now
+		}
+		if now.Sub(since) >= gc.opts.GracePeriod {
+			due = append(due, row)
+		}
+	}
+	for id := range gc.orphanedSince {
+		if !seen[id] {
+			delete(gc.orphanedSince, id)
+		}
+	}
+	return due
+}
+
+func (gc *GarbageCollector) forget(id string) {
+	gc.lock.Lock()
+	defer gc.lock.Unlock()
+	delete(gc.orphanedSince, id)
+}
@@ -0,0 +1,51 @@
+// file: pkg/controller/gc_controller_test.go
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+func TestOrphanedRows(t *testing.T) {
+	rows := []clientset.ProvisionListRow{
+		{ID: "1", Name: "owned", Labels: []string{LabelOwnerUID + "=uid-1"}},
+		{ID: "2", Name: "orphan", Labels: []string{LabelOwnerUID + "=uid-2"}},
+		{ID: "3", Name: "unmanaged", Labels: []string{"app=web"}},
+	}
+	orphans := orphanedRows(rows, map[string]bool{"uid-1": true})
+	if len(orphans) != 1 || orphans[0].ID != "2" {
+		t.Errorf("orphanedRows() = %v, want only the platform service with an unknown owner", orphans)
+	}
+}
+
+func TestGarbageCollectorGracePeriod(t *testing.T) {
+	now := time.Unix(1000, 0)
+	gc := NewGarbageCollector(nil, nil, GarbageCollectorOptions{GracePeriod: time.Minute})
+	gc.clock = func() time.Time { return now }
+
+	orphan := clientset.ProvisionListRow{ID: "1", Name: "orphan"}
+
+	if due := gc.dueForDeletion([]clientset.ProvisionListRow{orphan}); len(due) != 0 {
+		t.Fatalf("orphan deleted when first seen: %v", due)
+	}
+
+	now = now.Add(30 * time.Second)
+	if due := gc.dueForDeletion([]clientset.ProvisionListRow{orphan}); len(due) != 0 {
+		t.Fatalf("orphan deleted before the grace period expired: %v", due)
+	}
+
+	// 在宽限期内被重新认领，计时被重置
+	gc.dueForDeletion(nil)
+	now = now.Add(45 * time.Second)
+	if due := gc.dueForDeletion([]clientset.ProvisionListRow{orphan}); len(due) != 0 {
+		t.Fatalf("grace period not reset after the orphan was claimed: %v", due)
+	}
+
+	now = now.Add(time.Minute)
+	if due := gc.dueForDeletion([]clientset.ProvisionListRow{orphan}); len(due) != 1 {
+		t.Fatalf("orphan not deleted after the grace period: %v", due)
+	}
+}
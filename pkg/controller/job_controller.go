@@ -0,0 +1,319 @@
+// file: pkg/controller/job_controller.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultJobSyncPeriod 是 JobController 默认的同步周期
+	DefaultJobSyncPeriod = 10 * time.Second
+
+	// defaultJobBackoffLimit 是 spec.backoffLimit 的默认值，与 Kubernetes Job 一致
+	defaultJobBackoffLimit = 6
+
+	// jobSyncTimeout 是一次全量同步的最长时间
+	jobSyncTimeout = time.Minute
+
+	// jobControllerName 是 JobController 在指标中的名称
+	jobControllerName = "job"
+
+	// LabelOwnerKind 记录了平台服务属主的类型。ECSMService 的平台服务没有这个标签。
+	LabelOwnerKind = "ecsm.sh/owner-kind"
+
+	// jobOwnerKind 是 ECSMJob 的平台服务上 LabelOwnerKind 的值
+	jobOwnerKind = "ECSMJob"
+)
+
+// 作业相关的事件和状况原因
+const (
+	ReasonJobStarted           = "Started"
+	ReasonJobCompleted         = "Completed"
+	ReasonBackoffLimitExceeded = "BackoffLimitExceeded"
+	ReasonDeadlineExceeded     = "DeadlineExceeded"
+)
+
+// JobController 周期性地同步所有 ECSMJob：为尚未开始的作业创建平台服务，
+// 统计平台服务下容器的成功和失败次数，并在作业完成、失败或超时后记录最终状态。
+type JobController struct {
+	clusters *ClusterClients
+	registry registry.Interface
+	recorder record.EventRecorder
+
+	syncPeriod time.Duration
+	clock      func() time.Time
+}
+
+// NewJobController 创建一个新的 JobController。
+func NewJobController(
+	clusters *ClusterClients,
+	reg registry.Interface,
+	recorder record.EventRecorder,
+	syncPeriod time.Duration,
+) *JobController {
+	return &JobController{
+		clusters:   clusters,
+		registry:   reg,
+		recorder:   recorder,
+		syncPeriod: syncPeriod,
+		clock:      time.Now,
+	}
+}
+
+// Run 启动周期性同步，直到 stopCh 被关闭。
+func (c *JobController) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting job controller")
+	defer klog.Info("Shutting down job controller")
+
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), jobSyncTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := c.syncAll(ctx)
+		result := reconcileResult(ctx, err)
+		reconcileTotal.WithLabelValues(jobControllerName, result).Inc()
+		reconcileDuration.WithLabelValues(jobControllerName).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("failed to sync jobs: %w", err))
+		}
+	}, c.syncPeriod, stopCh)
+}
+
+// syncAll 同步所有尚未结束的 ECSMJob。
+func (c *JobController) syncAll(ctx context.Context) error {
+	jobs, _, err := c.registry.ListJobs(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMJobs: %w", err)
+	}
+
+	var errs []error
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if isJobFinished(job) {
+			continue
+		}
+		if err := c.syncJob(ctx, job); err != nil {
+			errs = append(errs, fmt.Errorf("job %s/%s: %w", job.Namespace, job.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// syncJob 推进一个作业，并在状态变化时写回 Registry。
+func (c *JobController) syncJob(ctx context.Context, job *ecsmv1.ECSMJob) error {
+	newStatus := job.Status.DeepCopy()
+	syncErr := c.reconcileJob(ctx, job, newStatus)
+
+	if reflect.DeepEqual(&job.Status, newStatus) {
+		return syncErr
+	}
+	toUpdate := job.DeepCopy()
+	toUpdate.Status = *newStatus
+	if _, err := c.registry.UpdateJobStatus(ctx, toUpdate); err != nil {
+		return fmt.Errorf("failed to update ECSMJob status: %w", err)
+	}
+	return syncErr
+}
+
+// reconcileJob 完成一次作业的调谐，结果写入 status。
+func (c *JobController) reconcileJob(ctx context.Context, job *ecsmv1.ECSMJob, status *ecsmv1.ECSMJobStatus) error {
+	now := c.clock()
+	if status.StartTime == nil {
+		start := metav1.NewTime(now)
+		status.StartTime = &start
+	}
+
+	service := jobService(job)
+	if err := resolveTemplateConfigs(ctx, c.registry, job.Namespace, &service.Spec.Template); err != nil {
+		c.recorder.Eventf(job, ecsmv1.EventTypeWarning, ReasonConfigError, "%v", err)
+		return err
+	}
+	ecsmClient, err := c.clusters.ForService(service)
+	if err != nil {
+		return err
+	}
+	rows, err := listJobRows(ctx, ecsmClient, job)
+	if err != nil {
+		return err
+	}
+
+	if deadline := job.Spec.ActiveDeadlineSeconds; deadline != nil && now.Sub(status.StartTime.Time) > time.Duration(*deadline)*time.Second {
+		msg := fmt.Sprintf("Job was active longer than the deadline of %ds", *deadline)
+		return c.failJob(ctx, ecsmClient, job, status, rows, ReasonDeadlineExceeded, msg)
+	}
+
+	if len(rows) == 0 {
+		if status.UnderlyingServiceID != "" {
+			// 平台服务已经提交，但还没有出现在列表中
+			klog.V(2).Infof("Job %s/%s: waiting for platform service %s to appear", job.Namespace, job.Name, status.UnderlyingServiceID)
+			return nil
+		}
+		return c.startJob(ctx, ecsmClient, job, service, status)
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	status.UnderlyingServiceID = rows[0].ID
+	containers, err := ecsmClient.Containers().ListAllByService(ctx, clientset.ListContainersByServiceOptions{ServiceIDs: ids})
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	status.Active, status.Succeeded, status.Failed = countJobContainers(containers)
+
+	switch {
+	case status.Failed > jobBackoffLimit(job):
+		msg := fmt.Sprintf("Job has failed %d time(s), more than the backoff limit of %d", status.Failed, jobBackoffLimit(job))
+		return c.failJob(ctx, ecsmClient, job, status, rows, ReasonBackoffLimitExceeded, msg)
+	case status.Succeeded >= jobCompletions(job):
+		completed := metav1.NewTime(now)
+		status.CompletionTime = &completed
+		status.Active = 0
+		msg := fmt.Sprintf("Job completed with %d successful container(s)", status.Succeeded)
+		setJobCondition(status, ecsmv1.JobComplete, ReasonJobCompleted, msg)
+		c.recorder.Event(job, ecsmv1.EventTypeNormal, ReasonJobCompleted, msg)
+	}
+	return nil
+}
+
+// startJob 创建运行作业的平台服务。
+func (c *JobController) startJob(ctx context.Context, ecsmClient clientset.Interface, job *ecsmv1.ECSMJob, service *ecsmv1.ECSMService, status *ecsmv1.ECSMJobStatus) error {
+	completions := jobCompletions(job)
+	req, err := buildCreateServiceRequest(service, computeTemplateHash(&service.Spec.Template), completions)
+	if err != nil {
+		c.recorder.Eventf(job, ecsmv1.EventTypeWarning, ReasonInvalidSpec, "%v", err)
+		return err
+	}
+	req.Labels = append(req.Labels, LabelOwnerKind+"="+jobOwnerKind)
+
+	resp, err := ecsmClient.Services().Create(ctx, req)
+	if err != nil {
+		c.recorder.Eventf(job, ecsmv1.EventTypeWarning, ReasonCreateFailed, "Failed to create platform service %s: %v", req.Name, err)
+		return fmt.Errorf("failed to create platform service %s: %w", req.Name, err)
+	}
+	status.UnderlyingServiceID = resp.ID
+	klog.Infof("Job %s/%s: created platform service %s with %d completion(s)", job.Namespace, job.Name, req.Name, completions)
+	c.recorder.Eventf(job, ecsmv1.EventTypeNormal, ReasonJobStarted, "Created platform service %s with %d container(s)", req.Name, completions)
+	return nil
+}
+
+// failJob 删除作业的平台服务以停止 ECSM 继续重启容器，并把作业标记为失败。
+func (c *JobController) failJob(ctx context.Context, ecsmClient clientset.Interface, job *ecsmv1.ECSMJob, status *ecsmv1.ECSMJobStatus, rows []clientset.ProvisionListRow, reason, msg string) error {
+	for _, row := range rows {
+		if _, err := ecsmClient.Services().Delete(ctx, row.ID); err != nil {
+			return fmt.Errorf("failed to delete platform service %s: %w", row.Name, err)
+		}
+	}
+	status.Active = 0
+	setJobCondition(status, ecsmv1.JobFailed, reason, msg)
+	klog.Infof("Job %s/%s failed: %s", job.Namespace, job.Name, msg)
+	c.recorder.Event(job, ecsmv1.EventTypeWarning, reason, msg)
+	return nil
+}
+
+// jobService 把作业表示为一个 Dynamic 策略的 ECSMService，以复用构造 ECSM 请求和选择集群的逻辑。
+// 它只存在于内存中，不会被写入 Registry。
+func jobService(job *ecsmv1.ECSMJob) *ecsmv1.ECSMService {
+	return &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   job.Namespace,
+			Name:        job.Name,
+			UID:         job.UID,
+			Annotations: job.Annotations,
+		},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:     ecsmv1.DeploymentStrategyTypeDynamic,
+				NodePool: job.Spec.NodePool,
+			},
+			Template: *job.Spec.Template.DeepCopy(),
+		},
+	}
+}
+
+// listJobRows 通过属主标签列出作业的平台服务。
+func listJobRows(ctx context.Context, ecsmClient clientset.Interface, job *ecsmv1.ECSMJob) ([]clientset.ProvisionListRow, error) {
+	uid := string(job.UID)
+	rows, err := ecsmClient.Services().ListAll(ctx, clientset.ListServicesOptions{Label: LabelOwnerUID + "=" + uid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list platform services: %w", err)
+	}
+	var owned []clientset.ProvisionListRow
+	for _, row := range rows {
+		// ECSM 的标签过滤不一定是精确匹配，这里再检查一次
+		if rowLabels(row)[LabelOwnerUID] == uid {
+			owned = append(owned, row)
+		}
+	}
+	return owned, nil
+}
+
+// countJobContainers 统计作业容器中运行中、成功和失败的数量。
+// ECSM 没有暴露容器的退出码，这里假设失败的容器带有 failedMessage 或处于 "failure" 状态，
+// 其他已经停止的容器都是正常退出的。每次被 ECSM 重启也算作一次失败。
+func countJobContainers(containers []clientset.ContainerInfo) (active, succeeded, failed int32) {
+	for _, co := range containers {
+		failed += int32(co.RestartCount)
+		switch {
+		case co.Status == "failure" || (co.FailedMessage != nil && *co.FailedMessage != ""):
+			failed++
+		case co.Status == "exited" || co.Status == "stopped" || co.Status == "success":
+			succeeded++
+		default:
+			active++
+		}
+	}
+	return active, succeeded, failed
+}
+
+// isJobFinished 判断作业是否已经完成或失败。
+func isJobFinished(job *ecsmv1.ECSMJob) bool {
+	return ecsmmeta.IsConditionTrue(job.Status.Conditions, ecsmv1.JobComplete) ||
+		ecsmmeta.IsConditionTrue(job.Status.Conditions, ecsmv1.JobFailed)
+}
+
+func jobCompletions(job *ecsmv1.ECSMJob) int32 {
+	if job.Spec.Completions != nil && *job.Spec.Completions > 0 {
+		return *job.Spec.Completions
+	}
+	return 1
+}
+
+func jobBackoffLimit(job *ecsmv1.ECSMJob) int32 {
+	if job.Spec.BackoffLimit != nil && *job.Spec.BackoffLimit >= 0 {
+		return *job.Spec.BackoffLimit
+	}
+	return defaultJobBackoffLimit
+}
+
+func setJobCondition(status *ecsmv1.ECSMJobStatus, condType, reason, message string) {
+	ecsmmeta.SetCondition(&status.Conditions, metav1.Condition{
+		Type:    condType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+}
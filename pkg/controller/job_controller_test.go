@@ -0,0 +1,26 @@
+// file: pkg/controller/job_controller_test.go
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+func TestCountJobContainers(t *testing.T) {
+	failedMessage := "exit code 1"
+	containers := []clientset.ContainerInfo{
+		{Status: "running"},
+		{Status: "running", RestartCount: 2},
+		{Status: "exited"},
+		{Status: "stopped"},
+		{Status: "failure"},
+		{Status: "exited", FailedMessage: &failedMessage},
+	}
+
+	active, succeeded, failed := countJobContainers(containers)
+	if active != 2 || succeeded != 2 || failed != 4 {
+		t.Errorf("countJobContainers() = (%d, %d, %d), want (2, 2, 4)", active, succeeded, failed)
+	}
+}
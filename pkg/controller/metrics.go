@@ -0,0 +1,52 @@
+// file: pkg/controller/metrics.go
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fx147/ecsm-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 调谐结果，作为 result 标签的值
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+	resultTimeout = "timeout"
+)
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "controller",
+		Name:      "reconcile_total",
+		Help:      "Total number of reconciliations per controller and result (success, error, timeout).",
+	}, []string{"controller", "result"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "controller",
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken by reconciliations per controller.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"controller"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileTotal, reconcileDuration)
+}
+
+// reconcileResult 把一次调谐返回的错误归类为 success、error 或 timeout。
+// ctx 是调谐使用的 context：即使错误链中丢失了 DeadlineExceeded，只要 ctx 已超时也算作 timeout。
+func reconcileResult(ctx context.Context, err error) string {
+	switch {
+	case err == nil:
+		return resultSuccess
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return resultTimeout
+	default:
+		return resultError
+	}
+}
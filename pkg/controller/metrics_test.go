@@ -0,0 +1,35 @@
+// file: pkg/controller/metrics_test.go
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReconcileResult(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want string
+	}{
+		{"success", context.Background(), nil, resultSuccess},
+		{"error", context.Background(), errors.New("boom"), resultError},
+		{"wrapped deadline", context.Background(), fmt.Errorf("list: %w", context.DeadlineExceeded), resultTimeout},
+		{"expired context with opaque error", expired, errors.New("request failed"), resultTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reconcileResult(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("reconcileResult() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
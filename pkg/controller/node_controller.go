@@ -0,0 +1,337 @@
+// file: pkg/controller/node_controller.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultNodeSyncPeriod 是 NodeController 默认的同步周期
+	DefaultNodeSyncPeriod = 10 * time.Second
+
+	// nodeHeartbeatGracePeriod 是节点在没有上报新指标的情况下仍被视为 Ready 的最长时间，
+	// 与 Kubernetes node-monitor-grace-period 的默认值一致。
+	nodeHeartbeatGracePeriod = 40 * time.Second
+
+	// nodeSyncTimeout 是一次全量同步的最长时间
+	nodeSyncTimeout = time.Minute
+
+	// nodeControllerName 是 NodeController 在指标中的名称
+	nodeControllerName = "node"
+)
+
+// 节点 Ready 状况的原因
+const (
+	nodeReasonReady            = "NodeReady"
+	nodeReasonOffline          = "NodeOffline"
+	nodeReasonHeartbeatTimeout = "HeartbeatTimeout"
+
+	// ReasonNodeReady 和 ReasonNodeNotReady 是节点 Ready 状况发生变化时记录的事件原因
+	ReasonNodeReady    = "NodeReady"
+	ReasonNodeNotReady = "NodeNotReady"
+)
+
+// nodeHeartbeat 记录了控制器对一个节点心跳的观察
+type nodeHeartbeat struct {
+	// metricsTimestamp 是节点最近一次上报的指标中的时间戳
+	metricsTimestamp int64
+	// observedAt 是控制器观察到该时间戳发生变化的本地时间
+	observedAt time.Time
+}
+
+// NodeController 周期性地列出 ECSM 平台上的节点，并在 Registry 中创建和更新对应的 ECSMNode 对象。
+// 与 ECSMServiceController 不同，节点的 "期望" 就是平台上的现实，所以它不需要工作队列，
+// 每个周期做一次全量同步即可。
+type NodeController struct {
+	ecsmClient clientset.Interface
+	registry   registry.Interface
+	recorder   record.EventRecorder
+
+	syncPeriod  time.Duration
+	gracePeriod time.Duration
+	clock       func() time.Time
+
+	// heartbeats 以平台节点 ID 为索引。
+	// 我们比较指标时间戳是否变化，而不是直接比较时间戳与本地时间，以避免节点与控制器之间的时钟偏差。
+	heartbeats     map[string]nodeHeartbeat
+	heartbeatsLock sync.Mutex
+}
+
+// NewNodeController 创建一个新的 NodeController。
+func NewNodeController(
+	ecsmClient clientset.Interface,
+	reg registry.Interface,
+	recorder record.EventRecorder,
+	syncPeriod time.Duration,
+) *NodeController {
+	return &NodeController{
+		ecsmClient:  ecsmClient,
+		registry:    reg,
+		recorder:    recorder,
+		syncPeriod:  syncPeriod,
+		gracePeriod: nodeHeartbeatGracePeriod,
+		clock:       time.Now,
+		heartbeats:  make(map[string]nodeHeartbeat),
+	}
+}
+
+// Run 启动周期性同步，直到 stopCh 被关闭。
+func (c *NodeController) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting node controller")
+	defer klog.Info("Shutting down node controller")
+
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), nodeSyncTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := c.syncNodes(ctx)
+		result := reconcileResult(ctx, err)
+		reconcileTotal.WithLabelValues(nodeControllerName, result).Inc()
+		reconcileDuration.WithLabelValues(nodeControllerName).Observe(time.Since(start).Seconds())
+
+		switch result {
+		case resultTimeout:
+			klog.Warningf("Syncing nodes timed out after %v: %v", nodeSyncTimeout, err)
+		case resultError:
+			runtime.HandleError(fmt.Errorf("failed to sync nodes: %w", err))
+		}
+	}, c.syncPeriod, stopCh)
+}
+
+// syncNodes 执行一次全量同步。
+func (c *NodeController) syncNodes(ctx context.Context) error {
+	platformNodes, err := c.ecsmClient.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list platform nodes: %w", err)
+	}
+
+	ids := make([]string, 0, len(platformNodes))
+	for _, n := range platformNodes {
+		ids = append(ids, n.ID)
+	}
+	statusByID := make(map[string]*clientset.NodeStatus)
+	if len(ids) > 0 {
+		statuses, err := c.ecsmClient.Nodes().ListStatus(ctx, ids)
+		if err != nil {
+			// 运行时状态只用于填充容量，获取失败不影响节点本身的同步
+			klog.Warningf("Failed to get runtime status of nodes: %v", err)
+		}
+		for i := range statuses {
+			statusByID[statuses[i].ID] = &statuses[i]
+		}
+	}
+
+	existing, _, err := c.registry.ListNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMNodes: %w", err)
+	}
+	existingByName := make(map[string]*ecsmv1.ECSMNode, len(existing.Items))
+	for i := range existing.Items {
+		existingByName[existing.Items[i].Name] = &existing.Items[i]
+	}
+
+	seen := make(map[string]bool, len(platformNodes))
+	var errs []error
+	for _, pn := range platformNodes {
+		if seen[pn.Name] {
+			klog.Warningf("Multiple platform nodes are named %q, ignoring node %s", pn.Name, pn.ID)
+			continue
+		}
+		seen[pn.Name] = true
+
+		if err := c.syncNode(ctx, pn, statusByID[pn.ID], existingByName[pn.Name]); err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", pn.Name, err))
+		}
+	}
+
+	// 平台上已经不存在的节点，删除其 ECSMNode
+	for name, node := range existingByName {
+		if seen[name] || node.Status.NodeID == "" {
+			continue
+		}
+		klog.Infof("Platform node %s (%s) no longer exists, deleting ECSMNode", name, node.Status.NodeID)
+		if err := c.registry.DeleteNode(ctx, name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete ECSMNode %s: %w", name, err))
+		}
+		c.forgetHeartbeat(node.Status.NodeID)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// syncNode 确保一个平台节点在 Registry 中有对应的 ECSMNode，且其状态是最新的。
+func (c *NodeController) syncNode(ctx context.Context, pn clientset.NodeInfo, status *clientset.NodeStatus, node *ecsmv1.ECSMNode) error {
+	var err error
+	if node == nil {
+		node, err = c.registry.CreateNode(ctx, &ecsmv1.ECSMNode{
+			ObjectMeta: metav1.ObjectMeta{Name: pn.Name},
+			Spec:       ecsmv1.ECSMNodeSpec{Address: pn.Address},
+		})
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create ECSMNode: %w", err)
+		}
+		if err != nil {
+			if node, err = c.registry.GetNode(ctx, pn.Name); err != nil {
+				return err
+			}
+		}
+		klog.Infof("Created ECSMNode %s for platform node %s", pn.Name, pn.ID)
+	} else if node.Spec.Address != pn.Address {
+		toUpdate := node.DeepCopy()
+		toUpdate.Spec.Address = pn.Address
+		if node, err = c.registry.UpdateNode(ctx, toUpdate); err != nil {
+			return fmt.Errorf("failed to update ECSMNode: %w", err)
+		}
+	}
+
+	lastHeartbeat := c.observeHeartbeat(ctx, pn.ID)
+	newStatus := c.computeNodeStatus(node, pn, status, lastHeartbeat)
+	newStatus.EcsdVersion = c.ecsdVersion(ctx, pn, node)
+
+	wasReady := ecsmmeta.IsConditionTrue(node.Status.Conditions, ecsmv1.NodeReady)
+	isReady := ecsmmeta.IsConditionTrue(newStatus.Conditions, ecsmv1.NodeReady)
+	if wasReady != isReady && (len(node.Status.Conditions) > 0 || !isReady) {
+		cond := ecsmmeta.GetCondition(newStatus.Conditions, ecsmv1.NodeReady)
+		if isReady {
+			c.recorder.Event(node, ecsmv1.EventTypeNormal, ReasonNodeReady, cond.Message)
+		} else {
+			c.recorder.Event(node, ecsmv1.EventTypeWarning, ReasonNodeNotReady, cond.Message)
+		}
+	}
+
+	if reflect.DeepEqual(node.Status, newStatus) {
+		return nil
+	}
+	toUpdate := node.DeepCopy()
+	toUpdate.Status = newStatus
+	if _, err := c.registry.UpdateNodeStatus(ctx, toUpdate); err != nil {
+		return fmt.Errorf("failed to update ECSMNode status: %w", err)
+	}
+	return nil
+}
+
+// observeHeartbeat 拉取节点的即时指标，返回控制器最近一次观察到新指标的时间 (从未观察到时为零值)。
+func (c *NodeController) observeHeartbeat(ctx context.Context, nodeID string) time.Time {
+	metrics, err := c.ecsmClient.Nodes().GetNodeMetrics(ctx, clientset.NodeMetricsOptions{NodeID: nodeID, Instant: true})
+
+	c.heartbeatsLock.Lock()
+	defer c.heartbeatsLock.Unlock()
+
+	hb, seen := c.heartbeats[nodeID]
+	if err != nil || len(metrics) == 0 {
+		klog.V(4).Infof("No metrics for node %s: %v", nodeID, err)
+		return hb.observedAt
+	}
+	if !seen || metrics[0].Timestamp != hb.metricsTimestamp {
+		hb = nodeHeartbeat{metricsTimestamp: metrics[0].Timestamp, observedAt: c.clock()}
+		c.heartbeats[nodeID] = hb
+	}
+	return hb.observedAt
+}
+
+func (c *NodeController) forgetHeartbeat(nodeID string) {
+	c.heartbeatsLock.Lock()
+	defer c.heartbeatsLock.Unlock()
+	delete(c.heartbeats, nodeID)
+}
+
+// computeNodeStatus 根据平台上的节点信息和心跳，计算 ECSMNode 的新状态。
+func (c *NodeController) computeNodeStatus(node *ecsmv1.ECSMNode, pn clientset.NodeInfo, status *clientset.NodeStatus, lastHeartbeat time.Time) ecsmv1.ECSMNodeStatus {
+	newStatus := ecsmv1.ECSMNodeStatus{
+		NodeID: pn.ID,
+		Type:   pn.Type,
+		Arch:   pn.Arch,
+		Containers: ecsmv1.NodeContainerCounts{
+			Total:          int32(pn.ContainerTotal),
+			Running:        int32(pn.ContainerRunning),
+			ManagedTotal:   int32(pn.ContainerEcsmTotal),
+			ManagedRunning: int32(pn.ContainerEcsmRunning),
+		},
+		Capacity:   node.Status.Capacity,
+		Conditions: append([]metav1.Condition(nil), node.Status.Conditions...),
+	}
+	if !lastHeartbeat.IsZero() {
+		newStatus.LastHeartbeatTime = metav1.NewTime(lastHeartbeat)
+	}
+	if status != nil {
+		// 假设平台返回的内存和磁盘总量均以字节为单位
+		newStatus.Capacity = map[ecsmv1.ResourceType]resource.Quantity{
+			ecsmv1.ResourceTypeMemory: *resource.NewQuantity(status.MemoryTotal, resource.BinarySI),
+			ecsmv1.ResourceTypeDisk:   *resource.NewQuantity(int64(status.DiskTotal), resource.BinarySI),
+		}
+	}
+
+	ecsmmeta.SetCondition(&newStatus.Conditions, nodeReadyCondition(pn.Status, lastHeartbeat, c.clock(), c.gracePeriod))
+	return newStatus
+}
+
+// ecsdVersion 返回节点上 ecsd 的版本。版本只能逐个节点地查询，所以只在第一次同步、
+// 以及节点重新上线 (升级 ecsd 通常伴随着重启) 时查询，其余时候沿用上一次的结果。
+func (c *NodeController) ecsdVersion(ctx context.Context, pn clientset.NodeInfo, node *ecsmv1.ECSMNode) string {
+	current := node.Status.EcsdVersion
+	if current != "" && (!isNodeOnline(pn.Status) || ecsmmeta.IsConditionTrue(node.Status.Conditions, ecsmv1.NodeReady)) {
+		return current
+	}
+	details, err := c.ecsmClient.Nodes().GetByID(ctx, pn.ID)
+	if err != nil {
+		klog.Warningf("Failed to get ecsd version of node %s: %v", pn.Name, err)
+		return current
+	}
+	return details.EcsdVersion
+}
+
+// nodeReadyCondition 计算节点的 Ready 状况：节点必须在线，并且在 gracePeriod 内上报过新的指标。
+func nodeReadyCondition(platformStatus string, lastHeartbeat, now time.Time, gracePeriod time.Duration) metav1.Condition {
+	switch {
+	case !isNodeOnline(platformStatus):
+		return metav1.Condition{
+			Type:    ecsmv1.NodeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  nodeReasonOffline,
+			Message: fmt.Sprintf("ECSM reports node status %q", platformStatus),
+		}
+	case lastHeartbeat.IsZero() || now.Sub(lastHeartbeat) > gracePeriod:
+		return metav1.Condition{
+			Type:    ecsmv1.NodeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  nodeReasonHeartbeatTimeout,
+			Message: fmt.Sprintf("Node stopped reporting metrics for more than %s", gracePeriod),
+		}
+	default:
+		return metav1.Condition{
+			Type:    ecsmv1.NodeReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  nodeReasonReady,
+			Message: "Node is online and reporting metrics",
+		}
+	}
+}
+
+// isNodeOnline 判断平台上报的节点状态是否为在线。
+func isNodeOnline(status string) bool {
+	return strings.EqualFold(status, "online") // 假设 "online" 表示节点在线
+}
@@ -0,0 +1,37 @@
+// file: pkg/controller/node_controller_test.go
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeReadyCondition(t *testing.T) {
+	now := time.Now()
+	grace := 40 * time.Second
+
+	tests := []struct {
+		name          string
+		status        string
+		lastHeartbeat time.Time
+		wantStatus    metav1.ConditionStatus
+		wantReason    string
+	}{
+		{"online with fresh heartbeat", "online", now.Add(-10 * time.Second), metav1.ConditionTrue, nodeReasonReady},
+		{"offline", "offline", now, metav1.ConditionFalse, nodeReasonOffline},
+		{"heartbeat stopped", "online", now.Add(-time.Minute), metav1.ConditionFalse, nodeReasonHeartbeatTimeout},
+		{"never heard from", "online", time.Time{}, metav1.ConditionFalse, nodeReasonHeartbeatTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := nodeReadyCondition(tt.status, tt.lastHeartbeat, now, grace)
+			if cond.Status != tt.wantStatus || cond.Reason != tt.wantReason {
+				t.Errorf("nodeReadyCondition() = (%s, %s), want (%s, %s)", cond.Status, cond.Reason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
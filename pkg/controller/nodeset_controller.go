@@ -0,0 +1,297 @@
+// file: pkg/controller/nodeset_controller.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/fx147/ecsm-operator/pkg/scheduler"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultNodeSetSyncPeriod 是 NodeSetController 默认的同步周期
+	DefaultNodeSetSyncPeriod = 15 * time.Second
+
+	// nodeSetSyncTimeout 是一次全量同步的最长时间
+	nodeSetSyncTimeout = time.Minute
+
+	// nodeSetControllerName 是 NodeSetController 在指标中的名称
+	nodeSetControllerName = "nodeset"
+
+	// nodeSetOwnerKind 是 ECSMNodeSet 维护的 ECSMService 上 ownerReference 的 kind
+	nodeSetOwnerKind = "ECSMNodeSet"
+)
+
+// 节点集相关的事件原因
+const (
+	ReasonNodesChanged    = "NodesChanged"
+	ReasonServiceConflict = "ServiceConflict"
+)
+
+// NodeSetController 周期性地同步所有 ECSMNodeSet。
+// 它根据 nodeSelector、污点和节点的就绪状态选出节点，并把它们写入一个同名的、Static 策略的 ECSMService，
+// 由 ECSMServiceController 在这些节点上创建、替换或删除容器。
+type NodeSetController struct {
+	registry registry.Interface
+	recorder record.EventRecorder
+
+	syncPeriod time.Duration
+}
+
+// NewNodeSetController 创建一个新的 NodeSetController。
+func NewNodeSetController(reg registry.Interface, recorder record.EventRecorder, syncPeriod time.Duration) *NodeSetController {
+	return &NodeSetController{
+		registry:   reg,
+		recorder:   recorder,
+		syncPeriod: syncPeriod,
+	}
+}
+
+// Run 启动周期性同步，直到 stopCh 被关闭。
+func (c *NodeSetController) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting nodeset controller")
+	defer klog.Info("Shutting down nodeset controller")
+
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), nodeSetSyncTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := c.syncAll(ctx)
+		result := reconcileResult(ctx, err)
+		reconcileTotal.WithLabelValues(nodeSetControllerName, result).Inc()
+		reconcileDuration.WithLabelValues(nodeSetControllerName).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("failed to sync nodesets: %w", err))
+		}
+	}, c.syncPeriod, stopCh)
+}
+
+// syncAll 同步所有 ECSMNodeSet，并删除属主已经不存在的 ECSMService。
+func (c *NodeSetController) syncAll(ctx context.Context) error {
+	nodeSets, _, err := c.registry.ListNodeSets(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMNodeSets: %w", err)
+	}
+	nodes, _, err := c.registry.ListNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMNodes: %w", err)
+	}
+	services, _, err := c.registry.ListAllServices(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMServices: %w", err)
+	}
+
+	owned := make(map[types.UID]*ecsmv1.ECSMService)
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if owner := ecsmmeta.GetControllerOf(svc); owner != nil && owner.Kind == nodeSetOwnerKind {
+			owned[owner.UID] = svc
+		}
+	}
+
+	var errs []error
+	for i := range nodeSets.Items {
+		nodeSet := &nodeSets.Items[i]
+		delete(owned, nodeSet.UID)
+		if err := c.syncNodeSet(ctx, nodeSet, nodes.Items); err != nil {
+			errs = append(errs, fmt.Errorf("nodeset %s/%s: %w", nodeSet.Namespace, nodeSet.Name, err))
+		}
+	}
+
+	// 剩下的 ECSMService 属于已经被删除的 ECSMNodeSet
+	for _, svc := range owned {
+		if svc.DeletionTimestamp != nil {
+			continue
+		}
+		klog.Infof("Deleting service %s, its nodeset no longer exists", serviceKey(svc))
+		if err := c.registry.DeleteService(ctx, svc.Namespace, svc.Name); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete orphaned service %s: %w", serviceKey(svc), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// syncNodeSet 调谐一个 ECSMNodeSet，并在状态变化时写回 Registry。
+func (c *NodeSetController) syncNodeSet(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet, nodes []ecsmv1.ECSMNode) error {
+	newStatus := nodeSet.Status.DeepCopy()
+	syncErr := c.reconcileNodeSet(ctx, nodeSet, nodes, newStatus)
+
+	if reflect.DeepEqual(&nodeSet.Status, newStatus) {
+		return syncErr
+	}
+	toUpdate := nodeSet.DeepCopy()
+	toUpdate.Status = *newStatus
+	if _, err := c.registry.UpdateNodeSetStatus(ctx, toUpdate); err != nil {
+		return fmt.Errorf("failed to update ECSMNodeSet status: %w", err)
+	}
+	return syncErr
+}
+
+// reconcileNodeSet 让 ECSMNodeSet 的 ECSMService 运行在当前选中的节点上，结果写入 status。
+func (c *NodeSetController) reconcileNodeSet(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet, nodes []ecsmv1.ECSMNode, status *ecsmv1.ECSMNodeSetStatus) error {
+	status.ObservedGeneration = nodeSet.Generation
+	status.ServiceName = nodeSet.Name
+
+	existing, err := c.registry.GetService(ctx, nodeSet.Namespace, nodeSet.Name)
+	if errors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get service %s/%s: %w", nodeSet.Namespace, nodeSet.Name, err)
+	}
+	// 正在删除的 ECSMService 可能属于之前的同名 ECSMNodeSet，等待它删除完成即可
+	if existing != nil && existing.DeletionTimestamp == nil {
+		if !ecsmmeta.IsControlledBy(existing, nodeSet) {
+			c.recorder.Eventf(nodeSet, ecsmv1.EventTypeWarning, ReasonServiceConflict,
+				"Service %s already exists and is not managed by this nodeset", serviceKey(existing))
+			return nil
+		}
+	}
+
+	var current []string
+	if existing != nil {
+		current = existing.Spec.DeploymentStrategy.Nodes
+	}
+	selected := selectNodeSetNodes(nodeSet, nodes, current)
+	if !reflect.DeepEqual(selected, status.Nodes) && (len(selected) > 0 || len(status.Nodes) > 0) {
+		added := sets.List(sets.New(selected...).Difference(sets.New(status.Nodes...)))
+		removed := sets.List(sets.New(status.Nodes...).Difference(sets.New(selected...)))
+		c.recorder.Eventf(nodeSet, ecsmv1.EventTypeNormal, ReasonNodesChanged,
+			"Running on %d node(s), added %v, removed %v", len(selected), added, removed)
+	}
+	status.Nodes = selected
+	status.DesiredNumberScheduled = int32(len(selected))
+
+	switch {
+	case existing != nil && existing.DeletionTimestamp != nil:
+		// 等待上一次删除完成后再重新创建
+		status.CurrentNumberScheduled = existing.Status.Replicas
+		status.NumberReady = existing.Status.ReadyReplicas
+		return nil
+
+	case len(selected) == 0:
+		// Static 策略需要至少一个节点，没有节点可选时删除 ECSMService，节点出现后再重新创建
+		status.CurrentNumberScheduled, status.NumberReady = 0, 0
+		if existing == nil {
+			return nil
+		}
+		klog.Infof("NodeSet %s/%s: no nodes selected, deleting service %s", nodeSet.Namespace, nodeSet.Name, serviceKey(existing))
+		if err := c.registry.DeleteService(ctx, existing.Namespace, existing.Name); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete service %s: %w", serviceKey(existing), err)
+		}
+		c.recorder.Eventf(nodeSet, ecsmv1.EventTypeNormal, ReasonSuccessfulDelete, "Deleted service %s", existing.Name)
+		return nil
+
+	case existing == nil:
+		desired := nodeSetService(nodeSet, selected)
+		created, err := c.registry.CreateService(ctx, desired)
+		if err != nil {
+			c.recorder.Eventf(nodeSet, ecsmv1.EventTypeWarning, ReasonCreateFailed, "Failed to create service %s: %v", desired.Name, err)
+			return fmt.Errorf("failed to create service %s: %w", serviceKey(desired), err)
+		}
+		klog.Infof("NodeSet %s/%s: created service %s on nodes %v", nodeSet.Namespace, nodeSet.Name, serviceKey(created), selected)
+		c.recorder.Eventf(nodeSet, ecsmv1.EventTypeNormal, ReasonSuccessfulCreate, "Created service %s", created.Name)
+		status.CurrentNumberScheduled, status.NumberReady = 0, 0
+		return nil
+	}
+
+	status.CurrentNumberScheduled = existing.Status.Replicas
+	status.NumberReady = existing.Status.ReadyReplicas
+
+	desired := nodeSetService(nodeSet, selected)
+	// 经过 JSON 往返后空切片会变成 nil，这里使用不区分两者的语义比较
+	if equality.Semantic.DeepEqual(existing.Spec, desired.Spec) {
+		return nil
+	}
+	toUpdate := existing.DeepCopy()
+	toUpdate.Spec = desired.Spec
+	if _, err := c.registry.UpdateService(ctx, toUpdate); err != nil {
+		return fmt.Errorf("failed to update service %s: %w", serviceKey(existing), err)
+	}
+	klog.V(2).Infof("NodeSet %s/%s: updated service %s", nodeSet.Namespace, nodeSet.Name, serviceKey(existing))
+	return nil
+}
+
+// selectNodeSetNodes 返回 ECSMNodeSet 应该运行的节点，按名称排序。
+// 节点必须满足 nodeSelector，且没有不被容忍的 NoExecute 污点。
+// 新的节点只在就绪、且没有不被容忍的 NoSchedule 污点时才会被选中，以免在离线的节点上部署失败；
+// 已经选中的节点在暂时离线或被加上 NoSchedule 污点时仍然保留，避免节点抖动时反复删除和重建容器。
+func selectNodeSetNodes(nodeSet *ecsmv1.ECSMNodeSet, nodes []ecsmv1.ECSMNode, current []string) []string {
+	currentSet := sets.New(current...)
+	var selected []string
+	for i := range nodes {
+		node := &nodes[i]
+		if node.DeletionTimestamp != nil || !nodeMatchesSelector(node.Labels, nodeSet.Spec.NodeSelector) {
+			continue
+		}
+		if scheduler.HasUntoleratedTaint(node.Spec.Taints, nodeSet.Spec.Tolerations, ecsmv1.TaintEffectNoExecute) {
+			continue
+		}
+		if !currentSet.Has(node.Name) && (!ecsmmeta.IsConditionTrue(node.Status.Conditions, ecsmv1.NodeReady) ||
+			scheduler.HasUntoleratedTaint(node.Spec.Taints, nodeSet.Spec.Tolerations, ecsmv1.TaintEffectNoSchedule)) {
+			continue
+		}
+		selected = append(selected, node.Name)
+	}
+	sort.Strings(selected)
+	return selected
+}
+
+// nodeMatchesSelector 判断节点标签是否满足 selector，空的 selector 匹配所有节点。
+func nodeMatchesSelector(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if actual, ok := labels[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeSetService 构造 ECSMNodeSet 在给定节点上运行时对应的 ECSMService。
+// 返回的对象已经填充了默认值，以便与 Registry 中的对象比较。
+func nodeSetService(nodeSet *ecsmv1.ECSMNodeSet, nodes []string) *ecsmv1.ECSMService {
+	service := &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: nodeSet.Namespace,
+			Name:      nodeSet.Name,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(nodeSet, ecsmv1.SchemeGroupVersion.WithKind(nodeSetOwnerKind)),
+			},
+		},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:        ecsmv1.DeploymentStrategyTypeStatic,
+				Nodes:       nodes,
+				Tolerations: nodeSet.Spec.Tolerations,
+			},
+			UpgradeStrategy: *nodeSet.Spec.UpgradeStrategy.DeepCopy(),
+			Template:        *nodeSet.Spec.Template.DeepCopy(),
+		},
+	}
+	defaults.SetServiceDefaults(service)
+	return service
+}
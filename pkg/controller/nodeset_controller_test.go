@@ -0,0 +1,93 @@
+// file: pkg/controller/nodeset_controller_test.go
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelectNodeSetNodes(t *testing.T) {
+	node := func(name string, ready bool, labels map[string]string, taints ...ecsmv1.NodeTaint) ecsmv1.ECSMNode {
+		status := metav1.ConditionFalse
+		if ready {
+			status = metav1.ConditionTrue
+		}
+		return ecsmv1.ECSMNode{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       ecsmv1.ECSMNodeSpec{Taints: taints},
+			Status: ecsmv1.ECSMNodeStatus{
+				Conditions: []metav1.Condition{{Type: ecsmv1.NodeReady, Status: status}},
+			},
+		}
+	}
+	edge := map[string]string{"role": "edge"}
+	gpuTaint := ecsmv1.NodeTaint{Key: "gpu", Value: "true", Effect: ecsmv1.TaintEffectNoSchedule}
+	maintenance := ecsmv1.NodeTaint{Key: "maintenance", Effect: ecsmv1.TaintEffectNoExecute}
+	nodes := []ecsmv1.ECSMNode{
+		node("c", true, edge),
+		node("a", true, edge),
+		node("b", true, map[string]string{"role": "core"}),
+		node("d", false, edge),
+		node("e", false, edge),
+		node("f", true, edge, gpuTaint),
+		node("g", true, edge, maintenance),
+	}
+
+	tests := []struct {
+		name    string
+		nodeSet ecsmv1.ECSMNodeSet
+		current []string
+		want    []string
+	}{
+		{
+			name: "empty selector selects all ready, untainted nodes",
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name:    "node selector",
+			nodeSet: ecsmv1.ECSMNodeSet{Spec: ecsmv1.ECSMNodeSetSpec{NodeSelector: edge}},
+			want:    []string{"a", "c"},
+		},
+		{
+			name:    "not ready nodes that are already selected are kept",
+			nodeSet: ecsmv1.ECSMNodeSet{Spec: ecsmv1.ECSMNodeSetSpec{NodeSelector: edge}},
+			current: []string{"a", "d"},
+			want:    []string{"a", "c", "d"},
+		},
+		{
+			name: "tolerated taint",
+			nodeSet: ecsmv1.ECSMNodeSet{Spec: ecsmv1.ECSMNodeSetSpec{
+				NodeSelector: edge,
+				Tolerations:  []ecsmv1.Toleration{{Key: "gpu", Operator: ecsmv1.TolerationOpExists}},
+			}},
+			want: []string{"a", "c", "f"},
+		},
+		{
+			name:    "NoSchedule keeps selected nodes, NoExecute evicts them",
+			nodeSet: ecsmv1.ECSMNodeSet{Spec: ecsmv1.ECSMNodeSetSpec{NodeSelector: edge}},
+			current: []string{"a", "f", "g"},
+			want:    []string{"a", "c", "f"},
+		},
+		{
+			name: "tolerated NoExecute taint",
+			nodeSet: ecsmv1.ECSMNodeSet{Spec: ecsmv1.ECSMNodeSetSpec{
+				NodeSelector: edge,
+				Tolerations:  []ecsmv1.Toleration{{Key: "maintenance", Operator: ecsmv1.TolerationOpExists, Effect: ecsmv1.TaintEffectNoExecute}},
+			}},
+			want: []string{"a", "c", "g"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectNodeSetNodes(&tt.nodeSet, nodes, tt.current)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectNodeSetNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
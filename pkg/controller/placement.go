@@ -0,0 +1,121 @@
+// file: pkg/controller/placement.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/scheduler"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+)
+
+// usesScheduler 判断服务的节点是否由调度器选择。
+func (c *ECSMServiceController) usesScheduler(service *ecsmv1.ECSMService) bool {
+	return c.scheduler != nil && service.Spec.DeploymentStrategy.Type == ecsmv1.DeploymentStrategyTypeDynamic &&
+		service.Status.Cluster == c.clusters.DefaultCluster()
+}
+
+// rescheduleNodes 在服务可选的节点发生变化时 (例如注册了一个满足 nodeSelector 的新节点，
+// affinity 选中的服务被调度到了其他节点上，或者实例所在的节点被加上了不能容忍的 NoExecute 污点)，
+// 重新调度当前修订版本，并把平台服务调整到选出的节点上。返回 true 表示平台服务被修改了。
+// 调度器会优先保留平台服务当前所在的节点，所以节点没有变化时不会产生任何修改。
+func (c *ECSMServiceController) rescheduleNodes(ctx context.Context, service *ecsmv1.ECSMService, ps *platformService, replicas int32) (bool, error) {
+	current := rowNodeNames(ps.Row)
+	if !c.usesScheduler(service) || len(current) == 0 {
+		return false, nil
+	}
+	evict, err := c.scheduler.NodesToEvict(ctx, service, current)
+	if err != nil {
+		return false, err
+	}
+	if len(evict) == 0 && len(service.Spec.DeploymentStrategy.NodeSelector) == 0 && service.Spec.Affinity == nil {
+		return false, nil
+	}
+	scheduled, err := c.scheduler.Schedule(ctx, service, replicas, current)
+	if err != nil {
+		c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonFailedScheduling, "%v", err)
+		return false, fmt.Errorf("failed to schedule service %s: %w", serviceKey(service), err)
+	}
+	if !placementChanged(current, scheduled) {
+		return false, nil
+	}
+
+	klog.Infof("Service %s/%s: schedulable nodes changed, moving %s from %v to %v",
+		service.Namespace, service.Name, ps.Row.Name, current, scheduled)
+	if len(evict) > 0 && !c.isDryRun(service) {
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonTaintEviction,
+			"Evicting platform service %s from node(s) %s with untolerated NoExecute taints", ps.Row.Name, strings.Join(evict, ","))
+	}
+	if err := c.restorePlatformService(ctx, service, ps, replicas); err != nil {
+		return true, err
+	}
+	if !c.isDryRun(service) {
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonRescheduled,
+			"Moved platform service %s to node(s) %s", ps.Row.Name, strings.Join(scheduled, ","))
+	}
+	return true, nil
+}
+
+// placementChanged 判断调度结果与平台服务当前所在的节点集合是否不同。
+// 同一个节点上可能运行着多个实例，所以比较的是去重后的集合。
+func placementChanged(current, scheduled []string) bool {
+	distinct := func(names []string) string {
+		seen := make(map[string]bool, len(names))
+		var out []string
+		for _, n := range names {
+			if !seen[n] {
+				seen[n] = true
+				out = append(out, n)
+			}
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+	return distinct(current) != distinct(scheduled)
+}
+
+// enqueueServicesForNode 将调度受 node 影响的 ECSMService 加入队列：
+// 通过 nodeSelector 选择了 node 的服务，使新注册的、带有匹配标签的节点能够被纳入调度；
+// 不能容忍 node 上 NoExecute 污点的服务，使它们在该节点上的实例被驱逐。
+// 标签被修改后不再匹配的节点无法从事件中识别，它上面的实例会在服务下一次被重新验证时迁移。
+func (c *ECSMServiceController) enqueueServicesForNode(node *ecsmv1.ECSMNode) {
+	services, _, err := c.registry.ListAllServices(context.Background(), "")
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list services for ECSMNode %s: %w", node.Name, err))
+		return
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if !c.usesScheduler(svc) {
+			continue
+		}
+		strategy := svc.Spec.DeploymentStrategy
+		if (len(strategy.NodeSelector) > 0 && scheduler.NodeMatches(svc, node)) ||
+			scheduler.HasUntoleratedTaint(node.Spec.Taints, strategy.Tolerations, ecsmv1.TaintEffectNoExecute) {
+			klog.V(4).Infof("ECSMNode %s changed, requeueing service %s", node.Name, serviceKey(svc))
+			c.queue.Add(serviceKey(svc))
+		}
+	}
+}
+
+// servicePlacement 返回服务在默认集群中的实例所在的节点，供调度器计算服务之间的亲和与反亲和。
+// 属于其他集群的服务没有实例在 ECSMNode 所描述的节点上，返回空列表。
+func (c *ECSMServiceController) servicePlacement(ctx context.Context, service *ecsmv1.ECSMService) ([]string, error) {
+	if service.Status.Cluster != "" && service.Status.Cluster != c.clusters.DefaultCluster() {
+		return nil, nil
+	}
+	rows, err := listOwnedRows(ctx, c.clusters.Default(), service)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []string
+	for _, row := range rows {
+		nodes = append(nodes, rowNodeNames(row)...)
+	}
+	return nodes, nil
+}
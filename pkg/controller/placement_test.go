@@ -0,0 +1,27 @@
+// file: pkg/controller/placement_test.go
+
+package controller
+
+import "testing"
+
+func TestPlacementChanged(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   []string
+		scheduled []string
+		want      bool
+	}{
+		{"same nodes", []string{"n1", "n2"}, []string{"n1", "n2"}, false},
+		{"different order", []string{"n2", "n1"}, []string{"n1", "n2"}, false},
+		{"several instances on one node", []string{"n1", "n1", "n2"}, []string{"n1", "n2"}, false},
+		{"new node registered", []string{"n1", "n1"}, []string{"n1", "n3"}, true},
+		{"node left the selection", []string{"n1", "n2"}, []string{"n1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := placementChanged(tt.current, tt.scheduled); got != tt.want {
+				t.Errorf("placementChanged(%v, %v) = %v, want %v", tt.current, tt.scheduled, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,505 @@
+// file: pkg/controller/platform.go
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// platformService 描述了一个属于某个 ECSMService 的 ECSM 平台服务 (即一个模板修订版本)。
+// 一个 ECSMService 在稳定状态下只对应一个平台服务；在滚动更新期间，新旧修订版本会同时存在。
+type platformService struct {
+	// Row 是平台服务列表接口返回的原始数据
+	Row clientset.ProvisionListRow
+	// TemplateHash 是从平台服务名称中解析出的模板哈希
+	TemplateHash string
+	// Containers 是该平台服务下的所有容器实例
+	Containers []clientset.ContainerInfo
+	// readiness 是就绪探针的结果，为 nil 时只根据容器的运行状态判断是否就绪
+	readiness *ProbeResults
+}
+
+// replicas 返回该修订版本当前期望的副本数 (ECSM 的 factor 字段)。
+func (p *platformService) replicas() int32 {
+	return int32(p.Row.Factor)
+}
+
+// readyReplicas 返回该修订版本中处于运行状态、且没有被就绪探针判定为未就绪的容器数量。
+func (p *platformService) readyReplicas() int32 {
+	var ready int32
+	for _, c := range p.Containers {
+		if p.readiness.containerReady(c) {
+			ready++
+		}
+	}
+	return ready
+}
+
+// isContainerReady 判断一个容器是否可以被视为可用。
+func isContainerReady(c clientset.ContainerInfo) bool {
+	return c.Status == "running" // 假设 "running" 就是 "ready"
+}
+
+// computeTemplateHash 计算容器模板的哈希值，用于标识一个模板修订版本。
+// 模板的任何变更 (镜像、环境变量、资源限制等) 都会产生新的哈希。
+func computeTemplateHash(template *ecsmv1.ContainerTemplateSpec) string {
+	hasher := fnv.New32a()
+	// json 序列化的结果是确定的 (map 的 key 会被排序)
+	data, _ := json.Marshal(template)
+	hasher.Write(data)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// platformServiceName 返回某个修订版本在 ECSM 平台上的服务名称。
+func platformServiceName(service *ecsmv1.ECSMService, hash string) string {
+	return platformServicePrefix(service) + hash
+}
+
+// platformServicePrefix 返回 ECSMService 的平台服务名称中模板哈希之前的部分。
+// default 命名空间中的服务使用 "<name>-"，其他命名空间在名称后加上命名空间的哈希，
+// 使不同命名空间中的同名服务在平台上不会使用相同的名称。
+// 命名空间不直接写在名称中，否则 "prod" 中的 "web" 和 default 中的 "web-prod" 仍然会冲突。
+func platformServicePrefix(service *ecsmv1.ECSMService) string {
+	if service.Namespace == "" || service.Namespace == metav1.NamespaceDefault {
+		return service.Name + "-"
+	}
+	hasher := fnv.New32a()
+	hasher.Write([]byte(service.Namespace))
+	return service.Name + "-" + rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())) + "-"
+}
+
+// parsePlatformServiceName 判断一个平台服务名称是否属于给定的 ECSMService，
+// 如果是，返回其模板哈希。
+func parsePlatformServiceName(service *ecsmv1.ECSMService, name string) (string, bool) {
+	prefix := platformServicePrefix(service)
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	hash := strings.TrimPrefix(name, prefix)
+	// 哈希本身不包含 "-"，这可以排除 "app-foo-xxx" 这种属于其他服务 ("app-foo") 的名称
+	if hash == "" || strings.Contains(hash, "-") {
+		return "", false
+	}
+	return hash, true
+}
+
+// listPlatformServices 列出 ECSM 平台上所有属于该 ECSMService 的平台服务及其容器。
+// 不属于任何 ECSMService、但与该服务同名、符合命名规则或匹配 selector 的平台服务会在这里被认领。
+func (c *ECSMServiceController) listPlatformServices(ctx context.Context, service *ecsmv1.ECSMService) ([]*platformService, error) {
+	rows, err := c.listCandidateRows(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []*platformService
+	var ids []string
+	byID := make(map[string]*platformService)
+	for _, row := range rows {
+		isOwned, isOrphan := claimPlatformService(service, row)
+		var hash string
+		switch {
+		case isOwned:
+			hash = revisionHash(service, row)
+		case isOrphan && service.DeletionTimestamp == nil:
+			// 正在删除的服务不再认领新的平台服务
+			hash, err = c.adoptPlatformService(ctx, service, row)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		ps := &platformService{Row: row, TemplateHash: hash, readiness: c.readiness}
+		owned = append(owned, ps)
+		byID[row.ID] = ps
+		ids = append(ids, row.ID)
+	}
+
+	if len(ids) == 0 {
+		return owned, nil
+	}
+
+	containers, err := c.clientFor(service).Containers().ListAllByService(ctx, clientset.ListContainersByServiceOptions{ServiceIDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, co := range containers {
+		if ps, ok := byID[co.ServiceID]; ok {
+			ps.Containers = append(ps.Containers, co)
+		}
+	}
+
+	return owned, nil
+}
+
+// listOwnedContainers 列出 ECSMService 所有修订版本下的容器。
+// 与 listPlatformServices 不同，它只通过属主标签查找平台服务，不会认领任何平台服务，
+// 供 ECSMServiceController 之外的控制器只读地观察服务的容器。
+func listOwnedContainers(ctx context.Context, ecsmClient clientset.Interface, service *ecsmv1.ECSMService) ([]clientset.ContainerInfo, error) {
+	rows, err := listOwnedRows(ctx, ecsmClient, service)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	containers, err := ecsmClient.Containers().ListAllByService(ctx, clientset.ListContainersByServiceOptions{ServiceIDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return containers, nil
+}
+
+// listOwnedRows 通过属主标签列出 ECSMService 所有修订版本的平台服务，不会认领任何平台服务。
+func listOwnedRows(ctx context.Context, ecsmClient clientset.Interface, service *ecsmv1.ECSMService) ([]clientset.ProvisionListRow, error) {
+	uid := string(service.UID)
+	rows, err := ecsmClient.Services().ListAll(ctx, clientset.ListServicesOptions{Label: LabelOwnerUID + "=" + uid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list platform services: %w", err)
+	}
+	var owned []clientset.ProvisionListRow
+	for _, row := range rows {
+		// ECSM 的标签过滤不一定是精确匹配，这里再检查一次
+		if rowLabels(row)[LabelOwnerUID] == uid {
+			owned = append(owned, row)
+		}
+	}
+	return owned, nil
+}
+
+// findRevision 返回模板哈希为 hash 的修订版本，不存在时返回 nil。
+func findRevision(revisions []*platformService, hash string) *platformService {
+	for _, rev := range revisions {
+		if rev.TemplateHash == hash {
+			return rev
+		}
+	}
+	return nil
+}
+
+// listCandidateRows 列出所有可能属于该 ECSMService 的平台服务。
+func (c *ECSMServiceController) listCandidateRows(ctx context.Context, service *ecsmv1.ECSMService) ([]clientset.ProvisionListRow, error) {
+	// ECSM 的 name 过滤是模糊匹配，所以需要在客户端再精确过滤一次
+	rows, err := c.clientFor(service).Services().ListAll(ctx, clientset.ListServicesOptions{Name: service.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list platform services: %w", err)
+	}
+	if len(service.Spec.Selector) == 0 {
+		return rows, nil
+	}
+
+	// ECSM 只支持按单个标签过滤，这里用 selector 中的一个标签缩小范围，其余的由 matchesSelector 检查
+	first := formatLabels(service.Spec.Selector)[0]
+	selected, err := c.clientFor(service).Services().ListAll(ctx, clientset.ListServicesOptions{Label: first})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list platform services by selector: %w", err)
+	}
+
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		seen[row.ID] = true
+	}
+	for _, row := range selected {
+		if !seen[row.ID] {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// desiredReplicas 返回 ECSMService 期望的副本总数。
+// Static 策略下为节点数量，Dynamic 策略下为 replicas 字段的值。
+func desiredReplicas(service *ecsmv1.ECSMService) int32 {
+	strategy := service.Spec.DeploymentStrategy
+	if strategy.Type == ecsmv1.DeploymentStrategyTypeStatic {
+		return int32(len(strategy.Nodes))
+	}
+	if strategy.Replicas != nil {
+		return *strategy.Replicas
+	}
+	return 0
+}
+
+// nodeSpecFor 计算一个修订版本在给定副本数下应该使用的节点配置。
+// Static 策略下每个节点恰好运行一个实例，所以副本数体现为节点列表的长度；
+// Dynamic 策略下节点列表是可供选择的节点池，副本数体现为 factor；调度器启用时，节点池会在 scheduleNodes 中被替换为选出的节点。
+func nodeSpecFor(service *ecsmv1.ECSMService, replicas int32) (clientset.NodeSpec, *int) {
+	strategy := service.Spec.DeploymentStrategy
+	if strategy.Type == ecsmv1.DeploymentStrategyTypeStatic {
+		names := strategy.Nodes
+		if int(replicas) < len(names) {
+			names = names[:replicas]
+		}
+		return clientset.NodeSpec{Names: append([]string(nil), names...)}, nil
+	}
+	factor := int(replicas)
+	return clientset.NodeSpec{Names: append([]string(nil), strategy.NodePool...)}, &factor
+}
+
+// scheduleNodes 在 Dynamic 策略下，用调度器选出的节点替换 node 中的节点池。
+// current 是该平台服务当前所在的节点，调度器会优先保留它们。
+func (c *ECSMServiceController) scheduleNodes(ctx context.Context, service *ecsmv1.ECSMService, replicas int32, node *clientset.NodeSpec, current []string) error {
+	if c.scheduler == nil || service.Spec.DeploymentStrategy.Type != ecsmv1.DeploymentStrategyTypeDynamic ||
+		service.Status.Cluster != c.clusters.DefaultCluster() {
+		return nil
+	}
+	names, err := c.scheduler.Schedule(ctx, service, replicas, current)
+	if err != nil {
+		c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonFailedScheduling, "%v", err)
+		return fmt.Errorf("failed to schedule service %s: %w", serviceKey(service), err)
+	}
+	node.Names = names
+	return nil
+}
+
+// rowNodeNames 返回平台服务当前所在的节点名称。
+func rowNodeNames(row clientset.ProvisionListRow) []string {
+	names := make([]string, 0, len(row.NodeList))
+	for _, n := range row.NodeList {
+		names = append(names, n.NodeName)
+	}
+	return names
+}
+
+// DesiredPlatformService 返回控制器为 service 的当前模板和期望副本数创建平台服务时使用的 payload，
+// 供 ecsm-cli diff 等工具预览平台上将要发生的变化。reg 不为 nil 时先展开模板对 ECSMConfig 和 ECSMSecret 的引用。
+// 调度器启用时，控制器实际使用的节点可能与这里返回的节点池不同。
+func DesiredPlatformService(ctx context.Context, reg registry.Interface, service *ecsmv1.ECSMService) (*clientset.CreateServiceRequest, error) {
+	service = service.DeepCopy()
+	if reg != nil {
+		if err := resolveTemplateConfigs(ctx, reg, service.Namespace, &service.Spec.Template); err != nil {
+			return nil, err
+		}
+	}
+	return buildCreateServiceRequest(service, computeTemplateHash(&service.Spec.Template), desiredReplicas(service))
+}
+
+// ListPlatformServicesByOwner 通过 LabelOwnerName 标签列出属于命名空间 namespace 中名为 name 的 ECSMService 的平台服务。
+// 与 listOwnedRows 不同，它不需要知道 ECSMService 的 UID，可以在没有 Registry 时使用。
+func ListPlatformServicesByOwner(ctx context.Context, ecsmClient clientset.Interface, namespace, name string) ([]clientset.ProvisionListRow, error) {
+	owner := namespace + "." + name
+	rows, err := ecsmClient.Services().ListAll(ctx, clientset.ListServicesOptions{Label: LabelOwnerName + "=" + owner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list platform services: %w", err)
+	}
+	var owned []clientset.ProvisionListRow
+	for _, row := range rows {
+		if rowLabels(row)[LabelOwnerName] == owner {
+			owned = append(owned, row)
+		}
+	}
+	return owned, nil
+}
+
+// buildCreateServiceRequest 将 ECSMService 的期望状态翻译成 ECSM 创建服务的 payload。
+func buildCreateServiceRequest(service *ecsmv1.ECSMService, hash string, replicas int32) (*clientset.CreateServiceRequest, error) {
+	image, err := buildImageSpec(service)
+	if err != nil {
+		return nil, err
+	}
+	node, factor := nodeSpecFor(service, replicas)
+
+	req := &clientset.CreateServiceRequest{
+		Name:   platformServiceName(service, hash),
+		Image:  *image,
+		Node:   node,
+		Factor: factor,
+		Policy: strings.ToLower(string(service.Spec.DeploymentStrategy.Type)),
+		Labels: withDesiredReplicas(formatLabels(ownerLabels(service, hash)), replicas),
+	}
+	if service.Spec.Template.Prepull {
+		prepull := true
+		req.Prepull = &prepull
+	}
+	return req, nil
+}
+
+// buildImageSpec 将 spec.template 翻译成 ECSM 的镜像配置。
+func buildImageSpec(service *ecsmv1.ECSMService) (*clientset.ImageSpec, error) {
+	template := &service.Spec.Template
+
+	action := "run"
+	if ps := template.PlatformSpecific; ps != nil && ps.Action != "" {
+		action = strings.ToLower(string(ps.Action))
+	}
+
+	config, err := buildImageConfig(service)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientset.ImageSpec{
+		Ref:         template.Image,
+		Action:      action,
+		Config:      config,
+		VSOA:        buildImageVSOA(template),
+		PullPolicy:  string(template.ImagePullPolicy),
+		AutoUpgrade: strings.ToLower(string(service.Spec.UpgradeStrategy.Type)),
+	}, nil
+}
+
+// buildImageConfig 将容器模板中的进程、挂载、资源和平台特定配置翻译成 EcsImageConfig。
+func buildImageConfig(service *ecsmv1.ECSMService) (*clientset.EcsImageConfig, error) {
+	template := &service.Spec.Template
+
+	hostname := template.Hostname
+	if hostname == "" {
+		hostname = service.Name
+	}
+	config := &clientset.EcsImageConfig{Hostname: hostname}
+
+	if len(template.Command) > 0 || len(template.Env) > 0 {
+		process := &clientset.Process{Args: template.Command}
+		for _, env := range template.Env {
+			process.Env = append(process.Env, fmt.Sprintf("%s=%s", env.Name, env.Value))
+		}
+		config.Process = process
+	}
+
+	for _, vm := range template.VolumeMounts {
+		option := "rw"
+		if vm.ReadOnly {
+			option = "ro"
+		}
+		config.Mounts = append(config.Mounts, clientset.Mount{
+			Destination: vm.ContainerPath,
+			Source:      vm.HostPath,
+			Options:     []string{option},
+		})
+	}
+
+	sylixos := &clientset.SylixOS{}
+	hasSylixOS := false
+
+	if template.Resources != nil && len(template.Resources.Limits) > 0 {
+		resources := &clientset.Resources{}
+		if v, ok := template.Resources.Limits[ecsmv1.ResourceTypeMemory]; ok {
+			mb, err := quantityToMB(v)
+			if err != nil {
+				return nil, newSpecError("invalid memory limit %q: %w", v, err)
+			}
+			resources.Memory = &clientset.Memory{MemoryLimitMB: mb}
+		}
+		if v, ok := template.Resources.Limits[ecsmv1.ResourceTypeDisk]; ok {
+			mb, err := quantityToMB(v)
+			if err != nil {
+				return nil, newSpecError("invalid disk limit %q: %w", v, err)
+			}
+			resources.Disk = &clientset.Disk{LimitMB: mb}
+		}
+		sylixos.Resources = resources
+		hasSylixOS = true
+	}
+
+	if ps := template.PlatformSpecific; ps != nil {
+		if ps.Root != nil {
+			config.Root = &clientset.Root{Path: ps.Root.Path, Readonly: ps.Root.ReadOnly}
+		}
+		if ps.Platform != nil {
+			config.Platform = &clientset.Platform{OS: ps.Platform.OS, Arch: ps.Platform.Arch}
+		}
+		if s := ps.SylixOS; s != nil {
+			hasSylixOS = true
+			for _, d := range s.Devices {
+				sylixos.Devices = append(sylixos.Devices, clientset.Device{Path: d.Path, Access: d.Access})
+			}
+			if s.Network != nil {
+				sylixos.Network = &clientset.Network{FtpdEnable: s.Network.FTPD, TelnetdEnable: s.Network.TELNETD}
+			}
+			if s.CPU != nil || s.Memory != nil {
+				if sylixos.Resources == nil {
+					sylixos.Resources = &clientset.Resources{}
+				}
+			}
+			if s.CPU != nil {
+				cpu := &clientset.CPU{}
+				if s.CPU.HighestPrio != nil {
+					cpu.HighestPrio = int(*s.CPU.HighestPrio)
+				}
+				if s.CPU.LowestPrio != nil {
+					cpu.LowestPrio = int(*s.CPU.LowestPrio)
+				}
+				sylixos.Resources.CPU = cpu
+			}
+			if s.Memory != nil && s.Memory.KheapLimit != nil {
+				if sylixos.Resources.Memory == nil {
+					sylixos.Resources.Memory = &clientset.Memory{}
+				}
+				sylixos.Resources.Memory.KheapLimit = int(*s.Memory.KheapLimit)
+			}
+		}
+	}
+
+	if hasSylixOS {
+		config.SylixOS = sylixos
+	}
+	return config, nil
+}
+
+// buildImageVSOA 将 VSOASpec 翻译成 ECSM 的 VSOA 配置。
+// 使用 vsoa 的存活或就绪探针也被翻译成 ECSM 的 VSOA 健康检查，校验保证了它们与 vsoa.healthCheck 至多只有一个。
+func buildImageVSOA(template *ecsmv1.ContainerTemplateSpec) *clientset.ImageVSOA {
+	vsoa := template.VSOA
+	if vsoa == nil {
+		return nil
+	}
+	out := &clientset.ImageVSOA{Password: vsoa.Password}
+	if vsoa.Port != nil {
+		port := int(*vsoa.Port)
+		out.Port = &port
+	}
+	hc := vsoa.HealthCheck
+	for _, probe := range []*ecsmv1.Probe{template.LivenessProbe, template.ReadinessProbe} {
+		if probe != nil && probe.VSOA != nil {
+			hc = &probe.HealthCheckSpec
+			out.HealthPath = probe.VSOA.Path
+			break
+		}
+	}
+	if hc != nil {
+		out.HealthTimeout = intPtr(hc.TimeoutSeconds)
+		out.HealthRetries = intPtr(hc.FailureThreshold)
+		out.HealthStartPeriod = intPtr(hc.InitialDelaySeconds)
+		out.HealthInterval = intPtr(hc.PeriodSeconds)
+	}
+	return out
+}
+
+// quantityToMB 将 "512Mi"、"1Gi" 这样的资源数量字符串转换为 MB。
+func quantityToMB(value string) (int, error) {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	return int(q.Value() / (1024 * 1024)), nil
+}
+
+func intPtr(v int32) *int {
+	i := int(v)
+	return &i
+}
+
+// containerMatchesTemplate 判断一个正在运行的容器是否使用了模板中声明的镜像。
+// 模板中的镜像格式为 "name@tag"。
+func containerMatchesTemplate(c clientset.ContainerInfo, template *ecsmv1.ContainerTemplateSpec) bool {
+	name, tag, _ := strings.Cut(template.Image, "@")
+	// tag 后可能带有 "#os" 后缀
+	tag, _, _ = strings.Cut(tag, "#")
+	return c.ImageName == name && (tag == "" || c.ImageVersion == tag)
+}
@@ -0,0 +1,171 @@
+// file: pkg/controller/prober.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+// ContainerExecFunc 在容器中执行一条命令并返回它的退出码。
+// ECSM 的 API 不提供在容器中执行命令的接口，exec 探针只有在部署方提供了这个函数时才会执行。
+type ContainerExecFunc func(ctx context.Context, container clientset.ContainerInfo, command []string) (int, error)
+
+// probeResult 是一次探测的结果
+type probeResult int
+
+const (
+	// probeUnknown 表示探针没有执行：容器不在运行、仍在初始延迟内、探针由 ECSM 执行，或者无法执行
+	probeUnknown probeResult = iota
+	probeSuccess
+	probeFailure
+)
+
+// prober 执行由控制器负责的探针，即 tcpSocket 和 exec 探针。
+type prober struct {
+	exec ContainerExecFunc
+}
+
+// run 对容器执行一次探测。
+func (p *prober) run(ctx context.Context, probe *ecsmv1.Probe, co clientset.ContainerInfo) (probeResult, error) {
+	if probe == nil || probe.VSOA != nil || !isContainerReady(co) {
+		return probeUnknown, nil
+	}
+	if co.Uptime < int(probe.InitialDelaySeconds) {
+		return probeUnknown, nil
+	}
+	timeout := time.Duration(probe.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case probe.TCPSocket != nil:
+		if co.Address == "" {
+			return probeUnknown, fmt.Errorf("container %s has no address", co.Name)
+		}
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(co.Address, strconv.Itoa(int(probe.TCPSocket.Port))))
+		if err != nil {
+			return probeFailure, err
+		}
+		conn.Close()
+		return probeSuccess, nil
+	case probe.Exec != nil:
+		if p.exec == nil {
+			return probeUnknown, nil
+		}
+		code, err := p.exec(ctx, co, probe.Exec.Command)
+		if err != nil {
+			return probeFailure, err
+		}
+		if code != 0 {
+			return probeFailure, fmt.Errorf("command exited with code %d", code)
+		}
+		return probeSuccess, nil
+	}
+	return probeUnknown, nil
+}
+
+// probeState 记录了一个探针对一个容器的连续结果
+type probeState struct {
+	// lastProbe 是上一次执行探针的时间，用于按照 periodSeconds 控制探测频率
+	lastProbe time.Time
+	// failures 是连续失败的次数
+	failures int32
+}
+
+// due 判断距离上一次探测是否已经过了探针的周期。
+func (s *probeState) due(probe *ecsmv1.Probe, now time.Time) bool {
+	return now.Sub(s.lastProbe) >= time.Duration(probe.PeriodSeconds)*time.Second
+}
+
+// record 记录一次探测的结果，连续失败达到阈值时返回 true 并重新计数。
+func (s *probeState) record(probe *ecsmv1.Probe, result probeResult, now time.Time) bool {
+	s.lastProbe = now
+	switch result {
+	case probeSuccess:
+		s.failures = 0
+	case probeFailure:
+		s.failures++
+		threshold := probe.FailureThreshold
+		if threshold <= 0 {
+			threshold = 1
+		}
+		if s.failures >= threshold {
+			s.failures = 0
+			return true
+		}
+	}
+	return false
+}
+
+// ProbeResults 保存了就绪探针对每个容器的最新结论，以容器 ID 为索引。
+// RemediationController 写入结果，ECSMServiceController 在计算 status.readyReplicas 时读取它们。
+type ProbeResults struct {
+	lock  sync.RWMutex
+	ready map[string]bool
+}
+
+// NewProbeResults 创建一个空的 ProbeResults。
+func NewProbeResults() *ProbeResults {
+	return &ProbeResults{ready: make(map[string]bool)}
+}
+
+// Ready 返回容器是否就绪，没有探测结果时第二个返回值为 false。
+func (r *ProbeResults) Ready(containerID string) (bool, bool) {
+	if r == nil {
+		return false, false
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	ready, ok := r.ready[containerID]
+	return ready, ok
+}
+
+func (r *ProbeResults) set(containerID string, ready bool) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.ready[containerID] = ready
+}
+
+func (r *ProbeResults) forget(containerID string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.ready, containerID)
+}
+
+// containerReady 判断容器是否就绪：容器必须在运行，并且就绪探针没有判定它未就绪。
+// 还没有探测结果的容器 (包括探针无法执行时) 只根据运行状态判断。
+func (r *ProbeResults) containerReady(co clientset.ContainerInfo) bool {
+	if !isContainerReady(co) {
+		return false
+	}
+	ready, ok := r.Ready(co.ID)
+	return !ok || ready
+}
+
+// hasControllerProbes 判断服务的容器模板是否声明了需要控制器执行的探针。
+func hasControllerProbes(template *ecsmv1.ContainerTemplateSpec) bool {
+	for _, probe := range []*ecsmv1.Probe{template.LivenessProbe, template.ReadinessProbe} {
+		if probe != nil && probe.VSOA == nil {
+			return true
+		}
+	}
+	return false
+}
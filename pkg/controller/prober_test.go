@@ -0,0 +1,120 @@
+// file: pkg/controller/prober_test.go
+
+package controller
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+func TestProberTCPSocket(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	p := &prober{}
+	co := clientset.ContainerInfo{Name: "web-0", Status: "running", Address: "127.0.0.1", Uptime: 60}
+	probe := &ecsmv1.Probe{
+		ProbeHandler:    ecsmv1.ProbeHandler{TCPSocket: &ecsmv1.TCPSocketAction{Port: int32(port)}},
+		HealthCheckSpec: ecsmv1.HealthCheckSpec{TimeoutSeconds: 1},
+	}
+	if result, err := p.run(context.Background(), probe, co); result != probeSuccess {
+		t.Fatalf("probe of an open port = %v (%v), want success", result, err)
+	}
+
+	ln.Close()
+	if result, _ := p.run(context.Background(), probe, co); result != probeFailure {
+		t.Errorf("probe of a closed port = %v, want failure", result)
+	}
+
+	// 初始延迟内和不在运行的容器都不被探测
+	probe.InitialDelaySeconds = 120
+	if result, _ := p.run(context.Background(), probe, co); result != probeUnknown {
+		t.Errorf("probe within the initial delay = %v, want unknown", result)
+	}
+	probe.InitialDelaySeconds = 0
+	co.Status = "exited"
+	if result, _ := p.run(context.Background(), probe, co); result != probeUnknown {
+		t.Errorf("probe of an exited container = %v, want unknown", result)
+	}
+}
+
+func TestProberExec(t *testing.T) {
+	co := clientset.ContainerInfo{Name: "web-0", Status: "running"}
+	probe := &ecsmv1.Probe{ProbeHandler: ecsmv1.ProbeHandler{Exec: &ecsmv1.ExecAction{Command: []string{"check"}}}}
+
+	// 没有提供执行命令的方式时 exec 探针不会执行
+	if result, _ := (&prober{}).run(context.Background(), probe, co); result != probeUnknown {
+		t.Errorf("exec probe without an executor = %v, want unknown", result)
+	}
+
+	code := 1
+	p := &prober{exec: func(ctx context.Context, container clientset.ContainerInfo, command []string) (int, error) {
+		return code, nil
+	}}
+	if result, _ := p.run(context.Background(), probe, co); result != probeFailure {
+		t.Errorf("exec probe exiting with 1 = %v, want failure", result)
+	}
+	code = 0
+	if result, _ := p.run(context.Background(), probe, co); result != probeSuccess {
+		t.Errorf("exec probe exiting with 0 = %v, want success", result)
+	}
+}
+
+func TestProbeStateRecord(t *testing.T) {
+	probe := &ecsmv1.Probe{HealthCheckSpec: ecsmv1.HealthCheckSpec{PeriodSeconds: 10, FailureThreshold: 2}}
+	now := time.Now()
+	var s probeState
+
+	if !s.due(probe, now) {
+		t.Fatal("a probe that never ran is not due")
+	}
+	if s.record(probe, probeFailure, now) {
+		t.Fatal("one failure below the threshold reported as failed")
+	}
+	if s.due(probe, now.Add(5*time.Second)) {
+		t.Error("probe due before its period elapsed")
+	}
+	// 成功会重新开始计数
+	s.record(probe, probeSuccess, now.Add(10*time.Second))
+	s.record(probe, probeFailure, now.Add(20*time.Second))
+	if !s.record(probe, probeFailure, now.Add(30*time.Second)) {
+		t.Error("two consecutive failures not reported as failed")
+	}
+	if s.failures != 0 {
+		t.Errorf("failures = %d after reaching the threshold, want 0", s.failures)
+	}
+}
+
+func TestProbeResultsContainerReady(t *testing.T) {
+	running := clientset.ContainerInfo{ID: "c1", Status: "running"}
+
+	var nilResults *ProbeResults
+	if !nilResults.containerReady(running) {
+		t.Error("running container not ready without probe results")
+	}
+
+	r := NewProbeResults()
+	if !r.containerReady(running) {
+		t.Error("running container without a result not ready")
+	}
+	r.set("c1", false)
+	if r.containerReady(running) {
+		t.Error("container failing its readiness probe counted as ready")
+	}
+	r.forget("c1")
+	if !r.containerReady(running) {
+		t.Error("container not ready after its result was forgotten")
+	}
+	if r.containerReady(clientset.ContainerInfo{ID: "c2", Status: "exited"}) {
+		t.Error("exited container counted as ready")
+	}
+}
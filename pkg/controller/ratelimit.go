@@ -0,0 +1,42 @@
+// file: pkg/controller/ratelimit.go
+
+package controller
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// RateLimiterConfig 配置控制器工作队列的限速。
+// 它同时限制单个对象的失败重试间隔和整个队列的重试速率，
+// 避免大量对象同时失败时，重试请求压垮 ECSM 平台。
+type RateLimiterConfig struct {
+	// BaseDelay 是单个对象第一次失败后的重试间隔，之后每次失败翻倍
+	BaseDelay time.Duration
+	// MaxDelay 是单个对象重试间隔的上限
+	MaxDelay time.Duration
+	// QPS 是整个队列每秒允许的重试次数
+	QPS float64
+	// Burst 是整个队列允许的突发重试次数
+	Burst int
+}
+
+// DefaultRateLimiterConfig 返回与 client-go DefaultControllerRateLimiter 相同的参数。
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		BaseDelay: 5 * time.Millisecond,
+		MaxDelay:  1000 * time.Second,
+		QPS:       10,
+		Burst:     100,
+	}
+}
+
+// newRateLimiter 根据配置创建工作队列的限速器，取两种限速中较长的等待时间。
+func newRateLimiter(cfg RateLimiterConfig) workqueue.TypedRateLimiter[interface{}] {
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[interface{}](cfg.BaseDelay, cfg.MaxDelay),
+		&workqueue.TypedBucketRateLimiter[interface{}]{Limiter: rate.NewLimiter(rate.Limit(cfg.QPS), cfg.Burst)},
+	)
+}
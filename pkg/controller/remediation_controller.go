@@ -0,0 +1,600 @@
+// file: pkg/controller/remediation_controller.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/fx147/ecsm-operator/pkg/scheduler"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultRemediationSyncPeriod 是 RemediationController 默认的检查周期
+	DefaultRemediationSyncPeriod = 30 * time.Second
+
+	// remediationHealthyResetSyncs 是容器需要连续多少个周期保持健康，它的补救次数和 Degraded 标记才会被清除
+	remediationHealthyResetSyncs = 10
+
+	// remediationSyncTimeout 是一次全量检查的最长时间
+	remediationSyncTimeout = time.Minute
+
+	// remediationControllerName 是 RemediationController 在指标中的名称
+	remediationControllerName = "remediation"
+)
+
+// 健康补救相关的事件和状况原因
+const (
+	// ReasonRemediated 表示控制器对一个反复失败的容器采取了补救措施
+	ReasonRemediated = "Remediated"
+	// ReasonRemediationFailed 表示补救措施提交失败
+	ReasonRemediationFailed = "RemediationFailed"
+	// ReasonDegraded 表示服务因为容器持续失败而被标记为 Degraded
+	ReasonDegraded = "Degraded"
+	// ReasonRecovered 表示服务的容器恢复健康，Degraded 标记被清除
+	ReasonRecovered = "Recovered"
+	// ReasonUnhealthy 表示容器的存活探针或就绪探针连续失败
+	ReasonUnhealthy = "Unhealthy"
+	// ReasonPreempted 表示服务的实例被驱逐，为同一节点上失败的高优先级实例腾出资源
+	ReasonPreempted = "Preempted"
+
+	remediationReasonFailing = "ContainersFailing"
+	remediationReasonHealthy = "ContainersHealthy"
+)
+
+// RemediationControllerOptions 包含 RemediationController 的可调参数。
+type RemediationControllerOptions struct {
+	// Period 是检查容器健康状况的周期
+	Period time.Duration
+	// DryRun 为 true 时只记录将要采取的补救措施，不修改 ECSM 平台
+	DryRun bool
+	// Exec 在容器中执行 exec 探针的命令，为 nil 时 exec 探针不会执行
+	Exec ContainerExecFunc
+	// Readiness 保存就绪探针的结果，与 ECSMServiceController 共享后未就绪的容器不计入 status.readyReplicas
+	Readiness *ProbeResults
+}
+
+// DefaultRemediationControllerOptions 返回 RemediationController 的默认参数。
+func DefaultRemediationControllerOptions() RemediationControllerOptions {
+	return RemediationControllerOptions{Period: DefaultRemediationSyncPeriod}
+}
+
+// remediationDecision 是对一个容器的一次观察得出的结论
+type remediationDecision int
+
+const (
+	decisionNone remediationDecision = iota
+	decisionRemediate
+	decisionDegrade
+)
+
+// containerHealth 记录了控制器对一个容器的观察
+type containerHealth struct {
+	// restartCount 是最近一次观察到的重启次数
+	restartCount int
+	// failures 是自上一次补救以来累计的失败次数
+	failures int32
+	// remediations 是已经对该容器采取补救的次数
+	remediations int32
+	// healthyStreak 是容器连续保持健康的周期数
+	healthyStreak int
+	// degraded 表示该容器的补救措施已经用尽
+	degraded bool
+	// liveness 和 readiness 记录了存活探针和就绪探针的连续结果
+	liveness  probeState
+	readiness probeState
+}
+
+// observe 根据容器的最新状态和存活探针的结论更新健康记录，并决定是否需要补救。
+func (h *containerHealth) observe(co clientset.ContainerInfo, livenessFailed bool, policy *ecsmv1.RemediationPolicy) remediationDecision {
+	var newFailures int32
+	if co.RestartCount > h.restartCount {
+		newFailures += int32(co.RestartCount - h.restartCount)
+	}
+	h.restartCount = co.RestartCount
+	if !isContainerReady(co) || livenessFailed {
+		newFailures++
+	}
+
+	if newFailures == 0 {
+		h.healthyStreak++
+		if h.healthyStreak >= remediationHealthyResetSyncs {
+			h.failures, h.remediations, h.degraded = 0, 0, false
+		}
+		return decisionNone
+	}
+	h.healthyStreak = 0
+	h.failures += newFailures
+
+	if h.failures < remediationFailureThreshold(policy) {
+		return decisionNone
+	}
+	h.failures = 0
+	if remediationAction(policy) == ecsmv1.RemediationActionNone || h.remediations >= remediationMaxAttempts(policy) {
+		h.degraded = true
+		return decisionDegrade
+	}
+	h.remediations++
+	return decisionRemediate
+}
+
+// RemediationController 周期性地检查声明了 spec.remediation 的 ECSMService 的容器，
+// 对重启次数持续增长、不在运行或存活探针失败的容器采取补救措施，并在补救用尽时把服务标记为 Degraded。
+// 它同时执行容器模板中由控制器负责的存活探针和就绪探针 (tcpSocket 和 exec)，
+// 没有声明 spec.remediation 的服务只在存活探针失败时重启容器。
+// 重启失败的容器之前，如果它所在的节点内存不足，控制器会先把节点上优先级最低的其他服务的实例迁移走。
+// ECSM 自身只会在原节点上原地重启失败的容器，RemediationController 在此之上提供了
+// 按服务配置的阈值、换节点重新调度以及向用户暴露的 Degraded 状况。
+type RemediationController struct {
+	clusters *ClusterClients
+	registry registry.Interface
+	recorder record.EventRecorder
+
+	opts   RemediationControllerOptions
+	prober *prober
+
+	// health 以服务的 "namespace/name" 和容器 ID 为索引
+	health map[string]map[string]*containerHealth
+	lock   sync.Mutex
+
+	// services 和 priorities 是每次全量检查开始时的快照，用于选择被驱逐的低优先级实例，只被 syncAll 所在的 goroutine 访问
+	services   []ecsmv1.ECSMService
+	priorities *scheduler.Priorities
+}
+
+// NewRemediationController 创建一个新的 RemediationController。
+func NewRemediationController(
+	clusters *ClusterClients,
+	reg registry.Interface,
+	recorder record.EventRecorder,
+	opts RemediationControllerOptions,
+) *RemediationController {
+	return &RemediationController{
+		clusters: clusters,
+		registry: reg,
+		recorder: recorder,
+		opts:     opts,
+		prober:   &prober{exec: opts.Exec},
+		health:   make(map[string]map[string]*containerHealth),
+	}
+}
+
+// Run 启动周期性检查，直到 stopCh 被关闭。
+func (c *RemediationController) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting remediation controller")
+	defer klog.Info("Shutting down remediation controller")
+
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), remediationSyncTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := c.syncAll(ctx)
+		result := reconcileResult(ctx, err)
+		reconcileTotal.WithLabelValues(remediationControllerName, result).Inc()
+		reconcileDuration.WithLabelValues(remediationControllerName).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("failed to check container health: %w", err))
+		}
+	}, c.opts.Period, stopCh)
+}
+
+// syncAll 检查所有声明了补救策略或由控制器执行的探针的服务。
+func (c *RemediationController) syncAll(ctx context.Context) error {
+	services, _, err := c.registry.ListAllServices(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list ECSMServices: %w", err)
+	}
+	priorities, err := scheduler.LoadPriorities(ctx, c.registry)
+	if err != nil {
+		return err
+	}
+	c.services, c.priorities = services.Items, priorities
+
+	seen := make(map[string]bool)
+	var errs []error
+	for i := range services.Items {
+		service := &services.Items[i]
+		if (service.Spec.Remediation == nil && !hasControllerProbes(&service.Spec.Template)) || service.DeletionTimestamp != nil {
+			continue
+		}
+		key := serviceKey(service)
+		seen[key] = true
+		if err := c.syncService(ctx, key, service); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %w", key, err))
+		}
+	}
+	c.forgetServices(seen)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// syncService 观察一个服务的所有容器，对需要补救的容器采取措施，并更新服务的 Degraded 状况。
+func (c *RemediationController) syncService(ctx context.Context, key string, service *ecsmv1.ECSMService) error {
+	client, err := c.clusters.ForService(service)
+	if err != nil {
+		return err
+	}
+	containers, err := listOwnedContainers(ctx, client, service)
+	if err != nil {
+		return err
+	}
+
+	policy := service.Spec.Remediation
+	var toRemediate []clientset.ContainerInfo
+	var degraded []string
+	// 锁只保护 health 索引，containerHealth 本身只会被 syncAll 所在的 goroutine 访问，探测时不需要持有锁
+	c.lock.Lock()
+	records := c.health[key]
+	if records == nil {
+		records = make(map[string]*containerHealth)
+		c.health[key] = records
+	}
+	current := make(map[string]*containerHealth, len(containers))
+	for _, co := range containers {
+		h, ok := records[co.ID]
+		if !ok {
+			// 第一次见到的容器以当前的重启次数为基线，不把历史上的重启算作失败
+			h = &containerHealth{restartCount: co.RestartCount}
+			records[co.ID] = h
+		}
+		current[co.ID] = h
+	}
+	for id := range records {
+		if current[id] == nil {
+			delete(records, id)
+			c.opts.Readiness.forget(id)
+		}
+	}
+	c.lock.Unlock()
+
+	now := time.Now()
+	for _, co := range containers {
+		h := current[co.ID]
+		livenessFailed := c.probeLiveness(ctx, service, h, co, now)
+		c.probeReadiness(ctx, service, h, co, now)
+
+		if policy == nil {
+			// 没有补救策略时只处理存活探针的失败，与 ECSM 自身对失败容器的处理一样原地重启
+			if livenessFailed {
+				toRemediate = append(toRemediate, co)
+			}
+			continue
+		}
+		if h.observe(co, livenessFailed, policy) == decisionRemediate {
+			toRemediate = append(toRemediate, co)
+		}
+		if h.degraded {
+			degraded = append(degraded, co.Name)
+		}
+	}
+
+	var errs []error
+	for _, co := range toRemediate {
+		if err := c.remediate(ctx, client, service, co); err != nil {
+			c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonRemediationFailed, "Failed to remediate container %s: %v", co.Name, err)
+			errs = append(errs, err)
+		}
+	}
+	if err := c.updateDegradedCondition(ctx, service, degraded); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// probeLiveness 在存活探针到期时执行它，返回容器是否因为存活探针连续失败而需要被视为失败。
+func (c *RemediationController) probeLiveness(ctx context.Context, service *ecsmv1.ECSMService, h *containerHealth, co clientset.ContainerInfo, now time.Time) bool {
+	probe := service.Spec.Template.LivenessProbe
+	if probe == nil || probe.VSOA != nil || !h.liveness.due(probe, now) {
+		return false
+	}
+	result, err := c.prober.run(ctx, probe, co)
+	if err != nil {
+		klog.V(2).Infof("Service %s: liveness probe of container %s failed: %v", serviceKey(service), co.Name, err)
+	}
+	if !h.liveness.record(probe, result, now) {
+		return false
+	}
+	c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonUnhealthy, "Liveness probe of container %s failed %d times in a row", co.Name, probe.FailureThreshold)
+	return true
+}
+
+// probeReadiness 在就绪探针到期时执行它，并把容器是否就绪记录到 opts.Readiness 中。
+func (c *RemediationController) probeReadiness(ctx context.Context, service *ecsmv1.ECSMService, h *containerHealth, co clientset.ContainerInfo, now time.Time) {
+	probe := service.Spec.Template.ReadinessProbe
+	if probe == nil || probe.VSOA != nil {
+		c.opts.Readiness.forget(co.ID)
+		return
+	}
+	if !h.readiness.due(probe, now) {
+		return
+	}
+	result, err := c.prober.run(ctx, probe, co)
+	if err != nil {
+		klog.V(2).Infof("Service %s: readiness probe of container %s failed: %v", serviceKey(service), co.Name, err)
+	}
+	switch {
+	case h.readiness.record(probe, result, now):
+		if ready, ok := c.opts.Readiness.Ready(co.ID); !ok || ready {
+			c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonUnhealthy, "Readiness probe of container %s failed %d times in a row", co.Name, probe.FailureThreshold)
+		}
+		c.opts.Readiness.set(co.ID, false)
+	case result == probeSuccess:
+		c.opts.Readiness.set(co.ID, true)
+	}
+}
+
+// remediate 按照服务的补救策略处理一个失败的容器。
+func (c *RemediationController) remediate(ctx context.Context, client clientset.Interface, service *ecsmv1.ECSMService, co clientset.ContainerInfo) error {
+	action := remediationAction(service.Spec.Remediation)
+	if action == ecsmv1.RemediationActionReschedule {
+		rescheduled, err := c.reschedule(ctx, client, service, co)
+		if err != nil || rescheduled {
+			return err
+		}
+		klog.V(2).Infof("Service %s: container %s cannot be rescheduled to another node, restarting it instead", serviceKey(service), co.Name)
+	}
+
+	// 原地重启之前，先为容器腾出节点上被低优先级实例占用的内存。驱逐失败不影响重启
+	if err := c.preemptFor(ctx, client, service, co); err != nil {
+		runtime.HandleError(fmt.Errorf("service %s: failed to preempt for container %s: %w", serviceKey(service), co.Name, err))
+	}
+
+	if c.isDryRun(service) {
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonDryRun, "Would restart failing container %s on node %s", co.Name, co.NodeName)
+		return nil
+	}
+	if _, err := client.Containers().SubmitControlActionByName(ctx, co.Name, clientset.ActionRestart); err != nil {
+		return fmt.Errorf("failed to restart container %s: %w", co.Name, err)
+	}
+	klog.Infof("Service %s: restarted failing container %s on node %s", serviceKey(service), co.Name, co.NodeName)
+	c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonRemediated, "Restarted failing container %s on node %s", co.Name, co.NodeName)
+	return nil
+}
+
+// reschedule 把容器所在的节点从它所属平台服务的节点池中移除，让 ECSM 在其他节点上重新部署它。
+// 只有 Dynamic 策略、且节点池中还有其他节点时才能重新调度，否则返回 false。
+// 被移除的节点会在 ECSMServiceController 下一次按 spec 调整该平台服务时重新加入节点池。
+func (c *RemediationController) reschedule(ctx context.Context, client clientset.Interface, service *ecsmv1.ECSMService, co clientset.ContainerInfo) (bool, error) {
+	req, err := nodeExclusion(ctx, client, service, co)
+	if err != nil || req == nil {
+		return false, err
+	}
+
+	if c.isDryRun(service) {
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonDryRun, "Would reschedule failing container %s away from node %s", co.Name, co.NodeName)
+		return true, nil
+	}
+	if _, err := client.Services().Update(ctx, req.ID, req); err != nil {
+		return false, fmt.Errorf("failed to reschedule container %s: %w", co.Name, err)
+	}
+	klog.Infof("Service %s: rescheduling failing container %s away from node %s", serviceKey(service), co.Name, co.NodeName)
+	c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonRemediated, "Rescheduling failing container %s away from node %s", co.Name, co.NodeName)
+	return true, nil
+}
+
+// nodeExclusion 构造一个把容器所在的节点从它所属平台服务的节点池中移除的更新请求。
+// 只有 Dynamic 策略、且节点池中还有其他节点时容器才能被迁移，否则返回 nil。
+func nodeExclusion(ctx context.Context, client clientset.Interface, service *ecsmv1.ECSMService, co clientset.ContainerInfo) (*clientset.UpdateServiceRequest, error) {
+	if service.Spec.DeploymentStrategy.Type != ecsmv1.DeploymentStrategyTypeDynamic {
+		return nil, nil
+	}
+	current, err := client.Services().Get(ctx, co.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platform service of container %s: %w", co.Name, err)
+	}
+	if current.Node == nil {
+		return nil, nil
+	}
+	remaining := excludeNode(current.Node.Names, co.NodeName)
+	if len(remaining) == 0 || len(remaining) == len(current.Node.Names) {
+		return nil, nil
+	}
+
+	factor := current.Factor
+	req := &clientset.UpdateServiceRequest{
+		ID:     current.ID,
+		Name:   current.Name,
+		Node:   clientset.NodeSpec{Names: remaining},
+		Factor: &factor,
+		Policy: current.Policy,
+		Labels: current.Labels,
+	}
+	if current.Image != nil {
+		req.Image = *current.Image
+	}
+	return req, nil
+}
+
+// preemptFor 在失败的容器所在的节点内存不足时，把节点上优先级低于 service 的其他服务中优先级最低、
+// 且能够迁移的一个实例迁移到其他节点上，为失败的容器腾出资源。
+// 服务没有内存限制时无法判断节点是否内存不足，不会驱逐任何实例。
+func (c *RemediationController) preemptFor(ctx context.Context, client clientset.Interface, service *ecsmv1.ECSMService, co clientset.ContainerInfo) error {
+	memory := scheduler.InstanceMemory(service)
+	if memory == 0 || co.NodeID == "" || c.priorities == nil {
+		return nil
+	}
+	statuses, err := client.Nodes().ListStatus(ctx, []string{co.NodeID})
+	if err != nil {
+		return fmt.Errorf("failed to get runtime status of node %s: %w", co.NodeName, err)
+	}
+	if len(statuses) == 0 || statuses[0].MemoryFree >= memory {
+		return nil
+	}
+
+	// 按优先级从低到高排列优先级低于 service 的其他服务
+	priority := c.priorities.Of(service)
+	var candidates []*ecsmv1.ECSMService
+	for i := range c.services {
+		other := &c.services[i]
+		if other.UID != service.UID && c.priorities.Of(other) < priority {
+			candidates = append(candidates, other)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return c.priorities.Of(candidates[i]) < c.priorities.Of(candidates[j])
+	})
+
+	neighbors, err := client.Containers().ListAllByNode(ctx, clientset.ListContainersByNodeOptions{NodeIDs: []string{co.NodeID}})
+	if err != nil {
+		return fmt.Errorf("failed to list containers on node %s: %w", co.NodeName, err)
+	}
+	byServiceID := make(map[string][]clientset.ContainerInfo)
+	for _, n := range neighbors {
+		byServiceID[n.ServiceID] = append(byServiceID[n.ServiceID], n)
+	}
+
+	for _, victim := range candidates {
+		rows, err := listOwnedRows(ctx, client, victim)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			for _, vc := range byServiceID[row.ID] {
+				req, err := nodeExclusion(ctx, client, victim, vc)
+				if err != nil {
+					return err
+				}
+				if req == nil {
+					continue
+				}
+				if c.isDryRun(victim) {
+					c.recorder.Eventf(victim, ecsmv1.EventTypeNormal, ReasonDryRun, "Would move container %s away from node %s to make room for service %s",
+						vc.Name, vc.NodeName, serviceKey(service))
+					return nil
+				}
+				if _, err := client.Services().Update(ctx, req.ID, req); err != nil {
+					return fmt.Errorf("failed to move container %s of service %s: %w", vc.Name, serviceKey(victim), err)
+				}
+				klog.Infof("Service %s: preempted container %s of lower priority service %s on node %s",
+					serviceKey(service), vc.Name, serviceKey(victim), vc.NodeName)
+				c.recorder.Eventf(victim, ecsmv1.EventTypeWarning, ReasonPreempted, "Moving container %s away from node %s to make room for higher priority service %s",
+					vc.Name, vc.NodeName, serviceKey(service))
+				c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonPreempted, "Preempted container %s of lower priority service %s on node %s",
+					vc.Name, serviceKey(victim), vc.NodeName)
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// updateDegradedCondition 根据补救措施已经用尽的容器设置服务的 Degraded 状况，只在状况变化时写回 Registry。
+func (c *RemediationController) updateDegradedCondition(ctx context.Context, service *ecsmv1.ECSMService, degraded []string) error {
+	cond := metav1.Condition{
+		Type:    ecsmv1.ServiceDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  remediationReasonHealthy,
+		Message: "No container has exhausted its remediations",
+	}
+	if len(degraded) > 0 {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = remediationReasonFailing
+		cond.Message = fmt.Sprintf("Containers keep failing after remediation: %s", strings.Join(degraded, ", "))
+	}
+
+	// 服务从未退化过时不需要写入 False 状况
+	existing := ecsmmeta.GetCondition(service.Status.Conditions, ecsmv1.ServiceDegraded)
+	if (existing == nil && cond.Status == metav1.ConditionFalse) || !ecsmmeta.ConditionChanged(service.Status.Conditions, cond) {
+		return nil
+	}
+
+	// 重新读取服务，尽量减小覆盖 ECSMServiceController 刚写入的状态的窗口
+	latest, err := c.registry.GetService(ctx, service.Namespace, service.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	ecsmmeta.SetCondition(&latest.Status.Conditions, cond)
+	if _, err := c.registry.UpdateServiceStatus(ctx, latest); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	if cond.Status == metav1.ConditionTrue {
+		c.recorder.Event(service, ecsmv1.EventTypeWarning, ReasonDegraded, cond.Message)
+	} else {
+		c.recorder.Event(service, ecsmv1.EventTypeNormal, ReasonRecovered, "All containers are healthy again")
+	}
+	return nil
+}
+
+// isDryRun 与 ECSMServiceController 的判断相同：全局选项和对象上的 DryRunAnnotation 任一生效即可。
+func (c *RemediationController) isDryRun(service *ecsmv1.ECSMService) bool {
+	return c.opts.DryRun || service.Annotations[ecsmv1.DryRunAnnotation] == "true"
+}
+
+// forgetServices 丢弃已经不再需要补救的服务的健康记录。
+func (c *RemediationController) forgetServices(existing map[string]bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key, records := range c.health {
+		if !existing[key] {
+			for id := range records {
+				c.opts.Readiness.forget(id)
+			}
+			delete(c.health, key)
+		}
+	}
+}
+
+// excludeNode 返回去掉 node 之后的节点列表。
+func excludeNode(nodes []string, node string) []string {
+	var result []string
+	for _, n := range nodes {
+		if n != node {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+func remediationAction(policy *ecsmv1.RemediationPolicy) ecsmv1.RemediationActionType {
+	if policy == nil || policy.Action == "" {
+		return ecsmv1.RemediationActionRestart
+	}
+	return policy.Action
+}
+
+func remediationFailureThreshold(policy *ecsmv1.RemediationPolicy) int32 {
+	if policy.FailureThreshold != nil && *policy.FailureThreshold > 0 {
+		return *policy.FailureThreshold
+	}
+	return defaults.DefaultRemediationFailureThreshold
+}
+
+func remediationMaxAttempts(policy *ecsmv1.RemediationPolicy) int32 {
+	if policy.MaxRemediations != nil && *policy.MaxRemediations >= 0 {
+		return *policy.MaxRemediations
+	}
+	return defaults.DefaultMaxRemediations
+}
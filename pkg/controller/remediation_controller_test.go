@@ -0,0 +1,67 @@
+// file: pkg/controller/remediation_controller_test.go
+
+package controller
+
+import (
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+func TestContainerHealthObserve(t *testing.T) {
+	threshold, maxRemediations := int32(2), int32(1)
+	policy := &ecsmv1.RemediationPolicy{FailureThreshold: &threshold, MaxRemediations: &maxRemediations}
+	h := &containerHealth{}
+
+	running := func(restarts int) clientset.ContainerInfo {
+		return clientset.ContainerInfo{Status: "running", RestartCount: restarts}
+	}
+
+	if d := h.observe(running(1), false, policy); d != decisionNone {
+		t.Fatalf("one restart below the threshold: decision = %v, want none", d)
+	}
+	if d := h.observe(running(2), false, policy); d != decisionRemediate {
+		t.Fatalf("threshold reached: decision = %v, want remediate", d)
+	}
+	// 补救次数用尽后再次达到阈值，服务被标记为 Degraded
+	if d := h.observe(clientset.ContainerInfo{Status: "exited", RestartCount: 3}, false, policy); d != decisionDegrade {
+		t.Fatalf("remediations exhausted: decision = %v, want degrade", d)
+	}
+	if !h.degraded {
+		t.Fatal("container not marked degraded")
+	}
+
+	for i := 0; i < remediationHealthyResetSyncs; i++ {
+		h.observe(running(3), false, policy)
+	}
+	if h.degraded || h.remediations != 0 {
+		t.Errorf("health not reset after %d healthy syncs: %+v", remediationHealthyResetSyncs, h)
+	}
+}
+
+func TestContainerHealthActionNone(t *testing.T) {
+	threshold := int32(1)
+	policy := &ecsmv1.RemediationPolicy{Action: ecsmv1.RemediationActionNone, FailureThreshold: &threshold}
+	h := &containerHealth{}
+	if d := h.observe(clientset.ContainerInfo{Status: "exited"}, false, policy); d != decisionDegrade {
+		t.Errorf("decision = %v, want degrade without remediation", d)
+	}
+}
+
+func TestContainerHealthLivenessFailure(t *testing.T) {
+	threshold := int32(1)
+	policy := &ecsmv1.RemediationPolicy{FailureThreshold: &threshold}
+	h := &containerHealth{}
+	// 容器仍在运行且没有重启，但存活探针失败
+	if d := h.observe(clientset.ContainerInfo{Status: "running"}, true, policy); d != decisionRemediate {
+		t.Errorf("decision = %v, want remediate on liveness failure", d)
+	}
+}
+
+func TestExcludeNode(t *testing.T) {
+	got := excludeNode([]string{"a", "b", "c"}, "b")
+	if len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("excludeNode() = %v, want [a c]", got)
+	}
+}
@@ -0,0 +1,357 @@
+// file: pkg/controller/rollout.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// syncRevisions 调谐一个 ECSMService 名下的所有修订版本 (平台服务)，使其逐步收敛到期望状态。
+// 它返回当前修订版本 (可能为 nil) 以及 rollout 是否已经完成。
+// rollout 未完成时，调用方应该稍后重新入队，以推进下一步。
+func (c *ECSMServiceController) syncRevisions(ctx context.Context, service *ecsmv1.ECSMService, revisions []*platformService) (*platformService, bool, error) {
+	hash := computeTemplateHash(&service.Spec.Template)
+	desired := desiredReplicas(service)
+
+	var newRev *platformService
+	var oldRevs []*platformService
+	for _, rev := range revisions {
+		if rev.TemplateHash == hash {
+			newRev = rev
+		} else {
+			oldRevs = append(oldRevs, rev)
+		}
+	}
+
+	// 没有旧修订版本，说明模板没有漂移，只需要保证副本数
+	if len(oldRevs) == 0 {
+		if newRev == nil {
+			klog.Infof("Service %s/%s: no platform service found, creating %s with %d replica(s)",
+				service.Namespace, service.Name, platformServiceName(service, hash), desired)
+			created, err := c.createPlatformService(ctx, service, hash, desired)
+			if err == nil && !c.isDryRun(service) {
+				c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonScaledUp,
+					"Created platform service %s with %d replica(s)", created.Row.Name, desired)
+			}
+			return created, false, err
+		}
+		// 平台服务被带外修改时，先处理漂移
+		if drifted, err := c.handleDrift(ctx, service, newRev); drifted || err != nil {
+			return newRev, service.Spec.DriftPolicy == ecsmv1.DriftPolicyReportOnly && err == nil, err
+		}
+		if current := newRev.replicas(); current != desired {
+			klog.Infof("Service %s/%s: desired replicas (%d) != actual (%d), scaling %s",
+				service.Namespace, service.Name, desired, current, newRev.Row.Name)
+			if err := c.scalePlatformService(ctx, service, newRev, desired); err != nil {
+				return newRev, false, err
+			}
+			if !c.isDryRun(service) {
+				c.recordScaled(service, newRev.Row.Name, current, desired)
+			}
+			return newRev, false, nil
+		}
+		// 可选的节点发生了变化时 (nodeSelector 或 affinity)，把实例调整到新选出的节点上
+		if rescheduled, err := c.rescheduleNodes(ctx, service, newRev, desired); rescheduled || err != nil {
+			return newRev, false, err
+		}
+		// 发布已经完成
+		service.Status.Rollout = nil
+		return newRev, true, nil
+	}
+
+	rollout := service.Spec.UpgradeStrategy.Rollout
+	if rollout == "" {
+		rollout = ecsmv1.RolloutTypeRollingUpdate
+	}
+	klog.Infof("Service %s/%s: template drift detected (%d old revision(s)), %s to %s",
+		service.Namespace, service.Name, len(oldRevs), rollout, platformServiceName(service, hash))
+	if newRev == nil {
+		// 新修订版本还不存在，说明这是第一次发现模板变化
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonDriftDetected,
+			"Template changed, starting %s to revision %s", rollout, hash)
+	}
+
+	remaining := liveRevisions(oldRevs)
+	var err error
+	switch rollout {
+	case ecsmv1.RolloutTypeCanary:
+		newRev, err = c.rolloutCanary(ctx, service, hash, newRev, oldRevs)
+	case ecsmv1.RolloutTypeBlueGreen:
+		newRev, err = c.rolloutBlueGreen(ctx, service, hash, newRev, oldRevs)
+	default:
+		newRev, err = c.rolloutRolling(ctx, service, hash, newRev, oldRevs)
+	}
+	// 这一步删除了最后一个旧修订版本，发布完成
+	if err == nil && remaining > 0 && liveRevisions(oldRevs) == 0 && newRev != nil && !c.isDryRun(service) {
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonRolloutComplete,
+			"Rolled out revision %s with %d replica(s)", newRev.Row.Name, newRev.replicas())
+	}
+	return newRev, false, err
+}
+
+// liveRevisions 返回还有实例的修订版本的数量，被删除的修订版本的副本数为 0。
+func liveRevisions(revisions []*platformService) int {
+	n := 0
+	for _, rev := range revisions {
+		if rev.replicas() > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// rolloutRolling 执行一步滚动更新：在 maxSurge 允许的范围内扩容新修订版本，
+// 并在 maxUnavailable 允许的范围内缩容旧修订版本。
+func (c *ECSMServiceController) rolloutRolling(ctx context.Context, service *ecsmv1.ECSMService, hash string, newRev *platformService, oldRevs []*platformService) (*platformService, error) {
+	desired := desiredReplicas(service)
+	maxSurge, maxUnavailable, err := resolveFenceposts(&service.Spec.UpgradeStrategy, desired)
+	if err != nil {
+		return newRev, err
+	}
+
+	var newReplicas, newReady int32
+	if newRev != nil {
+		newReplicas = newRev.replicas()
+		newReady = newRev.readyReplicas()
+	}
+	totalReplicas := newReplicas
+	availableReplicas := newReady
+	for _, old := range oldRevs {
+		totalReplicas += old.replicas()
+		availableReplicas += old.readyReplicas()
+	}
+
+	// --- 1. 扩容新修订版本 ---
+	target := newRevisionReplicas(desired, newReplicas, totalReplicas, maxSurge)
+	if target != newReplicas {
+		klog.Infof("Service %s/%s: scaling new revision %s from %d to %d",
+			service.Namespace, service.Name, platformServiceName(service, hash), newReplicas, target)
+		if newRev == nil {
+			newRev, err = c.createPlatformService(ctx, service, hash, target)
+		} else {
+			err = c.scalePlatformService(ctx, service, newRev, target)
+		}
+		if err != nil {
+			return newRev, err
+		}
+		if !c.isDryRun(service) {
+			c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonRollingUpdate,
+				"Scaled up new revision %s from %d to %d", newRev.Row.Name, newReplicas, target)
+		}
+	}
+
+	// --- 2. 缩容旧修订版本 ---
+	// 新修订版本中尚未就绪的实例也会占用不可用额度，以避免在新实例起不来时把旧实例全部删掉
+	minAvailable := desired - maxUnavailable
+	cleanupBudget := totalReplicas - minAvailable - (newReplicas - newReady)
+	if cleanupBudget <= 0 {
+		return newRev, nil
+	}
+
+	// 优先清理旧修订版本中不可用的实例，它们不会影响服务的可用性
+	for _, old := range oldRevs {
+		unhealthy := old.replicas() - old.readyReplicas()
+		if cleanupBudget <= 0 || unhealthy <= 0 {
+			continue
+		}
+		step := min(unhealthy, cleanupBudget)
+		if err := c.scaleDownOldRevision(ctx, service, old, old.replicas()-step); err != nil {
+			return newRev, err
+		}
+		cleanupBudget -= step
+	}
+
+	// 再在保证最小可用数的前提下缩容旧修订版本中的可用实例
+	scaleDown := availableReplicas - minAvailable
+	for _, old := range oldRevs {
+		if scaleDown <= 0 {
+			break
+		}
+		if old.replicas() <= 0 {
+			continue
+		}
+		step := min(old.replicas(), scaleDown)
+		if err := c.scaleDownOldRevision(ctx, service, old, old.replicas()-step); err != nil {
+			return newRev, err
+		}
+		scaleDown -= step
+	}
+
+	return newRev, nil
+}
+
+// scaleDownOldRevision 将一个旧修订版本缩容到 replicas 个实例，缩容到 0 时直接删除该平台服务。
+func (c *ECSMServiceController) scaleDownOldRevision(ctx context.Context, service *ecsmv1.ECSMService, old *platformService, replicas int32) error {
+	if replicas <= 0 {
+		if c.isDryRun(service) {
+			c.planAction(service, "delete old revision %s", old.Row.Name)
+			old.Row.Factor = 0
+			return nil
+		}
+		klog.Infof("Service %s/%s: deleting old revision %s", service.Namespace, service.Name, old.Row.Name)
+		c.expectations.expectDeletion(serviceKey(service), old.Row.ID)
+		resp, err := c.clientFor(service).Services().Delete(ctx, old.Row.ID)
+		if err != nil {
+			c.expectations.deleteExpectations(serviceKey(service))
+			c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonScaleFailed,
+				"Failed to delete old revision %s: %v", old.Row.Name, err)
+			return fmt.Errorf("failed to delete old platform service %s: %w", old.Row.Name, err)
+		}
+		old.Row.Factor = 0
+		c.trackTransaction(service, resp.ID, operationDelete, old.Row.Name)
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonRollingUpdate,
+			"Deleted old revision %s", old.Row.Name)
+		return nil
+	}
+	current := old.replicas()
+	klog.Infof("Service %s/%s: scaling old revision %s from %d to %d",
+		service.Namespace, service.Name, old.Row.Name, current, replicas)
+	if err := c.scalePlatformService(ctx, service, old, replicas); err != nil {
+		return err
+	}
+	if !c.isDryRun(service) {
+		c.recorder.Eventf(service, ecsmv1.EventTypeNormal, ReasonRollingUpdate,
+			"Scaled down old revision %s from %d to %d", old.Row.Name, current, replicas)
+	}
+	return nil
+}
+
+// recordScaled 为一次副本数调整记录 ScaledUp 或 ScaledDown 事件。
+func (c *ECSMServiceController) recordScaled(service *ecsmv1.ECSMService, name string, from, to int32) {
+	reason := ReasonScaledUp
+	if to < from {
+		reason = ReasonScaledDown
+	}
+	c.recorder.Eventf(service, ecsmv1.EventTypeNormal, reason, "Scaled platform service %s from %d to %d", name, from, to)
+}
+
+// createPlatformService 为当前模板创建一个新的平台服务。
+func (c *ECSMServiceController) createPlatformService(ctx context.Context, service *ecsmv1.ECSMService, hash string, replicas int32) (*platformService, error) {
+	req, err := buildCreateServiceRequest(service, hash, replicas)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.scheduleNodes(ctx, service, replicas, &req.Node, nil); err != nil {
+		return nil, err
+	}
+	if c.isDryRun(service) {
+		c.planAction(service, "create platform service %s with %d replica(s) of %s", req.Name, replicas, service.Spec.Template.Image)
+		return &platformService{
+			Row:          clientset.ProvisionListRow{Name: req.Name, Factor: int(replicas)},
+			TemplateHash: hash,
+		}, nil
+	}
+	// 提交前设置期望；提交失败时清除它，以便下一轮可以立即重试
+	c.expectations.expectCreation(serviceKey(service), req.Name)
+	resp, err := c.clientFor(service).Services().Create(ctx, req)
+	if err != nil {
+		c.expectations.deleteExpectations(serviceKey(service))
+		c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonCreateFailed,
+			"Failed to create platform service %s: %v", req.Name, err)
+		return nil, fmt.Errorf("failed to create platform service %s: %w", req.Name, err)
+	}
+	return &platformService{
+		Row:          clientset.ProvisionListRow{ID: resp.ID, Name: req.Name, Factor: int(replicas)},
+		TemplateHash: hash,
+	}, nil
+}
+
+// scalePlatformService 修改一个平台服务的副本数，保持其镜像配置不变。
+func (c *ECSMServiceController) scalePlatformService(ctx context.Context, service *ecsmv1.ECSMService, ps *platformService, replicas int32) error {
+	if c.isDryRun(service) {
+		c.planAction(service, "scale platform service %s from %d to %d", ps.Row.Name, ps.replicas(), replicas)
+		ps.Row.Factor = int(replicas)
+		return nil
+	}
+	current, err := c.clientFor(service).Services().Get(ctx, ps.Row.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get platform service %s: %w", ps.Row.Name, err)
+	}
+
+	req := &clientset.UpdateServiceRequest{
+		ID:     current.ID,
+		Name:   current.Name,
+		Policy: current.Policy,
+		Labels: withDesiredReplicas(current.Labels, replicas),
+	}
+	if current.Image != nil {
+		req.Image = *current.Image
+	}
+	if current.Node != nil {
+		req.Node = *current.Node
+	}
+
+	if ps.TemplateHash == computeTemplateHash(&service.Spec.Template) {
+		// 当前修订版本：节点配置总是以 spec 为准
+		req.Node, req.Factor = nodeSpecFor(service, replicas)
+		if err := c.scheduleNodes(ctx, service, replicas, &req.Node, rowNodeNames(ps.Row)); err != nil {
+			return err
+		}
+	} else if service.Spec.DeploymentStrategy.Type == ecsmv1.DeploymentStrategyTypeStatic {
+		// 旧修订版本在 Static 策略下通过减少节点来缩容
+		if int(replicas) < len(req.Node.Names) {
+			req.Node.Names = req.Node.Names[:replicas]
+		}
+	} else {
+		factor := int(replicas)
+		req.Factor = &factor
+	}
+
+	if _, err := c.clientFor(service).Services().Update(ctx, req.ID, req); err != nil {
+		c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonScaleFailed,
+			"Failed to scale platform service %s to %d: %v", ps.Row.Name, replicas, err)
+		return fmt.Errorf("failed to scale platform service %s: %w", ps.Row.Name, err)
+	}
+	ps.Row.Factor = int(replicas)
+	return nil
+}
+
+// serviceKey 返回 ECSMService 在工作队列和期望缓存中使用的 key。
+func serviceKey(service *ecsmv1.ECSMService) string {
+	return cache.MetaObjectToName(service).String()
+}
+
+// resolveFenceposts 将 maxSurge 和 maxUnavailable 解析为基于期望副本数的绝对值。
+// 如果两者都解析为 0，maxUnavailable 会被设置为 1，否则滚动更新将无法推进。
+func resolveFenceposts(strategy *ecsmv1.UpgradeStrategy, desired int32) (int32, int32, error) {
+	surge := intstr.FromString(defaults.DefaultMaxSurge)
+	if strategy.MaxSurge != nil {
+		surge = *strategy.MaxSurge
+	}
+	unavailable := intstr.FromString(defaults.DefaultMaxUnavailable)
+	if strategy.MaxUnavailable != nil {
+		unavailable = *strategy.MaxUnavailable
+	}
+
+	maxSurge, err := intstr.GetScaledValueFromIntOrPercent(&surge, int(desired), true)
+	if err != nil {
+		return 0, 0, newSpecError("invalid maxSurge: %w", err)
+	}
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(&unavailable, int(desired), false)
+	if err != nil {
+		return 0, 0, newSpecError("invalid maxUnavailable: %w", err)
+	}
+
+	if maxSurge == 0 && maxUnavailable == 0 {
+		maxUnavailable = 1
+	}
+	return int32(maxSurge), int32(maxUnavailable), nil
+}
+
+// newRevisionReplicas 计算新修订版本在本轮可以扩容到的副本数。
+// 所有修订版本的副本总数不能超过 desired + maxSurge，新修订版本也不会超过 desired。
+func newRevisionReplicas(desired, newReplicas, totalReplicas, maxSurge int32) int32 {
+	maxTotal := desired + maxSurge
+	if totalReplicas >= maxTotal {
+		return newReplicas
+	}
+	return min(newReplicas+maxTotal-totalReplicas, desired)
+}
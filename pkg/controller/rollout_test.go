@@ -0,0 +1,117 @@
+// file: pkg/controller/rollout_test.go
+
+package controller
+
+import (
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestResolveFenceposts(t *testing.T) {
+	intOrStr := func(v intstr.IntOrString) *intstr.IntOrString { return &v }
+
+	tests := []struct {
+		name            string
+		strategy        ecsmv1.UpgradeStrategy
+		desired         int32
+		wantSurge       int32
+		wantUnavailable int32
+	}{
+		{"defaults", ecsmv1.UpgradeStrategy{}, 4, 1, 1},
+		{"defaults round surge up and unavailable down", ecsmv1.UpgradeStrategy{}, 3, 1, 0},
+		{"absolute values", ecsmv1.UpgradeStrategy{MaxSurge: intOrStr(intstr.FromInt32(2)), MaxUnavailable: intOrStr(intstr.FromInt32(0))}, 5, 2, 0},
+		{"both zero forces one unavailable", ecsmv1.UpgradeStrategy{MaxSurge: intOrStr(intstr.FromInt32(0)), MaxUnavailable: intOrStr(intstr.FromString("0%"))}, 5, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			surge, unavailable, err := resolveFenceposts(&tt.strategy, tt.desired)
+			if err != nil {
+				t.Fatalf("resolveFenceposts() error = %v", err)
+			}
+			if surge != tt.wantSurge || unavailable != tt.wantUnavailable {
+				t.Errorf("resolveFenceposts() = (%d, %d), want (%d, %d)", surge, unavailable, tt.wantSurge, tt.wantUnavailable)
+			}
+		})
+	}
+}
+
+func TestNewRevisionReplicas(t *testing.T) {
+	tests := []struct {
+		name                                  string
+		desired, newReplicas, total, maxSurge int32
+		want                                  int32
+	}{
+		{"surge room available", 3, 0, 3, 1, 1},
+		{"no surge room", 3, 1, 4, 1, 1},
+		{"never exceeds desired", 3, 2, 2, 5, 3},
+		{"old revisions already gone", 3, 1, 1, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newRevisionReplicas(tt.desired, tt.newReplicas, tt.total, tt.maxSurge); got != tt.want {
+				t.Errorf("newRevisionReplicas() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePlatformServiceName(t *testing.T) {
+	service := &ecsmv1.ECSMService{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	hash := computeTemplateHash(&service.Spec.Template)
+
+	if got, ok := parsePlatformServiceName(service, platformServiceName(service, hash)); !ok || got != hash {
+		t.Errorf("parsePlatformServiceName() = (%q, %v), want (%q, true)", got, ok, hash)
+	}
+	for _, name := range []string{"app", "app-", "other-abc", "app-foo-abc"} {
+		if _, ok := parsePlatformServiceName(service, name); ok {
+			t.Errorf("parsePlatformServiceName(%q) should not match", name)
+		}
+	}
+}
+
+// TestPlatformServiceName_Namespaces 测试不同命名空间中的同名服务使用不同的平台服务名称，
+// 并且不会认领彼此的平台服务。
+func TestPlatformServiceName_Namespaces(t *testing.T) {
+	defaultApp := &ecsmv1.ECSMService{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: metav1.NamespaceDefault}}
+	prodApp := &ecsmv1.ECSMService{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "prod"}}
+	testApp := &ecsmv1.ECSMService{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"}}
+	hash := computeTemplateHash(&defaultApp.Spec.Template)
+
+	// default 命名空间沿用原来的名称，已有的平台服务不会因为升级而被重建
+	if got := platformServiceName(defaultApp, hash); got != "app-"+hash {
+		t.Errorf("platformServiceName() in the default namespace = %q, want %q", got, "app-"+hash)
+	}
+
+	names := map[string]*ecsmv1.ECSMService{}
+	for _, service := range []*ecsmv1.ECSMService{defaultApp, prodApp, testApp} {
+		name := platformServiceName(service, hash)
+		if other, ok := names[name]; ok {
+			t.Fatalf("%s/%s and %s/%s both use platform service name %q", service.Namespace, service.Name, other.Namespace, other.Name, name)
+		}
+		names[name] = service
+	}
+	for name, owner := range names {
+		for _, service := range []*ecsmv1.ECSMService{defaultApp, prodApp, testApp} {
+			got, ok := parsePlatformServiceName(service, name)
+			if want := service == owner; ok != want || (ok && got != hash) {
+				t.Errorf("parsePlatformServiceName(%s/%s, %q) = (%q, %v), want a match %v", service.Namespace, service.Name, name, got, ok, want)
+			}
+		}
+	}
+}
+
+func TestComputeTemplateHashChangesWithTemplate(t *testing.T) {
+	a := &ecsmv1.ContainerTemplateSpec{Image: "njust@1.0"}
+	b := &ecsmv1.ContainerTemplateSpec{Image: "njust@1.1"}
+	if computeTemplateHash(a) == computeTemplateHash(b) {
+		t.Errorf("expected different hashes for different images")
+	}
+	if computeTemplateHash(a) != computeTemplateHash(a.DeepCopy()) {
+		t.Errorf("expected identical hashes for identical templates")
+	}
+}
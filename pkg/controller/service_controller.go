@@ -6,13 +6,19 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
 	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
 	"github.com/fx147/ecsm-operator/pkg/informer"
+	"github.com/fx147/ecsm-operator/pkg/record"
 	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/fx147/ecsm-operator/pkg/scheduler"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
@@ -23,13 +29,65 @@ import (
 const (
 	// maxRetries 是一个 key 在被放弃前的最大重试次数。
 	maxRetries = 15
+
+	// rolloutRequeueInterval 是副本调整或滚动更新尚未完成时，重新检查进度的间隔。
+	rolloutRequeueInterval = 10 * time.Second
+
+	// DefaultReconcileTimeout 是单次调谐默认的最长时间。
+	DefaultReconcileTimeout = 2 * time.Minute
+
+	// DefaultRequeueAfter 是服务收敛之后默认的重新验证间隔。
+	DefaultRequeueAfter = 5 * time.Minute
+
+	// serviceControllerName 是 ECSMServiceController 在指标中的名称
+	serviceControllerName = "ecsmservice"
+)
+
+// 控制器记录的事件原因
+const (
+	// ReasonScaledUp 表示某个修订版本的副本数被增加 (包括首次创建)
+	ReasonScaledUp = "ScaledUp"
+	// ReasonScaledDown 表示某个修订版本的副本数被减少 (包括删除旧修订版本)
+	ReasonScaledDown = "ScaledDown"
+	// ReasonCreateFailed 表示平台服务创建失败
+	ReasonCreateFailed = "CreateFailed"
+	// ReasonScaleFailed 表示平台服务的副本数调整或删除失败
+	ReasonScaleFailed = "ScaleFailed"
+	// ReasonDriftDetected 表示 spec.template 与平台上运行的修订版本不一致
+	ReasonDriftDetected = "DriftDetected"
+	// ReasonRollingUpdate 表示滚动更新推进了一步
+	ReasonRollingUpdate = "RollingUpdate"
+	// ReasonRolloutComplete 表示最后一个旧修订版本被删除，新修订版本的发布已经完成
+	ReasonRolloutComplete = "RolloutComplete"
+	// ReasonCleanupFailed 表示删除 ECSMService 时未能清理掉对应的平台服务
+	ReasonCleanupFailed = "CleanupFailed"
+	// ReasonAdopted 表示一个已存在的平台服务被 ECSMService 认领
+	ReasonAdopted = "Adopted"
+	// ReasonTransactionFailed 表示控制器提交的 ECSM 事务失败或超时
+	ReasonTransactionFailed = "TransactionFailed"
+	// ReasonInvalidSpec 表示 spec 无法被翻译成 ECSM 请求，控制器不会重试，直到 spec 被修改
+	ReasonInvalidSpec = "InvalidSpec"
+	// ReasonFailedScheduling 表示没有节点能够容纳服务的实例
+	ReasonFailedScheduling = "FailedScheduling"
+	// ReasonRescheduled 表示服务可选的节点发生变化，平台服务被调整到新选出的节点上
+	ReasonRescheduled = "Rescheduled"
+	// ReasonTaintEviction 表示服务的实例因为节点上不能容忍的 NoExecute 污点被驱逐
+	ReasonTaintEviction = "TaintEviction"
+)
+
+// Available 状况的原因
+const (
+	// reasonMinimumReplicasAvailable 表示就绪副本数已经达到期望的副本数
+	reasonMinimumReplicasAvailable = "MinimumReplicasAvailable"
+	// reasonMinimumReplicasUnavailable 表示就绪副本数少于期望的副本数
+	reasonMinimumReplicasUnavailable = "MinimumReplicasUnavailable"
 )
 
 // ECSMServiceController 负责监听 ECSMService 对象的变更，
 // 并确保 ECSM 平台上的真实状态与对象的 spec 保持一致。
 type ECSMServiceController struct {
-	// clientset 用于与 ECSM API Server 交互 (现实世界)
-	ecsmClient clientset.Interface
+	// clusters 保存了每个 ECSM 集群的客户端，用于与 ECSM API Server 交互 (现实世界)
+	clusters *ClusterClients
 
 	// registry 用于更新我们自己存储中的对象状态 (期望世界)
 	registry registry.Interface
@@ -39,24 +97,94 @@ type ECSMServiceController struct {
 	// 为了简化，我们先假设 Informer 提供了 Get 方法。
 	serviceInformer informer.Informer // 我们自己的 Informer
 
+	// informersSynced 在所有 Informer 完成第一次同步后返回 true
+	informersSynced []cache.InformerSynced
+
+	// recorder 用于记录调谐过程中发生的事件，它们会被持久化到 Registry 中。
+	recorder record.EventRecorder
+
+	// expectations 记录了已提交、但尚未在平台上观察到的创建和删除，防止重复操作。
+	expectations *controllerExpectations
+
+	// scheduler 为 Dynamic 策略的服务从节点池中选择具体的节点，为 nil 时把整个节点池交给 ECSM。
+	// ECSMNode 只反映默认集群的节点，所以它只用于默认集群中的服务。
+	scheduler *scheduler.Scheduler
+
+	// dryRun 为 true 时，所有服务都只计划、不执行对 ECSM 平台的修改。
+	dryRun bool
+
+	// reconcileTimeout 是单次调谐的最长时间
+	reconcileTimeout time.Duration
+
+	// requeueAfter 是调谐成功之后重新验证服务的间隔，0 表示不主动重新验证
+	requeueAfter time.Duration
+
+	// statusManager 合并频繁的 status 更新，为 nil 时每次都直接写回 Registry
+	statusManager *StatusManager
+
+	// readiness 是就绪探针的结果，未就绪的容器不计入 readyReplicas，也不被滚动更新视为可用
+	readiness *ProbeResults
+
 	// queue 是一个限速工作队列。
 	queue workqueue.TypedRateLimitingInterface[interface{}]
 }
 
+// ServiceControllerOptions 包含 ECSMServiceController 的可调参数。
+type ServiceControllerOptions struct {
+	// RateLimiter 是工作队列的重试限速参数
+	RateLimiter RateLimiterConfig
+	// DryRun 为 true 时，控制器对所有服务都只计划、不执行操作
+	DryRun bool
+	// ReconcileTimeout 是单次调谐的最长时间，超时后调谐中的 ECSM API 调用会被取消
+	ReconcileTimeout time.Duration
+	// RequeueAfter 是调谐成功之后，在没有任何事件的情况下重新验证服务的间隔。
+	// 它用于及时发现平台上静默发生的故障 (例如容器退出)，而不必等待 Informer 的全量同步。0 表示不主动重新验证。
+	RequeueAfter time.Duration
+	// StatusFlushInterval 是合并后的 status 更新写回 Registry 的间隔，0 表示每次都直接写回
+	StatusFlushInterval time.Duration
+	// Readiness 是 RemediationController 写入的就绪探针结果，为 nil 时只根据容器的运行状态判断是否就绪
+	Readiness *ProbeResults
+}
+
+// DefaultServiceControllerOptions 返回 ECSMServiceController 的默认参数。
+func DefaultServiceControllerOptions() ServiceControllerOptions {
+	return ServiceControllerOptions{
+		RateLimiter:         DefaultRateLimiterConfig(),
+		ReconcileTimeout:    DefaultReconcileTimeout,
+		RequeueAfter:        DefaultRequeueAfter,
+		StatusFlushInterval: DefaultStatusFlushInterval,
+	}
+}
+
 // NewECSMServiceController 创建一个新的控制器实例。
 func NewECSMServiceController(
-	ecsmClient clientset.Interface,
+	clusters *ClusterClients,
 	reg registry.Interface,
 	serviceInformer informer.Informer,
+	platformInformer informer.PlatformServiceInformer,
+	recorder record.EventRecorder,
+	opts ServiceControllerOptions,
 ) *ECSMServiceController {
 
 	c := &ECSMServiceController{
-		ecsmClient:      ecsmClient,
-		registry:        reg,
-		serviceInformer: serviceInformer,
-		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "ecsmservice"),
+		clusters:         clusters,
+		registry:         reg,
+		serviceInformer:  serviceInformer,
+		recorder:         recorder,
+		informersSynced:  []cache.InformerSynced{serviceInformer.HasSynced},
+		expectations:     newControllerExpectations(),
+		dryRun:           opts.DryRun,
+		reconcileTimeout: opts.ReconcileTimeout,
+		requeueAfter:     opts.RequeueAfter,
+		readiness:        opts.Readiness,
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			newRateLimiter(opts.RateLimiter),
+			workqueue.TypedRateLimitingQueueConfig[interface{}]{Name: "ecsmservice"},
+		),
 	}
 
+	c.scheduler = scheduler.New(reg, clusters.Default(), c.servicePlacement)
+
 	// EventHandler 的唯一职责就是将事件的 key 推入队列。
 	// 它不关心对象内容。
 	handler := cache.ResourceEventHandlerFuncs{
@@ -74,11 +202,49 @@ func NewECSMServiceController(
 		},
 	}
 
+	if opts.StatusFlushInterval > 0 {
+		c.statusManager = NewStatusManager(reg, opts.StatusFlushInterval)
+	}
+
 	serviceInformer.AddEventHandler(handler)
 
+	// 现实世界中平台服务的变化 (例如有人在 ECSM 控制台上直接扩缩容)
+	// 会让它的 owner 重新入队，以便及时发现漂移。
+	if platformInformer != nil {
+		c.informersSynced = append(c.informersSynced, platformInformer.HasSynced)
+		platformInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueuePlatformServiceOwner,
+			UpdateFunc: func(_, new interface{}) { c.enqueuePlatformServiceOwner(new) },
+			DeleteFunc: c.enqueuePlatformServiceOwner,
+		})
+	}
+
 	return c
 }
 
+// enqueuePlatformServiceOwner 根据平台服务上的 owner 标签，将其所属的 ECSMService 加入队列。
+// 没有 owner 标签的平台服务 (孤儿或不受管理的服务) 和属于其他类型 (例如 ECSMJob) 的平台服务会被忽略。
+func (c *ECSMServiceController) enqueuePlatformServiceOwner(obj interface{}) {
+	row, ok := obj.(*clientset.ProvisionListRow)
+	if !ok {
+		return
+	}
+	labels := rowLabels(*row)
+	if _, ok := labels[LabelOwnerKind]; ok {
+		return
+	}
+	owner, ok := labels[LabelOwnerName]
+	if !ok {
+		return
+	}
+	// owner 标签的值为 "<namespace>.<name>"，命名空间中不会包含 "."
+	namespace, name, ok := strings.Cut(owner, ".")
+	if !ok {
+		return
+	}
+	c.queue.Add(namespace + "/" + name)
+}
+
 // enqueueService 将一个 ECSMService 的 key 添加到工作队列中。
 func (c *ECSMServiceController) enqueueService(obj interface{}) {
 	key, err := cache.MetaNamespaceKeyFunc(obj)
@@ -89,36 +255,75 @@ func (c *ECSMServiceController) enqueueService(obj interface{}) {
 	c.queue.Add(key)
 }
 
-// Run 启动控制器的主工作循环。
+// Run 启动控制器的主工作循环，并阻塞到 stopCh 被关闭。
+// 停止时不会再开始新的调谐，但会等待正在进行中的调谐完成后才返回，
+// 避免在一次调谐的中途 (例如已经提交了创建、还没有记录 expectations) 退出进程。
 func (c *ECSMServiceController) Run(workers int, stopCh <-chan struct{}) {
 	defer runtime.HandleCrash()
-	defer c.queue.ShutDown()
 
 	klog.Info("Starting ECSMService controller")
 	defer klog.Info("Shutting down ECSMService controller")
 
-	// 启动 Informer，它会开始填充缓存和监听事件
-	// 注意：Informer 应该在控制器外部被启动和管理
-	// 我们假设调用 Run 的地方已经启动了 Informer
-
+	// Informer 应该在控制器外部被启动和管理，这里只等待它们完成第一次同步，
+	// 以免在现实世界的快照就绪之前做出决策。
 	klog.Info("Waiting for informer caches to sync...")
-	// if !cache.WaitForCacheSync(stopCh, c.serviceInformer.HasSynced) {
-	// 	runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
-	// 	return
-	// }
-	// (在我们的模型中，我们没有 HasSynced，所以暂时注释掉)
+	if !cache.WaitForCacheSync(stopCh, c.informersSynced...) {
+		runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
+		return
+	}
 
 	klog.Info("Starting workers")
+	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
-		go wait.Until(c.runWorker, time.Second, stopCh)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(func() { c.runWorker(stopCh) }, time.Second, stopCh)
+		}()
+	}
+
+	if c.statusManager != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.statusManager.Run(stopCh)
+		}()
 	}
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.watchRegistry(stopCh)
+	}()
+
 	<-stopCh
+	// 关闭队列会唤醒所有空闲的 worker，忙碌的 worker 会在当前调谐结束后退出
+	c.queue.ShutDown()
+	klog.Info("Waiting for in-flight reconciles to finish")
+	wg.Wait()
+
+	// 所有 worker 都已退出，写回最后一批合并的 status
+	if c.statusManager != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), statusFlushTimeout)
+		defer cancel()
+		if err := c.statusManager.Flush(ctx); err != nil {
+			runtime.HandleError(err)
+		}
+	}
 }
 
 // runWorker 是一个持续运行的循环，负责从队列中消费任务并处理。
-func (c *ECSMServiceController) runWorker() {
-	for c.processNextWorkItem() {
+// stopCh 关闭后，它会在当前任务处理完毕后退出，而不是继续消费队列中剩余的任务。
+func (c *ECSMServiceController) runWorker(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+		if !c.processNextWorkItem() {
+			return
+		}
 	}
 }
 
@@ -130,20 +335,56 @@ func (c *ECSMServiceController) processNextWorkItem() bool {
 	}
 	defer c.queue.Done(key)
 
-	err := c.reconcile(key.(string))
+	// 每次调谐都有独立的超时，避免一个卡住的 ECSM API 调用永远占用 worker。
+	// 它不继承 stopCh：退出时我们希望进行中的调谐能够完成，而不是被中途取消。
+	ctx, cancel := context.WithTimeout(context.Background(), c.reconcileTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.reconcile(ctx, key.(string))
+	result := reconcileResult(ctx, err)
+	reconcileTotal.WithLabelValues(serviceControllerName, result).Inc()
+	reconcileDuration.WithLabelValues(serviceControllerName).Observe(time.Since(start).Seconds())
+
 	// 调用我们之前在 K8s 中看到的 handleErr 逻辑
-	c.handleErr(err, key)
+	c.handleErr(err, key, classifyError(ctx, err))
 
 	return true
 }
 
-// handleErr 负责处理 reconcile 返回的错误，并决定是否重试。
-func (c *ECSMServiceController) handleErr(err error, key interface{}) {
+// handleErr 负责处理 reconcile 返回的错误，并根据错误的类别决定如何重试：
+//   - Registry 写冲突：重新读取对象后立即重试；
+//   - 超时：ECSM 平台暂时无响应，与对象本身无关，按退避间隔一直重试，不受 maxRetries 的限制；
+//   - ECSM 平台拒绝请求 (4xx)：间隔 validationRetryDelay 后重试；
+//   - spec 无效：放弃，等待用户修改 spec (调谐时已经记录了事件)；
+//   - 其他错误 (网络、5xx)：按指数退避重试，超过 maxRetries 后放弃。
+func (c *ECSMServiceController) handleErr(err error, key interface{}, class errorClass) {
 	if err == nil {
 		c.queue.Forget(key)
 		return
 	}
 
+	switch class {
+	case errorClassConflict:
+		klog.V(2).Infof("Conflict syncing service %v: %v. Retrying immediately.", key, err)
+		c.queue.Forget(key)
+		c.queue.Add(key)
+		return
+	case errorClassTimeout:
+		klog.Warningf("Syncing service %v timed out after %v: %v. Retrying.", key, c.reconcileTimeout, err)
+		c.queue.AddRateLimited(key)
+		return
+	case errorClassValidation:
+		klog.Warningf("ECSM rejected the request for service %v: %v. Retrying in %v.", key, err, validationRetryDelay)
+		c.queue.Forget(key)
+		c.queue.AddAfter(key, validationRetryDelay)
+		return
+	case errorClassInvalidSpec:
+		klog.Warningf("Service %v has an invalid spec, not retrying until it changes: %v", key, err)
+		c.queue.Forget(key)
+		return
+	}
+
 	if c.queue.NumRequeues(key) < maxRetries {
 		klog.V(2).Infof("Error syncing service %v: %v. Retrying.", key, err)
 		c.queue.AddRateLimited(key)
@@ -155,9 +396,8 @@ func (c *ECSMServiceController) handleErr(err error, key interface{}) {
 	c.queue.Forget(key)
 }
 
-func (c *ECSMServiceController) reconcile(key string) error {
+func (c *ECSMServiceController) reconcile(ctx context.Context, key string) error {
 	klog.Infof("Reconciling ECSMService %s", key)
-	ctx := context.Background()
 
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
@@ -173,63 +413,122 @@ func (c *ECSMServiceController) reconcile(key string) error {
 		if errors.IsNotFound(err) {
 			// 对象已被删除，无需处理。Informer 的 resync 会清理 versionCache。
 			klog.Infof("ECSMService %s in work queue no longer exists", key)
+			c.expectations.deleteExpectations(key)
+			if c.statusManager != nil {
+				c.statusManager.Forget(key)
+			}
 			return nil
 		}
 		return err // 其他读取错误，需要重试
 	}
+	// 尚未写回的 status 比 Registry 中的更新
+	if c.statusManager != nil {
+		if status, ok := c.statusManager.PendingStatus(key); ok {
+			desiredService.Status = *status
+		}
+	}
 
-	// --- 2. 获取“现实” ---
-	//    调用 EcsmClient
-	actualContainers, err := c.ecsmClient.Containers().ListAllByService(ctx, clientset.ListContainersByServiceOptions{
-		ServiceIDs: []string{string(desiredService.UID)},
-	})
-	if err != nil {
-		// 如果是网络错误等，返回 err 会触发重试
-		return fmt.Errorf("failed to list containers for service %s: %w", key, err)
+	// 记录原始状态，用于在最后判断是否需要写回 Registry
+	originalStatus := desiredService.Status.DeepCopy()
+	// 计划的操作只反映最近一次调谐
+	desiredService.Status.PlannedActions = nil
+
+	// 之后所有对 ECSM 平台的调用都发往负责该服务的集群
+	if err := c.resolveCluster(desiredService); err != nil {
+		c.recorder.Eventf(desiredService, ecsmv1.EventTypeWarning, ReasonInvalidSpec, "%v", err)
+		return err
 	}
 
-	// --- 3. 调谐 (Compare & Act) ---
-	desiredReplicas := 0
-	if desiredService.Spec.DeploymentStrategy.Replicas != nil {
-		desiredReplicas = int(*desiredService.Spec.DeploymentStrategy.Replicas)
+	// --- 在调谐之前，先等待上一轮提交的异步事务 ---
+	//    事务完成之前平台的状态还没有收敛，此时重新列出并决策只会得到过时的结果
+	if c.syncTransactions(ctx, desiredService) {
+		klog.V(2).Infof("Service %s has pending transactions, requeueing", key)
+		c.queue.AddAfter(key, transactionRequeueInterval)
+		return c.updateStatus(ctx, desiredService, originalStatus)
 	}
-	actualReplicas := len(actualContainers)
 
-	delta := desiredReplicas - actualReplicas
+	// 对象已被标记删除：先清理平台上的服务，再移除 finalizer
+	if desiredService.DeletionTimestamp != nil {
+		return c.finalizeService(ctx, desiredService, originalStatus)
+	}
 
-	if delta > 0 {
-		klog.Infof("Service %s: Desired replicas (%d) > Actual (%d). Need to create %d container(s).", key, desiredReplicas, actualReplicas, delta)
-		// TODO: 在这里实现创建容器的逻辑
-		// err := c.createContainers(ctx, delta, desiredService)
-		// return err
-	} else if delta < 0 {
-		klog.Infof("Service %s: Desired replicas (%d) < Actual (%d). Need to delete %d container(s).", key, desiredReplicas, actualReplicas, -delta)
-		// TODO: 在这里实现删除容器的逻辑
-		// err := c.deleteContainers(ctx, -delta, actualContainers)
-		// return err
+	// 在创建任何平台服务之前，确保 finalizer 已经就位，避免删除时留下孤儿服务
+	desiredService, err = c.ensureFinalizer(ctx, desiredService)
+	if err != nil {
+		return err
 	}
 
-	// TODO: 在这里实现滚动更新的逻辑，比较 template spec 和容器的 image/config
+	// 展开模板对 ECSMConfig 的引用。之后 desiredService 的 spec 不能再写回 Registry
+	if err := resolveTemplateConfigs(ctx, c.registry, namespace, &desiredService.Spec.Template); err != nil {
+		c.recorder.Eventf(desiredService, ecsmv1.EventTypeWarning, ReasonConfigError, "%v", err)
+		return err
+	}
 
-	// --- 4. 更新“状态” (`Status`) ---
-	// 重新获取最新的现实快照，因为我们可能刚刚修改了它
-	finalContainers, err := c.ecsmClient.Containers().ListAllByService(ctx, clientset.ListContainersByServiceOptions{
-		ServiceIDs: []string{string(desiredService.UID)},
-	})
+	// --- 2. 获取“现实” ---
+	//    调用 EcsmClient，找到该服务名下所有修订版本的平台服务及其容器
+	revisions, err := c.listPlatformServices(ctx, desiredService)
 	if err != nil {
-		return fmt.Errorf("failed to list containers for status update for service %s: %w", key, err)
+		// 如果是网络错误等，返回 err 会触发重试
+		return fmt.Errorf("failed to list platform services for service %s: %w", key, err)
 	}
 
-	newStatus := c.calculateStatus(finalContainers)
+	// --- 3. 调谐 (Compare & Act) ---
+	//    副本数调整和滚动更新都在这里完成，每次只推进一步。
+	//    如果上一次提交的创建/删除还没有在平台上体现出来，这一轮只更新状态，避免重复操作。
+	var currentRevision *platformService
+	done := false
+	if c.expectations.satisfiedExpectations(key, revisions) {
+		currentRevision, done, err = c.syncRevisions(ctx, desiredService, revisions)
+		if err != nil {
+			if isSpecError(err) {
+				c.recorder.Eventf(desiredService, ecsmv1.EventTypeWarning, ReasonInvalidSpec, "%v", err)
+			}
+			return fmt.Errorf("failed to sync revisions for service %s: %w", key, err)
+		}
+		// 当前的 spec 已经被处理过 (但 rollout 不一定已经完成)
+		desiredService.Status.ObservedGeneration = desiredService.Generation
+		// dry-run 模式下平台不会发生变化，不需要重新列出或稍后推进 rollout，
+		// 等到 spec 或平台发生变化时再重新计划
+		if c.isDryRun(desiredService) {
+			done = true
+		}
+	} else {
+		currentRevision = findRevision(revisions, computeTemplateHash(&desiredService.Spec.Template))
+	}
+	transactionsSubmitted := len(desiredService.Status.PendingTransactions) > 0
 
-	// 只有当 status 真的变了，才去写 Registry
-	if !reflect.DeepEqual(desiredService.Status, newStatus) {
-		klog.Infof("Updating status for service %s", key)
-		serviceToUpdate := desiredService.DeepCopy()
-		serviceToUpdate.Status = newStatus
-		// 注意：这里我们应该使用 UpdateServiceStatus，而不是 UpdateService
-		// 以防止覆盖用户可能同时对 spec 做的修改
-		_, err := c.registry.UpdateServiceStatus(ctx, serviceToUpdate)
+	// --- 4. 更新“状态” (`Status`) ---
+	// 重新获取最新的现实快照，因为我们可能刚刚修改了它。
+	// 如果刚刚提交了异步事务，平台的状态还不会变化，等事务完成后再重新列出。
+	if !done && !transactionsSubmitted {
+		revisions, err = c.listPlatformServices(ctx, desiredService)
+		if err != nil {
+			return fmt.Errorf("failed to list platform services for status update for service %s: %w", key, err)
+		}
+	}
+
+	newStatus := c.calculateStatus(desiredService, revisions, currentRevision)
+	newStatus.ObservedGeneration = desiredService.Status.ObservedGeneration
+	newStatus.Conditions = desiredService.Status.Conditions
+	setAvailableCondition(desiredService, &newStatus)
+	newStatus.PendingTransactions = desiredService.Status.PendingTransactions
+	newStatus.PlannedActions = desiredService.Status.PlannedActions
+	newStatus.Rollout = desiredService.Status.Rollout
+	newStatus.Cluster = desiredService.Status.Cluster
+	desiredService.Status = newStatus
+
+	// rollout 尚未完成时，稍后重新入队以推进下一步
+	switch {
+	case transactionsSubmitted:
+		c.queue.AddAfter(key, transactionRequeueInterval)
+	case !done:
+		c.queue.AddAfter(key, rolloutRequeueInterval)
+	case c.requeueAfter > 0:
+		// 服务已经收敛，定期重新验证它在平台上的状态
+		c.queue.AddAfter(key, c.requeueAfter)
+	}
+
+	if err := c.updateStatus(ctx, desiredService, originalStatus); err != nil {
 		return err // 返回错误以触发可能的重试
 	}
 
@@ -237,18 +536,72 @@ func (c *ECSMServiceController) reconcile(key string) error {
 	return nil
 }
 
+// updateStatus 在 status 相对 original 发生变化时，把它写回 Registry。
+// 启用了 StatusManager 时，普通的更新会被合并后批量写回；但正在删除的服务和事务发生了变化的服务会被立即写回：
+// 前者即将从 Registry 中消失，后者在 operator 重启后需要依靠 status 中的事务避免重复操作。
+func (c *ECSMServiceController) updateStatus(ctx context.Context, service *ecsmv1.ECSMService, original *ecsmv1.ECSMServiceStatus) error {
+	// 只有当 status 真的变了，才去写 Registry
+	if reflect.DeepEqual(*original, service.Status) {
+		return nil
+	}
+	if c.statusManager != nil {
+		if service.DeletionTimestamp == nil && reflect.DeepEqual(original.PendingTransactions, service.Status.PendingTransactions) {
+			klog.V(4).Infof("Queueing status update for service %s", serviceKey(service))
+			c.statusManager.SetStatus(service)
+			return nil
+		}
+		klog.Infof("Updating status for service %s", serviceKey(service))
+		return c.statusManager.UpdateNow(ctx, service)
+	}
+	klog.Infof("Updating status for service %s", serviceKey(service))
+	// 注意：这里我们应该使用 UpdateServiceStatus，而不是 UpdateService
+	// 以防止覆盖用户可能同时对 spec 做的修改
+	_, err := c.registry.UpdateServiceStatus(ctx, service)
+	return err
+}
+
 // calculateStatus 是一个辅助函数，用于将现实世界的对象列表，聚合成 Status 结构
-func (c *ECSMServiceController) calculateStatus(containers []clientset.ContainerInfo) ecsmv1.ECSMServiceStatus {
-	var readyReplicas int32 = 0
-	for _, c := range containers {
-		if c.Status == "running" { // 假设 "running" 就是 "ready"
-			readyReplicas++
+func (c *ECSMServiceController) calculateStatus(service *ecsmv1.ECSMService, revisions []*platformService, current *platformService) ecsmv1.ECSMServiceStatus {
+	hash := computeTemplateHash(&service.Spec.Template)
+
+	var replicas, readyReplicas, updatedReplicas int32
+	for _, rev := range revisions {
+		for _, co := range rev.Containers {
+			replicas++
+			if c.readiness.containerReady(co) {
+				readyReplicas++
+			}
+			// 只有属于当前修订版本、且确实运行着模板镜像的容器才算 "已更新"
+			if rev.TemplateHash == hash && containerMatchesTemplate(co, &service.Spec.Template) {
+				updatedReplicas++
+			}
 		}
 	}
 
-	return ecsmv1.ECSMServiceStatus{
-		Replicas:      int32(len(containers)),
-		ReadyReplicas: readyReplicas,
-		// TODO: 在这里填充 Conditions
+	status := ecsmv1.ECSMServiceStatus{
+		Replicas:        replicas,
+		ReadyReplicas:   readyReplicas,
+		UpdatedReplicas: updatedReplicas,
+	}
+	if current != nil {
+		status.UnderlyingServiceID = current.Row.ID
+	}
+	return status
+}
+
+// setAvailableCondition 根据就绪副本数和期望副本数更新 status 中的 Available 状况。
+func setAvailableCondition(service *ecsmv1.ECSMService, status *ecsmv1.ECSMServiceStatus) {
+	desired := desiredReplicas(service)
+	cond := metav1.Condition{
+		Type:               ecsmv1.ServiceAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonMinimumReplicasAvailable,
+		Message:            fmt.Sprintf("%d of %d replicas are ready", status.ReadyReplicas, desired),
+		ObservedGeneration: status.ObservedGeneration,
+	}
+	if status.ReadyReplicas < desired {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = reasonMinimumReplicasUnavailable
 	}
+	ecsmmeta.SetCondition(&status.Conditions, cond)
 }
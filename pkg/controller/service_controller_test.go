@@ -0,0 +1,233 @@
+// file: pkg/controller/service_controller_test.go
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset/fake"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"github.com/fx147/ecsm-operator/pkg/informer"
+	"github.com/fx147/ecsm-operator/pkg/record"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	bolt "go.etcd.io/bbolt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetAvailableCondition(t *testing.T) {
+	replicas := int32(3)
+	svc := &ecsmv1.ECSMService{
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeDynamic, Replicas: &replicas},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		ready      int32
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{"not enough ready replicas", 2, metav1.ConditionFalse, reasonMinimumReplicasUnavailable},
+		{"all replicas ready", 3, metav1.ConditionTrue, reasonMinimumReplicasAvailable},
+		{"more ready replicas during a rollout", 4, metav1.ConditionTrue, reasonMinimumReplicasAvailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &ecsmv1.ECSMServiceStatus{ReadyReplicas: tt.ready, ObservedGeneration: 2}
+			setAvailableCondition(svc, status)
+
+			cond := ecsmmeta.GetCondition(status.Conditions, ecsmv1.ServiceAvailable)
+			if cond == nil {
+				t.Fatalf("Available condition not set")
+			}
+			if cond.Status != tt.wantStatus || cond.Reason != tt.wantReason {
+				t.Errorf("condition = %s/%s, want %s/%s", cond.Status, cond.Reason, tt.wantStatus, tt.wantReason)
+			}
+			if cond.ObservedGeneration != 2 {
+				t.Errorf("observedGeneration = %d, want 2", cond.ObservedGeneration)
+			}
+		})
+	}
+}
+
+// newTestServiceController 返回使用 cs 作为默认集群的控制器，以及它使用的 Registry 和 FakeRecorder。
+func newTestServiceController(t *testing.T, cs *fake.Clientset) (*ECSMServiceController, *registry.Registry, *record.FakeRecorder) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	recorder := record.NewFakeRecorder(100)
+	opts := DefaultServiceControllerOptions()
+	opts.StatusFlushInterval = 0
+	c := NewECSMServiceController(NewClusterClients(DefaultClusterName, cs), reg, informer.NewInformer(reg, 0), nil, recorder, opts)
+	t.Cleanup(c.queue.ShutDown)
+	return c, reg, recorder
+}
+
+// drainEvents 返回 recorder 中已经记录的所有事件。
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+// TestReconcile_Lifecycle 用 fake.Clientset 测试服务从创建、扩缩容到删除的完整调谐过程。
+func TestReconcile_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	cs := fake.NewClientset()
+	cs.Tracker().AddNode(&clientset.NodeInfo{Name: "node-1"})
+	cs.Tracker().AddNode(&clientset.NodeInfo{Name: "node-2"})
+	c, reg, recorder := newTestServiceController(t, cs)
+
+	replicas := int32(3)
+	if _, err := reg.CreateService(ctx, &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:     ecsmv1.DeploymentStrategyTypeDynamic,
+				Replicas: &replicas,
+				NodePool: []string{"node-1", "node-2"},
+			},
+			Template: ecsmv1.ContainerTemplateSpec{Image: "web@1.0"},
+		},
+	}); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	// 第一轮调谐创建平台服务，容器立即就绪
+	if err := c.reconcile(ctx, "default/web"); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	services := cs.Tracker().Services()
+	if len(services) != 1 || !strings.HasPrefix(services[0].Name, "web-") || services[0].Factor != 3 {
+		t.Fatalf("Expected one platform service with 3 replicas, got %+v", services)
+	}
+	svc, err := reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if svc.Status.Replicas != 3 || svc.Status.ReadyReplicas != 3 || svc.Status.UpdatedReplicas != 3 ||
+		svc.Status.UnderlyingServiceID != services[0].ID {
+		t.Errorf("Unexpected status %+v", svc.Status)
+	}
+	if cond := ecsmmeta.GetCondition(svc.Status.Conditions, ecsmv1.ServiceAvailable); cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("Expected the service to be available, got %+v", cond)
+	}
+
+	// 再次调谐不应该修改平台
+	cs.ClearActions()
+	if err := c.reconcile(ctx, "default/web"); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	for _, action := range cs.Actions() {
+		if !strings.HasPrefix(action.Verb, "List") {
+			t.Errorf("Unexpected %s %s on a converged service", action.Verb, action.Resource)
+		}
+	}
+
+	// 缩容
+	replicas = 1
+	svc.Spec.DeploymentStrategy.Replicas = &replicas
+	if _, err := reg.UpdateService(ctx, svc); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	if err := c.reconcile(ctx, "default/web"); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if containers := cs.Tracker().Containers(); len(containers) != 1 {
+		t.Errorf("Expected 1 container after scaling down, got %d", len(containers))
+	}
+	svc, _ = reg.GetService(ctx, "default", "web")
+	if svc.Status.Replicas != 1 || svc.Status.ReadyReplicas != 1 {
+		t.Errorf("Unexpected status after scaling down %+v", svc.Status)
+	}
+
+	// 删除服务：先删除平台服务，等待删除的事务完成后再移除 finalizer
+	if err := reg.DeleteService(ctx, "default", "web"); err != nil {
+		t.Fatalf("DeleteService failed: %v", err)
+	}
+	if err := c.reconcile(ctx, "default/web"); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if len(cs.Tracker().Services()) != 0 {
+		t.Errorf("Expected the platform service to be deleted")
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := reg.GetService(ctx, "default", "web"); errors.IsNotFound(err) {
+			break
+		}
+		if err := c.reconcile(ctx, "default/web"); err != nil {
+			t.Fatalf("reconcile failed: %v", err)
+		}
+	}
+	if _, err := reg.GetService(ctx, "default", "web"); !errors.IsNotFound(err) {
+		t.Errorf("Expected the service to be removed after cleanup, got %v", err)
+	}
+
+	events := strings.Join(drainEvents(recorder), "\n")
+	for _, reason := range []string{ReasonScaledUp, ReasonScaledDown} {
+		if !strings.Contains(events, reason) {
+			t.Errorf("Expected a %s event, got:\n%s", reason, events)
+		}
+	}
+}
+
+// TestReconcile_CreateFailed 测试平台拒绝创建服务时，调谐返回可以分类的错误并记录事件。
+func TestReconcile_CreateFailed(t *testing.T) {
+	ctx := context.Background()
+	cs := fake.NewClientset()
+	cs.AddReactor("Create", "services", fake.ReturnError(&rest.Aerror{Status: http.StatusBadRequest, Message: "image not found"}))
+	c, reg, recorder := newTestServiceController(t, cs)
+
+	if _, err := reg.CreateService(ctx, &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeStatic, Nodes: []string{"node-1"}},
+			Template:           ecsmv1.ContainerTemplateSpec{Image: "web@1.0"},
+		},
+	}); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	err := c.reconcile(ctx, "default/web")
+	if err == nil || classifyError(ctx, err) != errorClassValidation {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if len(cs.Tracker().Services()) != 0 {
+		t.Errorf("Expected no platform service to be created")
+	}
+	if events := strings.Join(drainEvents(recorder), "\n"); !strings.Contains(events, ReasonCreateFailed) {
+		t.Errorf("Expected a %s event, got:\n%s", ReasonCreateFailed, events)
+	}
+
+	// 平台恢复后，下一轮调谐重新创建平台服务
+	c.clusters = NewClusterClients(DefaultClusterName, fake.NewClientsetWithTracker(cs.Tracker()))
+	if err := c.reconcile(ctx, "default/web"); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if services := cs.Tracker().Services(); len(services) != 1 || services[0].Factor != 1 {
+		t.Errorf("Expected the platform service to be created on retry, got %+v", services)
+	}
+}
@@ -0,0 +1,159 @@
+// file: pkg/controller/status_manager.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/metrics"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultStatusFlushInterval 是 StatusManager 默认的刷新间隔。
+	DefaultStatusFlushInterval = time.Second
+
+	// statusFlushTimeout 是一次刷新的最长时间
+	statusFlushTimeout = 30 * time.Second
+)
+
+// status 更新的去向，作为 outcome 标签的值
+const (
+	statusUpdateWritten   = "written"
+	statusUpdateCoalesced = "coalesced"
+)
+
+var statusUpdatesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "controller",
+	Name:      "status_updates_total",
+	Help:      "Total number of ECSMService status updates written to the registry or coalesced with a later update.",
+}, []string{"outcome"})
+
+func init() {
+	metrics.Registry.MustRegister(statusUpdatesTotal)
+}
+
+// StatusManager 合并同一个 ECSMService 在短时间内的多次 status 更新。
+// 大规模滚动更新期间，控制器每隔几秒就会为每个服务算出新的副本计数，逐次写回 Registry
+// 会不断推高全局 resourceVersion，并让 Informer 收到大量由控制器自己引起的回声事件。
+// StatusManager 只保留每个对象最新的 status 并把它标记为脏，每隔一个刷新间隔统一写回一次。
+type StatusManager struct {
+	registry registry.Interface
+	interval time.Duration
+
+	lock sync.Mutex
+	// pending 以 "namespace/name" 为索引，保存了 status 尚未写回的对象
+	pending map[string]*ecsmv1.ECSMService
+
+	// writeLock 串行化所有对 Registry 的写入，保证较旧的 status 不会覆盖较新的
+	writeLock sync.Mutex
+}
+
+// NewStatusManager 创建一个新的 StatusManager。
+func NewStatusManager(reg registry.Interface, interval time.Duration) *StatusManager {
+	return &StatusManager{
+		registry: reg,
+		interval: interval,
+		pending:  make(map[string]*ecsmv1.ECSMService),
+	}
+}
+
+// SetStatus 记录对象最新的 status，它会在下一次刷新时被写回 Registry。
+func (m *StatusManager) SetStatus(service *ecsmv1.ECSMService) {
+	key := serviceKey(service)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.pending[key]; ok {
+		statusUpdatesTotal.WithLabelValues(statusUpdateCoalesced).Inc()
+	}
+	m.pending[key] = service.DeepCopy()
+}
+
+// PendingStatus 返回对象尚未写回 Registry 的 status。
+// 控制器从 Registry 读取对象之后，应该用它覆盖对象的 status，否则会基于过时的 status 做出决策。
+func (m *StatusManager) PendingStatus(key string) (*ecsmv1.ECSMServiceStatus, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	obj, ok := m.pending[key]
+	if !ok {
+		return nil, false
+	}
+	return obj.Status.DeepCopy(), true
+}
+
+// UpdateNow 立即把对象的 status 写回 Registry，并丢弃它尚未写回的 status。
+func (m *StatusManager) UpdateNow(ctx context.Context, service *ecsmv1.ECSMService) error {
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
+	m.Forget(serviceKey(service))
+	return m.write(ctx, service)
+}
+
+// Forget 丢弃对象尚未写回的 status，在对象被删除之后调用。
+func (m *StatusManager) Forget(key string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.pending, key)
+}
+
+// Run 每隔一个刷新间隔把脏的 status 写回 Registry，直到 stopCh 被关闭。
+// 它返回时不会再刷新，调用方应该在所有写入者停止之后再调用一次 Flush。
+func (m *StatusManager) Run(stopCh <-chan struct{}) {
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), statusFlushTimeout)
+		defer cancel()
+		if err := m.Flush(ctx); err != nil {
+			runtime.HandleError(err)
+		}
+	}, m.interval, stopCh)
+}
+
+// Flush 把所有脏的 status 写回 Registry。
+// 写入失败的对象仍然是脏的，会在下一次刷新时重试；已经被删除的对象会被丢弃。
+func (m *StatusManager) Flush(ctx context.Context) error {
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
+
+	m.lock.Lock()
+	dirty := make(map[string]*ecsmv1.ECSMService, len(m.pending))
+	for key, obj := range m.pending {
+		dirty[key] = obj
+	}
+	m.lock.Unlock()
+
+	var errs []error
+	for key, obj := range dirty {
+		if err := m.write(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to flush status of service %s: %w", key, err))
+			continue
+		}
+		m.lock.Lock()
+		// 写入期间又有更新的 status 时，它仍然是脏的
+		if m.pending[key] == obj {
+			delete(m.pending, key)
+		}
+		m.lock.Unlock()
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+func (m *StatusManager) write(ctx context.Context, service *ecsmv1.ECSMService) error {
+	if _, err := m.registry.UpdateServiceStatus(ctx, service); err != nil {
+		return err
+	}
+	statusUpdatesTotal.WithLabelValues(statusUpdateWritten).Inc()
+	return nil
+}
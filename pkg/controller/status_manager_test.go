@@ -0,0 +1,84 @@
+// file: pkg/controller/status_manager_test.go
+
+package controller
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	bolt "go.etcd.io/bbolt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatusManagerCoalescesUpdates(t *testing.T) {
+	ctx := context.Background()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	created, err := reg.CreateService(ctx, &ecsmv1.ECSMService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeStatic, Nodes: []string{"node-a"}},
+			Template:           ecsmv1.ContainerTemplateSpec{Image: "web@1.0"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	m := NewStatusManager(reg, time.Second)
+	for i := int32(1); i <= 3; i++ {
+		update := created.DeepCopy()
+		update.Status.Replicas = i
+		m.SetStatus(update)
+	}
+
+	// 刷新之前 Registry 中的对象没有变化，但控制器能读到最新的 status
+	stored, err := reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if stored.ResourceVersion != created.ResourceVersion {
+		t.Errorf("status written before flush, resourceVersion %s -> %s", created.ResourceVersion, stored.ResourceVersion)
+	}
+	if status, ok := m.PendingStatus("default/web"); !ok || status.Replicas != 3 {
+		t.Errorf("PendingStatus() = %v, %v, want the latest status with 3 replicas", status, ok)
+	}
+
+	if err := m.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	stored, err = reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if stored.Status.Replicas != 3 {
+		t.Errorf("stored replicas = %d, want 3", stored.Status.Replicas)
+	}
+	if _, ok := m.PendingStatus("default/web"); ok {
+		t.Errorf("status still pending after flush")
+	}
+
+	// 已经被删除的对象在刷新时被丢弃
+	if err := reg.DeleteService(ctx, "default", "web"); err != nil {
+		t.Fatalf("DeleteService failed: %v", err)
+	}
+	m.SetStatus(stored)
+	if err := m.Flush(ctx); err != nil {
+		t.Errorf("Flush of a deleted service failed: %v", err)
+	}
+	if _, ok := m.PendingStatus("default/web"); ok {
+		t.Errorf("status of a deleted service still pending after flush")
+	}
+}
@@ -0,0 +1,75 @@
+// file: pkg/controller/transactions.go
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// transactionRequeueInterval 是存在未完成事务时，重新检查事务状态的间隔
+	transactionRequeueInterval = 2 * time.Second
+	// transactionTimeout 是等待单个 ECSM 事务完成的最长时间，超时后不再等待它
+	transactionTimeout = 5 * time.Minute
+
+	// operationDelete 是删除平台服务的事务操作
+	operationDelete = "Delete"
+)
+
+// trackTransaction 把一个刚提交的事务记录到 ECSMService 的状态中。
+// 平台没有返回事务 ID 时无需跟踪。
+func (c *ECSMServiceController) trackTransaction(service *ecsmv1.ECSMService, id, operation, target string) {
+	if id == "" {
+		return
+	}
+	service.Status.PendingTransactions = append(service.Status.PendingTransactions, ecsmv1.PendingTransaction{
+		ID:          id,
+		Operation:   operation,
+		Target:      target,
+		SubmittedAt: metav1.Now(),
+	})
+}
+
+// syncTransactions 查询 ECSMService 状态中所有未完成事务的最新状态，移除已经结束的事务，
+// 并返回是否仍有事务在进行中。它只修改传入对象的 status，由调用方负责持久化。
+func (c *ECSMServiceController) syncTransactions(ctx context.Context, service *ecsmv1.ECSMService) bool {
+	if len(service.Status.PendingTransactions) == 0 {
+		return false
+	}
+
+	var pending []ecsmv1.PendingTransaction
+	for _, ptx := range service.Status.PendingTransactions {
+		tx, err := c.clientFor(service).Transactions().Get(ctx, ptx.ID)
+		if err != nil {
+			// 查询失败可能只是暂时的，保留该事务，下一轮再查
+			klog.V(4).Infof("Service %s/%s: failed to get transaction %s: %v", service.Namespace, service.Name, ptx.ID, err)
+			tx = &clientset.Transaction{ID: ptx.ID, Status: clientset.TransactionStatusRunning}
+		}
+
+		switch tx.Status {
+		case clientset.TransactionStatusSuccess:
+			klog.V(2).Infof("Service %s/%s: transaction %s (%s %s) succeeded", service.Namespace, service.Name, ptx.ID, ptx.Operation, ptx.Target)
+		case clientset.TransactionStatusFailure:
+			c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonTransactionFailed,
+				"Transaction %s (%s %s) failed", ptx.ID, ptx.Operation, ptx.Target)
+			// 失败的删除不会在平台列表中体现出来，清除期望以便立即重新决策
+			c.expectations.deleteExpectations(serviceKey(service))
+		default:
+			if time.Since(ptx.SubmittedAt.Time) > transactionTimeout {
+				c.recorder.Eventf(service, ecsmv1.EventTypeWarning, ReasonTransactionFailed,
+					"Transaction %s (%s %s) did not complete within %s", ptx.ID, ptx.Operation, ptx.Target, transactionTimeout)
+				continue
+			}
+			pending = append(pending, ptx)
+		}
+	}
+
+	service.Status.PendingTransactions = pending
+	return len(pending) > 0
+}
@@ -0,0 +1,157 @@
+// file: pkg/controller/transactions_test.go
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestTransactionService() *ecsmv1.ECSMService {
+	return &ecsmv1.ECSMService{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+}
+
+// TestTrackTransaction 测试只有平台返回了事务 ID 时才跟踪事务。
+func TestTrackTransaction(t *testing.T) {
+	c, _, _ := newTestServiceController(t, fake.NewClientset())
+	service := newTestTransactionService()
+
+	c.trackTransaction(service, "", operationDelete, "web-old")
+	if len(service.Status.PendingTransactions) != 0 {
+		t.Fatalf("Expected a transaction without ID not to be tracked, got %v", service.Status.PendingTransactions)
+	}
+
+	c.trackTransaction(service, "42", operationDelete, "web-old")
+	if len(service.Status.PendingTransactions) != 1 {
+		t.Fatalf("Expected 1 pending transaction, got %d", len(service.Status.PendingTransactions))
+	}
+	ptx := service.Status.PendingTransactions[0]
+	if ptx.ID != "42" || ptx.Operation != operationDelete || ptx.Target != "web-old" || ptx.SubmittedAt.IsZero() {
+		t.Errorf("Unexpected pending transaction %+v", ptx)
+	}
+}
+
+// TestSyncTransactions 测试被跟踪的事务在进行中、成功、失败和超时时的处理。
+func TestSyncTransactions(t *testing.T) {
+	tests := []struct {
+		name string
+		// status 是平台上事务的状态
+		status string
+		// age 是事务提交至今的时间
+		age time.Duration
+		// getErr 不为 nil 时查询事务失败
+		getErr error
+
+		wantPending bool
+		// wantEvent 是期望的事件中包含的文本，为空时期望没有事件
+		wantEvent string
+		// wantExpectationsCleared 表示期望被清除
+		wantExpectationsCleared bool
+	}{
+		{
+			name:        "pending",
+			status:      clientset.TransactionStatusRunning,
+			wantPending: true,
+		},
+		{
+			name:   "completed",
+			status: clientset.TransactionStatusSuccess,
+		},
+		{
+			name:                    "failed",
+			status:                  clientset.TransactionStatusFailure,
+			wantEvent:               "Warning TransactionFailed Transaction 42 (Delete web-old) failed",
+			wantExpectationsCleared: true,
+		},
+		{
+			name:      "timed out",
+			status:    clientset.TransactionStatusRunning,
+			age:       transactionTimeout + time.Minute,
+			wantEvent: "Warning TransactionFailed Transaction 42 (Delete web-old) did not complete within",
+		},
+		{
+			name:        "get error",
+			status:      clientset.TransactionStatusSuccess,
+			getErr:      fmt.Errorf("connection refused"),
+			wantPending: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewClientset()
+			cs.Tracker().AddTransaction(&clientset.Transaction{ID: "42", Status: tt.status})
+			if tt.getErr != nil {
+				cs.PrependReactor("Get", "transactions", fake.ReturnError(tt.getErr))
+			}
+			c, _, recorder := newTestServiceController(t, cs)
+
+			service := newTestTransactionService()
+			service.Status.PendingTransactions = []ecsmv1.PendingTransaction{{
+				ID:          "42",
+				Operation:   operationDelete,
+				Target:      "web-old",
+				SubmittedAt: metav1.NewTime(time.Now().Add(-tt.age)),
+			}}
+			key := serviceKey(service)
+			c.expectations.expectDeletion(key, "old-id")
+
+			pending := c.syncTransactions(context.Background(), service)
+			if pending != tt.wantPending {
+				t.Errorf("Expected pending %v, got %v", tt.wantPending, pending)
+			}
+			if tt.wantPending && len(service.Status.PendingTransactions) != 1 {
+				t.Errorf("Expected the transaction to be kept, got %v", service.Status.PendingTransactions)
+			}
+			if !tt.wantPending && len(service.Status.PendingTransactions) != 0 {
+				t.Errorf("Expected the transaction to be removed, got %v", service.Status.PendingTransactions)
+			}
+
+			events := drainEvents(recorder)
+			if tt.wantEvent == "" && len(events) != 0 {
+				t.Errorf("Expected no events, got %v", events)
+			}
+			if tt.wantEvent != "" && (len(events) != 1 || !strings.HasPrefix(events[0], tt.wantEvent)) {
+				t.Errorf("Expected an event %q, got %v", tt.wantEvent, events)
+			}
+
+			_, tracked := c.expectations.store[key]
+			if tracked == tt.wantExpectationsCleared {
+				t.Errorf("Expected expectations cleared %v, got %v", tt.wantExpectationsCleared, !tracked)
+			}
+		})
+	}
+}
+
+// TestSyncTransactions_Completion 测试事务从进行中变为完成后不再被跟踪。
+func TestSyncTransactions_Completion(t *testing.T) {
+	ctx := context.Background()
+	cs := fake.NewClientset()
+	tx := cs.Tracker().AddTransaction(&clientset.Transaction{Status: clientset.TransactionStatusRunning})
+	c, _, recorder := newTestServiceController(t, cs)
+
+	service := newTestTransactionService()
+	c.trackTransaction(service, tx.ID, operationDelete, "web-old")
+	if !c.syncTransactions(ctx, service) {
+		t.Fatalf("Expected the running transaction to be pending")
+	}
+
+	cs.Tracker().SetTransactionStatus(tx.ID, clientset.TransactionStatusSuccess)
+	if c.syncTransactions(ctx, service) {
+		t.Fatalf("Expected no pending transactions after completion")
+	}
+	if len(service.Status.PendingTransactions) != 0 {
+		t.Errorf("Expected the transaction to be removed, got %v", service.Status.PendingTransactions)
+	}
+	if events := drainEvents(recorder); len(events) != 0 {
+		t.Errorf("Expected no events, got %v", events)
+	}
+}
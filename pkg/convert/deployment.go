@@ -0,0 +1,308 @@
+// file: pkg/convert/deployment.go
+
+// Package convert 把 Kubernetes 的清单转换为 ecsm.sh/v1 的对象，方便把已有的工作负载迁移到 ECSM 边缘集群。
+//
+// 转换只覆盖两者都能表达的常用字段，ECSM 无法表达的字段被丢弃并以警告的形式返回，
+// 调用方应该把警告展示给用户，由用户决定转换结果是否可用。
+package convert
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ignoredAnnotations 是 Kubernetes 自己维护的注解，转换时不会保留
+var ignoredAnnotations = map[string]bool{
+	corev1.LastAppliedConfigAnnotation:  true,
+	"deployment.kubernetes.io/revision": true,
+}
+
+// converter 在转换过程中收集警告
+type converter struct {
+	warnings []string
+}
+
+func (c *converter) warn(fldPath *field.Path, format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf("%s: %s", fldPath, fmt.Sprintf(format, args...)))
+}
+
+// Deployment 把一个 Kubernetes Deployment 转换为 ECSMService，返回转换结果和被丢弃或改写的字段的警告。
+//
+// Pod 模板必须只有一个容器。ConfigMap 和 Secret 的引用被转换为同名 ECSMConfig 和 ECSMSecret 的引用，
+// 挂载 hostPath 卷的挂载点被转换为 ECSM 的挂载点，其他类型的卷被丢弃。
+func Deployment(d *appsv1.Deployment) (*ecsmv1.ECSMService, []string, error) {
+	c := &converter{}
+	specPath := field.NewPath("spec")
+	podPath := specPath.Child("template", "spec")
+	pod := &d.Spec.Template.Spec
+
+	if len(pod.Containers) != 1 {
+		return nil, nil, fmt.Errorf("%s: ECSM services run a single container, but the deployment has %d", podPath.Child("containers"), len(pod.Containers))
+	}
+	template, err := c.container(&pod.Containers[0], pod, podPath.Child("containers").Index(0))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.Hostname = pod.Hostname
+
+	service := &ecsmv1.ECSMService{
+		TypeMeta: metav1.TypeMeta{APIVersion: ecsmv1.SchemeGroupVersion.String(), Kind: "ECSMService"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Name,
+			Namespace: d.Namespace,
+			Labels:    d.Labels,
+		},
+		Spec: ecsmv1.ECSMServiceSpec{
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:         ecsmv1.DeploymentStrategyTypeDynamic,
+				Replicas:     d.Spec.Replicas,
+				NodeSelector: pod.NodeSelector,
+			},
+			Template:          *template,
+			PriorityClassName: pod.PriorityClassName,
+		},
+	}
+	for k, v := range d.Annotations {
+		if ignoredAnnotations[k] {
+			continue
+		}
+		if service.Annotations == nil {
+			service.Annotations = make(map[string]string)
+		}
+		service.Annotations[k] = v
+	}
+
+	for i := range pod.Tolerations {
+		if t, ok := c.toleration(&pod.Tolerations[i], podPath.Child("tolerations").Index(i)); ok {
+			service.Spec.DeploymentStrategy.Tolerations = append(service.Spec.DeploymentStrategy.Tolerations, t)
+		}
+	}
+
+	strategyPath := specPath.Child("strategy")
+	switch d.Spec.Strategy.Type {
+	case "", appsv1.RollingUpdateDeploymentStrategyType:
+		if ru := d.Spec.Strategy.RollingUpdate; ru != nil {
+			service.Spec.UpgradeStrategy.MaxSurge = ru.MaxSurge
+			service.Spec.UpgradeStrategy.MaxUnavailable = ru.MaxUnavailable
+		}
+	default:
+		c.warn(strategyPath.Child("type"), "%s is not supported, the service uses the RollingUpdate rollout", d.Spec.Strategy.Type)
+	}
+
+	if pod.Affinity != nil {
+		c.warn(podPath.Child("affinity"), "dropped, use spec.affinity or spec.deploymentStrategy.nodeSelector of the ECSMService instead")
+	}
+	if len(pod.InitContainers) > 0 {
+		c.warn(podPath.Child("initContainers"), "dropped, ECSM services have no init containers")
+	}
+	return service, c.warnings, nil
+}
+
+// container 把容器转换为 ECSMService 的容器模板。
+func (c *converter) container(container *corev1.Container, pod *corev1.PodSpec, fldPath *field.Path) (*ecsmv1.ContainerTemplateSpec, error) {
+	image, err := c.image(container.Image, fldPath.Child("image"))
+	if err != nil {
+		return nil, err
+	}
+	template := &ecsmv1.ContainerTemplateSpec{
+		Image:           image,
+		ImagePullPolicy: ecsmv1.ImagePullPolicyType(container.ImagePullPolicy),
+	}
+	// ECSM 没有单独的参数，命令和参数合并为入口点
+	if len(container.Command) > 0 || len(container.Args) > 0 {
+		template.Command = append(append([]string{}, container.Command...), container.Args...)
+		if len(container.Command) == 0 {
+			c.warn(fldPath.Child("args"), "used as the command of the container, the entrypoint of the image is not kept")
+		}
+	}
+
+	for i := range container.Env {
+		if env, ok := c.envVar(&container.Env[i], fldPath.Child("env").Index(i)); ok {
+			template.Env = append(template.Env, env)
+		}
+	}
+	for _, from := range container.EnvFrom {
+		source := ecsmv1.EnvFromSource{Prefix: from.Prefix}
+		if ref := from.ConfigMapRef; ref != nil {
+			source.ConfigRef = &ecsmv1.ConfigReference{Name: ref.Name, Optional: ref.Optional}
+		}
+		if ref := from.SecretRef; ref != nil {
+			source.SecretRef = &ecsmv1.SecretReference{Name: ref.Name, Optional: ref.Optional}
+		}
+		template.EnvFrom = append(template.EnvFrom, source)
+	}
+
+	template.Resources = c.resources(&container.Resources, fldPath.Child("resources"))
+	template.VolumeMounts = c.volumeMounts(container.VolumeMounts, pod.Volumes, fldPath.Child("volumeMounts"))
+	template.LivenessProbe = c.probe(container.LivenessProbe, container, fldPath.Child("livenessProbe"))
+	template.ReadinessProbe = c.probe(container.ReadinessProbe, container, fldPath.Child("readinessProbe"))
+	if container.StartupProbe != nil {
+		c.warn(fldPath.Child("startupProbe"), "dropped, use initialDelaySeconds of the other probes instead")
+	}
+	if len(container.Ports) > 0 {
+		c.warn(fldPath.Child("ports"), "dropped, containers on ECSM use the network of their node")
+	}
+	if container.SecurityContext != nil {
+		c.warn(fldPath.Child("securityContext"), "dropped")
+	}
+	return template, nil
+}
+
+// image 把 "registry/repository/name:tag" 形式的镜像转换为 ECSM 的 "name@tag"。
+// ECSM 从平台自己的镜像仓库拉取镜像，镜像仓库和路径被丢弃，没有 tag 的镜像使用 latest。
+func (c *converter) image(image string, fldPath *field.Path) (string, error) {
+	if image == "" {
+		return "", fmt.Errorf("%s: image must be specified", fldPath)
+	}
+	if strings.Contains(image, "@") {
+		return "", fmt.Errorf("%s: image %q is referenced by digest, which ECSM does not support", fldPath, image)
+	}
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name, tag, found := strings.Cut(name, ":")
+	if !found {
+		tag = "latest"
+	}
+	ref := name + "@" + tag
+	if strings.Contains(image, "/") {
+		c.warn(fldPath, "%q is converted to %q, the image must be pushed to the ECSM image registry", image, ref)
+	}
+	return ref, nil
+}
+
+func (c *converter) envVar(env *corev1.EnvVar, fldPath *field.Path) (ecsmv1.EnvVar, bool) {
+	out := ecsmv1.EnvVar{Name: env.Name, Value: env.Value}
+	from := env.ValueFrom
+	switch {
+	case from == nil:
+	case from.ConfigMapKeyRef != nil:
+		ref := from.ConfigMapKeyRef
+		out.ValueFrom = &ecsmv1.EnvVarSource{ConfigKeyRef: &ecsmv1.ConfigKeySelector{Name: ref.Name, Key: ref.Key, Optional: ref.Optional}}
+	case from.SecretKeyRef != nil:
+		ref := from.SecretKeyRef
+		out.ValueFrom = &ecsmv1.EnvVarSource{SecretKeyRef: &ecsmv1.SecretKeySelector{Name: ref.Name, Key: ref.Key, Optional: ref.Optional}}
+	default:
+		c.warn(fldPath.Child("valueFrom"), "environment variable %q is dropped, only configMapKeyRef and secretKeyRef are supported", env.Name)
+		return out, false
+	}
+	return out, true
+}
+
+// resources 转换资源限制。ECSM 只能限制内存和硬盘，请求被忽略。
+func (c *converter) resources(resources *corev1.ResourceRequirements, fldPath *field.Path) *ecsmv1.ResourceRequirements {
+	if len(resources.Requests) > 0 {
+		c.warn(fldPath.Child("requests"), "dropped, ECSM only supports limits")
+	}
+	limits := make(map[ecsmv1.ResourceType]string)
+	for name, q := range resources.Limits {
+		switch name {
+		case corev1.ResourceMemory:
+			limits[ecsmv1.ResourceTypeMemory] = q.String()
+		case corev1.ResourceEphemeralStorage:
+			limits[ecsmv1.ResourceTypeDisk] = q.String()
+		default:
+			c.warn(fldPath.Child("limits").Key(string(name)), "dropped, ECSM only limits memory and ephemeral-storage (disk)")
+		}
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	return &ecsmv1.ResourceRequirements{Limits: limits}
+}
+
+// volumeMounts 把挂载 hostPath 卷的挂载点转换为 ECSM 的挂载点，subPath 被拼接到主机路径上。
+func (c *converter) volumeMounts(mounts []corev1.VolumeMount, volumes []corev1.Volume, fldPath *field.Path) []ecsmv1.VolumeMount {
+	hostPaths := make(map[string]string)
+	for _, v := range volumes {
+		if v.HostPath != nil {
+			hostPaths[v.Name] = v.HostPath.Path
+		}
+	}
+	var out []ecsmv1.VolumeMount
+	for i, m := range mounts {
+		hostPath, ok := hostPaths[m.Name]
+		if !ok {
+			c.warn(fldPath.Index(i), "mount of volume %q is dropped, only hostPath volumes are supported", m.Name)
+			continue
+		}
+		if m.SubPath != "" {
+			hostPath = path.Join(hostPath, m.SubPath)
+		}
+		out = append(out, ecsmv1.VolumeMount{
+			Name:          m.Name,
+			HostPath:      hostPath,
+			ContainerPath: m.MountPath,
+			ReadOnly:      m.ReadOnly,
+		})
+	}
+	return out
+}
+
+// probe 转换 tcpSocket 和 exec 探针，ECSM 不支持 httpGet 和 grpc 探针。
+func (c *converter) probe(probe *corev1.Probe, container *corev1.Container, fldPath *field.Path) *ecsmv1.Probe {
+	if probe == nil {
+		return nil
+	}
+	out := &ecsmv1.Probe{HealthCheckSpec: ecsmv1.HealthCheckSpec{
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		FailureThreshold:    probe.FailureThreshold,
+	}}
+	switch {
+	case probe.TCPSocket != nil:
+		port, ok := containerPort(probe.TCPSocket.Port, container)
+		if !ok {
+			c.warn(fldPath.Child("tcpSocket", "port"), "probe is dropped, port %q is not a port of the container", probe.TCPSocket.Port.String())
+			return nil
+		}
+		out.TCPSocket = &ecsmv1.TCPSocketAction{Port: port}
+	case probe.Exec != nil:
+		out.Exec = &ecsmv1.ExecAction{Command: probe.Exec.Command}
+	default:
+		c.warn(fldPath, "probe is dropped, only tcpSocket and exec probes are supported")
+		return nil
+	}
+	return out
+}
+
+// containerPort 返回端口的数值，具名端口在容器的端口中查找。
+func containerPort(port intstr.IntOrString, container *corev1.Container) (int32, bool) {
+	if port.Type == intstr.Int {
+		return port.IntVal, true
+	}
+	for _, p := range container.Ports {
+		if p.Name == port.StrVal {
+			return p.ContainerPort, true
+		}
+	}
+	return 0, false
+}
+
+func (c *converter) toleration(t *corev1.Toleration, fldPath *field.Path) (ecsmv1.Toleration, bool) {
+	if t.TolerationSeconds != nil {
+		c.warn(fldPath.Child("tolerationSeconds"), "dropped, ECSM services tolerate matching taints indefinitely")
+	}
+	switch t.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		c.warn(fldPath.Child("effect"), "toleration is dropped, effect %s is not supported", t.Effect)
+		return ecsmv1.Toleration{}, false
+	}
+	return ecsmv1.Toleration{
+		Key:      t.Key,
+		Operator: ecsmv1.TolerationOperator(t.Operator),
+		Value:    t.Value,
+		Effect:   ecsmv1.TaintEffect(t.Effect),
+	}, true
+}
@@ -0,0 +1,176 @@
+// file: pkg/convert/deployment_test.go
+
+package convert
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/validation"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func newDeployment() *appsv1.Deployment {
+	replicas := int32(3)
+	maxSurge := intstr.FromInt32(1)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "edge",
+			Labels:    map[string]string{"app": "web"},
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: "{}",
+				"team":                             "edge",
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge}},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{"zone": "a"},
+					Volumes: []corev1.Volume{
+						{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/data"}}},
+						{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					},
+					Containers: []corev1.Container{{
+						Name:    "web",
+						Image:   "web:1.2",
+						Command: []string{"/bin/web"},
+						Args:    []string{"--port", "8080"},
+						Ports:   []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+						Env: []corev1.EnvVar{
+							{Name: "MODE", Value: "prod"},
+							{Name: "PASS", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}, Key: "password",
+							}}},
+							{Name: "POD", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+						},
+						EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}}}},
+						Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+							corev1.ResourceMemory:           resource.MustParse("256Mi"),
+							corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+							corev1.ResourceCPU:              resource.MustParse("500m"),
+						}},
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "data", MountPath: "/data", SubPath: "web", ReadOnly: true},
+							{Name: "cache", MountPath: "/cache"},
+						},
+						LivenessProbe: &corev1.Probe{
+							ProbeHandler:  corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("http")}},
+							PeriodSeconds: 5,
+						},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/ready", Port: intstr.FromInt32(8080)}},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestDeployment(t *testing.T) {
+	svc, warnings, err := Deployment(newDeployment())
+	if err != nil {
+		t.Fatalf("Deployment failed: %v", err)
+	}
+
+	if svc.Name != "web" || svc.Namespace != "edge" || svc.Kind != "ECSMService" || svc.APIVersion != "ecsm.sh/v1" {
+		t.Errorf("converted object is %s %s/%s", svc.GroupVersionKind(), svc.Namespace, svc.Name)
+	}
+	if _, ok := svc.Annotations[corev1.LastAppliedConfigAnnotation]; ok || svc.Annotations["team"] != "edge" {
+		t.Errorf("annotations = %v", svc.Annotations)
+	}
+	strategy := svc.Spec.DeploymentStrategy
+	if strategy.Type != ecsmv1.DeploymentStrategyTypeDynamic || *strategy.Replicas != 3 || strategy.NodeSelector["zone"] != "a" {
+		t.Errorf("deployment strategy = %+v", strategy)
+	}
+	if svc.Spec.UpgradeStrategy.MaxSurge.IntValue() != 1 {
+		t.Errorf("maxSurge = %v, want 1", svc.Spec.UpgradeStrategy.MaxSurge)
+	}
+
+	template := svc.Spec.Template
+	if template.Image != "web@1.2" {
+		t.Errorf("image = %q, want web@1.2", template.Image)
+	}
+	if strings.Join(template.Command, " ") != "/bin/web --port 8080" {
+		t.Errorf("command = %v", template.Command)
+	}
+	if len(template.Env) != 2 || template.Env[1].ValueFrom.SecretKeyRef.Name != "creds" {
+		t.Errorf("env = %+v", template.Env)
+	}
+	if len(template.EnvFrom) != 1 || template.EnvFrom[0].ConfigRef.Name != "settings" {
+		t.Errorf("envFrom = %+v", template.EnvFrom)
+	}
+	limits := template.Resources.Limits
+	if len(limits) != 2 || limits[ecsmv1.ResourceTypeMemory] != "256Mi" || limits[ecsmv1.ResourceTypeDisk] != "1Gi" {
+		t.Errorf("limits = %v", limits)
+	}
+	if len(template.VolumeMounts) != 1 || template.VolumeMounts[0] != (ecsmv1.VolumeMount{Name: "data", HostPath: "/var/data/web", ContainerPath: "/data", ReadOnly: true}) {
+		t.Errorf("volume mounts = %+v", template.VolumeMounts)
+	}
+	if p := template.LivenessProbe; p == nil || p.TCPSocket == nil || p.TCPSocket.Port != 8080 || p.PeriodSeconds != 5 {
+		t.Errorf("liveness probe = %+v", p)
+	}
+	if template.ReadinessProbe != nil {
+		t.Errorf("httpGet readiness probe was converted to %+v", template.ReadinessProbe)
+	}
+
+	// 被丢弃的字段都有警告
+	for _, field := range []string{"env[2]", "limits[cpu]", "volumeMounts[1]", "readinessProbe", "ports"} {
+		found := false
+		for _, w := range warnings {
+			found = found || strings.Contains(w, field)
+		}
+		if !found {
+			t.Errorf("no warning for %s in %v", field, warnings)
+		}
+	}
+
+	// 转换结果可以直接创建
+	defaults.SetServiceDefaults(svc)
+	if errs := validation.ValidateService(svc); len(errs) > 0 {
+		t.Errorf("converted service is invalid: %v", errs.ToAggregate())
+	}
+}
+
+func TestDeploymentErrors(t *testing.T) {
+	d := newDeployment()
+	d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{Name: "sidecar", Image: "proxy:1.0"})
+	if _, _, err := Deployment(d); err == nil {
+		t.Error("a deployment with two containers was converted")
+	}
+
+	d = newDeployment()
+	d.Spec.Template.Spec.Containers[0].Image = "web@sha256:0123"
+	if _, _, err := Deployment(d); err == nil {
+		t.Error("an image referenced by digest was converted")
+	}
+}
+
+func TestImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+		warn  bool
+	}{
+		{"web", "web@latest", false},
+		{"web:1.2", "web@1.2", false},
+		{"library/web:1.2", "web@1.2", true},
+		{"registry.example.com:5000/team/web", "web@latest", true},
+	}
+	for _, tt := range tests {
+		c := &converter{}
+		got, err := c.image(tt.image, nil)
+		if err != nil || got != tt.want || (len(c.warnings) > 0) != tt.warn {
+			t.Errorf("image(%q) = %q, %v with warnings %v, want %q", tt.image, got, err, c.warnings, tt.want)
+		}
+	}
+}
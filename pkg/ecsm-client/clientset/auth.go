@@ -0,0 +1,79 @@
+package clientset
+
+import (
+	"context"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+)
+
+type AuthGetter interface {
+	Auth() AuthInterface
+}
+
+// AuthInterface 提供了登录 ECSM API Server 和管理 Token 的方法。
+// 这些请求本身不携带 Clientset 的凭据。
+type AuthInterface interface {
+	// Login 使用用户名和密码登录，返回 Token。
+	Login(ctx context.Context, req *LoginRequest) (*Token, error)
+	// Refresh 使用登录时得到的刷新令牌换取新的 Token。
+	Refresh(ctx context.Context, refreshToken string) (*Token, error)
+	// Logout 注销 token，此后使用它的请求会被服务器拒绝。
+	Logout(ctx context.Context, token string) error
+}
+
+type authClient struct {
+	restClient rest.Interface
+}
+
+func newAuth(restClient rest.Interface) *authClient {
+	return &authClient{restClient: restClient}
+}
+
+// Login 实现了 AuthInterface 的同名方法。
+func (c *authClient) Login(ctx context.Context, req *LoginRequest) (*Token, error) {
+	result := &Token{}
+	resp := c.restClient.Post().
+		Resource("user/login").
+		Body(req).
+		Do(ctx)
+	if err := resp.Into(result); err != nil {
+		return nil, err
+	}
+	result.Cookies = resp.Cookies()
+	return result, nil
+}
+
+// Refresh 实现了 AuthInterface 的同名方法。
+func (c *authClient) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	result := &Token{}
+	resp := c.restClient.Post().
+		Resource("user/refresh").
+		Body(&RefreshRequest{RefreshToken: refreshToken}).
+		Do(ctx)
+	if err := resp.Into(result); err != nil {
+		return nil, err
+	}
+	result.Cookies = resp.Cookies()
+	return result, nil
+}
+
+// Logout 实现了 AuthInterface 的同名方法。
+func (c *authClient) Logout(ctx context.Context, token string) error {
+	return c.restClient.Post().
+		Resource("user/logout").
+		Body(&LogoutRequest{Token: token}).
+		Do(ctx).
+		Into(nil)
+}
+
+// NewLoginAuthProvider 返回用 username 和 password 登录的 rest.AuthProvider，auth 通常是同一个 Clientset 的 Auth()。
+// 登录得到的 Token 和会话 Cookie 被添加到每个请求中，Token 快要过期或服务器以 401 拒绝请求时重新登录。
+func NewLoginAuthProvider(auth AuthInterface, username, password string) rest.AuthProvider {
+	return rest.NewSessionAuthProvider(func(ctx context.Context) (*rest.Credentials, error) {
+		token, err := auth.Login(ctx, &LoginRequest{Username: username, Password: password})
+		if err != nil {
+			return nil, err
+		}
+		return &rest.Credentials{Token: token.Token, Cookies: token.Cookies, Expiry: token.Expiry()}, nil
+	})
+}
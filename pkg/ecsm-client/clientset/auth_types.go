@@ -0,0 +1,42 @@
+package clientset
+
+import (
+	"net/http"
+	"time"
+)
+
+// LoginRequest 是登录 ECSM API Server 的 API payload。
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest 是用刷新令牌换取新 Token 的 API payload。
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// LogoutRequest 是注销 Token 的 API payload。
+type LogoutRequest struct {
+	Token string `json:"token"`
+}
+
+// Token 是登录或刷新成功后服务器返回的凭据。
+type Token struct {
+	// Token 在请求中以 "Authorization: Bearer <Token>" 的形式发送
+	Token string `json:"token"`
+	// RefreshToken 用于在 Token 过期前换取新的 Token，服务器不支持刷新时为空
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// ExpiresAt 是 Token 过期的时间 (Unix 毫秒)，为 0 表示不过期
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+	// Cookies 是服务器在响应中设置的会话 Cookie，使用 Cookie 维持会话的服务器需要在之后的请求中携带它们
+	Cookies []*http.Cookie `json:"-"`
+}
+
+// Expiry 返回 Token 过期的时间，不过期时返回零值。
+func (t *Token) Expiry() time.Time {
+	if t.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(t.ExpiresAt)
+}
@@ -1,6 +1,11 @@
 package clientset
 
-import "github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+import (
+	"io"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"go.opentelemetry.io/otel/trace"
+)
 
 type Interface interface {
 	RESTClient() rest.RESTClient
@@ -8,6 +13,9 @@ type Interface interface {
 	RecordGetter
 	ContainerGetter
 	NodeGetter
+	TransactionGetter
+	DiscoveryGetter
+	AuthGetter
 }
 
 type Clientset struct {
@@ -16,8 +24,13 @@ type Clientset struct {
 
 // NewClientset 创建一个新的 Clientset 实例，用于与 ECSM API 交互
 func NewClientset(protocol, host, port string) (*Clientset, error) {
+	return NewClientsetForConfig(&rest.Config{Protocol: protocol, Host: host, Port: port})
+}
+
+// NewClientsetForConfig 按照 config 创建 Clientset，用于需要 TLS 选项等额外设置的情况
+func NewClientsetForConfig(config *rest.Config) (*Clientset, error) {
 	// 创建 REST 客户端
-	restClient, err := rest.NewRESTClient(protocol, host, port, nil)
+	restClient, err := rest.RESTClientFor(config)
 	if err != nil {
 		return nil, err
 	}
@@ -28,6 +41,65 @@ func NewClientset(protocol, host, port string) (*Clientset, error) {
 	}, nil
 }
 
+// NewForRESTClient 返回使用 c 发送请求的 Clientset，例如在单元测试中使用 rest.Fake 的客户端:
+//
+//	fake := rest.NewFake()
+//	fake.AddReactor("GET", "service/*", rest.RespondWith(&clientset.ServiceGet{ID: "0f4b1c"}))
+//	cs := clientset.NewForRESTClient(fake.RESTClient)
+//
+// Clientset 复制了 c，之后对 c 的设置不影响 Clientset。
+func NewForRESTClient(c *rest.RESTClient) *Clientset {
+	return &Clientset{restClient: *c}
+}
+
+// SetMaxInflightRequests 限制同一时刻发往 ECSM API Server 的最大请求数，n <= 0 表示不限制。
+// 它必须在 Clientset 被使用之前调用。
+func (c *Clientset) SetMaxInflightRequests(n int) {
+	c.restClient.SetMaxInflightRequests(n)
+}
+
+// SetRateLimit 限制 Clientset 每秒平均发出 qps 个请求，允许突发 burst 个请求，qps <= 0 表示不限制。
+// 它必须在 Clientset 被使用之前调用。
+func (c *Clientset) SetRateLimit(qps float32, burst int) {
+	c.restClient.SetRateLimit(qps, burst)
+}
+
+// SetRetryPolicy 让 Clientset 的请求在暂时性的失败之后按照 policy 重试，参见 rest.RetryPolicy。
+// 它必须在 Clientset 被使用之前调用。
+func (c *Clientset) SetRetryPolicy(policy rest.RetryPolicy) {
+	c.restClient.SetRetryPolicy(policy)
+}
+
+// Use 在 Clientset 的拦截器链中添加 middlewares，参见 rest.RESTClient.Use。
+// 它必须在 Clientset 被使用之前调用。
+func (c *Clientset) Use(middlewares ...rest.Middleware) {
+	c.restClient.Use(middlewares...)
+}
+
+// SetTracerProvider 让 Clientset 用 tp 创建请求的 span，tp 为 nil 时使用 OpenTelemetry 的全局 TracerProvider。
+// 它必须在 Clientset 被使用之前调用。
+func (c *Clientset) SetTracerProvider(tp trace.TracerProvider) {
+	c.restClient.SetTracerProvider(tp)
+}
+
+// SetDebugLevel 让 Clientset 按 level 记录每个请求和响应，out 为 nil 时记录到 klog。
+// 它必须在 Clientset 被使用之前调用。
+func (c *Clientset) SetDebugLevel(level rest.DebugLevel, out io.Writer) {
+	c.restClient.SetDebugLevel(level, out)
+}
+
+// SetAuthProvider 让 Clientset 的请求携带 p 提供的凭据，Auth() 的请求除外，参见 rest.RESTClient.SetAuthProvider。
+// 它必须在 Clientset 被使用之前调用。
+func (c *Clientset) SetAuthProvider(p rest.AuthProvider) {
+	c.restClient.SetAuthProvider(p)
+}
+
+// SetTokenSource 让 Clientset 的请求携带 ts 提供的 Bearer Token，Auth() 的请求除外。
+// 它必须在 Clientset 被使用之前调用。
+func (c *Clientset) SetTokenSource(ts rest.TokenSource) {
+	c.restClient.SetTokenSource(ts)
+}
+
 // RESTClient 返回底层的 REST 客户端
 func (c *Clientset) RESTClient() rest.RESTClient {
 	return c.restClient
@@ -55,3 +127,21 @@ func (c *Clientset) Nodes() NodeInterface {
 func (c *Clientset) Images() ImageInterface {
 	return newImages(&c.restClient)
 }
+
+// Transactions 返回 TransactionInterface，用于查询异步事务的状态
+func (c *Clientset) Transactions() TransactionInterface {
+	return newTransactions(&c.restClient)
+}
+
+// Discovery 返回 DiscoveryInterface，用于查询服务器的版本和能力
+func (c *Clientset) Discovery() DiscoveryInterface {
+	return newDiscovery(&c.restClient)
+}
+
+// Auth 返回 AuthInterface，用于登录和刷新 Token。
+// 它的请求不携带凭据，因此可以在 TokenSource 中使用它刷新 Token。
+func (c *Clientset) Auth() AuthInterface {
+	restClient := c.restClient
+	restClient.SetAuthProvider(nil)
+	return newAuth(&restClient)
+}
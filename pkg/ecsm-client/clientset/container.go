@@ -31,6 +31,13 @@ type ContainerInterface interface {
 	SubmitControlActionByName(ctx context.Context, containerName string, action ContainerAction) (*Transaction, error)
 
 	SubmitControlActionByService(ctx context.Context, serviceID string, action ContainerAction) (*Transaction, error)
+
+	// SubmitControlActionByLabel 对带有路径标签 label 的所有服务的容器执行同一个动作，整个操作是一个事务。
+	// label 与 ListServicesOptions.Label 含义相同。
+	SubmitControlActionByLabel(ctx context.Context, label string, action ContainerAction) (*Transaction, error)
+
+	// Logs 获取容器的日志，按时间从早到晚排列。
+	Logs(ctx context.Context, opts ContainerLogOptions) (*ContainerLogList, error)
 }
 
 type containerClient struct {
@@ -131,6 +138,19 @@ func (c *containerClient) SubmitControlActionByService(ctx context.Context, serv
 	return result, err
 }
 
+// SubmitControlActionByLabel 实现了 ContainerInterface 的同名方法。
+func (c *containerClient) SubmitControlActionByLabel(ctx context.Context, label string, action ContainerAction) (*Transaction, error) {
+	result := &Transaction{}
+
+	err := c.restClient.Put().
+		Resource("service/container/label").
+		Body(&LabelControlContainerRequest{Label: label, Action: action}).
+		Do(ctx).
+		Into(result)
+
+	return result, err
+}
+
 // GetHistory 实现了 ContainerInterface 的同名方法。
 func (c *containerClient) GetHistory(ctx context.Context, opts ContainerHistoryOptions) (*ContainerHistoryList, error) {
 	result := &ContainerHistoryList{}
@@ -235,3 +255,23 @@ func (c *containerClient) GetByName(ctx context.Context, serviceClient ServiceIn
 
 	return nil, fmt.Errorf("container with name '%s' not found", name)
 }
+
+// Logs 实现了 ContainerInterface 的同名方法。
+func (c *containerClient) Logs(ctx context.Context, opts ContainerLogOptions) (*ContainerLogList, error) {
+	result := &ContainerLogList{}
+
+	req := c.restClient.Get().
+		Resource("container/log")
+
+	// 添加查询参数
+	req.Param("id", opts.TaskID)
+	if opts.Tail > 0 {
+		req.Param("tail", strconv.Itoa(opts.Tail))
+	}
+	if opts.Since > 0 {
+		req.Param("since", strconv.FormatInt(opts.Since, 10))
+	}
+
+	err := req.Do(ctx).Into(result)
+	return result, err
+}
@@ -92,12 +92,10 @@ type ServiceControlContainerRequest struct {
 	Action ContainerAction `json:"action"`
 }
 
-// Transaction 描述了一个异步操作任务。
-type Transaction struct {
-	ID        string      `json:"id"`
-	Status    string      `json:"status"` // "running", "failure", "success"
-	Data      interface{} `json:"data"`   // 使用 interface{} 来匹配任意对象
-	Timestamp int64       `json:"timestamp"`
+// LabelControlContainerRequest 是按路径标签控制服务容器的 API payload。
+type LabelControlContainerRequest struct {
+	Label  string          `json:"label"`
+	Action ContainerAction `json:"action"`
 }
 
 // --- Container History Structures ---
@@ -125,3 +123,27 @@ type ContainerHistory struct {
 	User string `json:"user"`
 	Time string `json:"time"`
 }
+
+// --- Container Log Structures ---
+
+// ContainerLogOptions 封装了查询容器日志的参数。
+type ContainerLogOptions struct {
+	// 与 ContainerHistoryOptions 相同，API 的 'id' 字段指的是 Task ID。
+	TaskID string `json:"id"`
+	// Tail 只返回最后的 Tail 条日志，0 表示返回所有日志。
+	Tail int `json:"tail,omitempty"`
+	// Since 只返回不早于该时间（毫秒时间戳）的日志，0 表示不限制。
+	Since int64 `json:"since,omitempty"`
+}
+
+// ContainerLogList 是 Logs 方法的返回值。
+type ContainerLogList struct {
+	Items []ContainerLogEntry `json:"list"`
+}
+
+// ContainerLogEntry 代表容器输出的一条日志。
+type ContainerLogEntry struct {
+	Timestamp int64  `json:"timestamp"` // 毫秒时间戳
+	Stream    string `json:"stream"`    // "stdout" 或 "stderr"
+	Message   string `json:"message"`
+}
@@ -0,0 +1,146 @@
+package clientset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// MinServerVersion 是本客户端支持的最低 ECSM 服务器版本，主版本号不同的服务器也不受支持。
+const MinServerVersion = "2.0.0"
+
+// SupportedAPIVersions 是本客户端实现了的 ECSM API 版本，按优先顺序排列，
+// NegotiateAPIVersion 选择其中第一个服务器也支持的版本。
+var SupportedAPIVersions = []string{"v1"}
+
+// AutoAPIVersion 是 ecsm-cli 和 operator 的 --api-version 的特殊值，表示用 NegotiateAPIVersion 选择 API 版本。
+const AutoAPIVersion = "auto"
+
+// ErrVersionUnavailable 表示服务器没有提供版本接口，通常是不支持版本发现的旧版本服务器。
+var ErrVersionUnavailable = errors.New("the ECSM server does not provide version information")
+
+// ErrNoCommonAPIVersion 表示服务器声明的 API 版本都不被本客户端支持。
+var ErrNoCommonAPIVersion = errors.New("the ECSM server supports none of the API versions of this client")
+
+type DiscoveryGetter interface {
+	Discovery() DiscoveryInterface
+}
+
+// DiscoveryInterface 提供了查询 ECSM 服务器版本和能力的方法。
+type DiscoveryInterface interface {
+	// ServerVersion 获取服务器的版本和能力。服务器没有版本接口时返回 ErrVersionUnavailable。
+	ServerVersion(ctx context.Context) (*ServerInfo, error)
+}
+
+// ServerInfo 是 ECSM 服务器的版本信息
+type ServerInfo struct {
+	Version      string   `json:"version"`
+	APIVersions  []string `json:"apiVersions"`
+	Capabilities []string `json:"capabilities"`
+	BuildTime    string   `json:"buildTime,omitempty"`
+	GitCommit    string   `json:"gitCommit,omitempty"`
+}
+
+// HasCapability 判断服务器是否声明了能力 name。
+func (s *ServerInfo) HasCapability(name string) bool {
+	for _, c := range s.Capabilities {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCompatibility 检查服务器版本是否被本客户端支持，不支持时返回描述原因的错误。
+func (s *ServerInfo) CheckCompatibility() error {
+	server, err := version.ParseGeneric(s.Version)
+	if err != nil {
+		return fmt.Errorf("cannot parse server version %q: %w", s.Version, err)
+	}
+	minimum := version.MustParseGeneric(MinServerVersion)
+	if server.Major() != minimum.Major() || !server.AtLeast(minimum) {
+		return fmt.Errorf("server version %s is not supported, this client requires a %d.x server of at least %s",
+			s.Version, minimum.Major(), MinServerVersion)
+	}
+	return nil
+}
+
+// NegotiateAPIVersion 探测服务器支持的 API 版本，并让 Clientset 的请求使用 candidates 中第一个服务器支持的版本。
+// candidates 为空时使用 SupportedAPIVersions。版本接口依次在当前版本和 candidates 的路径下探测，
+// 因此服务器升级后不再提供当前版本时也能找到新的版本。服务器没有版本接口时，
+// 它是只提供当前版本的旧服务器，Clientset 不变。返回 Clientset 使用的版本。
+// 它必须在 Clientset 被使用之前调用，并且应该在 SetAuthProvider 之前调用，
+// 使 Auth() 创建的 LoginAuthProvider 等认证也使用协商的版本，此时版本接口的请求不携带凭据。
+func (c *Clientset) NegotiateAPIVersion(ctx context.Context, candidates ...string) (string, error) {
+	if len(candidates) == 0 {
+		candidates = SupportedAPIVersions
+	}
+	apiPath, current := path.Split(c.restClient.APIVersion())
+
+	var info *ServerInfo
+	var probed string
+	for _, v := range append([]string{current}, candidates...) {
+		probe := c.restClient
+		probe.SetAPIVersion(apiPath, v)
+		var err error
+		info, err = newDiscovery(&probe).ServerVersion(ctx)
+		if errors.Is(err, ErrVersionUnavailable) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to probe the API versions of the server: %w", err)
+		}
+		probed = v
+		break
+	}
+	if info == nil {
+		return current, nil
+	}
+
+	selected := ""
+	if len(info.APIVersions) == 0 {
+		// 没有列出 API 版本的服务器只提供回答了版本接口的那个版本
+		selected = probed
+	} else {
+		for _, v := range candidates {
+			if slices.Contains(info.APIVersions, v) {
+				selected = v
+				break
+			}
+		}
+	}
+	if selected == "" {
+		return "", fmt.Errorf("%w: server %s supports %v, client supports %v",
+			ErrNoCommonAPIVersion, info.Version, info.APIVersions, candidates)
+	}
+	c.restClient.SetAPIVersion(apiPath, selected)
+	return selected, nil
+}
+
+type discoveryClient struct {
+	restClient rest.Interface
+}
+
+func newDiscovery(restClient rest.Interface) *discoveryClient {
+	return &discoveryClient{restClient: restClient}
+}
+
+func (c *discoveryClient) ServerVersion(ctx context.Context) (*ServerInfo, error) {
+	result := &ServerInfo{}
+	err := c.restClient.Get().
+		Resource("version").
+		Do(ctx).
+		Into(result)
+	if rest.IsNotFound(err) {
+		return nil, ErrVersionUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
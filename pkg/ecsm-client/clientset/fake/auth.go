@@ -0,0 +1,65 @@
+// file: pkg/ecsm-client/clientset/fake/auth.go
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+)
+
+// tokenLifetime 是 fakeAuth 签发的 Token 的有效期
+const tokenLifetime = time.Hour
+
+type fakeAuth struct {
+	*Clientset
+}
+
+var _ clientset.AuthInterface = &fakeAuth{}
+
+// Login 实现了 AuthInterface 的同名方法，任何非空的用户名都可以登录。
+func (c *fakeAuth) Login(ctx context.Context, req *clientset.LoginRequest) (*clientset.Token, error) {
+	action := Action{Verb: "Login", Resource: "auth", Name: req.Username, Object: req}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.Token, error) {
+		if req.Username == "" {
+			return nil, &rest.Aerror{Status: http.StatusUnauthorized, Message: "invalid username or password"}
+		}
+		return c.tracker.newToken(), nil
+	})
+}
+
+// Refresh 实现了 AuthInterface 的同名方法，任何非空的刷新令牌都可以换取新的 Token。
+func (c *fakeAuth) Refresh(ctx context.Context, refreshToken string) (*clientset.Token, error) {
+	action := Action{Verb: "Refresh", Resource: "auth", Object: refreshToken}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.Token, error) {
+		if refreshToken == "" {
+			return nil, &rest.Aerror{Status: http.StatusUnauthorized, Message: "invalid refresh token"}
+		}
+		return c.tracker.newToken(), nil
+	})
+}
+
+// Logout 实现了 AuthInterface 的同名方法。
+func (c *fakeAuth) Logout(ctx context.Context, token string) error {
+	action := Action{Verb: "Logout", Resource: "auth", Object: token}
+	_, err := invoke(ctx, c.Clientset, action, func() (struct{}, error) {
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// newToken 签发一个新的 Token，每个 Token 都不相同。
+func (t *Tracker) newToken() *clientset.Token {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	return &clientset.Token{
+		Token:        fmt.Sprintf("token-%d", t.nextID),
+		RefreshToken: fmt.Sprintf("refresh-%d", t.nextID),
+		ExpiresAt:    time.Now().Add(tokenLifetime).UnixMilli(),
+	}
+}
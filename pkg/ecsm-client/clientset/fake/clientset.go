@@ -0,0 +1,193 @@
+// file: pkg/ecsm-client/clientset/fake/clientset.go
+
+// Package fake 提供了在内存中模拟 ECSM 平台的 clientset.Interface 实现，用于控制器的单元测试。
+//
+// 与 rest.Fake 按 HTTP 请求编写响应不同，fake.Clientset 的各个客户端直接读写同一个 Tracker，
+// 创建的服务会出现在之后的列表中，它的容器也会按副本数被启动，因此可以在不编写任何响应的情况下
+// 确定地执行控制器的多轮调谐:
+//
+//	cs := fake.NewClientset()
+//	cs.Tracker().AddNode(&clientset.NodeInfo{Name: "node-1"})
+//	clusters := controller.NewClusterClients(controller.DefaultClusterName, cs)
+//
+// reactor 可以在请求到达 Tracker 之前返回错误或自定义的结果，用于模拟平台的故障。
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+)
+
+// Clientset 实现了 clientset.Interface，它的请求由 Tracker 处理，不连接 ECSM API Server。
+type Clientset struct {
+	tracker *Tracker
+	// restClient 是没有 reactor 的 rest.Fake，通过 RESTClient() 直接发出的请求都会失败
+	restClient *rest.Fake
+
+	mu       sync.Mutex
+	reactors []reactor
+	actions  []Action
+}
+
+var _ clientset.Interface = &Clientset{}
+
+// Action 是 Clientset 收到的一次调用。
+type Action struct {
+	// Verb 是被调用的方法名，例如 "Create"、"ListAll"
+	Verb string
+	// Resource 是方法所属的客户端，例如 "services"、"containers"
+	Resource string
+	// Name 是调用的目标，通常是对象的 ID，没有单一目标的调用 (例如 List) 为空
+	Name string
+	// Object 是调用的请求体或选项，例如 *clientset.CreateServiceRequest
+	Object interface{}
+}
+
+// Matches 判断 action 是否匹配 verb 和 resource，两者都不区分大小写，"*" 匹配任何值。
+func (a Action) Matches(verb, resource string) bool {
+	return (verb == "*" || strings.EqualFold(verb, a.Verb)) &&
+		(resource == "*" || strings.EqualFold(resource, a.Resource))
+}
+
+// ReactionFunc 在调用到达 Tracker 之前处理它。handled 为 false 时调用交给下一个 reactor，
+// 最终由 Tracker 处理。handled 为 true 时，err 不为 nil 则方法返回 err，否则返回 ret，
+// ret 必须是方法的返回值的类型 (例如 Services().Get 的 *clientset.ServiceGet)，为 nil 时返回零值。
+type ReactionFunc func(action Action) (handled bool, ret interface{}, err error)
+
+type reactor struct {
+	verb, resource string
+	reaction       ReactionFunc
+}
+
+// NewClientset 返回使用空的 Tracker 的 Clientset。
+func NewClientset() *Clientset {
+	return NewClientsetWithTracker(NewTracker())
+}
+
+// NewClientsetWithTracker 返回使用 t 的 Clientset，多个 Clientset 可以共享同一个 Tracker。
+func NewClientsetWithTracker(t *Tracker) *Clientset {
+	return &Clientset{tracker: t, restClient: rest.NewFake()}
+}
+
+// Tracker 返回 Clientset 使用的 Tracker，用于准备测试数据和检查调用的结果。
+func (c *Clientset) Tracker() *Tracker {
+	return c.tracker
+}
+
+// AddReactor 在已有的 reactor 之后添加一个 reactor，参数的含义参见 Action.Matches。
+func (c *Clientset) AddReactor(verb, resource string, reaction ReactionFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reactors = append(c.reactors, reactor{verb: verb, resource: resource, reaction: reaction})
+}
+
+// PrependReactor 在已有的 reactor 之前添加一个 reactor，用于在个别测试中覆盖公共的行为。
+func (c *Clientset) PrependReactor(verb, resource string, reaction ReactionFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reactors = append([]reactor{{verb: verb, resource: resource, reaction: reaction}}, c.reactors...)
+}
+
+// Actions 返回 Clientset 按顺序收到的所有调用，包括被 reactor 处理的调用。
+func (c *Clientset) Actions() []Action {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Action(nil), c.actions...)
+}
+
+// ClearActions 清空记录的调用。
+func (c *Clientset) ClearActions() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.actions = nil
+}
+
+// ReturnError 返回让匹配的调用以 err 失败的 ReactionFunc。
+func ReturnError(err error) ReactionFunc {
+	return func(Action) (bool, interface{}, error) {
+		return true, nil, err
+	}
+}
+
+// invoke 记录 action 并把它交给第一个处理了它的 reactor，没有 reactor 处理时调用 track。
+// ctx 已经结束时与真实的客户端一样返回 ctx 的错误。
+func invoke[T any](ctx context.Context, c *Clientset, action Action, track func() (T, error)) (T, error) {
+	var zero T
+	c.mu.Lock()
+	c.actions = append(c.actions, action)
+	reactors := c.reactors
+	c.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	// reactor 在锁之外调用，它们可以添加新的 reactor 或通过 Clientset 发起调用
+	for _, r := range reactors {
+		if !action.Matches(r.verb, r.resource) {
+			continue
+		}
+		handled, ret, err := r.reaction(action)
+		if !handled {
+			continue
+		}
+		if err != nil || ret == nil {
+			return zero, err
+		}
+		result, ok := ret.(T)
+		if !ok {
+			return zero, fmt.Errorf("reactor for %s %s returned %T, want %T", action.Verb, action.Resource, ret, zero)
+		}
+		return result, nil
+	}
+	return track()
+}
+
+// RESTClient 返回一个没有 reactor 的 rest.Fake 的客户端，通过它直接发出的请求都会失败。
+func (c *Clientset) RESTClient() rest.RESTClient {
+	return *c.restClient.RESTClient
+}
+
+// Services 返回由 Tracker 处理的 ServiceInterface
+func (c *Clientset) Services() clientset.ServiceInterface {
+	return &fakeServices{Clientset: c}
+}
+
+// Records 与真实的 Clientset 一样返回 nil
+func (c *Clientset) Records() clientset.RecordInterface {
+	return nil
+}
+
+// Containers 返回由 Tracker 处理的 ContainerInterface
+func (c *Clientset) Containers() clientset.ContainerInterface {
+	return &fakeContainers{Clientset: c}
+}
+
+// Nodes 返回由 Tracker 处理的 NodeInterface
+func (c *Clientset) Nodes() clientset.NodeInterface {
+	return &fakeNodes{Clientset: c}
+}
+
+// Images 返回由 Tracker 处理的 ImageInterface
+func (c *Clientset) Images() clientset.ImageInterface {
+	return &fakeImages{Clientset: c}
+}
+
+// Transactions 返回由 Tracker 处理的 TransactionInterface
+func (c *Clientset) Transactions() clientset.TransactionInterface {
+	return &fakeTransactions{Clientset: c}
+}
+
+// Discovery 返回 DiscoveryInterface，它返回 Tracker.SetServerInfo 设置的服务器信息
+func (c *Clientset) Discovery() clientset.DiscoveryInterface {
+	return &fakeDiscovery{Clientset: c}
+}
+
+// Auth 返回 AuthInterface，它接受任何凭据并签发新的 Token
+func (c *Clientset) Auth() clientset.AuthInterface {
+	return &fakeAuth{Clientset: c}
+}
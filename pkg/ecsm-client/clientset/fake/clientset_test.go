@@ -0,0 +1,260 @@
+// file: pkg/ecsm-client/clientset/fake/clientset_test.go
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+)
+
+func newTestClientset() *Clientset {
+	cs := NewClientset()
+	cs.Tracker().AddNode(&clientset.NodeInfo{Name: "node-1", Address: "10.0.0.1:3001"})
+	cs.Tracker().AddNode(&clientset.NodeInfo{Name: "node-2", Address: "10.0.0.2:3001"})
+	return cs
+}
+
+// TestServices_Lifecycle 测试创建、扩缩容、修改镜像和删除服务时容器随之变化。
+func TestServices_Lifecycle(t *testing.T) {
+	cs := newTestClientset()
+	ctx := context.Background()
+	factor := 3
+
+	resp, err := cs.Services().Create(ctx, &clientset.CreateServiceRequest{
+		Name:   "web",
+		Image:  clientset.ImageSpec{Ref: "nginx@1.0#sylixos"},
+		Factor: &factor,
+		Labels: []string{"app=web"},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(resp.Containers) != 3 {
+		t.Fatalf("Expected 3 containers, got %v", resp.Containers)
+	}
+	if _, err := cs.Services().Create(ctx, &clientset.CreateServiceRequest{Name: "web", Image: clientset.ImageSpec{Ref: "nginx@1.0"}}); !rest.IsConflict(err) {
+		t.Errorf("Expected a conflict for a duplicate name, got %v", err)
+	}
+
+	containers, err := cs.Containers().ListAllByService(ctx, clientset.ListContainersByServiceOptions{ServiceIDs: []string{resp.ID}})
+	if err != nil {
+		t.Fatalf("ListAllByService failed: %v", err)
+	}
+	nodes := map[string]int{}
+	for _, co := range containers {
+		if co.Status != "running" || co.ImageName != "nginx" || co.ImageVersion != "1.0" || co.ImageOS != "sylixos" {
+			t.Errorf("Unexpected container %+v", co)
+		}
+		nodes[co.NodeName]++
+	}
+	if nodes["node-1"] != 2 || nodes["node-2"] != 1 {
+		t.Errorf("Expected the containers to be spread over the nodes, got %v", nodes)
+	}
+
+	rows, err := cs.Services().ListAll(ctx, clientset.ListServicesOptions{Label: "app=web"})
+	if err != nil || len(rows) != 1 || rows[0].Factor != 3 || rows[0].InstanceOnline != 3 || len(rows[0].NodeList) != 2 {
+		t.Fatalf("Unexpected rows %+v (err %v)", rows, err)
+	}
+
+	// 缩容保留已有的容器
+	if _, err := cs.Services().Patch(ctx, resp.ID, rest.MergePatchType, []byte(`{"factor":1}`)); err != nil {
+		t.Fatalf("Patch failed: %v", err)
+	}
+	containers, _ = cs.Containers().ListAllByService(ctx, clientset.ListContainersByServiceOptions{ServiceIDs: []string{resp.ID}})
+	if len(containers) != 1 || containers[0].TaskID != resp.Containers[0] {
+		t.Errorf("Expected the first container to be kept, got %+v", containers)
+	}
+
+	// 修改镜像重新创建容器
+	svc, err := cs.Services().Get(ctx, resp.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	req := svc.UpdateRequest()
+	req.Image.Ref = "nginx@2.0#sylixos"
+	if _, err := cs.Services().Update(ctx, resp.ID, req); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	containers, _ = cs.Containers().ListAllByService(ctx, clientset.ListContainersByServiceOptions{ServiceIDs: []string{resp.ID}})
+	if len(containers) != 1 || containers[0].ImageVersion != "2.0" || containers[0].TaskID == resp.Containers[0] {
+		t.Errorf("Expected a new container running the new image, got %+v", containers)
+	}
+
+	deleted, err := cs.Services().Delete(ctx, resp.ID)
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	tx, err := cs.Transactions().Get(ctx, deleted.ID)
+	if err != nil || tx.Status != clientset.TransactionStatusSuccess {
+		t.Errorf("Expected a successful transaction, got %+v (err %v)", tx, err)
+	}
+	if _, err := cs.Services().Get(ctx, resp.ID); !rest.IsNotFound(err) {
+		t.Errorf("Expected the service to be gone, got %v", err)
+	}
+	if len(cs.Tracker().Containers()) != 0 {
+		t.Errorf("Expected the containers to be deleted with the service")
+	}
+}
+
+// TestServices_CopiesImage 测试修改请求或返回的镜像规格不会影响 Tracker 中保存的服务。
+func TestServices_CopiesImage(t *testing.T) {
+	cs := newTestClientset()
+	ctx := context.Background()
+	req := &clientset.CreateServiceRequest{
+		Name:  "web",
+		Image: clientset.ImageSpec{Ref: "nginx@1.0", VSOA: &clientset.ImageVSOA{Password: "secret"}},
+	}
+	resp, err := cs.Services().Create(ctx, req)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	req.Image.VSOA.Password = "changed"
+
+	svc, _ := cs.Services().Get(ctx, resp.ID)
+	svc.Image.VSOA.Password = ""
+	if svc, _ := cs.Services().Get(ctx, resp.ID); svc.Image.VSOA.Password != "secret" {
+		t.Errorf("Expected the stored password to be unchanged, got %q", svc.Image.VSOA.Password)
+	}
+}
+
+// TestServices_Static 测试 Static 策略的服务在每个节点上恰好运行一个容器。
+func TestServices_Static(t *testing.T) {
+	cs := newTestClientset()
+	ctx := context.Background()
+
+	resp, err := cs.Services().Create(ctx, &clientset.CreateServiceRequest{
+		Name:   "agent",
+		Image:  clientset.ImageSpec{Ref: "agent@1.0"},
+		Node:   clientset.NodeSpec{Names: []string{"node-1", "node-2"}},
+		Policy: "static",
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	svc, _ := cs.Services().Get(ctx, resp.ID)
+	if svc.Factor != 2 || len(svc.NodeList) != 2 {
+		t.Fatalf("Expected one instance per node, got %+v", svc)
+	}
+
+	req := svc.UpdateRequest()
+	req.Node.Names = []string{"node-2"}
+	if _, err := cs.Services().Update(ctx, resp.ID, req); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	containers := cs.Tracker().Containers()
+	if len(containers) != 1 || containers[0].NodeName != "node-2" || containers[0].TaskID != resp.Containers[1] {
+		t.Errorf("Expected only the container on node-2 to be kept, got %+v", containers)
+	}
+}
+
+// TestClientset_Reactors 测试 reactor 可以注入错误和结果，并且所有调用都被记录。
+func TestClientset_Reactors(t *testing.T) {
+	cs := newTestClientset()
+	ctx := context.Background()
+	cs.AddReactor("create", "services", ReturnError(&rest.Aerror{Status: http.StatusServiceUnavailable, Message: "busy"}))
+	cs.PrependReactor("get", "services", func(action Action) (bool, interface{}, error) {
+		return action.Name == "special", &clientset.ServiceGet{ID: "special"}, nil
+	})
+	cs.AddReactor("get", "transactions", func(Action) (bool, interface{}, error) {
+		return true, "not a transaction", nil
+	})
+
+	if _, err := cs.Services().Create(ctx, &clientset.CreateServiceRequest{Name: "web", Image: clientset.ImageSpec{Ref: "nginx@1.0"}}); !rest.IsServerError(err) {
+		t.Errorf("Expected the injected error, got %v", err)
+	}
+	if len(cs.Tracker().Services()) != 0 {
+		t.Errorf("Expected the failed create not to reach the tracker")
+	}
+	if svc, err := cs.Services().Get(ctx, "special"); err != nil || svc.ID != "special" {
+		t.Errorf("Expected the reactor's service, got %+v (err %v)", svc, err)
+	}
+	if _, err := cs.Services().Get(ctx, "other"); !rest.IsNotFound(err) {
+		t.Errorf("Expected unhandled calls to reach the tracker, got %v", err)
+	}
+	if _, err := cs.Transactions().Get(ctx, "tx"); err == nil {
+		t.Errorf("Expected an error for a result of the wrong type")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := cs.Services().ListAll(cancelled, clientset.ListServicesOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+
+	actions := cs.Actions()
+	if len(actions) != 5 || !actions[0].Matches("Create", "services") || actions[2].Name != "other" {
+		t.Errorf("Unexpected actions %+v", actions)
+	}
+	cs.ClearActions()
+	if len(cs.Actions()) != 0 {
+		t.Errorf("Expected the actions to be cleared")
+	}
+}
+
+// TestNodes_Delete 测试运行着容器的节点不能被删除。
+func TestNodes_Delete(t *testing.T) {
+	cs := newTestClientset()
+	ctx := context.Background()
+	if _, err := cs.Services().Create(ctx, &clientset.CreateServiceRequest{
+		Name:   "web",
+		Image:  clientset.ImageSpec{Ref: "nginx@1.0"},
+		Node:   clientset.NodeSpec{Names: []string{"node-1"}},
+		Policy: "static",
+	}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	nodes, _ := cs.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+	if nodes[0].ContainerEcsmRunning != 1 || nodes[1].ContainerEcsmTotal != 0 {
+		t.Errorf("Unexpected container counts %+v", nodes)
+	}
+
+	conflicts, err := cs.Nodes().Delete(ctx, []string{nodes[0].ID, nodes[1].ID})
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Name != "node-1" || conflicts[0].Serves[0].Name != "web" {
+		t.Errorf("Unexpected conflicts %+v", conflicts)
+	}
+	if remaining := cs.Tracker().Nodes(); len(remaining) != 1 || remaining[0].Name != "node-1" {
+		t.Errorf("Expected only node-1 to remain, got %+v", remaining)
+	}
+
+	details, err := cs.Nodes().GetByName(ctx, "node-1")
+	if err != nil || details.IP != "10.0.0.1" || details.Port != 3001 {
+		t.Errorf("Unexpected node details %+v (err %v)", details, err)
+	}
+}
+
+// TestImages_Pull 测试拉取的镜像被复制到本地仓库，被服务使用的镜像不能删除。
+func TestImages_Pull(t *testing.T) {
+	cs := newTestClientset()
+	ctx := context.Background()
+	cs.Tracker().AddImage("hub", &clientset.ImageDetails{Name: "nginx", Tag: "1.0", OS: "sylixos"})
+
+	if _, err := cs.Images().GetDetailsByRef(ctx, clientset.LocalRegistryID, "nginx@1.0"); !rest.IsNotFound(err) {
+		t.Fatalf("Expected the image to be missing locally, got %v", err)
+	}
+	if _, err := cs.Images().Pull(ctx, clientset.ImagePullOptions{RegistryID: "hub", Ref: "nginx@1.0#sylixos"}); err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	img, err := cs.Images().GetDetailsByRef(ctx, clientset.LocalRegistryID, "nginx@1.0")
+	if err != nil || !img.Pulled {
+		t.Fatalf("Expected a pulled local image, got %+v (err %v)", img, err)
+	}
+	stats, _ := cs.Images().GetStatistics(ctx)
+	if stats.Local != 1 || stats.Remote != 1 {
+		t.Errorf("Unexpected statistics %+v", stats)
+	}
+
+	if _, err := cs.Services().Create(ctx, &clientset.CreateServiceRequest{Name: "web", Image: clientset.ImageSpec{Ref: "nginx@1.0#sylixos"}}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := cs.Images().Delete(ctx, clientset.LocalRegistryID, img.ID); !rest.IsConflict(err) {
+		t.Errorf("Expected a conflict deleting an image in use, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+// file: pkg/ecsm-client/clientset/fake/container.go
+
+package fake
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+// actionStatuses 是容器在控制动作完成后的状态
+var actionStatuses = map[clientset.ContainerAction]string{
+	clientset.ActionStart:   "running",
+	clientset.ActionStop:    "stopped",
+	clientset.ActionRestart: "running",
+	clientset.ActionPause:   "paused",
+	clientset.ActionUnpause: "running",
+}
+
+type fakeContainers struct {
+	*Clientset
+}
+
+var _ clientset.ContainerInterface = &fakeContainers{}
+
+// GetByTaskID 实现了 ContainerInterface 的同名方法。
+func (c *fakeContainers) GetByTaskID(ctx context.Context, taskId string) (*clientset.ContainerInfo, error) {
+	action := Action{Verb: "GetByTaskID", Resource: "containers", Name: taskId}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ContainerInfo, error) {
+		return c.tracker.getContainer(taskId)
+	})
+}
+
+// GetByName 实现了 ContainerInterface 的同名方法。容器直接在 Tracker 中查找，不使用 serviceClient。
+func (c *fakeContainers) GetByName(ctx context.Context, serviceClient clientset.ServiceInterface, name string) (*clientset.ContainerInfo, error) {
+	action := Action{Verb: "GetByName", Resource: "containers", Name: name}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ContainerInfo, error) {
+		c.tracker.mu.Lock()
+		defer c.tracker.mu.Unlock()
+		for _, co := range c.tracker.containers {
+			if co.Name == name {
+				result := *co
+				return &result, nil
+			}
+		}
+		return nil, notFound("container", name)
+	})
+}
+
+// GetHistory 实现了 ContainerInterface 的同名方法。Tracker 不记录容器中执行过的命令，历史总是为空。
+func (c *fakeContainers) GetHistory(ctx context.Context, opts clientset.ContainerHistoryOptions) (*clientset.ContainerHistoryList, error) {
+	action := Action{Verb: "GetHistory", Resource: "containers", Name: opts.TaskID, Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ContainerHistoryList, error) {
+		if _, err := c.tracker.getContainer(opts.TaskID); err != nil {
+			return nil, err
+		}
+		return &clientset.ContainerHistoryList{PageNum: opts.PageNum, PageSize: opts.PageSize}, nil
+	})
+}
+
+// ListByService 实现了 ContainerInterface 的同名方法。
+func (c *fakeContainers) ListByService(ctx context.Context, opts clientset.ListContainersByServiceOptions) (*clientset.ContainerList, error) {
+	action := Action{Verb: "ListByService", Resource: "containers", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ContainerList, error) {
+		items, err := c.tracker.listContainers(opts.ServiceIDs, opts.Key, func(co *clientset.ContainerInfo) string { return co.ServiceID })
+		if err != nil {
+			return nil, err
+		}
+		return containerList(items, opts.PageNum, opts.PageSize), nil
+	})
+}
+
+// ListAllByService 实现了 ContainerInterface 的同名方法。
+func (c *fakeContainers) ListAllByService(ctx context.Context, opts clientset.ListContainersByServiceOptions) ([]clientset.ContainerInfo, error) {
+	action := Action{Verb: "ListAllByService", Resource: "containers", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.ContainerInfo, error) {
+		return c.tracker.listContainers(opts.ServiceIDs, opts.Key, func(co *clientset.ContainerInfo) string { return co.ServiceID })
+	})
+}
+
+// ListByNode 实现了 ContainerInterface 的同名方法。
+func (c *fakeContainers) ListByNode(ctx context.Context, opts clientset.ListContainersByNodeOptions) (*clientset.ContainerList, error) {
+	action := Action{Verb: "ListByNode", Resource: "containers", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ContainerList, error) {
+		items, err := c.tracker.listContainers(opts.NodeIDs, opts.Key, func(co *clientset.ContainerInfo) string { return co.NodeID })
+		if err != nil {
+			return nil, err
+		}
+		return containerList(items, opts.PageNum, opts.PageSize), nil
+	})
+}
+
+// ListAllByNode 实现了 ContainerInterface 的同名方法。
+func (c *fakeContainers) ListAllByNode(ctx context.Context, opts clientset.ListContainersByNodeOptions) ([]clientset.ContainerInfo, error) {
+	action := Action{Verb: "ListAllByNode", Resource: "containers", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.ContainerInfo, error) {
+		return c.tracker.listContainers(opts.NodeIDs, opts.Key, func(co *clientset.ContainerInfo) string { return co.NodeID })
+	})
+}
+
+// SubmitControlActionByName 实现了 ContainerInterface 的同名方法，动作立即完成，返回的事务已经成功。
+func (c *fakeContainers) SubmitControlActionByName(ctx context.Context, containerName string, ca clientset.ContainerAction) (*clientset.Transaction, error) {
+	action := Action{Verb: "SubmitControlActionByName", Resource: "containers", Name: containerName, Object: ca}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.Transaction, error) {
+		return c.tracker.controlContainers(ca, func(co *clientset.ContainerInfo) bool { return co.Name == containerName },
+			func() error { return notFound("container", containerName) })
+	})
+}
+
+// SubmitControlActionByService 实现了 ContainerInterface 的同名方法，动作立即完成，返回的事务已经成功。
+func (c *fakeContainers) SubmitControlActionByService(ctx context.Context, serviceID string, ca clientset.ContainerAction) (*clientset.Transaction, error) {
+	action := Action{Verb: "SubmitControlActionByService", Resource: "containers", Name: serviceID, Object: ca}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.Transaction, error) {
+		if _, err := c.tracker.getService(serviceID); err != nil {
+			return nil, err
+		}
+		return c.tracker.controlContainers(ca, func(co *clientset.ContainerInfo) bool { return co.ServiceID == serviceID }, nil)
+	})
+}
+
+// SubmitControlActionByLabel 实现了 ContainerInterface 的同名方法，动作立即完成，返回的事务已经成功。
+// 没有服务带有 label 时不执行任何动作。
+func (c *fakeContainers) SubmitControlActionByLabel(ctx context.Context, label string, ca clientset.ContainerAction) (*clientset.Transaction, error) {
+	action := Action{Verb: "SubmitControlActionByLabel", Resource: "containers", Name: label, Object: ca}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.Transaction, error) {
+		var ids []string
+		for _, row := range c.tracker.listServices(clientset.ListServicesOptions{Label: label}) {
+			ids = append(ids, row.ID)
+		}
+		return c.tracker.controlContainers(ca, func(co *clientset.ContainerInfo) bool { return slices.Contains(ids, co.ServiceID) }, nil)
+	})
+}
+
+// Logs 实现了 ContainerInterface 的同名方法。Tracker 不保存容器的输出，日志总是为空。
+func (c *fakeContainers) Logs(ctx context.Context, opts clientset.ContainerLogOptions) (*clientset.ContainerLogList, error) {
+	action := Action{Verb: "Logs", Resource: "containers", Name: opts.TaskID, Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ContainerLogList, error) {
+		if _, err := c.tracker.getContainer(opts.TaskID); err != nil {
+			return nil, err
+		}
+		return &clientset.ContainerLogList{}, nil
+	})
+}
+
+func (t *Tracker) getContainer(taskID string) (*clientset.ContainerInfo, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	co := t.findContainer(taskID)
+	if co == nil {
+		return nil, notFound("container", taskID)
+	}
+	result := *co
+	return &result, nil
+}
+
+// listContainers 返回 owner 在 ids 中、名称包含 key 的容器。与 ECSM API Server 一样，ids 不能为空。
+func (t *Tracker) listContainers(ids []string, key string, owner func(*clientset.ContainerInfo) string) ([]clientset.ContainerInfo, error) {
+	if len(ids) == 0 {
+		return nil, badRequest("at least one id is required to list containers")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := []clientset.ContainerInfo{}
+	for _, co := range t.containers {
+		if slices.Contains(ids, owner(co)) && strings.Contains(co.Name, key) {
+			result = append(result, *co)
+		}
+	}
+	return result, nil
+}
+
+// controlContainers 对 match 选中的容器执行动作 ca，返回已经成功的事务。
+// 没有容器被选中且 missing 不为 nil 时返回 missing 的错误。
+func (t *Tracker) controlContainers(ca clientset.ContainerAction, match func(*clientset.ContainerInfo) bool, missing func() error) (*clientset.Transaction, error) {
+	status, ok := actionStatuses[ca]
+	if !ok {
+		return nil, badRequest("unknown container action %q", ca)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	matched := false
+	for _, co := range t.containers {
+		if !match(co) {
+			continue
+		}
+		matched = true
+		co.Status = status
+		if ca == clientset.ActionRestart {
+			co.RestartCount++
+		}
+	}
+	if !matched && missing != nil {
+		return nil, missing()
+	}
+	return t.newTransaction("container-" + string(ca)), nil
+}
+
+func containerList(items []clientset.ContainerInfo, pageNum, pageSize int) *clientset.ContainerList {
+	return &clientset.ContainerList{
+		Total:    len(items),
+		PageNum:  pageNum,
+		PageSize: pageSize,
+		Items:    paginate(items, pageNum, pageSize),
+	}
+}
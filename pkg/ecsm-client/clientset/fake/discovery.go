@@ -0,0 +1,33 @@
+// file: pkg/ecsm-client/clientset/fake/discovery.go
+
+package fake
+
+import (
+	"context"
+	"slices"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+type fakeDiscovery struct {
+	*Clientset
+}
+
+var _ clientset.DiscoveryInterface = &fakeDiscovery{}
+
+// ServerVersion 实现了 DiscoveryInterface 的同名方法。
+// Tracker.SetServerInfo 设置的版本为空时，与没有版本接口的旧版本服务器一样返回 ErrVersionUnavailable。
+func (c *fakeDiscovery) ServerVersion(ctx context.Context) (*clientset.ServerInfo, error) {
+	action := Action{Verb: "ServerVersion", Resource: "version"}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ServerInfo, error) {
+		c.tracker.mu.Lock()
+		defer c.tracker.mu.Unlock()
+		info := c.tracker.serverInfo
+		if info.Version == "" {
+			return nil, clientset.ErrVersionUnavailable
+		}
+		info.APIVersions = slices.Clone(info.APIVersions)
+		info.Capabilities = slices.Clone(info.Capabilities)
+		return &info, nil
+	})
+}
@@ -0,0 +1,245 @@
+// file: pkg/ecsm-client/clientset/fake/image.go
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+type fakeImages struct {
+	*Clientset
+}
+
+var _ clientset.ImageInterface = &fakeImages{}
+
+// List 实现了 ImageInterface 的同名方法。
+func (c *fakeImages) List(ctx context.Context, opts clientset.ImageListOptions) (*clientset.ImageList, error) {
+	action := Action{Verb: "List", Resource: "images", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ImageList, error) {
+		items := c.tracker.listImages(opts)
+		return &clientset.ImageList{
+			Total:    len(items),
+			PageNum:  opts.PageNum,
+			PageSize: opts.PageSize,
+			Items:    paginate(items, opts.PageNum, opts.PageSize),
+		}, nil
+	})
+}
+
+// ListAll 实现了 ImageInterface 的同名方法。
+func (c *fakeImages) ListAll(ctx context.Context, opts clientset.ImageListOptions) ([]clientset.ImageListItem, error) {
+	action := Action{Verb: "ListAll", Resource: "images", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.ImageListItem, error) {
+		return c.tracker.listImages(opts), nil
+	})
+}
+
+// GetDetails 实现了 ImageInterface 的同名方法。
+func (c *fakeImages) GetDetails(ctx context.Context, registryID, imageID string) (*clientset.ImageDetails, error) {
+	action := Action{Verb: "GetDetails", Resource: "images", Name: imageID, Object: registryID}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ImageDetails, error) {
+		return c.tracker.getImage(registryID, func(img *clientset.ImageDetails) bool { return img.ID == imageID }, imageID)
+	})
+}
+
+// GetDetailsByRef 实现了 ImageInterface 的同名方法，ref 中没有 os 时返回第一个名称和 tag 匹配的镜像。
+func (c *fakeImages) GetDetailsByRef(ctx context.Context, registryID string, ref string) (*clientset.ImageDetails, error) {
+	action := Action{Verb: "GetDetailsByRef", Resource: "images", Name: ref, Object: registryID}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ImageDetails, error) {
+		name, tag, os := parseRef(ref)
+		if name == "" || tag == "" {
+			return nil, fmt.Errorf("invalid image ref: '%s', expected format name@tag[#os]", ref)
+		}
+		return c.tracker.getImage(registryID, refMatcher(name, tag, os), ref)
+	})
+}
+
+// GetConfig 实现了 ImageInterface 的同名方法，在本地仓库中查找 ref 指定的镜像。
+// 镜像没有配置时返回只包含平台信息的配置。
+func (c *fakeImages) GetConfig(ctx context.Context, ref string) (*clientset.EcsImageConfig, error) {
+	action := Action{Verb: "GetConfig", Resource: "images", Name: ref}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.EcsImageConfig, error) {
+		name, tag, os := parseRef(ref)
+		img, err := c.tracker.getImage(clientset.LocalRegistryID, refMatcher(name, tag, os), ref)
+		if err != nil {
+			return nil, err
+		}
+		if img.Config != nil {
+			return img.Config, nil
+		}
+		return &clientset.EcsImageConfig{Platform: &clientset.Platform{OS: img.OS, Arch: img.Arch}}, nil
+	})
+}
+
+// GetStatistics 实现了 ImageInterface 的同名方法，本地仓库以外的镜像都计入 Remote。
+func (c *fakeImages) GetStatistics(ctx context.Context) (*clientset.ImageStatistics, error) {
+	action := Action{Verb: "GetStatistics", Resource: "images"}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ImageStatistics, error) {
+		c.tracker.mu.Lock()
+		defer c.tracker.mu.Unlock()
+		stats := &clientset.ImageStatistics{}
+		for registryID, images := range c.tracker.images {
+			if registryID == clientset.LocalRegistryID {
+				stats.Local += len(images)
+			} else {
+				stats.Remote += len(images)
+			}
+		}
+		return stats, nil
+	})
+}
+
+// GetRepositoryInfo 实现了 ImageInterface 的同名方法，仓库按 ID 排序，仓库的名称就是它的 ID。
+func (c *fakeImages) GetRepositoryInfo(ctx context.Context, opts clientset.RepositoryInfoOptions) ([]clientset.RepositoryInfo, error) {
+	action := Action{Verb: "GetRepositoryInfo", Resource: "images", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.RepositoryInfo, error) {
+		c.tracker.mu.Lock()
+		registryIDs := make([]string, 0, len(c.tracker.images))
+		for registryID := range c.tracker.images {
+			registryIDs = append(registryIDs, registryID)
+		}
+		c.tracker.mu.Unlock()
+		sort.Strings(registryIDs)
+
+		infos := []clientset.RepositoryInfo{}
+		for _, registryID := range registryIDs {
+			images := c.tracker.listImages(clientset.ImageListOptions{RegistryID: registryID, Name: opts.Name, OS: opts.OS, Author: opts.Author})
+			infos = append(infos, clientset.RepositoryInfo{Count: len(images), RegistryID: registryID, RegistryName: registryID})
+		}
+		return infos, nil
+	})
+}
+
+// Push 实现了 ImageInterface 的同名方法。镜像包的内容被读取后丢弃，
+// 镜像的名称是去掉扩展名的文件名，tag 是 "latest"。
+func (c *fakeImages) Push(ctx context.Context, bundle io.Reader, opts clientset.ImagePushOptions) (*clientset.ImagePushResponse, error) {
+	if opts.RegistryID == "" {
+		opts.RegistryID = clientset.LocalRegistryID
+	}
+	if opts.FileName == "" {
+		opts.FileName = "image.tar"
+	}
+	action := Action{Verb: "Push", Resource: "images", Name: opts.FileName, Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ImagePushResponse, error) {
+		size, err := io.Copy(io.Discard, bundle)
+		if err != nil {
+			return nil, err
+		}
+		img := c.tracker.AddImage(opts.RegistryID, &clientset.ImageDetails{
+			Name:        strings.TrimSuffix(opts.FileName, path.Ext(opts.FileName)),
+			Tag:         "latest",
+			Size:        float64(size),
+			CreatedTime: time.Now().Format(timeLayout),
+		})
+		return &clientset.ImagePushResponse{ID: img.ID, Ref: img.Name + "@" + img.Tag}, nil
+	})
+}
+
+// Pull 实现了 ImageInterface 的同名方法，镜像被立即复制到本地仓库，返回的事务已经成功。
+func (c *fakeImages) Pull(ctx context.Context, opts clientset.ImagePullOptions) (*clientset.ImagePullResponse, error) {
+	action := Action{Verb: "Pull", Resource: "images", Name: opts.Ref, Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ImagePullResponse, error) {
+		return c.tracker.pullImage(opts)
+	})
+}
+
+// Delete 实现了 ImageInterface 的同名方法，被服务使用的镜像不能删除。
+func (c *fakeImages) Delete(ctx context.Context, registryID, imageID string) error {
+	action := Action{Verb: "Delete", Resource: "images", Name: imageID, Object: registryID}
+	_, err := invoke(ctx, c.Clientset, action, func() (struct{}, error) {
+		return struct{}{}, c.tracker.deleteImage(registryID, imageID)
+	})
+	return err
+}
+
+// refMatcher 返回匹配名称和 tag 的镜像的函数，os 不为空时还要求操作系统一致。
+func refMatcher(name, tag, os string) func(*clientset.ImageDetails) bool {
+	return func(img *clientset.ImageDetails) bool {
+		return img.Name == name && img.Tag == tag && (os == "" || img.OS == os)
+	}
+}
+
+func (t *Tracker) listImages(opts clientset.ImageListOptions) []clientset.ImageListItem {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	items := []clientset.ImageListItem{}
+	for _, img := range t.images[opts.RegistryID] {
+		if !strings.Contains(img.Name, opts.Name) || (opts.OS != "" && img.OS != opts.OS) {
+			continue
+		}
+		if opts.Author != "" && (img.Author == nil || *img.Author != opts.Author) {
+			continue
+		}
+		items = append(items, clientset.ImageListItem{
+			ID:          img.ID,
+			Name:        img.Name,
+			OS:          img.OS,
+			CreatedTime: img.CreatedTime,
+			Tag:         img.Tag,
+			Size:        img.Size,
+			Author:      img.Author,
+			Arch:        img.Arch,
+			Pulled:      img.Pulled,
+			OCIVersion:  img.OCIVersion,
+		})
+	}
+	return items
+}
+
+func (t *Tracker) getImage(registryID string, match func(*clientset.ImageDetails) bool, key string) (*clientset.ImageDetails, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := slices.IndexFunc(t.images[registryID], match)
+	if i < 0 {
+		return nil, notFound("image", key)
+	}
+	img := *t.images[registryID][i]
+	return &img, nil
+}
+
+func (t *Tracker) pullImage(opts clientset.ImagePullOptions) (*clientset.ImagePullResponse, error) {
+	name, tag, os := parseRef(opts.Ref)
+	img, err := t.getImage(opts.RegistryID, refMatcher(name, tag, os), opts.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// 本地仓库中已经有这个镜像时不重复添加
+	if !slices.ContainsFunc(t.images[clientset.LocalRegistryID], refMatcher(img.Name, img.Tag, img.OS)) {
+		img.ID = t.newID("image")
+		img.Pulled = true
+		t.images[clientset.LocalRegistryID] = append(t.images[clientset.LocalRegistryID], img)
+	}
+	return &clientset.ImagePullResponse{ID: t.newTransaction("image-pull").ID}, nil
+}
+
+func (t *Tracker) deleteImage(registryID, imageID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := slices.IndexFunc(t.images[registryID], func(img *clientset.ImageDetails) bool { return img.ID == imageID })
+	if i < 0 {
+		return notFound("image", imageID)
+	}
+	img := t.images[registryID][i]
+	for _, svc := range t.services {
+		if svc.Image == nil {
+			continue
+		}
+		if name, tag, _ := parseRef(svc.Image.Ref); name == img.Name && tag == img.Tag {
+			return conflict("image %s@%s is used by service %s", img.Name, img.Tag, svc.Name)
+		}
+	}
+	t.images[registryID] = slices.Delete(t.images[registryID], i, i+1)
+	return nil
+}
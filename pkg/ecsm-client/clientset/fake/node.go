@@ -0,0 +1,344 @@
+// file: pkg/ecsm-client/clientset/fake/node.go
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+type fakeNodes struct {
+	*Clientset
+}
+
+var _ clientset.NodeInterface = &fakeNodes{}
+
+// Register 实现了 NodeInterface 的同名方法，名称或地址已经被使用时返回冲突。
+func (c *fakeNodes) Register(ctx context.Context, req *clientset.NodeRegisterRequest) error {
+	action := Action{Verb: "Register", Resource: "nodes", Name: req.Name, Object: req}
+	_, err := invoke(ctx, c.Clientset, action, func() (struct{}, error) {
+		return struct{}{}, c.tracker.registerNode(req)
+	})
+	return err
+}
+
+// ValidateName 实现了 NodeInterface 的同名方法。
+func (c *fakeNodes) ValidateName(ctx context.Context, opts clientset.NodeValidateNameOptions) (*clientset.ValidationResult, error) {
+	action := Action{Verb: "ValidateName", Resource: "nodes", Name: opts.Name, Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ValidationResult, error) {
+		c.tracker.mu.Lock()
+		defer c.tracker.mu.Unlock()
+		if c.tracker.findNode(func(n *clientset.NodeInfo) bool { return n.Name == opts.Name && n.ID != opts.ExcludeID }) != nil {
+			return &clientset.ValidationResult{Message: fmt.Sprintf("node name '%s' already exists", opts.Name)}, nil
+		}
+		return &clientset.ValidationResult{IsValid: true}, nil
+	})
+}
+
+// ValidateAddress 实现了 NodeInterface 的同名方法。
+func (c *fakeNodes) ValidateAddress(ctx context.Context, opts clientset.NodeValidateAddressOptions) (*clientset.ValidationResult, error) {
+	action := Action{Verb: "ValidateAddress", Resource: "nodes", Name: opts.Address, Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ValidationResult, error) {
+		c.tracker.mu.Lock()
+		defer c.tracker.mu.Unlock()
+		if c.tracker.findNode(func(n *clientset.NodeInfo) bool { return n.Address == opts.Address && n.ID != opts.ExcludeID }) != nil {
+			return &clientset.ValidationResult{Message: fmt.Sprintf("node address '%s' already exists", opts.Address)}, nil
+		}
+		return &clientset.ValidationResult{IsValid: true}, nil
+	})
+}
+
+// Update 实现了 NodeInterface 的同名方法。
+func (c *fakeNodes) Update(ctx context.Context, nodeID string, req *clientset.NodeUpdateRequest) error {
+	// 与真实的客户端一样，ID 不一致的请求不会被发出
+	if nodeID != req.ID {
+		return fmt.Errorf("nodeID in path (%s) does not match ID in request body (%s)", nodeID, req.ID)
+	}
+	action := Action{Verb: "Update", Resource: "nodes", Name: nodeID, Object: req}
+	_, err := invoke(ctx, c.Clientset, action, func() (struct{}, error) {
+		return struct{}{}, c.tracker.updateNode(req)
+	})
+	return err
+}
+
+// RefreshNodeTypes 实现了 NodeInterface 的同名方法，节点的类型不会发生变化。
+func (c *fakeNodes) RefreshNodeTypes(ctx context.Context) error {
+	action := Action{Verb: "RefreshNodeTypes", Resource: "nodes"}
+	_, err := invoke(ctx, c.Clientset, action, func() (struct{}, error) {
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// CheckNodeTypeUpdates 实现了 NodeInterface 的同名方法，总是返回空的列表。
+func (c *fakeNodes) CheckNodeTypeUpdates(ctx context.Context) ([]clientset.NodeTypeUpdateInfo, error) {
+	action := Action{Verb: "CheckNodeTypeUpdates", Resource: "nodes"}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.NodeTypeUpdateInfo, error) {
+		return []clientset.NodeTypeUpdateInfo{}, nil
+	})
+}
+
+// List 实现了 NodeInterface 的同名方法，节点上的容器数量根据 Tracker 中的容器计算。
+func (c *fakeNodes) List(ctx context.Context, opts clientset.NodeListOptions) (*clientset.NodeList, error) {
+	action := Action{Verb: "List", Resource: "nodes", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.NodeList, error) {
+		items := c.tracker.listNodes(opts)
+		return &clientset.NodeList{
+			Total:    len(items),
+			PageNum:  opts.PageNum,
+			PageSize: opts.PageSize,
+			Items:    paginate(items, opts.PageNum, opts.PageSize),
+		}, nil
+	})
+}
+
+// ListAll 实现了 NodeInterface 的同名方法。
+func (c *fakeNodes) ListAll(ctx context.Context, opts clientset.NodeListOptions) ([]clientset.NodeInfo, error) {
+	action := Action{Verb: "ListAll", Resource: "nodes", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.NodeInfo, error) {
+		return c.tracker.listNodes(opts), nil
+	})
+}
+
+// GetByID 实现了 NodeInterface 的同名方法。
+func (c *fakeNodes) GetByID(ctx context.Context, nodeID string) (*clientset.NodeDetailsByID, error) {
+	action := Action{Verb: "GetByID", Resource: "nodes", Name: nodeID}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.NodeDetailsByID, error) {
+		n, err := c.tracker.getNode(func(n *clientset.NodeInfo) bool { return n.ID == nodeID }, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return &clientset.NodeDetailsByID{
+			ID:          n.ID,
+			Address:     n.Address,
+			Name:        n.Name,
+			Password:    n.Password,
+			TLS:         n.TLS,
+			Type:        n.Type,
+			CreatedTime: n.CreatedTime,
+			Arch:        n.Arch,
+		}, nil
+	})
+}
+
+// GetByName 实现了 NodeInterface 的同名方法，节点的地址被拆分为 IP 和端口。
+func (c *fakeNodes) GetByName(ctx context.Context, nodeName string) (*clientset.NodeDetailsByName, error) {
+	action := Action{Verb: "GetByName", Resource: "nodes", Name: nodeName}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.NodeDetailsByName, error) {
+		n, err := c.tracker.getNode(func(n *clientset.NodeInfo) bool { return n.Name == nodeName }, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		details := &clientset.NodeDetailsByName{
+			ID:          n.ID,
+			IP:          n.Address,
+			Name:        n.Name,
+			Password:    n.Password,
+			Type:        n.Type,
+			CreatedTime: n.CreatedTime,
+			Arch:        n.Arch,
+		}
+		if host, port, err := net.SplitHostPort(n.Address); err == nil {
+			details.IP = host
+			details.Port, _ = strconv.Atoi(port)
+		}
+		if n.TLS {
+			details.TLS = 1
+		}
+		return details, nil
+	})
+}
+
+// GetNodeView 实现了 NodeInterface 的同名方法，视图中列出节点上的所有容器。
+func (c *fakeNodes) GetNodeView(ctx context.Context, nodeID string) (*clientset.NodeView, error) {
+	action := Action{Verb: "GetNodeView", Resource: "nodes", Name: nodeID}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.NodeView, error) {
+		n, err := c.tracker.getNode(func(n *clientset.NodeInfo) bool { return n.ID == nodeID }, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		view := &clientset.NodeView{ID: n.ID, Status: n.Status, Type: n.Type, Name: n.Name}
+		for _, co := range c.tracker.Containers() {
+			if co.NodeID == nodeID {
+				view.Children = append(view.Children, clientset.NodeViewContainer{
+					ID:        co.ID,
+					Name:      co.Name,
+					NodeID:    co.NodeID,
+					ServiceID: co.ServiceID,
+					Type:      "container",
+					Status:    co.Status,
+				})
+			}
+		}
+		return view, nil
+	})
+}
+
+// GetNodeMetrics 实现了 NodeInterface 的同名方法，只返回一个采样，其中只有容器的数量。
+func (c *fakeNodes) GetNodeMetrics(ctx context.Context, opts clientset.NodeMetricsOptions) ([]clientset.NodeMetrics, error) {
+	action := Action{Verb: "GetNodeMetrics", Resource: "nodes", Name: opts.NodeID, Object: opts}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.NodeMetrics, error) {
+		n, err := c.tracker.getNode(func(n *clientset.NodeInfo) bool { return n.ID == opts.NodeID }, opts.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		return []clientset.NodeMetrics{{
+			Timestamp: time.Now().UnixMilli(),
+			Type:      n.Type,
+			Uptime:    n.UpTime,
+			Running:   n.ContainerEcsmRunning,
+			Stop:      n.ContainerEcsmTotal - n.ContainerEcsmRunning,
+		}}, nil
+	})
+}
+
+// ListStatus 实现了 NodeInterface 的同名方法，不存在的节点被忽略。
+func (c *fakeNodes) ListStatus(ctx context.Context, nodeIDs []string) ([]clientset.NodeStatus, error) {
+	action := Action{Verb: "ListStatus", Resource: "nodes", Object: nodeIDs}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.NodeStatus, error) {
+		statuses := []clientset.NodeStatus{}
+		for _, n := range c.tracker.listNodes(clientset.NodeListOptions{}) {
+			if !slices.Contains(nodeIDs, n.ID) {
+				continue
+			}
+			statuses = append(statuses, clientset.NodeStatus{
+				ID:                   n.ID,
+				Status:               n.Status,
+				Uptime:               n.UpTime,
+				ContainerTotal:       n.ContainerTotal,
+				ContainerRunning:     n.ContainerRunning,
+				ContainerEcsmTotal:   n.ContainerEcsmTotal,
+				ContainerEcsmRunning: n.ContainerEcsmRunning,
+			})
+		}
+		return statuses, nil
+	})
+}
+
+// Delete 实现了 NodeInterface 的同名方法。与 ECSM API Server 一样，运行着服务的容器的节点不会被删除，
+// 而是在返回的冲突列表中列出这些服务。
+func (c *fakeNodes) Delete(ctx context.Context, nodeIDs []string) ([]clientset.NodeDeleteConflict, error) {
+	action := Action{Verb: "Delete", Resource: "nodes", Object: nodeIDs}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.NodeDeleteConflict, error) {
+		return c.tracker.deleteNodes(nodeIDs), nil
+	})
+}
+
+func (t *Tracker) registerNode(req *clientset.NodeRegisterRequest) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if req.Name == "" || req.Address == "" {
+		return badRequest("node name and address are required")
+	}
+	if t.findNode(func(n *clientset.NodeInfo) bool { return n.Name == req.Name || n.Address == req.Address }) != nil {
+		return conflict("node %s (%s) already exists", req.Name, req.Address)
+	}
+	t.nodes = append(t.nodes, &clientset.NodeInfo{
+		ID:          t.newID("node"),
+		Address:     req.Address,
+		Name:        req.Name,
+		Password:    req.Password,
+		Status:      "online",
+		TLS:         req.TLS != nil && *req.TLS,
+		CreatedTime: time.Now().Format(timeLayout),
+	})
+	return nil
+}
+
+func (t *Tracker) updateNode(req *clientset.NodeUpdateRequest) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.findNode(func(n *clientset.NodeInfo) bool { return n.ID == req.ID })
+	if n == nil {
+		return notFound("node", req.ID)
+	}
+	if t.findNode(func(o *clientset.NodeInfo) bool {
+		return o.ID != req.ID && (o.Name == req.Name || o.Address == req.Address)
+	}) != nil {
+		return conflict("node %s (%s) already exists", req.Name, req.Address)
+	}
+	n.Name, n.Address, n.Password, n.TLS = req.Name, req.Address, req.Password, req.TLS
+	// 节点上的容器记录的是节点的名称和地址
+	for _, co := range t.containers {
+		if co.NodeID == n.ID {
+			co.NodeName, co.Address = n.Name, n.Address
+		}
+	}
+	return nil
+}
+
+// getNode 返回第一个满足 match 的节点的副本，其中的容器数量根据 Tracker 中的容器计算。
+func (t *Tracker) getNode(match func(*clientset.NodeInfo) bool, key string) (*clientset.NodeInfo, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.findNode(match)
+	if n == nil {
+		return nil, notFound("node", key)
+	}
+	result := t.nodeWithCounts(n)
+	return &result, nil
+}
+
+func (t *Tracker) listNodes(opts clientset.NodeListOptions) []clientset.NodeInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	items := []clientset.NodeInfo{}
+	for _, n := range t.nodes {
+		if strings.Contains(n.Name, opts.Name) {
+			items = append(items, t.nodeWithCounts(n))
+		}
+	}
+	return items
+}
+
+// nodeWithCounts 返回 n 的副本，其中的容器数量根据 Tracker 中的容器计算，调用方必须持有锁。
+// Tracker 中的容器都是 ECSM 管理的容器。
+func (t *Tracker) nodeWithCounts(n *clientset.NodeInfo) clientset.NodeInfo {
+	result := *n
+	result.ContainerEcsmTotal, result.ContainerEcsmRunning = 0, 0
+	for _, co := range t.containers {
+		if co.NodeID != n.ID {
+			continue
+		}
+		result.ContainerEcsmTotal++
+		if co.Status == "running" {
+			result.ContainerEcsmRunning++
+		}
+	}
+	result.ContainerTotal, result.ContainerRunning = result.ContainerEcsmTotal, result.ContainerEcsmRunning
+	return result
+}
+
+func (t *Tracker) deleteNodes(ids []string) []clientset.NodeDeleteConflict {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	conflicts := []clientset.NodeDeleteConflict{}
+	var deleted []string
+	for _, id := range ids {
+		n := t.findNode(func(n *clientset.NodeInfo) bool { return n.ID == id })
+		if n == nil {
+			continue
+		}
+		var serves []clientset.ConflictingService
+		for _, co := range t.containers {
+			if co.NodeID != id || slices.ContainsFunc(serves, func(s clientset.ConflictingService) bool { return s.ID == co.ServiceID }) {
+				continue
+			}
+			serves = append(serves, clientset.ConflictingService{ID: co.ServiceID, Name: co.ServiceName})
+		}
+		if len(serves) > 0 {
+			conflicts = append(conflicts, clientset.NodeDeleteConflict{ID: n.ID, Name: n.Name, Serves: serves})
+			continue
+		}
+		deleted = append(deleted, id)
+	}
+	t.nodes = slices.DeleteFunc(t.nodes, func(n *clientset.NodeInfo) bool { return slices.Contains(deleted, n.ID) })
+	return conflicts
+}
@@ -0,0 +1,415 @@
+// file: pkg/ecsm-client/clientset/fake/service.go
+
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+// policyStatic 是每个节点恰好运行一个实例的部署策略
+const policyStatic = "static"
+
+type fakeServices struct {
+	*Clientset
+}
+
+var _ clientset.ServiceInterface = &fakeServices{}
+
+// Create 实现了 ServiceInterface 的同名方法，服务的容器立即按副本数启动。
+func (c *fakeServices) Create(ctx context.Context, service *clientset.CreateServiceRequest) (*clientset.ServiceCreateResponse, error) {
+	action := Action{Verb: "Create", Resource: "services", Object: service}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ServiceCreateResponse, error) {
+		return c.tracker.createService(service)
+	})
+}
+
+// Get 实现了 ServiceInterface 的同名方法。
+func (c *fakeServices) Get(ctx context.Context, serviceID string) (*clientset.ServiceGet, error) {
+	action := Action{Verb: "Get", Resource: "services", Name: serviceID}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ServiceGet, error) {
+		return c.tracker.getService(serviceID)
+	})
+}
+
+// List 实现了 ServiceInterface 的同名方法，过滤条件的含义与 ECSM API Server 相同。
+func (c *fakeServices) List(ctx context.Context, opts clientset.ListServicesOptions) (*clientset.ServiceList, error) {
+	action := Action{Verb: "List", Resource: "services", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ServiceList, error) {
+		rows := c.tracker.listServices(opts)
+		return &clientset.ServiceList{
+			Total:    len(rows),
+			PageNum:  opts.PageNum,
+			PageSize: opts.PageSize,
+			Items:    paginate(rows, opts.PageNum, opts.PageSize),
+		}, nil
+	})
+}
+
+// ListAll 实现了 ServiceInterface 的同名方法。
+func (c *fakeServices) ListAll(ctx context.Context, opts clientset.ListServicesOptions) ([]clientset.ProvisionListRow, error) {
+	action := Action{Verb: "ListAll", Resource: "services", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.ProvisionListRow, error) {
+		return c.tracker.listServices(opts), nil
+	})
+}
+
+// Update 实现了 ServiceInterface 的同名方法，服务的容器随之按新的副本数和镜像调整。
+func (c *fakeServices) Update(ctx context.Context, serviceID string, service *clientset.UpdateServiceRequest) (*clientset.ServiceCreateResponse, error) {
+	// 与真实的客户端一样，ID 不一致的请求不会被发出
+	if serviceID != service.ID {
+		return nil, fmt.Errorf("serviceID in path (%s) does not match serviceID in body (%s)", serviceID, service.ID)
+	}
+	action := Action{Verb: "Update", Resource: "services", Name: serviceID, Object: service}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ServiceCreateResponse, error) {
+		return c.tracker.updateService(service)
+	})
+}
+
+// Patch 实现了 ServiceInterface 的同名方法，补丁作用于服务当前配置的 UpdateServiceRequest 形式。
+func (c *fakeServices) Patch(ctx context.Context, serviceID string, pt rest.PatchType, data []byte) (*clientset.ServiceCreateResponse, error) {
+	action := Action{Verb: "Patch", Resource: "services", Name: serviceID, Object: data}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ServiceCreateResponse, error) {
+		return c.tracker.patchService(serviceID, pt, data)
+	})
+}
+
+// Delete 实现了 ServiceInterface 的同名方法，服务和它的容器被立即删除，返回的事务已经成功。
+func (c *fakeServices) Delete(ctx context.Context, serviceID string) (*clientset.ServiceDeleteResponse, error) {
+	action := Action{Verb: "Delete", Resource: "services", Name: serviceID}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ServiceDeleteResponse, error) {
+		return c.tracker.deleteService(serviceID)
+	})
+}
+
+// Redeploy 实现了 ServiceInterface 的同名方法，服务的所有容器被重新部署，返回的事务已经成功。
+func (c *fakeServices) Redeploy(ctx context.Context, serviceID string) (*clientset.ServiceRedeployResponse, error) {
+	action := Action{Verb: "Redeploy", Resource: "services", Name: serviceID}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.ServiceRedeployResponse, error) {
+		return c.tracker.redeployService(serviceID)
+	})
+}
+
+func (t *Tracker) createService(req *clientset.CreateServiceRequest) (*clientset.ServiceCreateResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if req.Name == "" || req.Image.Ref == "" {
+		return nil, badRequest("service name and image ref are required")
+	}
+	if t.serviceNamed(req.Name, "") != nil {
+		return nil, conflict("service name %s already exists", req.Name)
+	}
+
+	now := time.Now().Format(timeLayout)
+	svc := &clientset.ServiceGet{
+		ID:          t.newID("service"),
+		Name:        req.Name,
+		Status:      "running",
+		Healthy:     true,
+		Factor:      1,
+		Policy:      req.Policy,
+		CreatedTime: now,
+		UpdatedTime: now,
+		Image:       copyImageSpec(req.Image),
+		Node:        &clientset.NodeSpec{Names: slices.Clone(req.Node.Names)},
+		Labels:      slices.Clone(req.Labels),
+	}
+	if req.Factor != nil {
+		svc.Factor = *req.Factor
+	}
+	t.services = append(t.services, svc)
+	return t.syncContainers(svc), nil
+}
+
+func (t *Tracker) getService(id string) (*clientset.ServiceGet, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	svc := t.findService(id)
+	if svc == nil {
+		return nil, notFound("service", id)
+	}
+	t.refreshService(svc)
+	return copyService(svc), nil
+}
+
+func (t *Tracker) listServices(opts clientset.ListServicesOptions) []clientset.ProvisionListRow {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rows := []clientset.ProvisionListRow{}
+	for _, svc := range t.services {
+		if t.serviceMatches(svc, opts) {
+			rows = append(rows, t.serviceRow(svc))
+		}
+	}
+	return rows
+}
+
+func (t *Tracker) updateService(req *clientset.UpdateServiceRequest) (*clientset.ServiceCreateResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	svc := t.findService(req.ID)
+	if svc == nil {
+		return nil, notFound("service", req.ID)
+	}
+	if req.Name == "" || req.Image.Ref == "" {
+		return nil, badRequest("service name and image ref are required")
+	}
+	if t.serviceNamed(req.Name, req.ID) != nil {
+		return nil, conflict("service name %s already exists", req.Name)
+	}
+
+	svc.Name = req.Name
+	svc.Image = copyImageSpec(req.Image)
+	svc.Node = &clientset.NodeSpec{Names: slices.Clone(req.Node.Names)}
+	svc.Policy = req.Policy
+	svc.Labels = slices.Clone(req.Labels)
+	// 没有指定 factor 时保持当前的副本数
+	if req.Factor != nil {
+		svc.Factor = *req.Factor
+	}
+	svc.UpdatedTime = time.Now().Format(timeLayout)
+	return t.syncContainers(svc), nil
+}
+
+func (t *Tracker) patchService(id string, pt rest.PatchType, patch []byte) (*clientset.ServiceCreateResponse, error) {
+	current, err := t.getService(id)
+	if err != nil {
+		return nil, err
+	}
+	original, err := json.Marshal(current.UpdateRequest())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode service %s: %w", id, err)
+	}
+
+	var patched []byte
+	switch pt {
+	case rest.MergePatchType:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case rest.JSONPatchType:
+		var ops jsonpatch.Patch
+		if ops, err = jsonpatch.DecodePatch(patch); err == nil {
+			patched, err = ops.Apply(original)
+		}
+	default:
+		return nil, badRequest("unsupported patch type %q", pt)
+	}
+	if err != nil {
+		return nil, badRequest("failed to apply patch to service %s: %v", id, err)
+	}
+
+	req := &clientset.UpdateServiceRequest{}
+	if err := json.Unmarshal(patched, req); err != nil {
+		return nil, badRequest("patched service %s is invalid: %v", id, err)
+	}
+	if req.ID != id {
+		return nil, badRequest("patch must not change the service id (%s)", id)
+	}
+	return t.updateService(req)
+}
+
+func (t *Tracker) deleteService(id string) (*clientset.ServiceDeleteResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.findService(id) == nil {
+		return nil, notFound("service", id)
+	}
+	t.services = slices.DeleteFunc(t.services, func(svc *clientset.ServiceGet) bool { return svc.ID == id })
+	t.containers = slices.DeleteFunc(t.containers, func(c *clientset.ContainerInfo) bool { return c.ServiceID == id })
+	return &clientset.ServiceDeleteResponse{ID: t.newTransaction("delete").ID}, nil
+}
+
+func (t *Tracker) redeployService(id string) (*clientset.ServiceRedeployResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	svc := t.findService(id)
+	if svc == nil {
+		return nil, notFound("service", id)
+	}
+	for _, c := range t.containers {
+		if c.ServiceID == id {
+			c.Status = "running"
+			c.DeployNum++
+		}
+	}
+	t.refreshService(svc)
+	return &clientset.ServiceRedeployResponse{ID: t.newTransaction("redeploy").ID}, nil
+}
+
+// serviceNamed 返回除 exceptID 以外名为 name 的服务，调用方必须持有锁。
+func (t *Tracker) serviceNamed(name, exceptID string) *clientset.ServiceGet {
+	for _, svc := range t.services {
+		if svc.Name == name && svc.ID != exceptID {
+			return svc
+		}
+	}
+	return nil
+}
+
+// serviceMatches 判断服务是否满足 opts 中的过滤条件，调用方必须持有锁。
+// 与 ECSM API Server 一样，name 是模糊匹配；label 为 key=value 时精确匹配一个标签，否则匹配标签的 key。
+func (t *Tracker) serviceMatches(svc *clientset.ServiceGet, opts clientset.ListServicesOptions) bool {
+	if opts.Name != "" && !strings.Contains(svc.Name, opts.Name) {
+		return false
+	}
+	if opts.Label != "" && !slices.ContainsFunc(svc.Labels, func(l string) bool {
+		key, _, _ := strings.Cut(l, "=")
+		return l == opts.Label || key == opts.Label
+	}) {
+		return false
+	}
+	if opts.NodeID != "" && !slices.ContainsFunc(t.containers, func(c *clientset.ContainerInfo) bool {
+		return c.ServiceID == svc.ID && c.NodeID == opts.NodeID
+	}) {
+		return false
+	}
+	if opts.ImageID != "" {
+		if svc.Image == nil {
+			return false
+		}
+		name, tag, _ := parseRef(svc.Image.Ref)
+		found := false
+		for _, images := range t.images {
+			found = found || slices.ContainsFunc(images, func(img *clientset.ImageDetails) bool {
+				return img.ID == opts.ImageID && img.Name == name && img.Tag == tag
+			})
+		}
+		return found
+	}
+	return true
+}
+
+// serviceRow 返回服务在列表接口中的形式，调用方必须持有锁。
+func (t *Tracker) serviceRow(svc *clientset.ServiceGet) clientset.ProvisionListRow {
+	t.refreshService(svc)
+	svc = copyService(svc)
+	row := clientset.ProvisionListRow{
+		ID:                   svc.ID,
+		Name:                 svc.Name,
+		Status:               svc.Status,
+		UpdatedTime:          svc.UpdatedTime,
+		CreatedTime:          svc.CreatedTime,
+		NodeList:             svc.NodeList,
+		ContainerStatusGroup: svc.ContainerStatusGroup,
+		Factor:               svc.Factor,
+		Policy:               svc.Policy,
+		InstanceOnline:       svc.InstanceOnline,
+		Labels:               svc.Labels,
+	}
+	if svc.Image != nil {
+		name, tag, os := parseRef(svc.Image.Ref)
+		row.ImageList = []clientset.ImageListEntry{{Name: name, OS: os, Tag: tag}}
+	}
+	return row
+}
+
+// syncContainers 按照服务的副本数、节点和镜像启动或删除它的容器，调用方必须持有锁。
+// Static 策略的服务在每个节点上恰好运行一个容器，副本数是节点的数量；
+// 其它服务运行 factor 个容器，依次放置在节点列表中的节点上，节点列表为空时放置在所有已注册的节点上。
+// 镜像与服务不一致的容器被删除后重新创建。
+func (t *Tracker) syncContainers(svc *clientset.ServiceGet) *clientset.ServiceCreateResponse {
+	name, tag, os := parseRef(svc.Image.Ref)
+	var names []string
+	if svc.Node != nil {
+		names = svc.Node.Names
+	}
+	if svc.Policy == policyStatic {
+		svc.Factor = len(names)
+	}
+	if len(names) == 0 {
+		for _, n := range t.nodes {
+			names = append(names, n.Name)
+		}
+	}
+
+	// 保留镜像一致的容器，Static 策略下还要求容器所在的节点仍在节点列表中且没有被其它容器占用
+	var kept []*clientset.ContainerInfo
+	free := slices.Clone(names)
+	t.containers = slices.DeleteFunc(t.containers, func(c *clientset.ContainerInfo) bool {
+		if c.ServiceID != svc.ID {
+			return false
+		}
+		keep := c.ImageName == name && c.ImageVersion == tag && len(kept) < svc.Factor
+		if keep && svc.Policy == policyStatic {
+			i := slices.Index(free, c.NodeName)
+			if keep = i >= 0; keep {
+				free = slices.Delete(free, i, i+1)
+			}
+		}
+		if keep {
+			kept = append(kept, c)
+		}
+		return !keep
+	})
+
+	for i := len(kept); i < svc.Factor; i++ {
+		var nodeName string
+		switch {
+		case svc.Policy == policyStatic:
+			nodeName, free = free[0], free[1:]
+		case len(names) > 0:
+			nodeName = names[i%len(names)]
+		}
+		c := &clientset.ContainerInfo{
+			ID:           t.newID("container"),
+			TaskID:       t.newID("task"),
+			Status:       "running",
+			DeployStatus: "success",
+			CreatedTime:  time.Now().Format(timeLayout),
+			DeployNum:    1,
+			ServiceID:    svc.ID,
+			ServiceName:  svc.Name,
+			NodeName:     nodeName,
+			ImageName:    name,
+			ImageVersion: tag,
+			ImageOS:      os,
+		}
+		c.Name = fmt.Sprintf("%s-%s", svc.Name, strings.TrimPrefix(c.TaskID, "task-"))
+		c.StartedTime = c.CreatedTime
+		c.TaskCreatedTime = c.CreatedTime
+		if n := t.findNode(func(n *clientset.NodeInfo) bool { return n.Name == nodeName }); n != nil {
+			c.NodeID = n.ID
+			c.Address = n.Address
+			c.NodeArch = n.Arch
+		}
+		t.containers = append(t.containers, c)
+		kept = append(kept, c)
+	}
+
+	t.refreshService(svc)
+	resp := &clientset.ServiceCreateResponse{ID: svc.ID}
+	for _, c := range kept {
+		resp.Containers = append(resp.Containers, c.TaskID)
+	}
+	return resp
+}
+
+// refreshService 根据服务当前的容器更新它的实例统计和节点列表，调用方必须持有锁。
+func (t *Tracker) refreshService(svc *clientset.ServiceGet) {
+	svc.ContainerStatusGroup = nil
+	svc.NodeList = nil
+	svc.InstanceOnline = 0
+	svc.InstanceActive = 0
+	for _, c := range t.containers {
+		if c.ServiceID != svc.ID {
+			continue
+		}
+		c.ServiceName = svc.Name
+		svc.ContainerStatusGroup = append(svc.ContainerStatusGroup, c.Status)
+		if c.Status == "running" {
+			svc.InstanceOnline++
+			svc.InstanceActive++
+		}
+		if !slices.ContainsFunc(svc.NodeList, func(n clientset.ServiceNodeInfo) bool { return n.NodeName == c.NodeName }) {
+			svc.NodeList = append(svc.NodeList, clientset.ServiceNodeInfo{NodeID: c.NodeID, NodeName: c.NodeName, Address: c.Address})
+		}
+	}
+	svc.Healthy = svc.InstanceOnline == len(svc.ContainerStatusGroup)
+}
@@ -0,0 +1,289 @@
+// file: pkg/ecsm-client/clientset/fake/tracker.go
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+)
+
+// timeLayout 是 ECSM API 返回的时间的格式
+const timeLayout = "2006-01-02 15:04:05"
+
+// Tracker 在内存中保存模拟的 ECSM 平台上的服务、容器、节点、镜像和事务，Clientset 的所有客户端共享它。
+// 它模拟了平台的主要行为：创建和修改服务时按照副本数启动或停止容器，删除服务时删除它的容器，
+// 删除、重新部署、拉取镜像和容器控制操作立即完成并返回成功的事务。
+// 对象按照添加的顺序列出，使测试的结果是确定的。所有方法都可以并发调用。
+type Tracker struct {
+	mu sync.Mutex
+	// nextID 是生成对象 ID 使用的序号，每个 Tracker 从 1 开始
+	nextID       int
+	services     []*clientset.ServiceGet
+	containers   []*clientset.ContainerInfo
+	nodes        []*clientset.NodeInfo
+	images       map[string][]*clientset.ImageDetails
+	transactions []*clientset.Transaction
+	serverInfo   clientset.ServerInfo
+}
+
+// NewTracker 返回空的 Tracker，服务器的版本是 clientset.MinServerVersion。
+func NewTracker() *Tracker {
+	return &Tracker{
+		images: make(map[string][]*clientset.ImageDetails),
+		serverInfo: clientset.ServerInfo{
+			Version:     clientset.MinServerVersion,
+			APIVersions: clientset.SupportedAPIVersions,
+		},
+	}
+}
+
+// AddService 添加一个已经存在的服务，ID 为空时生成一个。它不启动容器，需要时用 AddContainer 添加，
+// 服务的实例统计和节点列表总是根据它的容器计算。
+func (t *Tracker) AddService(svc *clientset.ServiceGet) *clientset.ServiceGet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	svc = copyService(svc)
+	if svc.ID == "" {
+		svc.ID = t.newID("service")
+	}
+	t.services = append(t.services, svc)
+	return copyService(svc)
+}
+
+// AddContainer 添加一个容器，ID 和 TaskID 为空时生成。ServiceID 指定的服务存在时补全 ServiceName。
+func (t *Tracker) AddContainer(co *clientset.ContainerInfo) *clientset.ContainerInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := *co
+	if c.ID == "" {
+		c.ID = t.newID("container")
+	}
+	if c.TaskID == "" {
+		c.TaskID = t.newID("task")
+	}
+	if svc := t.findService(c.ServiceID); svc != nil && c.ServiceName == "" {
+		c.ServiceName = svc.Name
+	}
+	t.containers = append(t.containers, &c)
+	return &c
+}
+
+// AddNode 添加一个已经注册的节点，ID 为空时生成，Status 为空时为 "online"。
+func (t *Tracker) AddNode(node *clientset.NodeInfo) *clientset.NodeInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := *node
+	if n.ID == "" {
+		n.ID = t.newID("node")
+	}
+	if n.Status == "" {
+		n.Status = "online"
+	}
+	t.nodes = append(t.nodes, &n)
+	return &n
+}
+
+// AddImage 在仓库 registryID 中添加一个镜像，ID 为空时生成。
+func (t *Tracker) AddImage(registryID string, image *clientset.ImageDetails) *clientset.ImageDetails {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	img := *image
+	if img.ID == "" {
+		img.ID = t.newID("image")
+	}
+	t.images[registryID] = append(t.images[registryID], &img)
+	return &img
+}
+
+// AddTransaction 添加一个事务，例如状态为 running 的事务，用于测试等待事务的逻辑。
+func (t *Tracker) AddTransaction(tx *clientset.Transaction) *clientset.Transaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := *tx
+	if c.ID == "" {
+		c.ID = t.newID("transaction")
+	}
+	t.transactions = append(t.transactions, &c)
+	return &c
+}
+
+// SetTransactionStatus 修改事务 id 的状态，例如让 running 的事务完成。事务不存在时返回 false。
+func (t *Tracker) SetTransactionStatus(id, status string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tx := range t.transactions {
+		if tx.ID == id {
+			tx.Status = status
+			return true
+		}
+	}
+	return false
+}
+
+// SetContainerStatus 修改容器 taskID 的状态，例如模拟容器退出。容器不存在时返回 false。
+func (t *Tracker) SetContainerStatus(taskID, status string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c := t.findContainer(taskID); c != nil {
+		c.Status = status
+		return true
+	}
+	return false
+}
+
+// SetServerInfo 设置 Discovery().ServerVersion 返回的服务器信息。
+func (t *Tracker) SetServerInfo(info clientset.ServerInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.serverInfo = info
+}
+
+// Services 返回所有服务的副本，按添加的顺序排列。
+func (t *Tracker) Services() []clientset.ServiceGet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]clientset.ServiceGet, 0, len(t.services))
+	for _, svc := range t.services {
+		result = append(result, *copyService(svc))
+	}
+	return result
+}
+
+// Containers 返回所有容器的副本，按添加的顺序排列。
+func (t *Tracker) Containers() []clientset.ContainerInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]clientset.ContainerInfo, 0, len(t.containers))
+	for _, c := range t.containers {
+		result = append(result, *c)
+	}
+	return result
+}
+
+// Nodes 返回所有节点的副本，按添加的顺序排列。
+func (t *Tracker) Nodes() []clientset.NodeInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]clientset.NodeInfo, 0, len(t.nodes))
+	for _, n := range t.nodes {
+		result = append(result, *n)
+	}
+	return result
+}
+
+// newID 返回以 prefix 开头的新 ID，调用方必须持有锁。
+func (t *Tracker) newID(prefix string) string {
+	t.nextID++
+	return fmt.Sprintf("%s-%d", prefix, t.nextID)
+}
+
+// newTransaction 记录一个已经成功的事务并返回它的副本，调用方必须持有锁。
+func (t *Tracker) newTransaction(txType string) *clientset.Transaction {
+	tx := &clientset.Transaction{
+		ID:        t.newID("transaction"),
+		Status:    clientset.TransactionStatusSuccess,
+		Type:      txType,
+		Timestamp: time.Now().UnixMilli(),
+	}
+	t.transactions = append(t.transactions, tx)
+	c := *tx
+	return &c
+}
+
+func (t *Tracker) findService(id string) *clientset.ServiceGet {
+	for _, svc := range t.services {
+		if svc.ID == id {
+			return svc
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) findContainer(taskID string) *clientset.ContainerInfo {
+	for _, c := range t.containers {
+		if c.TaskID == taskID {
+			return c
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) findNode(match func(*clientset.NodeInfo) bool) *clientset.NodeInfo {
+	for _, n := range t.nodes {
+		if match(n) {
+			return n
+		}
+	}
+	return nil
+}
+
+// copyService 返回 svc 的深拷贝，Tracker 保存和返回的服务不与调用方共享切片和指针。
+func copyService(svc *clientset.ServiceGet) *clientset.ServiceGet {
+	c := *svc
+	c.ContainerStatusGroup = slices.Clone(svc.ContainerStatusGroup)
+	c.NodeList = slices.Clone(svc.NodeList)
+	c.Labels = slices.Clone(svc.Labels)
+	if svc.Image != nil {
+		c.Image = copyImageSpec(*svc.Image)
+	}
+	if svc.Node != nil {
+		c.Node = &clientset.NodeSpec{Names: slices.Clone(svc.Node.Names)}
+	}
+	return &c
+}
+
+// copyImageSpec 深度复制镜像规格，使调用者修改返回的 Config 和 VSOA 时不影响 Tracker，
+// 就像真实的客户端每次返回新解码的对象一样。
+func copyImageSpec(image clientset.ImageSpec) *clientset.ImageSpec {
+	data, err := json.Marshal(image)
+	if err != nil {
+		panic(err)
+	}
+	result := &clientset.ImageSpec{}
+	if err := json.Unmarshal(data, result); err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// parseRef 把 name@tag#os 形式的镜像引用拆分为名称、tag 和操作系统。
+func parseRef(ref string) (name, tag, os string) {
+	ref, os, _ = strings.Cut(ref, "#")
+	name, tag, _ = strings.Cut(ref, "@")
+	return name, tag, os
+}
+
+// paginate 返回第 pageNum 页的元素，pageNum 和 pageSize 小于 1 时返回全部元素。
+func paginate[T any](items []T, pageNum, pageSize int) []T {
+	if pageNum < 1 || pageSize < 1 {
+		return items
+	}
+	start := (pageNum - 1) * pageSize
+	if start >= len(items) {
+		return nil
+	}
+	return items[start:min(start+pageSize, len(items))]
+}
+
+// notFound 返回与 ECSM API Server 的 404 响应相同的错误，可以用 rest.IsNotFound 判断。
+func notFound(kind, id string) error {
+	return &rest.Aerror{Status: http.StatusNotFound, Message: fmt.Sprintf("%s %s not found", kind, id)}
+}
+
+// conflict 返回与 ECSM API Server 的 409 响应相同的错误，可以用 rest.IsConflict 判断。
+func conflict(format string, args ...interface{}) error {
+	return &rest.Aerror{Status: http.StatusConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// badRequest 返回与 ECSM API Server 的 400 响应相同的错误，可以用 rest.IsBadRequest 判断。
+func badRequest(format string, args ...interface{}) error {
+	return &rest.Aerror{Status: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
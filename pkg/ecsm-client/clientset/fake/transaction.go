@@ -0,0 +1,67 @@
+// file: pkg/ecsm-client/clientset/fake/transaction.go
+
+package fake
+
+import (
+	"context"
+	"slices"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+type fakeTransactions struct {
+	*Clientset
+}
+
+var _ clientset.TransactionInterface = &fakeTransactions{}
+
+// Get 实现了 TransactionInterface 的同名方法。
+func (c *fakeTransactions) Get(ctx context.Context, transactionID string) (*clientset.Transaction, error) {
+	action := Action{Verb: "Get", Resource: "transactions", Name: transactionID}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.Transaction, error) {
+		c.tracker.mu.Lock()
+		defer c.tracker.mu.Unlock()
+		for _, tx := range c.tracker.transactions {
+			if tx.ID == transactionID {
+				result := *tx
+				return &result, nil
+			}
+		}
+		return nil, notFound("transaction", transactionID)
+	})
+}
+
+// List 实现了 TransactionInterface 的同名方法。
+func (c *fakeTransactions) List(ctx context.Context, opts clientset.TransactionListOptions) (*clientset.TransactionList, error) {
+	action := Action{Verb: "List", Resource: "transactions", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() (*clientset.TransactionList, error) {
+		items := c.tracker.listTransactions(opts)
+		return &clientset.TransactionList{
+			Total:    len(items),
+			PageNum:  opts.PageNum,
+			PageSize: opts.PageSize,
+			Items:    paginate(items, opts.PageNum, opts.PageSize),
+		}, nil
+	})
+}
+
+// ListAll 实现了 TransactionInterface 的同名方法。
+func (c *fakeTransactions) ListAll(ctx context.Context, opts clientset.TransactionListOptions) ([]clientset.Transaction, error) {
+	action := Action{Verb: "ListAll", Resource: "transactions", Object: opts}
+	return invoke(ctx, c.Clientset, action, func() ([]clientset.Transaction, error) {
+		return c.tracker.listTransactions(opts), nil
+	})
+}
+
+// listTransactions 返回满足 opts 中过滤条件的事务，与 ECSM API Server 一样，最近提交的事务在前。
+func (t *Tracker) listTransactions(opts clientset.TransactionListOptions) []clientset.Transaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	items := []clientset.Transaction{}
+	for _, tx := range slices.Backward(t.transactions) {
+		if (opts.Status == "" || tx.Status == opts.Status) && (opts.Type == "" || tx.Type == opts.Type) {
+			items = append(items, *tx)
+		}
+	}
+	return items
+}
@@ -3,6 +3,8 @@ package clientset
 import (
 	"context"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"strconv"
 	"strings"
 
@@ -36,6 +38,16 @@ type ImageInterface interface {
 	// GetRepositoryInfo 获取所有镜像仓库的信息和统计数据。
 	// 支持通过 Options 进行过滤。
 	GetRepositoryInfo(ctx context.Context, opts RepositoryInfoOptions) ([]RepositoryInfo, error)
+
+	// Push 把一个 OCI 镜像包 (tar 文件) 上传到 opts.RegistryID 指定的仓库。
+	// bundle 以流的方式上传，调用方可以包装它来报告上传进度。
+	Push(ctx context.Context, bundle io.Reader, opts ImagePushOptions) (*ImagePushResponse, error)
+
+	// Pull 把远程仓库中的镜像拉取到本地仓库。拉取是异步的，返回的事务 ID 可以用于查询拉取结果。
+	Pull(ctx context.Context, opts ImagePullOptions) (*ImagePullResponse, error)
+
+	// Delete 从仓库中删除一个镜像。
+	Delete(ctx context.Context, registryID, imageID string) error
 }
 
 type imageClient struct {
@@ -245,6 +257,71 @@ func (c *imageClient) GetRepositoryInfo(ctx context.Context, opts RepositoryInfo
 	return result, nil
 }
 
+// Push 实现了 ImageInterface 的同名方法。
+// 镜像包作为 multipart/form-data 的 "file" 字段上传，通过管道边读边发送，不会整个读入内存。
+func (c *imageClient) Push(ctx context.Context, bundle io.Reader, opts ImagePushOptions) (*ImagePushResponse, error) {
+	if opts.RegistryID == "" {
+		opts.RegistryID = LocalRegistryID
+	}
+	if opts.FileName == "" {
+		opts.FileName = "image.tar"
+	}
+
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	go func() {
+		err := func() error {
+			if err := form.WriteField("registryId", opts.RegistryID); err != nil {
+				return err
+			}
+			part, err := form.CreateFormFile("file", opts.FileName)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(part, bundle); err != nil {
+				return err
+			}
+			return form.Close()
+		}()
+		// 出错时让请求的读取端得到同样的错误，请求随之失败
+		pw.CloseWithError(err)
+	}()
+	// 请求提前失败时 (例如连接被拒绝) 结束上面的 goroutine
+	defer pr.Close()
+
+	result := &ImagePushResponse{}
+	err := c.restClient.Post().
+		Resource("image/upload").
+		BodyReader(pr, form.FormDataContentType()).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+// Pull 实现了 ImageInterface 的同名方法。
+func (c *imageClient) Pull(ctx context.Context, opts ImagePullOptions) (*ImagePullResponse, error) {
+	result := &ImagePullResponse{}
+
+	err := c.restClient.Post().
+		Resource("image/pull").
+		Body(&opts).
+		Do(ctx).
+		Into(result)
+
+	return result, err
+}
+
+// Delete 实现了 ImageInterface 的同名方法。
+func (c *imageClient) Delete(ctx context.Context, registryID, imageID string) error {
+	return c.restClient.Delete().
+		Resource("registry").
+		Name(registryID).
+		Subresource("image").
+		Name(imageID).
+		Do(ctx).
+		Into(nil)
+}
+
 func (i *ImageListItem) Ref() string {
 	return fmt.Sprintf("%s@%s#%s", i.Name, i.Tag, i.OS)
 }
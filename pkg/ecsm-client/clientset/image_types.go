@@ -88,6 +88,9 @@ type Network struct {
 	TelnetdEnable bool `json:"telnetdEnable"`
 }
 
+// LocalRegistryID 是本地镜像仓库的 ID
+const LocalRegistryID = "local"
+
 // ImageListOptions 封装了所有可以用于 List 镜像的查询参数。
 type ImageListOptions struct {
 	// RegistryID 是要查询的仓库主键，本地仓库为 "local"。
@@ -163,3 +166,30 @@ type RepositoryInfo struct {
 	Status   *bool `json:"status,omitempty"`
 	Standard *bool `json:"standard,omitempty"`
 }
+
+// ImagePushOptions 封装了上传镜像包时的参数。
+type ImagePushOptions struct {
+	// RegistryID 是镜像上传到的仓库，默认为本地仓库。
+	RegistryID string
+	// FileName 是上传的文件名，平台用它识别镜像包的格式，默认为 "image.tar"。
+	FileName string
+}
+
+// ImagePushResponse 是上传镜像包的 API 响应。
+type ImagePushResponse struct {
+	ID  string `json:"id"`
+	Ref string `json:"ref"`
+}
+
+// ImagePullOptions 是从远程仓库拉取镜像的 API payload。
+type ImagePullOptions struct {
+	// RegistryID 是镜像所在的远程仓库。
+	RegistryID string `json:"registryId"`
+	// Ref 是 name@tag#os 形式的镜像引用。
+	Ref string `json:"ref"`
+}
+
+// ImagePullResponse 是拉取镜像的 API 响应。
+type ImagePullResponse struct {
+	ID string `json:"transactionId"`
+}
@@ -0,0 +1,43 @@
+package clientset
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+// patchUnsupported 判断 PATCH 请求失败的状态码是否表示服务器不支持 PATCH。
+// 不支持的服务器对未知的方法可能返回 404 (没有匹配的路由)、405 或 501。
+func patchUnsupported(status int) bool {
+	switch status {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// applyPatch 在客户端把类型为 pt 的补丁 patch 应用到 JSON 文档 original 上，返回修改后的文档。
+func applyPatch(original []byte, pt rest.PatchType, patch []byte) ([]byte, error) {
+	switch pt {
+	case rest.MergePatchType:
+		patched, err := jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply merge patch: %w", err)
+		}
+		return patched, nil
+	case rest.JSONPatchType:
+		ops, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON patch: %w", err)
+		}
+		patched, err := ops.Apply(original)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply JSON patch: %w", err)
+		}
+		return patched, nil
+	default:
+		return nil, fmt.Errorf("unsupported patch type %q", pt)
+	}
+}
@@ -2,6 +2,8 @@ package clientset
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -30,6 +32,11 @@ type ServiceInterface interface {
 	// Update 修改一个已存在的服务。
 	Update(ctx context.Context, serviceID string, service *UpdateServiceRequest) (*ServiceCreateResponse, error)
 
+	// Patch 按照补丁 data 修改服务的部分字段，pt 指定补丁的格式。补丁作用于 UpdateServiceRequest 的 JSON 形式，
+	// 例如合并补丁 {"factor":3} 只修改副本数。ECSM API Server 不支持 PATCH 时，
+	// 在客户端读取服务的当前配置，应用补丁后以 Update 提交。
+	Patch(ctx context.Context, serviceID string, pt rest.PatchType, data []byte) (*ServiceCreateResponse, error)
+
 	// Delete 根据服务 ID 删除一个服务。
 	Delete(ctx context.Context, serviceID string) (*ServiceDeleteResponse, error)
 
@@ -41,8 +48,8 @@ type ServiceInterface interface {
 
 	// // --- 特殊操作 (Actions) ---
 
-	// // Redeploy 触发一次服务的重新部署。
-	// Redeploy(ctx context.Context, serviceID string) error
+	// Redeploy 触发一次服务的重新部署，平台会按服务当前的配置重新创建所有容器。
+	Redeploy(ctx context.Context, serviceID string) (*ServiceRedeployResponse, error)
 
 	// // ValidateName 校验服务名称是否合法或可用。
 	// ValidateName(ctx context.Context, name string) (*ValidationResult, error)
@@ -93,6 +100,50 @@ func (c *serviceClient) Update(ctx context.Context, serviceID string, service *U
 	return result, err
 }
 
+// Patch 实现了 ServiceInterface 的 Patch 方法。
+func (c *serviceClient) Patch(ctx context.Context, serviceID string, pt rest.PatchType, data []byte) (*ServiceCreateResponse, error) {
+	result := &ServiceCreateResponse{}
+
+	err := c.restClient.Patch(pt).
+		Resource("service").
+		Name(serviceID).
+		Body(data).
+		Do(ctx).
+		Into(result)
+
+	var apiErr *rest.Aerror
+	if err == nil || !errors.As(err, &apiErr) || !patchUnsupported(apiErr.Status) {
+		return result, err
+	}
+	return c.patchByUpdate(ctx, serviceID, pt, data)
+}
+
+// patchByUpdate 读取服务的当前配置，在客户端应用补丁，再以 Update 提交完整的配置。
+// 它与服务端的 PATCH 不同，不是原子的：读取和提交之间的其它修改会被覆盖。
+func (c *serviceClient) patchByUpdate(ctx context.Context, serviceID string, pt rest.PatchType, data []byte) (*ServiceCreateResponse, error) {
+	current, err := c.Get(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	original, err := json.Marshal(current.UpdateRequest())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode service %s: %w", serviceID, err)
+	}
+	patched, err := applyPatch(original, pt, data)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &UpdateServiceRequest{}
+	if err := json.Unmarshal(patched, req); err != nil {
+		return nil, fmt.Errorf("patched service %s is invalid: %w", serviceID, err)
+	}
+	if req.ID != serviceID {
+		return nil, fmt.Errorf("patch must not change the service id (%s)", serviceID)
+	}
+	return c.Update(ctx, serviceID, req)
+}
+
 func (c *serviceClient) Delete(ctx context.Context, serviceID string) (*ServiceDeleteResponse, error) {
 	result := &ServiceDeleteResponse{}
 
@@ -106,6 +157,19 @@ func (c *serviceClient) Delete(ctx context.Context, serviceID string) (*ServiceD
 	return result, err
 }
 
+// Redeploy 实现了 ServiceInterface 的同名方法。
+func (c *serviceClient) Redeploy(ctx context.Context, serviceID string) (*ServiceRedeployResponse, error) {
+	result := &ServiceRedeployResponse{}
+
+	err := c.restClient.Put().
+		Resource("service/redeploy").
+		Body(&ServiceRedeployRequest{ID: serviceID}).
+		Do(ctx).
+		Into(result)
+
+	return result, err
+}
+
 func (c *serviceClient) Get(ctx context.Context, serviceID string) (*ServiceGet, error) {
 	result := &ServiceGet{}
 
@@ -12,6 +12,8 @@ type CreateServiceRequest struct {
 	Factor  *int      `json:"factor,omitempty"`
 	Policy  string    `json:"policy,omitempty"` // "dynamic" or "static"
 	Prepull *bool     `json:"prepull,omitempty"`
+	// Labels 是附加在服务上的标签，每个标签的格式为 "key=value"
+	Labels []string `json:"labels,omitempty"`
 }
 
 type ImageSpec struct {
@@ -50,6 +52,16 @@ type ServiceDeleteResponse struct {
 	ID string `json:"transactionId"`
 }
 
+// ServiceRedeployRequest 是重新部署服务的 API payload。
+type ServiceRedeployRequest struct {
+	ID string `json:"id"`
+}
+
+// ServiceRedeployResponse 是 Redeploy 方法的返回值，重新部署是一个异步事务。
+type ServiceRedeployResponse struct {
+	ID string `json:"transactionId"`
+}
+
 // ServiceGet mimics the response from the GET /service/:id endpoint.
 // ServiceGet 精确匹配 GET /service/:id API 的成功响应 data。
 type ServiceGet struct {
@@ -67,6 +79,28 @@ type ServiceGet struct {
 	Image                *ImageSpec        `json:"image"`          // <-- 复用共享类型
 	Node                 *NodeSpec         `json:"node,omitempty"` // <-- 复用共享类型
 	NodeList             []ServiceNodeInfo `json:"nodeList"`
+	Labels               []string          `json:"labels,omitempty"`
+}
+
+// UpdateRequest 返回以服务当前的配置为内容的 UpdateServiceRequest，修改其中的部分字段后即可用于 Update。
+func (s *ServiceGet) UpdateRequest() *UpdateServiceRequest {
+	req := &UpdateServiceRequest{
+		ID:     s.ID,
+		Name:   s.Name,
+		Policy: s.Policy,
+		Labels: s.Labels,
+	}
+	if s.Image != nil {
+		req.Image = *s.Image
+	}
+	if s.Node != nil {
+		req.Node = *s.Node
+	}
+	if s.Factor > 0 {
+		factor := s.Factor
+		req.Factor = &factor
+	}
+	return req
 }
 
 // --- List Options and Response Structures ---
@@ -106,6 +140,7 @@ type ProvisionListRow struct {
 	InstanceOnline       int               `json:"instanceOnline"`
 	DefaultLabels        []string          `json:"defaultLabels"`
 	PathLabel            string            `json:"pathLabel"`
+	Labels               []string          `json:"labels,omitempty"`
 }
 
 // ImageListEntry 是服务列表中内嵌的镜像信息。
@@ -142,6 +177,7 @@ type UpdateServiceRequest struct {
 	Node   NodeSpec  `json:"node"`
 	Factor *int      `json:"factor,omitempty"`
 	Policy string    `json:"policy,omitempty"` // "dynamic" or "static"
+	Labels []string  `json:"labels,omitempty"`
 
 	// 注意：Update 的 payload 中似乎没有 prepull 字段，所以我们不在这里包含它。
 }
@@ -0,0 +1,107 @@
+// file: pkg/ecsm-client/clientset/test/auth_client_test.go
+
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthClient 测试登录、刷新和注销，以及设置 TokenSource 后请求携带 Token 而 Auth() 的请求不携带。
+func TestAuthClient(t *testing.T) {
+	cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/user/login":
+			assert.Empty(t, r.Header.Get("Authorization"), "the login request must not send credentials")
+			var req clientset.LoginRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req.Username != "admin" || req.Password != "secret" {
+				w.Write([]byte(`{"status":401,"message":"invalid username or password"}`))
+				return
+			}
+			w.Write([]byte(`{"status":200,"message":"success","data":{"token":"t1","refreshToken":"r1","expiresAt":1700000000000}}`))
+		case "/api/v1/user/refresh":
+			assert.Empty(t, r.Header.Get("Authorization"), "the refresh request must not send credentials")
+			var req clientset.RefreshRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "r1", req.RefreshToken)
+			w.Write([]byte(`{"status":200,"message":"success","data":{"token":"t2","expiresAt":1700000600000}}`))
+		case "/api/v1/user/logout":
+			var req clientset.LogoutRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "t2", req.Token)
+			w.Write([]byte(`{"status":200,"message":"success"}`))
+		case "/api/v1/transaction/tx-1":
+			assert.Equal(t, "Bearer t2", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"status":200,"message":"success","data":{"id":"tx-1","status":"success"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	_, err := cs.Auth().Login(ctx, &clientset.LoginRequest{Username: "admin", Password: "wrong"})
+	require.Error(t, err)
+
+	token, err := cs.Auth().Login(ctx, &clientset.LoginRequest{Username: "admin", Password: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "t1", token.Token)
+	assert.Equal(t, "r1", token.RefreshToken)
+	assert.Equal(t, int64(1700000000000), token.Expiry().UnixMilli())
+
+	cs.SetTokenSource(rest.StaticTokenSource("t2"))
+	refreshed, err := cs.Auth().Refresh(ctx, token.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, "t2", refreshed.Token)
+	assert.Empty(t, refreshed.RefreshToken)
+
+	_, err = cs.Transactions().Get(ctx, "tx-1")
+	require.NoError(t, err)
+
+	require.NoError(t, cs.Auth().Logout(ctx, refreshed.Token))
+}
+
+// TestLoginAuthProvider 测试 NewLoginAuthProvider 在第一个请求之前登录、携带登录时设置的会话 Cookie，
+// 并在服务器使会话失效后重新登录。
+func TestLoginAuthProvider(t *testing.T) {
+	logins := 0
+	expired := false
+	cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/user/login":
+			logins++
+			expired = false
+			http.SetCookie(w, &http.Cookie{Name: "SESSION", Value: fmt.Sprintf("s%d", logins)})
+			w.Write([]byte(`{"status":200,"message":"success","data":{"token":"t1"}}`))
+		case "/api/v1/transaction/tx-1":
+			cookie, err := r.Cookie("SESSION")
+			if err != nil || cookie.Value != fmt.Sprintf("s%d", logins) || expired {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "Bearer t1", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"status":200,"message":"success","data":{"id":"tx-1","status":"success"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	cs.SetAuthProvider(clientset.NewLoginAuthProvider(cs.Auth(), "admin", "secret"))
+	ctx := context.Background()
+
+	_, err := cs.Transactions().Get(ctx, "tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, logins)
+
+	expired = true
+	_, err = cs.Transactions().Get(ctx, "tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, logins, "the expired session must be renewed by logging in again")
+}
@@ -2,16 +2,86 @@ package test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newFakeContainerClientset 创建一个由 rest.Fake 模拟服务和容器接口的 Clientset。
+// 它有一个没有容器的服务 idle 和一个有两个容器的服务 acc_server，
+// 提交给容器的控制动作被记录在容器的操作历史中，使容器的测试不依赖真实的 ECSM 环境。
+func newFakeContainerClientset(t *testing.T) *clientset.Clientset {
+	fake := rest.NewFake()
+	services := []clientset.ProvisionListRow{
+		{ID: "svc-0", Name: "idle", Status: "running"},
+		{ID: "svc-1", Name: "acc_server", Status: "running", InstanceOnline: 2},
+	}
+	containers := []clientset.ContainerInfo{
+		{ID: "c-1", TaskID: "task-1", Name: "acc_server-1", Status: "running", ServiceID: "svc-1", ServiceName: "acc_server"},
+		{ID: "c-2", TaskID: "task-2", Name: "acc_server-2", Status: "running", ServiceID: "svc-1", ServiceName: "acc_server"},
+	}
+	history := map[string][]clientset.ContainerHistory{}
+	var nextTx int
+
+	fake.AddReactor("GET", "service", func(action rest.Action) (bool, *http.Response, error) {
+		list := &clientset.ServiceList{Items: []clientset.ProvisionListRow{}}
+		for _, svc := range services {
+			if strings.Contains(svc.Name, action.Query.Get("name")) {
+				list.Items = append(list.Items, svc)
+			}
+		}
+		list.Total = len(list.Items)
+		return rest.RespondWith(list)(action)
+	})
+	fake.AddReactor("GET", "container/service", func(action rest.Action) (bool, *http.Response, error) {
+		list := &clientset.ContainerList{Items: []clientset.ContainerInfo{}}
+		for _, co := range containers {
+			if slices.Contains(action.Query["serviceIds[]"], co.ServiceID) {
+				list.Items = append(list.Items, co)
+			}
+		}
+		list.Total = len(list.Items)
+		return rest.RespondWith(list)(action)
+	})
+	fake.AddReactor("GET", "container/action/history", func(action rest.Action) (bool, *http.Response, error) {
+		items := history[action.Query.Get("id")]
+		return rest.RespondWith(&clientset.ContainerHistoryList{Total: len(items), Items: items})(action)
+	})
+	fake.AddReactor("GET", "container/*", func(action rest.Action) (bool, *http.Response, error) {
+		for _, co := range containers {
+			if co.TaskID == path.Base(action.Path) {
+				return rest.RespondWith(co)(action)
+			}
+		}
+		return rest.RespondWithError(http.StatusNotFound, "container not found")(action)
+	})
+	fake.AddReactor("PUT", "container", func(action rest.Action) (bool, *http.Response, error) {
+		var req clientset.ContainerControlByNameRequest
+		require.NoError(t, json.Unmarshal(action.Body, &req))
+		for _, co := range containers {
+			if co.Name == req.Name {
+				history[co.TaskID] = append(history[co.TaskID], clientset.ContainerHistory{
+					ID: co.TaskID, Cmd: string(req.Action), User: "admin", Time: "2024-01-01 00:00:00"})
+				nextTx++
+				return rest.RespondWith(&clientset.Transaction{ID: fmt.Sprintf("tx-%d", nextTx), Status: clientset.TransactionStatusRunning})(action)
+			}
+		}
+		return rest.RespondWithError(http.StatusNotFound, "container not found")(action)
+	})
+	return clientset.NewForRESTClient(fake.RESTClient)
+}
+
 func TestContainerClient_Get(t *testing.T) {
-	clientsetInstance := newTestClientset(t)
+	clientsetInstance := newFakeContainerClientset(t)
 	containerClient := clientsetInstance.Containers()
 	serviceClient := clientsetInstance.Services()
 	ctx := context.Background()
@@ -66,7 +136,7 @@ func TestContainerClient_Get(t *testing.T) {
 // TestContainerClient_ListByService 测试根据服务ID列出容器
 func TestContainerClient_ListByService(t *testing.T) {
 	// --- Setup ---
-	clientsetInstance := newTestClientset(t)
+	clientsetInstance := newFakeContainerClientset(t)
 	containerClient := clientsetInstance.Containers()
 	serviceClient := clientsetInstance.Services()
 	ctx := context.Background()
@@ -125,7 +195,7 @@ func TestContainerClient_ListByService(t *testing.T) {
 // TestContainerClient_SubmitControlActionAndGetHistory 测试控制容器状态和获取历史
 func TestContainerClient_SubmitControlActionAndGetHistory(t *testing.T) {
 	// --- Setup ---
-	clientsetInstance := newTestClientset(t)
+	clientsetInstance := newFakeContainerClientset(t)
 	containerClient := clientsetInstance.Containers()
 	serviceClient := clientsetInstance.Services()
 	ctx := context.Background()
@@ -161,10 +231,6 @@ func TestContainerClient_SubmitControlActionAndGetHistory(t *testing.T) {
 		require.NotNil(t, transaction)
 		assert.NotEmpty(t, transaction.ID, "返回的 Transaction ID 不能为空")
 		t.Logf("成功提交 'stop' 动作, Transaction ID: %s", transaction.ID)
-
-		// 由于这是异步操作，我们等待一小段时间，让状态有机会改变
-		// 注意：在真实的集成测试中，这里会使用轮询(polling)来检查事务状态，而不是硬编码等待
-		time.Sleep(2 * time.Second)
 	})
 
 	// --- Test Get History ---
@@ -199,6 +265,5 @@ func TestContainerClient_SubmitControlActionAndGetHistory(t *testing.T) {
 		t.Log("正在重新启动容器以清理测试状态...")
 		_, err := containerClient.SubmitControlActionByName(ctx, containerName, clientset.ActionStart)
 		assert.NoError(t, err, "清理步骤：重新启动容器失败")
-		time.Sleep(2 * time.Second)
 	})
 }
@@ -0,0 +1,144 @@
+// file: pkg/ecsm-client/clientset/test/discovery_client_test.go
+
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockClientset 创建一个连接到 mock 服务器的 Clientset。
+func newMockClientset(t *testing.T, handler http.HandlerFunc) *clientset.Clientset {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	cs, err := clientset.NewClientset(u.Scheme, u.Hostname(), u.Port())
+	require.NoError(t, err)
+	return cs
+}
+
+// TestDiscoveryClient_ServerVersion 测试获取服务器版本，以及旧版本服务器没有版本接口时的错误。
+func TestDiscoveryClient_ServerVersion(t *testing.T) {
+	t.Run("Available", func(t *testing.T) {
+		cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/version", r.URL.Path)
+			w.Write([]byte(`{"status":200,"message":"success","data":{"version":"2.3.1","apiVersions":["v1"],"capabilities":["image-upload","label-control"]}}`))
+		})
+
+		info, err := cs.Discovery().ServerVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "2.3.1", info.Version)
+		assert.Equal(t, []string{"v1"}, info.APIVersions)
+		assert.True(t, info.HasCapability("label-control"))
+		assert.False(t, info.HasCapability("exec"))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		})
+
+		_, err := cs.Discovery().ServerVersion(context.Background())
+		assert.ErrorIs(t, err, clientset.ErrVersionUnavailable)
+	})
+}
+
+// newFakeVersionClientset 返回使用 rest.Fake 的 Clientset，服务器在 served 中的 API 版本下提供版本接口，
+// 并声明支持 listed 中的版本。
+func newFakeVersionClientset(served, listed []string) (*clientset.Clientset, *rest.Fake) {
+	fake := rest.NewFake()
+	fake.AddReactor("GET", "version", func(action rest.Action) (bool, *http.Response, error) {
+		if !slices.Contains(served, action.APIVersion) {
+			return rest.RespondWithError(http.StatusNotFound, "not found")(action)
+		}
+		return rest.RespondWith(&clientset.ServerInfo{Version: "2.4.0", APIVersions: listed})(action)
+	})
+	fake.AddReactor("GET", "service/*", rest.RespondWith(&clientset.ServiceGet{ID: "svc-1"}))
+	return clientset.NewForRESTClient(fake.RESTClient), fake
+}
+
+// TestClientset_NegotiateAPIVersion 测试按照服务器声明的 API 版本选择请求使用的版本。
+func TestClientset_NegotiateAPIVersion(t *testing.T) {
+	ctx := context.Background()
+	// lastVersion 返回 Clientset 的下一个请求使用的 API 版本
+	lastVersion := func(t *testing.T, cs *clientset.Clientset, fake *rest.Fake) string {
+		_, err := cs.Services().Get(ctx, "svc-1")
+		require.NoError(t, err)
+		actions := fake.Actions()
+		return actions[len(actions)-1].APIVersion
+	}
+
+	t.Run("Upgraded", func(t *testing.T) {
+		cs, fake := newFakeVersionClientset([]string{"v1", "v2"}, []string{"v1", "v2"})
+		v, err := cs.NegotiateAPIVersion(ctx, "v2", "v1")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", v)
+		assert.Equal(t, "v2", lastVersion(t, cs, fake))
+	})
+
+	t.Run("DefaultCandidates", func(t *testing.T) {
+		cs, fake := newFakeVersionClientset([]string{"v1", "v2"}, []string{"v1", "v2"})
+		v, err := cs.NegotiateAPIVersion(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, clientset.SupportedAPIVersions[0], v)
+		assert.Equal(t, v, lastVersion(t, cs, fake))
+	})
+
+	t.Run("CurrentVersionRemoved", func(t *testing.T) {
+		cs, fake := newFakeVersionClientset([]string{"v2"}, []string{"v2"})
+		v, err := cs.NegotiateAPIVersion(ctx, "v2", "v1")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", v)
+		assert.Equal(t, "v2", lastVersion(t, cs, fake))
+	})
+
+	t.Run("NoVersionEndpoint", func(t *testing.T) {
+		cs, fake := newFakeVersionClientset(nil, nil)
+		v, err := cs.NegotiateAPIVersion(ctx, "v2", "v1")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", v)
+		assert.Equal(t, "v1", lastVersion(t, cs, fake))
+	})
+
+	t.Run("NoCommonVersion", func(t *testing.T) {
+		cs, fake := newFakeVersionClientset([]string{"v1"}, []string{"v3"})
+		_, err := cs.NegotiateAPIVersion(ctx, "v2", "v1")
+		assert.ErrorIs(t, err, clientset.ErrNoCommonAPIVersion)
+		assert.Equal(t, "v1", lastVersion(t, cs, fake))
+	})
+}
+
+// TestServerInfo_CheckCompatibility 测试服务器版本的兼容性检查。
+func TestServerInfo_CheckCompatibility(t *testing.T) {
+	testCases := []struct {
+		version    string
+		compatible bool
+	}{
+		{"2.0.0", true},
+		{"v2.4", true},
+		{"2.10.3-rc.1", true},
+		{"1.9.0", false},
+		{"3.0.0", false},
+		{"unknown", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			err := (&clientset.ServerInfo{Version: tc.version}).CheckCompatibility()
+			if tc.compatible {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -5,22 +5,60 @@ package test
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
+	"path"
 	"testing"
 
 	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// --- newTestClientset() 辅助函数 (已存在) ---
+// newFakeImageClientset 创建一个由 rest.Fake 模拟镜像接口的 Clientset，
+// "local" 仓库中有一个 SylixOS 镜像 acc_server@1.0，使镜像的测试不依赖真实的 ECSM 环境。
+func newFakeImageClientset(t *testing.T) *clientset.Clientset {
+	fake := rest.NewFake()
+	config := &clientset.EcsImageConfig{
+		Platform: &clientset.Platform{OS: "sylixos", Arch: "arm64"},
+		SylixOS:  &clientset.SylixOS{},
+	}
+	image := &clientset.ImageDetails{ID: "img-1", Name: "acc_server", Tag: "1.0", OS: "sylixos", Arch: "arm64",
+		Size: 1024, CreatedTime: "2024-01-01 00:00:00", Config: config}
+
+	fake.AddReactor("GET", "image", func(action rest.Action) (bool, *http.Response, error) {
+		list := &clientset.ImageList{Items: []clientset.ImageListItem{}}
+		if action.Query.Get("registryId") == clientset.LocalRegistryID {
+			list.Items = append(list.Items, clientset.ImageListItem{ID: image.ID, Name: image.Name, Tag: image.Tag,
+				OS: image.OS, Arch: image.Arch, Size: image.Size, CreatedTime: image.CreatedTime})
+		}
+		list.Total = len(list.Items)
+		return rest.RespondWith(list)(action)
+	})
+	fake.AddReactor("GET", "image/summary", rest.RespondWith(&clientset.ImageStatistics{Local: 1}))
+	fake.AddReactor("GET", "image/count", rest.RespondWith([]clientset.RepositoryInfo{
+		{Count: 1, RegistryID: clientset.LocalRegistryID, RegistryName: "本地仓库"},
+	}))
+	fake.AddReactor("GET", "registry/*/image/*", func(action rest.Action) (bool, *http.Response, error) {
+		if path.Base(action.Path) != image.ID {
+			return rest.RespondWithError(http.StatusNotFound, "image not found")(action)
+		}
+		return rest.RespondWith(image)(action)
+	})
+	fake.AddReactor("GET", "image/config", func(action rest.Action) (bool, *http.Response, error) {
+		if action.Query.Get("ref") != "acc_server@1.0#sylixos" {
+			return rest.RespondWithError(http.StatusNotFound, "image not found")(action)
+		}
+		return rest.RespondWith(map[string]interface{}{"config": config})(action)
+	})
+	return clientset.NewForRESTClient(fake.RESTClient)
+}
 
 // TestImageClient_ReadOperations 对镜像的只读操作进行测试。
-// 这个测试是安全的，因为它不会修改任何外部系统状态。
-// 它依赖于你的 ECSM 环境中 "local" 仓库里至少存在一个镜像。
 func TestImageClient_ReadOperations(t *testing.T) {
 	// --- Setup ---
-	cs := newTestClientset(t)
+	cs := newFakeImageClientset(t)
 	imageClient := cs.Images()
 	ctx := context.Background()
 
@@ -34,8 +72,7 @@ func TestImageClient_ReadOperations(t *testing.T) {
 		list, err := imageClient.List(ctx, opts)
 		require.NoError(t, err)
 		require.NotNil(t, list)
-		// 核心前置条件：你的 local 仓库中必须至少有一个镜像
-		require.NotEmpty(t, list.Items, "测试失败：ECSM 'local' 仓库中必须至少存在一个镜像")
+		require.NotEmpty(t, list.Items, "'local' 仓库中应该有一个镜像")
 
 		// 抽查第一个镜像的字段
 		firstImage := list.Items[0]
@@ -4,21 +4,53 @@ package test
 
 import (
 	"context"
+	"net/http"
+	"path"
+	"slices"
 	"testing"
 
 	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// --- newTestClientset() 辅助函数 (已存在) ---
+// newFakeNodeClientset 创建一个由 rest.Fake 模拟节点接口的 Clientset，
+// 其中注册了一个节点 node-1，使节点的测试不依赖真实的 ECSM 环境。
+func newFakeNodeClientset(t *testing.T) *clientset.Clientset {
+	fake := rest.NewFake()
+	node := clientset.NodeInfo{ID: "node-id-1", Name: "node-1", Address: "10.0.0.1:3001", Password: "root", Status: "online"}
+
+	fake.AddReactor("GET", "node", rest.RespondWith(&clientset.NodeList{Total: 1, Items: []clientset.NodeInfo{node}}))
+	fake.AddReactor("GET", "node/status", func(action rest.Action) (bool, *http.Response, error) {
+		resp := &clientset.NodeStatusResponse{Nodes: []clientset.NodeStatus{}}
+		if slices.Contains(action.Query["ids[]"], node.ID) {
+			resp.Nodes = append(resp.Nodes, clientset.NodeStatus{ID: node.ID, Status: node.Status, MemoryTotal: 512 << 20})
+		}
+		return rest.RespondWith(resp)(action)
+	})
+	fake.AddReactor("GET", "node/name/check", func(action rest.Action) (bool, *http.Response, error) {
+		return rest.RespondWith(action.Query.Get("name") == node.Name)(action)
+	})
+	fake.AddReactor("GET", "node/name/*", func(action rest.Action) (bool, *http.Response, error) {
+		if path.Base(action.Path) != node.Name {
+			return rest.RespondWithError(http.StatusNotFound, "node not found")(action)
+		}
+		return rest.RespondWith(&clientset.NodeDetailsByName{ID: node.ID, IP: "10.0.0.1", Port: 3001, Name: node.Name, Password: node.Password})(action)
+	})
+	fake.AddReactor("GET", "node/*", func(action rest.Action) (bool, *http.Response, error) {
+		if path.Base(action.Path) != node.ID {
+			return rest.RespondWithError(http.StatusNotFound, "node not found")(action)
+		}
+		return rest.RespondWith(&clientset.NodeDetailsByID{ID: node.ID, Address: node.Address, Name: node.Name, Password: node.Password})(action)
+	})
+	return clientset.NewForRESTClient(fake.RESTClient)
+}
 
 // TestNodeClient_ReadOperations 对节点的只读操作进行测试。
-// 这个测试是安全的，因为它不会修改任何外部系统状态。
-// 它依赖于你的 ECSM 环境中至少存在一个已注册的节点。
 func TestNodeClient_ReadOperations(t *testing.T) {
 	// --- Setup ---
-	cs := newTestClientset(t)
+	cs := newFakeNodeClientset(t)
 	nodeClient := cs.Nodes()
 	ctx := context.Background()
 
@@ -31,8 +63,7 @@ func TestNodeClient_ReadOperations(t *testing.T) {
 		list, err := nodeClient.List(ctx, opts)
 		require.NoError(t, err)
 		require.NotNil(t, list)
-		// 核心前置条件：你的环境中必须至少有一个节点
-		require.GreaterOrEqual(t, len(list.Items), 1, "测试失败：ECSM环境中必须至少存在一个节点")
+		require.GreaterOrEqual(t, len(list.Items), 1, "应该至少有一个节点")
 
 		// 随机抽查第一个节点的字段是否符合预期
 		firstNode := list.Items[0]
@@ -2,32 +2,84 @@ package test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-const (
-	protocol = "http"
-	host     = "192.168.31.129"
-	port     = "3001"
-)
-
-// 创建测试用的 Clientset 实例
-func newTestClientset(t *testing.T) *clientset.Clientset {
-	clientsetInstance, err := clientset.NewClientset(protocol, host, port)
-	require.NoError(t, err, "创建 Clientset 失败")
-	require.NotNil(t, clientsetInstance, "Clientset 不应为 nil")
-	return clientsetInstance
+// newFakeServiceClientset 创建一个由 rest.Fake 模拟服务接口的 Clientset，它在内存中保存服务，
+// 初始时有一个名为 existing 的服务，使服务的测试不依赖真实的 ECSM 环境。
+func newFakeServiceClientset(t *testing.T) *clientset.Clientset {
+	fake := rest.NewFake()
+	services := map[string]*clientset.ServiceGet{
+		"svc-0": {ID: "svc-0", Name: "existing", Status: "running", Factor: 1, Policy: "static",
+			CreatedTime: "2024-01-01 00:00:00", UpdatedTime: "2024-01-01 00:00:00"},
+	}
+	var nextID int
+
+	fake.AddReactor("GET", "service", func(action rest.Action) (bool, *http.Response, error) {
+		pageNum, _ := strconv.Atoi(action.Query.Get("pageNum"))
+		pageSize, _ := strconv.Atoi(action.Query.Get("pageSize"))
+		list := &clientset.ServiceList{Total: len(services), PageNum: pageNum, PageSize: pageSize}
+		for _, svc := range services {
+			list.Items = append(list.Items, clientset.ProvisionListRow{ID: svc.ID, Name: svc.Name, Status: svc.Status,
+				CreatedTime: svc.CreatedTime, UpdatedTime: svc.UpdatedTime, Factor: svc.Factor, Policy: svc.Policy})
+		}
+		return rest.RespondWith(list)(action)
+	})
+	fake.AddReactor("GET", "service/*", func(action rest.Action) (bool, *http.Response, error) {
+		svc, ok := services[path.Base(action.Path)]
+		if !ok {
+			return rest.RespondWithError(http.StatusNotFound, "service not found")(action)
+		}
+		return rest.RespondWith(svc)(action)
+	})
+	fake.AddReactor("POST", "service", func(action rest.Action) (bool, *http.Response, error) {
+		var req clientset.CreateServiceRequest
+		require.NoError(t, json.Unmarshal(action.Body, &req))
+		nextID++
+		svc := &clientset.ServiceGet{ID: fmt.Sprintf("svc-%d", nextID), Name: req.Name, Status: "deploying", Policy: req.Policy,
+			CreatedTime: "2024-01-02 00:00:00", UpdatedTime: "2024-01-02 00:00:00"}
+		if req.Factor != nil {
+			svc.Factor = *req.Factor
+		}
+		services[svc.ID] = svc
+		return rest.RespondWith(&clientset.ServiceCreateResponse{ID: svc.ID})(action)
+	})
+	fake.AddReactor("PUT", "service", func(action rest.Action) (bool, *http.Response, error) {
+		var req clientset.UpdateServiceRequest
+		require.NoError(t, json.Unmarshal(action.Body, &req))
+		svc, ok := services[req.ID]
+		if !ok {
+			return rest.RespondWithError(http.StatusNotFound, "service not found")(action)
+		}
+		svc.Name, svc.Policy = req.Name, req.Policy
+		if req.Factor != nil {
+			svc.Factor = *req.Factor
+		}
+		return rest.RespondWith(&clientset.ServiceCreateResponse{ID: svc.ID})(action)
+	})
+	fake.AddReactor("DELETE", "service/*", func(action rest.Action) (bool, *http.Response, error) {
+		delete(services, path.Base(action.Path))
+		return rest.RespondWith(&clientset.ServiceDeleteResponse{ID: "tx-1"})(action)
+	})
+	return clientset.NewForRESTClient(fake.RESTClient)
 }
 
 // TestServiceClient_List 测试列出服务功能
 func TestServiceClient_List(t *testing.T) {
 	// 创建 Clientset 和 ServiceInterface
-	clientsetInstance := newTestClientset(t)
+	clientsetInstance := newFakeServiceClientset(t)
 	serviceClient := clientsetInstance.Services()
 
 	// 创建上下文
@@ -62,7 +114,7 @@ func TestServiceClient_List(t *testing.T) {
 // TestServiceClient_Get 测试获取单个服务详情功能
 func TestServiceClient_Get(t *testing.T) {
 	// 创建 Clientset 和 ServiceInterface
-	clientsetInstance := newTestClientset(t)
+	clientsetInstance := newFakeServiceClientset(t)
 	serviceClient := clientsetInstance.Services()
 
 	// 创建上下文
@@ -102,7 +154,7 @@ func TestServiceClient_Get(t *testing.T) {
 // TestServiceClient_Create 测试创建服务功能
 func TestServiceClient_Create(t *testing.T) {
 	// 创建 Clientset 和 ServiceInterface
-	clientsetInstance := newTestClientset(t)
+	clientsetInstance := newFakeServiceClientset(t)
 	serviceClient := clientsetInstance.Services()
 
 	// 创建上下文
@@ -172,9 +224,6 @@ func TestServiceClient_Create(t *testing.T) {
 	// 验证创建响应的基本属性
 	assert.NotEmpty(t, createResponse.ID, "服务 ID 不应为空")
 
-	// 等待服务创建完成
-	time.Sleep(5 * time.Second)
-
 	// 获取创建的服务详情
 	serviceDetail, err := serviceClient.Get(ctx, createResponse.ID)
 	require.NoError(t, err, "获取创建的服务详情失败")
@@ -193,7 +242,7 @@ func TestServiceClient_Create(t *testing.T) {
 // TestServiceClient_Update 测试更新服务功能
 func TestServiceClient_Update(t *testing.T) {
 	// 创建 Clientset 和 ServiceInterface
-	clientsetInstance := newTestClientset(t)
+	clientsetInstance := newFakeServiceClientset(t)
 	serviceClient := clientsetInstance.Services()
 
 	// 创建上下文
@@ -260,9 +309,6 @@ func TestServiceClient_Update(t *testing.T) {
 	require.NoError(t, err, "创建服务失败")
 	require.NotNil(t, createResponse, "创建服务响应不应为 nil")
 
-	// 等待服务创建完成
-	time.Sleep(5 * time.Second)
-
 	// 更新服务请求
 	updatedFactor := 2
 	updateRequest := &clientset.UpdateServiceRequest{
@@ -321,9 +367,6 @@ func TestServiceClient_Update(t *testing.T) {
 	require.NoError(t, err, "更新服务失败")
 	require.NotNil(t, updateResponse, "更新服务响应不应为 nil")
 
-	// 等待服务更新完成
-	time.Sleep(10 * time.Second)
-
 	// 获取更新后的服务详情
 	serviceDetail, err := serviceClient.Get(ctx, createResponse.ID)
 	require.NoError(t, err, "获取更新后的服务详情失败")
@@ -344,3 +387,74 @@ func TestServiceClient_Update(t *testing.T) {
 	require.NoError(t, err, "删除服务失败")
 	require.NotNil(t, deleteResponse, "删除服务响应不应为 nil")
 }
+
+// TestServiceClient_Patch 测试服务器支持时以 PATCH 发送补丁，不支持时在客户端应用补丁并以完整的请求更新服务。
+func TestServiceClient_Patch(t *testing.T) {
+	const service = `{"status":200,"message":"success","data":{"id":"0f4b1c","name":"web","factor":2,"policy":"static",` +
+		`"image":{"ref":"nginx@1.0#sylixos"},"node":{"names":["node-1"]},"labels":["tier=frontend"]}}`
+
+	t.Run("ServerSide", func(t *testing.T) {
+		cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			assert.Equal(t, "/api/v1/service/0f4b1c", r.URL.Path)
+			assert.Equal(t, string(rest.MergePatchType), r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"factor":3}`, string(body))
+			w.Write([]byte(`{"status":200,"message":"success","data":{"id":"0f4b1c"}}`))
+		})
+
+		resp, err := cs.Services().Patch(context.Background(), "0f4b1c", rest.MergePatchType, []byte(`{"factor":3}`))
+		require.NoError(t, err)
+		assert.Equal(t, "0f4b1c", resp.ID)
+	})
+
+	for _, tc := range []struct {
+		name  string
+		pt    rest.PatchType
+		patch string
+	}{
+		{"MergePatchFallback", rest.MergePatchType, `{"factor":3,"labels":null}`},
+		{"JSONPatchFallback", rest.JSONPatchType, `[{"op":"replace","path":"/factor","value":3},{"op":"remove","path":"/labels"}]`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var updated clientset.UpdateServiceRequest
+			cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodPatch:
+					w.WriteHeader(http.StatusMethodNotAllowed)
+				case http.MethodGet:
+					w.Write([]byte(service))
+				case http.MethodPut:
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+					w.Write([]byte(`{"status":200,"message":"success","data":{"id":"0f4b1c"}}`))
+				}
+			})
+
+			_, err := cs.Services().Patch(context.Background(), "0f4b1c", tc.pt, []byte(tc.patch))
+			require.NoError(t, err)
+			require.NotNil(t, updated.Factor)
+			assert.Equal(t, 3, *updated.Factor)
+			assert.Empty(t, updated.Labels)
+			// 补丁之外的字段保持不变
+			assert.Equal(t, "web", updated.Name)
+			assert.Equal(t, "static", updated.Policy)
+			assert.Equal(t, []string{"node-1"}, updated.Node.Names)
+		})
+	}
+
+	t.Run("ChangeID", func(t *testing.T) {
+		cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPatch:
+				w.WriteHeader(http.StatusNotImplemented)
+			case http.MethodGet:
+				w.Write([]byte(service))
+			default:
+				t.Errorf("Unexpected %s request", r.Method)
+			}
+		})
+
+		_, err := cs.Services().Patch(context.Background(), "0f4b1c", rest.MergePatchType, []byte(`{"id":"other"}`))
+		assert.ErrorContains(t, err, "must not change the service id")
+	})
+}
@@ -0,0 +1,65 @@
+// file: pkg/ecsm-client/clientset/test/transaction_client_test.go
+
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTransactionClient_Get 测试按 ID 获取事务。
+func TestTransactionClient_Get(t *testing.T) {
+	cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/transaction/tx-1", r.URL.Path)
+		w.Write([]byte(`{"status":200,"message":"success","data":{"id":"tx-1","status":"failure","type":"service.create","message":"image not found","timestamp":1700000000000}}`))
+	})
+
+	tx, err := cs.Transactions().Get(context.Background(), "tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, "tx-1", tx.ID)
+	assert.Equal(t, clientset.TransactionStatusFailure, tx.Status)
+	assert.Equal(t, "service.create", tx.Type)
+	assert.Equal(t, "image not found", tx.Message)
+	assert.Equal(t, int64(1700000000000), tx.Timestamp)
+}
+
+// TestTransactionClient_List 测试列出事务时的查询参数，以及 ListAll 的分页。
+func TestTransactionClient_List(t *testing.T) {
+	var pages []int
+	cs := newMockClientset(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/transaction", r.URL.Path)
+		assert.Equal(t, "running", r.URL.Query().Get("status"))
+		assert.Equal(t, "2", r.URL.Query().Get("pageSize"))
+		page, err := strconv.Atoi(r.URL.Query().Get("pageNum"))
+		require.NoError(t, err)
+		pages = append(pages, page)
+
+		// 一共 3 个事务，每页 2 个
+		items := fmt.Sprintf(`{"id":"tx-%d","status":"running"}`, 2*page-1)
+		if page == 1 {
+			items += `,{"id":"tx-2","status":"running"}`
+		}
+		fmt.Fprintf(w, `{"status":200,"message":"success","data":{"total":3,"pageNum":%d,"pageSize":2,"list":[%s]}}`, page, items)
+	})
+
+	opts := clientset.TransactionListOptions{PageNum: 1, PageSize: 2, Status: clientset.TransactionStatusRunning}
+	list, err := cs.Transactions().List(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, list.Total)
+	require.Len(t, list.Items, 2)
+	assert.Equal(t, "tx-1", list.Items[0].ID)
+
+	pages = nil
+	all, err := cs.Transactions().ListAll(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, pages)
+	require.Len(t, all, 3)
+	assert.Equal(t, "tx-3", all[2].ID)
+}
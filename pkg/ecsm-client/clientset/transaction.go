@@ -0,0 +1,95 @@
+package clientset
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+)
+
+// 事务的状态，参见 Transaction.Status
+const (
+	TransactionStatusRunning = "running"
+	TransactionStatusFailure = "failure"
+	TransactionStatusSuccess = "success"
+)
+
+type TransactionGetter interface {
+	Transactions() TransactionInterface
+}
+
+// TransactionInterface 提供了查询异步操作 (服务创建/删除、容器控制等) 事务状态的方法。
+type TransactionInterface interface {
+	// Get 根据事务 ID 获取事务的当前状态。
+	Get(ctx context.Context, transactionID string) (*Transaction, error)
+
+	// List 获取一页事务，最近提交的事务在前。
+	List(ctx context.Context, opts TransactionListOptions) (*TransactionList, error)
+
+	// ListAll 获取符合条件的所有事务，它会自动处理分页。
+	ListAll(ctx context.Context, opts TransactionListOptions) ([]Transaction, error)
+}
+
+type transactionClient struct {
+	restClient rest.Interface
+}
+
+func newTransactions(restClient rest.Interface) *transactionClient {
+	return &transactionClient{restClient: restClient}
+}
+
+func (c *transactionClient) Get(ctx context.Context, transactionID string) (*Transaction, error) {
+	result := &Transaction{}
+	err := c.restClient.Get().
+		Resource("transaction").
+		Name(transactionID).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+// List 实现了 TransactionInterface 的同名方法。
+func (c *transactionClient) List(ctx context.Context, opts TransactionListOptions) (*TransactionList, error) {
+	result := &TransactionList{}
+	req := c.restClient.Get().Resource("transaction")
+
+	req.Param("pageNum", strconv.Itoa(opts.PageNum))
+	req.Param("pageSize", strconv.Itoa(opts.PageSize))
+	if opts.Status != "" {
+		req.Param("status", opts.Status)
+	}
+	if opts.Type != "" {
+		req.Param("type", opts.Type)
+	}
+
+	err := req.Do(ctx).Into(result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListAll 实现了 TransactionInterface 的同名方法。
+func (c *transactionClient) ListAll(ctx context.Context, opts TransactionListOptions) ([]Transaction, error) {
+	var all []Transaction
+	opts.PageNum = 1
+	if opts.PageSize == 0 {
+		opts.PageSize = 100
+	}
+
+	for {
+		list, err := c.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(list.Items) == 0 {
+			break
+		}
+		all = append(all, list.Items...)
+		if len(all) >= list.Total {
+			break
+		}
+		opts.PageNum++
+	}
+	return all, nil
+}
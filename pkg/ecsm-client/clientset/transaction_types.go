@@ -0,0 +1,34 @@
+package clientset
+
+// Transaction 描述了一个异步操作任务。
+type Transaction struct {
+	ID        string      `json:"id"`
+	Status    string      `json:"status"` // "running", "failure", "success"
+	Data      interface{} `json:"data"`   // 使用 interface{} 来匹配任意对象
+	Timestamp int64       `json:"timestamp"`
+	// Type 是事务对应的操作，例如 "service.create" 和 "container.restart"。
+	// 提交操作的接口不返回它，只有查询事务的接口返回。
+	Type string `json:"type,omitempty"`
+	// Message 是事务失败的原因
+	Message string `json:"message,omitempty"`
+}
+
+// --- Transaction List Structures ---
+
+// TransactionListOptions 封装了所有可以用于 List 事务的查询参数。
+type TransactionListOptions struct {
+	PageNum  int
+	PageSize int
+	// Status 只返回处于该状态的事务，参见 TransactionStatusRunning 等常量
+	Status string
+	// Type 只返回该类型的操作的事务
+	Type string
+}
+
+// TransactionList 是 List 方法的返回值，精确匹配 API 响应的 data 字段。
+type TransactionList struct {
+	Total    int           `json:"total"`
+	PageNum  int           `json:"pageNum"`
+	PageSize int           `json:"pageSize"`
+	Items    []Transaction `json:"list"`
+}
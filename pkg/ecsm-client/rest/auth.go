@@ -0,0 +1,222 @@
+// file: pkg/ecsm-client/rest/auth.go
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// AuthProvider 为发往 ECSM API Server 的每个请求添加凭据，例如 Authorization 头或会话 Cookie。
+// 它会被并发调用。
+type AuthProvider interface {
+	// Authorize 在请求发出之前为 req 添加凭据。
+	Authorize(ctx context.Context, req *http.Request) error
+	// Reauthorize 在服务器以 401 拒绝了带着凭据的 req 之后调用，它让之后的 Authorize 使用新的凭据，
+	// 例如重新登录。返回 true 时请求会重新调用 Authorize 并重试一次，返回 false 时 401 响应被交给调用方。
+	Reauthorize(ctx context.Context, req *http.Request) (bool, error)
+}
+
+// tokenAuthProvider 以 Bearer Token 的形式发送 TokenSource 提供的凭据
+type tokenAuthProvider struct {
+	source TokenSource
+}
+
+// NewTokenAuthProvider 返回在每个请求中以 "Authorization: Bearer <Token>" 发送 ts 提供的 Token 的 AuthProvider。
+// 它不能在 401 之后换取新的 Token，Token 的刷新由 ts 在 Token 过期前完成。
+func NewTokenAuthProvider(ts TokenSource) AuthProvider {
+	return &tokenAuthProvider{source: ts}
+}
+
+func (p *tokenAuthProvider) Authorize(ctx context.Context, req *http.Request) error {
+	token, err := p.source.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (p *tokenAuthProvider) Reauthorize(ctx context.Context, req *http.Request) (bool, error) {
+	return false, nil
+}
+
+// basicAuthProvider 在每个请求中发送用户名和密码
+type basicAuthProvider struct {
+	username string
+	password string
+}
+
+// NewBasicAuthProvider 返回在每个请求中以 HTTP Basic 认证发送 username 和 password 的 AuthProvider，
+// 用于没有登录接口、直接校验用户名和密码的 ECSM API Server 或反向代理。
+func NewBasicAuthProvider(username, password string) AuthProvider {
+	return &basicAuthProvider{username: username, password: password}
+}
+
+func (p *basicAuthProvider) Authorize(ctx context.Context, req *http.Request) error {
+	req.SetBasicAuth(p.username, p.password)
+	return nil
+}
+
+func (p *basicAuthProvider) Reauthorize(ctx context.Context, req *http.Request) (bool, error) {
+	return false, nil
+}
+
+// Credentials 是登录得到的会话凭据。
+type Credentials struct {
+	// Token 不为空时以 "Authorization: Bearer <Token>" 的形式发送
+	Token string
+	// Cookies 是服务器在登录时设置的会话 Cookie，它们被添加到每个请求中
+	Cookies []*http.Cookie
+	// Expiry 是凭据过期的时间，为零值表示不过期
+	Expiry time.Time
+}
+
+// LoginFunc 登录 ECSM API Server 并返回新的会话凭据。
+type LoginFunc func(ctx context.Context) (*Credentials, error)
+
+// sessionRenewMargin 是会话凭据过期前多久重新登录，避免请求在发送途中因为凭据过期而失败
+const sessionRenewMargin = time.Minute
+
+// sessionAuthProvider 在第一个请求之前登录，并在凭据快要过期或被服务器拒绝时重新登录
+type sessionAuthProvider struct {
+	login LoginFunc
+
+	// mu 保证同一时刻只有一个请求在登录，其它请求等待并使用它得到的凭据
+	mu    sync.Mutex
+	creds *Credentials
+}
+
+// NewSessionAuthProvider 返回调用 login 登录、在每个请求中发送登录得到的 Token 和会话 Cookie 的 AuthProvider。
+// 第一个请求之前、凭据过期前 1 分钟内以及服务器以 401 拒绝请求之后，它会重新登录。
+func NewSessionAuthProvider(login LoginFunc) AuthProvider {
+	return &sessionAuthProvider{login: login}
+}
+
+func (p *sessionAuthProvider) Authorize(ctx context.Context, req *http.Request) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.creds == nil || (!p.creds.Expiry.IsZero() && time.Until(p.creds.Expiry) < sessionRenewMargin) {
+		if err := p.relogin(ctx); err != nil {
+			return err
+		}
+	}
+	if p.creds.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.creds.Token)
+	}
+	for _, cookie := range p.creds.Cookies {
+		req.AddCookie(cookie)
+	}
+	return nil
+}
+
+func (p *sessionAuthProvider) Reauthorize(ctx context.Context, req *http.Request) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 并发的请求可能已经重新登录过了，此时直接用新的凭据重试
+	if p.creds != nil && !p.creds.usedBy(req) {
+		return true, nil
+	}
+	if err := p.relogin(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// relogin 登录并保存得到的凭据，调用方必须持有 p.mu。
+func (p *sessionAuthProvider) relogin(ctx context.Context) error {
+	creds, err := p.login(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+	p.creds = creds
+	return nil
+}
+
+// usedBy 判断 req 是否携带了这组凭据。
+func (c *Credentials) usedBy(req *http.Request) bool {
+	if c.Token != "" {
+		return req.Header.Get("Authorization") == "Bearer "+c.Token
+	}
+	for _, cookie := range c.Cookies {
+		if sent, err := req.Cookie(cookie.Name); err != nil || sent.Value != cookie.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// authError 是获取凭据或重新认证失败的错误，它不会被重试策略重试
+type authError struct {
+	msg string
+	err error
+}
+
+func (e *authError) Error() string {
+	return fmt.Sprintf("%s: %v", e.msg, e.err)
+}
+
+func (e *authError) Unwrap() error {
+	return e.err
+}
+
+// authRoundTripper 是拦截器链中为请求添加凭据的一环。服务器以 401 拒绝请求时，
+// 没有请求体或请求体可以重新获取 (GetBody) 的请求会在 provider 重新认证后重试一次。
+type authRoundTripper struct {
+	provider AuthProvider
+	delegate http.RoundTripper
+}
+
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorized, err := rt.authorize(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rt.delegate.RoundTrip(authorized)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// 以流的方式发送的请求体无法重新发送
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	retry, err := rt.provider.Reauthorize(req.Context(), authorized)
+	if err != nil {
+		resp.Body.Close()
+		return nil, &authError{msg: "request was rejected as unauthorized and reauthorization failed", err: err}
+	}
+	if !retry {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	klog.V(4).InfoS("Retrying request with new credentials", "method", req.Method, "url", req.URL)
+	if authorized, err = rt.authorize(req); err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if authorized.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return rt.delegate.RoundTrip(authorized)
+}
+
+// authorize 返回添加了凭据的 req 的副本，RoundTripper 不能修改调用方的请求。
+func (rt *authRoundTripper) authorize(req *http.Request) (*http.Request, error) {
+	authorized := req.Clone(req.Context())
+	if err := rt.provider.Authorize(req.Context(), authorized); err != nil {
+		return nil, &authError{msg: "failed to get credentials", err: err}
+	}
+	return authorized, nil
+}
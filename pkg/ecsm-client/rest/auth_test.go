@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newAuthTestClient 创建一个连接到 handler、使用 p 认证的客户端。
+func newAuthTestClient(t *testing.T, p AuthProvider, handler http.HandlerFunc) *RESTClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	client, err := NewRESTClient(u.Scheme, u.Hostname(), u.Port(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+	client.SetAuthProvider(p)
+	return client
+}
+
+// TestBasicAuthProvider 测试每个请求都携带用户名和密码。
+func TestBasicAuthProvider(t *testing.T) {
+	client := newAuthTestClient(t, NewBasicAuthProvider("admin", "secret"), func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+	if err := client.Get().Resource("node").Do(context.Background()).Into(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+// TestSessionAuthProvider 测试会话在第一个请求之前登录，服务器拒绝 Cookie 后并发的请求只重新登录一次并重试成功。
+func TestSessionAuthProvider(t *testing.T) {
+	var mu sync.Mutex
+	valid := ""
+	var logins atomic.Int32
+	login := func(ctx context.Context) (*Credentials, error) {
+		n := logins.Add(1)
+		mu.Lock()
+		defer mu.Unlock()
+		valid = fmt.Sprintf("session-%d", n)
+		return &Credentials{Cookies: []*http.Cookie{{Name: "SESSION", Value: valid}}}, nil
+	}
+
+	client := newAuthTestClient(t, NewSessionAuthProvider(login), func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"node-1"}` {
+			t.Errorf("Server received body %q, want the JSON body on every attempt", body)
+		}
+		cookie, err := r.Cookie("SESSION")
+		mu.Lock()
+		ok := err == nil && cookie.Value == valid
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+	ctx := context.Background()
+	post := func() error {
+		return client.Post().Resource("node").Body(map[string]string{"name": "node-1"}).Do(ctx).Into(nil)
+	}
+
+	if err := post(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if n := logins.Load(); n != 1 {
+		t.Fatalf("Expected 1 login before the first request, got %d", n)
+	}
+
+	// 服务器使会话失效，并发的请求都会被拒绝一次
+	mu.Lock()
+	valid = "expired"
+	mu.Unlock()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := post(); err != nil {
+				t.Errorf("Request after the session expired failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := logins.Load(); n != 2 {
+		t.Errorf("Expected exactly 1 relogin for the concurrent requests, got %d logins in total", n)
+	}
+}
+
+// TestSessionAuthProvider_Expiry 测试凭据快要过期时在请求之前重新登录，以及登录失败时请求失败。
+func TestSessionAuthProvider_Expiry(t *testing.T) {
+	var logins atomic.Int32
+	fail := false
+	login := func(ctx context.Context) (*Credentials, error) {
+		if fail {
+			return nil, fmt.Errorf("invalid password")
+		}
+		n := logins.Add(1)
+		return &Credentials{Token: fmt.Sprintf("t%d", n), Expiry: time.Now().Add(30 * time.Second)}, nil
+	}
+	client := newAuthTestClient(t, NewSessionAuthProvider(login), func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer t") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := client.Get().Resource("node").Do(ctx).Into(nil); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+	if n := logins.Load(); n != 2 {
+		t.Errorf("Expected a login before each request because the token expires within the renew margin, got %d", n)
+	}
+
+	fail = true
+	err := client.Get().Resource("node").Do(ctx).Into(nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid password") {
+		t.Errorf("Expected the login error, got %v", err)
+	}
+}
+
+// TestTokenAuthProvider_Unauthorized 测试 Token 被拒绝时不重试，401 被交给调用方。
+func TestTokenAuthProvider_Unauthorized(t *testing.T) {
+	var requests atomic.Int32
+	client := newAuthTestClient(t, NewTokenAuthProvider(StaticTokenSource("revoked")), func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":401,"message":"token revoked"}`))
+	})
+	err := client.Get().Resource("node").Do(context.Background()).Into(nil)
+	var apiErr *Aerror
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		t.Errorf("Expected an unauthorized error, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected 1 request, got %d", n)
+	}
+}
@@ -0,0 +1,182 @@
+// file: pkg/ecsm-client/rest/cache.go
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxCacheEntries 是缓存的响应的最大数量，超过时丢弃最早保存的响应
+	maxCacheEntries = 128
+	// maxCachedBodyBytes 是被缓存的响应体的最大长度，更大的响应不被缓存
+	maxCachedBodyBytes = 4 << 20
+)
+
+// CacheResponses 返回缓存 GET 请求的成功响应的拦截器，用于 CLI 按名称查找资源时反复列出同一类资源的场景。
+//
+// 缓存以 URL 为键。保存时间不超过 ttl 的响应直接返回，不发送请求；超过 ttl 之后，
+// 如果响应带有 ETag 或 Last-Modified，就发送带有 If-None-Match 或 If-Modified-Since 的条件请求，
+// 服务器返回 304 时继续使用缓存的响应体，否则重新请求。
+// 只有 HTTP 状态码和 ECSM 响应信封中的 status 都是 200 的响应才被缓存。
+// 经过这个拦截器的任何非 GET 请求都会清空缓存，使之后的读取能看到修改的结果。
+func CacheResponses(ttl time.Duration) Middleware {
+	cache := &responseCache{ttl: ttl, entries: make(map[string]*cacheEntry)}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &cachingRoundTripper{cache: cache, delegate: next}
+	}
+}
+
+// cacheEntry 是一个被缓存的响应
+type cacheEntry struct {
+	header       http.Header
+	body         []byte
+	etag         string
+	lastModified string
+	stored       time.Time
+}
+
+// response 返回以 entry 为内容的 req 的响应。
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// responseCache 是 CacheResponses 返回的拦截器共用的缓存
+type responseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	// generation 在缓存被清空时增加，清空之前发出的请求的响应不再被保存
+	generation uint64
+}
+
+func (c *responseCache) get(key string) (*cacheEntry, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key], c.generation
+}
+
+func (c *responseCache) put(key string, entry *cacheEntry, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCacheEntries {
+		var oldest string
+		for k, e := range c.entries {
+			if oldest == "" || e.stored.Before(c.entries[oldest].stored) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = entry
+}
+
+func (c *responseCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.generation++
+}
+
+// cachingRoundTripper 用 cache 响应 GET 请求
+type cachingRoundTripper struct {
+	cache    *responseCache
+	delegate http.RoundTripper
+}
+
+func (rt *cachingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		// 修改可能部分成功，无论结果如何都清空缓存
+		defer rt.cache.clear()
+		return rt.delegate.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	entry, generation := rt.cache.get(key)
+	if entry != nil && time.Since(entry.stored) < rt.cache.ttl {
+		klog.V(6).InfoS("Serving response from cache", "url", key, "age", time.Since(entry.stored).Round(time.Millisecond))
+		return entry.response(req), nil
+	}
+	if entry != nil && (entry.etag != "" || entry.lastModified != "") {
+		req = req.Clone(req.Context())
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		revalidated := *entry
+		revalidated.stored = time.Now()
+		rt.cache.put(key, &revalidated, generation)
+		return revalidated.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBodyBytes+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedBodyBytes {
+		// 响应太大，把已经读取的部分和剩下的部分一起交给调用方
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if succeeded(body) {
+		rt.cache.put(key, &cacheEntry{
+			header:       resp.Header.Clone(),
+			body:         body,
+			etag:         resp.Header.Get("ETag"),
+			lastModified: resp.Header.Get("Last-Modified"),
+			stored:       time.Now(),
+		}, generation)
+	}
+	return resp, nil
+}
+
+// succeeded 判断响应体是否是 status 为 200 的 ECSM 响应信封，ECSM 的错误也可能以 HTTP 200 返回。
+func succeeded(body []byte) bool {
+	var envelope struct {
+		Status int `json:"status"`
+	}
+	return json.Unmarshal(body, &envelope) == nil && envelope.Status == http.StatusOK
+}
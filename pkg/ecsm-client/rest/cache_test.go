@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCacheResponses 测试新的响应直接从缓存返回，过期的响应通过条件请求重新验证，修改请求清空缓存。
+func TestCacheResponses(t *testing.T) {
+	var requests, notModified atomic.Int32
+	version := "v1"
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method != http.MethodGet {
+			version = "v2"
+			w.Write([]byte(`{"status":200,"message":"success"}`))
+			return
+		}
+		etag := `"` + version + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"status":200,"message":"success","data":{"version":"` + version + `"}}`))
+	})
+	const ttl = 100 * time.Millisecond
+	client.Use(CacheResponses(ttl))
+	ctx := context.Background()
+
+	get := func() string {
+		var data struct {
+			Version string `json:"version"`
+		}
+		if err := client.Get().Resource("service").Param("pageNum", "1").Do(ctx).Into(&data); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return data.Version
+	}
+
+	get()
+	if v := get(); v != "v1" || requests.Load() != 1 {
+		t.Fatalf("Expected the second read to be served from the cache, got %s after %d requests", v, requests.Load())
+	}
+
+	time.Sleep(ttl)
+	if v := get(); v != "v1" || notModified.Load() != 1 {
+		t.Fatalf("Expected the expired response to be revalidated, got %s and %d 304 responses", v, notModified.Load())
+	}
+	if get(); requests.Load() != 2 {
+		t.Errorf("Expected the revalidated response to be fresh again, got %d requests", requests.Load())
+	}
+
+	if err := client.Post().Resource("service").Body(map[string]string{"name": "web"}).Do(ctx).Into(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if v := get(); v != "v2" {
+		t.Errorf("Expected a write to invalidate the cache, got %s", v)
+	}
+}
+
+// TestCacheResponses_Errors 测试错误的响应不被缓存，包括以 HTTP 200 返回的 ECSM 错误。
+func TestCacheResponses_Errors(t *testing.T) {
+	var requests atomic.Int32
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"status":500,"message":"database busy"}`))
+	})
+	client.Use(CacheResponses(time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if err := client.Get().Resource("node").Do(context.Background()).Into(nil); err == nil {
+			t.Fatalf("Expected an error")
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("Expected every failed read to be sent, got %d requests", n)
+	}
+}
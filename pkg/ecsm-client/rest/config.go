@@ -0,0 +1,186 @@
+// file: pkg/ecsm-client/rest/config.go
+
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// Config 是连接 ECSM API Server 所需的设置，RESTClientFor 按照它创建 RESTClient。
+type Config struct {
+	// Protocol 是 http 或 https
+	Protocol string
+	Host     string
+	Port     string
+
+	// APIPath 和 APIVersion 是接口 URL 中资源路径之前的部分，为空时使用 "api" 和 "v1"，参见 RESTClient.SetAPIVersion
+	APIPath    string
+	APIVersion string
+
+	// TLS 是 https 连接的设置，Protocol 为 http 时必须为空
+	TLS TLSConfig
+
+	// QPS 和 Burst 是客户端每秒平均发出的最大请求数和允许的突发请求数，QPS 为 0 表示不限制，
+	// 参见 RESTClient.SetRateLimit
+	QPS   float32
+	Burst int
+
+	// Proxy 返回请求使用的代理，为 nil 时按照 HTTP_PROXY、HTTPS_PROXY 和 NO_PROXY 环境变量选择代理。
+	// 代理可以是 http、https 或 socks5 代理，例如在跳板机上用 "ssh -D" 建立的 SOCKS5 代理，参见 ProxyURL
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// Dial 不为 nil 时代替 net.Dialer 建立到服务器或代理的 TCP 连接，例如经过 SSH 跳板机的隧道建立连接
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+
+	// HTTPClient 不为 nil 时用于发送请求，此时 TLS、Proxy 和 Dial 必须为空，由调用方自己配置 Transport
+	HTTPClient *http.Client
+}
+
+// ProxyURL 返回所有请求都使用代理 raw 的 Config.Proxy，raw 为空时返回 nil，即按照环境变量选择代理。
+func ProxyURL(raw string) (func(*http.Request) (*url.URL, error), error) {
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy URL %q, the scheme must be http, https or socks5", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// TLSConfig 是 https 连接的设置。文件和数据同时设置时使用数据。
+type TLSConfig struct {
+	// CAFile 和 CAData 是验证服务器证书的 PEM 格式的 CA 证书，为空时使用系统的根证书
+	CAFile string
+	CAData []byte
+
+	// CertFile、KeyFile 和 CertData、KeyData 是向服务器证明身份的 PEM 格式的客户端证书和私钥，必须成对设置
+	CertFile string
+	KeyFile  string
+	CertData []byte
+	KeyData  []byte
+
+	// ServerName 不为空时代替 Host 用于验证服务器证书，用于通过 IP 地址或代理访问服务器的情况
+	ServerName string
+
+	// Insecure 为 true 时不验证服务器证书，只应该用于测试。它不能和 CA 证书同时设置
+	Insecure bool
+}
+
+// IsZero 返回是否没有设置任何 TLS 选项。
+func (c *TLSConfig) IsZero() bool {
+	return c.CAFile == "" && len(c.CAData) == 0 &&
+		c.CertFile == "" && c.KeyFile == "" && len(c.CertData) == 0 && len(c.KeyData) == 0 &&
+		c.ServerName == "" && !c.Insecure
+}
+
+// ClientConfig 读取证书并返回对应的 tls.Config，没有设置任何 TLS 选项时返回 nil。
+func (c *TLSConfig) ClientConfig() (*tls.Config, error) {
+	if c.IsZero() {
+		return nil, nil
+	}
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.Insecure,
+	}
+
+	caData, err := dataOrFile(c.CAData, c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	if len(caData) > 0 {
+		if c.Insecure {
+			return nil, fmt.Errorf("a CA certificate cannot be used when server certificate verification is skipped")
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no PEM certificates found in CA certificate %s", describeSource(c.CAFile, c.CAData))
+		}
+	}
+
+	certData, err := dataOrFile(c.CertData, c.CertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	keyData, err := dataOrFile(c.KeyData, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client key: %w", err)
+	}
+	switch {
+	case len(certData) > 0 && len(keyData) > 0:
+		cert, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	case len(certData) > 0 || len(keyData) > 0:
+		return nil, fmt.Errorf("client certificate and key must be specified together")
+	}
+	return config, nil
+}
+
+// dataOrFile 返回 data，data 为空时读取 path。
+func dataOrFile(data []byte, path string) ([]byte, error) {
+	if len(data) > 0 || path == "" {
+		return data, nil
+	}
+	return os.ReadFile(path)
+}
+
+// describeSource 返回错误信息中用于指代证书来源的文字。
+func describeSource(path string, data []byte) string {
+	if len(data) > 0 || path == "" {
+		return "data"
+	}
+	return path
+}
+
+// RESTClientFor 按照 config 创建 RESTClient。设置了 TLS、Proxy 或 Dial 时使用独立的 Transport，不影响 http.DefaultTransport。
+func RESTClientFor(config *Config) (*RESTClient, error) {
+	if !config.TLS.IsZero() && config.Protocol != "https" {
+		return nil, fmt.Errorf("TLS options require protocol https, got %q", config.Protocol)
+	}
+	httpClient := config.HTTPClient
+	if !config.TLS.IsZero() || config.Proxy != nil || config.Dial != nil {
+		if httpClient != nil {
+			return nil, fmt.Errorf("TLS, proxy and dial options cannot be used with a custom HTTP client")
+		}
+		tlsConfig, err := config.TLS.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		// 克隆的 Transport 保留了 http.DefaultTransport 按照环境变量选择代理的行为
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		if config.Proxy != nil {
+			transport.Proxy = config.Proxy
+		}
+		if config.Dial != nil {
+			transport.DialContext = config.Dial
+		}
+		httpClient = &http.Client{Transport: transport}
+	}
+	client, err := NewRESTClient(config.Protocol, config.Host, config.Port, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	client.SetAPIVersion(config.APIPath, config.APIVersion)
+	client.SetRateLimit(config.QPS, config.Burst)
+	return client, nil
+}
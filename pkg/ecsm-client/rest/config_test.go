@@ -0,0 +1,257 @@
+package rest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTLSTestServer 启动一个 https 服务器，返回它和 PEM 格式的服务器证书。
+// httptest 的证书对 example.com 和 127.0.0.1 有效。
+func newTLSTestServer(t *testing.T, clientCAs *x509.CertPool) (*httptest.Server, []byte) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	}))
+	// 被拒绝的握手是预期的，不输出服务器的日志
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	if clientCAs != nil {
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, caData
+}
+
+// configFor 返回连接到 server 的 Config。
+func configFor(t *testing.T, server *httptest.Server, tlsConfig TLSConfig) *Config {
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	return &Config{Protocol: u.Scheme, Host: u.Hostname(), Port: u.Port(), TLS: tlsConfig}
+}
+
+// newClientCert 生成自签名的客户端证书，返回 PEM 格式的证书和私钥。
+func newClientCert(t *testing.T) (certData, keyData []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ecsm-operator"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// TestRESTClientFor_ServerVerification 测试 CA 证书、服务器名称和跳过验证的设置。
+func TestRESTClientFor_ServerVerification(t *testing.T) {
+	server, caData := newTLSTestServer(t, nil)
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(caFile, caData, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr string
+	}{
+		{name: "system roots", wantErr: "certificate"},
+		{name: "CA file", tls: TLSConfig{CAFile: caFile}},
+		{name: "CA data", tls: TLSConfig{CAData: caData}},
+		{name: "server name", tls: TLSConfig{CAData: caData, ServerName: "example.com"}},
+		{name: "wrong server name", tls: TLSConfig{CAData: caData, ServerName: "ecsm.example.org"}, wantErr: "ecsm.example.org"},
+		{name: "insecure", tls: TLSConfig{Insecure: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := RESTClientFor(configFor(t, server, tc.tls))
+			if err != nil {
+				t.Fatalf("RESTClientFor failed: %v", err)
+			}
+			err = client.Get().Resource("node").Do(context.Background()).Into(nil)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("Request failed: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("Expected an error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestRESTClientFor_ClientCertificate 测试要求客户端证书的服务器只接受带着证书的请求。
+func TestRESTClientFor_ClientCertificate(t *testing.T) {
+	certData, keyData := newClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(certData)
+	server, caData := newTLSTestServer(t, clientCAs)
+
+	client, err := RESTClientFor(configFor(t, server, TLSConfig{CAData: caData, CertData: certData, KeyData: keyData}))
+	if err != nil {
+		t.Fatalf("RESTClientFor failed: %v", err)
+	}
+	if err := client.Get().Resource("node").Do(context.Background()).Into(nil); err != nil {
+		t.Errorf("Request with the client certificate failed: %v", err)
+	}
+
+	client, err = RESTClientFor(configFor(t, server, TLSConfig{CAData: caData}))
+	if err != nil {
+		t.Fatalf("RESTClientFor failed: %v", err)
+	}
+	if err := client.Get().Resource("node").Do(context.Background()).Into(nil); err == nil {
+		t.Errorf("Expected the request without a client certificate to fail")
+	}
+}
+
+// TestRESTClientFor_InvalidConfig 测试不合法的设置在创建客户端时报错。
+func TestRESTClientFor_InvalidConfig(t *testing.T) {
+	certData, keyData := newClientCert(t)
+	testCases := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "TLS over http", config: Config{Protocol: "http", TLS: TLSConfig{Insecure: true}}, wantErr: "require protocol https"},
+		{name: "custom HTTP client", config: Config{Protocol: "https", TLS: TLSConfig{Insecure: true}, HTTPClient: &http.Client{}}, wantErr: "custom HTTP client"},
+		{name: "proxy with custom HTTP client", config: Config{Protocol: "http", Proxy: http.ProxyFromEnvironment, HTTPClient: &http.Client{}}, wantErr: "custom HTTP client"},
+		{name: "missing CA file", config: Config{Protocol: "https", TLS: TLSConfig{CAFile: "/nonexistent/ca.crt"}}, wantErr: "failed to read CA certificate"},
+		{name: "invalid CA", config: Config{Protocol: "https", TLS: TLSConfig{CAData: []byte("not a certificate")}}, wantErr: "no PEM certificates"},
+		{name: "CA with insecure", config: Config{Protocol: "https", TLS: TLSConfig{CAData: certData, Insecure: true}}, wantErr: "cannot be used"},
+		{name: "certificate without key", config: Config{Protocol: "https", TLS: TLSConfig{CertData: certData}}, wantErr: "specified together"},
+		{name: "truncated key", config: Config{Protocol: "https", TLS: TLSConfig{CertData: certData, KeyData: keyData[:len(keyData)/2]}}, wantErr: "failed to load client certificate"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.Host, tc.config.Port = "localhost", "3001"
+			_, err := RESTClientFor(&tc.config)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestRESTClientFor_Proxy 测试请求经过 Proxy 指定的代理发送。
+func TestRESTClientFor_Proxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 代理收到的是绝对 URL，服务器的主机名只有代理能解析
+		proxied.Store(r.URL.String())
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	}))
+	t.Cleanup(proxy.Close)
+
+	proxyFunc, err := ProxyURL(proxy.URL)
+	if err != nil {
+		t.Fatalf("ProxyURL failed: %v", err)
+	}
+	client, err := RESTClientFor(&Config{Protocol: "http", Host: "ecsm.internal", Port: "3001", Proxy: proxyFunc})
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+	if err := client.Get().Resource("node").Do(context.Background()).Into(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if got, _ := proxied.Load().(string); got != "http://ecsm.internal:3001/api/v1/node" {
+		t.Errorf("Proxy received %q", got)
+	}
+
+	for _, raw := range []string{"ftp://jump:21", "socks5://", "://bad"} {
+		if _, err := ProxyURL(raw); err == nil {
+			t.Errorf("Expected an error for proxy URL %q", raw)
+		}
+	}
+}
+
+// TestRESTClientFor_Dial 测试 Dial 代替默认的拨号建立连接。
+func TestRESTClientFor_Dial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var dialed atomic.Value
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed.Store(address)
+		// 模拟经过跳板机的隧道：任何地址都连接到测试服务器
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	client, err := RESTClientFor(&Config{Protocol: "http", Host: "ecsm.internal", Port: "3001", Dial: dial})
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+	if err := client.Get().Resource("node").Do(context.Background()).Into(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if got, _ := dialed.Load().(string); got != "ecsm.internal:3001" {
+		t.Errorf("Dial was called with %q", got)
+	}
+}
+
+// TestRESTClientFor_APIVersion 测试请求发往配置的 API 路径和版本，未配置时使用 /api/v1。
+func TestRESTClientFor_APIVersion(t *testing.T) {
+	var gotPath atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath.Store(r.URL.Path)
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+
+	testCases := []struct {
+		apiPath, apiVersion string
+		want                string
+	}{
+		{"", "", "/api/v1/node/n1"},
+		{"", "v2", "/api/v2/node/n1"},
+		{"/ecsm/api/", "v2", "/ecsm/api/v2/node/n1"},
+	}
+	for _, tc := range testCases {
+		client, err := RESTClientFor(&Config{Protocol: "http", Host: u.Hostname(), Port: u.Port(), APIPath: tc.apiPath, APIVersion: tc.apiVersion})
+		if err != nil {
+			t.Fatalf("Failed to create REST client: %v", err)
+		}
+		if err := client.Get().Resource("node").Name("n1").Do(context.Background()).Into(nil); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if got, _ := gotPath.Load().(string); got != tc.want {
+			t.Errorf("APIPath %q and APIVersion %q sent the request to %q, want %q", tc.apiPath, tc.apiVersion, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// file: pkg/ecsm-client/rest/debug.go
+
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// DebugLevel 决定 debuggingRoundTripper 记录请求的哪些内容，级别越高记录的越多。
+type DebugLevel int
+
+const (
+	// DebugNone 不记录任何内容
+	DebugNone DebugLevel = iota
+	// DebugURLTiming 记录请求的方法、URL、响应的状态码和耗时
+	DebugURLTiming
+	// DebugHeaders 额外记录请求和响应的头
+	DebugHeaders
+	// DebugBodies 额外记录请求体和响应体，超过 maxDebugBodyBytes 的部分被截断
+	DebugBodies
+	// DebugFullBodies 记录完整的请求体和响应体
+	DebugFullBodies
+)
+
+// maxDebugBodyBytes 是 DebugBodies 级别下记录的请求体和响应体的最大长度
+const maxDebugBodyBytes = 10240
+
+// DebugLevelFromVerbosity 按照 klog 的日志级别返回 DebugLevel，与 kubectl 一致：
+// -v=6 记录 URL 和耗时，-v=7 记录头，-v=8 记录截断的请求体和响应体，-v=9 记录完整的请求体和响应体。
+func DebugLevelFromVerbosity() DebugLevel {
+	switch {
+	case klog.V(9).Enabled():
+		return DebugFullBodies
+	case klog.V(8).Enabled():
+		return DebugBodies
+	case klog.V(7).Enabled():
+		return DebugHeaders
+	case klog.V(6).Enabled():
+		return DebugURLTiming
+	}
+	return DebugNone
+}
+
+// maskedHeaders 是记录时被隐藏的头，它们包含登录凭据
+var maskedHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// passwordPattern 匹配 JSON 中的 password 字段，节点和镜像的接口会在请求体和响应体中携带密码
+var passwordPattern = regexp.MustCompile(`("password"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// debuggingRoundTripper 在发送请求前后记录请求和响应，用于排查 CLI 和 operator 与 ECSM API Server 之间的问题。
+type debuggingRoundTripper struct {
+	delegate http.RoundTripper
+	level    DebugLevel
+	// out 为 nil 时通过 klog 记录
+	out io.Writer
+}
+
+// NewDebuggingRoundTripper 返回一个按 level 记录每个请求和响应的 RoundTripper，rt 为 nil 时使用 http.DefaultTransport。
+// out 为 nil 时记录到 klog，否则直接写入 out。认证相关的头和 JSON 中的密码不会被记录。
+func NewDebuggingRoundTripper(rt http.RoundTripper, level DebugLevel, out io.Writer) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &debuggingRoundTripper{delegate: rt, level: level, out: out}
+}
+
+func (d *debuggingRoundTripper) logf(format string, args ...interface{}) {
+	if d.out == nil {
+		klog.InfoDepth(1, fmt.Sprintf(format, args...))
+		return
+	}
+	fmt.Fprintf(d.out, format+"\n", args...)
+}
+
+// RoundTrip 在 DebugURLTiming 级别下为每个请求记录一行，更高的级别先记录请求，再记录响应。
+func (d *debuggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if d.level >= DebugHeaders {
+		d.logf("%s %s", req.Method, req.URL)
+		d.logf("Request Headers:")
+		d.logHeaders(req.Header)
+	}
+	if d.level >= DebugBodies && req.Body != nil && req.Body != http.NoBody {
+		if isTextContent(req.Header.Get("Content-Type")) {
+			body, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			// RoundTripper 不能修改调用方的请求
+			req = req.Clone(req.Context())
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			d.logf("Request Body: %s", d.formatBody(body))
+		} else {
+			// 上传的文件以流的方式发送，不读入内存
+			d.logf("Request Body: <%s body omitted>", req.Header.Get("Content-Type"))
+		}
+	}
+
+	start := time.Now()
+	resp, err := d.delegate.RoundTrip(req)
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		d.logf("%s %s failed in %d milliseconds: %v", req.Method, req.URL, elapsed, err)
+		return nil, err
+	}
+	if d.level < DebugHeaders {
+		d.logf("%s %s %s in %d milliseconds", req.Method, req.URL, resp.Status, elapsed)
+		return resp, nil
+	}
+
+	d.logf("Response Status: %s in %d milliseconds", resp.Status, elapsed)
+	d.logf("Response Headers:")
+	d.logHeaders(resp.Header)
+	if d.level >= DebugBodies && isTextContent(resp.Header.Get("Content-Type")) {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		d.logf("Response Body: %s", d.formatBody(body))
+	}
+	return resp, nil
+}
+
+// logHeaders 按名称顺序记录 h，隐藏认证相关的头。
+func (d *debuggingRoundTripper) logHeaders(h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range h[name] {
+			if maskedHeaders[http.CanonicalHeaderKey(name)] {
+				value = "<masked>"
+			}
+			d.logf("    %s: %s", name, value)
+		}
+	}
+}
+
+// formatBody 隐藏 body 中的密码，并在 DebugBodies 级别下截断过长的 body。
+func (d *debuggingRoundTripper) formatBody(body []byte) string {
+	s := passwordPattern.ReplaceAllString(string(body), `$1"<masked>"`)
+	if d.level < DebugFullBodies && len(s) > maxDebugBodyBytes {
+		return fmt.Sprintf("%s [truncated %d chars]", s[:maxDebugBodyBytes], len(s)-maxDebugBodyBytes)
+	}
+	return s
+}
+
+// isTextContent 判断 contentType 的内容是否可以作为文本记录，没有 Content-Type 时当作文本。
+func isTextContent(contentType string) bool {
+	return contentType == "" ||
+		strings.Contains(contentType, "json") ||
+		strings.HasPrefix(contentType, "text/")
+}
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newDebugTestClient 创建一个连接到 handler 的客户端，它把 level 级别的调试信息写入返回的 buffer。
+func newDebugTestClient(t *testing.T, level DebugLevel, handler http.HandlerFunc) (*RESTClient, *bytes.Buffer) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	client, err := NewRESTClient(u.Scheme, u.Hostname(), u.Port(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+	out := &bytes.Buffer{}
+	client.SetDebugLevel(level, out)
+	return client, out
+}
+
+// TestDebuggingRoundTripper 测试各个级别记录的内容，以及记录请求体和响应体之后请求仍然正常。
+func TestDebuggingRoundTripper(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"name":"node-1"`) {
+			t.Errorf("Server received unexpected body %q", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"status":200,"message":"success","data":{"id":"n1","password":"p@ss"}}`))
+	}
+	req := struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}{Name: "node-1", Password: "hunter2"}
+
+	testCases := []struct {
+		name     string
+		level    DebugLevel
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "URLTiming",
+			level:    DebugURLTiming,
+			contains: []string{"POST http://", "/api/v1/node 200 OK in "},
+			excludes: []string{"Request Headers:", "Request Body:"},
+		},
+		{
+			name:     "Headers",
+			level:    DebugHeaders,
+			contains: []string{"Request Headers:", "Content-Type: application/json", "Response Status: 200 OK in ", "Set-Cookie: <masked>"},
+			excludes: []string{"session=secret", "Response Body:"},
+		},
+		{
+			name:  "Bodies",
+			level: DebugBodies,
+			contains: []string{
+				`Request Body: {"name":"node-1","password":"<masked>"}`,
+				`Response Body: {"status":200,"message":"success","data":{"id":"n1","password":"<masked>"}}`,
+			},
+			excludes: []string{"hunter2", "p@ss"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, out := newDebugTestClient(t, tc.level, handler)
+
+			var result struct {
+				ID string `json:"id"`
+			}
+			if err := client.Post().Resource("node").Body(req).Do(context.Background()).Into(&result); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if result.ID != "n1" {
+				t.Errorf("Expected the response to be decoded after logging, got ID %q", result.ID)
+			}
+
+			log := out.String()
+			for _, s := range tc.contains {
+				if !strings.Contains(log, s) {
+					t.Errorf("Expected debug output to contain %q, got:\n%s", s, log)
+				}
+			}
+			for _, s := range tc.excludes {
+				if strings.Contains(log, s) {
+					t.Errorf("Expected debug output not to contain %q, got:\n%s", s, log)
+				}
+			}
+		})
+	}
+}
+
+// TestDebuggingRoundTripper_Truncate 测试 DebugBodies 级别截断过长的响应体，DebugFullBodies 级别记录完整的响应体。
+func TestDebuggingRoundTripper_Truncate(t *testing.T) {
+	long := strings.Repeat("x", maxDebugBodyBytes)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":200,"message":"success","data":"` + long + `"}`))
+	}
+
+	client, out := newDebugTestClient(t, DebugBodies, handler)
+	if _, err := client.Get().Resource("node").Do(context.Background()).Raw(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "[truncated ") {
+		t.Errorf("Expected the response body to be truncated, got:\n%s", out.String())
+	}
+
+	client, out = newDebugTestClient(t, DebugFullBodies, handler)
+	if _, err := client.Get().Resource("node").Do(context.Background()).Raw(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if strings.Contains(out.String(), "[truncated ") || !strings.Contains(out.String(), long+`"}`) {
+		t.Errorf("Expected the full response body, got %d bytes of output", out.Len())
+	}
+}
@@ -0,0 +1,101 @@
+// file: pkg/ecsm-client/rest/errors.go
+
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statusReasons 把 ECSM 错误的状态码映射为 apimachinery 的 StatusReason，其它状态码的原因是 StatusReasonUnknown
+var statusReasons = map[int]metav1.StatusReason{
+	http.StatusBadRequest:            metav1.StatusReasonBadRequest,
+	http.StatusUnauthorized:          metav1.StatusReasonUnauthorized,
+	http.StatusForbidden:             metav1.StatusReasonForbidden,
+	http.StatusNotFound:              metav1.StatusReasonNotFound,
+	http.StatusMethodNotAllowed:      metav1.StatusReasonMethodNotAllowed,
+	http.StatusNotAcceptable:         metav1.StatusReasonNotAcceptable,
+	http.StatusConflict:              metav1.StatusReasonConflict,
+	http.StatusGone:                  metav1.StatusReasonGone,
+	http.StatusRequestEntityTooLarge: metav1.StatusReasonRequestEntityTooLarge,
+	http.StatusUnsupportedMediaType:  metav1.StatusReasonUnsupportedMediaType,
+	http.StatusUnprocessableEntity:   metav1.StatusReasonInvalid,
+	http.StatusTooManyRequests:       metav1.StatusReasonTooManyRequests,
+	http.StatusInternalServerError:   metav1.StatusReasonInternalError,
+	http.StatusServiceUnavailable:    metav1.StatusReasonServiceUnavailable,
+	http.StatusGatewayTimeout:        metav1.StatusReasonTimeout,
+}
+
+// ToStatusError 把错误链中的 *Aerror 转换为 apimachinery 的 *apierrors.StatusError，
+// 使 ECSM 的错误可以和 Registry 的错误一样用 apierrors.IsNotFound 等函数判断。
+// ECSM 的字段错误被保存在 Details.Causes 中。err 中没有 *Aerror 时原样返回 err。
+func ToStatusError(err error) error {
+	var aerr *Aerror
+	if !errors.As(err, &aerr) {
+		return err
+	}
+	message := aerr.Message
+	if message == "" {
+		message = http.StatusText(aerr.Status)
+	}
+	reason, ok := statusReasons[aerr.Status]
+	if !ok {
+		reason = metav1.StatusReasonUnknown
+	}
+	status := metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    int32(aerr.Status),
+		Reason:  reason,
+		Message: message,
+	}
+	if aerr.FieldErrors != "" {
+		status.Details = &metav1.StatusDetails{
+			Causes: []metav1.StatusCause{{Type: metav1.CauseTypeFieldValueInvalid, Message: aerr.FieldErrors}},
+		}
+	}
+	return &apierrors.StatusError{ErrStatus: status}
+}
+
+// IsNotFound 判断 err 是否表示 ECSM 平台上的资源不存在。
+func IsNotFound(err error) bool {
+	return apierrors.IsNotFound(ToStatusError(err))
+}
+
+// IsConflict 判断 err 是否表示请求与 ECSM 平台上资源的当前状态冲突，例如名称已经被使用。
+func IsConflict(err error) bool {
+	return apierrors.IsConflict(ToStatusError(err))
+}
+
+// IsUnauthorized 判断 err 是否表示 ECSM API Server 拒绝了请求的凭据。
+func IsUnauthorized(err error) bool {
+	return apierrors.IsUnauthorized(ToStatusError(err))
+}
+
+// IsForbidden 判断 err 是否表示请求的用户没有执行这个操作的权限。
+func IsForbidden(err error) bool {
+	return apierrors.IsForbidden(ToStatusError(err))
+}
+
+// IsBadRequest 判断 err 是否表示 ECSM API Server 认为请求的格式不正确。
+func IsBadRequest(err error) bool {
+	return apierrors.IsBadRequest(ToStatusError(err))
+}
+
+// IsInvalid 判断 err 是否表示请求中的字段没有通过 ECSM API Server 的校验。
+func IsInvalid(err error) bool {
+	return apierrors.IsInvalid(ToStatusError(err))
+}
+
+// IsTooManyRequests 判断 err 是否表示 ECSM API Server 因为请求过多而拒绝了请求。
+func IsTooManyRequests(err error) bool {
+	return apierrors.IsTooManyRequests(ToStatusError(err))
+}
+
+// IsServerError 判断 err 是否是 ECSM API Server 的 5xx 错误，这类错误通常是暂时性的。
+func IsServerError(err error) bool {
+	var aerr *Aerror
+	return errors.As(err, &aerr) && aerr.Status >= http.StatusInternalServerError && aerr.Status < 600
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestToStatusError 测试 ECSM 的错误被转换为对应原因的 StatusError，包装过的错误也能被识别。
+func TestToStatusError(t *testing.T) {
+	testCases := []struct {
+		status int
+		check  func(error) bool
+		reason metav1.StatusReason
+	}{
+		{http.StatusNotFound, IsNotFound, metav1.StatusReasonNotFound},
+		{http.StatusConflict, IsConflict, metav1.StatusReasonConflict},
+		{http.StatusUnauthorized, IsUnauthorized, metav1.StatusReasonUnauthorized},
+		{http.StatusForbidden, IsForbidden, metav1.StatusReasonForbidden},
+		{http.StatusBadRequest, IsBadRequest, metav1.StatusReasonBadRequest},
+		{http.StatusUnprocessableEntity, IsInvalid, metav1.StatusReasonInvalid},
+		{http.StatusTooManyRequests, IsTooManyRequests, metav1.StatusReasonTooManyRequests},
+		{http.StatusServiceUnavailable, IsServerError, metav1.StatusReasonServiceUnavailable},
+	}
+	for _, tc := range testCases {
+		err := fmt.Errorf("failed to get service: %w", &Aerror{Status: tc.status, Message: "boom"})
+		if !tc.check(err) {
+			t.Errorf("Status %d was not recognized", tc.status)
+		}
+		if got := apierrors.ReasonForError(ToStatusError(err)); got != tc.reason {
+			t.Errorf("Status %d was mapped to reason %q, want %q", tc.status, got, tc.reason)
+		}
+	}
+
+	if IsNotFound(&Aerror{Status: http.StatusInternalServerError}) || IsNotFound(errors.New("not found")) || IsNotFound(nil) {
+		t.Errorf("Expected only ECSM 404 errors to be recognized as not found")
+	}
+
+	err := ToStatusError(&Aerror{Status: http.StatusBadRequest, Message: "invalid service", FieldErrors: "name: too long"})
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || statusErr.ErrStatus.Message != "invalid service" ||
+		statusErr.ErrStatus.Details == nil || statusErr.ErrStatus.Details.Causes[0].Message != "name: too long" {
+		t.Errorf("Unexpected status error %#v", err)
+	}
+}
+
+// TestIsNotFound_Response 测试服务器返回的错误响应可以直接用 IsNotFound 判断。
+func TestIsNotFound_Response(t *testing.T) {
+	fake := NewFake()
+	fake.AddReactor("GET", "service/*", RespondWithError(http.StatusNotFound, "service not found"))
+
+	err := fake.Get().Resource("service").Name("missing").Do(context.Background()).Into(nil)
+	if !IsNotFound(err) || IsConflict(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
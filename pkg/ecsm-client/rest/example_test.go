@@ -56,13 +56,12 @@ func ExampleRESTClient() {
 }
 
 // TestManualRESTClient 手动测试函数，可以用来快速验证与真实 API 的连接
-// 运行方式: go test -v -run TestManualRESTClient
+// 运行方式: ECSM_TEST_SERVER=<host>:<port> go test -v -run TestManualRESTClient
 func TestManualRESTClient(t *testing.T) {
-	// 如果不想运行真实 API 测试，可以跳过
-	// t.Skip("Manual test - uncomment to run against real ECSM API")
+	host, port := realServer(t)
 
 	// 创建客户端
-	client, err := NewRESTClient("http", "192.168.31.129", "3001", &http.Client{
+	client, err := NewRESTClient("http", host, port, &http.Client{
 		Timeout: 10 * time.Second,
 	})
 	if err != nil {
@@ -115,11 +114,11 @@ func TestManualRESTClient(t *testing.T) {
 	}
 }
 
-// BenchmarkRESTClient_GetServices 性能测试
+// BenchmarkRESTClient_GetServices 性能测试，与 TestManualRESTClient 一样需要设置 ECSM_TEST_SERVER
 func BenchmarkRESTClient_GetServices(b *testing.B) {
-	// b.Skip("Benchmark test - uncomment to run performance test against real ECSM API")
+	host, port := realServer(b)
 
-	client, err := NewRESTClient("http", "192.168.31.129", "3001", &http.Client{
+	client, err := NewRESTClient("http", host, port, &http.Client{
 		Timeout: 10 * time.Second,
 	})
 	if err != nil {
@@ -0,0 +1,176 @@
+// file: pkg/ecsm-client/rest/fake.go
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Fake 是用于单元测试的客户端，它不连接 ECSM API Server，而是由按 verb 和路径匹配的 reactor 生成响应，
+// 并记录收到的每个请求。Fake.RESTClient 是一个普通的 RESTClient，请求经过与连接真实服务器时相同的
+// 编码、解码、认证和重试逻辑，因此可以用它创建 Clientset 来测试 Clientset 和控制器。
+type Fake struct {
+	*RESTClient
+
+	mu       sync.Mutex
+	reactors []fakeReactor
+	actions  []Action
+}
+
+// Action 是 Fake 收到的一个请求。
+type Action struct {
+	Verb string
+	// APIVersion 是请求的 URL 中的 API 版本，例如 "v1"
+	APIVersion string
+	// Path 是 Resource、Name 和 Subresource 组成的路径，例如 "service/0f4b1c"
+	Path  string
+	Query url.Values
+	Body  []byte
+}
+
+// Matches 判断 action 是否匹配 verb 和 path。verb 为 "*" 时匹配任何方法，
+// path 是 path.Match 的模式，例如 "service/*"，为 "*" 时匹配任何路径。
+func (a Action) Matches(verb, pattern string) bool {
+	if verb != "*" && !strings.EqualFold(verb, a.Verb) {
+		return false
+	}
+	if pattern == "*" {
+		return true
+	}
+	ok, err := path.Match(pattern, a.Path)
+	return err == nil && ok
+}
+
+// ReactionFunc 为匹配的请求生成响应。handled 为 false 时请求交给下一个匹配的 reactor，
+// 返回的 err 被当作没有得到响应的连接失败。
+type ReactionFunc func(action Action) (handled bool, resp *http.Response, err error)
+
+type fakeReactor struct {
+	verb, path string
+	reaction   ReactionFunc
+}
+
+// NewFake 返回没有 reactor 的 Fake，没有 reactor 处理的请求以连接失败的错误结束。
+func NewFake() *Fake {
+	f := &Fake{}
+	// 主机名不会被解析，所有请求都由 RoundTrip 处理
+	client, err := NewRESTClient("http", "ecsm.fake", "80", &http.Client{Transport: f})
+	if err != nil {
+		panic(err)
+	}
+	f.RESTClient = client
+	return f
+}
+
+// AddReactor 在已有的 reactor 之后添加一个 reactor，参数的含义参见 Action.Matches。
+func (f *Fake) AddReactor(verb, path string, reaction ReactionFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reactors = append(f.reactors, fakeReactor{verb: verb, path: path, reaction: reaction})
+}
+
+// PrependReactor 在已有的 reactor 之前添加一个 reactor，用于在个别测试中覆盖公共的响应。
+func (f *Fake) PrependReactor(verb, path string, reaction ReactionFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reactors = append([]fakeReactor{{verb: verb, path: path, reaction: reaction}}, f.reactors...)
+}
+
+// Actions 返回 Fake 按顺序收到的所有请求，包括重试的请求。
+func (f *Fake) Actions() []Action {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Action(nil), f.actions...)
+}
+
+// ClearActions 清空记录的请求。
+func (f *Fake) ClearActions() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = nil
+}
+
+// RoundTrip 实现了 http.RoundTripper，它把请求交给第一个匹配并处理了它的 reactor。
+func (f *Fake) RoundTrip(req *http.Request) (*http.Response, error) {
+	action := Action{
+		Verb:  req.Method,
+		Query: req.URL.Query(),
+	}
+	// URL 的路径是 /<apiPath>/<version>/<path>，Clientset 复制的客户端可能设置了不同的版本
+	p := strings.TrimPrefix(req.URL.Path, "/"+f.apiPath+"/")
+	action.APIVersion, action.Path, _ = strings.Cut(p, "/")
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		action.Body = body
+	}
+
+	f.mu.Lock()
+	f.actions = append(f.actions, action)
+	reactors := f.reactors
+	f.mu.Unlock()
+
+	// reactor 在锁之外调用，它们可以添加新的 reactor 或查看已经收到的请求
+	for _, r := range reactors {
+		if !action.Matches(r.verb, r.path) {
+			continue
+		}
+		handled, resp, err := r.reaction(action)
+		if !handled {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Request = req
+		return resp, nil
+	}
+	return nil, fmt.Errorf("no reactor handles %s %s", action.Verb, action.Path)
+}
+
+// RespondWith 返回以 data 为 data 字段的成功响应的 ReactionFunc，data 在每次响应时被序列化。
+func RespondWith(data interface{}) ReactionFunc {
+	return func(Action) (bool, *http.Response, error) {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return true, nil, fmt.Errorf("failed to marshal fake response: %w", err)
+		}
+		return true, NewFakeResponse(http.StatusOK, Response{Status: http.StatusOK, Message: "success", Data: raw}), nil
+	}
+}
+
+// RespondWithError 返回 HTTP 状态码和 ECSM 响应信封中的 status 都是 status 的错误响应的 ReactionFunc。
+func RespondWithError(status int, message string) ReactionFunc {
+	return func(Action) (bool, *http.Response, error) {
+		return true, NewFakeResponse(status, Response{Status: status, Message: message}), nil
+	}
+}
+
+// NewFakeResponse 返回 HTTP 状态码为 statusCode、响应体为 envelope 的响应，用于编写 ReactionFunc。
+func NewFakeResponse(statusCode int, envelope Response) *http.Response {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal fake response: %v", err))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestFake 测试 reactor 的匹配顺序、未处理的请求交给下一个 reactor，以及请求被记录。
+func TestFake(t *testing.T) {
+	fake := NewFake()
+	fake.AddReactor("GET", "service/*", RespondWith(map[string]string{"id": "generic"}))
+	fake.PrependReactor("GET", "service/*", func(action Action) (bool, *http.Response, error) {
+		if action.Path != "service/special" {
+			return false, nil, nil
+		}
+		return RespondWith(map[string]string{"id": "special"})(action)
+	})
+	fake.AddReactor("*", "*", RespondWithError(http.StatusNotFound, "not found"))
+	ctx := context.Background()
+
+	for name, want := range map[string]string{"special": "special", "0f4b1c": "generic"} {
+		var got struct {
+			ID string `json:"id"`
+		}
+		if err := fake.Get().Resource("service").Name(name).Do(ctx).Into(&got); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if got.ID != want {
+			t.Errorf("GET service/%s returned %q, want %q", name, got.ID, want)
+		}
+	}
+
+	err := fake.Post().Resource("node").Param("force", "true").Body(map[string]string{"name": "n1"}).Do(ctx).Into(nil)
+	var apiErr *Aerror
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Errorf("Expected the catch-all 404, got %v", err)
+	}
+
+	actions := fake.Actions()
+	if len(actions) != 3 {
+		t.Fatalf("Expected 3 actions, got %d", len(actions))
+	}
+	last := actions[2]
+	if !last.Matches("post", "node") || last.Query.Get("force") != "true" || string(last.Body) != `{"name":"n1"}` {
+		t.Errorf("Unexpected action %+v", last)
+	}
+}
+
+// TestFake_NoReactor 测试没有 reactor 处理的请求返回错误。
+func TestFake_NoReactor(t *testing.T) {
+	fake := NewFake()
+	err := fake.Delete().Resource("service").Name("0f4b1c").Do(context.Background()).Into(nil)
+	if err == nil {
+		t.Fatalf("Expected an error for an unhandled request")
+	}
+}
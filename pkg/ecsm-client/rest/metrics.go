@@ -0,0 +1,54 @@
+// file: pkg/ecsm-client/rest/metrics.go
+
+package rest
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// codeError 是请求没有得到响应时 code 标签的值，例如连接失败或超时
+const codeError = "error"
+
+// 指标的 resource 标签是 Resource() 和 Subresource() 添加的路径段，例如 "service/redeploy"，
+// 不包括 Name() 添加的资源 ID 等取值无限的内容
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "rest_client",
+		Name:      "requests_total",
+		Help:      "Total number of requests sent to the ECSM API server per verb, resource and HTTP status code (\"error\" when no response was received). Every retry is counted.",
+	}, []string{"verb", "resource", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "rest_client",
+		Name:      "request_duration_seconds",
+		Help:      "Time until the response headers of requests to the ECSM API server were received, per verb, resource and HTTP status code.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"verb", "resource", "code"})
+
+	retriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "rest_client",
+		Name:      "retries_total",
+		Help:      "Total number of requests to the ECSM API server retried after a transient failure, per verb and resource.",
+	}, []string{"verb", "resource"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestDuration, retriesTotal)
+}
+
+// observeRequest 记录一次尝试的结果和耗时，statusCode 为 0 表示没有得到响应。
+func (r *Request) observeRequest(statusCode int, elapsed time.Duration) {
+	code := codeError
+	if statusCode != 0 {
+		code = strconv.Itoa(statusCode)
+	}
+	requestsTotal.WithLabelValues(r.verb, r.resource, code).Inc()
+	requestDuration.WithLabelValues(r.verb, r.resource, code).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestRequestMetrics 测试每次尝试按 verb、resource 和状态码计数，资源 ID 不出现在标签中。
+func TestRequestMetrics(t *testing.T) {
+	client, _ := newFlakyTestClient(t, 1, http.StatusServiceUnavailable)
+
+	succeeded := requestsTotal.WithLabelValues("GET", "service/redeploy", "200")
+	failed := requestsTotal.WithLabelValues("GET", "service/redeploy", "503")
+	retried := retriesTotal.WithLabelValues("GET", "service/redeploy")
+	before := []float64{testutil.ToFloat64(succeeded), testutil.ToFloat64(failed), testutil.ToFloat64(retried)}
+
+	err := client.Get().Resource("service").Name("0f4b1c").Subresource("redeploy").Do(context.Background()).Into(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	for i, c := range []struct {
+		name string
+		got  float64
+	}{
+		{"200 responses", testutil.ToFloat64(succeeded)},
+		{"503 responses", testutil.ToFloat64(failed)},
+		{"retries", testutil.ToFloat64(retried)},
+	} {
+		if c.got-before[i] != 1 {
+			t.Errorf("Expected %s to increase by 1, got %v", c.name, c.got-before[i])
+		}
+	}
+	if n := testutil.CollectAndCount(requestDuration, "ecsm_rest_client_request_duration_seconds"); n == 0 {
+		t.Errorf("Expected request durations to be observed")
+	}
+}
@@ -0,0 +1,52 @@
+// file: pkg/ecsm-client/rest/middleware.go
+
+package rest
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Middleware 包装发送请求的 RoundTripper，用于在不修改 request.go 的情况下添加日志、指标、认证和故障注入等功能。
+// 返回的 RoundTripper 必须可以被并发调用，并且和其它 RoundTripper 一样不能修改传入的请求。
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc 让普通函数实现 http.RoundTripper，便于编写 Middleware。
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use 在客户端的拦截器链中添加 middlewares，先添加的在外层。
+//
+// 请求依次经过 Use 添加的拦截器、认证 (SetAuthProvider)、调试日志 (SetDebugLevel)，最后由原来的 Transport 发送。
+// 因此拦截器看到的请求还没有凭据，凭据被拒绝后的重新认证和重试对它们来说是一次请求；
+// 而重试策略 (SetRetryPolicy) 的每次尝试都会经过整个拦截器链。它必须在客户端被使用之前调用。
+func (c *RESTClient) Use(middlewares ...Middleware) {
+	// 复制切片，不影响复制了这个客户端的其它客户端，例如 Clientset.Auth()
+	c.middlewares = append(slices.Clip(c.middlewares), middlewares...)
+	c.rebuildTransport()
+}
+
+// rebuildTransport 按照客户端的设置重新组装 httpClient 的拦截器链。
+func (c *RESTClient) rebuildTransport() {
+	rt := c.transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if c.debugLevel > DebugNone {
+		rt = NewDebuggingRoundTripper(rt, c.debugLevel, c.debugOut)
+	}
+	if c.authProvider != nil {
+		rt = &authRoundTripper{provider: c.authProvider, delegate: rt}
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		rt = c.middlewares[i](rt)
+	}
+
+	// 复制 http.Client，不修改调用方传入的客户端或 http.DefaultClient
+	client := *c.httpClient
+	client.Transport = rt
+	c.httpClient = &client
+}
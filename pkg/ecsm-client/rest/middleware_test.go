@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestUse_Order 测试拦截器按添加的顺序由外到内执行，并且在认证之外，看不到凭据。
+func TestUse_Order(t *testing.T) {
+	client := newAuthTestClient(t, NewTokenAuthProvider(StaticTokenSource("secret")), func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Trace"); got != "outer,inner" {
+			t.Errorf("Server received X-Trace %q, want %q", got, "outer,inner")
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Server received Authorization %q", got)
+		}
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+
+	var mu sync.Mutex
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+				if req.Header.Get("Authorization") != "" {
+					t.Errorf("Middleware %s saw the credentials", name)
+				}
+				req = req.Clone(req.Context())
+				if prev := req.Header.Get("X-Trace"); prev != "" {
+					name = prev + "," + name
+				}
+				req.Header.Set("X-Trace", name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	client.Use(trace("outer"))
+	client.Use(trace("inner"))
+
+	if err := client.Get().Resource("node").Do(context.Background()).Into(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if got := strings.Join(calls, ","); got != "outer,inner" {
+		t.Errorf("Middlewares were called in order %q, want %q", got, "outer,inner")
+	}
+}
+
+// TestUse_FaultInjection 测试拦截器注入的失败和服务器返回的失败一样按照重试策略重试。
+func TestUse_FaultInjection(t *testing.T) {
+	var requests atomic.Int32
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+	client.SetRetryPolicy(fastRetryPolicy)
+
+	var injected atomic.Int32
+	client.Use(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if injected.Add(1) <= 2 {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader("injected")),
+					Request:    req,
+				}, nil
+			}
+			return next.RoundTrip(req)
+		})
+	})
+
+	if err := client.Get().Resource("node").Do(context.Background()).Into(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected the server to receive 1 request after 2 injected failures, got %d", n)
+	}
+}
@@ -6,13 +6,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/klog/v2"
 )
 
@@ -22,9 +27,22 @@ type Request struct {
 	verb string
 	// --- 路径构建字段 ---
 	pathParts []string // 不再使用 resource, resourceID，而是用一个切片
-	body      interface{}
+	// resource 是 Resource() 和 Subresource() 添加的路径段，不包括 Name()，用作指标的 resource 标签
+	resource string
+	body     interface{}
+	// bodyReader 和 contentType 是 BodyReader 设置的非 JSON 请求体
+	bodyReader  io.Reader
+	contentType string
+	// patchType 是 PATCH 请求的 JSON 请求体的 Content-Type
+	patchType PatchType
 	err       error
 	params    url.Values
+	// retryPolicy 不为 nil 时代替客户端的重试策略
+	retryPolicy *RetryPolicy
+	// idempotencyKey 是 IdempotencyKey 设置的幂等键，为空时在需要时生成
+	idempotencyKey string
+	// timeout 是 Timeout 设置的整个调用的时限，为 0 时只受调用方 ctx 的限制
+	timeout time.Duration
 }
 
 func NewRequest(c *RESTClient) *Request {
@@ -45,6 +63,10 @@ func (r *Request) Resource(resource string) *Request {
 		return r
 	}
 	r.pathParts = append(r.pathParts, resource)
+	if r.resource != "" {
+		resource = r.resource + "/" + resource
+	}
+	r.resource = resource
 	return r
 }
 
@@ -67,7 +89,7 @@ func (r *Request) Subresource(subresource string) *Request {
 	return r.Resource(subresource)
 }
 
-// Body 设置请求体。传入的 obj 会被序列化为 JSON。
+// Body 设置请求体。传入的 obj 会被序列化为 JSON，[]byte 被当作已经序列化的 JSON 原样发送，例如 Patch 的补丁。
 func (r *Request) Body(obj interface{}) *Request {
 	if r.err != nil {
 		return r
@@ -76,6 +98,40 @@ func (r *Request) Body(obj interface{}) *Request {
 	return r
 }
 
+// BodyReader 设置一个原始的请求体及其 Content-Type，用于上传文件等不是 JSON 的请求。
+// 请求体以流的方式发送，不会被读入内存；设置后 Body() 设置的对象被忽略。
+func (r *Request) BodyReader(body io.Reader, contentType string) *Request {
+	if r.err != nil {
+		return r
+	}
+	r.bodyReader = body
+	r.contentType = contentType
+	return r
+}
+
+// Retry 让这个请求使用 policy 代替客户端的重试策略，例如用 RetryPolicy{} 禁止重试。
+func (r *Request) Retry(policy RetryPolicy) *Request {
+	r.retryPolicy = &policy
+	return r
+}
+
+// Timeout 限制这个请求的整个调用的时长为 d，包括限速等待、重试和读取响应体，d <= 0 表示不限制。
+// 它与调用方 ctx 的截止时间同时生效，先到者结束请求，因此一个 ctx 中可以为列出全部资源等
+// 较慢的请求和启动、停止等控制操作设置不同的时限。超时的请求返回包装了 context.DeadlineExceeded 的错误。
+// 它不改变 http.Client 的 Timeout，参见 Config.HTTPClient。
+func (r *Request) Timeout(d time.Duration) *Request {
+	r.timeout = d
+	return r
+}
+
+// IdempotencyKey 让这个请求携带幂等键 key，代替启用重试时为 POST 请求生成的幂等键。
+// 控制器可以在多次 reconcile 中为同一个创建操作使用同一个键，使跨越多次调用的重试也不会重复创建资源。
+// 携带幂等键的请求在超时和 5xx 之后也会重试，参见 RetryPolicy。
+func (r *Request) IdempotencyKey(key string) *Request {
+	r.idempotencyKey = key
+	return r
+}
+
 // Param 向请求添加一个 URL Query 参数。
 func (r *Request) Param(key, value string) *Request {
 	if r.err != nil {
@@ -94,62 +150,192 @@ func (r *Request) Do(ctx context.Context) *Result {
 		return &Result{err: r.err}
 	}
 
+	// 时限在响应体被关闭时解除，Into 等方法读取响应体也受它的限制
+	var cancel context.CancelFunc = func() {}
+	if r.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+	}
+
+	// 每个请求是一个 span，重试和重新认证都包含在其中
+	fullURL := r.url()
+	ctx, span := r.startSpan(ctx, fullURL)
+	result := r.do(ctx, fullURL)
+	endSpan(span, result)
+	if result.body == nil {
+		cancel()
+	} else {
+		result.body = &releasingBody{ReadCloser: result.body, release: cancel}
+	}
+	return result
+}
+
+// url 返回请求的完整 URL。
+func (r *Request) url() *url.URL {
 	// ---- 核心修复逻辑 ----
 	// 1. 构建 URL 路径
 	resourcePath := strings.Join(r.pathParts, "/")
 
-	// --- 关键修正 ---
-	// 我们必须在这里包含 API 的基础路径 "api"。
-	p := path.Join(defaultAPIPath, r.c.apiVersion, resourcePath)
+	// 接口位于 API 的基础路径和版本之下，例如 "api/v1"，参见 SetAPIVersion
+	p := path.Join(r.c.apiPath, r.c.apiVersion, resourcePath)
 
 	fullURL := r.c.baseURL.ResolveReference(&url.URL{Path: p})
 
 	if len(r.params) > 0 {
 		fullURL.RawQuery = r.params.Encode()
 	}
+	return fullURL
+}
 
+// do 发送 Do 构建的请求。
+func (r *Request) do(ctx context.Context, fullURL *url.URL) *Result {
 	// 2. 序列化 Body
-	var bodyReader io.Reader
-	if r.body != nil {
-		data, err := json.Marshal(r.body)
+	var data []byte
+	if raw, ok := r.body.([]byte); ok && r.bodyReader == nil {
+		data = raw
+	} else if r.bodyReader == nil && r.body != nil {
+		var err error
+		data, err = json.Marshal(r.body)
 		if err != nil {
 			r.err = fmt.Errorf("failed to marshal body: %w", err)
 			return &Result{err: r.err}
 		}
-		bodyReader = bytes.NewBuffer(data)
 	}
 
-	// 3. 创建 HTTP Request
-	req, err := http.NewRequestWithContext(ctx, r.verb, fullURL.String(), bodyReader)
+	policy := r.c.retryPolicy
+	if r.retryPolicy != nil {
+		policy = *r.retryPolicy
+	}
+	// 启用重试的 POST 请求的每次尝试都携带同一个幂等键，服务器不会重复处理重试的请求
+	idempotencyKey := r.idempotencyKey
+	if idempotencyKey == "" && r.verb == http.MethodPost && policy.MaxAttempts > 1 && !policy.NoIdempotencyKeys && r.bodyReader == nil {
+		idempotencyKey = uuid.New().String()
+	}
+	idempotent := idempotentMethods[r.verb] || idempotencyKey != ""
+
+	// 3. 创建 HTTP Request，重试时会再次调用它
+	newHTTPRequest := func() (*http.Request, error) {
+		var bodyReader io.Reader
+		contentType := "application/json"
+		if r.patchType != "" {
+			contentType = string(r.patchType)
+		}
+		if r.bodyReader != nil {
+			bodyReader = r.bodyReader
+			contentType = r.contentType
+		} else if data != nil {
+			bodyReader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, r.verb, fullURL.String(), bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", "application/json")
+		if idempotencyKey != "" {
+			req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		injectTraceHeaders(ctx, req)
+		return req, nil
+	}
+
+	// 4. 发送请求，暂时性的失败按照重试策略重试，以流的方式发送的请求体无法重新发送
+	for attempt := 1; ; attempt++ {
+		resp, release, err := r.send(ctx, newHTTPRequest)
+		if attempt > 1 {
+			trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPRequestResendCount(attempt - 1))
+		}
+		if attempt >= policy.MaxAttempts || r.bodyReader != nil || !shouldRetry(ctx, idempotent, resp, err) {
+			if err != nil {
+				r.err = err
+				return &Result{err: r.err}
+			}
+			return &Result{
+				body:       &releasingBody{ReadCloser: resp.Body, release: release},
+				statusCode: resp.StatusCode,
+				header:     resp.Header,
+				err:        nil,
+			}
+		}
+
+		retriesTotal.WithLabelValues(r.verb, r.resource).Inc()
+		delay := policy.backoff(attempt, retryAfter(resp))
+		if err != nil {
+			klog.V(4).InfoS("Retrying request after transient error", "method", r.verb, "url", fullURL, "attempt", attempt, "delay", delay, "err", err)
+		} else {
+			klog.V(4).InfoS("Retrying request after transient error", "method", r.verb, "url", fullURL, "attempt", attempt, "delay", delay, "status", resp.StatusCode)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			release()
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			r.err = fmt.Errorf("request canceled while waiting to retry: %w", err)
+			return &Result{err: r.err}
+		}
+	}
+}
+
+// send 通过客户端的拦截器链发送一次请求。返回的响应占用一个并发名额，release 释放它，
+// 返回错误时名额已经被释放。
+func (r *Request) send(ctx context.Context, newHTTPRequest func() (*http.Request, error)) (*http.Response, func(), error) {
+	req, err := newHTTPRequest()
 	if err != nil {
-		r.err = fmt.Errorf("failed to create request: %w", err)
-		return &Result{err: r.err}
+		return nil, nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
 
-	// 4. 执行请求
+	// 等待令牌桶中的令牌和并发名额
+	if err := r.c.throttle(ctx); err != nil {
+		return nil, nil, fmt.Errorf("request failed while waiting for client-side rate limiting: %w", err)
+	}
+	release, err := r.c.acquire(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request failed while waiting for a free connection slot: %w", err)
+	}
+
+	// 执行请求
 	klog.V(4).InfoS("Executing request", "method", req.Method, "url", req.URL)
+	start := time.Now()
 	resp, err := r.c.httpClient.Do(req)
 	if err != nil {
-		r.err = fmt.Errorf("request failed: %w", err)
-		return &Result{err: r.err}
+		release()
+		// 认证的错误不是发送请求时的错误，去掉 http.Client 添加的 URL 前缀，并且不重试
+		var authErr *authError
+		if errors.As(err, &authErr) {
+			return nil, nil, authErr
+		}
+		r.observeRequest(0, time.Since(start))
+		return nil, nil, &transportError{err: err}
 	}
+	r.observeRequest(resp.StatusCode, time.Since(start))
+	return resp, release, nil
+}
 
-	return &Result{
-		body:       resp.Body,
-		statusCode: resp.StatusCode,
-		err:        nil,
-	}
+// releasingBody 在响应体被关闭时释放并发名额。
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
 }
 
 // Result 封装了请求的结果。
 type Result struct {
 	body       io.ReadCloser
 	statusCode int
+	header     http.Header
 	err        error
 }
 
+// Cookies 返回响应中 Set-Cookie 头设置的 Cookie，例如登录接口设置的会话 Cookie。
+func (r *Result) Cookies() []*http.Cookie {
+	if r.header == nil {
+		return nil
+	}
+	return (&http.Response{Header: r.header}).Cookies()
+}
+
 // transformAndGetRawData 是一个新的辅助方法。
 // 它解码通用的响应信封，检查 API 错误，如果成功，则返回原始的 data 字段。
 func (r *Result) transformAndGetRawData() (json.RawMessage, error) {
@@ -159,14 +345,22 @@ func (r *Result) transformAndGetRawData() (json.RawMessage, error) {
 		return nil, err
 	}
 
-	// 如果 body 为空，直接返回成功
+	// 如果 body 为空，按 HTTP 状态码判断成功与否，例如不支持 PATCH 的服务器返回的空的 405 响应
 	if len(bodyBytes) == 0 {
+		if r.statusCode >= http.StatusBadRequest {
+			return nil, &Aerror{Status: r.statusCode, Message: http.StatusText(r.statusCode)}
+		}
 		return nil, nil
 	}
 
 	// 解码到通用的 response 结构体
 	var apiResp Response
 	if err := json.Unmarshal(bodyBytes, &apiResp); err != nil {
+		// 网关或反向代理返回的错误页不是 ECSM 的响应信封，用 HTTP 状态码构造错误，
+		// 以便调用方能够区分 5xx 等错误类别
+		if r.statusCode >= http.StatusBadRequest {
+			return nil, &Aerror{Status: r.statusCode, Message: http.StatusText(r.statusCode)}
+		}
 		return nil, fmt.Errorf("failed to decode generic response: %w (raw response: %q)", err, string(bodyBytes))
 	}
 
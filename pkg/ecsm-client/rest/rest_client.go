@@ -1,9 +1,18 @@
 package rest
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -17,15 +26,46 @@ type Interface interface {
 	Put() *Request
 	Post() *Request
 	Delete() *Request
+	Patch(pt PatchType) *Request
 	APIVersion() string
 }
 
+// PatchType 是 PATCH 请求的补丁格式，作为请求的 Content-Type 发送。
+type PatchType string
+
+const (
+	// MergePatchType 是 RFC 7386 JSON Merge Patch：补丁中的对象递归合并，null 删除字段，数组整体替换
+	MergePatchType PatchType = "application/merge-patch+json"
+	// JSONPatchType 是 RFC 6902 JSON Patch：按顺序执行的 add、remove、replace 等操作的列表
+	JSONPatchType PatchType = "application/json-patch+json"
+)
+
 // Client 是与 ECSM API Server 交互的客户端。
 type RESTClient struct {
 	baseURL    *url.URL
 	httpClient *http.Client
 	apiVersion string
 	apiPath    string
+	// transport 是 httpClient 原来的 Transport，rebuildTransport 在它外面包装拦截器链
+	transport http.RoundTripper
+	// debugLevel 和 debugOut 是 SetDebugLevel 的参数
+	debugLevel DebugLevel
+	debugOut   io.Writer
+	// authProvider 为每个请求添加凭据，为 nil 时不发送凭据
+	authProvider AuthProvider
+	// middlewares 是 Use 添加的拦截器，第一个在最外层
+	middlewares []Middleware
+	// tracerProvider 创建请求的 span，为 nil 时使用 OpenTelemetry 的全局 TracerProvider
+	tracerProvider trace.TracerProvider
+	// retryPolicy 是请求遇到暂时性的失败时的重试策略，默认不重试
+	retryPolicy RetryPolicy
+
+	// rateLimiter 限制每秒发出的请求数，为 nil 时不限制。每次尝试 (包括重试) 消耗一个令牌
+	rateLimiter *rate.Limiter
+
+	// inflight 是限制并发请求数的信号量，为 nil 时不限制。
+	// 一个请求从发出开始占用一个名额，直到响应体被关闭。
+	inflight chan struct{}
 }
 
 // NewClient 创建一个新的 ECSM 客户端实例。
@@ -40,12 +80,115 @@ func NewRESTClient(protocol, host, port string, httpClient *http.Client) (*RESTC
 		return nil, fmt.Errorf("failed to parse base url: %w", err)
 	}
 
-	return &RESTClient{
+	c := &RESTClient{
 		baseURL:    baseURL,
 		httpClient: httpClient,
 		apiVersion: defaultAPIVersion,
 		apiPath:    defaultAPIPath,
-	}, nil
+		transport:  httpClient.Transport,
+	}
+	c.SetDebugLevel(DebugLevelFromVerbosity(), nil)
+	return c, nil
+}
+
+// SetAuthProvider 让客户端用 p 为每个请求添加凭据，p 为 nil 时不发送凭据。
+// 服务器以 401 拒绝请求时，没有请求体或请求体可以重新发送的请求会在 p 重新认证后重试一次。
+// 它必须在客户端被使用之前调用。
+func (c *RESTClient) SetAuthProvider(p AuthProvider) {
+	c.authProvider = p
+	c.rebuildTransport()
+}
+
+// SetTokenSource 让客户端在每个请求中以 Bearer Token 的形式发送 ts 提供的凭据，ts 为 nil 时不发送凭据。
+// 它是 SetAuthProvider(NewTokenAuthProvider(ts)) 的简写，必须在客户端被使用之前调用。
+func (c *RESTClient) SetTokenSource(ts TokenSource) {
+	if ts == nil {
+		c.SetAuthProvider(nil)
+		return
+	}
+	c.SetAuthProvider(NewTokenAuthProvider(ts))
+}
+
+// SetDebugLevel 让客户端按 level 记录每个请求和响应，out 为 nil 时记录到 klog。
+// NewRESTClient 已经按照 klog 的日志级别设置了级别，参见 DebugLevelFromVerbosity。
+// 它必须在客户端被使用之前调用。
+func (c *RESTClient) SetDebugLevel(level DebugLevel, out io.Writer) {
+	c.debugLevel, c.debugOut = level, out
+	c.rebuildTransport()
+}
+
+// SetRetryPolicy 让请求在连接失败、超时和 5xx 等暂时性的失败之后按照 policy 重试，参见 RetryPolicy。
+// 等待重试时不占用并发名额，ctx 结束时立即返回。它必须在客户端被使用之前调用。
+func (c *RESTClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// SetMaxInflightRequests 限制同一时刻发往 ECSM API Server 的最大请求数，n <= 0 表示不限制。
+// 资源有限的边缘管理服务器在大量并发请求下容易过载，多个控制器共享一个客户端时尤其需要限制。
+// 它必须在客户端被使用之前调用。
+func (c *RESTClient) SetMaxInflightRequests(n int) {
+	if n <= 0 {
+		c.inflight = nil
+		return
+	}
+	c.inflight = make(chan struct{}, n)
+}
+
+// SetRateLimit 用令牌桶限制客户端每秒平均发出 qps 个请求，允许短时间内突发 burst 个请求，qps <= 0 表示不限制。
+// 与 SetMaxInflightRequests 不同，它限制的是请求的速率，即使每个请求都很快完成，
+// 每次 reconcile 都列出全部服务的控制器也不会使 ECSM API Server 过载。它必须在客户端被使用之前调用。
+func (c *RESTClient) SetRateLimit(qps float32, burst int) {
+	if qps <= 0 {
+		c.rateLimiter = nil
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	c.rateLimiter = rate.NewLimiter(rate.Limit(qps), burst)
+}
+
+// SetAPIVersion 让客户端的请求发往 /<apiPath>/<version>/ 下的接口，参数为空时使用默认的 "api" 和 "v1"。
+// ECSM 服务器升级后可能提供新版本的接口，clientset.Clientset.NegotiateAPIVersion 可以探测服务器支持的版本。
+// 它必须在客户端被使用之前调用。
+func (c *RESTClient) SetAPIVersion(apiPath, version string) {
+	if apiPath == "" {
+		apiPath = defaultAPIPath
+	}
+	if version == "" {
+		version = defaultAPIVersion
+	}
+	c.apiPath, c.apiVersion = strings.Trim(apiPath, "/"), version
+}
+
+// throttle 等待令牌桶中的令牌，ctx 结束前等不到令牌时返回错误。
+func (c *RESTClient) throttle(ctx context.Context) error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+	start := time.Now()
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	if waited := time.Since(start); waited >= time.Second {
+		klog.V(2).Infof("Waited for %v due to client-side throttling of ECSM API requests", waited.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// acquire 占用一个并发名额，名额已满时阻塞直到有空闲名额或 ctx 结束。
+// 返回的函数用于释放名额，重复调用是安全的。
+func (c *RESTClient) acquire(ctx context.Context) (func(), error) {
+	if c.inflight == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-c.inflight }) }, nil
 }
 
 func (c *RESTClient) Verb(verb string) *Request {
@@ -72,6 +215,13 @@ func (c *RESTClient) Delete() *Request {
 	return c.Verb("DELETE")
 }
 
+// Patch begins a PATCH request whose body is a patch of type pt. Short for c.Verb("PATCH") with the Content-Type pt.
+func (c *RESTClient) Patch(pt PatchType) *Request {
+	r := c.Verb("PATCH")
+	r.patchType = pt
+	return r
+}
+
 // APIVersion returns the APIVersion this RESTClient is expected to use.
 func (c *RESTClient) APIVersion() string {
 	return fmt.Sprintf("%s/%s", c.apiPath, c.apiVersion)
@@ -3,10 +3,16 @@ package rest
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -132,13 +138,27 @@ func TestRESTClient_GetServices(t *testing.T) {
 	}
 }
 
+// realServer 返回环境变量 ECSM_TEST_SERVER 指定的真实 ECSM API Server 的主机和端口，
+// 例如 ECSM_TEST_SERVER=192.168.31.129:3001。没有设置时跳过测试，使 go test ./... 不依赖真实的环境。
+func realServer(tb testing.TB) (host, port string) {
+	addr := os.Getenv("ECSM_TEST_SERVER")
+	if addr == "" {
+		tb.Skip("ECSM_TEST_SERVER is not set, skipping test against a real ECSM server")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		tb.Fatalf("Invalid ECSM_TEST_SERVER %q: %v", addr, err)
+	}
+	return host, port
+}
+
 // TestRESTClient_RealAPI 测试真实的 ECSM API（需要真实的服务器运行）
-// 这个测试默认跳过，可以通过 go test -v -run TestRESTClient_RealAPI 单独运行
+// 这个测试默认跳过，可以通过 ECSM_TEST_SERVER=<host>:<port> go test -v -run TestRESTClient_RealAPI 单独运行
 func TestRESTClient_RealAPI(t *testing.T) {
-	//t.Skip("Skipping real API test - uncomment to test against real ECSM server")
+	host, port := realServer(t)
 
 	// 创建连接到真实 ECSM API 的客户端
-	client, err := NewRESTClient("http", "192.168.31.129", "3001", &http.Client{Timeout: 10 * time.Second})
+	client, err := NewRESTClient("http", host, port, &http.Client{Timeout: 10 * time.Second})
 	if err != nil {
 		t.Fatalf("Failed to create REST client: %v", err)
 	}
@@ -219,3 +239,199 @@ func TestRESTClient_ErrorHandling(t *testing.T) {
 		t.Errorf("Expected *aerror, got %T", err)
 	}
 }
+
+// TestRESTClient_MaxInflightRequests 测试并发请求数不会超过设置的上限
+func TestRESTClient_MaxInflightRequests(t *testing.T) {
+	const limit = 2
+	var current, peak int32
+	release := make(chan struct{})
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":200,"message":"success","data":null}`))
+	}))
+	defer mockServer.Close()
+
+	host, port, _ := net.SplitHostPort(mockServer.Listener.Addr().String())
+	client, err := NewRESTClient("http", host, port, nil)
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+	client.SetMaxInflightRequests(limit)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Get().Resource("service").Do(context.Background()).Into(nil); err != nil {
+				t.Errorf("Request failed: %v", err)
+			}
+		}()
+	}
+
+	// 等待请求堆积，然后逐个放行
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	peak = atomic.LoadInt32(&peak)
+	if peak > limit {
+		t.Errorf("Expected at most %d concurrent requests, got %d", limit, peak)
+	}
+	if peak < limit {
+		t.Errorf("Expected requests to use all %d slots, got %d", limit, peak)
+	}
+}
+
+// TestRESTClient_MaxInflightRequestsContextCanceled 测试等待名额时 context 取消会立即返回
+func TestRESTClient_MaxInflightRequestsContextCanceled(t *testing.T) {
+	client, err := NewRESTClient("http", "127.0.0.1", "1", nil)
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+	client.SetMaxInflightRequests(1)
+	// 占满唯一的名额
+	client.inflight <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.Get().Resource("service").Do(ctx).Into(nil); err == nil {
+		t.Fatal("Expected an error when no slot becomes free before the deadline")
+	}
+}
+
+// TestRESTClient_RateLimit 测试请求速率不超过设置的 QPS，以及等不到令牌时请求在 context 结束前失败
+func TestRESTClient_RateLimit(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"status":200,"message":"success","data":null}`))
+	}))
+	defer mockServer.Close()
+
+	host, port, _ := net.SplitHostPort(mockServer.Listener.Addr().String())
+	client, err := RESTClientFor(&Config{Protocol: "http", Host: host, Port: port, QPS: 20, Burst: 2})
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+
+	// 突发的 2 个请求立即发出，之后的 4 个请求每 50ms 发出一个
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := client.Get().Resource("service").Do(context.Background()).Into(nil); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected 6 requests at 20 QPS with burst 2 to take about 200ms, took %v", elapsed)
+	}
+
+	client.SetRateLimit(0.1, 1)
+	client.Get().Resource("service").Do(context.Background()).Into(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	before := requests.Load()
+	if err := client.Get().Resource("service").Do(ctx).Into(nil); err == nil || !strings.Contains(err.Error(), "rate limiting") {
+		t.Errorf("Expected the request to fail waiting for the rate limiter, got %v", err)
+	}
+	if requests.Load() != before {
+		t.Errorf("Expected the throttled request not to be sent")
+	}
+}
+
+// TestRESTClient_BodyReader 测试原始请求体按原样发送，并使用调用方指定的 Content-Type
+func TestRESTClient_BodyReader(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/octet-stream" {
+			t.Errorf("Expected Content-Type application/octet-stream, got %s", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "raw bundle" {
+			t.Errorf("Expected body %q, got %q", "raw bundle", string(body))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": 200, "message": "success", "data": nil})
+	}))
+	defer mockServer.Close()
+
+	addr := mockServer.Listener.Addr().(*net.TCPAddr)
+	client, err := NewRESTClient("http", addr.IP.String(), strconv.Itoa(addr.Port), nil)
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+
+	err = client.Post().
+		Resource("image/upload").
+		BodyReader(strings.NewReader("raw bundle"), "application/octet-stream").
+		Do(context.Background()).
+		Into(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+// TestRESTClient_Patch 测试 PATCH 请求以补丁类型作为 Content-Type，并原样发送已经序列化的补丁
+func TestRESTClient_Patch(t *testing.T) {
+	for _, pt := range []PatchType{MergePatchType, JSONPatchType} {
+		client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPatch {
+				t.Errorf("Expected method PATCH, got %s", r.Method)
+			}
+			if ct := r.Header.Get("Content-Type"); ct != string(pt) {
+				t.Errorf("Expected Content-Type %s, got %s", pt, ct)
+			}
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"factor":3}` {
+				t.Errorf("Expected the patch to be sent as is, got %q", string(body))
+			}
+			w.Write([]byte(`{"status":200,"message":"success"}`))
+		})
+
+		err := client.Patch(pt).Resource("service").Name("0f4b1c").Body([]byte(`{"factor":3}`)).Do(context.Background()).Into(nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+}
+
+// TestRESTClient_Timeout 测试 Request.Timeout 限制单个请求的时长，包括等待重试，不影响同一个 ctx 中的其它请求。
+func TestRESTClient_Timeout(t *testing.T) {
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "true" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+	ctx := context.Background()
+
+	start := time.Now()
+	err := client.Get().Resource("service").Param("slow", "true").Timeout(50 * time.Millisecond).Do(ctx).Into(nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the request to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Request returned after %v, want it to stop at its timeout", elapsed)
+	}
+	if err := client.Get().Resource("service").Timeout(time.Minute).Do(ctx).Into(nil); err != nil {
+		t.Errorf("Request with a longer timeout failed: %v", err)
+	}
+
+	flaky, requests := newFlakyTestClient(t, 5, http.StatusServiceUnavailable)
+	flaky.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour})
+	err = flaky.Get().Resource("node").Timeout(50 * time.Millisecond).Do(ctx).Into(nil)
+	if !errors.Is(err, context.DeadlineExceeded) || requests.Load() != 1 {
+		t.Errorf("Expected the timeout to end the wait for a retry, got %v after %d requests", err, requests.Load())
+	}
+}
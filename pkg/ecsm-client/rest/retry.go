@@ -0,0 +1,143 @@
+// file: pkg/ecsm-client/rest/retry.go
+
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy 决定请求遇到暂时性的失败时如何重试。零值表示不重试。
+//
+// 连接被拒绝和 429 时请求没有被服务器处理，任何方法都会重试；超时、连接中断和 5xx 时请求可能已经被处理，
+// 只有 GET、PUT、DELETE 等幂等的请求和携带幂等键的 POST 请求会重试，避免重复创建资源。
+// 以流的方式发送请求体的请求不重试。
+//
+// 启用重试时，POST 请求的每次尝试都携带同一个 Idempotency-Key 头，服务器据此识别重试的请求，
+// 返回第一次处理的结果而不是再次创建服务或注册节点。
+type RetryPolicy struct {
+	// MaxAttempts 是包括第一次在内最多发送请求的次数，小于等于 1 表示不重试
+	MaxAttempts int
+	// BaseDelay 是第一次重试之前等待的时间，之后每次重试翻倍
+	BaseDelay time.Duration
+	// MaxDelay 是两次尝试之间等待时间的上限，为 0 表示不限制
+	MaxDelay time.Duration
+	// Jitter 是随机减少的等待时间的最大比例，取值 [0, 1]，避免大量客户端同时重试
+	Jitter float64
+	// NoIdempotencyKeys 为 true 时 POST 请求不携带幂等键，超时和 5xx 时不重试，用于不识别 Idempotency-Key 的服务器
+	NoIdempotencyKeys bool
+}
+
+// IdempotencyKeyHeader 是携带幂等键的请求头，参见 RetryPolicy
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultRetryPolicy 是建议的重试策略：最多尝试 3 次，等待约 200ms 和 400ms。
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.2,
+}
+
+// backoff 返回第 attempt 次尝试失败后等待的时间，attempt 从 1 开始。
+// retryAfter 是服务器通过 Retry-After 要求的等待时间，不超过 MaxDelay 时优先使用。
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	if retryAfter > delay && (p.MaxDelay <= 0 || retryAfter <= p.MaxDelay) {
+		delay = retryAfter
+	}
+	return delay
+}
+
+// idempotentMethods 是重复执行不会产生额外效果的方法
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// shouldRetry 判断请求的一次尝试是否因为暂时性的失败而值得重试。idempotent 表示请求可以安全地重复执行，
+// 即方法是幂等的或者请求携带了幂等键。
+func shouldRetry(ctx context.Context, idempotent bool, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		// 只有发送请求时的错误是暂时性的，创建请求和获取凭据的错误不是。
+		// 获取凭据的错误可能包装了登录请求的 transportError，因此不能用 errors.As
+		if _, ok := err.(*transportError); !ok {
+			return false
+		}
+		var certErr *tls.CertificateVerificationError
+		switch {
+		case errors.As(err, &certErr):
+			// 证书错误不会自行恢复
+			return false
+		case errors.Is(err, syscall.ECONNREFUSED):
+			return true
+		}
+		return idempotent
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// transportError 是发送请求或接收响应时发生的错误，例如连接被拒绝或超时
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("request failed: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// retryAfter 返回响应的 Retry-After 头要求等待的秒数，没有时返回 0。
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// sleepContext 等待 d 或者直到 ctx 结束，ctx 结束时返回它的错误。
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
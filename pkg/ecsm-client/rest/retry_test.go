@@ -0,0 +1,196 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fastRetryPolicy 是测试使用的重试策略，等待时间很短
+var fastRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+// newFlakyTestClient 创建一个客户端，它连接的服务器对前 failures 个请求返回 status，之后返回成功。
+func newFlakyTestClient(t *testing.T, failures int32, status int) (*RESTClient, *atomic.Int32) {
+	var requests atomic.Int32
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(status)
+			w.Write([]byte("<html>gateway error</html>"))
+			return
+		}
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+	client.SetRetryPolicy(fastRetryPolicy)
+	return client, &requests
+}
+
+// TestRetry_StatusCodes 测试不同状态码和方法的请求是否重试。
+func TestRetry_StatusCodes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		verb         string
+		failures     int32
+		status       int
+		wantRequests int32
+		wantErr      bool
+	}{
+		{name: "GET recovers from 503", verb: "GET", failures: 2, status: http.StatusServiceUnavailable, wantRequests: 3},
+		{name: "GET gives up after max attempts", verb: "GET", failures: 5, status: http.StatusBadGateway, wantRequests: 3, wantErr: true},
+		{name: "DELETE recovers from 500", verb: "DELETE", failures: 1, status: http.StatusInternalServerError, wantRequests: 2},
+		{name: "POST with an idempotency key recovers from 500", verb: "POST", failures: 1, status: http.StatusInternalServerError, wantRequests: 2},
+		{name: "PATCH is not retried on 500", verb: "PATCH", failures: 1, status: http.StatusInternalServerError, wantRequests: 1, wantErr: true},
+		{name: "POST is retried on 429", verb: "POST", failures: 1, status: http.StatusTooManyRequests, wantRequests: 2},
+		{name: "404 is not retried", verb: "GET", failures: 1, status: http.StatusNotFound, wantRequests: 1, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, requests := newFlakyTestClient(t, tc.failures, tc.status)
+			err := client.Verb(tc.verb).Resource("service").Body(map[string]string{"name": "web"}).Do(context.Background()).Into(nil)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if n := requests.Load(); n != tc.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tc.wantRequests, n)
+			}
+		})
+	}
+}
+
+// TestRetry_RequestOverride 测试 Request.Retry 代替客户端的重试策略。
+func TestRetry_RequestOverride(t *testing.T) {
+	client, requests := newFlakyTestClient(t, 1, http.StatusServiceUnavailable)
+	if err := client.Get().Resource("node").Retry(RetryPolicy{}).Do(context.Background()).Into(nil); err == nil {
+		t.Errorf("Expected the 503 without retries")
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected 1 request, got %d", n)
+	}
+}
+
+// TestRetry_IdempotencyKey 测试启用重试的 POST 请求的每次尝试携带同一个幂等键，不同的请求使用不同的键，
+// 不重试或禁用幂等键时不携带幂等键，也不在 5xx 之后重试。
+func TestRetry_IdempotencyKey(t *testing.T) {
+	var keys []string
+	var failures atomic.Int32
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+	client.SetRetryPolicy(fastRetryPolicy)
+	post := func(failing int32, policy *RetryPolicy, key string) error {
+		keys = nil
+		failures.Store(failing)
+		req := client.Post().Resource("service").Body(map[string]string{"name": "web"})
+		if policy != nil {
+			req.Retry(*policy)
+		}
+		if key != "" {
+			req.IdempotencyKey(key)
+		}
+		return req.Do(context.Background()).Into(nil)
+	}
+
+	if err := post(2, nil, ""); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if len(keys) != 3 || keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Fatalf("Expected 3 attempts with the same idempotency key, got %q", keys)
+	}
+	first := keys[0]
+	if post(0, nil, ""); keys[0] == first {
+		t.Errorf("Expected a new idempotency key for a new request")
+	}
+	if post(0, nil, "create-web"); keys[0] != "create-web" {
+		t.Errorf("Expected the caller's idempotency key, got %q", keys[0])
+	}
+
+	if err := post(1, &RetryPolicy{}, ""); err == nil || len(keys) != 1 || keys[0] != "" {
+		t.Errorf("Expected a single attempt without an idempotency key, got %v with %q", err, keys)
+	}
+	noKeys := fastRetryPolicy
+	noKeys.NoIdempotencyKeys = true
+	if err := post(1, &noKeys, ""); err == nil || len(keys) != 1 || keys[0] != "" {
+		t.Errorf("Expected a single attempt without an idempotency key, got %v with %q", err, keys)
+	}
+}
+
+// TestRetry_ContextCanceled 测试等待重试时 ctx 结束会立即返回。
+func TestRetry_ContextCanceled(t *testing.T) {
+	client, requests := newFlakyTestClient(t, 5, http.StatusServiceUnavailable)
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := client.Get().Resource("node").Do(ctx).Into(nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Request returned after %v, want it to stop waiting when the context ends", elapsed)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected 1 request, got %d", n)
+	}
+}
+
+// TestShouldRetry_Errors 测试哪些发送请求的错误被重试。
+func TestShouldRetry_Errors(t *testing.T) {
+	refused := &transportError{err: &url.Error{Op: "Post", URL: "http://ecsm", Err: syscall.ECONNREFUSED}}
+	reset := &transportError{err: &url.Error{Op: "Post", URL: "http://ecsm", Err: syscall.ECONNRESET}}
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	testCases := []struct {
+		name       string
+		ctx        context.Context
+		idempotent bool
+		err        error
+		want       bool
+	}{
+		{name: "non-idempotent connection refused", ctx: ctx, idempotent: false, err: refused, want: true},
+		{name: "non-idempotent connection reset", ctx: ctx, idempotent: false, err: reset, want: false},
+		{name: "idempotent connection reset", ctx: ctx, idempotent: true, err: reset, want: true},
+		{name: "context canceled", ctx: canceled, idempotent: true, err: reset, want: false},
+		{name: "credentials error wrapping a transport error", ctx: ctx, idempotent: true, err: errors.Join(errors.New("failed to log in"), reset), want: false},
+	}
+	for _, tc := range testCases {
+		if got := shouldRetry(tc.ctx, tc.idempotent, nil, tc.err); got != tc.want {
+			t.Errorf("%s: shouldRetry = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestRetryPolicy_Backoff 测试等待时间的翻倍、上限、随机减少和 Retry-After。
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 30: time.Second} {
+		if got := p.backoff(attempt, 0); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+
+	if got := p.backoff(1, 500*time.Millisecond); got != 500*time.Millisecond {
+		t.Errorf("Expected Retry-After to extend the delay, got %v", got)
+	}
+	if got := p.backoff(1, time.Minute); got != 100*time.Millisecond {
+		t.Errorf("Expected Retry-After beyond MaxDelay to be ignored, got %v", got)
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(2, 0); got < 100*time.Millisecond || got > 200*time.Millisecond {
+			t.Fatalf("backoff with jitter = %v, want it in [100ms, 200ms]", got)
+		}
+	}
+}
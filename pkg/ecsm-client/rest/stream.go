@@ -0,0 +1,82 @@
+// file: pkg/ecsm-client/rest/stream.go
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Stream 返回响应体本身，不解码 ECSM 的响应信封，用于日志流和大量数据的导出等边接收边处理的响应。
+// 调用方必须关闭返回的 ReadCloser，在那之前请求一直占用一个并发名额。
+//
+// 4xx 和 5xx 响应不会返回响应体，而是和 Into 一样返回解码得到的错误。
+// ctx 被取消后，响应体被关闭，之后的读取返回 ctx 的错误。
+func (r *Result) Stream(ctx context.Context) (io.ReadCloser, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.statusCode >= http.StatusBadRequest {
+		_, err := r.transformAndGetRawData()
+		if err == nil {
+			err = &Aerror{Status: r.statusCode, Message: http.StatusText(r.statusCode)}
+		}
+		return nil, err
+	}
+
+	s := &stream{ReadCloser: r.body, ctx: ctx}
+	s.stop = context.AfterFunc(ctx, func() { r.body.Close() })
+	return s, nil
+}
+
+// stream 在 ctx 被取消时关闭响应体，使阻塞的读取立即返回。
+type stream struct {
+	io.ReadCloser
+	ctx  context.Context
+	stop func() bool
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && s.ctx.Err() != nil {
+		return n, s.ctx.Err()
+	}
+	return n, err
+}
+
+func (s *stream) Close() error {
+	if !s.stop() {
+		// 响应体已经因为 ctx 被取消而关闭
+		return nil
+	}
+	return s.ReadCloser.Close()
+}
+
+// StreamDecoder 逐个解码流中的 JSON 值，例如 NDJSON (每行一个 JSON 对象) 或分块发送的连续的 JSON 对象，
+// 使调用方不必等到整个响应结束就可以处理已经收到的数据。
+type StreamDecoder struct {
+	r   io.ReadCloser
+	dec *json.Decoder
+}
+
+// NewStreamDecoder 返回从 r 中解码 JSON 值的 StreamDecoder，r 通常是 Result.Stream 返回的响应体。
+func NewStreamDecoder(r io.ReadCloser) *StreamDecoder {
+	return &StreamDecoder{r: r, dec: json.NewDecoder(r)}
+}
+
+// Decode 把流中的下一个 JSON 值解码到 obj 中，阻塞到这个值被完整地接收。流正常结束时返回 io.EOF。
+func (d *StreamDecoder) Decode(obj interface{}) error {
+	err := d.dec.Decode(obj)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.New("stream ended in the middle of a JSON value")
+	}
+	return err
+}
+
+// Close 关闭底层的流。
+func (d *StreamDecoder) Close() error {
+	return d.r.Close()
+}
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type logLine struct {
+	Seq  int    `json:"seq"`
+	Line string `json:"line"`
+}
+
+// TestStream 测试流式响应可以在服务器发送完之前被逐条解码。
+func TestStream(t *testing.T) {
+	received := make(chan struct{})
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"seq":1,"line":"starting"}` + "\n"))
+		w.(http.Flusher).Flush()
+		// 客户端收到第一条之后才发送剩下的内容
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Errorf("The first line was not decoded before the response ended")
+		}
+		w.Write([]byte("\n" + `{"seq":2,"line":"ready"}` + "\n"))
+	})
+
+	body, err := client.Get().Resource("container/logs").Name("c1").Do(context.Background()).Stream(context.Background())
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	dec := NewStreamDecoder(body)
+	defer dec.Close()
+
+	var lines []logLine
+	for {
+		var l logLine
+		err := dec.Decode(&l)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		lines = append(lines, l)
+		if l.Seq == 1 {
+			close(received)
+		}
+	}
+	if len(lines) != 2 || lines[1].Line != "ready" {
+		t.Errorf("Unexpected lines %+v", lines)
+	}
+}
+
+// TestStream_Error 测试错误的响应返回解码得到的错误，而不是响应体。
+func TestStream_Error(t *testing.T) {
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":404,"message":"container not found"}`))
+	})
+
+	body, err := client.Get().Resource("container/logs").Name("missing").Do(context.Background()).Stream(context.Background())
+	var apiErr *Aerror
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || body != nil {
+		t.Fatalf("Expected a 404 error and no body, got %v", err)
+	}
+}
+
+// TestStream_ContextCanceled 测试取消 ctx 使阻塞的读取返回 ctx 的错误。
+func TestStream_ContextCanceled(t *testing.T) {
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"seq":1}` + "\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	body, err := client.Get().Resource("container/logs").Name("c1").Do(context.Background()).Stream(ctx)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	dec := NewStreamDecoder(body)
+	defer dec.Close()
+
+	var l logLine
+	if err := dec.Decode(&l); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := dec.Decode(&l); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
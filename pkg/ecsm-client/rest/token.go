@@ -0,0 +1,18 @@
+// file: pkg/ecsm-client/rest/token.go
+
+package rest
+
+import "context"
+
+// TokenSource 提供请求使用的 Bearer Token，实现可以在 Token 过期前刷新它。
+// 它会被并发调用。
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticTokenSource 总是返回同一个 Token，用于通过标志或环境变量传入的 Token。
+type StaticTokenSource string
+
+func (s StaticTokenSource) Token(ctx context.Context) (string, error) {
+	return string(s), nil
+}
@@ -0,0 +1,77 @@
+// file: pkg/ecsm-client/rest/tracing.go
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName 是 REST 客户端创建的 span 的 instrumentation scope
+const tracerName = "github.com/fx147/ecsm-operator/pkg/ecsm-client/rest"
+
+// resourceKey 是 span 中记录 ECSM 资源的属性，取值与指标的 resource 标签相同
+const resourceKey = attribute.Key("ecsm.resource")
+
+// tracePropagator 把 span 的上下文以 W3C Trace Context (traceparent 头) 的形式注入到每个请求中，
+// 使 ECSM API Server 或中间的代理可以把它们的 span 关联到调用方的 trace 上
+var tracePropagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// SetTracerProvider 让客户端用 tp 创建请求的 span，tp 为 nil 时使用 OpenTelemetry 的全局 TracerProvider (默认)。
+// 每次 Do 创建一个 client span，它是调用方传入的 context 中的 span 的子 span，
+// 因此 reconcile 等调用方的 span 可以经过 Clientset 一直延伸到 ECSM API Server。它必须在客户端被使用之前调用。
+func (c *RESTClient) SetTracerProvider(tp trace.TracerProvider) {
+	c.tracerProvider = tp
+}
+
+// tracer 返回客户端创建 span 使用的 Tracer。
+func (c *RESTClient) tracer() trace.Tracer {
+	if c.tracerProvider != nil {
+		return c.tracerProvider.Tracer(tracerName)
+	}
+	return otel.Tracer(tracerName)
+}
+
+// startSpan 为请求创建一个 client span，返回包含它的 context。
+func (r *Request) startSpan(ctx context.Context, u *url.URL) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(r.verb),
+		semconv.URLFull(u.String()),
+		semconv.ServerAddress(u.Hostname()),
+		resourceKey.String(r.resource),
+	}
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		attrs = append(attrs, semconv.ServerPort(port))
+	}
+	return r.c.tracer().Start(ctx, r.verb+" "+r.resource,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+// endSpan 在 span 中记录请求的结果并结束它。4xx 和 5xx 响应与没有得到响应的请求一样被记录为错误。
+func endSpan(span trace.Span, result *Result) {
+	defer span.End()
+	if result.err != nil {
+		span.RecordError(result.err)
+		span.SetStatus(codes.Error, result.err.Error())
+		return
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(result.statusCode))
+	if result.statusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(result.statusCode))
+	}
+}
+
+// injectTraceHeaders 把 ctx 中的 span 上下文注入到 req 的头中。
+func injectTraceHeaders(ctx context.Context, req *http.Request) {
+	tracePropagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanAttr 返回 span 中 key 属性的值。
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// TestTracing 测试每个请求是调用方 span 的子 span，记录请求的属性和重试次数，并把 traceparent 头发送给服务器。
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer tp.Shutdown(context.Background())
+
+	var traceparents []string
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if len(traceparents) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":200,"message":"success"}`))
+	})
+	client.SetTracerProvider(tp)
+	client.SetRetryPolicy(fastRetryPolicy)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "reconcile")
+	err := client.Get().Resource("service").Name("0f4b1c").Do(ctx).Into(nil)
+	parent.End()
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	var span sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Name() == "GET service" {
+			span = s
+		}
+	}
+	if span == nil {
+		t.Fatalf("Expected a span named %q", "GET service")
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() || span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("Expected a client span under the caller's span, got parent %v and kind %v", span.Parent().SpanID(), span.SpanKind())
+	}
+	if got := spanAttr(span, "http.response.status_code").AsInt64(); got != 200 {
+		t.Errorf("Expected status code 200, got %d", got)
+	}
+	if got := spanAttr(span, "http.request.resend_count").AsInt64(); got != 1 {
+		t.Errorf("Expected resend count 1, got %d", got)
+	}
+	if got := spanAttr(span, "url.full").AsString(); !strings.HasSuffix(got, "/api/v1/service/0f4b1c") {
+		t.Errorf("Unexpected url.full %q", got)
+	}
+
+	traceID := span.SpanContext().TraceID().String()
+	for i, tp := range traceparents {
+		if !strings.Contains(tp, traceID) {
+			t.Errorf("Attempt %d sent traceparent %q, want trace ID %s", i+1, tp, traceID)
+		}
+	}
+}
+
+// TestTracing_Error 测试错误的响应把 span 标记为错误。
+func TestTracing_Error(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer tp.Shutdown(context.Background())
+
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	client.SetTracerProvider(tp)
+	client.Delete().Resource("service").Name("missing").Do(context.Background()).Into(nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Fatalf("Expected 1 span with an error status, got %d spans", len(spans))
+	}
+	if got := spanAttr(spans[0], "http.response.status_code").AsInt64(); got != 404 {
+		t.Errorf("Expected status code 404, got %d", got)
+	}
+}
@@ -0,0 +1,119 @@
+// file: pkg/ecsm-client/rest/websocket.go
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/klog/v2"
+)
+
+// DialWebSocket 把 GET 请求升级为 WebSocket 连接，用于容器控制台 attach 和事件推送等双向的长连接接口。
+//
+// 连接与客户端的其它请求使用相同的服务器地址 (http 对应 ws，https 对应 wss)、TLS 设置、代理和认证：
+// 握手请求携带 AuthProvider 提供的凭据，被以 401 拒绝时按照 Reauthorize 的结果重新认证并重试一次。
+// 握手受客户端的限速约束，但建立的连接不占用并发名额，也不经过拦截器链和重试策略。
+// 握手失败时返回的错误与 Into 一样由响应解码得到，例如 *Aerror。调用方负责关闭返回的连接。
+func (r *Request) DialWebSocket(ctx context.Context) (*websocket.Conn, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.verb != http.MethodGet {
+		return nil, fmt.Errorf("WebSocket requests must use GET, not %s", r.verb)
+	}
+	if r.body != nil || r.bodyReader != nil {
+		return nil, errors.New("WebSocket requests cannot have a body")
+	}
+
+	// Timeout 只限制握手，不限制建立的连接
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	fullURL := r.url()
+	wsURL := *fullURL
+	if fullURL.Scheme == "https" {
+		wsURL.Scheme = "wss"
+	} else {
+		wsURL.Scheme = "ws"
+	}
+
+	// 凭据和 trace 头添加在一个普通的 HTTP 请求上，握手请求使用它的头
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	injectTraceHeaders(ctx, req)
+
+	if err := r.c.throttle(ctx); err != nil {
+		return nil, fmt.Errorf("request failed while waiting for client-side rate limiting: %w", err)
+	}
+
+	dialer := r.c.webSocketDialer()
+	conn, resp, authorized, err := r.handshake(ctx, dialer, &wsURL, req)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusUnauthorized && r.c.authProvider != nil {
+		retry, rerr := r.c.authProvider.Reauthorize(ctx, authorized)
+		if rerr != nil {
+			return nil, &authError{msg: "request was rejected as unauthorized and reauthorization failed", err: rerr}
+		}
+		if retry {
+			klog.V(4).InfoS("Retrying WebSocket handshake with new credentials", "url", fullURL)
+			conn, resp, _, err = r.handshake(ctx, dialer, &wsURL, req)
+		}
+	}
+	if err != nil {
+		return nil, webSocketError(resp, err)
+	}
+	return conn, nil
+}
+
+// handshake 为 req 的副本添加凭据，然后以它的头与 wsURL 握手，返回添加了凭据的请求。
+func (r *Request) handshake(ctx context.Context, dialer *websocket.Dialer, wsURL *url.URL, req *http.Request) (*websocket.Conn, *http.Response, *http.Request, error) {
+	authorized := req.Clone(ctx)
+	if r.c.authProvider != nil {
+		if err := r.c.authProvider.Authorize(ctx, authorized); err != nil {
+			return nil, nil, nil, &authError{msg: "failed to get credentials", err: err}
+		}
+	}
+	klog.V(4).InfoS("Opening WebSocket connection", "url", wsURL)
+	conn, resp, err := dialer.DialContext(ctx, wsURL.String(), authorized.Header)
+	return conn, resp, authorized, err
+}
+
+// webSocketError 把握手失败的响应转换为与 Into 相同的错误。
+func webSocketError(resp *http.Response, err error) error {
+	var authErr *authError
+	if errors.As(err, &authErr) {
+		return authErr
+	}
+	if resp == nil {
+		return &transportError{err: err}
+	}
+	result := &Result{body: resp.Body, statusCode: resp.StatusCode, header: resp.Header}
+	if _, decodeErr := result.transformAndGetRawData(); decodeErr != nil {
+		return decodeErr
+	}
+	return fmt.Errorf("WebSocket handshake failed with status %d: %w", resp.StatusCode, err)
+}
+
+// webSocketDialer 返回与客户端的 Transport 使用相同的 TLS 设置、代理和拨号函数的 Dialer。
+func (c *RESTClient) webSocketDialer() *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+	rt := c.transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if t, ok := rt.(*http.Transport); ok {
+		dialer.Proxy = t.Proxy
+		dialer.NetDialContext = t.DialContext
+		dialer.TLSClientConfig = t.TLSClientConfig
+	}
+	return &dialer
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestDialWebSocket 测试 WebSocket 连接使用客户端的 TLS 设置和认证，凭据被拒绝后重新登录并重试握手。
+func TestDialWebSocket(t *testing.T) {
+	var logins atomic.Int32
+	login := func(ctx context.Context) (*Credentials, error) {
+		return &Credentials{Token: fmt.Sprintf("t%d", logins.Add(1))}, nil
+	}
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/container/console/c1" || r.URL.Query().Get("tty") != "true" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		// 第一次登录得到的凭据已经失效
+		if r.Header.Get("Authorization") != "Bearer t2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			mt, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(mt, msg)
+		}
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	client, err := RESTClientFor(configFor(t, server, TLSConfig{CAData: caData}))
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+	client.SetAuthProvider(NewSessionAuthProvider(login))
+
+	conn, err := client.Get().Resource("container/console").Name("c1").Param("tty", "true").DialWebSocket(context.Background())
+	if err != nil {
+		t.Fatalf("DialWebSocket failed: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("ls\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != "ls\n" {
+		t.Errorf("Expected the echo %q, got %q (%v)", "ls\n", msg, err)
+	}
+	if n := logins.Load(); n != 2 {
+		t.Errorf("Expected 1 relogin after the handshake was rejected, got %d logins in total", n)
+	}
+}
+
+// TestDialWebSocket_Error 测试握手失败时返回解码响应得到的错误。
+func TestDialWebSocket_Error(t *testing.T) {
+	client := newAuthTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":404,"message":"container not found"}`))
+	})
+
+	_, err := client.Get().Resource("container/console").Name("missing").DialWebSocket(context.Background())
+	var apiErr *Aerror
+	if !errors.As(err, &apiErr) || apiErr.Message != "container not found" {
+		t.Fatalf("Expected the decoded API error, got %v", err)
+	}
+
+	if _, err := client.Post().Resource("container/console").DialWebSocket(context.Background()); err == nil {
+		t.Errorf("Expected an error for a POST WebSocket request")
+	}
+}
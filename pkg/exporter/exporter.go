@@ -0,0 +1,275 @@
+// file: pkg/exporter/exporter.go
+
+// Package exporter 把 ECSM 平台自身的节点、容器和服务指标转换为 Prometheus 指标，
+// 使已有的监控系统可以直接采集边缘集群的运行状况。
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// scrapeTimeout 是抓取一个集群的最长时间
+const scrapeTimeout = 30 * time.Second
+
+var (
+	nodeUpDesc = newDesc("node", "up",
+		"Whether the platform node is online (1) or not (0).", "node", "address", "arch")
+	nodeCPUPercentDesc = newDesc("node", "cpu_percent",
+		"CPU usage of the platform node in percent.", "node")
+	nodeMemoryPercentDesc = newDesc("node", "memory_percent",
+		"Memory usage of the platform node in percent.", "node")
+	nodeMemoryBytesDesc = newDesc("node", "memory_bytes",
+		"Memory used on the platform node in bytes.", "node")
+	nodeDiskPercentDesc = newDesc("node", "disk_percent",
+		"Disk usage of the platform node in percent.", "node")
+	nodeContainersDesc = newDesc("node", "containers",
+		"Number of containers on the platform node per state (running, stopped).", "node", "state")
+
+	containerCPUPercentDesc = newDesc("container", "cpu_percent",
+		"CPU usage of the container in percent.", "container", "service", "node")
+	containerMemoryBytesDesc = newDesc("container", "memory_bytes",
+		"Memory used by the container in bytes.", "container", "service", "node")
+	containerMemoryLimitBytesDesc = newDesc("container", "memory_limit_bytes",
+		"Memory limit of the container in bytes.", "container", "service", "node")
+	containerDiskBytesDesc = newDesc("container", "disk_bytes",
+		"Disk space used by the container in bytes.", "container", "service", "node")
+	containerRestartsDesc = newDesc("container", "restarts_total",
+		"Number of times the platform restarted the container.", "container", "service", "node")
+
+	serviceReplicasDesc = newDesc("service", "replicas",
+		"Number of instances the platform service should run.", "service")
+	serviceReadyReplicasDesc = newDesc("service", "ready_replicas",
+		"Number of online instances of the platform service.", "service")
+
+	scrapeSuccessDesc = newDesc("exporter", "scrape_success",
+		"Whether the last scrape of the cluster succeeded (1) or failed at least partially (0).")
+	lastScrapeDesc = newDesc("exporter", "last_scrape_timestamp_seconds",
+		"Unix time of the last scrape of the cluster.")
+)
+
+// newDesc 创建一个带有 cluster 标签的指标描述
+func newDesc(subsystem, name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, subsystem, name),
+		help,
+		append([]string{"cluster"}, labels...),
+		nil,
+	)
+}
+
+// snapshot 是一次抓取一个集群得到的数据
+type snapshot struct {
+	time time.Time
+	// err 是抓取中遇到的错误，抓取部分失败时其余的数据仍然可用
+	err error
+
+	nodes       []clientset.NodeInfo
+	nodeMetrics map[string]*clientset.NodeMetrics
+	containers  []clientset.ContainerInfo
+	services    []clientset.ProvisionListRow
+}
+
+// Exporter 周期性地通过 clientset 抓取每个集群的节点、容器和服务的指标，并作为 prometheus.Collector 提供它们。
+// Prometheus 采集时只读取最近一次抓取的结果，不会请求 ECSM API Server，因此采集的频率不影响平台的负载。
+type Exporter struct {
+	clusters map[string]clientset.Interface
+	period   time.Duration
+	clock    func() time.Time
+
+	// snapshots 以集群名称为索引
+	snapshots map[string]*snapshot
+	lock      sync.RWMutex
+}
+
+var _ prometheus.Collector = &Exporter{}
+
+// New 创建一个抓取 clusters 中所有集群的 Exporter，clusters 以集群名称为索引。
+func New(clusters map[string]clientset.Interface, period time.Duration) *Exporter {
+	return &Exporter{
+		clusters:  clusters,
+		period:    period,
+		clock:     time.Now,
+		snapshots: make(map[string]*snapshot),
+	}
+}
+
+// Run 启动周期性抓取，直到 stopCh 被关闭。
+func (e *Exporter) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting platform metrics exporter")
+	defer klog.Info("Shutting down platform metrics exporter")
+
+	wait.Until(func() {
+		var wg sync.WaitGroup
+		for name, client := range e.clusters {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+				defer cancel()
+
+				s := e.scrape(ctx, client)
+				if s.err != nil {
+					runtime.HandleError(fmt.Errorf("failed to scrape metrics of cluster %q: %w", name, s.err))
+				}
+				e.lock.Lock()
+				e.snapshots[name] = s
+				e.lock.Unlock()
+			}()
+		}
+		wg.Wait()
+	}, e.period, stopCh)
+}
+
+// scrape 抓取一个集群。一部分请求失败时继续抓取其余的数据，返回的 snapshot 中记录了第一个错误。
+func (e *Exporter) scrape(ctx context.Context, client clientset.Interface) *snapshot {
+	s := &snapshot{time: e.clock(), nodeMetrics: make(map[string]*clientset.NodeMetrics)}
+	setErr := func(err error) {
+		if s.err == nil {
+			s.err = err
+		}
+	}
+
+	nodes, err := client.Nodes().ListAll(ctx, clientset.NodeListOptions{})
+	if err != nil {
+		setErr(fmt.Errorf("failed to list nodes: %w", err))
+	}
+	s.nodes = nodes
+
+	var nodeIDs []string
+	for _, node := range nodes {
+		nodeIDs = append(nodeIDs, node.ID)
+		if !strings.EqualFold(node.Status, "online") {
+			continue
+		}
+		// instant 查询返回当前时刻的一个采样点
+		samples, err := client.Nodes().GetNodeMetrics(ctx, clientset.NodeMetricsOptions{NodeID: node.ID, Instant: true})
+		if err != nil {
+			setErr(fmt.Errorf("failed to get metrics of node %s: %w", node.Name, err))
+			continue
+		}
+		if len(samples) > 0 {
+			s.nodeMetrics[node.ID] = &samples[len(samples)-1]
+		}
+	}
+
+	if len(nodeIDs) > 0 {
+		containers, err := client.Containers().ListAllByNode(ctx, clientset.ListContainersByNodeOptions{NodeIDs: nodeIDs})
+		if err != nil {
+			setErr(fmt.Errorf("failed to list containers: %w", err))
+		}
+		s.containers = containers
+	}
+
+	services, err := client.Services().ListAll(ctx, clientset.ListServicesOptions{})
+	if err != nil {
+		setErr(fmt.Errorf("failed to list services: %w", err))
+	}
+	s.services = services
+	return s
+}
+
+// Describe 实现 prometheus.Collector。
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		nodeUpDesc, nodeCPUPercentDesc, nodeMemoryPercentDesc, nodeMemoryBytesDesc, nodeDiskPercentDesc, nodeContainersDesc,
+		containerCPUPercentDesc, containerMemoryBytesDesc, containerMemoryLimitBytesDesc, containerDiskBytesDesc, containerRestartsDesc,
+		serviceReplicasDesc, serviceReadyReplicasDesc,
+		scrapeSuccessDesc, lastScrapeDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect 实现 prometheus.Collector，它输出每个集群最近一次抓取的结果。
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	names := make([]string, 0, len(e.snapshots))
+	for name := range e.snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		collectSnapshot(ch, name, e.snapshots[name])
+	}
+}
+
+func collectSnapshot(ch chan<- prometheus.Metric, cluster string, s *snapshot) {
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, append([]string{cluster}, labels...)...)
+	}
+
+	success := 1.0
+	if s.err != nil {
+		success = 0
+	}
+	gauge(scrapeSuccessDesc, success)
+	gauge(lastScrapeDesc, float64(s.time.UnixNano())/1e9)
+
+	for _, node := range s.nodes {
+		up := 0.0
+		if strings.EqualFold(node.Status, "online") {
+			up = 1
+		}
+		gauge(nodeUpDesc, up, node.Name, node.Address, node.Arch)
+		gauge(nodeContainersDesc, float64(node.ContainerRunning), node.Name, "running")
+		gauge(nodeContainersDesc, float64(node.ContainerTotal-node.ContainerRunning), node.Name, "stopped")
+
+		m := s.nodeMetrics[node.ID]
+		if m == nil {
+			continue
+		}
+		if v, ok := parsePercent(m.CPU.Percent); ok {
+			gauge(nodeCPUPercentDesc, v, node.Name)
+		}
+		if v, ok := parsePercent(m.RAM.Percent); ok {
+			gauge(nodeMemoryPercentDesc, v, node.Name)
+		}
+		gauge(nodeMemoryBytesDesc, m.RAM.Size, node.Name)
+		if v, ok := parsePercent(m.ROM.Percent); ok {
+			gauge(nodeDiskPercentDesc, v, node.Name)
+		}
+	}
+
+	for _, c := range s.containers {
+		labels := []string{c.Name, c.ServiceName, c.NodeName}
+		gauge(containerCPUPercentDesc, c.CPUUsage.Total, labels...)
+		gauge(containerMemoryBytesDesc, float64(c.MemoryUsage), labels...)
+		if c.MemoryLimit > 0 {
+			gauge(containerMemoryLimitBytesDesc, float64(c.MemoryLimit), labels...)
+		}
+		gauge(containerDiskBytesDesc, float64(c.SizeUsage), labels...)
+		ch <- prometheus.MustNewConstMetric(containerRestartsDesc, prometheus.CounterValue, float64(c.RestartCount), append([]string{cluster}, labels...)...)
+	}
+
+	for _, row := range s.services {
+		gauge(serviceReplicasDesc, float64(row.Factor), row.Name)
+		gauge(serviceReadyReplicasDesc, float64(row.InstanceOnline), row.Name)
+	}
+}
+
+// parsePercent 解析平台以字符串返回的百分比，例如 "12.5" 或 "12.5%"。
+func parsePercent(s string) (float64, bool) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
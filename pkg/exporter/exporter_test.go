@@ -0,0 +1,133 @@
+// file: pkg/exporter/exporter_test.go
+
+package exporter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gather 采集 e 的所有指标，以 "名称{cluster,其他标签值...}" 为索引返回它们的值。
+func gather(t *testing.T, e *Exporter) map[string]float64 {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(e); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			key := family.GetName() + "{"
+			for i, label := range m.GetLabel() {
+				if i > 0 {
+					key += ","
+				}
+				key += label.GetName() + "=" + label.GetValue()
+			}
+			key += "}"
+			values[key] = metricValue(m)
+		}
+	}
+	return values
+}
+
+func metricValue(m *dto.Metric) float64 {
+	if m.GetCounter() != nil {
+		return m.GetCounter().GetValue()
+	}
+	return m.GetGauge().GetValue()
+}
+
+func TestCollect(t *testing.T) {
+	e := New(nil, time.Minute)
+	e.snapshots["edge"] = &snapshot{
+		time: time.Unix(1000, 0),
+		nodes: []clientset.NodeInfo{
+			{ID: "n1", Name: "node-1", Address: "10.0.0.1", Arch: "arm64", Status: "online", ContainerTotal: 3, ContainerRunning: 2},
+			{ID: "n2", Name: "node-2", Address: "10.0.0.2", Arch: "x86_64", Status: "offline"},
+		},
+		nodeMetrics: map[string]*clientset.NodeMetrics{
+			"n1": {
+				CPU: clientset.MetricValue{Percent: "12.5"},
+				RAM: clientset.MetricValueWithSize{Percent: "40%", Size: 1 << 20},
+				ROM: clientset.MetricValueWithSize{Percent: "invalid"},
+			},
+		},
+		containers: []clientset.ContainerInfo{{
+			Name: "web-1", ServiceName: "web", NodeName: "node-1",
+			CPUUsage: clientset.CPUUsage{Total: 3.5}, MemoryUsage: 1024, RestartCount: 2,
+		}},
+		services: []clientset.ProvisionListRow{{Name: "web", Factor: 3, InstanceOnline: 2}},
+	}
+
+	values := gather(t, e)
+	want := map[string]float64{
+		"ecsm_exporter_scrape_success{cluster=edge}":                                          1,
+		"ecsm_exporter_last_scrape_timestamp_seconds{cluster=edge}":                           1000,
+		"ecsm_node_up{address=10.0.0.1,arch=arm64,cluster=edge,node=node-1}":                  1,
+		"ecsm_node_up{address=10.0.0.2,arch=x86_64,cluster=edge,node=node-2}":                 0,
+		"ecsm_node_containers{cluster=edge,node=node-1,state=running}":                        2,
+		"ecsm_node_containers{cluster=edge,node=node-1,state=stopped}":                        1,
+		"ecsm_node_cpu_percent{cluster=edge,node=node-1}":                                     12.5,
+		"ecsm_node_memory_percent{cluster=edge,node=node-1}":                                  40,
+		"ecsm_node_memory_bytes{cluster=edge,node=node-1}":                                    1 << 20,
+		"ecsm_container_cpu_percent{cluster=edge,container=web-1,node=node-1,service=web}":    3.5,
+		"ecsm_container_memory_bytes{cluster=edge,container=web-1,node=node-1,service=web}":   1024,
+		"ecsm_container_restarts_total{cluster=edge,container=web-1,node=node-1,service=web}": 2,
+		"ecsm_service_replicas{cluster=edge,service=web}":                                     3,
+		"ecsm_service_ready_replicas{cluster=edge,service=web}":                               2,
+	}
+	for key, v := range want {
+		got, ok := values[key]
+		if !ok {
+			t.Errorf("metric %s is missing", key)
+			continue
+		}
+		if got != v {
+			t.Errorf("%s = %v, want %v", key, got, v)
+		}
+	}
+	// 无法解析的百分比和没有限制的内存不输出
+	for _, key := range []string{
+		"ecsm_node_disk_percent{cluster=edge,node=node-1}",
+		"ecsm_node_cpu_percent{cluster=edge,node=node-2}",
+		"ecsm_container_memory_limit_bytes{cluster=edge,container=web-1,node=node-1,service=web}",
+	} {
+		if _, ok := values[key]; ok {
+			t.Errorf("unexpected metric %s", key)
+		}
+	}
+
+	e.snapshots["edge"].err = errors.New("boom")
+	if got := gather(t, e)["ecsm_exporter_scrape_success{cluster=edge}"]; got != 0 {
+		t.Errorf("scrape_success after a failed scrape = %v, want 0", got)
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"12.5", 12.5, true},
+		{" 40% ", 40, true},
+		{"", 0, false},
+		{"n/a", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePercent(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parsePercent(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// file: pkg/ftp/client.go
+
+// Package ftp 实现了一个最小的 FTP 客户端，用于通过 SylixOS 容器的 FTPD 服务器上传和下载文件。
+// 它只支持被动模式和二进制传输，足以传输单个文件。
+package ftp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Conn 是一个已经连接到 FTP 服务器的控制连接，它不是并发安全的。
+type Conn struct {
+	conn *textproto.Conn
+	// host 是控制连接的主机，数据连接也连接到它，而不是 PASV 响应中的地址，
+	// 因为服务器在 NAT 之后时响应中的地址通常不可达。
+	host    string
+	timeout time.Duration
+	// stop 取消 ctx 结束时关闭连接的回调
+	stop func() bool
+}
+
+// Dial 连接到 addr (host:port) 上的 FTP 服务器并读取欢迎信息。timeout 是建立每个连接的超时时间，
+// ctx 结束时连接被关闭，正在进行的传输会失败。
+func Dial(ctx context.Context, addr string, timeout time.Duration) (*Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	nc, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{
+		conn:    textproto.NewConn(nc),
+		host:    host,
+		timeout: timeout,
+		stop:    context.AfterFunc(ctx, func() { nc.Close() }),
+	}
+	if _, _, err := c.conn.ReadResponse(220); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("unexpected greeting from %s: %w", addr, err)
+	}
+	return c, nil
+}
+
+// cmd 发送一个命令并读取响应，响应码与 expectCode 不符时返回 *textproto.Error。
+// expectCode 的含义与 textproto.Reader.ReadResponse 相同，例如 2 接受所有 2xx 响应码。
+func (c *Conn) cmd(expectCode int, format string, args ...interface{}) (int, string, error) {
+	if err := c.conn.PrintfLine(format, args...); err != nil {
+		return 0, "", err
+	}
+	return c.conn.ReadResponse(expectCode)
+}
+
+// Login 使用 user 和 password 登录，并把传输类型设置为二进制。
+func (c *Conn) Login(user, password string) error {
+	code, _, err := c.cmd(0, "USER %s", user)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case 230:
+		// 不需要密码
+	case 331:
+		if _, _, err := c.cmd(2, "PASS %s", password); err != nil {
+			return fmt.Errorf("login failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("login failed: unexpected response %d to USER", code)
+	}
+
+	if _, _, err := c.cmd(2, "TYPE I"); err != nil {
+		return fmt.Errorf("failed to switch to binary mode: %w", err)
+	}
+	return nil
+}
+
+// Stor 把 r 的内容上传为服务器上的 path，已经存在的文件会被覆盖。
+func (c *Conn) Stor(path string, r io.Reader) error {
+	data, err := c.openData("STOR", path)
+	if err != nil {
+		return err
+	}
+	_, copyErr := io.Copy(data, r)
+	// 服务器在数据连接关闭后才会发送传输结果
+	if err := data.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if _, _, err := c.conn.ReadResponse(2); err != nil {
+		return fmt.Errorf("STOR %s: %w", path, err)
+	}
+	if copyErr != nil {
+		return fmt.Errorf("STOR %s: %w", path, copyErr)
+	}
+	return nil
+}
+
+// Retr 下载服务器上的 path 并写入 w。
+func (c *Conn) Retr(path string, w io.Writer) error {
+	data, err := c.openData("RETR", path)
+	if err != nil {
+		return err
+	}
+	_, copyErr := io.Copy(w, data)
+	data.Close()
+	if _, _, err := c.conn.ReadResponse(2); err != nil {
+		return fmt.Errorf("RETR %s: %w", path, err)
+	}
+	if copyErr != nil {
+		return fmt.Errorf("RETR %s: %w", path, copyErr)
+	}
+	return nil
+}
+
+// openData 以被动模式打开数据连接并发送 command，服务器接受后返回数据连接。
+func (c *Conn) openData(command, path string) (net.Conn, error) {
+	_, msg, err := c.cmd(227, "PASV")
+	if err != nil {
+		return nil, fmt.Errorf("failed to enter passive mode: %w", err)
+	}
+	port, err := parsePasvPort(msg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := net.DialTimeout("tcp", net.JoinHostPort(c.host, strconv.Itoa(port)), c.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data connection: %w", err)
+	}
+	if _, _, err := c.cmd(1, "%s %s", command, path); err != nil {
+		data.Close()
+		return nil, fmt.Errorf("%s %s: %w", command, path, err)
+	}
+	return data, nil
+}
+
+// parsePasvPort 从 "Entering Passive Mode (h1,h2,h3,h4,p1,p2)" 形式的 PASV 响应中解析端口。
+func parsePasvPort(msg string) (int, error) {
+	start, end := strings.Index(msg, "("), strings.LastIndex(msg, ")")
+	if start < 0 || end < start {
+		return 0, fmt.Errorf("invalid PASV response %q", msg)
+	}
+	fields := strings.Split(msg[start+1:end], ",")
+	if len(fields) != 6 {
+		return 0, fmt.Errorf("invalid PASV response %q", msg)
+	}
+	p1, err1 := strconv.Atoi(strings.TrimSpace(fields[4]))
+	p2, err2 := strconv.Atoi(strings.TrimSpace(fields[5]))
+	if err1 != nil || err2 != nil || p1 < 0 || p1 > 255 || p2 < 0 || p2 > 255 {
+		return 0, fmt.Errorf("invalid PASV response %q", msg)
+	}
+	return p1<<8 | p2, nil
+}
+
+// Quit 发送 QUIT 并关闭连接。
+func (c *Conn) Quit() error {
+	_, _, err := c.cmd(2, "QUIT")
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Close 直接关闭连接，不通知服务器。
+func (c *Conn) Close() error {
+	c.stop()
+	return c.conn.Close()
+}
@@ -0,0 +1,180 @@
+// file: pkg/ftp/client_test.go
+
+package ftp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer 是一个只支持 USER、PASS、TYPE、PASV、STOR、RETR 和 QUIT 的 FTP 服务器，文件保存在内存中。
+type fakeServer struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// start 在随机端口上启动服务器并返回它的地址。
+func (s *fakeServer) start(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+
+	reply("220 fake ftpd ready")
+	var user string
+	var pasv net.Listener
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		switch command {
+		case "USER":
+			user = arg
+			reply("331 password required")
+		case "PASS":
+			if user != "root" || arg != "root" {
+				reply("530 login incorrect")
+				continue
+			}
+			reply("230 logged in")
+		case "TYPE":
+			reply("200 type set to %s", arg)
+		case "PASV":
+			if pasv, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+				reply("425 cannot open data connection")
+				continue
+			}
+			port := pasv.Addr().(*net.TCPAddr).Port
+			// 返回一个不可达的地址，客户端应该连接到控制连接的主机
+			reply("227 Entering Passive Mode (10,0,0,1,%d,%d)", port>>8, port&0xff)
+		case "STOR", "RETR":
+			s.mu.Lock()
+			content, ok := s.files[arg]
+			s.mu.Unlock()
+			if command == "RETR" && !ok {
+				pasv.Close()
+				reply("550 %s: no such file", arg)
+				continue
+			}
+			reply("150 opening data connection")
+			data, err := pasv.Accept()
+			pasv.Close()
+			if err != nil {
+				return
+			}
+			if command == "STOR" {
+				content, _ = io.ReadAll(data)
+				s.mu.Lock()
+				s.files[arg] = content
+				s.mu.Unlock()
+			} else {
+				data.Write(content)
+			}
+			data.Close()
+			reply("226 transfer complete")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 %s not implemented", command)
+		}
+	}
+}
+
+// TestConn_StorRetr 测试上传的文件可以原样下载，以及下载不存在的文件返回服务器的错误。
+func TestConn_StorRetr(t *testing.T) {
+	server := &fakeServer{files: map[string][]byte{}}
+	addr := server.start(t)
+
+	c, err := Dial(context.Background(), addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	if err := c.Login("root", "root"); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	content := bytes.Repeat([]byte("ecsm\x00\xff"), 10000)
+	if err := c.Stor("/apps/app.bin", bytes.NewReader(content)); err != nil {
+		t.Fatalf("Stor failed: %v", err)
+	}
+	var got bytes.Buffer
+	if err := c.Retr("/apps/app.bin", &got); err != nil {
+		t.Fatalf("Retr failed: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), content) {
+		t.Errorf("Retr returned %d bytes, want the %d uploaded bytes", got.Len(), len(content))
+	}
+
+	err = c.Retr("/missing", io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Errorf("Expected the error of the server for a missing file, got %v", err)
+	}
+
+	if err := c.Quit(); err != nil {
+		t.Errorf("Quit failed: %v", err)
+	}
+}
+
+// TestConn_LoginFailed 测试错误的密码导致 Login 失败。
+func TestConn_LoginFailed(t *testing.T) {
+	server := &fakeServer{files: map[string][]byte{}}
+	addr := server.start(t)
+
+	c, err := Dial(context.Background(), addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer c.Close()
+	err = c.Login("root", "wrong")
+	if err == nil || !strings.Contains(err.Error(), "530") {
+		t.Errorf("Expected login to fail with 530, got %v", err)
+	}
+}
+
+// TestParsePasvPort 测试 PASV 响应的解析。
+func TestParsePasvPort(t *testing.T) {
+	testCases := []struct {
+		msg     string
+		want    int
+		wantErr bool
+	}{
+		{msg: "Entering Passive Mode (192,168,1,10,195,80)", want: 195<<8 | 80},
+		{msg: "Entering Passive Mode (192,168,1,10, 4, 1).", want: 4<<8 | 1},
+		{msg: "Entering Passive Mode", wantErr: true},
+		{msg: "Entering Passive Mode (192,168,1,10,300,1)", wantErr: true},
+	}
+	for _, tc := range testCases {
+		got, err := parsePasvPort(tc.msg)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("parsePasvPort(%q) = %d, %v; want %d, error %t", tc.msg, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
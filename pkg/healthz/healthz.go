@@ -0,0 +1,111 @@
+// file: pkg/healthz/healthz.go
+
+package healthz
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// HealthChecker 是一项具名的健康检查。
+type HealthChecker interface {
+	// Name 返回检查项的名称，它会出现在 /healthz/<name> 路径和详细输出中。
+	Name() string
+	// Check 执行检查，返回 nil 表示健康。
+	Check(req *http.Request) error
+}
+
+// PingHealthz 总是返回健康，只用于确认 HTTP 服务本身仍在响应。
+var PingHealthz HealthChecker = ping{}
+
+type ping struct{}
+
+func (ping) Name() string                { return "ping" }
+func (ping) Check(_ *http.Request) error { return nil }
+
+// NamedCheck 用一个函数构造 HealthChecker。
+func NamedCheck(name string, check func(r *http.Request) error) HealthChecker {
+	return &healthzCheck{name: name, check: check}
+}
+
+type healthzCheck struct {
+	name  string
+	check func(r *http.Request) error
+}
+
+func (c *healthzCheck) Name() string                { return c.name }
+func (c *healthzCheck) Check(r *http.Request) error { return c.check(r) }
+
+// InstallHandler 在 mux 上注册 /healthz 以及每个检查项单独的 /healthz/<name>。
+func InstallHandler(mux *http.ServeMux, checks ...HealthChecker) {
+	InstallPathHandler(mux, "/healthz", checks...)
+}
+
+// InstallPathHandler 与 InstallHandler 相同，但使用指定的路径。
+func InstallPathHandler(mux *http.ServeMux, path string, checks ...HealthChecker) {
+	if len(checks) == 0 {
+		checks = []HealthChecker{PingHealthz}
+	}
+	klog.V(4).Infof("Installing health checkers for (%v): %v", path, checkerNames(checks...))
+
+	mux.Handle(path, handleRootHealth(path, checks...))
+	for _, check := range checks {
+		mux.Handle(fmt.Sprintf("%s/%v", path, check.Name()), adaptCheckToHandler(check.Check))
+	}
+}
+
+// handleRootHealth 依次执行所有检查，全部通过时返回 200 "ok"。
+// 请求带有 ?verbose 参数，或有检查失败时，会逐项输出结果。
+func handleRootHealth(path string, checks ...HealthChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var out bytes.Buffer
+		var failed []string
+		for _, check := range checks {
+			if err := check.Check(r); err != nil {
+				fmt.Fprintf(&out, "[-]%s failed: reason withheld\n", check.Name())
+				klog.V(2).Infof("%s check %q failed: %v", path, check.Name(), err)
+				failed = append(failed, check.Name())
+			} else {
+				fmt.Fprintf(&out, "[+]%s ok\n", check.Name())
+			}
+		}
+
+		if len(failed) > 0 {
+			klog.Warningf("%s check failed: %s", path, strings.Join(failed, ","))
+			http.Error(w, fmt.Sprintf("%s%s check failed", out.String(), strings.TrimPrefix(path, "/")), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if _, verbose := r.URL.Query()["verbose"]; !verbose {
+			fmt.Fprint(w, "ok")
+			return
+		}
+		out.WriteTo(w)
+		fmt.Fprintf(w, "%s check passed\n", strings.TrimPrefix(path, "/"))
+	}
+}
+
+// adaptCheckToHandler 把单个检查函数包装为 HTTP handler。
+func adaptCheckToHandler(c func(r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := c(r); err != nil {
+			http.Error(w, fmt.Sprintf("internal server error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}
+}
+
+func checkerNames(checks ...HealthChecker) []string {
+	names := make([]string, 0, len(checks))
+	for _, check := range checks {
+		names = append(names, check.Name())
+	}
+	return names
+}
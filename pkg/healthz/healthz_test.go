@@ -0,0 +1,46 @@
+// file: pkg/healthz/healthz_test.go
+
+package healthz
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInstallHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	healthy := NamedCheck("healthy", func(_ *http.Request) error { return nil })
+	broken := NamedCheck("broken", func(_ *http.Request) error { return errors.New("boom") })
+	InstallHandler(mux, healthy, broken)
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"/healthz", http.StatusInternalServerError, "[-]broken failed"},
+		{"/healthz/healthy", http.StatusOK, "ok"},
+		{"/healthz/broken", http.StatusInternalServerError, "boom"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantCode || !strings.Contains(rec.Body.String(), tt.wantBody) {
+			t.Errorf("GET %s = %d %q, want %d containing %q", tt.path, rec.Code, rec.Body.String(), tt.wantCode, tt.wantBody)
+		}
+	}
+}
+
+func TestInstallHandlerVerbose(t *testing.T) {
+	mux := http.NewServeMux()
+	InstallHandler(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz?verbose", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "[+]ping ok") {
+		t.Errorf("GET /healthz?verbose = %d %q, want ping check listed", rec.Code, rec.Body.String())
+	}
+}
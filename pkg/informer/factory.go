@@ -0,0 +1,109 @@
+// file: pkg/informer/factory.go
+
+package informer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+)
+
+// SharedInformerFactory 为所有控制器提供共享的 Informer。
+// 同一种资源只会创建一个 Informer，多个控制器在它上面注册各自的事件处理器，
+// 避免重复地 List Registry 或轮询 ECSM 平台。
+type SharedInformerFactory interface {
+	// Services 返回监听 Registry 中 ECSMService 的 Informer。
+	Services() Informer
+	// PlatformServices 返回监听 ECSM 平台上服务真实状态的 Informer。
+	PlatformServices() PlatformServiceInformer
+
+	// Start 启动所有已经被请求过、但尚未启动的 Informer。它不会阻塞。
+	Start(stopCh <-chan struct{})
+	// HasSynced 在所有已启动的 Informer 都完成第一次同步后返回 true。
+	HasSynced() bool
+	// Shutdown 等待所有已启动的 Informer 退出。调用方应该先关闭传给 Start 的 stopCh。
+	Shutdown()
+}
+
+// startable 是 Informer 和 PlatformServiceInformer 的公共部分
+type startable interface {
+	Run(stopCh <-chan struct{})
+	HasSynced() bool
+}
+
+type sharedInformerFactory struct {
+	registry     registry.Interface
+	ecsmClient   clientset.Interface
+	resyncPeriod time.Duration
+
+	lock             sync.Mutex
+	services         Informer
+	platformServices PlatformServiceInformer
+	// started 记录了已经启动的 Informer，防止重复启动
+	started map[startable]bool
+	wg      sync.WaitGroup
+}
+
+// NewSharedInformerFactory 创建一个新的 SharedInformerFactory。
+// resyncPeriod 同时用作 Registry 的全量同步周期和 ECSM 平台的轮询周期。
+func NewSharedInformerFactory(reg registry.Interface, ecsmClient clientset.Interface, resyncPeriod time.Duration) SharedInformerFactory {
+	return &sharedInformerFactory{
+		registry:     reg,
+		ecsmClient:   ecsmClient,
+		resyncPeriod: resyncPeriod,
+		started:      make(map[startable]bool),
+	}
+}
+
+func (f *sharedInformerFactory) Services() Informer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.services == nil {
+		f.services = NewInformer(f.registry, f.resyncPeriod)
+	}
+	return f.services
+}
+
+func (f *sharedInformerFactory) PlatformServices() PlatformServiceInformer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.platformServices == nil {
+		f.platformServices = NewPlatformServiceInformer(f.ecsmClient, f.resyncPeriod)
+	}
+	return f.platformServices
+}
+
+func (f *sharedInformerFactory) Start(stopCh <-chan struct{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, inf := range []startable{f.services, f.platformServices} {
+		// 未被请求过的 Informer 为 nil 接口
+		if inf == nil || f.started[inf] {
+			continue
+		}
+		f.started[inf] = true
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			inf.Run(stopCh)
+		}()
+	}
+}
+
+func (f *sharedInformerFactory) HasSynced() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for inf := range f.started {
+		if !inf.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *sharedInformerFactory) Shutdown() {
+	f.wg.Wait()
+}
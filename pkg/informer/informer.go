@@ -5,6 +5,7 @@ package informer
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
@@ -24,6 +25,8 @@ type Informer interface {
 	AddEventHandler(handler ResourceEventHandler)
 	// Run 启动 Informer 的主循环。
 	Run(stopCh <-chan struct{})
+	// HasSynced 在第一次全量同步完成后返回 true。
+	HasSynced() bool
 }
 
 // informer 是 Informer 接口的具体实现。
@@ -33,6 +36,7 @@ type informer struct {
 
 	// --- 我们的核心状态 ---
 	versionCache sync.Map // 线程安全的 "key -> resourceVersion" 缓存
+	synced       atomic.Bool
 
 	// --- 事件分发 ---
 	handlers    []ResourceEventHandler
@@ -51,6 +55,10 @@ func NewInformer(reg registry.Interface, resyncPeriod time.Duration) Informer {
 	return inf
 }
 
+func (i *informer) HasSynced() bool {
+	return i.synced.Load()
+}
+
 func (i *informer) AddEventHandler(handler ResourceEventHandler) {
 	i.handlerLock.Lock()
 	defer i.handlerLock.Unlock()
@@ -112,6 +120,11 @@ func (i *informer) watchLoop(stopCh <-chan struct{}) {
 
 // processEvent 处理单个实时事件
 func (i *informer) processEvent(event registry.Event) {
+	// Registry 会广播所有资源的变更，这个 Informer 只关心 ECSMService
+	if _, ok := event.Object.(*ecsmv1.ECSMService); !ok {
+		return
+	}
+
 	key := event.Key
 	newRV := event.ResourceVersion
 
@@ -143,7 +156,7 @@ func (i *informer) resync() {
 
 	// 1. 从 Registry 全量 List 所有对象和当前的全局版本
 	//    我们先只为 Service 实现
-	allServices, _, err := i.registry.ListAllServices(context.Background(), "") // 假设 "" 表示所有命名空间
+	allServices, _, err := i.registry.ListAllServices(context.Background(), "") // "" 表示所有命名空间
 	if err != nil {
 		klog.Errorf("Failed to list services for resync: %v", err)
 		return
@@ -195,5 +208,6 @@ func (i *informer) resync() {
 		i.versionCache.Store(key, rv)
 	}
 
+	i.synced.Store(true)
 	klog.V(4).Infof("Informer resync complete.")
 }
@@ -0,0 +1,105 @@
+// file: pkg/informer/platform_informer.go
+
+package informer
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// PlatformServiceInformer 监听 ECSM 平台上服务的真实状态 (现实世界)。
+// ECSM 没有 watch 接口，所以它通过周期性的全量 List 并与上一次的快照比较来产生事件。
+// 事件中的对象是 *clientset.ProvisionListRow。
+type PlatformServiceInformer interface {
+	// AddEventHandler 注册一个事件处理器。
+	AddEventHandler(handler ResourceEventHandler)
+	// Run 启动 Informer 的主循环。
+	Run(stopCh <-chan struct{})
+	// HasSynced 在第一次成功列出平台服务后返回 true。
+	HasSynced() bool
+}
+
+type platformServiceInformer struct {
+	client       clientset.Interface
+	resyncPeriod time.Duration
+
+	// snapshot 是上一次 List 的结果，以平台服务 ID 为 key
+	snapshot map[string]clientset.ProvisionListRow
+	synced   atomic.Bool
+
+	handlers    []ResourceEventHandler
+	handlerLock sync.RWMutex
+}
+
+// NewPlatformServiceInformer 创建一个新的 PlatformServiceInformer。
+func NewPlatformServiceInformer(client clientset.Interface, resyncPeriod time.Duration) PlatformServiceInformer {
+	return &platformServiceInformer{
+		client:       client,
+		resyncPeriod: resyncPeriod,
+		snapshot:     make(map[string]clientset.ProvisionListRow),
+	}
+}
+
+func (i *platformServiceInformer) HasSynced() bool {
+	return i.synced.Load()
+}
+
+func (i *platformServiceInformer) AddEventHandler(handler ResourceEventHandler) {
+	i.handlerLock.Lock()
+	defer i.handlerLock.Unlock()
+	i.handlers = append(i.handlers, handler)
+}
+
+func (i *platformServiceInformer) Run(stopCh <-chan struct{}) {
+	klog.Infof("Starting platform service informer...")
+	wait.Until(i.poll, i.resyncPeriod, stopCh)
+	klog.Infof("Shutting down platform service informer...")
+}
+
+// poll 列出平台上的所有服务，并与上一次的快照比较，分发 Add/Update/Delete 事件。
+func (i *platformServiceInformer) poll() {
+	rows, err := i.client.Services().ListAll(context.Background(), clientset.ListServicesOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list platform services: %v", err)
+		return
+	}
+
+	current := make(map[string]clientset.ProvisionListRow, len(rows))
+	for _, row := range rows {
+		current[row.ID] = row
+	}
+
+	i.handlerLock.RLock()
+	defer i.handlerLock.RUnlock()
+
+	for id, row := range current {
+		old, exists := i.snapshot[id]
+		switch {
+		case !exists:
+			for _, h := range i.handlers {
+				h.OnAdd(&row, false)
+			}
+		case !reflect.DeepEqual(old, row):
+			for _, h := range i.handlers {
+				h.OnUpdate(&old, &row)
+			}
+		}
+	}
+	for id, old := range i.snapshot {
+		if _, exists := current[id]; !exists {
+			for _, h := range i.handlers {
+				h.OnDelete(&old)
+			}
+		}
+	}
+
+	i.snapshot = current
+	i.synced.Store(true)
+}
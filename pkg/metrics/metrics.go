@@ -0,0 +1,31 @@
+// file: pkg/metrics/metrics.go
+
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 是 ecsm-operator 所有指标名称的前缀
+const Namespace = "ecsm"
+
+// Registry 是 ecsm-operator 进程内所有指标的注册表。
+// 各个包在 init 中把自己的指标注册到这里，operator 通过 Handler 把它们暴露在 /metrics 上。
+// 我们不使用 prometheus 的全局默认注册表，以免引入的第三方库向其中注册无关的指标。
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler 返回暴露 Registry 中所有指标的 HTTP handler。
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
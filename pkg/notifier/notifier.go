@@ -0,0 +1,379 @@
+// file: pkg/notifier/notifier.go
+
+// Package notifier 把控制器记录的事件推送到 ECSMNotification 中配置的 HTTP webhook，
+// 使运维人员可以在 Slack、钉钉等工具中及时看到发布完成、服务降级、节点失联等生命周期事件。
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultMaxPerMinute 是没有设置 maxPerMinute 时每分钟最多发送的通知数量
+	DefaultMaxPerMinute = 10
+
+	// DefaultTemplate 是没有设置 template 时消息文本的模板
+	DefaultTemplate = `[{{.Type}}] {{.InvolvedObject.Kind}} {{with .InvolvedObject.Namespace}}{{.}}/{{end}}{{.InvolvedObject.Name}}: {{.Reason}} - {{.Message}}`
+
+	// defaultTimeout 是没有设置 timeoutSeconds 时一次请求的超时时间
+	defaultTimeout = 10 * time.Second
+
+	// queueSize 是每个 ECSMNotification 等待发送的通知数量的上限，超过时新的通知被丢弃
+	queueSize = 100
+
+	// syncPeriod 是重新读取 ECSMNotification 和写回发送状态的周期
+	syncPeriod = 10 * time.Second
+)
+
+// Notifier 订阅 Registry 中的 Event，把被 ECSMNotification 选中的事件发送到它们的 webhook。
+// 每个 ECSMNotification 有自己的发送队列和限速器，一个缓慢的 webhook 不会影响其他通知。
+// 发送失败的通知不会重试，发送情况定期写回 ECSMNotification 的 status。
+type Notifier struct {
+	registry registry.Interface
+	clock    func() time.Time
+
+	lock sync.Mutex
+	// senders 以 ECSMNotification 的名称为索引
+	senders map[string]*sender
+}
+
+// New 创建一个使用 reg 中的 ECSMNotification 的 Notifier。
+func New(reg registry.Interface) *Notifier {
+	return &Notifier{
+		registry: reg,
+		clock:    time.Now,
+		senders:  make(map[string]*sender),
+	}
+}
+
+// Run 开始发送通知，直到 stopCh 被关闭。
+func (n *Notifier) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Info("Starting notifier")
+	defer klog.Info("Shutting down notifier")
+
+	events, cancel := n.registry.Subscribe()
+	defer cancel()
+
+	// 周期性地同步 ECSMNotification 是订阅丢失事件时的安全网，同时写回发送状态
+	go wait.Until(func() {
+		n.sync()
+		n.flushStatus()
+	}, syncPeriod, stopCh)
+
+	for {
+		select {
+		case <-stopCh:
+			n.lock.Lock()
+			for name, s := range n.senders {
+				delete(n.senders, name)
+				s.stop()
+			}
+			n.lock.Unlock()
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			switch obj := event.Object.(type) {
+			case *ecsmv1.Event:
+				// 被合并的重复事件以 MODIFIED 发布，它们同样需要通知；删除只是事件过期被清理
+				if event.Type != registry.Deleted {
+					n.notify(obj)
+				}
+			case *ecsmv1.ECSMNotification:
+				n.sync()
+			}
+		}
+	}
+}
+
+// sync 根据 Registry 中的 ECSMNotification 创建、替换或停止 sender。
+// status 的变化不会修改 generation，因此写回状态不会重建 sender。
+func (n *Notifier) sync() {
+	list, _, err := n.registry.ListNotifications(context.Background())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list ECSMNotifications: %w", err))
+		return
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	seen := sets.New[string]()
+	for i := range list.Items {
+		notification := &list.Items[i]
+		seen.Insert(notification.Name)
+		old := n.senders[notification.Name]
+		if old != nil && old.uid == notification.UID && old.generation == notification.Generation {
+			continue
+		}
+		s, err := newSender(notification, n.clock)
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("invalid ECSMNotification %s: %w", notification.Name, err))
+			continue
+		}
+		if old != nil {
+			old.stop()
+			if old.uid == notification.UID {
+				// 还没有写回的计数由新的 sender 继续累计
+				s.status = old.currentStatus()
+				s.dirty = true
+			}
+		}
+		n.senders[notification.Name] = s
+	}
+	for name, s := range n.senders {
+		if !seen.Has(name) {
+			delete(n.senders, name)
+			s.stop()
+		}
+	}
+}
+
+// notify 把一个事件交给所有选中它的 sender。
+func (n *Notifier) notify(event *ecsmv1.Event) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for _, s := range n.senders {
+		if s.spec.Suspend || !Matches(&s.spec.Selector, event) {
+			continue
+		}
+		s.enqueue(event)
+	}
+}
+
+// flushStatus 把发送状态有变化的 sender 的状态写回 Registry。
+func (n *Notifier) flushStatus() {
+	n.lock.Lock()
+	senders := make([]*sender, 0, len(n.senders))
+	for _, s := range n.senders {
+		senders = append(senders, s)
+	}
+	n.lock.Unlock()
+
+	for _, s := range senders {
+		s.lock.Lock()
+		if !s.dirty {
+			s.lock.Unlock()
+			continue
+		}
+		status := *s.status.DeepCopy()
+		s.dirty = false
+		s.lock.Unlock()
+
+		notification := &ecsmv1.ECSMNotification{ObjectMeta: metav1.ObjectMeta{Name: s.name}, Status: status}
+		if _, err := n.registry.UpdateNotificationStatus(context.Background(), notification); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			runtime.HandleError(fmt.Errorf("failed to update status of ECSMNotification %s: %w", s.name, err))
+			s.lock.Lock()
+			s.dirty = true
+			s.lock.Unlock()
+		}
+	}
+}
+
+// Matches 返回 selector 是否选中了 event。
+func Matches(selector *ecsmv1.NotificationSelector, event *ecsmv1.Event) bool {
+	contains := func(values []string, v string) bool {
+		if len(values) == 0 {
+			return true
+		}
+		for _, value := range values {
+			if value == v {
+				return true
+			}
+		}
+		return false
+	}
+	return contains(selector.Types, event.Type) &&
+		contains(selector.Reasons, event.Reason) &&
+		contains(selector.Kinds, event.InvolvedObject.Kind) &&
+		contains(selector.Namespaces, event.Namespace)
+}
+
+// sender 负责把通知发送到一个 ECSMNotification 的 webhook。
+type sender struct {
+	name       string
+	uid        types.UID
+	generation int64
+	spec       ecsmv1.ECSMNotificationSpec
+
+	template *template.Template
+	client   *http.Client
+	limiter  *rate.Limiter
+	clock    func() time.Time
+	queue    chan *ecsmv1.Event
+
+	lock   sync.Mutex
+	status ecsmv1.ECSMNotificationStatus
+	// dirty 表示 status 有还没有写回 Registry 的变化
+	dirty bool
+}
+
+// newSender 为 notification 创建一个 sender 并启动它的发送协程。
+func newSender(notification *ecsmv1.ECSMNotification, clock func() time.Time) (*sender, error) {
+	text := notification.Spec.Template
+	if text == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New(notification.Name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	timeout := defaultTimeout
+	if notification.Spec.Webhook.TimeoutSeconds > 0 {
+		timeout = time.Duration(notification.Spec.Webhook.TimeoutSeconds) * time.Second
+	}
+	perMinute := int(notification.Spec.MaxPerMinute)
+	if perMinute <= 0 {
+		perMinute = DefaultMaxPerMinute
+	}
+
+	s := &sender{
+		name:       notification.Name,
+		uid:        notification.UID,
+		generation: notification.Generation,
+		spec:       *notification.Spec.DeepCopy(),
+		template:   tmpl,
+		client:     &http.Client{Timeout: timeout},
+		limiter:    rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), perMinute),
+		clock:      clock,
+		queue:      make(chan *ecsmv1.Event, queueSize),
+		status:     *notification.Status.DeepCopy(),
+	}
+	go s.run()
+	return s, nil
+}
+
+// enqueue 在速率限制允许时把事件放入发送队列，否则丢弃它。调用时必须持有 Notifier 的锁。
+func (s *sender) enqueue(event *ecsmv1.Event) {
+	if s.limiter.Allow() {
+		select {
+		case s.queue <- event.DeepCopy():
+			return
+		default:
+		}
+	}
+	klog.V(2).Infof("Dropping notification %s for event %s/%s, rate limit exceeded or queue full", s.name, event.Namespace, event.Name)
+	s.lock.Lock()
+	s.status.Dropped++
+	s.dirty = true
+	s.lock.Unlock()
+}
+
+// stop 停止接收新的通知，队列中剩下的通知仍然会被发送。调用时必须持有 Notifier 的锁。
+func (s *sender) stop() {
+	close(s.queue)
+}
+
+func (s *sender) run() {
+	defer runtime.HandleCrash()
+	for event := range s.queue {
+		err := s.send(event)
+
+		s.lock.Lock()
+		if err != nil {
+			klog.Warningf("Failed to send notification %s for event %s/%s: %v", s.name, event.Namespace, event.Name, err)
+			s.status.Failed++
+			s.status.LastError = err.Error()
+		} else {
+			now := metav1.NewTime(s.clock())
+			s.status.Sent++
+			s.status.LastSentTime = &now
+			s.status.LastError = ""
+		}
+		s.dirty = true
+		s.lock.Unlock()
+	}
+}
+
+// currentStatus 返回当前的发送状态。
+func (s *sender) currentStatus() ecsmv1.ECSMNotificationStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return *s.status.DeepCopy()
+}
+
+// send 把一个事件渲染为消息并发送到 webhook。
+func (s *sender) send(event *ecsmv1.Event) error {
+	var text bytes.Buffer
+	if err := s.template.Execute(&text, event); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	body, err := payload(s.spec.Webhook.Type, text.String(), event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.spec.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.spec.Webhook.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	if s.spec.Webhook.Type == ecsmv1.NotificationWebhookDingTalk {
+		// 钉钉以 HTTP 200 返回错误，错误码在响应体中
+		var result struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if err := json.Unmarshal(data, &result); err == nil && result.ErrCode != 0 {
+			return fmt.Errorf("webhook returned error %d: %s", result.ErrCode, result.ErrMsg)
+		}
+	}
+	return nil
+}
+
+// payload 按照 webhook 的类型构造请求体。
+func payload(webhookType ecsmv1.NotificationWebhookType, text string, event *ecsmv1.Event) ([]byte, error) {
+	switch webhookType {
+	case ecsmv1.NotificationWebhookSlack:
+		return json.Marshal(map[string]string{"text": text})
+	case ecsmv1.NotificationWebhookDingTalk:
+		return json.Marshal(map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": text},
+		})
+	default:
+		event.TypeMeta = metav1.TypeMeta{APIVersion: ecsmv1.SchemeGroupVersion.String(), Kind: "Event"}
+		return json.Marshal(map[string]interface{}{"text": text, "event": event})
+	}
+}
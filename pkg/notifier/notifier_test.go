@@ -0,0 +1,200 @@
+// file: pkg/notifier/notifier_test.go
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	bolt "go.etcd.io/bbolt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestRegistry(t *testing.T) *registry.Registry {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	return reg
+}
+
+func newEvent(eventType, reason, kind, namespace string) *ecsmv1.Event {
+	return &ecsmv1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: namespace},
+		InvolvedObject: ecsmv1.ObjectReference{Kind: kind, Namespace: namespace, Name: "web"},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        "2 of 3 replicas are ready",
+	}
+}
+
+func TestMatches(t *testing.T) {
+	event := newEvent(ecsmv1.EventTypeWarning, "Degraded", "ECSMService", "edge")
+	tests := []struct {
+		name     string
+		selector ecsmv1.NotificationSelector
+		want     bool
+	}{
+		{"empty selector", ecsmv1.NotificationSelector{}, true},
+		{"matching fields", ecsmv1.NotificationSelector{
+			Types: []string{ecsmv1.EventTypeWarning}, Reasons: []string{"NodeNotReady", "Degraded"},
+			Kinds: []string{"ECSMService"}, Namespaces: []string{"edge"},
+		}, true},
+		{"other type", ecsmv1.NotificationSelector{Types: []string{ecsmv1.EventTypeNormal}}, false},
+		{"other reason", ecsmv1.NotificationSelector{Reasons: []string{"RolloutComplete"}}, false},
+		{"other namespace", ecsmv1.NotificationSelector{Namespaces: []string{"default"}}, false},
+	}
+	for _, tt := range tests {
+		if got := Matches(&tt.selector, event); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 集群级对象的事件没有命名空间，指定命名空间时不会被选中
+	nodeEvent := newEvent(ecsmv1.EventTypeWarning, "NodeNotReady", "ECSMNode", "")
+	if Matches(&ecsmv1.NotificationSelector{Namespaces: []string{"edge"}}, nodeEvent) {
+		t.Error("a cluster-scoped event matched a namespace selector")
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	bodies := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Authorization header = %q", r.Header.Get("Authorization"))
+		}
+		data, _ := io.ReadAll(r.Body)
+		bodies <- string(data)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+	_, err := reg.CreateNotification(ctx, &ecsmv1.ECSMNotification{
+		ObjectMeta: metav1.ObjectMeta{Name: "ops"},
+		Spec: ecsmv1.ECSMNotificationSpec{
+			Selector: ecsmv1.NotificationSelector{Types: []string{ecsmv1.EventTypeWarning}},
+			Webhook: ecsmv1.NotificationWebhook{
+				Type:    ecsmv1.NotificationWebhookSlack,
+				URL:     server.URL,
+				Headers: map[string]string{"Authorization": "Bearer token"},
+			},
+			Template:     "{{.Reason}} {{.InvolvedObject.Namespace}}/{{.InvolvedObject.Name}}: {{.Message}}",
+			MaxPerMinute: 1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateNotification failed: %v", err)
+	}
+
+	n := New(reg)
+	n.sync()
+	defer func() {
+		n.lock.Lock()
+		for _, s := range n.senders {
+			s.stop()
+		}
+		n.lock.Unlock()
+	}()
+
+	n.notify(newEvent(ecsmv1.EventTypeNormal, "ScaledUp", "ECSMService", "edge"))
+	n.notify(newEvent(ecsmv1.EventTypeWarning, "Degraded", "ECSMService", "edge"))
+	// 超过了每分钟一条的限制
+	n.notify(newEvent(ecsmv1.EventTypeWarning, "Degraded", "ECSMService", "edge"))
+
+	select {
+	case body := <-bodies:
+		var payload map[string]string
+		if err := json.Unmarshal([]byte(body), &payload); err != nil {
+			t.Fatalf("invalid payload %q: %v", body, err)
+		}
+		if want := "Degraded edge/web: 2 of 3 replicas are ready"; payload["text"] != want {
+			t.Errorf("text = %q, want %q", payload["text"], want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification was sent")
+	}
+
+	// 等待 sender 记录发送结果后写回状态
+	s := n.senders["ops"]
+	for i := 0; s.currentStatus().Sent == 0; i++ {
+		if i > 100 {
+			t.Fatal("sent notification was not recorded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	n.flushStatus()
+	notification, err := reg.GetNotification(ctx, "ops")
+	if err != nil {
+		t.Fatalf("GetNotification failed: %v", err)
+	}
+	if status := notification.Status; status.Sent != 1 || status.Dropped != 1 || status.Failed != 0 || status.LastSentTime == nil {
+		t.Errorf("status = %+v, want 1 sent and 1 dropped", status)
+	}
+
+	// 写回状态不会重建 sender
+	n.sync()
+	if n.senders["ops"] != s {
+		t.Error("sender was recreated after a status update")
+	}
+	select {
+	case body := <-bodies:
+		t.Errorf("unexpected notification %s", body)
+	default:
+	}
+}
+
+func TestSendDingTalk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			MsgType string `json:"msgtype"`
+			Text    struct {
+				Content string `json:"content"`
+			} `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.MsgType != "text" {
+			t.Errorf("invalid payload: %+v, %v", payload, err)
+		}
+		if strings.Contains(payload.Text.Content, "forbidden") {
+			io.WriteString(w, `{"errcode": 310000, "errmsg": "keywords not in content"}`)
+			return
+		}
+		io.WriteString(w, `{"errcode": 0, "errmsg": "ok"}`)
+	}))
+	defer server.Close()
+
+	s, err := newSender(&ecsmv1.ECSMNotification{
+		ObjectMeta: metav1.ObjectMeta{Name: "dingtalk"},
+		Spec: ecsmv1.ECSMNotificationSpec{
+			Webhook: ecsmv1.NotificationWebhook{Type: ecsmv1.NotificationWebhookDingTalk, URL: server.URL},
+		},
+	}, time.Now)
+	if err != nil {
+		t.Fatalf("newSender failed: %v", err)
+	}
+	defer s.stop()
+
+	event := newEvent(ecsmv1.EventTypeWarning, "NodeNotReady", "ECSMNode", "")
+	if err := s.send(event); err != nil {
+		t.Errorf("send failed: %v", err)
+	}
+	// 钉钉以 HTTP 200 返回的错误也是失败
+	event.Message = "forbidden"
+	if err := s.send(event); err == nil || !strings.Contains(err.Error(), "310000") {
+		t.Errorf("send returned %v, want the DingTalk error", err)
+	}
+}
@@ -0,0 +1,172 @@
+// file: pkg/openapi/builder.go
+
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+// definitionNamePrefix 是 ecsm.sh/v1 类型的定义名称前缀，与 Kubernetes 一样使用反写的 API Group
+var definitionNamePrefix = "sh.ecsm." + ecsmv1.SchemeGroupVersion.Version + "."
+
+// 这些接口由 apimachinery 中有自定义 JSON 格式的类型实现 (例如 metav1.Time、intstr.IntOrString)，
+// kube-openapi 也通过它们确定这些类型的 schema。
+type (
+	openAPISchemaType interface {
+		OpenAPISchemaType() []string
+	}
+	openAPISchemaFormat interface {
+		OpenAPISchemaFormat() string
+	}
+	openAPIV3OneOfTypes interface {
+		OpenAPIV3OneOfTypes() []string
+	}
+	swaggerDoc interface {
+		SwaggerDoc() map[string]string
+	}
+)
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// builder 通过反射为 Go 类型生成 schema，每个具名结构体生成一个定义，在其他地方以 $ref 引用。
+type builder struct {
+	schemas map[string]*Schema
+}
+
+// definitionName 返回类型的定义名称：ecsm.sh/v1 的类型为 "sh.ecsm.v1.ECSMService"，
+// 其他类型使用反写域名的包路径，例如 "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"。
+func definitionName(t reflect.Type) string {
+	if t.PkgPath() == reflect.TypeOf(ecsmv1.ECSMService{}).PkgPath() {
+		return definitionNamePrefix + t.Name()
+	}
+	parts := strings.Split(t.PkgPath(), "/")
+	domain := strings.Split(parts[0], ".")
+	for i, j := 0, len(domain)-1; i < j; i, j = i+1, j-1 {
+		domain[i], domain[j] = domain[j], domain[i]
+	}
+	name := append(domain, parts[1:]...)
+	return strings.Join(append(name, t.Name()), ".")
+}
+
+// ref 返回类型的 schema：具名结构体返回对它的定义的引用 (必要时先生成定义)，其他类型直接内联。
+func (b *builder) ref(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if s, ok := customSchema(t); ok {
+		return s
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return b.inline(t)
+	}
+
+	name := definitionName(t)
+	if _, ok := b.schemas[name]; !ok {
+		// 先占位，避免递归类型无限展开
+		s := &Schema{Type: "object"}
+		b.schemas[name] = s
+		*s = *b.structSchema(t)
+	}
+	return &Schema{Ref: refPrefix + name}
+}
+
+// customSchema 处理有自定义 JSON 格式的类型。
+func customSchema(t reflect.Type) (*Schema, bool) {
+	v := reflect.New(t).Elem().Interface()
+	if oneOf, ok := v.(openAPIV3OneOfTypes); ok {
+		types := oneOf.OpenAPIV3OneOfTypes()
+		if len(types) == 2 {
+			return &Schema{IntOrString: true}, true
+		}
+	}
+	if st, ok := v.(openAPISchemaType); ok {
+		s := &Schema{}
+		if types := st.OpenAPISchemaType(); len(types) > 0 {
+			s.Type = types[0]
+		}
+		if sf, ok := v.(openAPISchemaFormat); ok {
+			s.Format = sf.OpenAPISchemaFormat()
+		}
+		return s, true
+	}
+	// 其他自定义序列化的结构体无法推断格式
+	if t.Kind() == reflect.Struct && (t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)) {
+		return &Schema{PreserveUnknownFields: true}, true
+	}
+	return nil, false
+}
+
+// inline 为非结构体类型生成 schema。
+func (b *builder) inline(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.ref(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.ref(t.Elem())}
+	case reflect.Struct:
+		return b.structSchema(t)
+	}
+	// interface{} 等任意值
+	return &Schema{PreserveUnknownFields: true}
+}
+
+// structSchema 为结构体生成 schema，内嵌的 inline 字段被展开，字段说明来自 SwaggerDoc()。
+func (b *builder) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	var docs map[string]string
+	if d, ok := reflect.New(t).Elem().Interface().(swaggerDoc); ok {
+		docs = d.SwaggerDoc()
+		s.Description = docs[""]
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		if f.Anonymous && (strings.Contains(opts, "inline") || tag == "") {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range b.structSchema(ft).Properties {
+					s.Properties[k] = v
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := b.ref(f.Type)
+		if doc := docs[name]; doc != "" {
+			// 一些 OpenAPI 3.0 工具会忽略 $ref 旁边的 description，但字段自己的说明比类型的说明更具体
+			prop.Description = doc
+		}
+		s.Properties[name] = prop
+	}
+	return s
+}
@@ -0,0 +1,244 @@
+// file: pkg/openapi/openapi.go
+
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Path 是 API Server 提供 OpenAPI 文档的路径
+const Path = "/openapi/v3"
+
+// refPrefix 是 $ref 引用 components.schemas 中定义的前缀
+const refPrefix = "#/components/schemas/"
+
+// Schema 是 OpenAPI v3 schema 的一个子集，足够描述 ecsm.sh 的 API 类型。
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// IntOrString 表示值可以是整数或字符串，例如 maxSurge
+	IntOrString bool `json:"x-kubernetes-int-or-string,omitempty"`
+	// PreserveUnknownFields 表示值可以是任意 JSON
+	PreserveUnknownFields bool `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	// GroupVersionKind 标记了顶层 API 对象的类型
+	GroupVersionKind []GroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+// GroupVersionKind 与 schema.GroupVersionKind 相同，但按照 OpenAPI 扩展的惯例序列化。
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// Document 是一个 OpenAPI v3 文档。
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// PathItem 是一个路径支持的操作
+type PathItem struct {
+	Get        *Operation  `json:"get,omitempty"`
+	Put        *Operation  `json:"put,omitempty"`
+	Post       *Operation  `json:"post,omitempty"`
+	Patch      *Operation  `json:"patch,omitempty"`
+	Delete     *Operation  `json:"delete,omitempty"`
+	Parameters []Parameter `json:"parameters,omitempty"`
+}
+
+// Operation 是一个 HTTP 操作
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Description string               `json:"description,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter 是路径或查询参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody 是请求体，以媒体类型为索引
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response 是一种响应，以媒体类型为索引
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType 给出请求体或响应的 schema
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// KindRef 返回对 ecsm.sh/v1 中一个 Kind 的定义的引用。
+func KindRef(kind string) *Schema {
+	return &Schema{Ref: refPrefix + definitionNamePrefix + kind}
+}
+
+// Info 是文档的标题和版本
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Components 包含了所有类型的 schema，以 DefinitionName 为索引
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Resolve 返回 $ref 指向的 schema，不是引用的 schema 原样返回。
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+	}
+	return s
+}
+
+// SchemaForKind 返回 ecsm.sh/v1 中一个 Kind 的 schema。
+func (d *Document) SchemaForKind(kind string) (*Schema, bool) {
+	s, ok := d.Components.Schemas[definitionNamePrefix+kind]
+	return s, ok
+}
+
+// Kinds 返回文档中所有 ecsm.sh/v1 顶层对象的 Kind，按字母排序。
+func (d *Document) Kinds() []string {
+	var kinds []string
+	for _, s := range d.Components.Schemas {
+		for _, gvk := range s.GroupVersionKind {
+			kinds = append(kinds, gvk.Kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// KindFor 返回 resource 对应的 ecsm.sh/v1 Kind。resource 不区分大小写，可以是 Kind 本身 (ECSMService)、
+// 复数形式 (ecsmservices)，也可以省略 ECSM 前缀 (service)。
+func (d *Document) KindFor(resource string) (string, bool) {
+	resource = strings.ToLower(resource)
+	for _, kind := range d.Kinds() {
+		lower := strings.ToLower(kind)
+		short := strings.TrimPrefix(lower, "ecsm")
+		for _, name := range []string{lower, short} {
+			if resource == name || resource == name+"s" || resource == name+"es" {
+				return kind, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Field 返回从 s 开始按 path 逐级取出的字段的 schema，数组和 map 类型的字段取其元素的字段。
+// 返回的是字段自身的 schema，它带有字段的说明，但可能是一个引用，需要用 Resolve 取得类型的定义。
+func (d *Document) Field(s *Schema, path []string) (*Schema, error) {
+	for i, name := range path {
+		var prop *Schema
+		if parent := d.Elem(s); parent != nil {
+			prop = parent.Properties[name]
+		}
+		if prop == nil {
+			return nil, fmt.Errorf("field %q does not exist", strings.Join(path[:i+1], "."))
+		}
+		s = prop
+	}
+	return s, nil
+}
+
+// Elem 解析 s 的引用，对数组和 map 返回其元素的定义，直到得到一个具体的类型。
+func (d *Document) Elem(s *Schema) *Schema {
+	s = d.Resolve(s)
+	for s != nil {
+		switch {
+		case s.Items != nil:
+			s = d.Resolve(s.Items)
+		case s.AdditionalProperties != nil && len(s.Properties) == 0:
+			s = d.Resolve(s.AdditionalProperties)
+		default:
+			return s
+		}
+	}
+	return s
+}
+
+var (
+	documentOnce sync.Once
+	document     *Document
+)
+
+// NewDocument 返回 ecsm.sh/v1 所有类型的 OpenAPI 文档，文档中没有路径。
+// 文档只生成一次，调用方不能修改它，需要补充路径时应该复制 Document 本身并替换 Paths。
+func NewDocument() *Document {
+	documentOnce.Do(func() {
+		scheme := runtime.NewScheme()
+		_ = ecsmv1.AddToScheme(scheme)
+		document = build(scheme, ecsmv1.SchemeGroupVersion)
+	})
+	return document
+}
+
+// Handler 返回以 JSON 提供 doc 的 http.Handler。
+func Handler(doc *Document) http.Handler {
+	data, err := json.Marshal(doc)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// build 为 scheme 中属于 gv、并且定义在 API 包中的所有类型生成文档。
+// metav1.AddToGroupVersion 注册到同一个 GroupVersion 的 WatchEvent、ListOptions 等辅助类型不包括在内。
+func build(scheme *runtime.Scheme, gv schema.GroupVersion) *Document {
+	apiPkg := reflect.TypeOf(ecsmv1.ECSMService{}).PkgPath()
+	b := &builder{schemas: make(map[string]*Schema)}
+	for kind, t := range scheme.KnownTypes(gv) {
+		if t.PkgPath() != apiPkg {
+			continue
+		}
+		b.ref(t)
+		b.schemas[definitionName(t)].GroupVersionKind = []GroupVersionKind{{Group: gv.Group, Version: gv.Version, Kind: kind}}
+	}
+	return &Document{
+		OpenAPI:    "3.0.0",
+		Info:       Info{Title: "ecsm-operator", Version: gv.Version},
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: b.schemas},
+	}
+}
@@ -0,0 +1,150 @@
+// file: pkg/openapi/openapi_test.go
+
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	doc := NewDocument()
+
+	svc, ok := doc.SchemaForKind("ECSMService")
+	if !ok {
+		t.Fatalf("ECSMService is missing, kinds = %v", doc.Kinds())
+	}
+	if len(svc.GroupVersionKind) != 1 || svc.GroupVersionKind[0] != (GroupVersionKind{Group: "ecsm.sh", Version: "v1", Kind: "ECSMService"}) {
+		t.Errorf("ECSMService group version kind = %v", svc.GroupVersionKind)
+	}
+	for _, field := range []string{"apiVersion", "kind", "metadata", "spec", "status"} {
+		if svc.Properties[field] == nil {
+			t.Errorf("ECSMService has no property %s", field)
+		}
+	}
+
+	spec := doc.Resolve(svc.Properties["spec"])
+	if spec == nil || spec.Type != "object" || spec.Description == "" {
+		t.Fatalf("ECSMService spec = %+v, want an object with a description", spec)
+	}
+	if spec.Properties["template"] == nil || spec.Properties["template"].Description == "" {
+		t.Error("spec.template has no description")
+	}
+
+	strategy, ok := doc.SchemaForKind("UpgradeStrategy")
+	if !ok {
+		t.Fatal("UpgradeStrategy is missing")
+	}
+	if s := strategy.Properties["maxSurge"]; s == nil || !s.IntOrString {
+		t.Errorf("maxSurge = %+v, want int-or-string", s)
+	}
+
+	meta := doc.Resolve(svc.Properties["metadata"])
+	if meta == nil {
+		t.Fatal("metadata does not resolve")
+	}
+	if ts := meta.Properties["creationTimestamp"]; ts == nil || ts.Type != "string" || ts.Format != "date-time" {
+		t.Errorf("creationTimestamp = %+v, want a date-time string", ts)
+	}
+	if labels := meta.Properties["labels"]; labels == nil || labels.AdditionalProperties == nil || labels.AdditionalProperties.Type != "string" {
+		t.Errorf("labels = %+v, want a map of strings", labels)
+	}
+
+	// 列表类型是顶层对象，但 WatchEvent 这样的辅助类型不是
+	kinds := map[string]bool{}
+	for _, kind := range doc.Kinds() {
+		kinds[kind] = true
+	}
+	if !kinds["ECSMServiceList"] || !kinds["ECSMServiceScale"] || kinds["WatchEvent"] {
+		t.Errorf("kinds = %v", doc.Kinds())
+	}
+
+	// 所有引用都指向存在的定义
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw interface{}
+	json.Unmarshal(data, &raw)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok && doc.Resolve(&Schema{Ref: ref}) == nil {
+				t.Errorf("dangling reference %s", ref)
+			}
+			for _, e := range v {
+				walk(e)
+			}
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(raw)
+}
+
+func TestKindForAndField(t *testing.T) {
+	doc := NewDocument()
+
+	for resource, want := range map[string]string{
+		"ECSMService":  "ECSMService",
+		"ecsmservices": "ECSMService",
+		"service":      "ECSMService",
+		"nodesets":     "ECSMNodeSet",
+		"Event":        "Event",
+	} {
+		if kind, ok := doc.KindFor(resource); !ok || kind != want {
+			t.Errorf("KindFor(%q) = %q, %v, want %q", resource, kind, ok, want)
+		}
+	}
+	if kind, ok := doc.KindFor("deployment"); ok {
+		t.Errorf("KindFor(deployment) = %q, want no kind", kind)
+	}
+
+	svc, _ := doc.SchemaForKind("ECSMService")
+	template, err := doc.Field(svc, []string{"spec", "template"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.Description == "" || doc.Resolve(template).Properties["image"] == nil {
+		t.Errorf("spec.template = %+v, want a described object with an image", template)
+	}
+	// 数组的元素的字段可以直接访问
+	if _, err := doc.Field(svc, []string{"spec", "template", "env", "name"}); err != nil {
+		t.Errorf("spec.template.env.name: %v", err)
+	}
+	if _, err := doc.Field(svc, []string{"spec", "replicas", "foo"}); err == nil {
+		t.Error("a field of an integer should not exist")
+	}
+	if _, err := doc.Field(svc, []string{"spec", "nope"}); err == nil || !strings.Contains(err.Error(), "spec.nope") {
+		t.Errorf("unknown field error = %v, want it to name spec.nope", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := Handler(NewDocument())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("GET returned %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var doc Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	if _, ok := doc.SchemaForKind("ECSMNode"); !ok || doc.OpenAPI == "" {
+		t.Errorf("served document is incomplete")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST returned %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,146 @@
+// file: pkg/record/correlator.go
+
+package record
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maxSimilarEvents 是同一对象、同一原因下允许出现的不同消息数，超过后事件会被聚合
+	maxSimilarEvents = 10
+	// aggregateInterval 是统计相似事件的时间窗口
+	aggregateInterval = 10 * time.Minute
+	// maxCacheEntries 限制了内存中缓存的事件数量，超过后缓存会被清空
+	maxCacheEntries = 4096
+
+	// combinedMessagePrefix 是聚合事件消息的前缀
+	combinedMessagePrefix = "(combined from similar events): "
+)
+
+// correlateResult 是 correlate 的结果。
+type correlateResult struct {
+	// event 是需要写入 sink 的事件
+	event *ecsmv1.Event
+	// update 为 true 表示 event 是一个已存在事件的合并更新
+	update bool
+	// key 是该事件的去重 key
+	key string
+}
+
+// aggregateRecord 记录了一个聚合 key 下在时间窗口内出现过的不同消息
+type aggregateRecord struct {
+	messages      map[string]struct{}
+	lastTimestamp time.Time
+}
+
+// eventCorrelator 负责事件的去重与聚合，所有状态都只保存在内存中。
+// 控制器重启后缓存丢失，最坏情况只是多产生一个新的 Event 对象。
+type eventCorrelator struct {
+	lock  sync.Mutex
+	clock func() time.Time
+
+	aggregates map[string]*aggregateRecord
+	observed   map[string]*ecsmv1.Event
+}
+
+func newEventCorrelator() *eventCorrelator {
+	return &eventCorrelator{
+		clock:      time.Now,
+		aggregates: make(map[string]*aggregateRecord),
+		observed:   make(map[string]*ecsmv1.Event),
+	}
+}
+
+// aggregateKey 标识 "同一对象上的同一类事件"，不包含消息
+func aggregateKey(event *ecsmv1.Event) string {
+	return strings.Join([]string{
+		event.Source.Component,
+		event.InvolvedObject.Kind,
+		event.InvolvedObject.Namespace,
+		event.InvolvedObject.Name,
+		string(event.InvolvedObject.UID),
+		event.Type,
+		event.Reason,
+	}, "/")
+}
+
+// correlate 根据已观察到的事件，决定 event 是新建、合并还是聚合。
+func (c *eventCorrelator) correlate(event *ecsmv1.Event) correlateResult {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.clock()
+	aggKey := aggregateKey(event)
+
+	// --- 1. 聚合：相似事件过多时，改写为一个聚合事件 ---
+	record, ok := c.aggregates[aggKey]
+	if !ok || now.Sub(record.lastTimestamp) > aggregateInterval {
+		record = &aggregateRecord{messages: make(map[string]struct{})}
+		c.aggregates[aggKey] = record
+	}
+	record.messages[event.Message] = struct{}{}
+	record.lastTimestamp = now
+
+	key := aggKey + "/" + event.Message
+	if len(record.messages) >= maxSimilarEvents {
+		event.Message = combinedMessagePrefix + event.Message
+		key = aggKey + "/" + combinedMessagePrefix
+	}
+
+	// --- 2. 去重：与上次写入的事件相同，则合并为一次计数 ---
+	if last, ok := c.observed[key]; ok {
+		merged := last.DeepCopy()
+		merged.Count++
+		merged.LastTimestamp = metav1.NewTime(now)
+		merged.Message = event.Message
+		return correlateResult{event: merged, update: true, key: key}
+	}
+
+	event.Name = fmt.Sprintf("%s.%x", event.InvolvedObject.Name, now.UnixNano())
+	event.Count = 1
+	event.FirstTimestamp = metav1.NewTime(now)
+	event.LastTimestamp = event.FirstTimestamp
+	return correlateResult{event: event, key: key}
+}
+
+// restart 把一个无法再被更新的合并事件重新变成一个新事件
+func (c *eventCorrelator) restart(event *ecsmv1.Event) *ecsmv1.Event {
+	now := c.clock()
+	fresh := event.DeepCopy()
+	fresh.ObjectMeta = metav1.ObjectMeta{
+		Name:      fmt.Sprintf("%s.%x", event.InvolvedObject.Name, now.UnixNano()),
+		Namespace: event.Namespace,
+	}
+	fresh.Count = 1
+	fresh.FirstTimestamp = metav1.NewTime(now)
+	fresh.LastTimestamp = fresh.FirstTimestamp
+	return fresh
+}
+
+// observe 记录一个已成功写入的事件，后续相同的事件会合并到它上面
+func (c *eventCorrelator) observe(key string, event *ecsmv1.Event) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.observed) >= maxCacheEntries {
+		c.observed = make(map[string]*ecsmv1.Event)
+	}
+	if len(c.aggregates) >= maxCacheEntries {
+		c.aggregates = make(map[string]*aggregateRecord)
+	}
+	c.observed[key] = event.DeepCopy()
+}
+
+// forget 丢弃一个去重 key 的缓存，通常在写入失败后调用
+func (c *eventCorrelator) forget(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.observed, key)
+}
@@ -0,0 +1,32 @@
+// file: pkg/record/fake.go
+
+package record
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FakeRecorder 用于测试，它把事件以 "<type> <reason> <message>" 的形式写入 Events channel。
+// Events 为 nil 时事件会被直接丢弃。
+type FakeRecorder struct {
+	Events chan string
+}
+
+var _ EventRecorder = &FakeRecorder{}
+
+// NewFakeRecorder 创建一个带有 bufferSize 缓冲的 FakeRecorder。
+func NewFakeRecorder(bufferSize int) *FakeRecorder {
+	return &FakeRecorder{Events: make(chan string, bufferSize)}
+}
+
+func (f *FakeRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if f.Events != nil {
+		f.Events <- fmt.Sprintf("%s %s %s", eventtype, reason, message)
+	}
+}
+
+func (f *FakeRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	f.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
@@ -0,0 +1,148 @@
+// file: pkg/record/recorder.go
+
+package record
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// EventRecorder 是控制器用来记录事件的接口，与 client-go 的 record.EventRecorder 保持一致。
+type EventRecorder interface {
+	// Event 为 object 记录一个事件。eventtype 是 Normal 或 Warning，
+	// reason 是简短的驼峰式原因 (例如 "ScaledUp")，message 是人类可读的描述。
+	Event(object runtime.Object, eventtype, reason, message string)
+
+	// Eventf 与 Event 相同，但使用 fmt.Sprintf 格式化 message。
+	Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{})
+}
+
+// EventSink 是事件最终被持久化的地方，registry.Interface 满足这个接口。
+type EventSink interface {
+	CreateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error)
+	UpdateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error)
+}
+
+// recorder 是 EventRecorder 的默认实现，它把事件写入 Registry。
+// 重复的事件会被合并为同一个 Event 对象并递增 Count，
+// 同一对象上原因相同、但消息不同的大量事件会被聚合为一个 "(combined from similar events)" 事件。
+type recorder struct {
+	sink       EventSink
+	scheme     *runtime.Scheme
+	source     ecsmv1.EventSource
+	correlator *eventCorrelator
+
+	// lock 保证同一个去重 key 的读-改-写不会交错
+	lock sync.Mutex
+}
+
+var _ EventRecorder = &recorder{}
+
+// NewRecorder 创建一个把事件写入 sink 的 EventRecorder。
+// scheme 用于在对象没有填写 TypeMeta 时推断其 Kind。
+func NewRecorder(sink EventSink, scheme *runtime.Scheme, source ecsmv1.EventSource) EventRecorder {
+	return &recorder{
+		sink:       sink,
+		scheme:     scheme,
+		source:     source,
+		correlator: newEventCorrelator(),
+	}
+}
+
+func (r *recorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.generateEvent(object, eventtype, reason, message)
+}
+
+func (r *recorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.generateEvent(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *recorder) generateEvent(object runtime.Object, eventtype, reason, message string) {
+	ref, err := GetReference(r.scheme, object)
+	if err != nil {
+		klog.Errorf("Could not construct reference to %#v, will not report event %s/%s: %v", object, eventtype, reason, err)
+		return
+	}
+	if eventtype != ecsmv1.EventTypeNormal && eventtype != ecsmv1.EventTypeWarning {
+		klog.Errorf("Unsupported event type %q, will not report event %s", eventtype, reason)
+		return
+	}
+
+	event := &ecsmv1.Event{
+		InvolvedObject: *ref,
+		Reason:         reason,
+		Message:        message,
+		Source:         r.source,
+		Type:           eventtype,
+	}
+	event.Namespace = ref.Namespace
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	result := r.correlator.correlate(event)
+	persisted, err := r.write(result)
+	if err != nil {
+		klog.Errorf("Failed to record event %s/%s for %s %s/%s: %v",
+			eventtype, reason, ref.Kind, ref.Namespace, ref.Name, err)
+		r.correlator.forget(result.key)
+		return
+	}
+	r.correlator.observe(result.key, persisted)
+}
+
+// write 把关联后的事件写入 sink。合并更新失败 (例如事件已被清理) 时退化为新建。
+func (r *recorder) write(result correlateResult) (*ecsmv1.Event, error) {
+	ctx := context.Background()
+	if result.update {
+		persisted, err := r.sink.UpdateEvent(ctx, result.event)
+		if err == nil {
+			return persisted, nil
+		}
+		if !errors.IsNotFound(err) && !errors.IsConflict(err) {
+			return nil, err
+		}
+		klog.V(4).Infof("Event %s/%s can no longer be updated (%v), creating a new one", result.event.Namespace, result.event.Name, err)
+		result.event = r.correlator.restart(result.event)
+	}
+	return r.sink.CreateEvent(ctx, result.event)
+}
+
+// GetReference 为一个 API 对象构造 ObjectReference。
+func GetReference(scheme *runtime.Scheme, obj runtime.Object) (*ecsmv1.ObjectReference, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("can't reference a nil object")
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Kind == "" && scheme != nil {
+		gvks, _, err := scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+		gvk = gvks[0]
+	}
+	if gvk.Kind == "" {
+		return nil, fmt.Errorf("unable to determine kind of object %T", obj)
+	}
+
+	return &ecsmv1.ObjectReference{
+		Kind:            gvk.Kind,
+		APIVersion:      gvk.GroupVersion().String(),
+		Namespace:       accessor.GetNamespace(),
+		Name:            accessor.GetName(),
+		UID:             accessor.GetUID(),
+		ResourceVersion: accessor.GetResourceVersion(),
+	}, nil
+}
@@ -0,0 +1,89 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// memorySink 是一个内存中的 EventSink
+type memorySink struct {
+	events map[string]*ecsmv1.Event
+}
+
+func (s *memorySink) CreateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error) {
+	if _, ok := s.events[event.Name]; ok {
+		return nil, errors.NewAlreadyExists(ecsmv1.Resource("events"), event.Name)
+	}
+	s.events[event.Name] = event.DeepCopy()
+	return event, nil
+}
+
+func (s *memorySink) UpdateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error) {
+	if _, ok := s.events[event.Name]; !ok {
+		return nil, errors.NewNotFound(ecsmv1.Resource("events"), event.Name)
+	}
+	s.events[event.Name] = event.DeepCopy()
+	return event, nil
+}
+
+func newTestRecorder(t *testing.T) (*memorySink, EventRecorder) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ecsmv1.AddToScheme(scheme))
+	sink := &memorySink{events: map[string]*ecsmv1.Event{}}
+	return sink, NewRecorder(sink, scheme, ecsmv1.EventSource{Component: "test"})
+}
+
+func TestRecorder(t *testing.T) {
+	svc := &ecsmv1.ECSMService{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "uid-1"}}
+
+	t.Run("Dedup identical events", func(t *testing.T) {
+		sink, rec := newTestRecorder(t)
+		for i := 0; i < 3; i++ {
+			rec.Event(svc, ecsmv1.EventTypeNormal, "ScaledUp", "Scaled up to 3")
+		}
+		require.Len(t, sink.events, 1)
+		for _, ev := range sink.events {
+			assert.Equal(t, int32(3), ev.Count)
+			assert.Equal(t, "ECSMService", ev.InvolvedObject.Kind)
+			assert.Equal(t, "web", ev.InvolvedObject.Name)
+			assert.Equal(t, "default", ev.Namespace)
+		}
+	})
+
+	t.Run("Aggregate similar events", func(t *testing.T) {
+		sink, rec := newTestRecorder(t)
+		for i := 0; i < maxSimilarEvents+2; i++ {
+			rec.Eventf(svc, ecsmv1.EventTypeWarning, "CreateFailed", "attempt %d failed", i)
+		}
+		// 前 maxSimilarEvents-1 条各自独立，之后的全部合并为一个聚合事件
+		require.Len(t, sink.events, maxSimilarEvents)
+		var combined *ecsmv1.Event
+		for _, ev := range sink.events {
+			if ev.Count > 1 {
+				combined = ev
+			}
+		}
+		require.NotNil(t, combined)
+		assert.Equal(t, int32(3), combined.Count)
+		assert.Equal(t, combinedMessagePrefix+fmt.Sprintf("attempt %d failed", maxSimilarEvents+1), combined.Message)
+	})
+
+	t.Run("Recreate event after it was removed", func(t *testing.T) {
+		sink, rec := newTestRecorder(t)
+		rec.Event(svc, ecsmv1.EventTypeNormal, "ScaledDown", "Scaled down to 1")
+		sink.events = map[string]*ecsmv1.Event{}
+		rec.Event(svc, ecsmv1.EventTypeNormal, "ScaledDown", "Scaled down to 1")
+		require.Len(t, sink.events, 1)
+		for _, ev := range sink.events {
+			assert.Equal(t, int32(1), ev.Count)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+// file: pkg/registry/apievent.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _eventsBucket = "events"
+
+// eventStore 返回 Event 资源的通用存储。
+func (r *Registry) eventStore() *resourceStore[ecsmv1.Event, *ecsmv1.Event] {
+	return newResourceStore[ecsmv1.Event](r, _eventsBucket,
+		ecsmv1.Resource("events"), ecsmv1.SchemeGroupVersion.WithKind("Event").GroupKind())
+}
+
+// CreateEvent 持久化一个新的 Event。
+func (r *Registry) CreateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error) {
+	return r.eventStore().create(event)
+}
+
+// UpdateEvent 整体更新一个已有的 Event，通常用于递增 Count 和 LastTimestamp。
+func (r *Registry) UpdateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error) {
+	return r.eventStore().replace(event)
+}
+
+// GetEvent 获取单个 Event。
+func (r *Registry) GetEvent(ctx context.Context, namespace, name string) (*ecsmv1.Event, error) {
+	return r.eventStore().get(namespace, name)
+}
+
+// ListEvents 返回指定命名空间下的所有 Event，namespace 为空时返回所有命名空间的 Event。
+func (r *Registry) ListEvents(ctx context.Context, namespace string) (*ecsmv1.EventList, string, error) {
+	items, rv, err := r.eventStore().list(namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.EventList{Items: items}, rv, nil
+}
+
+// DeleteEvent 删除一个 Event。
+func (r *Registry) DeleteEvent(ctx context.Context, namespace, name string) error {
+	return r.eventStore().delete(namespace, name)
+}
@@ -0,0 +1,59 @@
+// file: pkg/registry/autoscaler.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _autoscalersBucket = "ecsmserviceautoscalers"
+
+// autoscalerStore 返回 ECSMServiceAutoscaler 资源的通用存储。
+func (r *Registry) autoscalerStore() *resourceStore[ecsmv1.ECSMServiceAutoscaler, *ecsmv1.ECSMServiceAutoscaler] {
+	return newResourceStore[ecsmv1.ECSMServiceAutoscaler](r, _autoscalersBucket,
+		ecsmv1.Resource("ecsmserviceautoscalers"), ecsmv1.SchemeGroupVersion.WithKind("ECSMServiceAutoscaler").GroupKind())
+}
+
+// CreateAutoscaler 创建一个新的 ECSMServiceAutoscaler。
+func (r *Registry) CreateAutoscaler(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error) {
+	return r.autoscalerStore().create(autoscaler)
+}
+
+// UpdateAutoscaler 更新 ECSMServiceAutoscaler 的 spec 和 metadata，status 保持不变。
+func (r *Registry) UpdateAutoscaler(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error) {
+	return r.autoscalerStore().update(autoscaler, func(current, incoming *ecsmv1.ECSMServiceAutoscaler) *ecsmv1.ECSMServiceAutoscaler {
+		updated := incoming.DeepCopy()
+		updated.Status = current.Status
+		return updated
+	})
+}
+
+// UpdateAutoscalerStatus 只用传入对象的 status 覆盖存储中的 status，spec 和 metadata 保持不变。
+func (r *Registry) UpdateAutoscalerStatus(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error) {
+	return r.autoscalerStore().updateUnconditionally(autoscaler, func(current, incoming *ecsmv1.ECSMServiceAutoscaler) *ecsmv1.ECSMServiceAutoscaler {
+		updated := current.DeepCopy()
+		updated.Status = incoming.Status
+		return updated
+	})
+}
+
+// GetAutoscaler 获取单个 ECSMServiceAutoscaler。
+func (r *Registry) GetAutoscaler(ctx context.Context, namespace, name string) (*ecsmv1.ECSMServiceAutoscaler, error) {
+	return r.autoscalerStore().get(namespace, name)
+}
+
+// ListAutoscalers 返回指定命名空间下的所有 ECSMServiceAutoscaler，namespace 为空时返回所有命名空间的对象。
+func (r *Registry) ListAutoscalers(ctx context.Context, namespace string) (*ecsmv1.ECSMServiceAutoscalerList, string, error) {
+	items, rv, err := r.autoscalerStore().list(namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMServiceAutoscalerList{Items: items}, rv, nil
+}
+
+// DeleteAutoscaler 删除一个 ECSMServiceAutoscaler。
+func (r *Registry) DeleteAutoscaler(ctx context.Context, namespace, name string) error {
+	return r.autoscalerStore().delete(namespace, name)
+}
@@ -0,0 +1,46 @@
+// file: pkg/registry/config.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _configsBucket = "ecsmconfigs"
+
+// configStore 返回 ECSMConfig 资源的通用存储。
+func (r *Registry) configStore() *resourceStore[ecsmv1.ECSMConfig, *ecsmv1.ECSMConfig] {
+	return newResourceStore[ecsmv1.ECSMConfig](r, _configsBucket,
+		ecsmv1.Resource("ecsmconfigs"), ecsmv1.SchemeGroupVersion.WithKind("ECSMConfig").GroupKind())
+}
+
+// CreateConfig 创建一个新的 ECSMConfig。
+func (r *Registry) CreateConfig(ctx context.Context, config *ecsmv1.ECSMConfig) (*ecsmv1.ECSMConfig, error) {
+	return r.configStore().create(config)
+}
+
+// UpdateConfig 更新 ECSMConfig。它没有 status，传入的对象会整体替换存储中的对象。
+func (r *Registry) UpdateConfig(ctx context.Context, config *ecsmv1.ECSMConfig) (*ecsmv1.ECSMConfig, error) {
+	return r.configStore().replace(config)
+}
+
+// GetConfig 获取单个 ECSMConfig。
+func (r *Registry) GetConfig(ctx context.Context, namespace, name string) (*ecsmv1.ECSMConfig, error) {
+	return r.configStore().get(namespace, name)
+}
+
+// ListConfigs 返回指定命名空间下的所有 ECSMConfig，namespace 为空时返回所有命名空间的对象。
+func (r *Registry) ListConfigs(ctx context.Context, namespace string) (*ecsmv1.ECSMConfigList, string, error) {
+	items, rv, err := r.configStore().list(namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMConfigList{Items: items}, rv, nil
+}
+
+// DeleteConfig 删除一个 ECSMConfig。
+func (r *Registry) DeleteConfig(ctx context.Context, namespace, name string) error {
+	return r.configStore().delete(namespace, name)
+}
@@ -0,0 +1,54 @@
+// file: pkg/registry/conversion.go
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/install"
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// storageScheme 包含 ecsm.sh 组的所有版本及它们之间的转换函数，用于读取旧版本写入的对象
+var storageScheme = newStorageScheme()
+
+func newStorageScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	install.Install(s)
+	return s
+}
+
+// decodeVersioned 把存储的 JSON 反序列化到 obj 中。
+// 如果对象的 apiVersion 是 ecsm.sh 组的其他版本 (例如旧版本的 operator 写入的 v1alpha1)，
+// 先按该版本解码，再转换为 obj 的版本。对象被再次写入时会以新的版本保存。
+func decodeVersioned(data []byte, obj runtime.Object) error {
+	// 大多数对象没有 apiVersion 或者就是当前版本，避免为它们多解析一次
+	if !bytes.Contains(data, []byte(`"apiVersion"`)) {
+		return json.Unmarshal(data, obj)
+	}
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(data, &typeMeta); err != nil {
+		return err
+	}
+	gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+	if err != nil || gv.Group != ecsmv1.GroupName || gv.Version == "" || gv == ecsmv1.SchemeGroupVersion {
+		return json.Unmarshal(data, obj)
+	}
+
+	in, err := storageScheme.New(gv.WithKind(typeMeta.Kind))
+	if err != nil {
+		return fmt.Errorf("cannot decode stored %s %s: %w", typeMeta.APIVersion, typeMeta.Kind, err)
+	}
+	if err := json.Unmarshal(data, in); err != nil {
+		return err
+	}
+	if err := storageScheme.Convert(in, obj, nil); err != nil {
+		return fmt.Errorf("failed to convert stored %s %s: %w", typeMeta.APIVersion, typeMeta.Kind, err)
+	}
+	return nil
+}
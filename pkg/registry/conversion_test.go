@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestGetServiceStoredAsV1alpha1(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+
+	// 模拟旧版本的 operator 以 v1alpha1 写入的对象
+	stored := `{"apiVersion":"ecsm.sh/v1alpha1","kind":"ECSMService",` +
+		`"metadata":{"namespace":"default","name":"legacy","resourceVersion":"1"},` +
+		`"spec":{"nodes":["node-a"],"autoUpgrade":"never","template":{"image":"legacy@1.0","memoryLimit":"32M"}}}`
+	err := reg.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(_servicesBucketKey)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("default/legacy"), []byte(stored))
+	})
+	if err != nil {
+		t.Fatalf("failed to seed the registry: %v", err)
+	}
+
+	svc, err := reg.GetService(ctx, "default", "legacy")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if svc.APIVersion != ecsmv1.SchemeGroupVersion.String() {
+		t.Errorf("apiVersion = %q, want %q", svc.APIVersion, ecsmv1.SchemeGroupVersion.String())
+	}
+	if svc.Spec.DeploymentStrategy.Type != ecsmv1.DeploymentStrategyTypeStatic || svc.Spec.Template.Image != "legacy@1.0" {
+		t.Errorf("converted spec = %+v", svc.Spec)
+	}
+	if svc.Spec.Template.Resources == nil || svc.Spec.Template.Resources.Limits[ecsmv1.ResourceTypeMemory] != "32M" {
+		t.Errorf("converted resources = %+v, want memory limit 32M", svc.Spec.Template.Resources)
+	}
+
+	// 更新后对象以 v1 保存
+	svc.Labels = map[string]string{"migrated": "true"}
+	if _, err := reg.UpdateService(ctx, svc); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	list, _, err := reg.ListAllServices(ctx, "default")
+	if err != nil {
+		t.Fatalf("ListAllServices failed: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Labels["migrated"] != "true" {
+		t.Errorf("ListAllServices = %+v, want the migrated service", list.Items)
+	}
+}
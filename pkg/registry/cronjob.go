@@ -0,0 +1,59 @@
+// file: pkg/registry/cronjob.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _cronJobsBucket = "ecsmcronjobs"
+
+// cronJobStore 返回 ECSMCronJob 资源的通用存储。
+func (r *Registry) cronJobStore() *resourceStore[ecsmv1.ECSMCronJob, *ecsmv1.ECSMCronJob] {
+	return newResourceStore[ecsmv1.ECSMCronJob](r, _cronJobsBucket,
+		ecsmv1.Resource("ecsmcronjobs"), ecsmv1.SchemeGroupVersion.WithKind("ECSMCronJob").GroupKind())
+}
+
+// CreateCronJob 创建一个新的 ECSMCronJob。
+func (r *Registry) CreateCronJob(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error) {
+	return r.cronJobStore().create(cronJob)
+}
+
+// UpdateCronJob 更新 ECSMCronJob 的 spec 和 metadata，status 保持不变。
+func (r *Registry) UpdateCronJob(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error) {
+	return r.cronJobStore().update(cronJob, func(current, incoming *ecsmv1.ECSMCronJob) *ecsmv1.ECSMCronJob {
+		updated := incoming.DeepCopy()
+		updated.Status = current.Status
+		return updated
+	})
+}
+
+// UpdateCronJobStatus 只用传入对象的 status 覆盖存储中的 status，spec 和 metadata 保持不变。
+func (r *Registry) UpdateCronJobStatus(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error) {
+	return r.cronJobStore().updateUnconditionally(cronJob, func(current, incoming *ecsmv1.ECSMCronJob) *ecsmv1.ECSMCronJob {
+		updated := current.DeepCopy()
+		updated.Status = incoming.Status
+		return updated
+	})
+}
+
+// GetCronJob 获取单个 ECSMCronJob。
+func (r *Registry) GetCronJob(ctx context.Context, namespace, name string) (*ecsmv1.ECSMCronJob, error) {
+	return r.cronJobStore().get(namespace, name)
+}
+
+// ListCronJobs 返回指定命名空间下的所有 ECSMCronJob，namespace 为空时返回所有命名空间的对象。
+func (r *Registry) ListCronJobs(ctx context.Context, namespace string) (*ecsmv1.ECSMCronJobList, string, error) {
+	items, rv, err := r.cronJobStore().list(namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMCronJobList{Items: items}, rv, nil
+}
+
+// DeleteCronJob 删除一个 ECSMCronJob。
+func (r *Registry) DeleteCronJob(ctx context.Context, namespace, name string) error {
+	return r.cronJobStore().delete(namespace, name)
+}
@@ -0,0 +1,104 @@
+// file: pkg/registry/encryption.go
+
+package registry
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// aesGCMPrefix 标记了由 aesGCMTransformer 加密的数据，
+// 没有这个前缀的数据被视为启用加密之前写入的明文
+var aesGCMPrefix = []byte("ecsm:enc:aesgcm:v1:")
+
+// ErrNoEncryptionKey 表示读写加密的资源时没有配置加密密钥
+var ErrNoEncryptionKey = errors.New("no encryption key configured for the registry")
+
+// Transformer 在对象写入 bbolt 之前和读出之后转换它的序列化数据，用于静态加密。
+type Transformer interface {
+	// TransformToStorage 把序列化后的对象转换为存储的形式
+	TransformToStorage(data []byte) ([]byte, error)
+	// TransformFromStorage 把存储的数据还原为序列化的对象
+	TransformFromStorage(data []byte) ([]byte, error)
+}
+
+// aesGCMTransformer 使用 AES-GCM 加密数据，每次写入使用随机的 nonce。
+type aesGCMTransformer struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMTransformer 创建一个使用 AES-GCM 加密的 Transformer，key 的长度必须为 16、24 或 32 字节。
+func NewAESGCMTransformer(key []byte) (Transformer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCMTransformer{aead: aead}, nil
+}
+
+func (t *aesGCMTransformer) TransformToStorage(data []byte) ([]byte, error) {
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out := append([]byte(nil), aesGCMPrefix...)
+	out = append(out, nonce...)
+	return t.aead.Seal(out, nonce, data, nil), nil
+}
+
+func (t *aesGCMTransformer) TransformFromStorage(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, aesGCMPrefix) {
+		return data, nil
+	}
+	data = data[len(aesGCMPrefix):]
+	if len(data) < t.aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+	nonce, ciphertext := data[:t.aead.NonceSize()], data[t.aead.NonceSize():]
+	plaintext, err := t.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data, the encryption key may be wrong: %w", err)
+	}
+	return plaintext, nil
+}
+
+// isEncrypted 判断存储的数据是否是加密过的
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, aesGCMPrefix)
+}
+
+// IsMissingKey 判断错误是否是因为没有配置加密密钥
+func IsMissingKey(err error) bool {
+	return errors.Is(err, ErrNoEncryptionKey)
+}
+
+// LoadEncryptionKey 从文件中读取 base64 编码的加密密钥。
+// 可以使用 "head -c 32 /dev/urandom | base64" 生成一个 32 字节的密钥。
+func LoadEncryptionKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key file %s is not valid base64: %w", path, err)
+	}
+	return key, nil
+}
+
+// SetEncryption 为需要静态加密的资源 (ECSMSecret) 设置 Transformer。
+// 它必须在 Registry 被使用之前调用。未设置时，这些资源不能被写入，已加密的对象也不能被读取。
+func (r *Registry) SetEncryption(t Transformer) {
+	r.transformer = t
+}
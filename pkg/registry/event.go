@@ -22,4 +22,8 @@ type Event struct {
 	Object runtime.Object
 	// ResourceVersion 是变更后对象的 resourceVersion
 	ResourceVersion string
+	// Revision 是产生这个事件的写操作分配的全局版本号。
+	// 对于删除事件，它是删除操作本身的版本，而不是被删除对象的最后版本。
+	// Registry 按 Revision 递增的顺序发布事件，订阅者没有丢弃事件时它是连续的。
+	Revision uint64
 }
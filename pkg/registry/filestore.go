@@ -123,7 +123,7 @@ func (fs *FileStore) Get(namespace, name string, objInto runtime.Object) error {
 		}
 		return fmt.Errorf("failed to read object file: %w", err)
 	}
-	return json.Unmarshal(data, objInto)
+	return decodeVersioned(data, objInto)
 }
 
 func (fs *FileStore) List(namespace string, listInto runtime.Object) error {
@@ -155,7 +155,7 @@ func (fs *FileStore) List(namespace string, listInto runtime.Object) error {
 			}
 
 			newItem := reflect.New(itemType).Interface().(runtime.Object)
-			if umErr := json.Unmarshal(data, newItem); umErr != nil {
+			if umErr := decodeVersioned(data, newItem); umErr != nil {
 				fmt.Fprintf(os.Stderr, "warning: failed to unmarshal file %s: %v\n", path, umErr)
 				continue
 			}
@@ -28,7 +28,11 @@ func newTestService(namespace, name string) *ecsmv1.ECSMService {
 			Labels:    map[string]string{"app": name},
 		},
 		Spec: ecsmv1.ECSMServiceSpec{
-			// ...可以填充一些简单的 spec 用于测试
+			DeploymentStrategy: ecsmv1.DeploymentStrategy{
+				Type:  ecsmv1.DeploymentStrategyTypeStatic,
+				Nodes: []string{"node-a"},
+			},
+			Template: ecsmv1.ContainerTemplateSpec{Image: name + "@1.0"},
 		},
 	}
 }
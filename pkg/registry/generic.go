@@ -0,0 +1,338 @@
+// file: pkg/registry/generic.go
+
+package registry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// objectPtr 约束了可以被通用存储逻辑处理的 API 对象指针类型，例如 *ecsmv1.Event。
+type objectPtr[T any] interface {
+	*T
+	runtime.Object
+	metav1.Object
+}
+
+// resourceStore 封装了单一资源类型在 bbolt 中的通用事务逻辑
+// (乐观并发控制、全局 resourceVersion 递增、事件发布)。
+// 各资源的业务方法 (默认值、校验) 仍然写在各自的文件中，只把持久化部分委托给它。
+type resourceStore[T any, P objectPtr[T]] struct {
+	r        *Registry
+	bucket   []byte
+	resource schema.GroupResource
+	kind     schema.GroupKind
+
+	// encrypted 为 true 时，对象在写入 bbolt 之前使用 Registry 的 Transformer 加密
+	encrypted bool
+}
+
+func newResourceStore[T any, P objectPtr[T]](r *Registry, bucket string, resource schema.GroupResource, kind schema.GroupKind) *resourceStore[T, P] {
+	return &resourceStore[T, P]{r: r, bucket: []byte(bucket), resource: resource, kind: kind}
+}
+
+// encode 序列化一个对象，必要时加密。
+func (s *resourceStore[T, P]) encode(obj P) ([]byte, error) {
+	buf, err := json.Marshal(obj)
+	if err != nil || !s.encrypted {
+		return buf, err
+	}
+	if s.r.transformer == nil {
+		return nil, fmt.Errorf("cannot store %s: %w", s.resource.String(), ErrNoEncryptionKey)
+	}
+	return s.r.transformer.TransformToStorage(buf)
+}
+
+// decode 反序列化一个对象，必要时解密，并把旧版本的对象转换为当前版本。
+// 启用加密之前写入的明文对象可以直接读取。
+func (s *resourceStore[T, P]) decode(data []byte, obj P) error {
+	if s.encrypted && isEncrypted(data) {
+		if s.r.transformer == nil {
+			return fmt.Errorf("cannot read %s: %w", s.resource.String(), ErrNoEncryptionKey)
+		}
+		plaintext, err := s.r.transformer.TransformFromStorage(data)
+		if err != nil {
+			return err
+		}
+		data = plaintext
+	}
+	return decodeVersioned(data, obj)
+}
+
+func (s *resourceStore[T, P]) create(obj P) (P, error) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	s.r.writeLock.Lock()
+	defer s.r.writeLock.Unlock()
+
+	var revision uint64
+	err = s.r.db.Update(func(tx *bolt.Tx) error {
+		metaBucket := tx.Bucket(_metadataBucketKey)
+		b, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+
+		if b.Get([]byte(key)) != nil {
+			return errors.NewAlreadyExists(s.resource, obj.GetName())
+		}
+
+		newRV, err := getAndIncrementGlobalRV(metaBucket)
+		if err != nil {
+			return err
+		}
+		revision = newRV
+
+		obj.SetResourceVersion(strconv.FormatUint(newRV, 10))
+		obj.SetUID(types.UID(uuid.New().String()))
+		obj.SetCreationTimestamp(metav1.Time{Time: time.Now().UTC()})
+		obj.SetGeneration(1)
+
+		buf, err := s.encode(obj)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), buf)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.r.publish(Event{Type: Added, Key: key, Object: obj, ResourceVersion: obj.GetResourceVersion(), Revision: revision})
+	return obj, nil
+}
+
+// update 使用 mutate 回调把传入对象合并到存储中的当前对象上，然后写回。
+// 传入对象的 resourceVersion 必须与存储中的一致，否则返回 Conflict。
+func (s *resourceStore[T, P]) update(obj P, mutate func(current, incoming P) P) (P, error) {
+	return s.doUpdate(obj, mutate, true)
+}
+
+// updateUnconditionally 与 update 相同，但不检查 resourceVersion，用于 status 子资源这类
+// 只由单一写者 (控制器) 修改、且不会覆盖用户字段的更新。
+func (s *resourceStore[T, P]) updateUnconditionally(obj P, mutate func(current, incoming P) P) (P, error) {
+	return s.doUpdate(obj, mutate, false)
+}
+
+func (s *resourceStore[T, P]) doUpdate(obj P, mutate func(current, incoming P) P, checkRV bool) (P, error) {
+	if checkRV && obj.GetResourceVersion() == "" {
+		errs := field.ErrorList{
+			field.Required(field.NewPath("metadata", "resourceVersion"), "resourceVersion must be specified for an update"),
+		}
+		return nil, errors.NewInvalid(s.kind, obj.GetName(), errs)
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated P
+	s.r.writeLock.Lock()
+	defer s.r.writeLock.Unlock()
+
+	var revision uint64
+	err = s.r.db.Update(func(tx *bolt.Tx) error {
+		metaBucket := tx.Bucket(_metadataBucketKey)
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return errors.NewNotFound(s.resource, obj.GetName())
+		}
+
+		currentBytes := b.Get([]byte(key))
+		if currentBytes == nil {
+			return errors.NewNotFound(s.resource, obj.GetName())
+		}
+
+		current := P(new(T))
+		if err := s.decode(currentBytes, current); err != nil {
+			return err
+		}
+
+		if checkRV && current.GetResourceVersion() != obj.GetResourceVersion() {
+			return errors.NewConflict(s.resource, obj.GetName(), fmt.Errorf("object has been modified; please apply your changes to the latest version and try again"))
+		}
+
+		updated = mutate(current, obj)
+
+		newRV, err := getAndIncrementGlobalRV(metaBucket)
+		if err != nil {
+			return err
+		}
+		revision = newRV
+		updated.SetResourceVersion(strconv.FormatUint(newRV, 10))
+		// 确保 UID 和创建时间戳不被修改
+		updated.SetUID(current.GetUID())
+		updated.SetCreationTimestamp(current.GetCreationTimestamp())
+		updated.SetGeneration(nextGeneration(current, updated))
+
+		buf, err := s.encode(updated)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), buf)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.r.publish(Event{Type: Modified, Key: key, Object: updated, ResourceVersion: updated.GetResourceVersion(), Revision: revision})
+	return updated, nil
+}
+
+// replace 用传入对象整体替换存储中的对象。
+func (s *resourceStore[T, P]) replace(obj P) (P, error) {
+	return s.update(obj, func(_, incoming P) P { return incoming })
+}
+
+func (s *resourceStore[T, P]) get(namespace, name string) (P, error) {
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	obj := P(new(T))
+
+	err := s.r.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return errors.NewNotFound(s.resource, name)
+		}
+		val := b.Get([]byte(key))
+		if val == nil {
+			return errors.NewNotFound(s.resource, name)
+		}
+		return s.decode(val, obj)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// list 返回指定命名空间下的所有对象和一个全局的 ResourceVersion。
+// namespace 为空时返回所有命名空间的对象。
+func (s *resourceStore[T, P]) list(namespace string) ([]T, string, error) {
+	items := []T{}
+	var resourceVersion string
+
+	err := s.r.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(s.bucket); b != nil {
+			c := b.Cursor()
+			var prefix []byte
+			if namespace != "" {
+				prefix = []byte(namespace + "/")
+			}
+			for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+				var item T
+				if err := s.decode(v, P(&item)); err != nil {
+					if IsMissingKey(err) {
+						return err
+					}
+					// 记录错误但继续，以增加健壮性
+					klog.Errorf("Failed to unmarshal %s object with key %s: %v", s.resource.String(), string(k), err)
+					continue
+				}
+				items = append(items, item)
+			}
+		}
+
+		if metaBucket := tx.Bucket(_metadataBucketKey); metaBucket != nil {
+			if rvBytes := metaBucket.Get(_globalResourceVersionKey); rvBytes != nil {
+				resourceVersion = strconv.FormatUint(binary.BigEndian.Uint64(rvBytes), 10)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return items, resourceVersion, nil
+}
+
+func (s *resourceStore[T, P]) delete(namespace, name string) error {
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	deleted := P(new(T))
+	found := false
+
+	s.r.writeLock.Lock()
+	defer s.r.writeLock.Unlock()
+
+	var revision uint64
+	err := s.r.db.Update(func(tx *bolt.Tx) error {
+		metaBucket := tx.Bucket(_metadataBucketKey)
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return nil
+		} // Already deleted
+
+		val := b.Get([]byte(key))
+		if val == nil {
+			return nil
+		} // Already deleted
+		if err := s.decode(val, deleted); err != nil {
+			return err
+		}
+		found = true
+
+		if err := b.Delete([]byte(key)); err != nil {
+			return err
+		}
+		newRV, err := getAndIncrementGlobalRV(metaBucket)
+		revision = newRV
+		return err
+	})
+	if err != nil || !found {
+		return err
+	}
+
+	s.r.publish(Event{Type: Deleted, Key: key, Object: deleted, ResourceVersion: deleted.GetResourceVersion(), Revision: revision})
+	return nil
+}
+
+// nextGeneration 返回对象更新后的 metadata.generation：只有 spec 变化时才递增，
+// metadata 和 status 的修改不会改变它。没有 spec 字段的资源 (例如 ECSMConfig) 把
+// metadata 和 status 之外的所有字段都视为 spec。
+func nextGeneration(current, updated runtime.Object) int64 {
+	generation := current.(metav1.Object).GetGeneration()
+	if !equality.Semantic.DeepEqual(specFields(current), specFields(updated)) {
+		generation++
+	}
+	return generation
+}
+
+// specFields 返回对象中除 TypeMeta、ObjectMeta 和 Status 之外的所有字段。
+func specFields(obj runtime.Object) []interface{} {
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	var fields []interface{}
+	for i := 0; i < v.NumField(); i++ {
+		switch v.Type().Field(i).Name {
+		case "TypeMeta", "ObjectMeta", "Status":
+			continue
+		}
+		fields = append(fields, v.Field(i).Interface())
+	}
+	return fields
+}
@@ -0,0 +1,59 @@
+// file: pkg/registry/job.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _jobsBucket = "ecsmjobs"
+
+// jobStore 返回 ECSMJob 资源的通用存储。
+func (r *Registry) jobStore() *resourceStore[ecsmv1.ECSMJob, *ecsmv1.ECSMJob] {
+	return newResourceStore[ecsmv1.ECSMJob](r, _jobsBucket,
+		ecsmv1.Resource("ecsmjobs"), ecsmv1.SchemeGroupVersion.WithKind("ECSMJob").GroupKind())
+}
+
+// CreateJob 创建一个新的 ECSMJob。
+func (r *Registry) CreateJob(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error) {
+	return r.jobStore().create(job)
+}
+
+// UpdateJob 更新 ECSMJob 的 spec 和 metadata，status 保持不变。
+func (r *Registry) UpdateJob(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error) {
+	return r.jobStore().update(job, func(current, incoming *ecsmv1.ECSMJob) *ecsmv1.ECSMJob {
+		updated := incoming.DeepCopy()
+		updated.Status = current.Status
+		return updated
+	})
+}
+
+// UpdateJobStatus 只用传入对象的 status 覆盖存储中的 status，spec 和 metadata 保持不变。
+func (r *Registry) UpdateJobStatus(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error) {
+	return r.jobStore().updateUnconditionally(job, func(current, incoming *ecsmv1.ECSMJob) *ecsmv1.ECSMJob {
+		updated := current.DeepCopy()
+		updated.Status = incoming.Status
+		return updated
+	})
+}
+
+// GetJob 获取单个 ECSMJob。
+func (r *Registry) GetJob(ctx context.Context, namespace, name string) (*ecsmv1.ECSMJob, error) {
+	return r.jobStore().get(namespace, name)
+}
+
+// ListJobs 返回指定命名空间下的所有 ECSMJob，namespace 为空时返回所有命名空间的对象。
+func (r *Registry) ListJobs(ctx context.Context, namespace string) (*ecsmv1.ECSMJobList, string, error) {
+	items, rv, err := r.jobStore().list(namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMJobList{Items: items}, rv, nil
+}
+
+// DeleteJob 删除一个 ECSMJob。
+func (r *Registry) DeleteJob(ctx context.Context, namespace, name string) error {
+	return r.jobStore().delete(namespace, name)
+}
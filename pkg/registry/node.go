@@ -0,0 +1,59 @@
+// file: pkg/registry/node.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _nodesBucket = "ecsmnodes"
+
+// nodeStore 返回 ECSMNode 资源的通用存储。ECSMNode 是集群级别的资源，key 就是它的名称。
+func (r *Registry) nodeStore() *resourceStore[ecsmv1.ECSMNode, *ecsmv1.ECSMNode] {
+	return newResourceStore[ecsmv1.ECSMNode](r, _nodesBucket,
+		ecsmv1.Resource("ecsmnodes"), ecsmv1.SchemeGroupVersion.WithKind("ECSMNode").GroupKind())
+}
+
+// CreateNode 创建一个新的 ECSMNode。
+func (r *Registry) CreateNode(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error) {
+	return r.nodeStore().create(node)
+}
+
+// UpdateNode 更新 ECSMNode 的 spec 和 metadata，status 保持不变。
+func (r *Registry) UpdateNode(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error) {
+	return r.nodeStore().update(node, func(current, incoming *ecsmv1.ECSMNode) *ecsmv1.ECSMNode {
+		updated := incoming.DeepCopy()
+		updated.Status = current.Status
+		return updated
+	})
+}
+
+// UpdateNodeStatus 只用传入对象的 status 覆盖存储中的 status，spec 和 metadata 保持不变。
+func (r *Registry) UpdateNodeStatus(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error) {
+	return r.nodeStore().updateUnconditionally(node, func(current, incoming *ecsmv1.ECSMNode) *ecsmv1.ECSMNode {
+		updated := current.DeepCopy()
+		updated.Status = incoming.Status
+		return updated
+	})
+}
+
+// GetNode 获取单个 ECSMNode。
+func (r *Registry) GetNode(ctx context.Context, name string) (*ecsmv1.ECSMNode, error) {
+	return r.nodeStore().get("", name)
+}
+
+// ListNodes 返回所有 ECSMNode 和一个全局的 ResourceVersion。
+func (r *Registry) ListNodes(ctx context.Context) (*ecsmv1.ECSMNodeList, string, error) {
+	items, rv, err := r.nodeStore().list("")
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMNodeList{Items: items}, rv, nil
+}
+
+// DeleteNode 删除一个 ECSMNode。
+func (r *Registry) DeleteNode(ctx context.Context, name string) error {
+	return r.nodeStore().delete("", name)
+}
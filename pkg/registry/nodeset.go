@@ -0,0 +1,59 @@
+// file: pkg/registry/nodeset.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _nodeSetsBucket = "ecsmnodesets"
+
+// nodeSetStore 返回 ECSMNodeSet 资源的通用存储。
+func (r *Registry) nodeSetStore() *resourceStore[ecsmv1.ECSMNodeSet, *ecsmv1.ECSMNodeSet] {
+	return newResourceStore[ecsmv1.ECSMNodeSet](r, _nodeSetsBucket,
+		ecsmv1.Resource("ecsmnodesets"), ecsmv1.SchemeGroupVersion.WithKind("ECSMNodeSet").GroupKind())
+}
+
+// CreateNodeSet 创建一个新的 ECSMNodeSet。
+func (r *Registry) CreateNodeSet(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error) {
+	return r.nodeSetStore().create(nodeSet)
+}
+
+// UpdateNodeSet 更新 ECSMNodeSet 的 spec 和 metadata，status 保持不变。
+func (r *Registry) UpdateNodeSet(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error) {
+	return r.nodeSetStore().update(nodeSet, func(current, incoming *ecsmv1.ECSMNodeSet) *ecsmv1.ECSMNodeSet {
+		updated := incoming.DeepCopy()
+		updated.Status = current.Status
+		return updated
+	})
+}
+
+// UpdateNodeSetStatus 只用传入对象的 status 覆盖存储中的 status，spec 和 metadata 保持不变。
+func (r *Registry) UpdateNodeSetStatus(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error) {
+	return r.nodeSetStore().updateUnconditionally(nodeSet, func(current, incoming *ecsmv1.ECSMNodeSet) *ecsmv1.ECSMNodeSet {
+		updated := current.DeepCopy()
+		updated.Status = incoming.Status
+		return updated
+	})
+}
+
+// GetNodeSet 获取单个 ECSMNodeSet。
+func (r *Registry) GetNodeSet(ctx context.Context, namespace, name string) (*ecsmv1.ECSMNodeSet, error) {
+	return r.nodeSetStore().get(namespace, name)
+}
+
+// ListNodeSets 返回指定命名空间下的所有 ECSMNodeSet，namespace 为空时返回所有命名空间的对象。
+func (r *Registry) ListNodeSets(ctx context.Context, namespace string) (*ecsmv1.ECSMNodeSetList, string, error) {
+	items, rv, err := r.nodeSetStore().list(namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMNodeSetList{Items: items}, rv, nil
+}
+
+// DeleteNodeSet 删除一个 ECSMNodeSet。
+func (r *Registry) DeleteNodeSet(ctx context.Context, namespace, name string) error {
+	return r.nodeSetStore().delete(namespace, name)
+}
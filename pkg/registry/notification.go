@@ -0,0 +1,75 @@
+// file: pkg/registry/notification.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/validation"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const _notificationsBucket = "ecsmnotifications"
+
+// notificationStore 返回 ECSMNotification 资源的通用存储。ECSMNotification 是集群级别的资源，key 就是它的名称。
+func (r *Registry) notificationStore() *resourceStore[ecsmv1.ECSMNotification, *ecsmv1.ECSMNotification] {
+	return newResourceStore[ecsmv1.ECSMNotification](r, _notificationsBucket,
+		ecsmv1.Resource("ecsmnotifications"), ecsmv1.SchemeGroupVersion.WithKind("ECSMNotification").GroupKind())
+}
+
+// validateNotification 在写入之前校验 ECSMNotification。
+func validateNotification(notification *ecsmv1.ECSMNotification) error {
+	if errs := validation.ValidateNotification(notification); len(errs) > 0 {
+		return errors.NewInvalid(ecsmv1.SchemeGroupVersion.WithKind("ECSMNotification").GroupKind(), notification.Name, errs)
+	}
+	return nil
+}
+
+// CreateNotification 创建一个新的 ECSMNotification。
+func (r *Registry) CreateNotification(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error) {
+	if err := validateNotification(notification); err != nil {
+		return nil, err
+	}
+	return r.notificationStore().create(notification)
+}
+
+// UpdateNotification 更新 ECSMNotification 的 spec 和 metadata，status 保持不变。
+func (r *Registry) UpdateNotification(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error) {
+	if err := validateNotification(notification); err != nil {
+		return nil, err
+	}
+	return r.notificationStore().update(notification, func(current, incoming *ecsmv1.ECSMNotification) *ecsmv1.ECSMNotification {
+		updated := incoming.DeepCopy()
+		updated.Status = current.Status
+		return updated
+	})
+}
+
+// UpdateNotificationStatus 只用传入对象的 status 覆盖存储中的 status，spec 和 metadata 保持不变。
+func (r *Registry) UpdateNotificationStatus(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error) {
+	return r.notificationStore().updateUnconditionally(notification, func(current, incoming *ecsmv1.ECSMNotification) *ecsmv1.ECSMNotification {
+		updated := current.DeepCopy()
+		updated.Status = incoming.Status
+		return updated
+	})
+}
+
+// GetNotification 获取单个 ECSMNotification。
+func (r *Registry) GetNotification(ctx context.Context, name string) (*ecsmv1.ECSMNotification, error) {
+	return r.notificationStore().get("", name)
+}
+
+// ListNotifications 返回所有 ECSMNotification 和一个全局的 ResourceVersion。
+func (r *Registry) ListNotifications(ctx context.Context) (*ecsmv1.ECSMNotificationList, string, error) {
+	items, rv, err := r.notificationStore().list("")
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMNotificationList{Items: items}, rv, nil
+}
+
+// DeleteNotification 删除一个 ECSMNotification。
+func (r *Registry) DeleteNotification(ctx context.Context, name string) error {
+	return r.notificationStore().delete("", name)
+}
@@ -0,0 +1,46 @@
+// file: pkg/registry/priorityclass.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _priorityClassesBucket = "ecsmpriorityclasses"
+
+// priorityClassStore 返回 ECSMPriorityClass 资源的通用存储。ECSMPriorityClass 是集群级别的资源，key 就是它的名称。
+func (r *Registry) priorityClassStore() *resourceStore[ecsmv1.ECSMPriorityClass, *ecsmv1.ECSMPriorityClass] {
+	return newResourceStore[ecsmv1.ECSMPriorityClass](r, _priorityClassesBucket,
+		ecsmv1.Resource("ecsmpriorityclasses"), ecsmv1.SchemeGroupVersion.WithKind("ECSMPriorityClass").GroupKind())
+}
+
+// CreatePriorityClass 创建一个新的 ECSMPriorityClass。
+func (r *Registry) CreatePriorityClass(ctx context.Context, pc *ecsmv1.ECSMPriorityClass) (*ecsmv1.ECSMPriorityClass, error) {
+	return r.priorityClassStore().create(pc)
+}
+
+// UpdatePriorityClass 更新 ECSMPriorityClass。它没有 status，传入的对象会整体替换存储中的对象。
+func (r *Registry) UpdatePriorityClass(ctx context.Context, pc *ecsmv1.ECSMPriorityClass) (*ecsmv1.ECSMPriorityClass, error) {
+	return r.priorityClassStore().replace(pc)
+}
+
+// GetPriorityClass 获取单个 ECSMPriorityClass。
+func (r *Registry) GetPriorityClass(ctx context.Context, name string) (*ecsmv1.ECSMPriorityClass, error) {
+	return r.priorityClassStore().get("", name)
+}
+
+// ListPriorityClasses 返回所有 ECSMPriorityClass 和一个全局的 ResourceVersion。
+func (r *Registry) ListPriorityClasses(ctx context.Context) (*ecsmv1.ECSMPriorityClassList, string, error) {
+	items, rv, err := r.priorityClassStore().list("")
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMPriorityClassList{Items: items}, rv, nil
+}
+
+// DeletePriorityClass 删除一个 ECSMPriorityClass。引用它的服务之后按默认优先级处理。
+func (r *Registry) DeletePriorityClass(ctx context.Context, name string) error {
+	return r.priorityClassStore().delete("", name)
+}
@@ -35,9 +35,84 @@ type Interface interface {
 	GetService(ctx context.Context, namespace, name string) (*ecsmv1.ECSMService, error)
 	ListAllServices(ctx context.Context, namespace string) (*ecsmv1.ECSMServiceList, string, error)
 	DeleteService(ctx context.Context, namespace, name string) error
-
-	// -- Node-specific methods (future) --
-	// ...
+	GetServiceScale(ctx context.Context, namespace, name string) (*ecsmv1.ECSMServiceScale, error)
+	UpdateServiceScale(ctx context.Context, scale *ecsmv1.ECSMServiceScale) (*ecsmv1.ECSMServiceScale, error)
+
+	// -- Event-specific methods --
+	CreateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error)
+	UpdateEvent(ctx context.Context, event *ecsmv1.Event) (*ecsmv1.Event, error)
+	GetEvent(ctx context.Context, namespace, name string) (*ecsmv1.Event, error)
+	ListEvents(ctx context.Context, namespace string) (*ecsmv1.EventList, string, error)
+	DeleteEvent(ctx context.Context, namespace, name string) error
+
+	// -- Node-specific methods --
+	CreateNode(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error)
+	UpdateNode(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error)
+	UpdateNodeStatus(ctx context.Context, node *ecsmv1.ECSMNode) (*ecsmv1.ECSMNode, error)
+	GetNode(ctx context.Context, name string) (*ecsmv1.ECSMNode, error)
+	ListNodes(ctx context.Context) (*ecsmv1.ECSMNodeList, string, error)
+	DeleteNode(ctx context.Context, name string) error
+
+	// -- Autoscaler-specific methods --
+	CreateAutoscaler(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error)
+	UpdateAutoscaler(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error)
+	UpdateAutoscalerStatus(ctx context.Context, autoscaler *ecsmv1.ECSMServiceAutoscaler) (*ecsmv1.ECSMServiceAutoscaler, error)
+	GetAutoscaler(ctx context.Context, namespace, name string) (*ecsmv1.ECSMServiceAutoscaler, error)
+	ListAutoscalers(ctx context.Context, namespace string) (*ecsmv1.ECSMServiceAutoscalerList, string, error)
+	DeleteAutoscaler(ctx context.Context, namespace, name string) error
+
+	// -- Job-specific methods --
+	CreateJob(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error)
+	UpdateJob(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error)
+	UpdateJobStatus(ctx context.Context, job *ecsmv1.ECSMJob) (*ecsmv1.ECSMJob, error)
+	GetJob(ctx context.Context, namespace, name string) (*ecsmv1.ECSMJob, error)
+	ListJobs(ctx context.Context, namespace string) (*ecsmv1.ECSMJobList, string, error)
+	DeleteJob(ctx context.Context, namespace, name string) error
+
+	// -- CronJob-specific methods --
+	CreateCronJob(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error)
+	UpdateCronJob(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error)
+	UpdateCronJobStatus(ctx context.Context, cronJob *ecsmv1.ECSMCronJob) (*ecsmv1.ECSMCronJob, error)
+	GetCronJob(ctx context.Context, namespace, name string) (*ecsmv1.ECSMCronJob, error)
+	ListCronJobs(ctx context.Context, namespace string) (*ecsmv1.ECSMCronJobList, string, error)
+	DeleteCronJob(ctx context.Context, namespace, name string) error
+
+	// -- NodeSet-specific methods --
+	CreateNodeSet(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error)
+	UpdateNodeSet(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error)
+	UpdateNodeSetStatus(ctx context.Context, nodeSet *ecsmv1.ECSMNodeSet) (*ecsmv1.ECSMNodeSet, error)
+	GetNodeSet(ctx context.Context, namespace, name string) (*ecsmv1.ECSMNodeSet, error)
+	ListNodeSets(ctx context.Context, namespace string) (*ecsmv1.ECSMNodeSetList, string, error)
+	DeleteNodeSet(ctx context.Context, namespace, name string) error
+
+	// -- Config-specific methods --
+	CreateConfig(ctx context.Context, config *ecsmv1.ECSMConfig) (*ecsmv1.ECSMConfig, error)
+	UpdateConfig(ctx context.Context, config *ecsmv1.ECSMConfig) (*ecsmv1.ECSMConfig, error)
+	GetConfig(ctx context.Context, namespace, name string) (*ecsmv1.ECSMConfig, error)
+	ListConfigs(ctx context.Context, namespace string) (*ecsmv1.ECSMConfigList, string, error)
+	DeleteConfig(ctx context.Context, namespace, name string) error
+
+	// -- Secret-specific methods --
+	CreateSecret(ctx context.Context, secret *ecsmv1.ECSMSecret) (*ecsmv1.ECSMSecret, error)
+	UpdateSecret(ctx context.Context, secret *ecsmv1.ECSMSecret) (*ecsmv1.ECSMSecret, error)
+	GetSecret(ctx context.Context, namespace, name string) (*ecsmv1.ECSMSecret, error)
+	ListSecrets(ctx context.Context, namespace string) (*ecsmv1.ECSMSecretList, string, error)
+	DeleteSecret(ctx context.Context, namespace, name string) error
+
+	// -- PriorityClass-specific methods --
+	CreatePriorityClass(ctx context.Context, pc *ecsmv1.ECSMPriorityClass) (*ecsmv1.ECSMPriorityClass, error)
+	UpdatePriorityClass(ctx context.Context, pc *ecsmv1.ECSMPriorityClass) (*ecsmv1.ECSMPriorityClass, error)
+	GetPriorityClass(ctx context.Context, name string) (*ecsmv1.ECSMPriorityClass, error)
+	ListPriorityClasses(ctx context.Context) (*ecsmv1.ECSMPriorityClassList, string, error)
+	DeletePriorityClass(ctx context.Context, name string) error
+
+	// -- Notification-specific methods --
+	CreateNotification(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error)
+	UpdateNotification(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error)
+	UpdateNotificationStatus(ctx context.Context, notification *ecsmv1.ECSMNotification) (*ecsmv1.ECSMNotification, error)
+	GetNotification(ctx context.Context, name string) (*ecsmv1.ECSMNotification, error)
+	ListNotifications(ctx context.Context) (*ecsmv1.ECSMNotificationList, string, error)
+	DeleteNotification(ctx context.Context, name string) error
 
 	// -- Image-specific methods (future) --
 	// ...
@@ -47,6 +122,13 @@ type Interface interface {
 type Registry struct {
 	db *bolt.DB // 直接持有 bbolt DB 实例以使用其事务
 
+	// transformer 用于加密需要静态加密的资源，为 nil 时这些资源不可用
+	transformer Transformer
+
+	// writeLock 串行化所有递增全局版本号的写操作及其事件的发布，
+	// 保证事件按 Revision 递增的顺序到达订阅者。bbolt 本身同一时刻也只允许一个写事务。
+	writeLock sync.Mutex
+
 	// --- 事件相关的字段 ---
 	subs      map[int]chan Event // 存储所有订阅者的 channel
 	nextSubID int
@@ -55,14 +137,17 @@ type Registry struct {
 
 // NewRegistry 创建一个新的 Registry 实例。
 // 它接收一个已经打开的 bbolt 数据库实例。
+// 以只读方式打开的数据库 (例如 ecsm-cli 查看事件时) 不会初始化 bucket。
 func NewRegistry(db *bolt.DB) (*Registry, error) {
-	// 初始化元数据 bucket
-	err := db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(_metadataBucketKey)
-		return err
-	})
-	if err != nil {
-		return nil, err
+	if !db.IsReadOnly() {
+		// 初始化元数据 bucket
+		err := db.Update(func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(_metadataBucketKey)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &Registry{
@@ -0,0 +1,97 @@
+// file: pkg/registry/scale.go
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/retry"
+)
+
+// GetServiceScale 返回 ECSMService 的 scale 子资源。
+func (r *Registry) GetServiceScale(ctx context.Context, namespace, name string) (*ecsmv1.ECSMServiceScale, error) {
+	service, err := r.GetService(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return scaleFromService(service), nil
+}
+
+// UpdateServiceScale 只修改 ECSMService 的 spec.deploymentStrategy.replicas，spec 中的其他字段保持不变。
+// scale 设置了 resourceVersion 时，它必须与服务当前的 resourceVersion 相同，否则返回 Conflict；
+// 为空时副本数被无条件地修改，与其他写入者的冲突会被自动重试。
+// Static 策略的服务副本数由节点列表决定，不能通过 scale 子资源修改。
+func (r *Registry) UpdateServiceScale(ctx context.Context, scale *ecsmv1.ECSMServiceScale) (*ecsmv1.ECSMServiceScale, error) {
+	if scale.Spec.Replicas < 0 {
+		errs := field.ErrorList{field.Invalid(field.NewPath("spec", "replicas"), scale.Spec.Replicas, "must not be negative")}
+		return nil, errors.NewInvalid(ecsmv1.Kind("ECSMServiceScale"), scale.Name, errs)
+	}
+
+	var updated *ecsmv1.ECSMService
+	update := func() error {
+		service, err := r.GetService(ctx, scale.Namespace, scale.Name)
+		if err != nil {
+			return err
+		}
+		if scale.ResourceVersion != "" && scale.ResourceVersion != service.ResourceVersion {
+			return errors.NewConflict(ecsmv1.Resource("ecsmservices"), scale.Name,
+				fmt.Errorf("object has been modified; please apply your changes to the latest version and try again"))
+		}
+		if service.Spec.DeploymentStrategy.Type == ecsmv1.DeploymentStrategyTypeStatic {
+			errs := field.ErrorList{field.Forbidden(field.NewPath("spec", "replicas"),
+				"the replicas of a service with the Static deployment strategy are determined by its nodes")}
+			return errors.NewInvalid(ecsmv1.Kind("ECSMServiceScale"), scale.Name, errs)
+		}
+		if current := service.Spec.DeploymentStrategy.Replicas; current != nil && *current == scale.Spec.Replicas {
+			updated = service
+			return nil
+		}
+		replicas := scale.Spec.Replicas
+		service.Spec.DeploymentStrategy.Replicas = &replicas
+		updated, err = r.UpdateService(ctx, service)
+		return err
+	}
+
+	var err error
+	if scale.ResourceVersion != "" {
+		err = update()
+	} else {
+		err = retry.RetryOnConflict(retry.DefaultRetry, update)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scaleFromService(updated), nil
+}
+
+// scaleFromService 从 ECSMService 构造它的 scale 子资源。
+func scaleFromService(service *ecsmv1.ECSMService) *ecsmv1.ECSMServiceScale {
+	// 与 Kubernetes 的 Scale 一样，scale 子资源只携带服务的标识和 resourceVersion
+	scale := &ecsmv1.ECSMServiceScale{
+		TypeMeta: metav1.TypeMeta{APIVersion: ecsmv1.SchemeGroupVersion.String(), Kind: "ECSMServiceScale"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              service.Name,
+			Namespace:         service.Namespace,
+			UID:               service.UID,
+			ResourceVersion:   service.ResourceVersion,
+			CreationTimestamp: service.CreationTimestamp,
+		},
+		Status: ecsmv1.ScaleStatus{
+			Replicas:      service.Status.Replicas,
+			ReadyReplicas: service.Status.ReadyReplicas,
+		},
+	}
+
+	strategy := service.Spec.DeploymentStrategy
+	if strategy.Type == ecsmv1.DeploymentStrategyTypeStatic {
+		scale.Spec.Replicas = int32(len(strategy.Nodes))
+	} else if strategy.Replicas != nil {
+		scale.Spec.Replicas = *strategy.Replicas
+	}
+	return scale
+}
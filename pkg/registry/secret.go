@@ -0,0 +1,48 @@
+// file: pkg/registry/secret.go
+
+package registry
+
+import (
+	"context"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+)
+
+const _secretsBucket = "ecsmsecrets"
+
+// secretStore 返回 ECSMSecret 资源的通用存储，对象在写入 bbolt 之前会被加密。
+func (r *Registry) secretStore() *resourceStore[ecsmv1.ECSMSecret, *ecsmv1.ECSMSecret] {
+	s := newResourceStore[ecsmv1.ECSMSecret](r, _secretsBucket,
+		ecsmv1.Resource("ecsmsecrets"), ecsmv1.SchemeGroupVersion.WithKind("ECSMSecret").GroupKind())
+	s.encrypted = true
+	return s
+}
+
+// CreateSecret 创建一个新的 ECSMSecret。Registry 没有设置加密时返回 ErrNoEncryptionKey。
+func (r *Registry) CreateSecret(ctx context.Context, secret *ecsmv1.ECSMSecret) (*ecsmv1.ECSMSecret, error) {
+	return r.secretStore().create(secret)
+}
+
+// UpdateSecret 更新 ECSMSecret。它没有 status，传入的对象会整体替换存储中的对象。
+func (r *Registry) UpdateSecret(ctx context.Context, secret *ecsmv1.ECSMSecret) (*ecsmv1.ECSMSecret, error) {
+	return r.secretStore().replace(secret)
+}
+
+// GetSecret 获取单个 ECSMSecret。
+func (r *Registry) GetSecret(ctx context.Context, namespace, name string) (*ecsmv1.ECSMSecret, error) {
+	return r.secretStore().get(namespace, name)
+}
+
+// ListSecrets 返回指定命名空间下的所有 ECSMSecret，namespace 为空时返回所有命名空间的对象。
+func (r *Registry) ListSecrets(ctx context.Context, namespace string) (*ecsmv1.ECSMSecretList, string, error) {
+	items, rv, err := r.secretStore().list(namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return &ecsmv1.ECSMSecretList{Items: items}, rv, nil
+}
+
+// DeleteSecret 删除一个 ECSMSecret。
+func (r *Registry) DeleteSecret(ctx context.Context, namespace, name string) error {
+	return r.secretStore().delete(namespace, name)
+}
@@ -0,0 +1,67 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	bolt "go.etcd.io/bbolt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSecretEncryptedAtRest(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+
+	secret := &ecsmv1.ECSMSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"},
+		Data:       map[string]string{"password": "s3cr3t-value"},
+	}
+
+	// 没有配置密钥时不能写入
+	if _, err := reg.CreateSecret(ctx, secret.DeepCopy()); !IsMissingKey(err) {
+		t.Fatalf("CreateSecret without a key: got %v, want ErrNoEncryptionKey", err)
+	}
+
+	transformer, err := NewAESGCMTransformer(bytes.Repeat([]byte{0x42}, 32))
+	if err != nil {
+		t.Fatalf("NewAESGCMTransformer failed: %v", err)
+	}
+	reg.SetEncryption(transformer)
+	if _, err := reg.CreateSecret(ctx, secret.DeepCopy()); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+
+	// 数据库中保存的是密文
+	err = reg.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(_secretsBucket)).Get([]byte("default/db"))
+		if !isEncrypted(raw) {
+			t.Errorf("stored secret is not encrypted")
+		}
+		if bytes.Contains(raw, []byte("s3cr3t-value")) {
+			t.Errorf("stored secret contains the plaintext value")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View failed: %v", err)
+	}
+
+	got, err := reg.GetSecret(ctx, "default", "db")
+	if err != nil {
+		t.Fatalf("GetSecret failed: %v", err)
+	}
+	if got.Data["password"] != "s3cr3t-value" {
+		t.Errorf("GetSecret returned data %v, want the decrypted value", got.Data)
+	}
+
+	// 其他资源不受加密影响
+	reg.SetEncryption(nil)
+	if _, _, err := reg.ListSecrets(ctx, ""); !IsMissingKey(err) {
+		t.Errorf("ListSecrets without a key: got %v, want ErrNoEncryptionKey", err)
+	}
+	if _, err := reg.CreateService(ctx, newTestService("default", "app")); err != nil {
+		t.Errorf("CreateService without a key failed: %v", err)
+	}
+}
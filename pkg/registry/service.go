@@ -11,9 +11,12 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/defaults"
 	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/apis/ecsm/validation"
 	"github.com/google/uuid"
 	bolt "go.etcd.io/bbolt"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -27,8 +30,8 @@ var (
 )
 
 func (r *Registry) CreateService(ctx context.Context, service *ecsmv1.ECSMService) (*ecsmv1.ECSMService, error) {
-	setServiceDefaults(service)
-	if errs := validateService(service); len(errs) > 0 {
+	defaults.SetServiceDefaults(service)
+	if errs := validation.ValidateService(service); len(errs) > 0 {
 		return nil, errors.NewInvalid(ecsmv1.SchemeGroupVersion.WithKind("ECSMService").GroupKind(), service.Name, errs)
 	}
 
@@ -37,6 +40,10 @@ func (r *Registry) CreateService(ctx context.Context, service *ecsmv1.ECSMServic
 		return nil, err
 	}
 
+	r.writeLock.Lock()
+	defer r.writeLock.Unlock()
+
+	var revision uint64
 	err = r.db.Update(func(tx *bolt.Tx) error {
 		// 获取元数据和业务数据 bucket
 		metaBucket := tx.Bucket(_metadataBucketKey)
@@ -55,11 +62,13 @@ func (r *Registry) CreateService(ctx context.Context, service *ecsmv1.ECSMServic
 		if err != nil {
 			return err
 		}
+		revision = newRV
 
 		// 填充系统字段
 		service.ResourceVersion = strconv.FormatUint(newRV, 10)
 		service.UID = types.UID(uuid.New().String())
 		service.CreationTimestamp = metav1.Time{Time: time.Now().UTC()}
+		service.Generation = 1
 
 		buf, err := json.Marshal(service)
 		if err != nil {
@@ -79,6 +88,7 @@ func (r *Registry) CreateService(ctx context.Context, service *ecsmv1.ECSMServic
 		Key:             key,
 		Object:          service,
 		ResourceVersion: service.ResourceVersion,
+		Revision:        revision,
 	})
 
 	return service, nil
@@ -98,6 +108,11 @@ func (r *Registry) UpdateService(ctx context.Context, service *ecsmv1.ECSMServic
 		return nil, err
 	}
 
+	r.writeLock.Lock()
+	defer r.writeLock.Unlock()
+
+	var revision uint64
+	eventType := Modified
 	err = r.db.Update(func(tx *bolt.Tx) error {
 		metaBucket := tx.Bucket(_metadataBucketKey)
 		b := tx.Bucket(_servicesBucketKey)
@@ -112,7 +127,7 @@ func (r *Registry) UpdateService(ctx context.Context, service *ecsmv1.ECSMServic
 		}
 
 		var currentService ecsmv1.ECSMService
-		if err := json.Unmarshal(currentBytes, &currentService); err != nil {
+		if err := decodeVersioned(currentBytes, &currentService); err != nil {
 			return err
 		}
 
@@ -120,16 +135,39 @@ func (r *Registry) UpdateService(ctx context.Context, service *ecsmv1.ECSMServic
 			return errors.NewConflict(ecsmv1.SchemeGroupVersion.WithResource("ecsmservices").GroupResource(), service.Name, fmt.Errorf("object has been modified; please apply your changes to the latest version and try again"))
 		}
 
+		// 只在 spec 变化时校验和递增 generation，使不合法的旧对象仍然可以更新元数据 (例如移除 finalizer)
+		service.Generation = currentService.Generation
+		if !equality.Semantic.DeepEqual(currentService.Spec, service.Spec) {
+			if errs := validation.ValidateService(service); len(errs) > 0 {
+				return errors.NewInvalid(ecsmv1.SchemeGroupVersion.WithKind("ECSMService").GroupKind(), service.Name, errs)
+			}
+			service.Generation++
+		}
+		// 模板变化时记录之前的模板，使用户可以回退发布
+		if !equality.Semantic.DeepEqual(currentService.Spec.Template, service.Spec.Template) {
+			if err := recordPreviousTemplate(service, &currentService.Spec.Template); err != nil {
+				return err
+			}
+		}
+
 		// Act: 递增 RV 并写入新对象
 		newRV, err := getAndIncrementGlobalRV(metaBucket)
 		if err != nil {
 			return err
 		}
+		revision = newRV
 
 		service.ResourceVersion = strconv.FormatUint(newRV, 10)
-		// 确保 UID 和创建时间戳不被修改
+		// 确保 UID、创建时间戳和删除时间戳不被修改
 		service.UID = currentService.UID
 		service.CreationTimestamp = currentService.CreationTimestamp
+		service.DeletionTimestamp = currentService.DeletionTimestamp
+
+		// 对象已被标记删除，且最后一个 finalizer 也已被移除，此时才真正删除它
+		if service.DeletionTimestamp != nil && len(service.Finalizers) == 0 {
+			eventType = Deleted
+			return b.Delete([]byte(key))
+		}
 
 		buf, err := json.Marshal(service)
 		if err != nil {
@@ -144,15 +182,32 @@ func (r *Registry) UpdateService(ctx context.Context, service *ecsmv1.ECSMServic
 
 	// 发布事件
 	r.publish(Event{
-		Type:            Modified,
+		Type:            eventType,
 		Key:             key,
 		Object:          service,
 		ResourceVersion: service.ResourceVersion,
+		Revision:        revision,
 	})
 
 	return service, nil
 }
 
+// recordPreviousTemplate 把 previous 写入 service 的 PreviousTemplateAnnotation。
+// annotations 可能与调用方的其它对象共享，这里写入它的副本。
+func recordPreviousTemplate(service *ecsmv1.ECSMService, previous *ecsmv1.ContainerTemplateSpec) error {
+	data, err := json.Marshal(previous)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(service.Annotations)+1)
+	for k, v := range service.Annotations {
+		annotations[k] = v
+	}
+	annotations[ecsmv1.PreviousTemplateAnnotation] = string(data)
+	service.Annotations = annotations
+	return nil
+}
+
 // ... (List, Get, Delete 等方法的实现也应遵循类似的事务模式) ...
 // UpdateServiceStatus 是一个专门用于更新 Service Status 子资源的业务方法。
 // 它的核心逻辑是：只用传入对象的 status 覆盖存储中的 status，而 spec 和 metadata 保持不变。
@@ -164,6 +219,10 @@ func (r *Registry) UpdateServiceStatus(ctx context.Context, service *ecsmv1.ECSM
 
 	var updatedService *ecsmv1.ECSMService
 
+	r.writeLock.Lock()
+	defer r.writeLock.Unlock()
+
+	var revision uint64
 	err = r.db.Update(func(tx *bolt.Tx) error {
 		metaBucket := tx.Bucket(_metadataBucketKey)
 		b := tx.Bucket(_servicesBucketKey)
@@ -178,7 +237,7 @@ func (r *Registry) UpdateServiceStatus(ctx context.Context, service *ecsmv1.ECSM
 		}
 
 		var currentService ecsmv1.ECSMService
-		if err := json.Unmarshal(currentBytes, &currentService); err != nil {
+		if err := decodeVersioned(currentBytes, &currentService); err != nil {
 			return err
 		}
 
@@ -192,6 +251,7 @@ func (r *Registry) UpdateServiceStatus(ctx context.Context, service *ecsmv1.ECSM
 		if err != nil {
 			return err
 		}
+		revision = newRV
 		updatedService.ResourceVersion = strconv.FormatUint(newRV, 10)
 
 		buf, err := json.Marshal(updatedService)
@@ -211,6 +271,7 @@ func (r *Registry) UpdateServiceStatus(ctx context.Context, service *ecsmv1.ECSM
 		Key:             key,
 		Object:          updatedService,
 		ResourceVersion: updatedService.ResourceVersion,
+		Revision:        revision,
 	})
 
 	return updatedService, nil
@@ -233,7 +294,7 @@ func (r *Registry) GetService(ctx context.Context, namespace, name string) (*ecs
 			return errors.NewNotFound(ecsmv1.Resource("ecsmservices"), name)
 		}
 
-		return json.Unmarshal(val, &service)
+		return decodeVersioned(val, &service)
 	})
 
 	if err != nil {
@@ -243,6 +304,7 @@ func (r *Registry) GetService(ctx context.Context, namespace, name string) (*ecs
 }
 
 // ListAllServices 返回指定命名空间下的所有 ECSMService 对象和一个全局的 ResourceVersion。
+// namespace 为空时返回所有命名空间的对象。
 // 这个方法将用于 Informer 的 resync 过程。
 func (r *Registry) ListAllServices(ctx context.Context, namespace string) (*ecsmv1.ECSMServiceList, string, error) {
 	serviceList := &ecsmv1.ECSMServiceList{
@@ -261,11 +323,14 @@ func (r *Registry) ListAllServices(ctx context.Context, namespace string) (*ecsm
 		}
 
 		c := b.Cursor()
-		prefix := []byte(namespace + "/")
+		var prefix []byte
+		if namespace != "" {
+			prefix = []byte(namespace + "/")
+		}
 
 		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
 			var service ecsmv1.ECSMService
-			if err := json.Unmarshal(v, &service); err != nil {
+			if err := decodeVersioned(v, &service); err != nil {
 				// 记录错误但继续，以增加健壮性
 				klog.Errorf("Failed to unmarshal service object with key %s: %v", string(k), err)
 				continue
@@ -275,6 +340,9 @@ func (r *Registry) ListAllServices(ctx context.Context, namespace string) (*ecsm
 
 		// 2. 获取全局 ResourceVersion
 		metaBucket := tx.Bucket(_metadataBucketKey)
+		if metaBucket == nil {
+			return nil
+		}
 		rvBytes := metaBucket.Get(_globalResourceVersionKey)
 		if rvBytes != nil {
 			rvUint := binary.BigEndian.Uint64(rvBytes)
@@ -291,11 +359,19 @@ func (r *Registry) ListAllServices(ctx context.Context, namespace string) (*ecsm
 	return serviceList, resourceVersion, nil
 }
 
-// DeleteService ... (实现与 Create/Update 类似, 在 Update 事务中)
+// DeleteService 删除一个 ECSMService。
+// 如果对象上还有 finalizer，它只会被标记删除 (设置 deletionTimestamp) 并发布一个 MODIFIED 事件，
+// 等到所有 finalizer 都被移除后，UpdateService 才会真正删除它。
 func (r *Registry) DeleteService(ctx context.Context, namespace, name string) error {
 	key := namespace + "/" + name
 	var deletedService ecsmv1.ECSMService
+	found := false
+	eventType := Deleted
+
+	r.writeLock.Lock()
+	defer r.writeLock.Unlock()
 
+	var revision uint64
 	err := r.db.Update(func(tx *bolt.Tx) error {
 		metaBucket := tx.Bucket(_metadataBucketKey)
 		b := tx.Bucket(_servicesBucketKey)
@@ -308,35 +384,49 @@ func (r *Registry) DeleteService(ctx context.Context, namespace, name string) er
 		if val == nil {
 			return nil
 		} // Already deleted
-		json.Unmarshal(val, &deletedService)
-
-		if err := b.Delete([]byte(key)); err != nil {
+		if err := decodeVersioned(val, &deletedService); err != nil {
 			return err
 		}
+		if len(deletedService.Finalizers) > 0 && deletedService.DeletionTimestamp != nil {
+			// 已经处于删除中，重复删除不做任何修改
+			return nil
+		}
+		found = true
 
 		// 删除也应该递增全局版本号
-		_, err := getAndIncrementGlobalRV(metaBucket)
-		return err
+		newRV, err := getAndIncrementGlobalRV(metaBucket)
+		if err != nil {
+			return err
+		}
+		revision = newRV
+
+		if len(deletedService.Finalizers) > 0 {
+			// 优雅删除：只标记，等待 finalizer 的持有者完成清理
+			eventType = Modified
+			now := metav1.NewTime(time.Now().UTC())
+			deletedService.DeletionTimestamp = &now
+			deletedService.ResourceVersion = strconv.FormatUint(newRV, 10)
+			buf, err := json.Marshal(&deletedService)
+			if err != nil {
+				return err
+			}
+			return b.Put([]byte(key), buf)
+		}
+
+		return b.Delete([]byte(key))
 	})
 
-	if err != nil {
+	if err != nil || !found {
 		return err
 	}
 
 	r.publish(Event{
-		Type:            Deleted,
+		Type:            eventType,
 		Key:             key,
 		Object:          &deletedService,
-		ResourceVersion: deletedService.ResourceVersion, // 传递被删除前的最后版本
+		ResourceVersion: deletedService.ResourceVersion, // 对于真正的删除，传递被删除前的最后版本
+		Revision:        revision,
 	})
 
 	return nil
 }
-func setServiceDefaults(service *ecsmv1.ECSMService) {
-	// 填充默认值
-}
-
-func validateService(service *ecsmv1.ECSMService) field.ErrorList {
-	// 验证对象
-	return nil
-}
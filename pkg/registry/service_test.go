@@ -0,0 +1,241 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	bolt "go.etcd.io/bbolt"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "registry.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	reg, err := NewRegistry(db)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	return reg
+}
+
+func TestDeleteServiceWithFinalizer(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+
+	svc := newTestService("default", "app-one")
+	svc.Finalizers = []string{ecsmv1.ServiceCleanupFinalizer}
+	if _, err := reg.CreateService(ctx, svc); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	// 带有 finalizer 的对象只会被标记删除
+	if err := reg.DeleteService(ctx, "default", "app-one"); err != nil {
+		t.Fatalf("DeleteService failed: %v", err)
+	}
+	marked, err := reg.GetService(ctx, "default", "app-one")
+	if err != nil {
+		t.Fatalf("Expected object to still exist after delete, got: %v", err)
+	}
+	if marked.DeletionTimestamp == nil {
+		t.Fatalf("Expected deletionTimestamp to be set")
+	}
+
+	// 普通的更新不能清除 deletionTimestamp
+	marked.DeletionTimestamp = nil
+	marked.Labels = map[string]string{"foo": "bar"}
+	updated, err := reg.UpdateService(ctx, marked)
+	if err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	if updated.DeletionTimestamp == nil {
+		t.Fatalf("Expected deletionTimestamp to be preserved across updates")
+	}
+
+	// 移除最后一个 finalizer 后，对象被真正删除
+	updated.Finalizers = nil
+	if _, err := reg.UpdateService(ctx, updated); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	if _, err := reg.GetService(ctx, "default", "app-one"); !errors.IsNotFound(err) {
+		t.Fatalf("Expected NotFound after removing finalizer, got: %v", err)
+	}
+}
+
+func TestUpdateServiceValidatesSpecChanges(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+
+	created, err := reg.CreateService(ctx, newTestService("default", "app"))
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	invalid := created.DeepCopy()
+	invalid.Spec.Template.Image = ""
+	if _, err := reg.UpdateService(ctx, invalid); !errors.IsInvalid(err) {
+		t.Fatalf("UpdateService with an invalid spec: got %v, want an Invalid error", err)
+	}
+
+	// 不修改 spec 的更新不会被校验
+	labeled := created.DeepCopy()
+	labeled.Labels = map[string]string{"tier": "web"}
+	if _, err := reg.UpdateService(ctx, labeled); err != nil {
+		t.Fatalf("UpdateService with only label changes failed: %v", err)
+	}
+}
+
+func TestGenerationIncrementsOnSpecChanges(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+
+	svc, err := reg.CreateService(ctx, newTestService("default", "app"))
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	if svc.Generation != 1 {
+		t.Fatalf("generation after create = %d, want 1", svc.Generation)
+	}
+
+	svc.Labels = map[string]string{"tier": "web"}
+	if svc, err = reg.UpdateService(ctx, svc); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	svc.Status.ObservedGeneration = 1
+	if svc, err = reg.UpdateServiceStatus(ctx, svc); err != nil {
+		t.Fatalf("UpdateServiceStatus failed: %v", err)
+	}
+	if svc.Generation != 1 {
+		t.Errorf("generation after metadata and status updates = %d, want 1", svc.Generation)
+	}
+
+	svc.Spec.Template.Image = "nginx@1.25#linux"
+	if svc, err = reg.UpdateService(ctx, svc); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	if svc.Generation != 2 {
+		t.Errorf("generation after a spec update = %d, want 2", svc.Generation)
+	}
+
+	// 通用存储对没有 spec 字段的资源同样适用
+	cfg, err := reg.CreateConfig(ctx, &ecsmv1.ECSMConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cfg"},
+		Data:       map[string]string{"k": "v1"},
+	})
+	if err != nil {
+		t.Fatalf("CreateConfig failed: %v", err)
+	}
+	cfg.Labels = map[string]string{"tier": "web"}
+	if cfg, err = reg.UpdateConfig(ctx, cfg); err != nil || cfg.Generation != 1 {
+		t.Fatalf("UpdateConfig with a label change = generation %d, %v, want 1", cfg.Generation, err)
+	}
+	cfg.Data["k"] = "v2"
+	if cfg, err = reg.UpdateConfig(ctx, cfg); err != nil || cfg.Generation != 2 {
+		t.Fatalf("UpdateConfig with a data change = generation %d, %v, want 2", cfg.Generation, err)
+	}
+}
+
+func TestUpdateServiceRecordsPreviousTemplate(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+
+	svc, err := reg.CreateService(ctx, newTestService("default", "app"))
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	original := svc.Spec.Template.DeepCopy()
+
+	// 模板之外的变化不记录
+	svc.Labels = map[string]string{"tier": "web"}
+	if svc, err = reg.UpdateService(ctx, svc); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	if _, ok := svc.Annotations[ecsmv1.PreviousTemplateAnnotation]; ok {
+		t.Fatalf("previous template recorded for a label change")
+	}
+
+	annotations := map[string]string{"owner": "team-a"}
+	svc.Annotations = annotations
+	svc.Spec.Template.Image = "nginx@1.25#linux"
+	if svc, err = reg.UpdateService(ctx, svc); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	var previous ecsmv1.ContainerTemplateSpec
+	if err := json.Unmarshal([]byte(svc.Annotations[ecsmv1.PreviousTemplateAnnotation]), &previous); err != nil {
+		t.Fatalf("failed to decode previous template annotation: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(&previous, original) {
+		t.Errorf("previous template = %+v, want %+v", previous, original)
+	}
+	if svc.Annotations["owner"] != "team-a" {
+		t.Errorf("existing annotations were not kept: %v", svc.Annotations)
+	}
+	if _, ok := annotations[ecsmv1.PreviousTemplateAnnotation]; ok {
+		t.Errorf("the caller's annotations map was modified")
+	}
+}
+
+func TestUpdateServiceScale(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+
+	svc := newTestService("default", "web")
+	replicas := int32(2)
+	svc.Spec.DeploymentStrategy = ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeDynamic, Replicas: &replicas, NodePool: []string{"node-a"}}
+	created, err := reg.CreateService(ctx, svc)
+	if err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+
+	scale, err := reg.GetServiceScale(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetServiceScale failed: %v", err)
+	}
+	if scale.Spec.Replicas != 2 || scale.ResourceVersion != created.ResourceVersion {
+		t.Fatalf("scale = %+v, want 2 replicas at resourceVersion %s", scale, created.ResourceVersion)
+	}
+
+	// 其他写入者修改了 spec 之后，带有旧 resourceVersion 的 scale 会冲突，不带 resourceVersion 的不会
+	created.Labels = map[string]string{"tier": "web"}
+	if _, err := reg.UpdateService(ctx, created); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	scale.Spec.Replicas = 5
+	if _, err := reg.UpdateServiceScale(ctx, scale); !errors.IsConflict(err) {
+		t.Fatalf("UpdateServiceScale with a stale resourceVersion = %v, want a conflict", err)
+	}
+	scale.ResourceVersion = ""
+	updated, err := reg.UpdateServiceScale(ctx, scale)
+	if err != nil {
+		t.Fatalf("UpdateServiceScale failed: %v", err)
+	}
+	if updated.Spec.Replicas != 5 {
+		t.Errorf("scale replicas = %d, want 5", updated.Spec.Replicas)
+	}
+
+	got, err := reg.GetService(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if *got.Spec.DeploymentStrategy.Replicas != 5 || got.Labels["tier"] != "web" || got.Generation != 2 {
+		t.Errorf("service after scale: replicas=%d labels=%v generation=%d, want 5, the new label and generation 2",
+			*got.Spec.DeploymentStrategy.Replicas, got.Labels, got.Generation)
+	}
+
+	// Static 策略的服务不能通过 scale 子资源修改副本数
+	if _, err := reg.CreateService(ctx, newTestService("default", "static")); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	static := &ecsmv1.ECSMServiceScale{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "static"}, Spec: ecsmv1.ScaleSpec{Replicas: 3}}
+	if _, err := reg.UpdateServiceScale(ctx, static); !errors.IsInvalid(err) {
+		t.Errorf("scaling a Static service = %v, want an invalid error", err)
+	}
+}
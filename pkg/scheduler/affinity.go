@@ -0,0 +1,189 @@
+// file: pkg/scheduler/affinity.go
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+)
+
+// topologyTerm 是解析之后的一个亲和或反亲和约束
+type topologyTerm struct {
+	// key 是拓扑键，为空时每个节点自成一个拓扑域
+	key string
+	// domains 是匹配的其他服务的实例所在的拓扑域
+	domains map[string]bool
+	// self 表示服务自身也被这个约束选中
+	self bool
+}
+
+// topologyDomain 返回节点所在的拓扑域，节点没有拓扑键对应的标签时返回 false。
+func topologyDomain(n nodeInfo, key string) (string, bool) {
+	if key == "" {
+		return n.name, true
+	}
+	v, ok := n.labels[key]
+	return v, ok
+}
+
+// affinityTerms 解析服务的 affinity：找出每个约束选中的服务，并计算它们的实例所在的拓扑域。
+func (s *Scheduler) affinityTerms(ctx context.Context, service *ecsmv1.ECSMService, nodes []nodeInfo) ([]topologyTerm, []topologyTerm, error) {
+	a := service.Spec.Affinity
+	if a == nil || s.placements == nil || len(a.ServiceAffinity)+len(a.ServiceAntiAffinity) == 0 {
+		return nil, nil, nil
+	}
+	services, _, err := s.registry.ListAllServices(ctx, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list ECSMServices: %w", err)
+	}
+
+	byName := make(map[string]nodeInfo, len(nodes))
+	for _, n := range nodes {
+		byName[n.name] = n
+	}
+	self := cache.MetaObjectToName(service)
+	// 同一个服务可能被多个约束选中，只查询一次它的实例
+	placements := make(map[cache.ObjectName][]string)
+
+	resolve := func(term *ecsmv1.ServiceAffinityTerm) (topologyTerm, error) {
+		t := topologyTerm{key: term.TopologyKey, domains: make(map[string]bool)}
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			return t, fmt.Errorf("invalid affinity label selector: %w", err)
+		}
+		namespaces := sets.New(term.Namespaces...)
+		if namespaces.Len() == 0 {
+			namespaces.Insert(service.Namespace)
+		}
+
+		for i := range services.Items {
+			other := &services.Items[i]
+			if !namespaces.Has(other.Namespace) || !selector.Matches(labels.Set(other.Labels)) {
+				continue
+			}
+			name := cache.MetaObjectToName(other)
+			if name == self {
+				t.self = true
+				continue
+			}
+			placed, ok := placements[name]
+			if !ok {
+				if placed, err = s.placements(ctx, other); err != nil {
+					return t, fmt.Errorf("failed to get nodes of service %s: %w", name, err)
+				}
+				placements[name] = placed
+			}
+			for _, nodeName := range placed {
+				n, ok := byName[nodeName]
+				if !ok {
+					n = nodeInfo{name: nodeName}
+				}
+				if d, ok := topologyDomain(n, t.key); ok {
+					t.domains[d] = true
+				}
+			}
+		}
+		return t, nil
+	}
+
+	var affinity, antiAffinity []topologyTerm
+	for i := range a.ServiceAffinity {
+		t, err := resolve(&a.ServiceAffinity[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		affinity = append(affinity, t)
+	}
+	for i := range a.ServiceAntiAffinity {
+		t, err := resolve(&a.ServiceAntiAffinity[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		antiAffinity = append(antiAffinity, t)
+	}
+	return affinity, antiAffinity, nil
+}
+
+// affinityUnfitReason 返回节点不满足服务之间亲和约束的原因，满足时返回空字符串。
+func affinityUnfitReason(n nodeInfo, req requirements) string {
+	for _, t := range req.affinity {
+		d, ok := topologyDomain(n, t.key)
+		if !ok {
+			return reasonNoTopologyKey
+		}
+		// 匹配的服务都还没有实例时，服务自身就是这一组服务中的第一个
+		if len(t.domains) == 0 && t.self {
+			continue
+		}
+		if !t.domains[d] {
+			return reasonAffinity
+		}
+	}
+	for _, t := range req.antiAffinity {
+		d, ok := topologyDomain(n, t.key)
+		if !ok {
+			return reasonNoTopologyKey
+		}
+		if t.domains[d] {
+			return reasonAntiAffinity
+		}
+	}
+	return ""
+}
+
+// spreadTerms 返回服务自身也被选中的反亲和约束，服务的实例需要按这些约束分散开。
+func (r requirements) spreadTerms() []topologyTerm {
+	var terms []topologyTerm
+	for _, t := range r.antiAffinity {
+		if t.self {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// spreadAcrossDomains 按顺序在每个拓扑域中至多保留一个节点，nodes 应该已经按优先级排好序。
+func spreadAcrossDomains(nodes []nodeInfo, terms []topologyTerm) []nodeInfo {
+	used := make([]map[string]bool, len(terms))
+	for i := range used {
+		used[i] = make(map[string]bool)
+	}
+	var out []nodeInfo
+	for _, n := range nodes {
+		conflict := false
+		for i, t := range terms {
+			// affinityUnfitReason 已经排除了没有拓扑键的节点
+			d, _ := topologyDomain(n, t.key)
+			if used[i][d] {
+				conflict = true
+				break
+			}
+		}
+		if conflict {
+			continue
+		}
+		for i, t := range terms {
+			d, _ := topologyDomain(n, t.key)
+			used[i][d] = true
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// SpreadError 表示服务与自身反亲和，但能够容纳实例的拓扑域少于副本数。
+type SpreadError struct {
+	Replicas int
+	Domains  int
+}
+
+func (e *SpreadError) Error() string {
+	return fmt.Sprintf("service anti-affinity requires %d replica(s) on distinct topology domains, but only %d are available",
+		e.Replicas, e.Domains)
+}
@@ -0,0 +1,82 @@
+// file: pkg/scheduler/affinity_test.go
+
+package scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelectNodesAffinity(t *testing.T) {
+	zone := func(z string) map[string]string { return map[string]string{"zone": z} }
+	nodes := []nodeInfo{
+		readyNode("a", 400, 0, zone("1")),
+		readyNode("b", 300, 0, zone("1")),
+		readyNode("c", 200, 0, zone("2")),
+		readyNode("d", 100, 0, nil),
+	}
+
+	tests := []struct {
+		name     string
+		req      requirements
+		replicas int
+		want     []string
+	}{
+		{
+			name:     "affinity to the zone of another service",
+			req:      requirements{affinity: []topologyTerm{{key: "zone", domains: map[string]bool{"2": true}}}},
+			replicas: 2,
+			want:     []string{"c"},
+		},
+		{
+			name:     "affinity to itself only is ignored until the first instance exists",
+			req:      requirements{affinity: []topologyTerm{{domains: map[string]bool{}, self: true}}},
+			replicas: 2,
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "anti-affinity to another service by node",
+			req:      requirements{antiAffinity: []topologyTerm{{domains: map[string]bool{"a": true}}}},
+			replicas: 2,
+			want:     []string{"b", "c"},
+		},
+		{
+			name:     "self anti-affinity spreads across zones",
+			req:      requirements{antiAffinity: []topologyTerm{{key: "zone", domains: map[string]bool{}, self: true}}},
+			replicas: 2,
+			want:     []string{"a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectNodes(nodes, tt.req, tt.replicas, nil)
+			if err != nil {
+				t.Fatalf("selectNodes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectNodesSpreadError(t *testing.T) {
+	nodes := []nodeInfo{
+		readyNode("a", 100, 0, nil),
+		readyNode("b", 100, 0, nil),
+	}
+	req := requirements{antiAffinity: []topologyTerm{{domains: map[string]bool{}, self: true}}}
+
+	if got, err := selectNodes(nodes, req, 2, nil); err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("selectNodes() = %v, %v, want one replica on each node", got, err)
+	}
+	_, err := selectNodes(nodes, req, 3, nil)
+	var spreadErr *SpreadError
+	if !errors.As(err, &spreadErr) {
+		t.Fatalf("selectNodes() error = %v, want a SpreadError", err)
+	}
+	if spreadErr.Replicas != 3 || spreadErr.Domains != 2 {
+		t.Errorf("SpreadError = %+v, want 3 replicas on 2 domains", spreadErr)
+	}
+}
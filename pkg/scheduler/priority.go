@@ -0,0 +1,121 @@
+// file: pkg/scheduler/priority.go
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Priorities 是所有 ECSMPriorityClass 的一个快照，用于解析服务的优先级。
+type Priorities struct {
+	values        map[string]int32
+	globalDefault int32
+}
+
+// NewPriorities 根据一组 ECSMPriorityClass 创建 Priorities。
+func NewPriorities(classes []ecsmv1.ECSMPriorityClass) *Priorities {
+	p := &Priorities{values: make(map[string]int32, len(classes)), globalDefault: ecsmv1.DefaultPriority}
+	hasDefault := false
+	for i := range classes {
+		pc := &classes[i]
+		p.values[pc.Name] = pc.Value
+		// 有多个 GlobalDefault 时取值最小的一个，以免意外地提升了大量服务的优先级
+		if pc.GlobalDefault && (!hasDefault || pc.Value < p.globalDefault) {
+			p.globalDefault = pc.Value
+			hasDefault = true
+		}
+	}
+	return p
+}
+
+// LoadPriorities 读取 Registry 中所有的 ECSMPriorityClass。
+func LoadPriorities(ctx context.Context, reg registry.Interface) (*Priorities, error) {
+	list, _, err := reg.ListPriorityClasses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ECSMPriorityClasses: %w", err)
+	}
+	return NewPriorities(list.Items), nil
+}
+
+// Of 返回服务的优先级。没有设置 priorityClassName 时使用 GlobalDefault 的优先级，
+// 引用的优先级不存在时使用 ecsmv1.DefaultPriority。
+func (p *Priorities) Of(service *ecsmv1.ECSMService) int32 {
+	name := service.Spec.PriorityClassName
+	if name == "" {
+		return p.globalDefault
+	}
+	if v, ok := p.values[name]; ok {
+		return v
+	}
+	return ecsmv1.DefaultPriority
+}
+
+// InstanceMemory 返回服务一个实例的内存限制 (字节)，没有限制或限制无法解析时返回 0。
+func InstanceMemory(service *ecsmv1.ECSMService) int64 {
+	res := service.Spec.Template.Resources
+	if res == nil {
+		return 0
+	}
+	v, ok := res.Limits[ecsmv1.ResourceTypeMemory]
+	if !ok {
+		return 0
+	}
+	q, err := resource.ParseQuantity(v)
+	if err != nil {
+		return 0
+	}
+	return q.Value()
+}
+
+// preemptibleMemory 返回每个节点上优先级低于 service 的其他服务实例占用的内存，按它们的内存限制计算。
+// 节点资源紧张时，调度器认为这部分内存可以通过驱逐低优先级的实例腾出来。
+func (s *Scheduler) preemptibleMemory(ctx context.Context, service *ecsmv1.ECSMService) (map[string]int64, error) {
+	if s.placements == nil {
+		return nil, nil
+	}
+	priorities, err := LoadPriorities(ctx, s.registry)
+	if err != nil {
+		return nil, err
+	}
+	if len(priorities.values) == 0 {
+		// 没有任何优先级时所有服务的优先级相同
+		return nil, nil
+	}
+	services, _, err := s.registry.ListAllServices(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ECSMServices: %w", err)
+	}
+
+	priority := priorities.Of(service)
+	self := cache.MetaObjectToName(service)
+	preemptible := make(map[string]int64)
+	for i := range services.Items {
+		other := &services.Items[i]
+		if cache.MetaObjectToName(other) == self || priorities.Of(other) >= priority {
+			continue
+		}
+		memory := InstanceMemory(other)
+		if memory == 0 {
+			continue
+		}
+		placed, err := s.placements(ctx, other)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nodes of service %s: %w", cache.MetaObjectToName(other), err)
+		}
+		for _, n := range placed {
+			preemptible[n] += memory
+		}
+	}
+	return preemptible, nil
+}
+
+// needsPreemption 判断实例只有在驱逐了节点上低优先级的实例之后才能放下。
+func needsPreemption(n nodeInfo, req requirements) bool {
+	return req.memory > 0 && n.status != nil && n.status.MemoryFree < req.memory
+}
@@ -0,0 +1,61 @@
+// file: pkg/scheduler/priority_test.go
+
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPrioritiesOf(t *testing.T) {
+	classes := []ecsmv1.ECSMPriorityClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "critical"}, Value: 1000},
+		{ObjectMeta: metav1.ObjectMeta{Name: "normal"}, Value: 100, GlobalDefault: true},
+		{ObjectMeta: metav1.ObjectMeta{Name: "low"}, Value: 10, GlobalDefault: true},
+	}
+	p := NewPriorities(classes)
+
+	withClass := func(name string) *ecsmv1.ECSMService {
+		return &ecsmv1.ECSMService{Spec: ecsmv1.ECSMServiceSpec{PriorityClassName: name}}
+	}
+	for name, want := range map[string]int32{
+		"critical": 1000,
+		// 多个 GlobalDefault 时取值最小的一个
+		"":        10,
+		"missing": ecsmv1.DefaultPriority,
+	} {
+		if got := p.Of(withClass(name)); got != want {
+			t.Errorf("Of(%q) = %d, want %d", name, got, want)
+		}
+	}
+
+	if got := NewPriorities(nil).Of(withClass("")); got != ecsmv1.DefaultPriority {
+		t.Errorf("Of() without any class = %d, want %d", got, ecsmv1.DefaultPriority)
+	}
+}
+
+func TestSelectNodesPreemption(t *testing.T) {
+	full := readyNode("full", 20, 0, nil)
+	full.preemptible = 100
+	nodes := []nodeInfo{full, readyNode("free", 60, 3, nil), readyNode("small", 20, 0, nil)}
+	req := requirements{memory: 50}
+
+	// 只有驱逐低优先级实例才能放下实例的节点是可用的，但排在能直接放下的节点之后
+	got, err := selectNodes(nodes, req, 2, nil)
+	if err != nil {
+		t.Fatalf("selectNodes() error = %v", err)
+	}
+	if want := []string{"free", "full"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("selectNodes() = %v, want %v", got, want)
+	}
+	got, err = selectNodes(nodes, req, 1, nil)
+	if err != nil {
+		t.Fatalf("selectNodes() error = %v", err)
+	}
+	if want := []string{"free"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("selectNodes() with one replica = %v, want %v", got, want)
+	}
+}
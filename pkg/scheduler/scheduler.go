@@ -0,0 +1,373 @@
+// file: pkg/scheduler/scheduler.go
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	ecsmmeta "github.com/fx147/ecsm-operator/pkg/apis/meta/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+	"github.com/fx147/ecsm-operator/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Scheduler 为 Dynamic 部署策略的服务选择具体的节点。
+// 它根据 Registry 中 ECSMNode 的标签和 Ready 状况，以及 ECSM 平台上节点的实时剩余资源，
+// 从节点池中筛选出能容纳一个实例的节点，并按剩余资源排序，
+// 而不是把整个节点池原样交给 ECSM 盲目地放置。
+// 节点内存不足时，被低优先级服务实例占用的内存也被视为可用，见 priority.go。
+type Scheduler struct {
+	registry   registry.Interface
+	ecsmClient clientset.Interface
+	placements PlacementLister
+}
+
+// PlacementLister 返回一个 ECSMService 的所有实例当前所在的节点，同一个节点上有多个实例时会重复出现。
+// 调度器用它计算服务之间的亲和与反亲和。
+type PlacementLister func(ctx context.Context, service *ecsmv1.ECSMService) ([]string, error)
+
+// New 创建一个新的 Scheduler。placements 为 nil 时，服务的 affinity 被忽略。
+func New(reg registry.Interface, ecsmClient clientset.Interface, placements PlacementLister) *Scheduler {
+	return &Scheduler{registry: reg, ecsmClient: ecsmClient, placements: placements}
+}
+
+// nodeInfo 是调度时对一个节点的快照
+type nodeInfo struct {
+	name   string
+	labels map[string]string
+	taints []ecsmv1.NodeTaint
+	ready  bool
+	// status 是节点的实时状态，平台没有返回时为 nil
+	status *clientset.NodeStatus
+	// preemptible 是节点上优先级低于被调度服务的实例占用的内存，见 priority.go
+	preemptible int64
+}
+
+// requirements 是一个实例对节点的要求
+type requirements struct {
+	pool         map[string]bool
+	nodeSelector map[string]string
+	tolerations  []ecsmv1.Toleration
+	// memory 和 disk 是一个实例的资源限制 (字节)，0 表示没有要求
+	memory int64
+	disk   int64
+	// affinity 和 antiAffinity 是服务之间的亲和约束，见 affinity.go
+	affinity     []topologyTerm
+	antiAffinity []topologyTerm
+}
+
+// Schedule 为服务的 replicas 个实例选择节点，返回发送给 ECSM 的 node.names。
+// 可用节点不少于 replicas 时，每个实例分配到不同的节点；否则返回所有可用节点，由 ECSM 在其中放置多个实例。
+// preferred 是当前已经运行着该服务的节点，它们会被优先选中，以免扩缩容时无谓地迁移实例。
+func (s *Scheduler) Schedule(ctx context.Context, service *ecsmv1.ECSMService, replicas int32, preferred []string) ([]string, error) {
+	req, err := requirementsFor(service)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		if len(service.Spec.DeploymentStrategy.NodeSelector) > 0 {
+			// 节点选择器只能根据 ECSMNode 的标签解析，不能交给 ECSM 盲目放置
+			return nil, &FitError{Reasons: map[string]int{}}
+		}
+		// NodeController 还没有同步过节点，退回到把节点池交给 ECSM 放置
+		return append([]string(nil), service.Spec.DeploymentStrategy.NodePool...), nil
+	}
+	if req.affinity, req.antiAffinity, err = s.affinityTerms(ctx, service, nodes); err != nil {
+		return nil, err
+	}
+	if req.memory > 0 {
+		preemptible, err := s.preemptibleMemory(ctx, service)
+		if err != nil {
+			return nil, err
+		}
+		for i := range nodes {
+			nodes[i].preemptible = preemptible[nodes[i].name]
+		}
+	}
+	return selectNodes(nodes, req, int(replicas), preferred)
+}
+
+// snapshot 读取所有 ECSMNode 及其实时状态。
+func (s *Scheduler) snapshot(ctx context.Context) ([]nodeInfo, error) {
+	list, _, err := s.registry.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ECSMNodes: %w", err)
+	}
+
+	var ids []string
+	for _, n := range list.Items {
+		if n.Status.NodeID != "" {
+			ids = append(ids, n.Status.NodeID)
+		}
+	}
+	statusByID := make(map[string]*clientset.NodeStatus, len(ids))
+	if len(ids) > 0 {
+		statuses, err := s.ecsmClient.Nodes().ListStatus(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get runtime status of nodes: %w", err)
+		}
+		for i := range statuses {
+			statusByID[statuses[i].ID] = &statuses[i]
+		}
+	}
+
+	nodes := make([]nodeInfo, 0, len(list.Items))
+	for _, n := range list.Items {
+		nodes = append(nodes, nodeInfo{
+			name:   n.Name,
+			labels: n.Labels,
+			taints: n.Spec.Taints,
+			ready:  ecsmmeta.IsConditionTrue(n.Status.Conditions, ecsmv1.NodeReady),
+			status: statusByID[n.Status.NodeID],
+		})
+	}
+	return nodes, nil
+}
+
+// requirementsFor 从服务的部署策略和容器模板中提取对节点的要求。
+func requirementsFor(service *ecsmv1.ECSMService) (requirements, error) {
+	strategy := service.Spec.DeploymentStrategy
+	req := requirements{nodeSelector: strategy.NodeSelector, tolerations: strategy.Tolerations}
+	if len(strategy.NodePool) > 0 {
+		req.pool = make(map[string]bool, len(strategy.NodePool))
+		for _, n := range strategy.NodePool {
+			req.pool[n] = true
+		}
+	}
+
+	if res := service.Spec.Template.Resources; res != nil {
+		for typ, target := range map[ecsmv1.ResourceType]*int64{
+			ecsmv1.ResourceTypeMemory: &req.memory,
+			ecsmv1.ResourceTypeDisk:   &req.disk,
+		} {
+			v, ok := res.Limits[typ]
+			if !ok {
+				continue
+			}
+			q, err := resource.ParseQuantity(v)
+			if err != nil {
+				return requirements{}, fmt.Errorf("invalid %s limit %q: %w", typ, v, err)
+			}
+			*target = q.Value()
+		}
+	}
+	return req, nil
+}
+
+// selectNodes 过滤出满足要求的节点，并按优先级选出至多 replicas 个。
+// 只有驱逐低优先级的实例才能放下实例的节点排在其他节点之后。
+func selectNodes(nodes []nodeInfo, req requirements, replicas int, preferred []string) ([]string, error) {
+	fitErr := &FitError{NumAllNodes: len(nodes), Reasons: make(map[string]int)}
+	var feasible []nodeInfo
+	for _, n := range nodes {
+		if reason := unfitReason(n, req); reason != "" {
+			fitErr.Reasons[reason]++
+			continue
+		}
+		feasible = append(feasible, n)
+	}
+	if len(feasible) == 0 {
+		return nil, fitErr
+	}
+
+	isPreferred := make(map[string]bool, len(preferred))
+	for _, n := range preferred {
+		isPreferred[n] = true
+	}
+	sort.SliceStable(feasible, func(i, j int) bool {
+		a, b := feasible[i], feasible[j]
+		if isPreferred[a.name] != isPreferred[b.name] {
+			return isPreferred[a.name]
+		}
+		if aPreempt, bPreempt := needsPreemption(a, req), needsPreemption(b, req); aPreempt != bPreempt {
+			return bPreempt
+		}
+		// 尽量避开带有不能容忍的 PreferNoSchedule 污点的节点
+		aAvoid := HasUntoleratedTaint(a.taints, req.tolerations, ecsmv1.TaintEffectPreferNoSchedule)
+		bAvoid := HasUntoleratedTaint(b.taints, req.tolerations, ecsmv1.TaintEffectPreferNoSchedule)
+		if aAvoid != bAvoid {
+			return bAvoid
+		}
+		if a.status.MemoryFree != b.status.MemoryFree {
+			return a.status.MemoryFree > b.status.MemoryFree
+		}
+		if a.status.ContainerEcsmRunning != b.status.ContainerEcsmRunning {
+			return a.status.ContainerEcsmRunning < b.status.ContainerEcsmRunning
+		}
+		return a.name < b.name
+	})
+
+	if spread := req.spreadTerms(); len(spread) > 0 {
+		// 服务与自身反亲和：每个拓扑域中至多选择一个节点，拓扑域不足以容纳所有实例时调度失败
+		feasible = spreadAcrossDomains(feasible, spread)
+		if replicas > len(feasible) {
+			return nil, &SpreadError{Replicas: replicas, Domains: len(feasible)}
+		}
+	}
+	if replicas > 0 && replicas < len(feasible) {
+		feasible = feasible[:replicas]
+	}
+	names := make([]string, 0, len(feasible))
+	for _, n := range feasible {
+		names = append(names, n.name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// 节点不可用的原因
+const (
+	reasonNotInPool        = "node(s) not in the node pool"
+	reasonNotReady         = "node(s) were not ready"
+	reasonSelectorMismatch = "node(s) didn't match the node selector"
+	reasonUntoleratedTaint = "node(s) had untolerated taints"
+	reasonNoStatus         = "node(s) had no runtime status"
+	reasonInsufficientMem  = "Insufficient memory"
+	reasonInsufficientDisk = "Insufficient disk"
+	reasonNoTopologyKey    = "node(s) didn't have the topology key"
+	reasonAffinity         = "node(s) didn't match service affinity rules"
+	reasonAntiAffinity     = "node(s) didn't match service anti-affinity rules"
+)
+
+// unfitReason 返回节点不能容纳一个实例的原因，能够容纳时返回空字符串。
+func unfitReason(n nodeInfo, req requirements) string {
+	switch {
+	case req.pool != nil && !req.pool[n.name]:
+		return reasonNotInPool
+	case !n.ready:
+		return reasonNotReady
+	case !matchesSelector(n.labels, req.nodeSelector):
+		return reasonSelectorMismatch
+	case HasUntoleratedTaint(n.taints, req.tolerations, ecsmv1.TaintEffectNoSchedule),
+		HasUntoleratedTaint(n.taints, req.tolerations, ecsmv1.TaintEffectNoExecute):
+		return reasonUntoleratedTaint
+	}
+	if reason := affinityUnfitReason(n, req); reason != "" {
+		return reason
+	}
+	switch {
+	case n.status == nil:
+		return reasonNoStatus
+	case req.memory > 0 && n.status.MemoryFree+n.preemptible < req.memory:
+		return reasonInsufficientMem
+	case req.disk > 0 && n.status.DiskFree < float64(req.disk):
+		return reasonInsufficientDisk
+	}
+	return ""
+}
+
+// NodeMatches 判断节点是否在服务的节点池中 (节点池为空时不限制)，且满足服务的 nodeSelector。
+// 它只检查服务对节点的静态要求，不考虑节点的就绪状态、污点和剩余资源。
+// ECSMServiceController 用它找出新注册或标签变化的节点影响了哪些服务。
+func NodeMatches(service *ecsmv1.ECSMService, node *ecsmv1.ECSMNode) bool {
+	strategy := service.Spec.DeploymentStrategy
+	if len(strategy.NodePool) > 0 {
+		inPool := false
+		for _, n := range strategy.NodePool {
+			if n == node.Name {
+				inPool = true
+				break
+			}
+		}
+		if !inPool {
+			return false
+		}
+	}
+	return matchesSelector(node.Labels, strategy.NodeSelector)
+}
+
+func matchesSelector(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if actual, ok := labels[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+// NodesToEvict 返回 nodes 中带有服务不能容忍的 NoExecute 污点的节点，服务在这些节点上的实例应该被驱逐。
+// 不存在对应 ECSMNode 的节点被忽略。
+func (s *Scheduler) NodesToEvict(ctx context.Context, service *ecsmv1.ECSMService, nodes []string) ([]string, error) {
+	list, _, err := s.registry.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ECSMNodes: %w", err)
+	}
+	running := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		running[n] = true
+	}
+	var evict []string
+	for i := range list.Items {
+		n := &list.Items[i]
+		if running[n.Name] && HasUntoleratedTaint(n.Spec.Taints, service.Spec.DeploymentStrategy.Tolerations, ecsmv1.TaintEffectNoExecute) {
+			evict = append(evict, n.Name)
+		}
+	}
+	sort.Strings(evict)
+	return evict, nil
+}
+
+// HasUntoleratedTaint 判断节点上是否有影响为 effect、且不被任何容忍匹配的污点。
+// 除调度器外，按节点部署的 ECSMNodeSet 也用它过滤节点。
+func HasUntoleratedTaint(taints []ecsmv1.NodeTaint, tolerations []ecsmv1.Toleration, effect ecsmv1.TaintEffect) bool {
+	for i := range taints {
+		if taints[i].Effect != effect {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if toleratesTaint(&tolerations[j], &taints[i]) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return true
+		}
+	}
+	return false
+}
+
+// toleratesTaint 判断容忍是否匹配污点。
+func toleratesTaint(t *ecsmv1.Toleration, taint *ecsmv1.NodeTaint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	switch t.Operator {
+	case ecsmv1.TolerationOpExists:
+		return t.Key == "" || t.Key == taint.Key
+	case ecsmv1.TolerationOpEqual, "":
+		return t.Key == taint.Key && t.Value == taint.Value
+	}
+	return false
+}
+
+// FitError 表示没有任何节点能够容纳服务的实例。
+type FitError struct {
+	NumAllNodes int
+	// Reasons 统计了每种原因下不可用的节点数量
+	Reasons map[string]int
+}
+
+// Error 返回与 Kubernetes 调度器类似的消息，例如
+// "0/3 nodes are available: 1 node(s) were not ready, 2 Insufficient memory."
+func (f *FitError) Error() string {
+	var reasons []string
+	for reason, count := range f.Reasons {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, reason))
+	}
+	sort.Strings(reasons)
+	msg := fmt.Sprintf("0/%d nodes are available", f.NumAllNodes)
+	if len(reasons) > 0 {
+		msg += ": " + strings.Join(reasons, ", ")
+	}
+	return msg + "."
+}
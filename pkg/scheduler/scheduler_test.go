@@ -0,0 +1,191 @@
+// file: pkg/scheduler/scheduler_test.go
+
+package scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ecsmv1 "github.com/fx147/ecsm-operator/pkg/apis/ecsm/v1"
+	"github.com/fx147/ecsm-operator/pkg/ecsm-client/clientset"
+)
+
+func readyNode(name string, memFree int64, running int, labels map[string]string) nodeInfo {
+	return nodeInfo{
+		name:   name,
+		labels: labels,
+		ready:  true,
+		status: &clientset.NodeStatus{MemoryFree: memFree, DiskFree: 1 << 30, ContainerEcsmRunning: running},
+	}
+}
+
+func TestSelectNodes(t *testing.T) {
+	nodes := []nodeInfo{
+		readyNode("a", 100, 0, nil),
+		readyNode("b", 300, 5, map[string]string{"zone": "1"}),
+		readyNode("c", 300, 1, map[string]string{"zone": "1"}),
+		{name: "d", ready: false},
+		readyNode("e", 10, 0, nil),
+	}
+
+	tests := []struct {
+		name      string
+		req       requirements
+		replicas  int
+		preferred []string
+		want      []string
+	}{
+		{
+			name:     "most free memory, then fewest containers",
+			replicas: 2,
+			want:     []string{"b", "c"},
+		},
+		{
+			name:      "preferred nodes first",
+			replicas:  2,
+			preferred: []string{"a"},
+			want:      []string{"a", "c"},
+		},
+		{
+			name:     "node pool and selector",
+			req:      requirements{pool: map[string]bool{"a": true, "b": true}, nodeSelector: map[string]string{"zone": "1"}},
+			replicas: 3,
+			want:     []string{"b"},
+		},
+		{
+			name:     "memory requirement",
+			req:      requirements{memory: 50},
+			replicas: 5,
+			want:     []string{"a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectNodes(nodes, tt.req, tt.replicas, tt.preferred)
+			if err != nil {
+				t.Fatalf("selectNodes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectNodesFitError(t *testing.T) {
+	nodes := []nodeInfo{
+		readyNode("a", 10, 0, nil),
+		{name: "b", ready: false},
+	}
+	_, err := selectNodes(nodes, requirements{memory: 50}, 1, nil)
+	var fitErr *FitError
+	if !errors.As(err, &fitErr) {
+		t.Fatalf("selectNodes() error = %v, want a FitError", err)
+	}
+	want := "0/2 nodes are available: 1 Insufficient memory, 1 node(s) were not ready."
+	if err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestSelectNodesTaints(t *testing.T) {
+	dedicated := ecsmv1.NodeTaint{Key: "dedicated", Value: "gpu", Effect: ecsmv1.TaintEffectNoSchedule}
+	draining := ecsmv1.NodeTaint{Key: "draining", Effect: ecsmv1.TaintEffectPreferNoSchedule}
+	maintenance := ecsmv1.NodeTaint{Key: "maintenance", Effect: ecsmv1.TaintEffectNoExecute}
+	withTaints := func(n nodeInfo, taints ...ecsmv1.NodeTaint) nodeInfo {
+		n.taints = taints
+		return n
+	}
+	nodes := []nodeInfo{
+		withTaints(readyNode("a", 300, 0, nil), dedicated),
+		withTaints(readyNode("b", 200, 0, nil), draining),
+		readyNode("c", 100, 0, nil),
+		withTaints(readyNode("d", 50, 0, nil), maintenance),
+	}
+
+	tests := []struct {
+		name     string
+		req      requirements
+		replicas int
+		want     []string
+	}{
+		{
+			name:     "NoSchedule excludes, PreferNoSchedule ranks last",
+			replicas: 1,
+			want:     []string{"c"},
+		},
+		{
+			name:     "tolerated by key and value",
+			req:      requirements{tolerations: []ecsmv1.Toleration{{Key: "dedicated", Value: "gpu"}}},
+			replicas: 2,
+			want:     []string{"a", "c"},
+		},
+		{
+			name:     "Exists with an empty key tolerates everything",
+			req:      requirements{tolerations: []ecsmv1.Toleration{{Operator: ecsmv1.TolerationOpExists}}},
+			replicas: 2,
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "NoExecute excludes unless tolerated",
+			req:      requirements{tolerations: []ecsmv1.Toleration{{Key: "maintenance", Operator: ecsmv1.TolerationOpExists, Effect: ecsmv1.TaintEffectNoExecute}}},
+			replicas: 3,
+			want:     []string{"b", "c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectNodes(nodes, tt.req, tt.replicas, nil)
+			if err != nil {
+				t.Fatalf("selectNodes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirementsFor(t *testing.T) {
+	service := &ecsmv1.ECSMService{Spec: ecsmv1.ECSMServiceSpec{
+		DeploymentStrategy: ecsmv1.DeploymentStrategy{Type: ecsmv1.DeploymentStrategyTypeDynamic, NodePool: []string{"a"}},
+		Template: ecsmv1.ContainerTemplateSpec{Resources: &ecsmv1.ResourceRequirements{
+			Limits: map[ecsmv1.ResourceType]string{ecsmv1.ResourceTypeMemory: "64Mi"},
+		}},
+	}}
+	req, err := requirementsFor(service)
+	if err != nil {
+		t.Fatalf("requirementsFor() error = %v", err)
+	}
+	if req.memory != 64<<20 || req.disk != 0 || !req.pool["a"] {
+		t.Errorf("requirementsFor() = %+v", req)
+	}
+}
+
+func TestNodeMatches(t *testing.T) {
+	node := &ecsmv1.ECSMNode{}
+	node.Name = "edge-3"
+	node.Labels = map[string]string{"role": "edge", "zone": "1"}
+
+	tests := []struct {
+		name     string
+		strategy ecsmv1.DeploymentStrategy
+		want     bool
+	}{
+		{"no pool and no selector", ecsmv1.DeploymentStrategy{}, true},
+		{"selector matches", ecsmv1.DeploymentStrategy{NodeSelector: map[string]string{"role": "edge"}}, true},
+		{"selector value differs", ecsmv1.DeploymentStrategy{NodeSelector: map[string]string{"role": "core"}}, false},
+		{"selector key missing", ecsmv1.DeploymentStrategy{NodeSelector: map[string]string{"gpu": "true"}}, false},
+		{"not in pool", ecsmv1.DeploymentStrategy{NodePool: []string{"edge-1"}, NodeSelector: map[string]string{"role": "edge"}}, false},
+		{"in pool", ecsmv1.DeploymentStrategy{NodePool: []string{"edge-1", "edge-3"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &ecsmv1.ECSMService{Spec: ecsmv1.ECSMServiceSpec{DeploymentStrategy: tt.strategy}}
+			if got := NodeMatches(service, node); got != tt.want {
+				t.Errorf("NodeMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
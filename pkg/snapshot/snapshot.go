@@ -0,0 +1,330 @@
+// file: pkg/snapshot/snapshot.go
+
+// Package snapshot 周期性地把 Registry 的 bbolt 数据库备份到本地目录或远程目标，按保留策略清理旧的快照，
+// 并可以把一个快照恢复为 Registry 的数据库文件。每个快照都附带一个 sha256sum 格式的校验文件，
+// 恢复前会校验快照的完整性。
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fx147/ecsm-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	bolt "go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// 快照文件名为 registry-<UTC 时间>.db，按名称排序即按时间排序
+	filePrefix = "registry-"
+	fileSuffix = ".db"
+	timeFormat = "20060102T150405Z"
+	// checksumSuffix 是校验文件的后缀，校验文件的内容与 sha256sum 的输出相同
+	checksumSuffix = ".sha256"
+
+	// snapshotTimeout 是写入一个快照并清理旧快照的最长时间
+	snapshotTimeout = 10 * time.Minute
+)
+
+var (
+	snapshotsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "registry",
+		Name:      "snapshots_total",
+		Help:      "Total number of registry snapshots per result (success, error).",
+	}, []string{"result"})
+
+	lastSnapshotTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "registry",
+		Name:      "last_snapshot_timestamp_seconds",
+		Help:      "Unix time of the last successful registry snapshot.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(snapshotsTotal, lastSnapshotTimestamp)
+}
+
+// Options 是快照的调度和保留策略
+type Options struct {
+	// Period 是两次快照的间隔
+	Period time.Duration
+	// Retention 是最多保留的快照数量，0 表示不按数量清理
+	Retention int
+	// MaxAge 是快照的最长保留时间，0 表示不按时间清理
+	MaxAge time.Duration
+}
+
+// Info 描述了目标中的一个快照
+type Info struct {
+	// Name 是快照文件的名称
+	Name string
+	// Time 是快照的创建时间
+	Time time.Time
+	// Checksum 是快照文件的十六进制 sha256
+	Checksum string
+}
+
+// Snapshotter 按 Options 周期性地把数据库写入 Target，并清理超出保留策略的快照。
+// 无论保留策略如何，最新的一个快照总是被保留。
+type Snapshotter struct {
+	db     *bolt.DB
+	target Target
+	opts   Options
+	clock  func() time.Time
+}
+
+// New 创建一个把 db 的快照写入 target 的 Snapshotter。
+func New(db *bolt.DB, target Target, opts Options) *Snapshotter {
+	return &Snapshotter{db: db, target: target, opts: opts, clock: time.Now}
+}
+
+// Run 启动时立即写入一个快照，此后每个 Period 写入一个，直到 stopCh 被关闭。
+func (s *Snapshotter) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.Infof("Starting registry snapshots to %s every %v", s.target, s.opts.Period)
+	defer klog.Info("Shutting down registry snapshots")
+
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		defer cancel()
+
+		info, err := s.Take(ctx)
+		if err != nil {
+			snapshotsTotal.WithLabelValues("error").Inc()
+			runtime.HandleError(fmt.Errorf("failed to snapshot the registry to %s: %w", s.target, err))
+			return
+		}
+		snapshotsTotal.WithLabelValues("success").Inc()
+		lastSnapshotTimestamp.Set(float64(info.Time.Unix()))
+		klog.V(2).Infof("Wrote registry snapshot %s to %s", info.Name, s.target)
+
+		if _, err := s.Prune(ctx); err != nil {
+			runtime.HandleError(fmt.Errorf("failed to prune registry snapshots in %s: %w", s.target, err))
+		}
+	}, s.opts.Period, stopCh)
+}
+
+// Take 写入一个快照和它的校验文件。
+func (s *Snapshotter) Take(ctx context.Context) (*Info, error) {
+	now := s.clock().UTC()
+	name := filePrefix + now.Format(timeFormat) + fileSuffix
+
+	// 先复制到临时文件再上传，避免在上传期间一直持有读事务，
+	// 长时间的读事务会阻止 bbolt 在写入时扩大内存映射
+	tmp, err := os.CreateTemp("", "ecsm-snapshot-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	err = s.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(io.MultiWriter(tmp, h))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy the database: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	checksum := hex.EncodeToString(h.Sum(nil))
+
+	if err := s.target.Put(ctx, name, tmp); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	// 校验文件最后写入，List 只返回有校验文件的快照，所以写到一半的快照不会被使用
+	if err := s.target.Put(ctx, name+checksumSuffix, strings.NewReader(checksum+"  "+name+"\n")); err != nil {
+		s.target.Delete(ctx, name)
+		return nil, fmt.Errorf("failed to write the checksum of %s: %w", name, err)
+	}
+	return &Info{Name: name, Time: now, Checksum: checksum}, nil
+}
+
+// Prune 删除超出保留策略的快照，返回被删除的快照名称。
+func (s *Snapshotter) Prune(ctx context.Context) ([]string, error) {
+	snapshots, err := List(ctx, s.target)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock()
+	var deleted []string
+	// snapshots 按时间从新到旧排列，第一个总是被保留
+	for i, info := range snapshots {
+		if i == 0 {
+			continue
+		}
+		tooMany := s.opts.Retention > 0 && i >= s.opts.Retention
+		tooOld := s.opts.MaxAge > 0 && now.Sub(info.Time) > s.opts.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		// 先删除校验文件，删除中断时剩下的快照文件不会再被当作可用的快照
+		if err := s.target.Delete(ctx, info.Name+checksumSuffix); err != nil {
+			return deleted, err
+		}
+		if err := s.target.Delete(ctx, info.Name); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, info.Name)
+	}
+	return deleted, nil
+}
+
+// List 返回 target 中所有完整的快照，按时间从新到旧排列。
+func List(ctx context.Context, target Target) ([]Info, error) {
+	names, err := target.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", target, err)
+	}
+	files := make(map[string]bool, len(names))
+	for _, name := range names {
+		files[name] = true
+	}
+
+	var snapshots []Info
+	for _, name := range names {
+		t, ok := parseName(name)
+		if !ok || !files[name+checksumSuffix] {
+			continue
+		}
+		checksum, err := readChecksum(ctx, target, name)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Info{Name: name, Time: t, Checksum: checksum})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// Restore 把 target 中名为 name 的快照恢复到 dbPath。
+// 快照先被下载到 dbPath 所在的目录并校验 sha256 和 bbolt 的数据结构，校验通过后才替换 dbPath，
+// 原有的数据库被重命名为 dbPath.bak。dbPath 正在被 operator 使用时返回错误。
+func Restore(ctx context.Context, target Target, name, dbPath string) error {
+	if _, ok := parseName(name); !ok {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	checksum, err := readChecksum(ctx, target, name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dbPath), "."+filepath.Base(dbPath)+".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := download(ctx, target, name, tmp, checksum); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := checkDB(tmp.Name()); err != nil {
+		return fmt.Errorf("snapshot %s is corrupted: %w", name, err)
+	}
+
+	// operator 运行时持有数据库的文件锁，在它退出之前不能替换数据库
+	if _, err := os.Stat(dbPath); err == nil {
+		db, err := bolt.Open(dbPath, 0600, &bolt.Options{Timeout: time.Second})
+		if errors.Is(err, berrors.ErrTimeout) {
+			return fmt.Errorf("registry database %s is in use, stop the ecsm-operator before restoring", dbPath)
+		}
+		if err == nil {
+			db.Close()
+		}
+		if err := os.Rename(dbPath, dbPath+".bak"); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), dbPath)
+}
+
+// download 把快照写入 w，并校验它的 sha256 等于 checksum。
+func download(ctx context.Context, target Target, name string, w io.Writer, checksum string) error {
+	body, err := target.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", name, err)
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), body); err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", name, err)
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != checksum {
+		return fmt.Errorf("checksum mismatch for snapshot %s: expected %s, got %s", name, checksum, actual)
+	}
+	return nil
+}
+
+// checkDB 以只读方式打开一个 bbolt 数据库并检查它的页面结构。
+func checkDB(path string) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error {
+		// 必须读完 channel，Check 的 goroutine 才会结束
+		var first error
+		for err := range tx.Check() {
+			if first == nil {
+				first = err
+			}
+		}
+		return first
+	})
+}
+
+// readChecksum 读取快照的校验文件，返回其中的十六进制 sha256。
+func readChecksum(ctx context.Context, target Target, name string) (string, error) {
+	body, err := target.Get(ctx, name+checksumSuffix)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the checksum of snapshot %s: %w", name, err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the checksum of snapshot %s: %w", name, err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum file for snapshot %s", name)
+	}
+	return fields[0], nil
+}
+
+// parseName 从快照文件名中解析创建时间，name 不是快照文件名时返回 false。
+func parseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(timeFormat, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
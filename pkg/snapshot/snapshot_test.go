@@ -0,0 +1,215 @@
+// file: pkg/snapshot/snapshot_test.go
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// newTestDB 创建一个包含 key=value 的数据库
+func newTestDB(t *testing.T, path, value string) *bolt.DB {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open bbolt db: %v", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte("test"))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("key"), []byte(value))
+	})
+	if err != nil {
+		t.Fatalf("Failed to write bbolt db: %v", err)
+	}
+	return db
+}
+
+func readValue(t *testing.T, path string) string {
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer db.Close()
+	var value string
+	db.View(func(tx *bolt.Tx) error {
+		value = string(tx.Bucket([]byte("test")).Get([]byte("key")))
+		return nil
+	})
+	return value
+}
+
+// takeAt 在 t 时刻写入一个快照
+func takeAt(t *testing.T, s *Snapshotter, at time.Time) *Info {
+	s.clock = func() time.Time { return at }
+	info, err := s.Take(context.Background())
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	return info
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, filepath.Join(t.TempDir(), "registry.db"), "v1")
+	defer db.Close()
+	target, _ := NewTarget(filepath.Join(t.TempDir(), "snapshots"))
+
+	s := New(db, target, Options{Retention: 3, MaxAge: 24 * time.Hour})
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		takeAt(t, s, start.Add(time.Duration(i)*time.Hour))
+	}
+	// 没有校验文件的快照不是完整的快照
+	target.Put(ctx, "registry-20261001T100000Z.db", strings.NewReader("partial"))
+
+	deleted, err := s.Prune(ctx)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(deleted) != 2 || deleted[0] != "registry-20261001T010000Z.db" || deleted[1] != "registry-20261001T000000Z.db" {
+		t.Errorf("deleted = %v, want the two oldest snapshots", deleted)
+	}
+	snapshots, err := List(ctx, target)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(snapshots) != 3 || snapshots[0].Name != "registry-20261001T040000Z.db" {
+		t.Fatalf("snapshots = %+v, want the three newest", snapshots)
+	}
+
+	// 超过 MaxAge 的快照被删除，但最新的一个总是保留
+	s.clock = func() time.Time { return start.Add(30 * 24 * time.Hour) }
+	if _, err := s.Prune(ctx); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	snapshots, _ = List(ctx, target)
+	if len(snapshots) != 1 || snapshots[0].Name != "registry-20261001T040000Z.db" {
+		t.Errorf("snapshots = %+v, want only the newest", snapshots)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "registry.db")
+	db := newTestDB(t, dbPath, "v1")
+	target, _ := NewTarget(filepath.Join(dir, "snapshots"))
+	info := takeAt(t, New(db, target, Options{}), time.Now())
+
+	db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("test")).Put([]byte("key"), []byte("v2"))
+	})
+	// operator 仍在运行时不能恢复
+	if err := Restore(ctx, target, info.Name, dbPath); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("Restore returned %v, want an in use error", err)
+	}
+	db.Close()
+
+	if err := Restore(ctx, target, info.Name, dbPath); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if value := readValue(t, dbPath); value != "v1" {
+		t.Errorf("restored value = %q, want v1", value)
+	}
+	if value := readValue(t, dbPath+".bak"); value != "v2" {
+		t.Errorf("backup value = %q, want v2", value)
+	}
+
+	// 快照被篡改后校验失败，数据库保持不变
+	target.Put(ctx, info.Name, strings.NewReader("corrupted"))
+	if err := Restore(ctx, target, info.Name, dbPath); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Restore returned %v, want a checksum mismatch", err)
+	}
+	if value := readValue(t, dbPath); value != "v1" {
+		t.Errorf("value after failed restore = %q, want v1", value)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("unexpected files left in %s: %v", dir, entries)
+	}
+}
+
+// fileServer 是一个在内存中保存文件、支持 PUT、GET 和 DELETE 的 HTTP 服务器
+type fileServer struct {
+	lock  sync.Mutex
+	files map[string][]byte
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.files[r.URL.Path] = data
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := s.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(s.files, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestHTTPTarget(t *testing.T) {
+	ctx := context.Background()
+	files := &fileServer{files: make(map[string][]byte)}
+	server := httptest.NewServer(files)
+	defer server.Close()
+
+	db := newTestDB(t, filepath.Join(t.TempDir(), "registry.db"), "v1")
+	defer db.Close()
+	target, _ := NewTarget(server.URL + "/backups/")
+	s := New(db, target, Options{Retention: 1})
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	takeAt(t, s, start)
+	latest := takeAt(t, s, start.Add(time.Hour))
+	if _, err := s.Prune(ctx); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	snapshots, err := List(ctx, target)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0] != *latest {
+		t.Errorf("snapshots = %+v, want %+v", snapshots, latest)
+	}
+	index := string(files.files["/backups/index"])
+	if want := latest.Name + "\n" + latest.Name + ".sha256\n"; index != want {
+		t.Errorf("index = %q, want %q", index, want)
+	}
+	if _, ok := files.files["/backups/registry-20261001T000000Z.db"]; ok {
+		t.Error("the pruned snapshot was not deleted")
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "restored.db")
+	if err := Restore(ctx, target, latest.Name, dbPath); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if value := readValue(t, dbPath); value != "v1" {
+		t.Errorf("restored value = %q, want v1", value)
+	}
+	if !bytes.HasPrefix(files.files["/backups/"+latest.Name+".sha256"], []byte(latest.Checksum)) {
+		t.Error("checksum file does not start with the checksum")
+	}
+}
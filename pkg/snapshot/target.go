@@ -0,0 +1,256 @@
+// file: pkg/snapshot/target.go
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound 在目标中不存在指定的文件时返回
+var ErrNotFound = errors.New("not found")
+
+// Target 是存放快照文件的位置。文件名不包含路径分隔符。
+type Target interface {
+	// Put 写入一个文件，已经存在的同名文件被覆盖。写入失败时不能留下不完整的文件。
+	Put(ctx context.Context, name string, r io.Reader) error
+	// Get 读取一个文件，文件不存在时返回 ErrNotFound。
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List 返回所有文件的名称。
+	List(ctx context.Context) ([]string, error)
+	// Delete 删除一个文件，文件不存在时不返回错误。
+	Delete(ctx context.Context, name string) error
+	// String 返回目标的位置，用于日志和错误信息。
+	String() string
+}
+
+// NewTarget 根据 location 创建 Target：http:// 或 https:// 开头的地址是远程的 HTTP 目标，否则是本地目录。
+func NewTarget(location string) (Target, error) {
+	if location == "" {
+		return nil, fmt.Errorf("snapshot target must be specified")
+	}
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return &httpTarget{baseURL: strings.TrimSuffix(location, "/"), client: http.DefaultClient}, nil
+	}
+	return &dirTarget{dir: location}, nil
+}
+
+// dirTarget 把快照保存在本地目录中，这个目录也可以是挂载的网络存储。
+type dirTarget struct {
+	dir string
+}
+
+func (t *dirTarget) Put(ctx context.Context, name string, r io.Reader) error {
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return err
+	}
+	// 先写入临时文件再重命名，写入失败或进程退出时不会留下不完整的快照
+	tmp, err := os.CreateTemp(t.dir, "."+name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(t.dir, name))
+}
+
+func (t *dirTarget) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(t.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s in %s: %w", name, t.dir, ErrNotFound)
+	}
+	return f, err
+}
+
+func (t *dirTarget) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(t.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (t *dirTarget) Delete(ctx context.Context, name string) error {
+	err := os.Remove(filepath.Join(t.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (t *dirTarget) String() string {
+	return t.dir
+}
+
+// indexName 是 httpTarget 中记录所有文件名称的索引文件
+const indexName = "index"
+
+// httpTarget 通过 HTTP PUT、GET 和 DELETE 把快照保存在远程的服务器上，例如 WebDAV 或支持 PUT 的对象存储网关。
+// HTTP 没有列出文件的通用方法，所以它在同一位置维护一个每行一个文件名的索引文件。
+type httpTarget struct {
+	baseURL string
+	client  *http.Client
+	// lock 保护索引文件的读-改-写，同一个目标只应该有一个 operator 写入
+	lock sync.Mutex
+}
+
+func (t *httpTarget) do(ctx context.Context, method, name string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+"/"+name, body)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.Do(req)
+}
+
+// check 把非 2xx 的响应转换为错误并关闭响应。
+func check(resp *http.Response, method, name string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %w", method, name, ErrNotFound)
+	}
+	return fmt.Errorf("%s %s: %s: %s", method, name, resp.Status, strings.TrimSpace(string(body)))
+}
+
+func (t *httpTarget) Put(ctx context.Context, name string, r io.Reader) error {
+	resp, err := t.do(ctx, http.MethodPut, name, r)
+	if err != nil {
+		return err
+	}
+	if err := check(resp, http.MethodPut, name); err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	names, err := t.readIndex(ctx)
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		if n == name {
+			return nil
+		}
+	}
+	return t.writeIndex(ctx, append(names, name))
+}
+
+func (t *httpTarget) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := t.do(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(resp, http.MethodGet, name); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (t *httpTarget) List(ctx context.Context) ([]string, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.readIndex(ctx)
+}
+
+func (t *httpTarget) Delete(ctx context.Context, name string) error {
+	resp, err := t.do(ctx, http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	if err := check(resp, http.MethodDelete, name); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	resp.Body.Close()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	names, err := t.readIndex(ctx)
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, n := range names {
+		if n != name {
+			kept = append(kept, n)
+		}
+	}
+	if len(kept) == len(names) {
+		return nil
+	}
+	return t.writeIndex(ctx, kept)
+}
+
+func (t *httpTarget) String() string {
+	return t.baseURL
+}
+
+// readIndex 读取索引文件，索引文件不存在时返回空列表。调用时必须持有 lock。
+func (t *httpTarget) readIndex(ctx context.Context) ([]string, error) {
+	body, err := t.Get(ctx, indexName)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, scanner.Err()
+}
+
+// writeIndex 覆盖索引文件。调用时必须持有 lock。
+func (t *httpTarget) writeIndex(ctx context.Context, names []string) error {
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintln(&buf, name)
+	}
+	resp, err := t.do(ctx, http.MethodPut, indexName, &buf)
+	if err != nil {
+		return err
+	}
+	if err := check(resp, http.MethodPut, indexName); err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,60 @@
+// file: pkg/util/mergepatch.go
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MergePatch 按照 RFC 7386 把 patch 合并到 doc 上：patch 中的对象递归合并，
+// null 删除对应的字段，其他值 (包括数组) 整体替换。doc 会被原地修改。
+func MergePatch(doc, patch map[string]interface{}) map[string]interface{} {
+	if doc == nil {
+		doc = make(map[string]interface{}, len(patch))
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(doc, k)
+			continue
+		}
+		if pv, ok := v.(map[string]interface{}); ok {
+			dv, _ := doc[k].(map[string]interface{})
+			doc[k] = MergePatch(dv, pv)
+			continue
+		}
+		doc[k] = v
+	}
+	return doc
+}
+
+// ApplyMergePatch 把 JSON Merge Patch 应用到 obj 的副本上，返回一个同类型的新对象，obj 本身不被修改。
+func ApplyMergePatch(obj runtime.Object, patch []byte) (runtime.Object, error) {
+	var p map[string]interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(MergePatch(doc, p)); err != nil {
+		return nil, err
+	}
+
+	patched, ok := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	if !ok {
+		return nil, fmt.Errorf("cannot create a new %T", obj)
+	}
+	if err := json.Unmarshal(data, patched); err != nil {
+		return nil, fmt.Errorf("patched object is invalid: %w", err)
+	}
+	return patched, nil
+}